@@ -82,9 +82,13 @@ func runServe(cmd *cobra.Command, args []string) error {
 	db.StartCheckpointLoop(ctx, 5*time.Minute)
 
 	repos := setupRepositories(ctx, db)
+	setupDatabaseMaintenance(ctx, db, repos.MainRepo, configManager.GetConfigGetter())
 	poolManager := pool.NewManager(ctx, repos.MainRepo)
 
 	metadataService, metadataReader := initializeMetadata(cfg)
+	if _, err := metadataService.MigrateSourceNzbPaths(ctx); err != nil {
+		logger.Warn("Failed to migrate source NZB paths", "err", err)
+	}
 
 	// 4. Setup network services
 	if err := setupNNTPPool(ctx, cfg, poolManager); err != nil {
@@ -116,7 +120,7 @@ func runServe(cmd *cobra.Command, args []string) error {
 	streamTracker := api.NewStreamTracker(poolManager)
 	defer streamTracker.Stop()
 
-	importerService, err := initializeImporter(ctx, cfg, metadataService, db, poolManager, rcloneRCClient, configManager.GetConfigGetter(), progressBroadcaster, repos.UserRepo, repos.HealthRepo)
+	importerService, err := initializeImporter(ctx, cfg, metadataService, db, poolManager, rcloneRCClient, configManager.GetConfigGetter(), progressBroadcaster, repos.UserRepo, repos.HealthRepo, repos.MainRepo)
 	if err != nil {
 		return err
 	}
@@ -172,6 +176,7 @@ func runServe(cmd *cobra.Command, args []string) error {
 	webdav.RegisterConfigHandlers(ctx, configManager, webdavHandler)
 	api.RegisterLogLevelHandler(ctx, configManager, debugMode, dynamicLeveler)
 	apiServer.RegisterFuseConfigChangeHandler(configManager)
+	registerMetadataConfigHandler(configManager, metadataService)
 
 	// Register segment cache config change handler for dynamic path/size/expiry changes.
 	// Enable/disable toggles take effect automatically via cacheSource.Store() at file-open time.
@@ -196,11 +201,12 @@ func runServe(cmd *cobra.Command, args []string) error {
 		}
 	})
 
-	healthWorker, librarySyncWorker, err := startHealthWorker(ctx, cfg, repos.HealthRepo, poolManager, configManager, rcloneRCClient, arrsService, importerService, progressBroadcaster)
+	healthWorker, librarySyncWorker, err := startHealthWorker(ctx, cfg, metadataService, repos.HealthRepo, poolManager, configManager, rcloneRCClient, arrsService, importerService, progressBroadcaster)
 	if err != nil {
 		logger.Warn("Health worker initialization failed", "err", err)
 	}
 	if healthWorker != nil {
+		healthWorker.SetStreamCanceller(streamTracker)
 		apiServer.SetHealthWorker(healthWorker)
 	}
 	if librarySyncWorker != nil {
@@ -341,6 +347,9 @@ func runServe(cmd *cobra.Command, args []string) error {
 	// Stop metadata backup worker
 	metadataBackupWorker.Stop(ctx)
 
+	// Stop filesystem background workers (empty directory sweeper)
+	fs.Close()
+
 	// Stop RClone mount service if running
 	if cfg.RClone.MountEnabled != nil && *cfg.RClone.MountEnabled {
 		if err := mountService.Stop(ctx); err != nil {
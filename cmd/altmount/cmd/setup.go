@@ -57,10 +57,26 @@ func initializeDatabase(ctx context.Context, cfg *config.Config) (*database.DB,
 // initializeMetadata creates metadata service and reader
 func initializeMetadata(cfg *config.Config) (*metadata.MetadataService, *metadata.MetadataReader) {
 	metadataService := metadata.NewMetadataService(cfg.Metadata.RootPath)
+	metadataService.SetMaxDirectoryDepth(cfg.Metadata.MaxDirectoryDepth)
+	metadataService.SetWriteRetry(cfg.GetMetadataWriteRetries(), cfg.GetMetadataWriteRetryDelay())
+	metadataService.SetNzbRoot(cfg.GetNzbRoot())
+	metadataService.SetComputeFingerprint(cfg.GetComputeFingerprint())
 	metadataReader := metadata.NewMetadataReader(metadataService)
 	return metadataService, metadataReader
 }
 
+// registerMetadataConfigHandler keeps a metadata service's max directory
+// depth, write retries, NZB root and fingerprinting in sync with the
+// configuration.
+func registerMetadataConfigHandler(configManager *config.Manager, metadataService *metadata.MetadataService) {
+	configManager.OnConfigChange(func(_, newConfig *config.Config) {
+		metadataService.SetMaxDirectoryDepth(newConfig.Metadata.MaxDirectoryDepth)
+		metadataService.SetWriteRetry(newConfig.GetMetadataWriteRetries(), newConfig.GetMetadataWriteRetryDelay())
+		metadataService.SetNzbRoot(newConfig.GetNzbRoot())
+		metadataService.SetComputeFingerprint(newConfig.GetComputeFingerprint())
+	})
+}
+
 // initializeImporter creates and starts the importer service
 func initializeImporter(
 	ctx context.Context,
@@ -73,6 +89,7 @@ func initializeImporter(
 	broadcaster *progress.ProgressBroadcaster,
 	userRepo *database.UserRepository,
 	healthRepo *database.HealthRepository,
+	mainRepo *database.Repository,
 ) (*importer.Service, error) {
 	// Set defaults for workers if not configured
 	maxProcessorWorkers := cfg.Import.MaxProcessorWorkers
@@ -84,7 +101,7 @@ func initializeImporter(
 		Workers: maxProcessorWorkers,
 	}
 
-	importerService, err := importer.NewService(serviceConfig, metadataService, db, poolManager, rcloneClient, configGetter, healthRepo, broadcaster, userRepo)
+	importerService, err := importer.NewService(serviceConfig, metadataService, db, poolManager, rcloneClient, configGetter, healthRepo, broadcaster, userRepo, mainRepo)
 	if err != nil {
 		slog.ErrorContext(ctx, "failed to create importer service", "err", err)
 		return nil, err
@@ -128,6 +145,12 @@ func initializeFilesystem(
 		cacheSource,
 	)
 
+	// Finish a directory rename a crash interrupted between the move and the
+	// health/symlink updates
+	if _, err := metadataRemoteFile.RecoverInterruptedRename(ctx); err != nil {
+		slog.ErrorContext(ctx, "failed to recover interrupted directory rename", "err", err)
+	}
+
 	// Create filesystem backed by metadata
 	return nzbfilesystem.NewNzbFilesystem(metadataRemoteFile)
 }
@@ -214,6 +237,25 @@ func setupRepositories(ctx context.Context, db *database.DB) *repositorySet {
 	}
 }
 
+// setupDatabaseMaintenance schedules periodic VACUUM/optimize runs and triggers
+// an extra run in the background after large bulk deletes.
+func setupDatabaseMaintenance(ctx context.Context, db *database.DB, mainRepo *database.Repository, configGetter config.ConfigGetter) {
+	cfg := configGetter()
+	db.StartMaintenanceLoop(ctx, time.Duration(cfg.Database.MaintenanceIntervalHours)*time.Hour)
+
+	mainRepo.SetBulkDeleteHook(func(_ context.Context, deleted int) {
+		threshold := configGetter().Database.MaintenanceAfterDeletedRows
+		if threshold <= 0 || deleted < threshold {
+			return
+		}
+		go func() {
+			if err := db.RunMaintenance(ctx); err != nil {
+				slog.WarnContext(ctx, "Database maintenance after bulk delete failed", "deleted", deleted, "err", err)
+			}
+		}()
+	})
+}
+
 // setupAuthService creates and initializes the authentication service.
 // When loginRequired is true, JWT_SECRET must be set or an error is returned.
 // When loginRequired is false, a missing JWT_SECRET is logged as a warning and nil is returned.
@@ -369,10 +411,12 @@ func setupWebDAV(
 	return webdavHandler, nil
 }
 
-// startHealthWorker creates and starts the health monitoring worker
+// startHealthWorker creates and starts the health monitoring worker. It shares
+// the main metadata service so a root migration is seen by both.
 func startHealthWorker(
 	ctx context.Context,
 	cfg *config.Config,
+	metadataService *metadata.MetadataService,
 	healthRepo *database.HealthRepository,
 	poolManager pool.Manager,
 	configManager *config.Manager,
@@ -381,9 +425,6 @@ func startHealthWorker(
 	importerService importer.ImportService,
 	broadcaster *progress.ProgressBroadcaster,
 ) (*health.HealthWorker, *health.LibrarySyncWorker, error) {
-	// Create metadata service for health worker
-	metadataService := metadata.NewMetadataService(cfg.Metadata.RootPath)
-
 	// Create health checker
 	healthChecker := health.NewHealthChecker(
 		healthRepo,
@@ -454,6 +495,7 @@ func createHTTPServer(apiServer *api.Server, app *fiber.App, webdavHandler *webd
 
 	// Mount stream handler directly (no Fiber adapter needed)
 	streamHTTPHandler := streamHandler.GetHTTPHandler()
+	zipHTTPHandler := streamHandler.GetZipHTTPHandler()
 
 	// Convert Fiber app to HTTP handler for all other routes
 	fiberHTTPHandler := adaptor.FiberApp(app)
@@ -482,6 +524,13 @@ func createHTTPServer(apiServer *api.Server, app *fiber.App, webdavHandler *webd
 			return
 		}
 
+		// Route directory zip downloads directly so the archive streams out
+		// as it is written
+		if path == "/api/files/zip" {
+			zipHTTPHandler.ServeHTTP(w, r)
+			return
+		}
+
 		// Route SSE log stream directly — bypasses adaptor.FiberApp which
 		// blocks forever on streaming responses (calls Response.Body() which
 		// reads the SSE pipe until EOF that never comes).
@@ -10,10 +10,12 @@ import type {
 	HealthCleanupRequest,
 	HealthCleanupResponse,
 	HealthPriority,
+	HealthReconcileResult,
 	HealthStats,
 	HealthWorkerStatus,
 	ImportHistoryItem,
 	ImportStatusResponse,
+	LibraryStats,
 	LibrarySyncStatus,
 	ManualScanRequest,
 	NzbdavMigrateSymlinksRequest,
@@ -292,6 +294,13 @@ class APIClient {
 		});
 	}
 
+	async moveQueueItem(id: number, position: { before_id: number } | { after_id: number }) {
+		return this.request<QueueItem>(`/queue/${id}/position`, {
+			method: "PATCH",
+			body: JSON.stringify(position),
+		});
+	}
+
 	async bulkUpdateQueueItemPriority(ids: number[], priority: 1 | 2 | 3) {
 		return this.request<{ updated_count: number; skipped_count: number; message: string }>(
 			"/queue/bulk/priority",
@@ -319,6 +328,12 @@ class APIClient {
 		return this.request<QueueStats>("/queue/stats");
 	}
 
+	async recomputeQueueStats() {
+		return this.request<QueueStats>("/queue/stats/recompute", {
+			method: "POST",
+		});
+	}
+
 	async getQueueHistory(days?: number) {
 		const searchParams = new URLSearchParams();
 		if (days) searchParams.set("days", days.toString());
@@ -349,6 +364,21 @@ class APIClient {
 		});
 	}
 
+	async requeueFailedQueue(params?: { category?: string; search?: string; since?: string }) {
+		const searchParams = new URLSearchParams();
+		if (params?.category) searchParams.set("category", params.category);
+		if (params?.search) searchParams.set("search", params.search);
+		if (params?.since) searchParams.set("since", params.since);
+
+		const query = searchParams.toString();
+		return this.request<{ requeued_count: number }>(
+			`/queue/failed/requeue${query ? `?${query}` : ""}`,
+			{
+				method: "POST",
+			},
+		);
+	}
+
 	async clearPendingQueue(olderThan?: string) {
 		const searchParams = new URLSearchParams();
 		if (olderThan) searchParams.set("older_than", olderThan);
@@ -491,6 +521,18 @@ class APIClient {
 		return this.request<HealthWorkerStatus>("/health/worker/status");
 	}
 
+	async pauseHealthWorker() {
+		return this.request<{ status: string }>("/health/worker/pause", {
+			method: "POST",
+		});
+	}
+
+	async resumeHealthWorker() {
+		return this.request<{ status: string }>("/health/worker/resume", {
+			method: "POST",
+		});
+	}
+
 	async getLibrarySyncStatus() {
 		return this.request<LibrarySyncStatus>("/health/library-sync/status");
 	}
@@ -507,6 +549,12 @@ class APIClient {
 		});
 	}
 
+	async reconcileHealthRecords(dryRun = false) {
+		return this.request<HealthReconcileResult>(`/health/reconcile?dry_run=${dryRun}`, {
+			method: "POST",
+		});
+	}
+
 	async getPoolMetrics() {
 		return this.request<PoolMetrics>("/system/pool/metrics");
 	}
@@ -773,6 +821,10 @@ class APIClient {
 		});
 	}
 
+	async getLibraryStats(refresh = false) {
+		return this.request<LibraryStats>(`/system/library-stats${refresh ? "?refresh=true" : ""}`);
+	}
+
 	async getIndexerStats() {
 		return this.request<
 			{
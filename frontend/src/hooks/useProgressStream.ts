@@ -91,6 +91,8 @@ export function useProgressStream(options: UseProgressStreamOptions = {}): UsePr
 								update.status === "queue_changed" ||
 								update.status === "completed" ||
 								update.status === "failed" ||
+								update.status === "processing" ||
+								update.status === "pending" ||
 								update.status === "health_changed"
 							) {
 								return;
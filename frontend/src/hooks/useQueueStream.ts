@@ -92,7 +92,20 @@ export function useQueueStream(options: UseQueueStreamOptions = {}): UseQueueStr
 								return;
 							}
 
-							if (update.status === "completed" || update.status === "failed") {
+							if (update.status === "processing") {
+								setProgress((prev) =>
+									update.queue_id in prev
+										? prev
+										: { ...prev, [update.queue_id]: { percentage: 0 } },
+								);
+								return;
+							}
+
+							if (
+								update.status === "completed" ||
+								update.status === "failed" ||
+								update.status === "pending"
+							) {
 								onQueueChangedRef.current?.();
 								setProgress((prev) => {
 									const next = { ...prev };
@@ -35,6 +35,7 @@ export interface QueueItem {
 	category?: string;
 	relative_path?: string;
 	priority: number;
+	sequence: number; // Manual order within the priority tier; lower is claimed first
 	status: QueueStatus;
 	created_at: string;
 	updated_at: string;
@@ -63,6 +64,7 @@ export interface ImportHistoryItem {
 	file_size: number;
 	completed_at: string;
 	indexer?: string;
+	batch_id?: string;
 }
 
 export interface QueueStats {
@@ -221,6 +223,13 @@ export interface HealthStats {
 	degraded: number;
 }
 
+export interface LibraryStats {
+	total_size: number;
+	files: number;
+	directories: number;
+	computed_at: string;
+}
+
 // Playback-impact classification embedded in FileHealth.error_details JSON.
 // Produced by the hole model (internal/holes): "degraded" files are still
 // playable (streaming zero-fills the missing segments), "failed" are not.
@@ -365,6 +374,15 @@ export interface LibrarySyncStatus {
 	last_sync_result?: LibrarySyncResult;
 }
 
+export interface HealthReconcileResult {
+	missing_records: string[] | null;
+	orphan_records: string[] | null;
+	corrupted_metadata: string[] | null;
+	added: number;
+	removed: number;
+	dry_run: boolean;
+}
+
 // Pool Metrics types
 export interface ProviderStatus {
 	id: string;
@@ -23,6 +23,7 @@ export interface ConfigResponse {
 	providers: ProviderConfig[];
 	nzblnk: NzblnkConfig;
 	network: NetworkConfig;
+	pool: PoolConfig;
 	mount_path: string;
 	mount_type: MountType;
 	api_key?: string;
@@ -59,17 +60,46 @@ export interface NetworkConfig {
 	no_proxy: string;
 }
 
+// NNTP connection pool configuration. acquire_timeout_seconds bounds how long
+// a streaming read waits for a free connection before failing with 503 (0 = no bound).
+export interface PoolConfig {
+	acquire_timeout_seconds: number;
+	circuit_breaker: CircuitBreakerConfig;
+}
+
+// Per-provider read circuit breaker (failure_threshold 0 = disabled).
+export interface CircuitBreakerConfig {
+	failure_threshold: number;
+	window_seconds: number;
+	cooldown_seconds: number;
+}
+
 // Database configuration
 export interface DatabaseConfig {
 	type: string;
 	path: string;
 	dsn: string;
+	maintenance_interval_hours: number;
+	maintenance_after_deleted_rows: number;
 }
 
 // Metadata configuration
 export interface MetadataConfig {
 	root_path: string;
 	delete_source_nzb_on_removal?: boolean;
+	case_insensitive?: boolean;
+	repair_id_symlinks?: boolean;
+	protect_repairing_on_delete?: boolean;
+	corrupted_category_views?: boolean;
+	show_hidden?: boolean;
+	listing_sort?: ListingSort;
+	max_directory_depth?: number;
+	on_path_conflict?: PathConflict;
+	nzb_root?: string;
+	empty_dir_grace_seconds?: number;
+	rename_concurrency?: number;
+	write_retries?: number | null;
+	write_retry_delay_ms?: number;
 	backup: MetadataBackupConfig;
 }
 
@@ -84,12 +114,42 @@ export interface MetadataBackupConfig {
 export interface FailureMaskingConfig {
 	enabled: boolean;
 	threshold: number;
+	auto_unmask_on_healthy: boolean;
 }
 
 // Streaming configuration
 export interface StreamingConfig {
 	max_prefetch: number;
 	failure_masking: FailureMaskingConfig;
+	max_streams_per_ip: number;
+	exempt_loopback_streams: boolean | null;
+	segment_fetch_timeout_seconds: number;
+	nested_read_concurrency: number;
+	read_buffer_size_kb: number;
+	initial_read_ahead_bytes: number;
+	max_warmups: number;
+	warmup_overflow?: WarmupOverflow;
+	tail_wait_ms: number;
+	small_file_threshold: number;
+	max_in_flight_bytes: number;
+	missing_segment_abort_fraction: number;
+	tracker_update_interval_ms: number;
+	prefetch_direction?: PrefetchDirection;
+	extension_filter: ExtensionFilterConfig;
+	content_type_overrides: Record<string, string>;
+	sniff_content_types: boolean;
+}
+
+export type PrefetchDirection = "forward" | "adaptive";
+
+export type WarmupOverflow = "drop" | "queue";
+
+export type ExtensionFilterMode = "" | "allow" | "deny";
+
+// Extension filter configuration
+export interface ExtensionFilterConfig {
+	mode: ExtensionFilterMode;
+	extensions: string[];
 }
 
 // Segment cache configuration
@@ -106,6 +166,7 @@ export interface HealthConfig {
 	library_dir?: string;
 	cleanup_orphaned_metadata?: boolean;
 	check_interval_seconds?: number;
+	interval_jitter?: number; // Max random deviation of each check interval, in percent (0-100)
 	max_connections_for_health_checks?: number;
 	check_batch_size?: number; // Files fetched and swept together per health-check cycle
 	max_concurrent_jobs?: number; // Max concurrent health check jobs
@@ -118,6 +179,7 @@ export interface HealthConfig {
 	verify_data?: boolean; // Verify 1 byte of data for each segment
 	read_timeout_seconds?: number; // Timeout for data verification
 	acceptable_missing_segments_percentage?: number;
+	filesystem_timeout_seconds?: number;
 	// SABnzbd category names whose files are never registered for health checking
 	// by the library-sync discovery pass. Matching is by the category's directory.
 	excluded_categories?: string[];
@@ -126,6 +188,16 @@ export interface HealthConfig {
 	// (non-degraded) corruption: "repair" (default) triggers an Arr rescan;
 	// "delete" removes the file and cleans up now-empty parent directories instead.
 	corruption_action?: "repair" | "delete";
+	peak_hours?: HealthPeakHoursConfig;
+	// How files marked corrupted appear outside the admin view, e.g. to an ARR
+	// scanning its import directory: hidden entirely or shown as empty files.
+	arr_corrupt_view?: ArrCorruptView;
+}
+
+// Lowers health-check concurrency inside daily windows
+export interface HealthPeakHoursConfig {
+	windows?: string[]; // "HH:MM-HH:MM" in server local time; may wrap midnight
+	max_concurrent_jobs?: number; // 0 disables the throttle
 }
 
 export interface RepairConfig {
@@ -220,6 +292,25 @@ export interface FuseConfig {
 // Import strategy type
 export type ImportStrategy = "NONE" | "SYMLINK" | "STRM";
 
+export type ListingSort = "none" | "name" | "natural" | "mtime";
+
+export type PathConflict = "" | "prefer_dir" | "prefer_file" | "error";
+
+export type ArrCorruptView = "" | "hide" | "zero";
+
+export type PathCollision = "" | "overwrite" | "skip" | "version";
+
+export type VerificationFileMode = "" | "retain" | "include" | "drop";
+
+export type FilenameEncoding =
+	| ""
+	| "auto"
+	| "utf8"
+	| "cp437"
+	| "cp850"
+	| "windows-1252"
+	| "windows-1251";
+
 // Import configuration
 export interface ImportConfig {
 	max_processor_workers: number;
@@ -233,8 +324,23 @@ export interface ImportConfig {
 	watch_dir?: string | null;
 	watch_interval_seconds?: number | null;
 	allow_nested_rar_extraction?: boolean;
+	keep_empty_archive_files?: boolean;
+	preserve_archive_structure?: boolean;
 	rename_to_nzb_name?: boolean;
 	filter_sample_files?: boolean;
+	verify_readback?: boolean;
+	use_par2_names?: boolean;
+	compute_fingerprint?: boolean;
+	max_files?: number;
+	max_concurrent_analyses?: number;
+	max_total_bytes?: number;
+	quarantine_over_limit?: boolean;
+	retry_transient_failures?: boolean;
+	processing_reclaim_minutes?: number | null;
+	retain_source_nzb?: boolean;
+	on_path_collision?: PathCollision;
+	filename_encoding?: FilenameEncoding;
+	verification_files?: VerificationFileMode;
 	failed_item_retention_hours?: number | null;
 	history_retention_days?: number | null;
 }
@@ -340,6 +446,7 @@ export interface ConfigUpdateRequest {
 	providers?: ProviderUpdateRequest[];
 	nzblnk?: NzblnkConfig;
 	network?: NetworkConfig;
+	pool?: Partial<PoolConfig>;
 	mount_path?: string;
 	mount_type?: MountType;
 	profiler_enabled?: boolean;
@@ -375,6 +482,19 @@ export interface DatabaseUpdateRequest {
 export interface MetadataUpdateRequest {
 	root_path?: string;
 	delete_source_nzb_on_removal?: boolean;
+	case_insensitive?: boolean;
+	repair_id_symlinks?: boolean;
+	protect_repairing_on_delete?: boolean;
+	corrupted_category_views?: boolean;
+	show_hidden?: boolean;
+	listing_sort?: ListingSort;
+	max_directory_depth?: number;
+	on_path_conflict?: PathConflict;
+	nzb_root?: string;
+	empty_dir_grace_seconds?: number;
+	rename_concurrency?: number;
+	write_retries?: number | null;
+	write_retry_delay_ms?: number;
 	backup?: MetadataBackupConfig;
 }
 
@@ -382,6 +502,23 @@ export interface MetadataUpdateRequest {
 export interface StreamingUpdateRequest {
 	max_prefetch?: number;
 	failure_masking?: Partial<FailureMaskingConfig>;
+	max_streams_per_ip?: number;
+	exempt_loopback_streams?: boolean;
+	segment_fetch_timeout_seconds?: number;
+	nested_read_concurrency?: number;
+	read_buffer_size_kb?: number;
+	initial_read_ahead_bytes?: number;
+	max_warmups?: number;
+	warmup_overflow?: WarmupOverflow;
+	tail_wait_ms?: number;
+	small_file_threshold?: number;
+	max_in_flight_bytes?: number;
+	missing_segment_abort_fraction?: number;
+	tracker_update_interval_ms?: number;
+	prefetch_direction?: PrefetchDirection;
+	extension_filter?: Partial<ExtensionFilterConfig>;
+	content_type_overrides?: Record<string, string>;
+	sniff_content_types?: boolean;
 }
 
 // Health update request
@@ -390,6 +527,7 @@ export interface HealthUpdateRequest {
 	library_dir?: string;
 	cleanup_orphaned_metadata?: boolean;
 	check_interval_seconds?: number; // Interval in seconds (optional)
+	interval_jitter?: number; // Max random deviation of each check interval, in percent (0-100)
 	max_connections_for_health_checks?: number;
 	check_batch_size?: number; // Files fetched and swept together per health-check cycle
 	max_concurrent_jobs?: number; // Max concurrent health check jobs
@@ -402,8 +540,11 @@ export interface HealthUpdateRequest {
 	resolve_repair_on_import?: boolean;
 	verify_data?: boolean;
 	acceptable_missing_segments_percentage?: number;
+	filesystem_timeout_seconds?: number;
 	repair?: Partial<RepairConfig>;
 	corruption_action?: "repair" | "delete";
+	peak_hours?: HealthPeakHoursConfig;
+	arr_corrupt_view?: ArrCorruptView;
 }
 
 // RClone update request
@@ -469,8 +610,23 @@ export interface ImportUpdateRequest {
 	watch_dir?: string | null;
 	watch_interval_seconds?: number | null;
 	allow_nested_rar_extraction?: boolean;
+	keep_empty_archive_files?: boolean;
+	preserve_archive_structure?: boolean;
 	rename_to_nzb_name?: boolean;
 	filter_sample_files?: boolean;
+	verify_readback?: boolean;
+	use_par2_names?: boolean;
+	compute_fingerprint?: boolean;
+	max_files?: number;
+	max_concurrent_analyses?: number;
+	max_total_bytes?: number;
+	quarantine_over_limit?: boolean;
+	retry_transient_failures?: boolean;
+	processing_reclaim_minutes?: number | null;
+	retain_source_nzb?: boolean;
+	on_path_collision?: PathCollision;
+	filename_encoding?: FilenameEncoding;
+	verification_files?: VerificationFileMode;
 	history_retention_days?: number | null;
 }
 
@@ -6,6 +6,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/xml"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
@@ -13,11 +14,14 @@ import (
 	"path/filepath"
 	"regexp"
 	"slices"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/javi11/altmount/internal/importer"
 	metapb "github.com/javi11/altmount/internal/metadata/proto"
+	"github.com/javi11/altmount/internal/nzbfilesystem"
 )
 
 // handleGetFileMetadata handles GET /files/info requests
@@ -92,7 +96,7 @@ func (s *Server) convertToFileMetadataResponse(metadata *metapb.FileMetadata) *F
 			VolumeIndex:     i,
 			InnerLength:     ns.InnerLength,
 			InnerVolumeSize: ns.InnerVolumeSize,
-			Encrypted:       len(ns.AesKey) > 0,
+			Encrypted:       len(ns.AesKey) > 0 || ns.Encryption != metapb.Encryption_NONE,
 			SegmentCount:    len(ns.Segments),
 			Segments:        segs,
 		})
@@ -586,3 +590,145 @@ func (s *Server) handleBatchExportNZB(c *fiber.Ctx) error {
 
 	return nil
 }
+
+// ReanalyzeNestedRequest is the body of POST /files/reanalyze-nested.
+type ReanalyzeNestedRequest struct {
+	Path     string `json:"path"`
+	DryRun   bool   `json:"dry_run"`
+	Password string `json:"password,omitempty"`
+}
+
+// handleReanalyzeNested handles POST /files/reanalyze-nested requests
+//
+//	@Summary		Re-run nested archive detection
+//	@Description	Re-analyzes the source NZB of an archive-imported file with nested RAR detection and rewrites its metadata when the segment layout changed.
+//	@Tags			Files
+//	@Accept			json
+//	@Produce		json
+//	@Param			body	body		ReanalyzeNestedRequest	true	"File to re-analyze"
+//	@Success		200		{object}	APIResponse{data=importer.NestedReanalysisResult}
+//	@Failure		400		{object}	APIResponse
+//	@Failure		404		{object}	APIResponse
+//	@Failure		500		{object}	APIResponse
+//	@Security		BearerAuth
+//	@Router			/files/reanalyze-nested [post]
+func (s *Server) handleReanalyzeNested(c *fiber.Ctx) error {
+	if s.importerService == nil {
+		return RespondInternalError(c, "Importer service not available", "")
+	}
+
+	var req ReanalyzeNestedRequest
+	if err := c.BodyParser(&req); err != nil {
+		return RespondBadRequest(c, "Invalid request body", err.Error())
+	}
+	if req.Path == "" {
+		return RespondBadRequest(c, "Path is required", "MISSING_PATH")
+	}
+
+	meta, err := s.metadataReader.GetFileMetadata(req.Path)
+	if err != nil {
+		return RespondInternalError(c, "Failed to read metadata", err.Error())
+	}
+	if meta == nil {
+		return RespondNotFound(c, "File metadata", "")
+	}
+
+	result, err := s.importerService.ReanalyzeNestedArchive(c.Context(), req.Path, importer.NestedReanalysisOptions{
+		DryRun:   req.DryRun,
+		Password: req.Password,
+	})
+	if err != nil {
+		if errors.Is(err, importer.ErrNotArchiveImport) || errors.Is(err, importer.ErrNestedExtractionDisabled) ||
+			errors.Is(err, importer.ErrReanalysisNoMatch) {
+			return RespondBadRequest(c, "Cannot re-analyze file", err.Error())
+		}
+		return RespondInternalError(c, "Failed to re-analyze file", err.Error())
+	}
+
+	return RespondSuccess(c, result)
+}
+
+// PathConflictsResponse lists virtual paths that exist both as a directory
+// and as a metadata file.
+type PathConflictsResponse struct {
+	Paths []string `json:"paths"`
+}
+
+// handleGetPathConflicts handles GET /files/path-conflicts requests
+//
+//	@Summary		Scan for file/directory path conflicts
+//	@Description	Walks the metadata tree and reports every path that exists both as a directory and as a file. Which of the two is served is set by metadata.on_path_conflict.
+//	@Tags			Files
+//	@Produce		json
+//	@Success		200	{object}	APIResponse{data=PathConflictsResponse}
+//	@Failure		500	{object}	APIResponse
+//	@Security		BearerAuth
+//	@Router			/files/path-conflicts [get]
+func (s *Server) handleGetPathConflicts(c *fiber.Ctx) error {
+	if s.metadataService == nil {
+		return RespondInternalError(c, "Metadata service not available", "")
+	}
+
+	paths, err := s.metadataService.ScanPathConflicts(c.Context())
+	if err != nil {
+		return RespondInternalError(c, "Failed to scan for path conflicts", err.Error())
+	}
+	if paths == nil {
+		paths = []string{}
+	}
+
+	return RespondSuccess(c, PathConflictsResponse{Paths: paths})
+}
+
+// defaultDiagnoseReadBytes is how much of a file /files/diagnose-read reads
+// when the request gives no end offset.
+const defaultDiagnoseReadBytes = 10 << 20
+
+// handleDiagnoseRead handles GET /files/diagnose-read requests
+//
+//	@Summary		Test-read a file
+//	@Description	Reads a byte range of a file through the normal streaming reader, one segment at a time, and reports per-segment fetch timing, provider, cache hits and errors. Without end, 10 MiB from start are read; end=-1 reads to the end of the file. At most 64 MiB are read per request.
+//	@Tags			Files
+//	@Produce		json
+//	@Param			path	query		string	true	"Virtual path to the file"
+//	@Param			start	query		int		false	"First byte to read (default 0)"
+//	@Param			end		query		int		false	"Last byte to read, inclusive"
+//	@Success		200		{object}	APIResponse{data=nzbfilesystem.ReadDiagnosis}
+//	@Failure		400		{object}	APIResponse
+//	@Failure		404		{object}	APIResponse
+//	@Failure		500		{object}	APIResponse
+//	@Security		BearerAuth
+//	@Router			/files/diagnose-read [get]
+func (s *Server) handleDiagnoseRead(c *fiber.Ctx) error {
+	if s.nzbFilesystem == nil {
+		return RespondInternalError(c, "Filesystem not available", "")
+	}
+
+	path := c.Query("path")
+	if path == "" {
+		return RespondBadRequest(c, "Path parameter is required", "MISSING_PATH")
+	}
+	start, err := strconv.ParseInt(c.Query("start", "0"), 10, 64)
+	if err != nil {
+		return RespondBadRequest(c, "Invalid start offset", err.Error())
+	}
+	end := start + defaultDiagnoseReadBytes - 1
+	if v := c.Query("end"); v != "" {
+		if end, err = strconv.ParseInt(v, 10, 64); err != nil {
+			return RespondBadRequest(c, "Invalid end offset", err.Error())
+		}
+	}
+
+	diag, err := s.nzbFilesystem.DiagnoseRead(c.Context(), path, start, end)
+	if err != nil {
+		switch {
+		case errors.Is(err, os.ErrNotExist):
+			return RespondNotFound(c, "File", "")
+		case errors.Is(err, nzbfilesystem.ErrInvalidRange), errors.Is(err, nzbfilesystem.ErrCannotReadDirectory):
+			return RespondBadRequest(c, "Cannot read this range", err.Error())
+		}
+		return RespondInternalError(c, "Failed to read file", err.Error())
+	}
+
+	return RespondSuccess(c, diag)
+}
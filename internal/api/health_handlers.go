@@ -6,6 +6,7 @@ import (
 	"log/slog"
 	"os"
 	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -161,6 +162,36 @@ func (s *Server) handleGetHealth(c *fiber.Ctx) error {
 	return RespondSuccess(c, response)
 }
 
+// handleGetHealthDetails handles GET /api/health/details
+//
+//	@Summary		Get file health details
+//	@Description	Returns a file's current health status and repair attempt details.
+//	@Tags			Health
+//	@Produce		json
+//	@Param			path	query		string	true	"File path"
+//	@Success		200		{object}	APIResponse{data=database.FileHealthDetails}
+//	@Failure		400		{object}	APIResponse
+//	@Failure		404		{object}	APIResponse
+//	@Security		BearerAuth
+//	@Router			/health/details [get]
+func (s *Server) handleGetHealthDetails(c *fiber.Ctx) error {
+	filePath := c.Query("path")
+	if filePath == "" {
+		return RespondBadRequest(c, "File path is required", "")
+	}
+
+	details, err := s.healthRepo.GetFileHealthDetails(c.Context(), filePath)
+	if err != nil {
+		return RespondInternalError(c, "Failed to retrieve health details", err.Error())
+	}
+
+	if details == nil {
+		return RespondNotFound(c, "Health record", "")
+	}
+
+	return RespondSuccess(c, details)
+}
+
 // handleDeleteHealth handles DELETE /api/health/{id}
 //
 //	@Summary		Delete health record
@@ -619,6 +650,74 @@ func (s *Server) handleListCorrupted(c *fiber.Ctx) error {
 	return RespondSuccessWithMeta(c, response, meta)
 }
 
+// handleListUnhealthy handles GET /api/health/unhealthy
+//
+//	@Summary		List unhealthy files
+//	@Description	Returns a paginated list of files in unhealthy health statuses. Defaults to corrupted, repair_triggered and pending.
+//	@Tags			Health
+//	@Produce		json
+//	@Param			status		query		string	false	"Comma-separated statuses (corrupted, repair_triggered, pending)"
+//	@Param			search		query		string	false	"Search in file, library and source NZB paths"
+//	@Param			sort_by		query		string	false	"Sort field (updated_at, created_at, file_path, status, last_checked, retry_count, repair_retry_count)"
+//	@Param			sort_order	query		string	false	"Sort order (asc, desc)"
+//	@Param			limit		query		int		false	"Page size (default 50)"
+//	@Param			offset		query		int		false	"Page offset"
+//	@Success		200			{object}	APIResponse{data=[]HealthItemResponse,meta=APIMeta}
+//	@Failure		400			{object}	APIResponse
+//	@Failure		500			{object}	APIResponse
+//	@Security		BearerAuth
+//	@Router			/health/unhealthy [get]
+func (s *Server) handleListUnhealthy(c *fiber.Ctx) error {
+	pagination := ParsePaginationFiber(c)
+	search := c.Query("search")
+
+	sortBy := c.Query("sort_by", "updated_at")
+	switch sortBy {
+	case "updated_at", "created_at", "file_path", "status", "last_checked", "retry_count", "repair_retry_count":
+	default:
+		sortBy = "updated_at"
+	}
+	sortOrder := c.Query("sort_order", "desc")
+	if sortOrder != "asc" && sortOrder != "desc" {
+		sortOrder = "desc"
+	}
+
+	var statuses []database.HealthStatus
+	if statusStr := c.Query("status"); statusStr != "" {
+		for part := range strings.SplitSeq(statusStr, ",") {
+			status := database.HealthStatus(strings.TrimSpace(part))
+			if !slices.Contains(database.UnhealthyStatuses, status) {
+				return RespondValidationError(c, fmt.Sprintf("Invalid status filter: '%s'", part), "Valid values: corrupted, repair_triggered, pending")
+			}
+			statuses = append(statuses, status)
+		}
+	}
+
+	items, err := s.healthRepo.ListUnhealthyFiles(c.Context(), statuses, search, pagination.Limit, pagination.Offset, sortBy, sortOrder)
+	if err != nil {
+		return RespondInternalError(c, "Failed to retrieve unhealthy files", err.Error())
+	}
+
+	totalCount, err := s.healthRepo.CountUnhealthyFiles(c.Context(), statuses, search)
+	if err != nil {
+		return RespondInternalError(c, "Failed to count unhealthy files", err.Error())
+	}
+
+	response := make([]*HealthItemResponse, len(items))
+	for i, item := range items {
+		response[i] = ToHealthItemResponse(item)
+	}
+
+	meta := &APIMeta{
+		Count:  len(response),
+		Limit:  pagination.Limit,
+		Offset: pagination.Offset,
+		Total:  totalCount,
+	}
+
+	return RespondSuccessWithMeta(c, response, meta)
+}
+
 // handleGetHealthStats handles GET /api/health/stats
 //
 //	@Summary		Get health statistics
@@ -909,6 +1008,117 @@ func (s *Server) handleGetHealthWorkerStatus(c *fiber.Ctx) error {
 	return RespondSuccess(c, response)
 }
 
+// handleHealthWorkerPause handles POST /api/health/worker/pause
+//
+//	@Summary		Pause health worker
+//	@Description	Stops the health worker from starting new check cycles without stopping it. A cycle in progress finishes.
+//	@Tags			Health
+//	@Produce		json
+//	@Success		200	{object}	APIResponse
+//	@Failure		404	{object}	APIResponse
+//	@Failure		409	{object}	APIResponse
+//	@Security		BearerAuth
+//	@Router			/health/worker/pause [post]
+func (s *Server) handleHealthWorkerPause(c *fiber.Ctx) error {
+	if s.healthWorker == nil {
+		return RespondNotFound(c, "Health worker", "Health worker is not configured or not running")
+	}
+
+	if err := s.healthWorker.Pause(c.Context()); err != nil {
+		return RespondConflict(c, "Failed to pause health worker", err.Error())
+	}
+
+	return RespondSuccess(c, fiber.Map{"status": string(s.healthWorker.GetStats().Status)})
+}
+
+// handleHealthWorkerResume handles POST /api/health/worker/resume
+//
+//	@Summary		Resume health worker
+//	@Description	Lets a paused health worker start check cycles again.
+//	@Tags			Health
+//	@Produce		json
+//	@Success		200	{object}	APIResponse
+//	@Failure		404	{object}	APIResponse
+//	@Failure		409	{object}	APIResponse
+//	@Security		BearerAuth
+//	@Router			/health/worker/resume [post]
+func (s *Server) handleHealthWorkerResume(c *fiber.Ctx) error {
+	if s.healthWorker == nil {
+		return RespondNotFound(c, "Health worker", "Health worker is not configured or not running")
+	}
+
+	if err := s.healthWorker.Resume(c.Context()); err != nil {
+		return RespondConflict(c, "Failed to resume health worker", err.Error())
+	}
+
+	return RespondSuccess(c, fiber.Map{"status": string(s.healthWorker.GetStats().Status)})
+}
+
+// handleGetFailureThreshold handles GET /api/health/failure-masking/threshold
+//
+//	@Summary		Get failure-masking threshold
+//	@Description	Returns the streaming failure-masking threshold in effect, the configured value, and any runtime override.
+//	@Tags			Health
+//	@Produce		json
+//	@Success		200	{object}	APIResponse{data=FailureThresholdResponse}
+//	@Security		BearerAuth
+//	@Router			/health/failure-masking/threshold [get]
+func (s *Server) handleGetFailureThreshold(c *fiber.Ctx) error {
+	response, err := s.failureThresholdResponse(c.Context())
+	if err != nil {
+		return RespondInternalError(c, "Failed to get failure threshold", err.Error())
+	}
+	return RespondSuccess(c, response)
+}
+
+// handleSetFailureThreshold handles PUT /api/health/failure-masking/threshold
+//
+//	@Summary		Set failure-masking threshold
+//	@Description	Persists a runtime streaming failure-masking threshold that overrides the configured one without a config reload. A threshold of 0 clears the override.
+//	@Tags			Health
+//	@Accept			json
+//	@Produce		json
+//	@Param			body	body		FailureThresholdRequest	true	"New threshold"
+//	@Success		200		{object}	APIResponse{data=FailureThresholdResponse}
+//	@Failure		400		{object}	APIResponse
+//	@Security		BearerAuth
+//	@Router			/health/failure-masking/threshold [put]
+func (s *Server) handleSetFailureThreshold(c *fiber.Ctx) error {
+	var req FailureThresholdRequest
+	if err := c.BodyParser(&req); err != nil {
+		return RespondBadRequest(c, "Invalid request body", err.Error())
+	}
+	if req.Threshold < 0 {
+		return RespondValidationError(c, "Threshold must be non-negative", "")
+	}
+
+	if err := s.healthRepo.SetStreamingFailureThreshold(c.Context(), req.Threshold); err != nil {
+		return RespondInternalError(c, "Failed to set failure threshold", err.Error())
+	}
+
+	response, err := s.failureThresholdResponse(c.Context())
+	if err != nil {
+		return RespondInternalError(c, "Failed to get failure threshold", err.Error())
+	}
+	return RespondSuccess(c, response)
+}
+
+// failureThresholdResponse combines the configured threshold with the runtime override.
+func (s *Server) failureThresholdResponse(ctx context.Context) (FailureThresholdResponse, error) {
+	configured := s.configManager.GetConfig().Streaming.FailureMasking.Threshold
+	response := FailureThresholdResponse{Threshold: configured, Configured: configured}
+
+	override, ok, err := s.healthRepo.GetStreamingFailureThreshold(ctx)
+	if err != nil {
+		return response, err
+	}
+	if ok {
+		response.Threshold = override
+		response.Override = &override
+	}
+	return response, nil
+}
+
 // handleDirectHealthCheck handles POST /api/health/{id}/check-now
 //
 //	@Summary		Trigger immediate health check
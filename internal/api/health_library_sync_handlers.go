@@ -1,6 +1,7 @@
 package api
 
 import (
+	"errors"
 	"log/slog"
 
 	"github.com/gofiber/fiber/v2"
@@ -67,6 +68,22 @@ func (h *LibrarySyncHandlers) handleDryRunLibrarySync(c *fiber.Ctx) error {
 	return RespondSuccess(c, apiResult)
 }
 
+// handleReconcileHealthRecords handles POST /api/health/reconcile
+func (h *LibrarySyncHandlers) handleReconcileHealthRecords(c *fiber.Ctx) error {
+	dryRun := c.QueryBool("dry_run", false)
+
+	result, err := h.librarySyncWorker.ReconcileHealthRecords(c.Context(), dryRun)
+	if errors.Is(err, health.ErrLibrarySyncRunning) {
+		return RespondConflict(c, "Library sync is running", err.Error())
+	}
+	if err != nil {
+		slog.ErrorContext(c.Context(), "Failed to reconcile health records", "error", err)
+		return RespondInternalError(c, "Failed to reconcile health records", err.Error())
+	}
+
+	return RespondSuccess(c, result)
+}
+
 // handleGetSyncNeeded handles GET /api/health/library-sync/needed
 // Returns whether a library sync is needed due to configuration changes
 func (h *LibrarySyncHandlers) handleGetSyncNeeded(c *fiber.Ctx) error {
@@ -219,10 +219,11 @@ func (s *Server) handleManualImportFile(c *fiber.Ctx) error {
 //	@Description	Returns a paginated list of completed import records.
 //	@Tags			Import
 //	@Produce		json
-//	@Param			limit	query		int	false	"Page size (default 50)"
-//	@Param			offset	query		int	false	"Page offset"
-//	@Success		200		{object}	APIResponse{data=[]ImportHistoryResponse,meta=APIMeta}
-//	@Failure		500		{object}	APIResponse
+//	@Param			limit		query		int		false	"Page size (default 50)"
+//	@Param			offset		query		int		false	"Page offset"
+//	@Param			batch_id	query		string	false	"Only return files imported from this batch"
+//	@Success		200			{object}	APIResponse{data=[]ImportHistoryResponse,meta=APIMeta}
+//	@Failure		500			{object}	APIResponse
 //	@Security		BearerAuth
 //	@Router			/import/history [get]
 func (s *Server) handleGetImportHistory(c *fiber.Ctx) error {
@@ -233,7 +234,7 @@ func (s *Server) handleGetImportHistory(c *fiber.Ctx) error {
 		}
 	}
 
-	history, err := s.queueRepo.ListImportHistory(c.Context(), limit, 0, "", "")
+	history, err := s.queueRepo.ListImportHistory(c.Context(), limit, 0, "", "", c.Query("batch_id"))
 	if err != nil {
 		return RespondInternalError(c, "Failed to list import history", err.Error())
 	}
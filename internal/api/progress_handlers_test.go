@@ -0,0 +1,81 @@
+package api
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/javi11/altmount/internal/config"
+	"github.com/javi11/altmount/internal/progress"
+)
+
+type queueStreamEvent struct {
+	Type string                  `json:"type"`
+	Data progress.ProgressUpdate `json:"data"`
+}
+
+func TestServeQueueSSE_StatusChange(t *testing.T) {
+	broadcaster := progress.NewProgressBroadcaster()
+	s := &Server{
+		configManager:       &mockConfigManager{cfg: config.DefaultConfig()},
+		progressBroadcaster: broadcaster,
+	}
+	srv := httptest.NewServer(http.HandlerFunc(s.ServeQueueSSE))
+	t.Cleanup(srv.Close)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+	require.NoError(t, err)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	events := make(chan string)
+	go func() {
+		defer close(events)
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			if data, ok := strings.CutPrefix(scanner.Text(), "data: "); ok {
+				events <- data
+			}
+		}
+	}()
+
+	next := func() queueStreamEvent {
+		t.Helper()
+		select {
+		case data, ok := <-events:
+			require.True(t, ok, "stream closed")
+			var ev queueStreamEvent
+			require.NoError(t, json.Unmarshal([]byte(data), &ev))
+			return ev
+		case <-ctx.Done():
+			t.Fatal("timed out waiting for SSE event")
+			return queueStreamEvent{}
+		}
+	}
+
+	// The subscription is registered before the initial payload is written.
+	require.Equal(t, "initial", next().Type)
+
+	broadcaster.NotifyStatus(42, "processing")
+	ev := next()
+	assert.Equal(t, "update", ev.Type)
+	assert.Equal(t, 42, ev.Data.QueueID)
+	assert.Equal(t, "processing", ev.Data.Status)
+
+	broadcaster.NotifyComplete(42, "completed")
+	ev = next()
+	assert.Equal(t, 42, ev.Data.QueueID)
+	assert.Equal(t, "completed", ev.Data.Status)
+}
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"errors"
 	"fmt"
 	"html"
 	"io"
@@ -61,7 +62,7 @@ func transformQueueError(err *string) string {
 //	@Produce		json
 //	@Param			status		query	string	false	"Filter by status"			Enums(pending,processing,completed,failed)
 //	@Param			search		query	string	false	"Search by filename"
-//	@Param			sort_by		query	string	false	"Sort field"				Enums(created_at,updated_at,status,nzb_path)
+//	@Param			sort_by		query	string	false	"Sort field"				Enums(created_at,updated_at,status,nzb_path,sequence)
 //	@Param			sort_order	query	string	false	"Sort direction"			Enums(asc,desc)
 //	@Param			since		query	string	false	"ISO8601 timestamp filter"
 //	@Param			limit		query	int		false	"Page size (default 50)"
@@ -102,6 +103,7 @@ func (s *Server) handleListQueue(c *fiber.Ctx) error {
 		"updated_at": true,
 		"status":     true,
 		"nzb_path":   true,
+		"sequence":   true,
 	}
 	if !validSortFields[sortBy] {
 		sortBy = "updated_at"
@@ -393,6 +395,25 @@ func (s *Server) handleGetQueueStats(c *fiber.Ctx) error {
 	return RespondSuccess(c, response)
 }
 
+// handleRecomputeQueueStats handles POST /api/queue/stats/recompute
+//
+//	@Summary		Recompute queue statistics
+//	@Description	Rebuilds the persisted queue statistics from the current queue, fixing drifted counts and averages.
+//	@Tags			Queue
+//	@Produce		json
+//	@Success		200	{object}	APIResponse{data=QueueStatsResponse}
+//	@Failure		500	{object}	APIResponse
+//	@Security		BearerAuth
+//	@Router			/queue/stats/recompute [post]
+func (s *Server) handleRecomputeQueueStats(c *fiber.Ctx) error {
+	stats, err := s.queueRepo.RecomputeQueueStats(c.Context())
+	if err != nil {
+		return RespondInternalError(c, "Failed to recompute queue statistics", err.Error())
+	}
+
+	return RespondSuccess(c, ToQueueStatsResponse(stats))
+}
+
 // handleGetQueueHistoricalStats handles GET /api/queue/stats/history
 //
 //	@Summary		Get historical queue statistics
@@ -483,6 +504,44 @@ func (s *Server) handleClearFailedQueue(c *fiber.Ctx) error {
 	return RespondSuccess(c, fiber.Map{"removed_count": count})
 }
 
+// handleRequeueFailedQueue handles POST /api/queue/failed/requeue
+//
+//	@Summary		Requeue failed queue items
+//	@Description	Resets every failed queue item matching the filters to pending with cleared retry counts.
+//	@Tags			Queue
+//	@Produce		json
+//	@Param			category	query		string	false	"Only items in this category"
+//	@Param			search		query		string	false	"Only items whose path contains this text"
+//	@Param			since		query		string	false	"Only items that failed at or after this time (RFC3339)"
+//	@Success		200			{object}	APIResponse
+//	@Failure		422			{object}	APIResponse
+//	@Failure		500			{object}	APIResponse
+//	@Security		BearerAuth
+//	@Router			/queue/failed/requeue [post]
+func (s *Server) handleRequeueFailedQueue(c *fiber.Ctx) error {
+	filter := database.RequeueFilter{
+		Category: c.Query("category"),
+		Search:   c.Query("search"),
+	}
+
+	since, err := ParseTimeParamFiber(c, "since")
+	if err != nil {
+		return RespondValidationError(c, "Invalid since parameter", err.Error())
+	}
+	filter.Since = since
+
+	count, err := s.queueRepo.RequeueFailed(c.Context(), filter)
+	if err != nil {
+		return RespondInternalError(c, "Failed to requeue failed queue items", err.Error())
+	}
+
+	if count > 0 && s.progressBroadcaster != nil {
+		s.progressBroadcaster.BroadcastQueueChanged()
+	}
+
+	return RespondSuccess(c, fiber.Map{"requeued_count": count})
+}
+
 // handleClearPendingQueue handles DELETE /api/queue/pending
 //
 //	@Summary		Clear pending queue items
@@ -1278,6 +1337,64 @@ func (s *Server) handleUpdateQueueItemPriority(c *fiber.Ctx) error {
 	return RespondSuccess(c, ToQueueItemResponse(updated))
 }
 
+// handleMoveQueueItem handles PATCH /api/queue/{id}/position
+//
+//	@Summary		Reorder queue item
+//	@Description	Moves a queue item directly before or after another one in claim order. The moved item adopts the target's priority. Exactly one of before_id and after_id must be set.
+//	@Tags			Queue
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		int								true	"Queue item ID"
+//	@Param			body	body		object{before_id=int,after_id=int}	true	"Target position"
+//	@Success		200		{object}	APIResponse{data=QueueItemResponse}
+//	@Failure		400		{object}	APIResponse
+//	@Failure		404		{object}	APIResponse
+//	@Failure		409		{object}	APIResponse
+//	@Security		BearerAuth
+//	@Router			/queue/{id}/position [patch]
+func (s *Server) handleMoveQueueItem(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return RespondBadRequest(c, "Invalid queue item ID", "ID must be a valid integer")
+	}
+
+	var req struct {
+		BeforeID *int64 `json:"before_id"`
+		AfterID  *int64 `json:"after_id"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return RespondBadRequest(c, "Invalid request body", err.Error())
+	}
+	if (req.BeforeID == nil) == (req.AfterID == nil) {
+		return RespondValidationError(c, "Invalid position", "Set exactly one of before_id or after_id")
+	}
+
+	if req.BeforeID != nil {
+		err = s.queueRepo.MoveQueueItemBefore(c.Context(), id, *req.BeforeID)
+	} else {
+		err = s.queueRepo.MoveQueueItemAfter(c.Context(), id, *req.AfterID)
+	}
+	switch {
+	case errors.Is(err, database.ErrQueueItemNotFound):
+		return RespondNotFound(c, "Queue item", err.Error())
+	case errors.Is(err, database.ErrQueueItemProcessing):
+		return RespondConflict(c, "Cannot reorder item currently being processed", "")
+	case err != nil:
+		return RespondBadRequest(c, "Failed to reorder queue item", err.Error())
+	}
+
+	if s.progressBroadcaster != nil {
+		s.progressBroadcaster.BroadcastQueueChanged()
+	}
+
+	updated, err := s.queueRepo.GetQueueItem(c.Context(), id)
+	if err != nil {
+		return RespondInternalError(c, "Failed to retrieve updated queue item", err.Error())
+	}
+
+	return RespondSuccess(c, ToQueueItemResponse(updated))
+}
+
 // handleBulkUpdateQueuePriority handles PATCH /api/queue/bulk/priority
 //
 //	@Summary		Bulk update queue item priorities
@@ -1362,15 +1479,9 @@ func (s *Server) handleDownloadNZB(c *fiber.Ctx) error {
 	if resolveErr != nil {
 		// Raw .nzb is gone (deleted after successful import).
 		// Reconstruct the .nzbz path the same way processor.go writes it:
-		//   configDir/.nzbs/{sanitizedCategory}/{queueID}-{nzbBasename}.nzbz
+		//   nzbRoot/{sanitizedCategory}/{queueID}-{nzbBasename}.nzbz
 		if s.metadataService != nil && s.configManager != nil {
-			cfg := s.configManager.GetConfigGetter()()
-			configDir := filepath.Dir(cfg.Database.Path)
-			if !filepath.IsAbs(configDir) {
-				if abs, absErr := filepath.Abs(configDir); absErr == nil {
-					configDir = abs
-				}
-			}
+			nzbRoot := s.configManager.GetConfigGetter()().GetNzbRoot()
 			var categoryStr string
 			if item.Category != nil && *item.Category != "" {
 				categoryStr = strings.ReplaceAll(*item.Category, `\`, "/")
@@ -1383,7 +1494,7 @@ func (s *Server) handleDownloadNZB(c *fiber.Ctx) error {
 				}
 			}
 			nzbBase := nzbtrim.TrimNzbExtension(filepath.Base(item.NzbPath))
-			storeRef := filepath.Join(configDir, ".nzbs", categoryStr, fmt.Sprintf("%d-%s.nzbz", item.ID, nzbBase))
+			storeRef := filepath.Join(nzbRoot, categoryStr, fmt.Sprintf("%d-%s.nzbz", item.ID, nzbBase))
 			nzbXML, err := s.metadataService.Store().RegenerateNZB(storeRef)
 			if err != nil {
 				return RespondInternalError(c, "Failed to regenerate NZB from store", err.Error())
@@ -1259,46 +1259,14 @@ func (s *Server) parseSABnzbdPriority(priority string) database.QueuePriority {
 
 // buildCategoryPath builds the directory path for a category
 func (s *Server) buildCategoryPath(category string) string {
-	// Empty category uses Default category's Dir
-	if category == "" {
-		category = config.DefaultCategoryName
-	}
-
 	if s.configManager == nil {
-		// No config manager, use category name as directory (Default uses its default dir)
-		if category == config.DefaultCategoryName {
-			return config.DefaultCategoryDir
-		}
-		return category
-	}
-
-	cfg := s.configManager.GetConfig()
-
-	// If no categories are configured, use category name as directory
-	if len(cfg.SABnzbd.Categories) == 0 {
-		if category == config.DefaultCategoryName {
-			return config.DefaultCategoryDir
-		}
-		return category
-	}
-
-	// Look for the category in configuration
-	for _, configCategory := range cfg.SABnzbd.Categories {
-		if configCategory.Name == category {
-			// Use configured Dir if available, otherwise use category name
-			if configCategory.Dir != "" {
-				return configCategory.Dir
-			}
-			// For Default category with empty Dir, return default dir
-			if category == config.DefaultCategoryName {
-				return config.DefaultCategoryDir
-			}
-			return category
-		}
+		// No config manager: Default uses its default dir, anything else its name
+		dir, _ := (&config.Config{}).GetCategoryDir(category)
+		return dir
 	}
 
-	// Category not found in configuration, use category name as directory
-	return category
+	dir, _ := s.configManager.GetConfig().GetCategoryDir(category)
+	return dir
 }
 
 // validateSABnzbdCategory validates and returns the category, or error if invalid
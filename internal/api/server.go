@@ -244,10 +244,12 @@ func (s *Server) SetupRoutes(app *fiber.App) {
 	api.Get("/queue", s.handleListQueue)
 	api.Get("/queue/stats", s.handleGetQueueStats)
 	api.Get("/queue/stats/history", s.handleGetQueueHistoricalStats)
+	api.Post("/queue/stats/recompute", s.handleRecomputeQueueStats)
 	// Note: /queue/stream and /health/stream are served by ServeQueueSSE/ServeHealthSSE
 	// at the HTTP server level (setup.go) — bypasses adaptor.FiberApp for correct SSE streaming.
 	api.Delete("/queue/completed", s.handleClearCompletedQueue)
 	api.Delete("/queue/failed", s.handleClearFailedQueue)
+	api.Post("/queue/failed/requeue", s.handleRequeueFailedQueue)
 	api.Delete("/queue/pending", s.handleClearPendingQueue)
 	api.Delete("/queue/bulk", s.handleDeleteQueueBulk)
 	api.Post("/queue/bulk/restart", s.handleRestartQueueBulk)
@@ -262,6 +264,7 @@ func (s *Server) SetupRoutes(app *fiber.App) {
 	api.Post("/queue/:id/retry", s.handleRetryQueue)
 	api.Post("/queue/:id/cancel", s.handleCancelQueue)
 	api.Patch("/queue/:id/priority", s.handleUpdateQueueItemPriority)
+	api.Patch("/queue/:id/position", s.handleMoveQueueItem)
 	api.Get("/queue/:id/download", s.handleDownloadNZB)
 
 	// Health endpoints
@@ -270,12 +273,18 @@ func (s *Server) SetupRoutes(app *fiber.App) {
 	api.Post("/health/bulk/restart", s.handleRestartHealthChecksBulk)
 	api.Post("/health/bulk/repair", s.handleRepairHealthBulk)
 	api.Get("/health/corrupted", s.handleListCorrupted)
+	api.Get("/health/unhealthy", s.handleListUnhealthy)
 	api.Get("/health/stats", s.handleGetHealthStats)
+	api.Get("/health/details", s.handleGetHealthDetails)
 	api.Delete("/health/cleanup", s.handleCleanupHealth)
 	api.Post("/health/reset-all", s.handleResetAllHealthChecks)
 	api.Post("/health/regenerate-symlinks", s.handleRegenerateLibraryFiles)
 	api.Post("/health/check", s.handleAddHealthCheck)
 	api.Get("/health/worker/status", s.handleGetHealthWorkerStatus)
+	api.Post("/health/worker/pause", s.handleHealthWorkerPause)
+	api.Post("/health/worker/resume", s.handleHealthWorkerResume)
+	api.Get("/health/failure-masking/threshold", s.handleGetFailureThreshold)
+	api.Put("/health/failure-masking/threshold", s.handleSetFailureThreshold)
 	api.Post("/health/:id/repair", s.handleRepairHealth)
 	api.Post("/health/:id/unmask", s.handleUnmaskHealth)
 	api.Post("/health/:id/check-now", s.handleDirectHealthCheck)
@@ -290,6 +299,7 @@ func (s *Server) SetupRoutes(app *fiber.App) {
 	api.Post("/health/library-sync/start", s.handleStartLibrarySync)
 	api.Post("/health/library-sync/cancel", s.handleCancelLibrarySync)
 	api.Post("/health/library-sync/dry-run", s.handleDryRunLibrarySync)
+	api.Post("/health/reconcile", s.handleReconcileHealthRecords)
 
 	api.Get("/files/info", s.handleGetFileMetadata)
 	api.Get("/files/active-streams", s.handleGetActiveStreams)
@@ -297,7 +307,10 @@ func (s *Server) SetupRoutes(app *fiber.App) {
 	api.Get("/files/streams/history", s.handleGetStreamHistory)
 	api.Get("/files/export-nzb", s.handleExportMetadataToNZB)
 	api.Post("/files/export-batch", s.handleBatchExportNZB)
-	// Note: /files/stream is handled by StreamHandler at HTTP server level
+	api.Post("/files/reanalyze-nested", s.handleReanalyzeNested)
+	api.Get("/files/path-conflicts", s.handleGetPathConflicts)
+	api.Get("/files/diagnose-read", s.handleDiagnoseRead)
+	// Note: /files/stream and /files/zip are handled by StreamHandler at HTTP server level
 
 	api.Post("/import/scan", s.handleStartManualScan)
 	api.Get("/logs", s.handleGetLogs)
@@ -309,6 +322,7 @@ func (s *Server) SetupRoutes(app *fiber.App) {
 	api.Delete("/import/history", s.handleClearImportHistory)
 	// System endpoints
 	api.Get("/system/stats", s.handleGetSystemStats)
+	api.Get("/system/library-stats", s.handleGetLibraryStats)
 	api.Get("/system/health", s.handleGetSystemHealth)
 	api.Get("/system/browse", s.handleSystemBrowse)
 	api.Get("/system/pool/metrics", s.handleGetPoolMetrics)
@@ -572,6 +586,29 @@ func (s *Server) handleDryRunLibrarySync(c *fiber.Ctx) error {
 	return handlers.handleDryRunLibrarySync(c)
 }
 
+// handleReconcileHealthRecords handles POST /api/health/reconcile
+//
+//	@Summary		Reconcile health records with metadata
+//	@Description	Adds pending health records for metadata files that have none and removes health records whose metadata file is gone. With dry_run=true the inconsistencies are only reported.
+//	@Tags			Health
+//	@Produce		json
+//	@Param			dry_run	query		bool	false	"Only report inconsistencies"
+//	@Success		200		{object}	APIResponse{data=health.HealthReconcileResult}
+//	@Failure		409		{object}	APIResponse
+//	@Failure		503		{object}	APIResponse
+//	@Security		BearerAuth
+//	@Router			/health/reconcile [post]
+func (s *Server) handleReconcileHealthRecords(c *fiber.Ctx) error {
+	if s.librarySyncWorker == nil {
+		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+			"error": "Library sync worker not available",
+		})
+	}
+
+	handlers := NewLibrarySyncHandlers(s.librarySyncWorker, s.configManager)
+	return handlers.handleReconcileHealthRecords(c)
+}
+
 // handleGetSyncNeeded handles GET /api/health/library-sync/needed
 //
 //	@Summary		Check if library sync is needed
@@ -3,11 +3,14 @@ package api
 import (
 	"context"
 	"crypto/subtle"
+	"errors"
+	"io"
 	"log/slog"
 	"mime"
 	"net/http"
 	"os"
 	"path/filepath"
+	"strconv"
 	"sync/atomic"
 
 	"github.com/javi11/altmount/internal/auth"
@@ -24,6 +27,7 @@ type StreamHandler struct {
 	nzbFilesystem *nzbfilesystem.NzbFilesystem
 	userRepo      *database.UserRepository
 	streamTracker *StreamTracker
+	transforms    map[string]StreamTransform
 }
 
 // MonitoredFile wraps an afero.File to track read progress and support cancellation
@@ -66,6 +70,9 @@ func NewStreamHandler(fs *nzbfilesystem.NzbFilesystem, userRepo *database.UserRe
 		nzbFilesystem: fs,
 		userRepo:      userRepo,
 		streamTracker: streamTracker,
+		transforms: map[string]StreamTransform{
+			"passthrough": PassthroughTransform{},
+		},
 	}
 }
 
@@ -136,22 +143,17 @@ func (h *StreamHandler) GetHTTPHandler() http.Handler {
 	})
 }
 
-// serveFile handles the actual file streaming after authentication
-func (h *StreamHandler) serveFile(w http.ResponseWriter, r *http.Request) {
+// streamContext enriches the request context with the request metadata
+// (similar to WebDAV adapter) and the stream source and username used for
+// stream tracking.
+func (h *StreamHandler) streamContext(r *http.Request, user *database.User) context.Context {
 	ctx := r.Context()
-
-	// Enrich context with request metadata (similar to WebDAV adapter)
 	ctx = context.WithValue(ctx, utils.ContentLengthKey, r.Header.Get("Content-Length"))
 	ctx = context.WithValue(ctx, utils.RangeKey, r.Header.Get("Range"))
 	ctx = context.WithValue(ctx, utils.Origin, r.RequestURI)
 	ctx = context.WithValue(ctx, utils.ShowCorrupted, r.Header.Get("X-Show-Corrupted") == "true")
-
-	// Authenticate again to get user details
-	user, ok := h.authenticate(r)
-	if !ok {
-		// Should have been caught by GetHTTPHandler
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
-		return
+	if seg, err := strconv.Atoi(r.Header.Get("X-Start-Segment")); err == nil {
+		ctx = context.WithValue(ctx, utils.StartSegmentKey, seg)
 	}
 
 	var userName string
@@ -163,11 +165,24 @@ func (h *StreamHandler) serveFile(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	// Set stream source and username for tracking
 	ctx = context.WithValue(ctx, utils.StreamSourceKey, "API")
 	ctx = context.WithValue(ctx, utils.StreamUserNameKey, userName)
 	ctx = context.WithValue(ctx, utils.ClientIPKey, r.RemoteAddr)
 	ctx = context.WithValue(ctx, utils.UserAgentKey, r.UserAgent())
+	return ctx
+}
+
+// serveFile handles the actual file streaming after authentication
+func (h *StreamHandler) serveFile(w http.ResponseWriter, r *http.Request) {
+	// Authenticate again to get user details
+	user, ok := h.authenticate(r)
+	if !ok {
+		// Should have been caught by GetHTTPHandler
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	ctx := h.streamContext(r, user)
 
 	// Get path from query parameter
 	path := r.URL.Query().Get("path")
@@ -176,6 +191,12 @@ func (h *StreamHandler) serveFile(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	transform, err := h.requestTransform(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	// Open file via NzbFilesystem (handles encryption, health tracking, etc.)
 	file, err := h.nzbFilesystem.OpenFile(ctx, path, os.O_RDONLY, 0)
 	if err != nil {
@@ -183,6 +204,10 @@ func (h *StreamHandler) serveFile(w http.ResponseWriter, r *http.Request) {
 			http.Error(w, "File not found", http.StatusNotFound)
 			return
 		}
+		if errors.Is(err, nzbfilesystem.ErrTooManyStreams) {
+			http.Error(w, "Too many concurrent streams from this client", http.StatusTooManyRequests)
+			return
+		}
 		http.Error(w, "Failed to open file", http.StatusInternalServerError)
 		return
 	}
@@ -229,16 +254,8 @@ func (h *StreamHandler) serveFile(w http.ResponseWriter, r *http.Request) {
 					ctx:    streamCtx,
 				}
 
-				// Set MIME type based on file extension (prevents internal seeks)
-				ext := filepath.Ext(path)
-				if ext != "" {
-					mimeType := mime.TypeByExtension(ext)
-					if mimeType != "" {
-						w.Header().Set("Content-Type", mimeType)
-					} else {
-						w.Header().Set("Content-Type", "application/octet-stream")
-					}
-				}
+				// Set MIME type up front (prevents internal seeks)
+				setStreamContentType(w, path, stat)
 
 				// Indicate support for range requests
 				w.Header().Set("Accept-Ranges", "bytes")
@@ -247,24 +264,63 @@ func (h *StreamHandler) serveFile(w http.ResponseWriter, r *http.Request) {
 				filename := filepath.Base(path)
 				w.Header().Set("Content-Disposition", `inline; filename="`+filename+`"`)
 
-				http.ServeContent(w, r, filename, stat.ModTime(), monitoredFile)
+				h.writeContent(streamCtx, w, r, filename, stat, monitoredFile, transform)
 				return
 			}
 		}
 	}
 
 	// Fallback if tracker is nil (should not happen in prod)
-	ext := filepath.Ext(path)
-	if ext != "" {
-		mimeType := mime.TypeByExtension(ext)
-		if mimeType != "" {
-			w.Header().Set("Content-Type", mimeType)
-		} else {
-			w.Header().Set("Content-Type", "application/octet-stream")
-		}
-	}
+	setStreamContentType(w, path, stat)
 	w.Header().Set("Accept-Ranges", "bytes")
 	filename := filepath.Base(path)
 	w.Header().Set("Content-Disposition", `inline; filename="`+filename+`"`)
-	http.ServeContent(w, r, filename, stat.ModTime(), file)
+	h.writeContent(ctx, w, r, filename, stat, file, transform)
+}
+
+// setStreamContentType sets the Content-Type of a streamed file: the type
+// the file system resolved for it (overrides, sniffing), else the one
+// registered for its extension, else application/octet-stream. Files with
+// neither an extension nor a resolved type are left to ServeContent.
+func setStreamContentType(w http.ResponseWriter, path string, stat os.FileInfo) {
+	if ct, ok := stat.(interface{ ContentType() string }); ok {
+		if ctype := ct.ContentType(); ctype != "" {
+			w.Header().Set("Content-Type", ctype)
+			return
+		}
+	}
+
+	ext := filepath.Ext(path)
+	if ext == "" {
+		return
+	}
+	if mimeType := mime.TypeByExtension(ext); mimeType != "" {
+		w.Header().Set("Content-Type", mimeType)
+	} else {
+		w.Header().Set("Content-Type", "application/octet-stream")
+	}
+}
+
+// writeContent sends the opened file: through transform when the request
+// selected one, otherwise via http.ServeContent (or chunked when the size is
+// only an estimate).
+func (h *StreamHandler) writeContent(ctx context.Context, w http.ResponseWriter, r *http.Request, filename string, stat os.FileInfo, content io.ReadSeeker, transform StreamTransform) {
+	if transform != nil {
+		h.serveTransformed(ctx, w, r, content, transform)
+		return
+	}
+	if utils.SizeUnknown(stat) {
+		h.serveUnknownLength(w, r, content)
+		return
+	}
+	http.ServeContent(w, r, filename, stat.ModTime(), content)
+}
+
+// serveUnknownLength streams a file whose size is only an estimate until EOF
+// with chunked encoding instead of a Content-Length it might not meet.
+func (h *StreamHandler) serveUnknownLength(w http.ResponseWriter, r *http.Request, content io.Reader) {
+	if err := utils.ServeUnknownLength(w, r, content); err != nil {
+		slog.DebugContext(r.Context(), "Unknown-length stream ended early",
+			"path", r.URL.Query().Get("path"), "error", err)
+	}
 }
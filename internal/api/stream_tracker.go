@@ -4,6 +4,7 @@ import (
 	"context"
 	"log/slog"
 	"sort"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -397,6 +398,33 @@ func (t *StreamTracker) KillStream(id string) bool {
 	return false
 }
 
+// CancelStreamsForPath cancels every active stream reading virtualPath and
+// returns how many were cancelled. Stream paths may carry a mount or WebDAV
+// prefix, so a stream matches when its path equals virtualPath or ends with it
+// on a path boundary. Streams registered without a cancel func are skipped.
+// The cancelled streams are removed by their owners as their reads unwind.
+func (t *StreamTracker) CancelStreamsForPath(virtualPath string) int {
+	target := strings.Trim(virtualPath, "/")
+	if target == "" {
+		return 0
+	}
+
+	cancelled := 0
+	t.streams.Range(func(_, val any) bool {
+		internal := valueToInternal(val)
+		streamPath := strings.Trim(internal.FilePath, "/")
+		if streamPath != target && !strings.HasSuffix(streamPath, "/"+target) {
+			return true
+		}
+		if internal.cancel != nil {
+			internal.cancel()
+			cancelled++
+		}
+		return true
+	})
+	return cancelled
+}
+
 // GetHistory returns the recent stream history
 func (t *StreamTracker) GetHistory() []nzbfilesystem.ActiveStream {
 	t.mu.Lock()
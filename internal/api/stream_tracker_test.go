@@ -72,3 +72,34 @@ func TestStreamTracker_GetAll_Sorting(t *testing.T) {
 	assert.Equal(t, "/new.mkv", streams[0].FilePath)
 	assert.Equal(t, "/old.mkv", streams[1].FilePath)
 }
+
+func TestStreamTracker_CancelStreamsForPath(t *testing.T) {
+	tracker := NewStreamTracker(nil)
+	defer tracker.Stop()
+
+	type tracked struct {
+		id        string
+		cancelled atomic.Bool
+	}
+	add := func(path string) *tracked {
+		s := &tracked{id: tracker.Add(path, "WebDAV", "user1", "127.0.0.1", "TestAgent", 1000)}
+		tracker.SetCancelFunc(s.id, func() { s.cancelled.Store(true) })
+		return s
+	}
+
+	direct := add("/complete/tv/show.s01e01.mkv")
+	prefixed := add("/webdav/complete/tv/show.s01e01.mkv")
+	sibling := add("/complete/tv/show.s01e02.mkv")
+	lookalike := add("/incomplete/tv/show.s01e01.mkv")
+	// Streams without a cancel func (e.g. FUSE handles) are left alone.
+	tracker.AddStream("/complete/tv/show.s01e01.mkv", "FUSE", "FUSE", "", "", 1000)
+
+	n := tracker.CancelStreamsForPath("complete/tv/show.s01e01.mkv")
+
+	assert.Equal(t, 2, n)
+	assert.True(t, direct.cancelled.Load())
+	assert.True(t, prefixed.cancelled.Load())
+	assert.False(t, sibling.cancelled.Load(), "unrelated stream must keep running")
+	assert.False(t, lookalike.cancelled.Load(), "suffix match must respect path boundaries")
+	assert.Equal(t, 0, tracker.CancelStreamsForPath(""))
+}
@@ -0,0 +1,109 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+
+	"github.com/javi11/altmount/internal/utils"
+)
+
+// StreamTransform rewrites a file's bytes between the reader and the HTTP
+// response, e.g. remuxing MKV to fragmented MP4 by piping through an external
+// tool. A request selects one by name with the transform query parameter;
+// without it the file is served as-is with full Range support.
+//
+// Transformed output has no known length and cannot be ranged, so it is sent
+// from the start of the file with chunked encoding.
+type StreamTransform interface {
+	// ContentType returns the Content-Type of the transformed output, or ""
+	// to keep the one derived from the file's extension.
+	ContentType() string
+
+	// Transform reads the file from src and writes the transformed output to
+	// dst until src is exhausted. It must return promptly once ctx is
+	// cancelled or a write to dst fails, which is how a client disconnect
+	// reaches it.
+	Transform(ctx context.Context, dst io.Writer, src io.Reader) error
+}
+
+// PassthroughTransform is the no-op StreamTransform: it copies the file
+// unchanged. It is registered as "passthrough".
+type PassthroughTransform struct{}
+
+func (PassthroughTransform) ContentType() string { return "" }
+
+func (PassthroughTransform) Transform(ctx context.Context, dst io.Writer, src io.Reader) error {
+	_, err := io.Copy(dst, &ctxReader{ctx: ctx, r: src})
+	return err
+}
+
+// ctxReader fails reads once ctx is cancelled.
+type ctxReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (c *ctxReader) Read(p []byte) (int, error) {
+	if err := c.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return c.r.Read(p)
+}
+
+// errTransformAborted unblocks a transform whose response has ended.
+var errTransformAborted = errors.New("stream transform aborted: response ended")
+
+// RegisterTransform makes t selectable with ?transform=name. It must be
+// called before the handler serves requests.
+func (h *StreamHandler) RegisterTransform(name string, t StreamTransform) {
+	h.transforms[name] = t
+}
+
+// requestTransform returns the transform the request selects, or nil when it
+// selects none.
+func (h *StreamHandler) requestTransform(r *http.Request) (StreamTransform, error) {
+	name := r.URL.Query().Get("transform")
+	if name == "" {
+		return nil, nil
+	}
+	t, ok := h.transforms[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown transform %q", name)
+	}
+	return t, nil
+}
+
+// serveTransformed streams content through transform to w. The transform runs
+// in its own goroutine writing into a pipe; when the response ends early the
+// pipe is closed so the transform's next write fails.
+func (h *StreamHandler) serveTransformed(ctx context.Context, w http.ResponseWriter, r *http.Request, content io.Reader, transform StreamTransform) {
+	if ct := transform.ContentType(); ct != "" {
+		w.Header().Set("Content-Type", ct)
+	}
+	if r.Method == http.MethodHead {
+		_ = utils.ServeUnknownLength(w, r, http.NoBody)
+		return
+	}
+
+	pr, pw := io.Pipe()
+	done := make(chan error, 1)
+	go func() {
+		err := transform.Transform(ctx, pw, content)
+		pw.CloseWithError(err)
+		done <- err
+	}()
+
+	serveErr := utils.ServeUnknownLength(w, r, pr)
+	pr.CloseWithError(errTransformAborted)
+	if err := <-done; err != nil && ctx.Err() == nil && !errors.Is(err, errTransformAborted) {
+		slog.WarnContext(ctx, "Stream transform failed",
+			"path", r.URL.Query().Get("path"), "error", err)
+	} else if serveErr != nil {
+		slog.DebugContext(ctx, "Transformed stream ended early",
+			"path", r.URL.Query().Get("path"), "error", serveErr)
+	}
+}
@@ -0,0 +1,174 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// upperTransform upper-cases ASCII letters, standing in for a real remuxer.
+type upperTransform struct{}
+
+func (upperTransform) ContentType() string { return "video/mp4" }
+
+func (upperTransform) Transform(ctx context.Context, dst io.Writer, src io.Reader) error {
+	buf := make([]byte, 4)
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		n, err := src.Read(buf)
+		if _, werr := dst.Write(bytes.ToUpper(buf[:n])); werr != nil {
+			return werr
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// blockingTransform writes nothing until ctx is cancelled.
+type blockingTransform struct{ returned chan error }
+
+func (blockingTransform) ContentType() string { return "" }
+
+func (b blockingTransform) Transform(ctx context.Context, _ io.Writer, _ io.Reader) error {
+	<-ctx.Done()
+	b.returned <- ctx.Err()
+	return ctx.Err()
+}
+
+func newTransformHandler() *StreamHandler {
+	h := NewStreamHandler(nil, nil, nil)
+	h.RegisterTransform("upper", upperTransform{})
+	return h
+}
+
+func TestStreamTransform_Selection(t *testing.T) {
+	h := newTransformHandler()
+
+	tr, err := h.requestTransform(httptest.NewRequest(http.MethodGet, "/stream?path=a.mkv", nil))
+	require.NoError(t, err)
+	assert.Nil(t, tr, "no transform unless the request asks for one")
+
+	tr, err = h.requestTransform(httptest.NewRequest(http.MethodGet, "/stream?path=a.mkv&transform=passthrough", nil))
+	require.NoError(t, err)
+	assert.Equal(t, PassthroughTransform{}, tr)
+
+	_, err = h.requestTransform(httptest.NewRequest(http.MethodGet, "/stream?path=a.mkv&transform=nope", nil))
+	assert.Error(t, err)
+}
+
+func TestStreamTransform_Output(t *testing.T) {
+	h := newTransformHandler()
+	content := []byte("matroska payload bytes")
+
+	t.Run("passthrough", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/stream?path=a.mkv&transform=passthrough", nil)
+		rec.Header().Set("Content-Type", "video/x-matroska")
+		h.writeContent(req.Context(), rec, req, "a.mkv", nil, bytes.NewReader(content), PassthroughTransform{})
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, content, rec.Body.Bytes())
+		assert.Equal(t, "video/x-matroska", rec.Header().Get("Content-Type"))
+		assert.Equal(t, "none", rec.Header().Get("Accept-Ranges"))
+	})
+
+	t.Run("byte transform", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/stream?path=a.mkv&transform=upper", nil)
+		h.writeContent(req.Context(), rec, req, "a.mkv", nil, bytes.NewReader(content), upperTransform{})
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "MATROSKA PAYLOAD BYTES", rec.Body.String())
+		assert.Equal(t, "video/mp4", rec.Header().Get("Content-Type"))
+		assert.Empty(t, rec.Header().Get("Content-Length"))
+	})
+
+	t.Run("head runs no transform", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodHead, "/stream?path=a.mkv&transform=upper", nil)
+		src := &countingReader{r: bytes.NewReader(content)}
+		h.writeContent(req.Context(), rec, req, "a.mkv", nil, src, upperTransform{})
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Zero(t, src.n, "the file is not read for a HEAD request")
+	})
+}
+
+func TestStreamTransform_Cancellation(t *testing.T) {
+	h := newTransformHandler()
+	ctx, cancel := context.WithCancel(context.Background())
+	req := httptest.NewRequest(http.MethodGet, "/stream?path=a.mkv", nil).WithContext(ctx)
+	transform := blockingTransform{returned: make(chan error, 1)}
+
+	served := make(chan struct{})
+	go func() {
+		defer close(served)
+		h.writeContent(ctx, httptest.NewRecorder(), req, "a.mkv", nil, bytes.NewReader([]byte("x")), transform)
+	}()
+
+	cancel()
+	select {
+	case err := <-transform.returned:
+		assert.ErrorIs(t, err, context.Canceled)
+	case <-time.After(5 * time.Second):
+		t.Fatal("transform did not observe cancellation")
+	}
+	select {
+	case <-served:
+	case <-time.After(5 * time.Second):
+		t.Fatal("response did not finish after cancellation")
+	}
+}
+
+func TestStreamTransform_ClientGoneStopsTransform(t *testing.T) {
+	h := newTransformHandler()
+	req := httptest.NewRequest(http.MethodGet, "/stream?path=a.mkv", nil)
+	// An endless source: only a failed write to the response can stop it.
+	src := &countingReader{r: endless{}}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		h.writeContent(req.Context(), &failingWriter{ResponseRecorder: httptest.NewRecorder()}, req, "a.mkv", nil, src, PassthroughTransform{})
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("transform kept running after the client went away")
+	}
+}
+
+type countingReader struct {
+	r io.Reader
+	n int
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += n
+	return n, err
+}
+
+func (c *countingReader) Seek(int64, int) (int64, error) { return 0, nil }
+
+type endless struct{}
+
+func (endless) Read(p []byte) (int, error) { return len(p), nil }
+
+// failingWriter is a response whose client disconnected after the headers.
+type failingWriter struct{ *httptest.ResponseRecorder }
+
+func (f *failingWriter) Write([]byte) (int, error) { return 0, io.ErrClosedPipe }
@@ -51,6 +51,33 @@ func (s *Server) handleGetSystemStats(c *fiber.Ctx) error {
 	return RespondSuccess(c, response)
 }
 
+// handleGetLibraryStats handles GET /api/system/library-stats
+//
+//	@Summary		Get library size
+//	@Description	Returns the total size and the file and directory counts of the library, summed from metadata without touching the mount. The result is cached for a few minutes; refresh=true recomputes it.
+//	@Tags			System
+//	@Produce		json
+//	@Param			refresh	query		bool	false	"Recompute instead of using the cached result"
+//	@Success		200		{object}	APIResponse{data=metadata.LibraryStats}
+//	@Failure		500		{object}	APIResponse
+//	@Security		BearerAuth
+//	@Router			/system/library-stats [get]
+func (s *Server) handleGetLibraryStats(c *fiber.Ctx) error {
+	if s.metadataService == nil {
+		return RespondInternalError(c, "Metadata service not available", "")
+	}
+
+	if c.QueryBool("refresh", false) {
+		s.metadataService.InvalidateLibraryStats()
+	}
+	stats, err := s.metadataService.ComputeLibraryStats(c.Context())
+	if err != nil {
+		return RespondInternalError(c, "Failed to compute library statistics", err.Error())
+	}
+
+	return RespondSuccess(c, stats)
+}
+
 // handleGetSystemHealth handles GET /api/system/health
 //
 //	@Summary		Get system health
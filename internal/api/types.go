@@ -156,8 +156,21 @@ type ImportAPIResponse struct {
 
 	WatchIntervalSeconds     *int  `json:"watch_interval_seconds,omitempty"`
 	AllowNestedRarExtraction *bool `json:"allow_nested_rar_extraction,omitempty"`
+	KeepEmptyArchiveFiles    *bool `json:"keep_empty_archive_files,omitempty"`
+	PreserveArchiveStructure *bool `json:"preserve_archive_structure,omitempty"`
 	RenameToNzbName          *bool `json:"rename_to_nzb_name,omitempty"`
 	FilterSampleFiles        *bool `json:"filter_sample_files,omitempty"`
+	VerifyReadback           *bool `json:"verify_readback,omitempty"`
+	UsePar2Names             *bool `json:"use_par2_names,omitempty"`
+	ComputeFingerprint       *bool `json:"compute_fingerprint,omitempty"`
+	MaxFiles                 int   `json:"max_files"`
+	MaxConcurrentAnalyses    int   `json:"max_concurrent_analyses"`
+	MaxTotalBytes            int64 `json:"max_total_bytes"`
+	QuarantineOverLimit      *bool `json:"quarantine_over_limit,omitempty"`
+	RetryTransientFailures   *bool `json:"retry_transient_failures,omitempty"`
+
+	OnPathCollision  config.PathCollision    `json:"on_path_collision,omitempty"`
+	FilenameEncoding config.FilenameEncoding `json:"filename_encoding,omitempty"`
 }
 
 // SABnzbdAPIResponse sanitizes SABnzbd config for API responses
@@ -424,8 +437,20 @@ func ToImportAPIResponse(importConfig config.ImportConfig) ImportAPIResponse {
 
 		WatchIntervalSeconds:     importConfig.WatchIntervalSeconds,
 		AllowNestedRarExtraction: importConfig.AllowNestedRarExtraction,
+		KeepEmptyArchiveFiles:    importConfig.KeepEmptyArchiveFiles,
+		PreserveArchiveStructure: importConfig.PreserveArchiveStructure,
 		RenameToNzbName:          importConfig.RenameToNzbName,
 		FilterSampleFiles:        importConfig.FilterSampleFiles,
+		VerifyReadback:           importConfig.VerifyReadback,
+		UsePar2Names:             importConfig.UsePar2Names,
+		ComputeFingerprint:       importConfig.ComputeFingerprint,
+		MaxFiles:                 importConfig.MaxFiles,
+		MaxConcurrentAnalyses:    importConfig.MaxConcurrentAnalyses,
+		MaxTotalBytes:            importConfig.MaxTotalBytes,
+		QuarantineOverLimit:      importConfig.QuarantineOverLimit,
+		RetryTransientFailures:   importConfig.RetryTransientFailures,
+		OnPathCollision:          importConfig.OnPathCollision,
+		FilenameEncoding:         importConfig.FilenameEncoding,
 	}
 }
 
@@ -478,6 +503,7 @@ type QueueItemResponse struct {
 	TargetPath     string                 `json:"target_path"`
 	Category       *string                `json:"category"`
 	Priority       database.QueuePriority `json:"priority"`
+	Sequence       int64                  `json:"sequence"` // Manual order within the priority tier
 	Status         database.QueueStatus   `json:"status"`
 	CreatedAt      time.Time              `json:"created_at"`
 	UpdatedAt      time.Time              `json:"updated_at"`
@@ -524,6 +550,7 @@ type ImportHistoryResponse struct {
 	Category    *string   `json:"category"`
 	Indexer     *string   `json:"indexer,omitempty"` // Added indexer
 	Metadata    *string   `json:"metadata,omitempty"`
+	BatchID     *string   `json:"batch_id,omitempty"`
 	CompletedAt time.Time `json:"completed_at"`
 }
 
@@ -617,6 +644,21 @@ type HealthWorkerStatusResponse struct {
 	ErrorCount             int64      `json:"error_count"`
 }
 
+// FailureThresholdResponse reports the streaming failure-masking threshold.
+// Threshold is the one in effect: the runtime override when set, otherwise
+// the configured value.
+type FailureThresholdResponse struct {
+	Threshold  int  `json:"threshold"`
+	Configured int  `json:"configured"`
+	Override   *int `json:"override,omitempty"`
+}
+
+// FailureThresholdRequest sets the runtime failure-masking threshold; 0 clears
+// the override so the configured value applies again.
+type FailureThresholdRequest struct {
+	Threshold int `json:"threshold"`
+}
+
 // System API Types
 
 // SystemStatsResponse represents combined system statistics
@@ -733,6 +775,7 @@ func ToQueueItemResponse(item *database.ImportQueueItem) *QueueItemResponse {
 		TargetPath:     targetPath,
 		Category:       item.Category,
 		Priority:       item.Priority,
+		Sequence:       item.Sequence,
 		Status:         item.Status,
 		CreatedAt:      item.CreatedAt,
 		UpdatedAt:      item.UpdatedAt,
@@ -849,6 +892,7 @@ func ToImportHistoryResponse(h *database.ImportHistory) *ImportHistoryResponse {
 		Category:    h.Category,
 		Indexer:     h.Indexer, // Fixed: use pointer directly
 		Metadata:    h.Metadata,
+		BatchID:     h.BatchID,
 		CompletedAt: h.CompletedAt,
 	}
 }
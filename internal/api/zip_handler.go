@@ -0,0 +1,144 @@
+package api
+
+import (
+	"archive/zip"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/spf13/afero"
+)
+
+// GetZipHTTPHandler returns an http.Handler that streams a directory of the
+// NzbFilesystem as a zip download. Authentication works as for
+// GetHTTPHandler.
+func (h *StreamHandler) GetZipHTTPHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, ok := h.authenticate(r)
+		if !ok {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="Stream API"`)
+			http.Error(w, "Unauthorized: valid download_key required", http.StatusUnauthorized)
+			return
+		}
+
+		h.serveZip(h.streamContext(r, user), w, r)
+	})
+}
+
+// serveZip writes every file below the requested directory into a zip
+// response. Files are read through the regular NzbFilesystem read path and
+// stored uncompressed, as they are almost always already-compressed media,
+// and the archive is written as it is produced rather than buffered.
+func (h *StreamHandler) serveZip(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+	dir := r.URL.Query().Get("path")
+	if dir == "" {
+		http.Error(w, "Path parameter required", http.StatusBadRequest)
+		return
+	}
+
+	stat, err := h.nzbFilesystem.Stat(ctx, dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			http.Error(w, "Directory not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "Failed to get directory information", http.StatusInternalServerError)
+		return
+	}
+	if !stat.IsDir() {
+		http.Error(w, "Path is not a directory", http.StatusBadRequest)
+		return
+	}
+
+	name := path.Base(path.Clean("/" + dir))
+	if name == "/" {
+		name = "altmount"
+	}
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": name + ".zip"}))
+
+	// The response is committed with the first entry, so a failure after that
+	// can only cut the archive short; the client sees a truncated zip.
+	if err := writeDirectoryZip(ctx, h.nzbFilesystem.Open, dir, w); err != nil {
+		slog.ErrorContext(ctx, "Zip download failed", "path", dir, "error", err)
+	}
+}
+
+// openFunc opens a NzbFilesystem path for reading.
+type openFunc func(ctx context.Context, name string) (afero.File, error)
+
+// writeDirectoryZip writes the files below dir to w as a zip archive, with
+// entry names relative to dir. Entries use the Store method and a trailing
+// data descriptor, so nothing is held in memory beyond the copy buffer.
+func writeDirectoryZip(ctx context.Context, open openFunc, dir string, w io.Writer) error {
+	zw := zip.NewWriter(w)
+	if err := addDirectoryToZip(ctx, open, zw, dir, ""); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+func addDirectoryToZip(ctx context.Context, open openFunc, zw *zip.Writer, dir, prefix string) error {
+	d, err := open(ctx, dir)
+	if err != nil {
+		return fmt.Errorf("failed to open directory %s: %w", dir, err)
+	}
+	entries, err := d.Readdir(0)
+	d.Close()
+	if err != nil {
+		return fmt.Errorf("failed to list directory %s: %w", dir, err)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+
+	for _, entry := range entries {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		fullPath := path.Join(dir, entry.Name())
+		entryName := strings.TrimPrefix(path.Join(prefix, entry.Name()), "/")
+
+		if entry.IsDir() {
+			if err := addDirectoryToZip(ctx, open, zw, fullPath, entryName); err != nil {
+				return err
+			}
+			continue
+		}
+
+		hdr := &zip.FileHeader{
+			Name:     entryName,
+			Method:   zip.Store,
+			Modified: entry.ModTime(),
+		}
+		hdr.SetMode(entry.Mode())
+		dst, err := zw.CreateHeader(hdr)
+		if err != nil {
+			return fmt.Errorf("failed to add %s to zip: %w", entryName, err)
+		}
+
+		if err := copyFileToZip(ctx, open, fullPath, dst); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func copyFileToZip(ctx context.Context, open openFunc, name string, dst io.Writer) error {
+	f, err := open(ctx, name)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", name, err)
+	}
+	defer f.Close()
+
+	if _, err := io.Copy(dst, f); err != nil {
+		return fmt.Errorf("failed to read %s: %w", name, err)
+	}
+	return nil
+}
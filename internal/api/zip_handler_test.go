@@ -0,0 +1,64 @@
+package api
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"io"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteDirectoryZip(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	files := map[string][]byte{
+		"/movies/Film (2024)/film.mkv":         bytes.Repeat([]byte{0xAB}, 300_000),
+		"/movies/Film (2024)/film.nfo":         []byte("<movie/>"),
+		"/movies/Film (2024)/Subs/film.en.srt": []byte("1\n00:00:01,000 --> 00:00:02,000\nHi\n"),
+		"/movies/Film (2024)/Subs/empty.srt":   {},
+		"/movies/Other (2023)/other.mkv":       []byte("not in the zip"),
+	}
+	for name, data := range files {
+		require.NoError(t, afero.WriteFile(fs, name, data, 0o644))
+	}
+	open := func(_ context.Context, name string) (afero.File, error) { return fs.Open(name) }
+
+	var buf bytes.Buffer
+	require.NoError(t, writeDirectoryZip(context.Background(), open, "/movies/Film (2024)", &buf))
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+
+	got := make(map[string]uint64)
+	for _, f := range zr.File {
+		assert.Equal(t, zip.Store, f.Method, "%s should be stored uncompressed", f.Name)
+		got[f.Name] = f.UncompressedSize64
+
+		rc, err := f.Open()
+		require.NoError(t, err)
+		data, err := io.ReadAll(rc)
+		rc.Close()
+		require.NoError(t, err, "%s should pass its CRC check", f.Name)
+		assert.Equal(t, files["/movies/Film (2024)/"+f.Name], data)
+	}
+	assert.Equal(t, map[string]uint64{
+		"Subs/empty.srt":   0,
+		"Subs/film.en.srt": uint64(len(files["/movies/Film (2024)/Subs/film.en.srt"])),
+		"film.mkv":         300_000,
+		"film.nfo":         8,
+	}, got)
+}
+
+func TestWriteDirectoryZipCancelled(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/dir/a.mkv", []byte("a"), 0o644))
+	open := func(_ context.Context, name string) (afero.File, error) { return fs.Open(name) }
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := writeDirectoryZip(ctx, open, "/dir", io.Discard)
+	require.ErrorIs(t, err, context.Canceled)
+}
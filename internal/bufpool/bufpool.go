@@ -0,0 +1,72 @@
+// Package bufpool hands out fixed-size scratch buffers backed by sync.Pool so
+// the streaming read paths can reuse per-read buffers instead of allocating a
+// fresh one for every refill. Pools are shared process-wide per buffer size:
+// readers opened with the same configured size draw from the same pool, and a
+// config change simply starts using a pool of the new size. It is imported by
+// the encryption and nzbfilesystem layers and must stay dependency-free.
+package bufpool
+
+import "sync"
+
+// Pool hands out byte slices of exactly Size() bytes.
+//
+// Buffers travel as *[]byte so Put does not allocate when boxing the slice
+// header into an interface.
+type Pool struct {
+	size int
+	p    sync.Pool
+}
+
+var pools sync.Map // int → *Pool
+
+// ForSize returns the shared pool for buffers of size bytes, or nil when size
+// is not positive (pooling disabled). A nil *Pool is valid: Get allocates and
+// Put discards.
+func ForSize(size int) *Pool {
+	if size <= 0 {
+		return nil
+	}
+	if p, ok := pools.Load(size); ok {
+		return p.(*Pool)
+	}
+	p, _ := pools.LoadOrStore(size, newPool(size))
+	return p.(*Pool)
+}
+
+func newPool(size int) *Pool {
+	p := &Pool{size: size}
+	p.p.New = func() any {
+		b := make([]byte, size)
+		return &b
+	}
+	return p
+}
+
+// Size returns the length of the buffers handed out by the pool, or 0 for a
+// nil pool.
+func (p *Pool) Size() int {
+	if p == nil {
+		return 0
+	}
+	return p.size
+}
+
+// Get returns a buffer of Size() bytes. Its contents are unspecified; callers
+// must only read back what they wrote. On a nil pool Get returns nil.
+func (p *Pool) Get() *[]byte {
+	if p == nil {
+		return nil
+	}
+	return p.p.Get().(*[]byte)
+}
+
+// Put resets b to its full length and returns it to the pool. Buffers of the
+// wrong capacity (or a nil b) are dropped rather than polluting the pool. The
+// caller must not use b afterwards.
+func (p *Pool) Put(b *[]byte) {
+	if p == nil || b == nil || cap(*b) != p.size {
+		return
+	}
+	*b = (*b)[:p.size]
+	p.p.Put(b)
+}
@@ -0,0 +1,42 @@
+package bufpool
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestForSize_SharesPoolPerSize(t *testing.T) {
+	assert.Same(t, ForSize(4096), ForSize(4096))
+	assert.NotSame(t, ForSize(4096), ForSize(8192))
+	assert.Nil(t, ForSize(0))
+	assert.Nil(t, ForSize(-1))
+}
+
+func TestPool_GetPutResetsLength(t *testing.T) {
+	p := ForSize(1024)
+	b := p.Get()
+	require.NotNil(t, b)
+	assert.Len(t, *b, 1024)
+
+	*b = (*b)[:10]
+	p.Put(b)
+	assert.Len(t, *b, 1024, "Put must restore the full length")
+
+	// Foreign-sized buffers are dropped instead of being handed out later.
+	foreign := make([]byte, 16)
+	p.Put(&foreign)
+	for range 8 {
+		got := p.Get()
+		assert.Len(t, *got, 1024)
+	}
+}
+
+func TestPool_NilIsDisabled(t *testing.T) {
+	var p *Pool
+	assert.Nil(t, p.Get())
+	assert.Equal(t, 0, p.Size())
+	b := make([]byte, 8)
+	p.Put(&b) // must not panic
+}
@@ -1,6 +1,11 @@
 package config
 
-import "time"
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+)
 
 // Health config accessor methods with default fallbacks.
 // These methods provide safe access to health configuration values
@@ -14,6 +19,12 @@ func (c *Config) GetCheckInterval() time.Duration {
 	return time.Duration(c.Health.CheckIntervalSeconds) * time.Second
 }
 
+// GetIntervalJitter returns the health check interval jitter as a fraction
+// between 0 and 1.
+func (c *Config) GetIntervalJitter() float64 {
+	return float64(min(max(c.Health.IntervalJitter, 0), 100)) / 100
+}
+
 // GetMaxConcurrentJobs returns max concurrent health check jobs with a default fallback.
 func (c *Config) GetMaxConcurrentJobs() int {
 	if c.Health.MaxConcurrentJobs <= 0 {
@@ -22,6 +33,60 @@ func (c *Config) GetMaxConcurrentJobs() int {
 	return c.Health.MaxConcurrentJobs
 }
 
+// GetEffectiveMaxConcurrentJobs returns the health check concurrency to use
+// at now: the peak-hours limit inside a configured window, otherwise
+// GetMaxConcurrentJobs.
+func (c *Config) GetEffectiveMaxConcurrentJobs(now time.Time) int {
+	jobs := c.GetMaxConcurrentJobs()
+	peak := c.Health.PeakHours
+	if peak.MaxConcurrentJobs > 0 && peak.MaxConcurrentJobs < jobs && peak.IsActive(now) {
+		return peak.MaxConcurrentJobs
+	}
+	return jobs
+}
+
+// IsActive reports whether now (in its own location) falls inside any peak
+// window. Malformed windows are ignored; Validate rejects them.
+func (p HealthPeakHoursConfig) IsActive(now time.Time) bool {
+	minute := now.Hour()*60 + now.Minute()
+	for _, w := range p.Windows {
+		start, end, err := parsePeakWindow(w)
+		if err != nil {
+			continue
+		}
+		if start <= end {
+			if minute >= start && minute < end {
+				return true
+			}
+		} else if minute >= start || minute < end {
+			return true
+		}
+	}
+	return false
+}
+
+// parsePeakWindow parses "HH:MM-HH:MM" into start and end minutes of the day.
+func parsePeakWindow(w string) (start, end int, err error) {
+	from, to, ok := strings.Cut(strings.TrimSpace(w), "-")
+	if !ok {
+		return 0, 0, fmt.Errorf("invalid window %q: expected HH:MM-HH:MM", w)
+	}
+	startT, err := time.Parse("15:04", strings.TrimSpace(from))
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid window %q: %w", w, err)
+	}
+	endT, err := time.Parse("15:04", strings.TrimSpace(to))
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid window %q: %w", w, err)
+	}
+	start = startT.Hour()*60 + startT.Minute()
+	end = endT.Hour()*60 + endT.Minute()
+	if start == end {
+		return 0, 0, fmt.Errorf("invalid window %q: start equals end", w)
+	}
+	return start, end, nil
+}
+
 // GetMaxConnectionsForHealthChecks returns max connections for health checks with a default fallback.
 func (c *Config) GetMaxConnectionsForHealthChecks() int {
 	if c.Health.MaxConnectionsForHealthChecks <= 0 {
@@ -149,6 +214,49 @@ func (c *Config) GetMaxConcurrentImports() int {
 	return c.Import.MaxConcurrentImports
 }
 
+// GetMaxConcurrentAnalyses returns the global cap on concurrent archive
+// analyses. 0 means unlimited (the default).
+func (c *Config) GetMaxConcurrentAnalyses() int {
+	if c.Import.MaxConcurrentAnalyses < 0 {
+		return 0
+	}
+	return c.Import.MaxConcurrentAnalyses
+}
+
+// GetComputeFingerprint returns whether imports store a content fingerprint.
+func (c *Config) GetComputeFingerprint() bool {
+	if c.Import.ComputeFingerprint == nil {
+		return false // Default: false
+	}
+	return *c.Import.ComputeFingerprint
+}
+
+// GetRetryTransientFailures returns whether transient import failures are
+// retried.
+func (c *Config) GetRetryTransientFailures() bool {
+	if c.Import.RetryTransientFailures == nil {
+		return false // Default: false
+	}
+	return *c.Import.RetryTransientFailures
+}
+
+// GetRetainSourceNzb returns whether imports keep a copy of their source NZB.
+func (c *Config) GetRetainSourceNzb() bool {
+	if c.Import.RetainSourceNzb == nil {
+		return false // Default: false
+	}
+	return *c.Import.RetainSourceNzb
+}
+
+// GetPreserveArchiveStructure returns whether archive members keep their
+// internal directories.
+func (c *Config) GetPreserveArchiveStructure() bool {
+	if c.Import.PreserveArchiveStructure == nil {
+		return true // Default: true
+	}
+	return *c.Import.PreserveArchiveStructure
+}
+
 // GetMaxDownloadPrefetch returns max download prefetch with a default fallback.
 func (c *Config) GetMaxDownloadPrefetch() int {
 	if c.Import.MaxDownloadPrefetch <= 0 {
@@ -182,6 +290,44 @@ func (c *Config) GetIsoAnalyzeTimeout() time.Duration {
 	return time.Duration(*c.Import.IsoAnalyzeTimeoutSeconds) * time.Second
 }
 
+// GetProcessingReclaimTimeout returns how long a processing queue item may go
+// without a worker heartbeat before it is reclaimed: 10 minutes when unset,
+// 0 (never) when explicitly set to 0.
+func (c *Config) GetProcessingReclaimTimeout() time.Duration {
+	if c.Import.ProcessingReclaimMinutes == nil {
+		return 10 * time.Minute
+	}
+	return time.Duration(max(*c.Import.ProcessingReclaimMinutes, 0)) * time.Minute
+}
+
+// GetHealthFilesystemTimeout returns the deadline for a single health-cycle
+// filesystem operation (metadata move or cleanup) with a default fallback.
+func (c *Config) GetHealthFilesystemTimeout() time.Duration {
+	if c.Health.FilesystemTimeoutSeconds <= 0 {
+		return 30 * time.Second
+	}
+	return time.Duration(c.Health.FilesystemTimeoutSeconds) * time.Second
+}
+
+// GetMetadataWriteRetries returns how many times a metadata write failing
+// with a transient error is retried: 2 when unset, 0 (never) when explicitly
+// set to 0.
+func (c *Config) GetMetadataWriteRetries() int {
+	if c.Metadata.WriteRetries == nil {
+		return 2
+	}
+	return max(*c.Metadata.WriteRetries, 0)
+}
+
+// GetMetadataWriteRetryDelay returns the wait before the first metadata write
+// retry with a default fallback.
+func (c *Config) GetMetadataWriteRetryDelay() time.Duration {
+	if c.Metadata.WriteRetryDelayMs <= 0 {
+		return 100 * time.Millisecond
+	}
+	return time.Duration(c.Metadata.WriteRetryDelayMs) * time.Millisecond
+}
+
 // GetMetadataBackupKeep returns the number of metadata backups to keep with a default fallback.
 func (c *Config) GetMetadataBackupKeep() int {
 	if c.Metadata.Backup.KeepBackups <= 0 {
@@ -249,3 +395,97 @@ func (c *Config) GetRepairExponentialBackoff() bool {
 	}
 	return *c.Health.Repair.ExponentialBackoff
 }
+
+// GetCategoryDir resolves a SABnzbd category name to its directory (relative to
+// complete_dir): an explicit Dir wins; otherwise Default maps to
+// DefaultCategoryDir and any other category maps to its own name. The boolean
+// reports whether the category is known — always true when no categories are
+// configured, since every name then maps to a directory of the same name.
+func (c *Config) GetCategoryDir(category string) (string, bool) {
+	if category == "" {
+		category = DefaultCategoryName
+	}
+	if len(c.SABnzbd.Categories) == 0 {
+		if strings.EqualFold(category, DefaultCategoryName) {
+			return DefaultCategoryDir, true
+		}
+		return category, true
+	}
+
+	for _, cat := range c.SABnzbd.Categories {
+		if strings.EqualFold(cat.Name, category) {
+			if cat.Dir != "" {
+				return cat.Dir, true
+			}
+			if strings.EqualFold(category, DefaultCategoryName) {
+				return DefaultCategoryDir, true
+			}
+			return category, true
+		}
+	}
+
+	return category, false
+}
+
+// IsCategoryDir reports whether virtualPath is complete_dir itself or the
+// directory of a category, either bare (tv, media/tv) or under complete_dir
+// (complete/media/tv). A configured category matches on both its name and its
+// mapped Dir, so folders created before a Dir was set stay recognized; extra
+// names (e.g. a category auto-detected from the watch folder) are matched the
+// same way. Comparison is case-insensitive.
+func (c *Config) IsCategoryDir(virtualPath string, extra ...string) bool {
+	clean := func(p string) string {
+		return strings.Trim(strings.ReplaceAll(p, "\\", "/"), "/")
+	}
+	normalizedPath := clean(virtualPath)
+	completeDir := clean(c.SABnzbd.CompleteDir)
+
+	if strings.EqualFold(normalizedPath, completeDir) {
+		return true
+	}
+
+	matches := func(dir string) bool {
+		dir = clean(dir)
+		if dir == "" {
+			return false
+		}
+		if strings.EqualFold(normalizedPath, dir) {
+			return true
+		}
+		return completeDir != "" && strings.EqualFold(normalizedPath, completeDir+"/"+dir)
+	}
+
+	for _, name := range extra {
+		if name == "" {
+			continue
+		}
+		if dir, _ := c.GetCategoryDir(name); matches(name) || matches(dir) {
+			return true
+		}
+	}
+
+	for _, cat := range c.SABnzbd.Categories {
+		if matches(cat.Name) {
+			return true
+		}
+		if dir, _ := c.GetCategoryDir(cat.Name); matches(dir) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// GetNzbRoot returns the absolute directory NZBs are persisted under, which
+// source NZB paths in metadata are stored relative to. Defaults to .nzbs next
+// to the database.
+func (c *Config) GetNzbRoot() string {
+	root := c.Metadata.NzbRoot
+	if root == "" {
+		root = filepath.Join(filepath.Dir(c.Database.Path), ".nzbs")
+	}
+	if abs, err := filepath.Abs(root); err == nil {
+		return abs
+	}
+	return root
+}
@@ -0,0 +1,88 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetCategoryDir(t *testing.T) {
+	cfg := &Config{}
+	dir, ok := cfg.GetCategoryDir("")
+	assert.True(t, ok)
+	assert.Equal(t, DefaultCategoryDir, dir, "unconfigured Default")
+	dir, ok = cfg.GetCategoryDir("tv")
+	assert.True(t, ok)
+	assert.Equal(t, "tv", dir, "unconfigured categories map to their name")
+
+	cfg.SABnzbd.Categories = []SABnzbdCategory{
+		{Name: DefaultCategoryName},
+		{Name: "tv", Dir: "media/tv"},
+		{Name: "movies"},
+	}
+	dir, ok = cfg.GetCategoryDir("TV")
+	assert.True(t, ok)
+	assert.Equal(t, "media/tv", dir)
+	dir, _ = cfg.GetCategoryDir("movies")
+	assert.Equal(t, "movies", dir)
+	dir, _ = cfg.GetCategoryDir("")
+	assert.Equal(t, DefaultCategoryDir, dir)
+	_, ok = cfg.GetCategoryDir("music")
+	assert.False(t, ok)
+}
+
+func TestIsCategoryDir(t *testing.T) {
+	cfg := &Config{}
+	cfg.SABnzbd.CompleteDir = "/complete"
+	cfg.SABnzbd.Categories = []SABnzbdCategory{
+		{Name: "tv", Dir: "media/tv"},
+		{Name: "movies"},
+	}
+
+	cases := []struct {
+		path string
+		want bool
+	}{
+		{"/complete", true},
+		{"complete/", true},
+		{"/complete/media/tv", true},
+		{"media/tv", true},
+		{"/COMPLETE/Media/TV", true},
+		{`complete\media\tv`, true},
+		{"/complete/tv", true},
+		{"/complete/movies", true},
+		{"/complete/media", false},
+		{"/complete/media/tv/Show", false},
+		{"/complete/anime", false},
+	}
+	for _, tc := range cases {
+		assert.Equal(t, tc.want, cfg.IsCategoryDir(tc.path), tc.path)
+	}
+
+	assert.True(t, cfg.IsCategoryDir("/complete/anime", "anime"), "extra names count as categories")
+	assert.False(t, cfg.IsCategoryDir("/complete/anime", ""))
+}
+
+func TestStreamingConfig_ContentTypeOverride(t *testing.T) {
+	s := StreamingConfig{ContentTypeOverrides: map[string]string{
+		"m2ts":  "video/mp2t",
+		".STRM": "text/plain",
+	}}
+	assert.Equal(t, "video/mp2t", s.ContentTypeOverride("Movie.M2TS"))
+	assert.Equal(t, "text/plain", s.ContentTypeOverride("movie.strm"), "the leading dot is optional")
+	assert.Empty(t, s.ContentTypeOverride("movie.mkv"))
+	assert.Empty(t, s.ContentTypeOverride("m2ts"), "a bare name has no extension")
+}
+
+func TestGetMetadataWriteRetry(t *testing.T) {
+	cfg := &Config{}
+	assert.Equal(t, 2, cfg.GetMetadataWriteRetries())
+	assert.Equal(t, 100*time.Millisecond, cfg.GetMetadataWriteRetryDelay())
+
+	zero := 0
+	cfg.Metadata.WriteRetries = &zero
+	cfg.Metadata.WriteRetryDelayMs = 250
+	assert.Equal(t, 0, cfg.GetMetadataWriteRetries(), "0 disables retrying")
+	assert.Equal(t, 250*time.Millisecond, cfg.GetMetadataWriteRetryDelay())
+}
@@ -2,6 +2,7 @@ package config
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"gopkg.in/yaml.v3"
@@ -161,6 +162,20 @@ func TestConfig_Validate_QueueCleanupRuleAction(t *testing.T) {
 	assert.Contains(t, err.Error(), "invalid action")
 }
 
+func TestConfig_Validate_ContentTypeOverrides(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Streaming.ContentTypeOverrides = map[string]string{"m2ts": "video/mp2t", "strm": "text/plain; charset=utf-8"}
+	assert.NoError(t, cfg.Validate())
+
+	cfg.Streaming.ContentTypeOverrides = map[string]string{"m2ts": "not a type"}
+	err := cfg.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "content_type_overrides[m2ts]")
+
+	cfg.Streaming.ContentTypeOverrides = map[string]string{".": "video/mp2t"}
+	assert.Error(t, cfg.Validate())
+}
+
 func TestConfig_GetWebhookBaseURL(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -454,3 +469,38 @@ func TestMigrateArrsCleanup_AutoFailureFlag_FalseClearsOnly(t *testing.T) {
 	assert.Equal(t, rules, cfg.Arrs.QueueCleanupRules)
 	assert.Nil(t, cfg.Arrs.CleanupAutomaticImportFailure)
 }
+
+func TestConfig_GetEffectiveMaxConcurrentJobs_PeakHours(t *testing.T) {
+	cfg := &Config{Health: HealthConfig{
+		MaxConcurrentJobs: 4,
+		PeakHours: HealthPeakHoursConfig{
+			Windows:           []string{"18:00-23:30", "23:45-01:00"},
+			MaxConcurrentJobs: 1,
+		},
+	}}
+	at := func(h, m int) time.Time { return time.Date(2026, 3, 1, h, m, 0, 0, time.Local) }
+
+	assert.Equal(t, 1, cfg.GetEffectiveMaxConcurrentJobs(at(18, 0)), "window start is inclusive")
+	assert.Equal(t, 1, cfg.GetEffectiveMaxConcurrentJobs(at(21, 15)))
+	assert.Equal(t, 4, cfg.GetEffectiveMaxConcurrentJobs(at(23, 30)), "window end is exclusive")
+	assert.Equal(t, 1, cfg.GetEffectiveMaxConcurrentJobs(at(0, 30)), "window wraps past midnight")
+	assert.Equal(t, 4, cfg.GetEffectiveMaxConcurrentJobs(at(12, 0)))
+
+	// The peak limit never raises concurrency, and 0 disables the throttle.
+	cfg.Health.PeakHours.MaxConcurrentJobs = 8
+	assert.Equal(t, 4, cfg.GetEffectiveMaxConcurrentJobs(at(21, 15)))
+	cfg.Health.PeakHours.MaxConcurrentJobs = 0
+	assert.Equal(t, 4, cfg.GetEffectiveMaxConcurrentJobs(at(21, 15)))
+}
+
+func TestParsePeakWindow(t *testing.T) {
+	start, end, err := parsePeakWindow(" 22:00 - 02:30 ")
+	assert.NoError(t, err)
+	assert.Equal(t, 22*60, start)
+	assert.Equal(t, 2*60+30, end)
+
+	for _, bad := range []string{"", "22:00", "25:00-02:00", "10:00-10:00", "evening"} {
+		_, _, err := parsePeakWindow(bad)
+		assert.Error(t, err, "window %q should be rejected", bad)
+	}
+}
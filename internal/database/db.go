@@ -6,6 +6,7 @@ import (
 	"embed"
 	"fmt"
 	"log/slog"
+	"sync"
 	"time"
 
 	_ "github.com/jackc/pgx/v5/stdlib"
@@ -24,6 +25,9 @@ type DB struct {
 	Repository    *QueueRepository
 	MigrationRepo *ImportMigrationRepository
 	StoreRefRepo  *StoreRefRepository
+
+	// maintenanceMu serialises RunMaintenance; overlapping requests are skipped.
+	maintenanceMu sync.Mutex
 }
 
 // Config holds database configuration.
@@ -504,6 +508,77 @@ func (db *DB) StartCheckpointLoop(ctx context.Context, interval time.Duration) {
 	}()
 }
 
+// RunMaintenance compacts the database and refreshes planner statistics.
+//
+// SQLite: VACUUM rebuilds the file to reclaim pages freed by deletes, then
+// wal_checkpoint(TRUNCATE) folds the VACUUM output back into the main file and
+// shrinks the WAL, and PRAGMA optimize refreshes statistics. VACUUM cannot run
+// inside a transaction, so all statements run on a dedicated connection outside
+// of any Tx. PostgreSQL: VACUUM ANALYZE, with the same restriction.
+//
+// If a maintenance run is already in progress the call returns immediately.
+func (db *DB) RunMaintenance(ctx context.Context) error {
+	if !db.maintenanceMu.TryLock() {
+		slog.DebugContext(ctx, "Database maintenance already running, skipping")
+		return nil
+	}
+	defer db.maintenanceMu.Unlock()
+
+	conn, err := db.conn.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire connection for maintenance: %w", err)
+	}
+	defer conn.Close()
+
+	start := time.Now()
+	if db.dialect.d == DialectPostgres {
+		if _, err := conn.ExecContext(ctx, "VACUUM ANALYZE"); err != nil {
+			return fmt.Errorf("failed to vacuum database: %w", err)
+		}
+		slog.InfoContext(ctx, "Database maintenance complete", "duration", time.Since(start))
+		return nil
+	}
+
+	if _, err := conn.ExecContext(ctx, "VACUUM"); err != nil {
+		return fmt.Errorf("failed to vacuum database: %w", err)
+	}
+	var busy, log, checkpointed int
+	if err := conn.QueryRowContext(ctx, "PRAGMA wal_checkpoint(TRUNCATE)").Scan(&busy, &log, &checkpointed); err != nil {
+		return fmt.Errorf("failed to checkpoint WAL: %w", err)
+	}
+	if _, err := conn.ExecContext(ctx, "PRAGMA optimize"); err != nil {
+		return fmt.Errorf("failed to optimize database: %w", err)
+	}
+
+	slog.InfoContext(ctx, "Database maintenance complete",
+		"duration", time.Since(start),
+		"wal_busy", busy)
+	return nil
+}
+
+// StartMaintenanceLoop starts a background goroutine that calls RunMaintenance
+// every interval. A non-positive interval disables the loop. Stops when ctx is
+// cancelled.
+func (db *DB) StartMaintenanceLoop(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := db.RunMaintenance(ctx); err != nil {
+					slog.WarnContext(ctx, "Scheduled database maintenance failed", "err", err)
+				}
+			}
+		}
+	}()
+}
+
 // UpdateConnectionPool adjusts the database connection pool settings based on worker count.
 func (db *DB) UpdateConnectionPool(workerCount int) {
 	if workerCount <= 0 {
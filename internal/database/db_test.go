@@ -3,6 +3,10 @@ package database
 import (
 	"context"
 	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	_ "github.com/mattn/go-sqlite3"
@@ -156,3 +160,47 @@ func TestUpdateConnectionPool_ActualConnections(t *testing.T) {
 		}
 	}
 }
+
+func TestRunMaintenance_ShrinksFileAfterMassDelete(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "maint.db")
+	db, err := NewDB(Config{Type: "sqlite", DatabasePath: dbPath})
+	require.NoError(t, err)
+	defer db.Close()
+
+	ctx := context.Background()
+	repo := NewRepository(db.Connection(), DialectSQLite)
+
+	var hookDeleted int
+	repo.SetBulkDeleteHook(func(_ context.Context, deleted int) { hookDeleted = deleted })
+
+	padding := strings.Repeat("x", 4096)
+	for i := range 500 {
+		_, err := db.Connection().ExecContext(ctx,
+			`INSERT INTO import_queue (nzb_path, status, error_message) VALUES (?, 'completed', ?)`,
+			fmt.Sprintf("/nzbs/%d.nzb", i), padding)
+		require.NoError(t, err)
+	}
+	require.NoError(t, db.RunMaintenance(ctx))
+	before := fileSize(t, dbPath)
+
+	_, count, err := repo.ClearCompletedQueueItems(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 500, count)
+	assert.Equal(t, 500, hookDeleted, "bulk delete hook should receive the deleted row count")
+
+	require.NoError(t, db.RunMaintenance(ctx))
+	after := fileSize(t, dbPath)
+	assert.Less(t, after, before, "VACUUM should reclaim pages freed by the delete")
+
+	// The WAL is truncated by the post-VACUUM checkpoint.
+	if info, err := os.Stat(dbPath + "-wal"); err == nil {
+		assert.Zero(t, info.Size())
+	}
+}
+
+func fileSize(t *testing.T, path string) int64 {
+	t.Helper()
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	return info.Size()
+}
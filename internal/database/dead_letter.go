@@ -0,0 +1,208 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// deadLetterColumns is the column list shared by every dead-letter SELECT;
+// keep it in sync with scanDeadLetterItem.
+const deadLetterColumns = `id, queue_id, download_id, nzb_path, relative_path, storage_path, category, priority,
+	attempts, max_retries, last_error, batch_id, metadata, file_size, target_path,
+	skip_arr_notification, skip_post_import_links, indexer, queued_at, dead_lettered_at`
+
+func scanDeadLetterItem(row interface{ Scan(...any) error }) (*DeadLetterItem, error) {
+	var item DeadLetterItem
+	err := row.Scan(
+		&item.ID, &item.QueueID, &item.DownloadID, &item.NzbPath, &item.RelativePath, &item.StoragePath, &item.Category, &item.Priority,
+		&item.Attempts, &item.MaxRetries, &item.LastError, &item.BatchID, &item.Metadata, &item.FileSize, &item.TargetPath,
+		&item.SkipArrNotification, &item.SkipPostImportLinks, &item.Indexer, &item.QueuedAt, &item.DeadLetteredAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+// RetryOrDeadLetter records a failed processing attempt. While the item still
+// has retries left it is reset to pending with retry_count incremented and is
+// not claimed again for backoff; once retries are exhausted it is moved to
+// import_dead_letter. Reports whether the item was dead-lettered.
+func (r *QueueRepository) RetryOrDeadLetter(ctx context.Context, id int64, errorMessage *string, backoff time.Duration) (bool, error) {
+	var deadLettered bool
+	err := r.withQueueTransaction(ctx, func(txRepo *QueueRepository) error {
+		retried, err := txRepo.IncrementRetryCountAndResetStatus(ctx, id, errorMessage)
+		if err != nil {
+			return err
+		}
+		if retried {
+			if backoff <= 0 {
+				return nil
+			}
+			retryAfter := txRepo.now().Add(backoff).Format("2006-01-02 15:04:05")
+			if _, err := txRepo.db.ExecContext(ctx, `UPDATE import_queue SET retry_after = ? WHERE id = ?`, retryAfter, id); err != nil {
+				return fmt.Errorf("failed to set retry backoff for queue item %d: %w", id, err)
+			}
+			return nil
+		}
+		if err := txRepo.moveToDeadLetter(ctx, id, errorMessage); err != nil {
+			return err
+		}
+		deadLettered = true
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	return deadLettered, nil
+}
+
+// MoveToDeadLetter moves a queue item to import_dead_letter regardless of its
+// remaining retries, recording errorMessage as the last error (the item's
+// current error_message is kept when errorMessage is nil).
+func (r *QueueRepository) MoveToDeadLetter(ctx context.Context, id int64, errorMessage *string) error {
+	return r.withQueueTransaction(ctx, func(txRepo *QueueRepository) error {
+		return txRepo.moveToDeadLetter(ctx, id, errorMessage)
+	})
+}
+
+// moveToDeadLetter copies the queue row into import_dead_letter and deletes it
+// from import_queue. Must run inside a queue transaction. attempts counts the
+// failed attempt that triggered the move, so it is retry_count + 1.
+func (r *QueueRepository) moveToDeadLetter(ctx context.Context, id int64, errorMessage *string) error {
+	insertQuery := `
+		INSERT INTO import_dead_letter (queue_id, download_id, nzb_path, relative_path, storage_path, category, priority,
+			attempts, max_retries, last_error, batch_id, metadata, file_size, target_path,
+			skip_arr_notification, skip_post_import_links, indexer, queued_at, dead_lettered_at)
+		SELECT id, download_id, nzb_path, relative_path, storage_path, category, priority,
+			retry_count + 1, max_retries, COALESCE(?, error_message), batch_id, metadata, file_size, target_path,
+			skip_arr_notification, skip_post_import_links, indexer, created_at, datetime('now')
+		FROM import_queue
+		WHERE id = ?
+	`
+	result, err := r.db.ExecContext(ctx, insertQuery, errorMessage, id)
+	if err != nil {
+		return fmt.Errorf("failed to dead-letter queue item %d: %w", id, err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("queue item %d not found", id)
+	}
+
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM import_queue WHERE id = ?`, id); err != nil {
+		return fmt.Errorf("failed to remove dead-lettered queue item %d: %w", id, err)
+	}
+	return nil
+}
+
+// UpdateDeadLetterNzbPath records where the NZB of the item dead-lettered from
+// queue item queueID now lives, so a requeue finds it after it was moved.
+func (r *QueueRepository) UpdateDeadLetterNzbPath(ctx context.Context, queueID int64, nzbPath string) error {
+	if _, err := r.db.ExecContext(ctx, `UPDATE import_dead_letter SET nzb_path = ? WHERE queue_id = ?`, nzbPath, queueID); err != nil {
+		return fmt.Errorf("failed to update dead-letter nzb path: %w", err)
+	}
+	return nil
+}
+
+// ListDeadLetterItems returns dead-lettered items, most recent first.
+func (r *QueueRepository) ListDeadLetterItems(ctx context.Context, limit, offset int) ([]*DeadLetterItem, error) {
+	query := `SELECT ` + deadLetterColumns + `
+		FROM import_dead_letter
+		ORDER BY dead_lettered_at DESC, id DESC
+		LIMIT ? OFFSET ?`
+
+	rows, err := r.db.QueryContext(ctx, query, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list dead-letter items: %w", err)
+	}
+	defer rows.Close()
+
+	var items []*DeadLetterItem
+	for rows.Next() {
+		item, err := scanDeadLetterItem(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan dead-letter item: %w", err)
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+// CountDeadLetterItems returns the number of dead-lettered items.
+func (r *QueueRepository) CountDeadLetterItems(ctx context.Context) (int, error) {
+	var count int
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM import_dead_letter`).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count dead-letter items: %w", err)
+	}
+	return count, nil
+}
+
+// GetDeadLetterItem retrieves a dead-lettered item by ID. Returns nil, nil when
+// no such item exists.
+func (r *QueueRepository) GetDeadLetterItem(ctx context.Context, id int64) (*DeadLetterItem, error) {
+	query := `SELECT ` + deadLetterColumns + ` FROM import_dead_letter WHERE id = ?`
+	item, err := scanDeadLetterItem(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get dead-letter item: %w", err)
+	}
+	return item, nil
+}
+
+// RequeueDeadLetterItem moves a dead-lettered item back into import_queue as a
+// fresh pending item (retry_count reset to 0, error cleared) and returns the
+// new queue row. Returns nil, nil when no such dead-letter item exists.
+func (r *QueueRepository) RequeueDeadLetterItem(ctx context.Context, id int64) (*ImportQueueItem, error) {
+	var requeued *ImportQueueItem
+
+	err := r.withQueueTransaction(ctx, func(txRepo *QueueRepository) error {
+		item, err := txRepo.GetDeadLetterItem(ctx, id)
+		if err != nil || item == nil {
+			return err
+		}
+
+		insertQuery := `
+			INSERT INTO import_queue (download_id, nzb_path, relative_path, storage_path, category, priority, status, retry_count, max_retries,
+				batch_id, metadata, file_size, target_path, skip_arr_notification, skip_post_import_links, indexer, sequence, created_at, updated_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, 0, ?, ?, ?, ?, ?, ?, ?, ?, ` + nextQueueSequence + `, datetime('now'), datetime('now'))
+		`
+		args := []any{item.DownloadID, item.NzbPath, item.RelativePath, item.StoragePath, item.Category, item.Priority,
+			QueueStatusPending, item.MaxRetries, item.BatchID, item.Metadata, item.FileSize, item.TargetPath,
+			item.SkipArrNotification, item.SkipPostImportLinks, item.Indexer}
+
+		var newID int64
+		if txRepo.dialect.IsPostgres() {
+			if err := txRepo.db.QueryRowContext(ctx, insertQuery+" RETURNING id", args...).Scan(&newID); err != nil {
+				return fmt.Errorf("failed to requeue dead-letter item %d: %w", id, err)
+			}
+		} else {
+			result, err := txRepo.db.ExecContext(ctx, insertQuery, args...)
+			if err != nil {
+				return fmt.Errorf("failed to requeue dead-letter item %d: %w", id, err)
+			}
+			if newID, err = result.LastInsertId(); err != nil {
+				return fmt.Errorf("failed to get last insert ID: %w", err)
+			}
+		}
+
+		if _, err := txRepo.db.ExecContext(ctx, `DELETE FROM import_dead_letter WHERE id = ?`, id); err != nil {
+			return fmt.Errorf("failed to remove requeued dead-letter item %d: %w", id, err)
+		}
+
+		requeued, err = txRepo.GetQueueItem(ctx, newID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return requeued, nil
+}
@@ -0,0 +1,148 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetryOrDeadLetter_ExhaustedRetriesMovesToDeadLetter(t *testing.T) {
+	repo := newTestDB(t).Repository
+	ctx := context.Background()
+
+	category := "movies"
+	downloadID := "dl-123"
+	item := &ImportQueueItem{
+		DownloadID: &downloadID,
+		NzbPath:    "/nzbs/broken.nzb",
+		Category:   &category,
+		Priority:   QueuePriorityNormal,
+		Status:     QueueStatusPending,
+		MaxRetries: 2,
+	}
+	require.NoError(t, repo.AddToQueue(ctx, item))
+
+	// Two retries are allowed; each puts the item back to pending.
+	for i := 1; i <= 2; i++ {
+		msg := "segment missing"
+		deadLettered, err := repo.RetryOrDeadLetter(ctx, item.ID, &msg, 0)
+		require.NoError(t, err)
+		assert.False(t, deadLettered, "retry %d should not dead-letter", i)
+
+		queued, err := repo.GetQueueItem(ctx, item.ID)
+		require.NoError(t, err)
+		require.NotNil(t, queued)
+		assert.Equal(t, QueueStatusPending, queued.Status)
+		assert.Equal(t, i, queued.RetryCount)
+	}
+
+	// The third failure exhausts retries.
+	lastErr := "article not found on any provider"
+	deadLettered, err := repo.RetryOrDeadLetter(ctx, item.ID, &lastErr, 0)
+	require.NoError(t, err)
+	assert.True(t, deadLettered)
+
+	queued, err := repo.GetQueueItem(ctx, item.ID)
+	require.NoError(t, err)
+	assert.Nil(t, queued, "dead-lettered item must leave the active queue")
+
+	count, err := repo.CountDeadLetterItems(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	items, err := repo.ListDeadLetterItems(ctx, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	dl := items[0]
+	assert.Equal(t, item.ID, dl.QueueID)
+	assert.Equal(t, "/nzbs/broken.nzb", dl.NzbPath)
+	assert.Equal(t, 3, dl.Attempts)
+	assert.Equal(t, 2, dl.MaxRetries)
+	require.NotNil(t, dl.LastError)
+	assert.Equal(t, lastErr, *dl.LastError)
+	require.NotNil(t, dl.DownloadID)
+	assert.Equal(t, downloadID, *dl.DownloadID)
+	require.NotNil(t, dl.Category)
+	assert.Equal(t, category, *dl.Category)
+	assert.NotNil(t, dl.QueuedAt)
+}
+
+func TestRetryOrDeadLetter_RequeuedItemWaitsOutBackoff(t *testing.T) {
+	repo := newTestDB(t).Repository
+	ctx := context.Background()
+	clock := &fakeClock{t: time.Date(2001, 2, 3, 4, 5, 6, 0, time.UTC)}
+	repo.SetClock(clock.now)
+
+	item := &ImportQueueItem{NzbPath: "/nzbs/outage.nzb", Priority: QueuePriorityNormal, Status: QueueStatusPending, MaxRetries: 3}
+	require.NoError(t, repo.AddToQueue(ctx, item))
+	claimed, err := repo.ClaimNextQueueItem(ctx, 0)
+	require.NoError(t, err)
+	require.NotNil(t, claimed)
+
+	msg := "connection reset by peer"
+	deadLettered, err := repo.RetryOrDeadLetter(ctx, item.ID, &msg, time.Minute)
+	require.NoError(t, err)
+	require.False(t, deadLettered)
+
+	claimed, err = repo.ClaimNextQueueItem(ctx, 0)
+	require.NoError(t, err)
+	assert.Nil(t, claimed, "a requeued item is not claimed before its backoff")
+
+	clock.advance(time.Minute)
+	claimed, err = repo.ClaimNextQueueItem(ctx, 0)
+	require.NoError(t, err)
+	require.NotNil(t, claimed, "the item is claimable once the backoff is over")
+	assert.Equal(t, item.ID, claimed.ID)
+}
+
+func TestRequeueDeadLetterItem_RestoresPendingItem(t *testing.T) {
+	repo := newTestDB(t).Repository
+	ctx := context.Background()
+
+	item := &ImportQueueItem{
+		NzbPath:    "/nzbs/retry-me.nzb",
+		Priority:   QueuePriorityHigh,
+		Status:     QueueStatusPending,
+		MaxRetries: 0,
+	}
+	require.NoError(t, repo.AddToQueue(ctx, item))
+
+	msg := "boom"
+	deadLettered, err := repo.RetryOrDeadLetter(ctx, item.ID, &msg, 0)
+	require.NoError(t, err)
+	require.True(t, deadLettered)
+
+	items, err := repo.ListDeadLetterItems(ctx, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+
+	requeued, err := repo.RequeueDeadLetterItem(ctx, items[0].ID)
+	require.NoError(t, err)
+	require.NotNil(t, requeued)
+	assert.Equal(t, QueueStatusPending, requeued.Status)
+	assert.Equal(t, "/nzbs/retry-me.nzb", requeued.NzbPath)
+	assert.Equal(t, QueuePriorityHigh, requeued.Priority)
+	assert.Equal(t, 0, requeued.RetryCount)
+	assert.Nil(t, requeued.ErrorMessage)
+
+	count, err := repo.CountDeadLetterItems(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, count)
+
+	// The restored item is claimable again.
+	claimed, err := repo.ClaimNextQueueItem(ctx, 0)
+	require.NoError(t, err)
+	require.NotNil(t, claimed)
+	assert.Equal(t, requeued.ID, claimed.ID)
+}
+
+func TestRequeueDeadLetterItem_NotFound(t *testing.T) {
+	repo := newTestDB(t).Repository
+
+	requeued, err := repo.RequeueDeadLetterItem(context.Background(), 42)
+	require.NoError(t, err)
+	assert.Nil(t, requeued)
+}
@@ -0,0 +1,55 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// FileHealthDetails is a consolidated view of a file's current health and
+// repair state, for callers that would otherwise stitch it together from
+// several queries.
+type FileHealthDetails struct {
+	FilePath              string       `json:"file_path"`
+	Status                HealthStatus `json:"status"`
+	RetryCount            int          `json:"retry_count"`
+	MaxRetries            int          `json:"max_retries"`
+	RepairRetryCount      int          `json:"repair_retry_count"`
+	MaxRepairRetries      int          `json:"max_repair_retries"`
+	StreamingFailureCount int          `json:"streaming_failure_count"`
+	LastError             *string      `json:"last_error"`
+	ErrorDetails          *string      `json:"error_details"` // JSON, see HealthErrorDetails
+	IsMasked              bool         `json:"is_masked"`
+	LastChecked           *time.Time   `json:"last_checked"`
+	ScheduledCheckAt      *time.Time   `json:"scheduled_check_at"`
+	SourceNzbPath         *string      `json:"source_nzb_path"`
+}
+
+// GetFileHealthDetails returns the health and repair details of a file, or
+// nil when the file has no health record.
+func (r *HealthRepository) GetFileHealthDetails(ctx context.Context, filePath string) (*FileHealthDetails, error) {
+	filePath = normalizeHealthPath(filePath)
+	query := `
+		SELECT file_path, status, retry_count, max_retries, repair_retry_count, max_repair_retries,
+		       streaming_failure_count, last_error, error_details, is_masked,
+		       last_checked, scheduled_check_at, source_nzb_path
+		FROM file_health
+		WHERE file_path = ?
+	`
+
+	var d FileHealthDetails
+	err := r.db.QueryRowContext(ctx, query, filePath).Scan(
+		&d.FilePath, &d.Status, &d.RetryCount, &d.MaxRetries,
+		&d.RepairRetryCount, &d.MaxRepairRetries,
+		&d.StreamingFailureCount, &d.LastError, &d.ErrorDetails, &d.IsMasked,
+		&d.LastChecked, &d.ScheduledCheckAt, &d.SourceNzbPath,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get file health details: %w", err)
+	}
+	return &d, nil
+}
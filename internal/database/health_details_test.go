@@ -0,0 +1,119 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetFileHealthDetails(t *testing.T) {
+	repo := setupTestDB(t)
+	ctx := context.Background()
+
+	scheduled := time.Now().UTC().Add(time.Hour).Truncate(time.Second)
+	checked := time.Now().UTC().Add(-time.Hour).Truncate(time.Second)
+	details := (&HealthErrorDetails{ErrorType: "missing_articles", MissingArticles: 3, TotalArticles: 10}).Marshal()
+
+	rows := []struct {
+		path      string
+		status    HealthStatus
+		retry     int
+		repair    int
+		failures  int
+		masked    bool
+		lastError any
+		details   any
+		scheduled any
+		sourceNzb any
+		lastCheck any
+	}{
+		{"movies/healthy.mkv", HealthStatusHealthy, 0, 0, 0, false, nil, nil, scheduled, "/nzbs/healthy.nzb", checked},
+		{"movies/pending.mkv", HealthStatusPending, 1, 0, 0, false, nil, nil, nil, nil, nil},
+		{"movies/corrupted.mkv", HealthStatusCorrupted, 3, 2, 0, false, "missing articles", *details, nil, "/nzbs/corrupted.nzb", checked},
+		{"movies/repair.mkv", HealthStatusRepairTriggered, 0, 1, 0, false, "repair requested", nil, scheduled, nil, checked},
+		{"movies/masked.mkv", HealthStatusHealthy, 0, 0, 5, true, "stream failed", nil, nil, nil, nil},
+	}
+	for _, r := range rows {
+		_, err := repo.db.ExecContext(ctx, `
+			INSERT INTO file_health (
+				file_path, status, retry_count, max_retries, repair_retry_count, max_repair_retries,
+				streaming_failure_count, is_masked, last_error, error_details, scheduled_check_at,
+				source_nzb_path, last_checked
+			) VALUES (?, ?, ?, 3, ?, 3, ?, ?, ?, ?, ?, ?, ?)
+		`, r.path, r.status, r.retry, r.repair, r.failures, r.masked, r.lastError, r.details,
+			r.scheduled, r.sourceNzb, r.lastCheck)
+		require.NoError(t, err)
+	}
+
+	t.Run("healthy", func(t *testing.T) {
+		d, err := repo.GetFileHealthDetails(ctx, "/movies/healthy.mkv")
+		require.NoError(t, err)
+		require.NotNil(t, d)
+		assert.Equal(t, "movies/healthy.mkv", d.FilePath)
+		assert.Equal(t, HealthStatusHealthy, d.Status)
+		assert.Nil(t, d.LastError)
+		assert.Nil(t, d.ErrorDetails)
+		assert.False(t, d.IsMasked)
+		require.NotNil(t, d.ScheduledCheckAt)
+		assert.True(t, scheduled.Equal(*d.ScheduledCheckAt))
+		require.NotNil(t, d.LastChecked)
+		assert.True(t, checked.Equal(*d.LastChecked))
+		require.NotNil(t, d.SourceNzbPath)
+		assert.Equal(t, "/nzbs/healthy.nzb", *d.SourceNzbPath)
+	})
+
+	t.Run("pending", func(t *testing.T) {
+		d, err := repo.GetFileHealthDetails(ctx, "movies/pending.mkv")
+		require.NoError(t, err)
+		require.NotNil(t, d)
+		assert.Equal(t, HealthStatusPending, d.Status)
+		assert.Equal(t, 1, d.RetryCount)
+		assert.Equal(t, 3, d.MaxRetries)
+		assert.Nil(t, d.LastChecked)
+		assert.Nil(t, d.ScheduledCheckAt)
+		assert.Nil(t, d.SourceNzbPath)
+	})
+
+	t.Run("corrupted", func(t *testing.T) {
+		d, err := repo.GetFileHealthDetails(ctx, "movies/corrupted.mkv")
+		require.NoError(t, err)
+		require.NotNil(t, d)
+		assert.Equal(t, HealthStatusCorrupted, d.Status)
+		assert.Equal(t, 3, d.RetryCount)
+		assert.Equal(t, 2, d.RepairRetryCount)
+		assert.Equal(t, 3, d.MaxRepairRetries)
+		require.NotNil(t, d.LastError)
+		assert.Equal(t, "missing articles", *d.LastError)
+		require.NotNil(t, d.ErrorDetails)
+		assert.JSONEq(t, *details, *d.ErrorDetails)
+	})
+
+	t.Run("repair triggered", func(t *testing.T) {
+		d, err := repo.GetFileHealthDetails(ctx, "movies/repair.mkv")
+		require.NoError(t, err)
+		require.NotNil(t, d)
+		assert.Equal(t, HealthStatusRepairTriggered, d.Status)
+		assert.Equal(t, 1, d.RepairRetryCount)
+		require.NotNil(t, d.ScheduledCheckAt)
+		assert.True(t, scheduled.Equal(*d.ScheduledCheckAt))
+	})
+
+	t.Run("masked", func(t *testing.T) {
+		d, err := repo.GetFileHealthDetails(ctx, "movies/masked.mkv")
+		require.NoError(t, err)
+		require.NotNil(t, d)
+		assert.True(t, d.IsMasked)
+		assert.Equal(t, 5, d.StreamingFailureCount)
+		require.NotNil(t, d.LastError)
+		assert.Equal(t, "stream failed", *d.LastError)
+	})
+
+	t.Run("unknown file", func(t *testing.T) {
+		d, err := repo.GetFileHealthDetails(ctx, "movies/missing.mkv")
+		require.NoError(t, err)
+		assert.Nil(t, d)
+	})
+}
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"log/slog"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
@@ -186,8 +187,52 @@ func (r *HealthRepository) GetFileHealthByID(ctx context.Context, id int64) (*Fi
 	return health, nil
 }
 
-// IncrementStreamingFailureCount increments the streaming failure count and returns whether masking/repair threshold was reached
+// streamingFailureThresholdKey is the system_state key holding the runtime
+// override of Streaming.FailureMasking.Threshold.
+const streamingFailureThresholdKey = "streaming_failure_threshold"
+
+// GetStreamingFailureThreshold returns the runtime failure-masking threshold.
+// ok is false when no override is set and the configured threshold applies.
+func (r *HealthRepository) GetStreamingFailureThreshold(ctx context.Context) (threshold int, ok bool, err error) {
+	value, err := r.GetSystemState(ctx, streamingFailureThresholdKey)
+	if err != nil || value == "" {
+		return 0, false, err
+	}
+	threshold, err = strconv.Atoi(value)
+	if err != nil {
+		return 0, false, fmt.Errorf("invalid streaming failure threshold %q: %w", value, err)
+	}
+	return threshold, true, nil
+}
+
+// SetStreamingFailureThreshold persists a runtime failure-masking threshold
+// that overrides the configured one without a config reload. 0 clears the
+// override.
+func (r *HealthRepository) SetStreamingFailureThreshold(ctx context.Context, threshold int) error {
+	if threshold < 0 {
+		return fmt.Errorf("streaming failure threshold must be non-negative, got %d", threshold)
+	}
+	value := ""
+	if threshold > 0 {
+		value = strconv.Itoa(threshold)
+	}
+	return r.UpdateSystemState(ctx, streamingFailureThresholdKey, value)
+}
+
+// IncrementStreamingFailureCount increments the streaming failure count and returns whether masking/repair threshold was reached.
+// threshold is the configured value; a runtime override set with
+// SetStreamingFailureThreshold takes precedence. With no positive threshold
+// masking is off: nothing is counted and the failure always warrants a repair.
 func (r *HealthRepository) IncrementStreamingFailureCount(ctx context.Context, filePath string, threshold int) (bool, bool, error) {
+	if override, ok, err := r.GetStreamingFailureThreshold(ctx); err != nil {
+		slog.WarnContext(ctx, "Failed to read runtime streaming failure threshold, using configured value", "error", err)
+	} else if ok {
+		threshold = override
+	}
+	if threshold <= 0 {
+		return false, true, nil
+	}
+
 	filePath = normalizeHealthPath(filePath)
 	query := `
 		UPDATE file_health
@@ -307,6 +352,61 @@ func (r *HealthRepository) GetUnhealthyFiles(ctx context.Context, limit int, str
 	return files, nil
 }
 
+// GetNeverCheckedFiles returns files that have a health record but have never
+// been verified by a health check (last_verified_at IS NULL): freshly imported
+// files waiting for their first check and records the library sync created as
+// 'healthy' without checking them. Records a check or repair currently owns
+// ('checking', 'repair_triggered') and terminal 'corrupted' ones are excluded.
+// Results are ordered by priority, then oldest first.
+func (r *HealthRepository) GetNeverCheckedFiles(ctx context.Context, limit int) ([]*FileHealth, error) {
+	query := `
+		SELECT id, file_path, status, last_checked, last_error, retry_count, max_retries,
+		       repair_retry_count, max_repair_retries, source_nzb_path,
+		       error_details, created_at, updated_at, release_date, scheduled_check_at,
+			   library_path, priority, streaming_failure_count, is_masked
+		, metadata, indexer, download_id
+		FROM file_health
+		WHERE last_verified_at IS NULL
+		  AND status NOT IN ('repair_triggered', 'checking', 'corrupted')
+		ORDER BY priority DESC, created_at ASC, id ASC
+		LIMIT ?
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query never checked files: %w", err)
+	}
+	defer rows.Close()
+
+	var files []*FileHealth
+	for rows.Next() {
+		var health FileHealth
+		err := rows.Scan(
+			&health.ID, &health.FilePath, &health.Status, &health.LastChecked,
+			&health.LastError, &health.RetryCount, &health.MaxRetries,
+			&health.RepairRetryCount, &health.MaxRepairRetries,
+			&health.SourceNzbPath, &health.ErrorDetails,
+			&health.CreatedAt, &health.UpdatedAt, &health.ReleaseDate,
+			&health.ScheduledCheckAt,
+			&health.LibraryPath,
+			&health.Priority,
+			&health.StreamingFailureCount,
+			&health.IsMasked,
+			&health.Metadata, &health.Indexer, &health.DownloadID,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan never checked file: %w", err)
+		}
+		files = append(files, &health)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate never checked files: %w", err)
+	}
+
+	return files, nil
+}
+
 // SetPriority sets the priority for a file health record
 func (r *HealthRepository) SetPriority(ctx context.Context, id int64, priority HealthPriority) error {
 	query := `
@@ -612,6 +712,74 @@ func (r *HealthRepository) DeleteHealthRecordsByPrefix(ctx context.Context, pref
 	return result.RowsAffected()
 }
 
+// GetRepairTriggeredPathsByPrefix returns the file paths at or under the given
+// virtual path prefix whose repair has been triggered and not yet resolved.
+// Directory deletes use it to keep files an Arr may still be replacing.
+func (r *HealthRepository) GetRepairTriggeredPathsByPrefix(ctx context.Context, prefix string) ([]string, error) {
+	prefix = normalizeHealthPath(prefix)
+	if prefix == "" {
+		return nil, nil
+	}
+
+	query := `
+		SELECT file_path FROM file_health
+		WHERE (file_path = ? OR file_path LIKE ? ESCAPE '\')
+		  AND status = ?
+	`
+	likePattern := escapeLikePrefix(prefix) + "/%"
+
+	rows, err := r.db.QueryContext(ctx, query, prefix, likePattern, HealthStatusRepairTriggered)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query repair-triggered paths by prefix %s: %w", prefix, err)
+	}
+	defer rows.Close()
+
+	var paths []string
+	for rows.Next() {
+		var p string
+		if err := rows.Scan(&p); err != nil {
+			return nil, fmt.Errorf("failed to scan repair-triggered path: %w", err)
+		}
+		paths = append(paths, p)
+	}
+	return paths, rows.Err()
+}
+
+// GetCorruptedPathsByPrefix returns the file paths under the given virtual
+// path prefix that health has flagged as bad: corrupted, awaiting repair, or
+// masked after repeated streaming failures. It backs the per-category
+// .corrupted views.
+func (r *HealthRepository) GetCorruptedPathsByPrefix(ctx context.Context, prefix string) ([]string, error) {
+	prefix = normalizeHealthPath(prefix)
+	if prefix == "" {
+		return nil, nil
+	}
+
+	query := `
+		SELECT file_path FROM file_health
+		WHERE file_path LIKE ? ESCAPE '\'
+		  AND (status IN (?, ?) OR is_masked = TRUE)
+		ORDER BY file_path
+	`
+	likePattern := escapeLikePrefix(prefix) + "/%"
+
+	rows, err := r.db.QueryContext(ctx, query, likePattern, HealthStatusCorrupted, HealthStatusRepairTriggered)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query corrupted paths by prefix %s: %w", prefix, err)
+	}
+	defer rows.Close()
+
+	var paths []string
+	for rows.Next() {
+		var p string
+		if err := rows.Scan(&p); err != nil {
+			return nil, fmt.Errorf("failed to scan corrupted path: %w", err)
+		}
+		paths = append(paths, p)
+	}
+	return paths, rows.Err()
+}
+
 // DeleteUnvalidatedHealthRecordsByPrefix removes only the still-unvalidated placeholder
 // records at or under the prefix — those an ARR webhook has not yet relinked to a real
 // library path (library_path NULL or still equal to the virtual file_path) and that are
@@ -699,6 +867,7 @@ func (r *HealthRepository) AddFileToHealthCheckWithMetadata(ctx context.Context,
 		retry_count = 0,
 		last_error = NULL,
 		error_details = NULL,
+		last_verified_at = NULL,
 		max_retries = excluded.max_retries,
 		max_repair_retries = excluded.max_repair_retries,
 		source_nzb_path = COALESCE(excluded.source_nzb_path, source_nzb_path),
@@ -787,6 +956,7 @@ func (r *HealthRepository) batchUpsertFileHealthCheck(ctx context.Context, recor
 			retry_count = 0,
 			last_error = NULL,
 			error_details = NULL,
+			last_verified_at = NULL,
 			max_retries = excluded.max_retries,
 			max_repair_retries = excluded.max_repair_retries,
 			source_nzb_path = COALESCE(excluded.source_nzb_path, source_nzb_path),
@@ -935,6 +1105,119 @@ func (r *HealthRepository) CountHealthItems(ctx context.Context, statusFilter *H
 	return count, nil
 }
 
+// UnhealthyStatuses are the health statuses listed by ListUnhealthyFiles when
+// no explicit status filter is given.
+var UnhealthyStatuses = []HealthStatus{
+	HealthStatusCorrupted,
+	HealthStatusRepairTriggered,
+	HealthStatusPending,
+}
+
+// unhealthyFilesWhere builds the WHERE clause shared by ListUnhealthyFiles and
+// CountUnhealthyFiles. An empty statuses slice means UnhealthyStatuses.
+func unhealthyFilesWhere(statuses []HealthStatus, search string) (string, []any) {
+	if len(statuses) == 0 {
+		statuses = UnhealthyStatuses
+	}
+
+	var conditions []string
+	var args []any
+
+	conditions = append(conditions, fmt.Sprintf("status IN (%s)", inPlaceholders(len(statuses))))
+	for _, s := range statuses {
+		args = append(args, string(s))
+	}
+
+	if search != "" {
+		conditions = append(conditions, "(file_path LIKE ? OR library_path LIKE ? OR source_nzb_path LIKE ?)")
+		searchPattern := "%" + search + "%"
+		args = append(args, searchPattern, searchPattern, searchPattern)
+	}
+
+	return " WHERE " + strings.Join(conditions, " AND "), args
+}
+
+// ListUnhealthyFiles returns one page of files in the given health statuses
+// (corrupted, repair_triggered and pending by default) for dashboards. Unlike
+// GetUnhealthyFiles it is not tied to worker scheduling: it ignores
+// scheduled_check_at and retry limits and supports offset pagination. Rows are
+// ordered by sortBy with id as a tiebreaker so pages never overlap.
+func (r *HealthRepository) ListUnhealthyFiles(ctx context.Context, statuses []HealthStatus, search string, limit, offset int, sortBy, sortOrder string) ([]*FileHealth, error) {
+	where, args := unhealthyFilesWhere(statuses, search)
+
+	// Build ORDER BY clause with validation
+	var orderByColumn string
+	switch sortBy {
+	case "file_path":
+		orderByColumn = "file_path"
+	case "created_at":
+		orderByColumn = "created_at"
+	case "status":
+		orderByColumn = "status"
+	case "last_checked":
+		orderByColumn = "last_checked"
+	case "retry_count":
+		orderByColumn = "retry_count"
+	case "repair_retry_count":
+		orderByColumn = "repair_retry_count"
+	default:
+		orderByColumn = "updated_at"
+	}
+
+	sortDirection := "DESC"
+	if sortOrder == "asc" {
+		sortDirection = "ASC"
+	}
+
+	query := `
+		SELECT id, file_path, status, last_checked, last_error, retry_count, max_retries,
+		       repair_retry_count, max_repair_retries, source_nzb_path,
+		       error_details, created_at, updated_at, scheduled_check_at,
+		       library_path, streaming_failure_count, is_masked, metadata, indexer
+		FROM file_health` + where +
+		fmt.Sprintf(" ORDER BY %s %s, id %s LIMIT ? OFFSET ?", orderByColumn, sortDirection, sortDirection)
+	args = append(args, limit, offset)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list unhealthy files: %w", err)
+	}
+	defer rows.Close()
+
+	var files []*FileHealth
+	for rows.Next() {
+		var health FileHealth
+		err := rows.Scan(
+			&health.ID, &health.FilePath, &health.Status, &health.LastChecked,
+			&health.LastError, &health.RetryCount, &health.MaxRetries,
+			&health.RepairRetryCount, &health.MaxRepairRetries,
+			&health.SourceNzbPath, &health.ErrorDetails,
+			&health.CreatedAt, &health.UpdatedAt, &health.ScheduledCheckAt,
+			&health.LibraryPath, &health.StreamingFailureCount, &health.IsMasked,
+			&health.Metadata, &health.Indexer,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan unhealthy file: %w", err)
+		}
+		files = append(files, &health)
+	}
+
+	return files, rows.Err()
+}
+
+// CountUnhealthyFiles returns the total number of rows ListUnhealthyFiles
+// would page through for the same filters.
+func (r *HealthRepository) CountUnhealthyFiles(ctx context.Context, statuses []HealthStatus, search string) (int, error) {
+	where, args := unhealthyFilesWhere(statuses, search)
+
+	var count int
+	if err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM file_health"+where, args...).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count unhealthy files: %w", err)
+	}
+
+	return count, nil
+}
+
 // SetFileChecking sets a file's status to 'checking'
 func (r *HealthRepository) SetFileChecking(ctx context.Context, filePath string) error {
 	query := `
@@ -1317,7 +1600,10 @@ func (r *HealthRepository) UpdateHealthStatusBulk(ctx context.Context, updates [
 		UPDATE file_health
 		SET status = 'healthy', scheduled_check_at = ?, retry_count = 0,
 		    repair_retry_count = 0, last_error = NULL, error_details = NULL,
-		    updated_at = datetime('now'), last_checked = datetime('now')
+		    is_masked = CASE WHEN ? THEN FALSE ELSE is_masked END,
+		    streaming_failure_count = CASE WHEN ? THEN 0 ELSE streaming_failure_count END,
+		    updated_at = datetime('now'), last_checked = datetime('now'),
+		    last_verified_at = datetime('now')
 		WHERE file_path = ? AND (status = ? OR ? = '')
 	`)
 	if err != nil {
@@ -1381,7 +1667,8 @@ func (r *HealthRepository) UpdateHealthStatusBulk(ctx context.Context, updates [
 		UPDATE file_health
 		SET status = 'degraded', last_error = ?, error_details = ?,
 		    scheduled_check_at = ?,
-		    updated_at = datetime('now'), last_checked = datetime('now')
+		    updated_at = datetime('now'), last_checked = datetime('now'),
+		    last_verified_at = datetime('now')
 		WHERE file_path = ? AND (status = ? OR ? = '')
 	`)
 	if err != nil {
@@ -1402,7 +1689,7 @@ func (r *HealthRepository) UpdateHealthStatusBulk(ctx context.Context, updates [
 		}
 		switch update.Type {
 		case UpdateTypeHealthy:
-			_, err = stmtHealthy.ExecContext(ctx, update.ScheduledCheckAt, filePath, expected, expected)
+			_, err = stmtHealthy.ExecContext(ctx, update.ScheduledCheckAt, update.Unmask, update.Unmask, filePath, expected, expected)
 		case UpdateTypeRetry:
 			_, err = stmtRetry.ExecContext(ctx, update.ErrorMessage, update.ErrorDetails, update.ScheduledCheckAt, filePath, expected, expected)
 		case UpdateTypeRepairTrigger:
@@ -1451,6 +1738,9 @@ type HealthStatusUpdate struct {
 	// guarded UPDATE matches no rows and the concurrent actor's decision wins instead of
 	// being silently clobbered (last-writer-wins re-entering the repair loop).
 	ExpectedStatus *HealthStatus
+	// Unmask, on a healthy update, also clears the failure mask and resets the
+	// streaming failure count (Streaming.FailureMasking.AutoUnmaskOnHealthy).
+	Unmask bool
 }
 
 // BackfillRecord represents a record used for metadata backfilling
@@ -40,7 +40,8 @@ func setupTestDB(t *testing.T) *HealthRepository {
 			streaming_failure_count INTEGER DEFAULT 0,
 			is_masked BOOLEAN DEFAULT FALSE,
 			indexer TEXT DEFAULT NULL,
-			download_id TEXT DEFAULT NULL
+			download_id TEXT DEFAULT NULL,
+			last_verified_at DATETIME DEFAULT NULL
 		);
 	`)
 	require.NoError(t, err)
@@ -717,3 +718,25 @@ func TestRelinkFileByFilename_ConflictMerge(t *testing.T) {
 	assert.Nil(t, oldH)
 }
 
+
+func TestGetRepairTriggeredPathsByPrefix(t *testing.T) {
+	repo := setupTestDB(t)
+	ctx := context.Background()
+
+	_, err := repo.db.ExecContext(ctx, `
+		INSERT INTO file_health (file_path, status) VALUES
+			('tv/Show_S01/e01.mkv', 'repair_triggered'),
+			('tv/Show_S01/e02.mkv', 'healthy'),
+			('tv/Show_S01/extras/x.mkv', 'repair_triggered'),
+			('tv/ShowXS01/e01.mkv', 'repair_triggered')
+	`)
+	require.NoError(t, err)
+
+	paths, err := repo.GetRepairTriggeredPathsByPrefix(ctx, "/tv/Show_S01")
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"tv/Show_S01/e01.mkv", "tv/Show_S01/extras/x.mkv"}, paths)
+
+	paths, err = repo.GetRepairTriggeredPathsByPrefix(ctx, "tv/Other")
+	require.NoError(t, err)
+	assert.Empty(t, paths)
+}
@@ -0,0 +1,115 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/pressly/goose/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func insertBatchQueueItem(t *testing.T, db *sql.DB, id int64, nzbPath string, batchID any) {
+	t.Helper()
+	_, err := db.Exec(`INSERT INTO import_queue (id, nzb_path, status, batch_id) VALUES (?, ?, 'processing', ?)`,
+		id, nzbPath, batchID)
+	require.NoError(t, err)
+}
+
+func TestAddImportHistory_CopiesBatchIDFromQueueItem(t *testing.T) {
+	ctx := context.Background()
+	db := openMigratedTo(t, 36)
+	repo := NewRepository(db, DialectSQLite)
+
+	insertBatchQueueItem(t, db, 1, "/nzbs/Show.S01.nzb", "season-1")
+	insertBatchQueueItem(t, db, 2, "/nzbs/Movie.nzb", nil)
+
+	for i, name := range []string{"Show.S01E01.mkv", "Show.S01E02.mkv"} {
+		nzbID := int64(1)
+		require.NoError(t, repo.AddImportHistory(ctx, &ImportHistory{
+			NzbID: &nzbID, NzbName: "Show.S01.nzb", FileName: name,
+			FileSize: int64(100 + i), VirtualPath: "/tv/Show/" + name,
+		}))
+	}
+	movieID := int64(2)
+	require.NoError(t, repo.AddImportHistory(ctx, &ImportHistory{
+		NzbID: &movieID, NzbName: "Movie.nzb", FileName: "Movie.mkv", VirtualPath: "/movies/Movie.mkv",
+	}))
+
+	h, err := repo.GetImportHistoryByPath(ctx, "/tv/Show/Show.S01E01.mkv")
+	require.NoError(t, err)
+	require.NotNil(t, h)
+	require.NotNil(t, h.BatchID)
+	assert.Equal(t, "season-1", *h.BatchID)
+
+	h, err = repo.GetImportHistoryByPath(ctx, "/movies/Movie.mkv")
+	require.NoError(t, err)
+	require.NotNil(t, h)
+	assert.Nil(t, h.BatchID, "queue item without a batch leaves history ungrouped")
+
+	// An explicit batch id wins over the queue item's.
+	explicit := "manual"
+	require.NoError(t, repo.AddImportHistory(ctx, &ImportHistory{
+		NzbID: &movieID, NzbName: "Movie.nzb", FileName: "Movie.srt", VirtualPath: "/movies/Movie.srt", BatchID: &explicit,
+	}))
+	h, err = repo.GetImportHistoryByPath(ctx, "/movies/Movie.srt")
+	require.NoError(t, err)
+	require.NotNil(t, h.BatchID)
+	assert.Equal(t, "manual", *h.BatchID)
+}
+
+func TestListImportHistory_FiltersByBatchID(t *testing.T) {
+	ctx := context.Background()
+	db := openMigratedTo(t, 36)
+	repo := NewRepository(db, DialectSQLite)
+
+	insertBatchQueueItem(t, db, 1, "/nzbs/Show.S01.nzb", "season-1")
+	insertBatchQueueItem(t, db, 2, "/nzbs/Movie.nzb", "movies")
+
+	add := func(nzbID int64, name string) {
+		require.NoError(t, repo.AddImportHistory(ctx, &ImportHistory{
+			NzbID: &nzbID, NzbName: name, FileName: name, VirtualPath: "/" + name,
+		}))
+	}
+	add(1, "Show.S01E01.mkv")
+	add(1, "Show.S01E02.mkv")
+	add(2, "Movie.mkv")
+
+	season, err := repo.ListImportHistory(ctx, 50, 0, "", "", "season-1")
+	require.NoError(t, err)
+	var names []string
+	for _, h := range season {
+		names = append(names, h.FileName)
+	}
+	assert.ElementsMatch(t, []string{"Show.S01E01.mkv", "Show.S01E02.mkv"}, names)
+
+	all, err := repo.ListImportHistory(ctx, 50, 0, "", "", "")
+	require.NoError(t, err)
+	assert.Len(t, all, 3)
+
+	none, err := repo.ListImportHistory(ctx, 50, 0, "", "", "unknown")
+	require.NoError(t, err)
+	assert.Empty(t, none)
+}
+
+// TestMigration036_BackfillsBatchID verifies that existing history rows pick up
+// the batch id of a queue item that still exists.
+func TestMigration036_BackfillsBatchID(t *testing.T) {
+	db := openMigratedTo(t, 35)
+
+	insertBatchQueueItem(t, db, 1, "/nzbs/Show.S01.nzb", "season-1")
+	_, err := db.Exec(`
+		INSERT INTO import_history (nzb_id, nzb_name, file_name, virtual_path) VALUES
+			(1, 'Show.S01.nzb', 'Show.S01E01.mkv', '/tv/Show.S01E01.mkv'),
+			(NULL, 'Orphan.nzb', 'Orphan.mkv', '/movies/Orphan.mkv')`)
+	require.NoError(t, err)
+
+	require.NoError(t, goose.UpTo(db, "migrations/sqlite", 36))
+
+	var batch sql.NullString
+	require.NoError(t, db.QueryRow(`SELECT batch_id FROM import_history WHERE file_name = 'Show.S01E01.mkv'`).Scan(&batch))
+	assert.Equal(t, "season-1", batch.String)
+	require.NoError(t, db.QueryRow(`SELECT batch_id FROM import_history WHERE file_name = 'Orphan.mkv'`).Scan(&batch))
+	assert.False(t, batch.Valid)
+}
@@ -0,0 +1,12 @@
+-- +goose Up
+-- Explicit manual ordering within a priority tier. New rows take the next
+-- value, so existing rows are numbered in insertion order.
+ALTER TABLE import_queue ADD COLUMN sequence BIGINT NOT NULL DEFAULT 0;
+
+UPDATE import_queue SET sequence = id;
+
+CREATE INDEX idx_import_queue_claim_order ON import_queue(status, priority, sequence, created_at);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_import_queue_claim_order;
+ALTER TABLE import_queue DROP COLUMN IF EXISTS sequence;
@@ -0,0 +1,16 @@
+-- +goose Up
+-- Time of the last health check that finished with a playable verdict
+-- (healthy or degraded). NULL means the file was never verified: records
+-- created by the library sync start out 'healthy' without being checked.
+ALTER TABLE file_health ADD COLUMN last_verified_at TIMESTAMPTZ DEFAULT NULL;
+
+-- Records inserted as 'healthy' get last_checked = created_at; only a real
+-- check moves last_checked past it.
+UPDATE file_health
+SET last_verified_at = last_checked
+WHERE status IN ('healthy', 'degraded')
+  AND last_checked IS NOT NULL
+  AND last_checked > created_at;
+
+-- +goose Down
+ALTER TABLE file_health DROP COLUMN IF EXISTS last_verified_at;
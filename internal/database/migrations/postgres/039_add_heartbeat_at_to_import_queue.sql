@@ -0,0 +1,8 @@
+-- +goose Up
+-- Refreshed periodically by the worker processing the item. A 'processing'
+-- row whose heartbeat (or started_at, before the first beat) is older than
+-- the reclaim timeout belongs to a crashed worker and may be claimed again.
+ALTER TABLE import_queue ADD COLUMN heartbeat_at TIMESTAMPTZ DEFAULT NULL;
+
+-- +goose Down
+ALTER TABLE import_queue DROP COLUMN heartbeat_at;
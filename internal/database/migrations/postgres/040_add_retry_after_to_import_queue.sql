@@ -0,0 +1,8 @@
+-- +goose Up
+-- Set when a transient failure requeues an item: the item stays pending but is
+-- not claimed again before this time, so retries back off instead of running
+-- back to back. NULL means the item may be claimed at once.
+ALTER TABLE import_queue ADD COLUMN retry_after TIMESTAMPTZ DEFAULT NULL;
+
+-- +goose Down
+ALTER TABLE import_queue DROP COLUMN retry_after;
@@ -0,0 +1,36 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Queue items that exhausted their retries are moved here so import_queue only
+-- holds work that can still make progress. Rows keep the full queue context so
+-- they can be reviewed and requeued.
+CREATE TABLE IF NOT EXISTS import_dead_letter (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    queue_id INTEGER NOT NULL, -- id the item had in import_queue
+    download_id TEXT DEFAULT NULL,
+    nzb_path TEXT NOT NULL,
+    relative_path TEXT DEFAULT NULL,
+    storage_path TEXT DEFAULT NULL,
+    category TEXT DEFAULT NULL,
+    priority INTEGER NOT NULL DEFAULT 1,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    max_retries INTEGER NOT NULL DEFAULT 3,
+    last_error TEXT DEFAULT NULL,
+    batch_id TEXT DEFAULT NULL,
+    metadata TEXT DEFAULT NULL,
+    file_size BIGINT DEFAULT NULL,
+    target_path TEXT DEFAULT NULL,
+    skip_arr_notification BOOLEAN NOT NULL DEFAULT FALSE,
+    skip_post_import_links BOOLEAN NOT NULL DEFAULT FALSE,
+    indexer TEXT DEFAULT NULL,
+    queued_at DATETIME DEFAULT NULL,
+    dead_lettered_at DATETIME NOT NULL DEFAULT (datetime('now'))
+);
+-- +goose StatementEnd
+
+CREATE INDEX IF NOT EXISTS idx_dead_letter_dead_lettered_at ON import_dead_letter(dead_lettered_at);
+CREATE INDEX IF NOT EXISTS idx_dead_letter_nzb_path ON import_dead_letter(nzb_path);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_dead_letter_nzb_path;
+DROP INDEX IF EXISTS idx_dead_letter_dead_lettered_at;
+DROP TABLE IF EXISTS import_dead_letter;
@@ -0,0 +1,15 @@
+-- +goose Up
+-- Carry the queue item's batch_id into history so files imported together can
+-- be grouped after the queue row is gone.
+ALTER TABLE import_history ADD COLUMN batch_id TEXT DEFAULT NULL;
+
+CREATE INDEX idx_import_history_batch_id ON import_history(batch_id);
+
+-- Backfill from queue rows that still exist.
+UPDATE import_history
+SET batch_id = (SELECT batch_id FROM import_queue WHERE import_queue.id = import_history.nzb_id)
+WHERE batch_id IS NULL AND nzb_id IS NOT NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_import_history_batch_id;
+ALTER TABLE import_history DROP COLUMN batch_id;
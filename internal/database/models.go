@@ -51,6 +51,32 @@ type ImportQueueItem struct {
 	SkipArrNotification bool          `db:"skip_arr_notification"`
 	SkipPostImportLinks bool          `db:"skip_post_import_links"`
 	Indexer             *string       `db:"indexer"`
+	Sequence            int64         `db:"sequence"` // Manual order within a priority tier; lower is claimed first
+}
+
+// DeadLetterItem is a queue item that exhausted its retries and was moved out
+// of import_queue into import_dead_letter for operator review.
+type DeadLetterItem struct {
+	ID                  int64         `db:"id"`
+	QueueID             int64         `db:"queue_id"` // ID the item had in import_queue
+	DownloadID          *string       `db:"download_id"`
+	NzbPath             string        `db:"nzb_path"`
+	RelativePath        *string       `db:"relative_path"`
+	StoragePath         *string       `db:"storage_path"`
+	Category            *string       `db:"category"`
+	Priority            QueuePriority `db:"priority"`
+	Attempts            int           `db:"attempts"`
+	MaxRetries          int           `db:"max_retries"`
+	LastError           *string       `db:"last_error"`
+	BatchID             *string       `db:"batch_id"`
+	Metadata            *string       `db:"metadata"`
+	FileSize            *int64        `db:"file_size"`
+	TargetPath          *string       `db:"target_path"`
+	SkipArrNotification bool          `db:"skip_arr_notification"`
+	SkipPostImportLinks bool          `db:"skip_post_import_links"`
+	Indexer             *string       `db:"indexer"`
+	QueuedAt            *time.Time    `db:"queued_at"` // created_at of the original queue row
+	DeadLetteredAt      time.Time     `db:"dead_lettered_at"`
 }
 
 // BulkOperationResult represents the result of a bulk queue operation
@@ -182,18 +208,19 @@ type ImportHourlyStat struct {
 
 // ImportHistory represents a persistent record of a single imported file
 type ImportHistory struct {
-	ID                  int64     `db:"id"`
-	DownloadID          *string   `db:"download_id"`
-	NzbID               *int64    `db:"nzb_id"` // Nullable if queue item deleted
-	NzbName             string    `db:"nzb_name"`
-	FileName            string    `db:"file_name"`
-	FileSize            int64     `db:"file_size"`
-	VirtualPath         string    `db:"virtual_path"`
-	LibraryPath         *string   `db:"library_path"` // Added to show final location from file_health
-	Category            *string   `db:"category"`
-	Metadata            *string   `db:"metadata"`
-	Indexer             *string   `db:"indexer"`
-	CompletedAt         time.Time `db:"completed_at"`
+	ID          int64     `db:"id"`
+	DownloadID  *string   `db:"download_id"`
+	NzbID       *int64    `db:"nzb_id"` // Nullable if queue item deleted
+	NzbName     string    `db:"nzb_name"`
+	FileName    string    `db:"file_name"`
+	FileSize    int64     `db:"file_size"`
+	VirtualPath string    `db:"virtual_path"`
+	LibraryPath *string   `db:"library_path"` // Added to show final location from file_health
+	Category    *string   `db:"category"`
+	Metadata    *string   `db:"metadata"`
+	Indexer     *string   `db:"indexer"`
+	BatchID     *string   `db:"batch_id"` // Copied from the queue item so a release's files can be grouped
+	CompletedAt time.Time `db:"completed_at"`
 }
 
 // ImportMigrationStatus represents the status of a migration item
@@ -0,0 +1,126 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/pressly/goose/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func neverCheckedPaths(t *testing.T, repo *HealthRepository) []string {
+	t.Helper()
+	files, err := repo.GetNeverCheckedFiles(context.Background(), 100)
+	require.NoError(t, err)
+	paths := make([]string, 0, len(files))
+	for _, f := range files {
+		paths = append(paths, f.FilePath)
+	}
+	return paths
+}
+
+// TestGetNeverCheckedFiles_DistinguishesVerified verifies that only records no
+// health check has ever passed are returned: a freshly imported file and a
+// library-sync record that starts out 'healthy', but not files a check marked
+// healthy or degraded, nor records owned by a check/repair or already corrupted.
+func TestGetNeverCheckedFiles_DistinguishesVerified(t *testing.T) {
+	repo := setupTestDB(t)
+	ctx := context.Background()
+
+	require.NoError(t, repo.AddFileToHealthCheck(ctx, "imported.mkv", nil, 3, 3, nil, HealthPriorityNormal))
+	require.NoError(t, repo.AddFileToHealthCheck(ctx, "checked-healthy.mkv", nil, 3, 3, nil, HealthPriorityNormal))
+	require.NoError(t, repo.AddFileToHealthCheck(ctx, "checked-degraded.mkv", nil, 3, 3, nil, HealthPriorityNormal))
+	require.NoError(t, repo.AddFileToHealthCheck(ctx, "failed-once.mkv", nil, 3, 3, nil, HealthPriorityNormal))
+	require.NoError(t, repo.BatchAddAutomaticHealthChecks(ctx, []AutomaticHealthCheckRecord{
+		{FilePath: "synced.mkv", MaxRetries: 3, MaxRepairRetries: 3},
+	}))
+	_, err := repo.db.ExecContext(ctx, `
+		INSERT INTO file_health (file_path, status) VALUES
+			('in-check.mkv', 'checking'), ('repairing.mkv', 'repair_triggered'), ('dead.mkv', 'corrupted')
+	`)
+	require.NoError(t, err)
+
+	next := time.Now().UTC().Add(24 * time.Hour)
+	errMsg := "segment missing"
+	require.NoError(t, repo.UpdateHealthStatusBulk(ctx, []HealthStatusUpdate{
+		{Type: UpdateTypeHealthy, FilePath: "checked-healthy.mkv", ScheduledCheckAt: next},
+		{Type: UpdateTypeDegraded, FilePath: "checked-degraded.mkv", ErrorMessage: &errMsg, ScheduledCheckAt: next},
+		{Type: UpdateTypeRetry, FilePath: "failed-once.mkv", ErrorMessage: &errMsg, ScheduledCheckAt: next},
+	}))
+
+	assert.ElementsMatch(t, []string{"imported.mkv", "failed-once.mkv", "synced.mkv"}, neverCheckedPaths(t, repo))
+}
+
+// TestGetNeverCheckedFiles_ReimportClearsVerification verifies that re-adding a
+// verified file (re-import or ARR webhook) makes it unverified again, since the
+// record now describes new content.
+func TestGetNeverCheckedFiles_ReimportClearsVerification(t *testing.T) {
+	repo := setupTestDB(t)
+	ctx := context.Background()
+
+	require.NoError(t, repo.AddFileToHealthCheck(ctx, "movie.mkv", nil, 3, 3, nil, HealthPriorityNormal))
+	require.NoError(t, repo.UpdateHealthStatusBulk(ctx, []HealthStatusUpdate{
+		{Type: UpdateTypeHealthy, FilePath: "movie.mkv", ScheduledCheckAt: time.Now().UTC().Add(time.Hour)},
+	}))
+	assert.Empty(t, neverCheckedPaths(t, repo))
+
+	require.NoError(t, repo.BatchAddFileToHealthCheck(ctx, []HealthCheckUpsert{
+		{FilePath: "movie.mkv", MaxRetries: 3, MaxRepairRetries: 3, Priority: HealthPriorityNormal},
+	}))
+	assert.Equal(t, []string{"movie.mkv"}, neverCheckedPaths(t, repo))
+}
+
+// TestGetNeverCheckedFiles_OrderAndLimit verifies higher priority comes first,
+// then older records, and that limit is honored.
+func TestGetNeverCheckedFiles_OrderAndLimit(t *testing.T) {
+	repo := setupTestDB(t)
+	ctx := context.Background()
+
+	_, err := repo.db.ExecContext(ctx, `
+		INSERT INTO file_health (file_path, status, priority, created_at) VALUES
+			('old.mkv',    'pending', 0, '2024-01-01 00:00:00'),
+			('new.mkv',    'pending', 0, '2024-06-01 00:00:00'),
+			('urgent.mkv', 'pending', 2, '2024-09-01 00:00:00')
+	`)
+	require.NoError(t, err)
+
+	files, err := repo.GetNeverCheckedFiles(ctx, 2)
+	require.NoError(t, err)
+	require.Len(t, files, 2)
+	assert.Equal(t, "urgent.mkv", files[0].FilePath)
+	assert.Equal(t, "old.mkv", files[1].FilePath)
+}
+
+// TestMigration038_BackfillsLastVerifiedAt verifies that only healthy/degraded
+// rows whose last_checked moved past created_at (i.e. a check actually ran) are
+// marked verified; rows inserted directly as 'healthy' stay unverified.
+func TestMigration038_BackfillsLastVerifiedAt(t *testing.T) {
+	ctx := context.Background()
+	db := openMigratedTo(t, 37)
+
+	_, err := db.ExecContext(ctx, `
+		INSERT INTO file_health (file_path, status, created_at, last_checked) VALUES
+			('checked.mkv',  'healthy',  '2024-01-01 00:00:00', '2024-02-01 00:00:00'),
+			('degraded.mkv', 'degraded', '2024-01-01 00:00:00', '2024-02-01 00:00:00'),
+			('synced.mkv',   'healthy',  '2024-01-01 00:00:00', '2024-01-01 00:00:00'),
+			('retried.mkv',  'pending',  '2024-01-01 00:00:00', '2024-02-01 00:00:00')
+	`)
+	require.NoError(t, err)
+
+	require.NoError(t, goose.UpTo(db, "migrations/sqlite", 38))
+
+	for path, verified := range map[string]bool{
+		"checked.mkv":  true,
+		"degraded.mkv": true,
+		"synced.mkv":   false,
+		"retried.mkv":  false,
+	} {
+		var got sql.NullString
+		require.NoError(t, db.QueryRowContext(ctx,
+			`SELECT last_verified_at FROM file_health WHERE file_path = ?`, path).Scan(&got))
+		assert.Equal(t, verified, got.Valid, path)
+	}
+}
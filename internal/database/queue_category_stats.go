@@ -0,0 +1,76 @@
+package database
+
+import (
+	"context"
+	"fmt"
+)
+
+// CategoryQueueStats holds the queue counts for a single category.
+// Uncategorized items are reported under an empty Category.
+type CategoryQueueStats struct {
+	Category   string `json:"category"`
+	Pending    int    `json:"pending"` // pending + paused, as in QueueStats.TotalQueued
+	Processing int    `json:"processing"`
+	Completed  int    `json:"completed"`
+	Failed     int    `json:"failed"`
+}
+
+// GetQueueStatsByCategory returns queue counts broken down by category,
+// ordered by category name.
+func (r *Repository) GetQueueStatsByCategory(ctx context.Context) ([]CategoryQueueStats, error) {
+	return queueStatsByCategory(ctx, r.db)
+}
+
+// GetQueueStatsByCategory returns queue counts broken down by category,
+// ordered by category name.
+func (r *QueueRepository) GetQueueStatsByCategory(ctx context.Context) ([]CategoryQueueStats, error) {
+	return queueStatsByCategory(ctx, r.db)
+}
+
+func queueStatsByCategory(ctx context.Context, db DBQuerier) ([]CategoryQueueStats, error) {
+	const query = `
+		SELECT COALESCE(category, ''), status, COUNT(*)
+		FROM import_queue
+		WHERE status IN ('pending', 'paused', 'processing', 'completed', 'failed')
+		GROUP BY COALESCE(category, ''), status
+		ORDER BY COALESCE(category, '')
+	`
+
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get queue stats by category: %w", err)
+	}
+	defer rows.Close()
+
+	var stats []CategoryQueueStats
+	for rows.Next() {
+		var category, status string
+		var count int
+		if err := rows.Scan(&category, &status, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan queue category stats: %w", err)
+		}
+
+		// Rows arrive grouped by category, so a new category always starts a
+		// new entry.
+		if len(stats) == 0 || stats[len(stats)-1].Category != category {
+			stats = append(stats, CategoryQueueStats{Category: category})
+		}
+		s := &stats[len(stats)-1]
+
+		switch QueueStatus(status) {
+		case QueueStatusPending, QueueStatusPaused:
+			s.Pending += count
+		case QueueStatusProcessing:
+			s.Processing = count
+		case QueueStatusCompleted:
+			s.Completed = count
+		case QueueStatusFailed:
+			s.Failed = count
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate queue category stats: %w", err)
+	}
+
+	return stats, nil
+}
@@ -0,0 +1,53 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetQueueStatsByCategory(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+
+	seed := []struct {
+		category any
+		status   QueueStatus
+		n        int
+	}{
+		{"tv", QueueStatusPending, 3},
+		{"tv", QueueStatusProcessing, 1},
+		{"tv", QueueStatusFailed, 2},
+		{"movies", QueueStatusCompleted, 4},
+		{"movies", QueueStatusPending, 1},
+		{nil, QueueStatusFailed, 1},
+		{"movies", QueueStatusFallback, 5}, // not part of any bucket
+	}
+	i := 0
+	for _, s := range seed {
+		for range s.n {
+			i++
+			_, err := db.Connection().ExecContext(ctx,
+				`INSERT INTO import_queue (nzb_path, category, status) VALUES (?, ?, ?)`,
+				fmt.Sprintf("/nzbs/%d.nzb", i), s.category, s.status)
+			require.NoError(t, err)
+		}
+	}
+
+	want := []CategoryQueueStats{
+		{Category: "", Failed: 1},
+		{Category: "movies", Pending: 1, Completed: 4},
+		{Category: "tv", Pending: 3, Processing: 1, Failed: 2},
+	}
+
+	stats, err := NewRepository(db.Connection(), db.Dialect()).GetQueueStatsByCategory(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, want, stats)
+
+	stats, err = NewQueueRepository(db.Connection(), db.Dialect()).GetQueueStatsByCategory(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, want, stats)
+}
@@ -0,0 +1,82 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClock is a settable scheduling clock for QueueRepository.SetClock.
+type fakeClock struct{ t time.Time }
+
+func (c *fakeClock) now() time.Time          { return c.t }
+func (c *fakeClock) advance(d time.Duration) { c.t = c.t.Add(d) }
+
+func TestClaimNextQueueItem_ReclaimBoundaryFollowsClock(t *testing.T) {
+	repo, item := setupHeartbeatTestDB(t)
+	ctx := context.Background()
+	// Far from the database's own clock, so any comparison against SQLite's
+	// now would reclaim (or never reclaim) regardless of the boundary.
+	clock := &fakeClock{t: time.Date(2001, 2, 3, 4, 5, 6, 0, time.UTC)}
+	repo.SetClock(clock.now)
+
+	claimed, err := repo.ClaimNextQueueItem(ctx, 10*time.Minute)
+	require.NoError(t, err)
+	require.NotNil(t, claimed)
+	require.NotNil(t, claimed.StartedAt)
+	assert.True(t, claimed.StartedAt.Equal(clock.t), "started_at is stamped from the clock, got %v", claimed.StartedAt)
+
+	clock.advance(10 * time.Minute)
+	again, err := repo.ClaimNextQueueItem(ctx, 10*time.Minute)
+	require.NoError(t, err)
+	assert.Nil(t, again, "exactly reclaimAfter old is not yet orphaned")
+
+	clock.advance(time.Second)
+	again, err = repo.ClaimNextQueueItem(ctx, 10*time.Minute)
+	require.NoError(t, err)
+	require.NotNil(t, again, "past reclaimAfter the item is reclaimed")
+	assert.Equal(t, item.ID, again.ID)
+}
+
+func TestUpdateQueueItemHeartbeat_MovesBoundaryWithClock(t *testing.T) {
+	repo, item := setupHeartbeatTestDB(t)
+	ctx := context.Background()
+	clock := &fakeClock{t: time.Date(2001, 2, 3, 4, 5, 6, 0, time.UTC)}
+	repo.SetClock(clock.now)
+
+	_, err := repo.ClaimNextQueueItem(ctx, 10*time.Minute)
+	require.NoError(t, err)
+
+	clock.advance(8 * time.Minute)
+	require.NoError(t, repo.UpdateQueueItemHeartbeat(ctx, item.ID))
+
+	// Past the claim's boundary, but within the heartbeat's.
+	clock.advance(9 * time.Minute)
+	claimed, err := repo.ClaimNextQueueItem(ctx, 10*time.Minute)
+	require.NoError(t, err)
+	assert.Nil(t, claimed)
+
+	clock.advance(time.Minute + time.Second)
+	claimed, err = repo.ClaimNextQueueItem(ctx, 10*time.Minute)
+	require.NoError(t, err)
+	require.NotNil(t, claimed)
+	assert.Equal(t, item.ID, claimed.ID)
+}
+
+func TestUpdateQueueItemStatus_ProcessingStampsClock(t *testing.T) {
+	repo, item := setupHeartbeatTestDB(t)
+	ctx := context.Background()
+	clock := &fakeClock{t: time.Date(2001, 2, 3, 4, 5, 6, 0, time.UTC)}
+	repo.SetClock(clock.now)
+
+	require.NoError(t, repo.UpdateQueueItemStatus(ctx, item.ID, QueueStatusProcessing, nil))
+
+	clock.advance(10*time.Minute + time.Second)
+	claimed, err := repo.ClaimNextQueueItem(ctx, 10*time.Minute)
+	require.NoError(t, err)
+	require.NotNil(t, claimed, "heartbeat_at set on the status change is compared on the same clock")
+	assert.Equal(t, item.ID, claimed.ID)
+}
@@ -0,0 +1,95 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupHeartbeatTestDB opens a test database holding one pending item.
+func setupHeartbeatTestDB(t *testing.T) (*QueueRepository, *ImportQueueItem) {
+	t.Helper()
+	repo := newTestDB(t).Repository
+	item := &ImportQueueItem{NzbPath: "/nzbs/slow.nzb", Priority: QueuePriorityNormal, Status: QueueStatusPending, MaxRetries: 3}
+	require.NoError(t, repo.AddToQueue(context.Background(), item))
+	return repo, item
+}
+
+// ageProcessingItem moves started_at and heartbeat_at of id back by age.
+func ageProcessingItem(t *testing.T, repo *QueueRepository, id int64, age time.Duration) {
+	t.Helper()
+	then := time.Now().UTC().Add(-age).Format("2006-01-02 15:04:05")
+	_, err := repo.db.ExecContext(context.Background(),
+		`UPDATE import_queue SET started_at = ?, heartbeat_at = ? WHERE id = ?`, then, then, id)
+	require.NoError(t, err)
+}
+
+func TestClaimNextQueueItem_HeartbeatPreventsReclaim(t *testing.T) {
+	repo, item := setupHeartbeatTestDB(t)
+	ctx := context.Background()
+
+	claimed, err := repo.ClaimNextQueueItem(ctx, 10*time.Minute)
+	require.NoError(t, err)
+	require.NotNil(t, claimed)
+	assert.Equal(t, item.ID, claimed.ID)
+
+	// The import has been running for an hour, but its worker is alive.
+	ageProcessingItem(t, repo, item.ID, time.Hour)
+	require.NoError(t, repo.UpdateQueueItemHeartbeat(ctx, item.ID))
+
+	again, err := repo.ClaimNextQueueItem(ctx, 10*time.Minute)
+	require.NoError(t, err)
+	assert.Nil(t, again, "an item with a fresh heartbeat must not be reclaimed")
+}
+
+func TestClaimNextQueueItem_ReclaimsWithoutHeartbeat(t *testing.T) {
+	repo, item := setupHeartbeatTestDB(t)
+	ctx := context.Background()
+
+	_, err := repo.ClaimNextQueueItem(ctx, 10*time.Minute)
+	require.NoError(t, err)
+
+	// Within the timeout the item still belongs to its worker.
+	ageProcessingItem(t, repo, item.ID, 5*time.Minute)
+	claimed, err := repo.ClaimNextQueueItem(ctx, 10*time.Minute)
+	require.NoError(t, err)
+	assert.Nil(t, claimed)
+
+	// Past it, the worker is presumed dead.
+	ageProcessingItem(t, repo, item.ID, 15*time.Minute)
+
+	claimed, err = repo.ClaimNextQueueItem(ctx, 0)
+	require.NoError(t, err)
+	assert.Nil(t, claimed, "reclaiming is disabled with a zero timeout")
+
+	claimed, err = repo.ClaimNextQueueItem(ctx, 10*time.Minute)
+	require.NoError(t, err)
+	require.NotNil(t, claimed)
+	assert.Equal(t, item.ID, claimed.ID)
+	assert.Equal(t, QueueStatusProcessing, claimed.Status)
+
+	// Reclaiming starts a fresh heartbeat, so the new owner keeps the item.
+	claimed, err = repo.ClaimNextQueueItem(ctx, 10*time.Minute)
+	require.NoError(t, err)
+	assert.Nil(t, claimed)
+}
+
+func TestUpdateQueueItemHeartbeat_IgnoresFinishedItems(t *testing.T) {
+	repo, item := setupHeartbeatTestDB(t)
+	ctx := context.Background()
+
+	_, err := repo.ClaimNextQueueItem(ctx, 10*time.Minute)
+	require.NoError(t, err)
+	require.NoError(t, repo.UpdateQueueItemStatus(ctx, item.ID, QueueStatusCompleted, nil))
+	ageProcessingItem(t, repo, item.ID, time.Hour)
+
+	require.NoError(t, repo.UpdateQueueItemHeartbeat(ctx, item.ID))
+
+	var heartbeat time.Time
+	require.NoError(t, repo.db.QueryRowContext(ctx,
+		`SELECT heartbeat_at FROM import_queue WHERE id = ?`, item.ID).Scan(&heartbeat))
+	assert.WithinDuration(t, time.Now().Add(-time.Hour), heartbeat, time.Minute)
+}
@@ -0,0 +1,95 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPurgeImportHistoryOlderThan(t *testing.T) {
+	repo, _ := setupHeartbeatTestDB(t)
+	ctx := context.Background()
+	now := time.Date(2026, 3, 20, 12, 0, 0, 0, time.UTC)
+	repo.SetClock(func() time.Time { return now })
+
+	exec := func(query string, args ...any) {
+		t.Helper()
+		_, err := repo.db.ExecContext(ctx, query, args...)
+		require.NoError(t, err)
+	}
+	exec(`DELETE FROM import_queue`)
+	exec(`INSERT INTO import_daily_stats (day, completed_count, failed_count) VALUES
+		('2026-03-01', 3, 2), ('2026-03-10', 1, 1), ('2026-03-19', 4, 0)`)
+	// Ten days before now is the cutoff: 2026-03-10 12:00:00.
+	exec(`INSERT INTO import_queue (id, nzb_path, status, priority, completed_at, updated_at) VALUES
+		(1, 'old-done.nzb', 'completed', 1, '2026-03-01 08:00:00', '2026-03-01 08:00:00'),
+		(2, 'old-failed.nzb', 'failed', 1, NULL, '2026-03-01 10:00:00')`)
+	exec(`INSERT INTO import_history (nzb_id, nzb_name, file_name, file_size, virtual_path, completed_at) VALUES
+		(1, 'old', 'a.mkv', 1, '/a.mkv', '2026-03-01 08:00:00'),
+		(1, 'old', 'b.mkv', 1, '/b.mkv', '2026-03-01 08:00:00'),
+		(NULL, 'unlinked', 'c.mkv', 1, '/c.mkv', '2026-03-01 09:00:00'),
+		(NULL, 'edge', 'd.mkv', 1, '/d.mkv', '2026-03-10 11:59:59'),
+		(NULL, 'recent', 'e.mkv', 1, '/e.mkv', '2026-03-10 12:00:00'),
+		(NULL, 'recent', 'f.mkv', 1, '/f.mkv', '2026-03-19 10:00:00')`)
+
+	purged, err := repo.PurgeImportHistoryOlderThan(ctx, 10)
+	require.NoError(t, err)
+	assert.EqualValues(t, 4, purged)
+
+	var names []string
+	rows, err := repo.db.QueryContext(ctx, `SELECT file_name FROM import_history ORDER BY file_name`)
+	require.NoError(t, err)
+	for rows.Next() {
+		var name string
+		require.NoError(t, rows.Scan(&name))
+		names = append(names, name)
+	}
+	require.NoError(t, rows.Close())
+	assert.Equal(t, []string{"e.mkv", "f.mkv"}, names)
+
+	var queued int
+	require.NoError(t, repo.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM import_queue`).Scan(&queued))
+	assert.Equal(t, 2, queued, "queue items are left to their own retention")
+
+	// Both records of item 1 count once; the unlinked record counts alone.
+	// Failed counts have no history records and are untouched.
+	for day, want := range map[string][2]int{
+		"2026-03-01": {1, 2},
+		"2026-03-10": {0, 1},
+		"2026-03-19": {4, 0},
+	} {
+		var completed, failed int
+		require.NoError(t, repo.db.QueryRowContext(ctx,
+			`SELECT completed_count, failed_count FROM import_daily_stats WHERE day = ?`, day).Scan(&completed, &failed))
+		assert.Equal(t, want, [2]int{completed, failed}, day)
+	}
+}
+
+func TestPurgeImportHistoryOlderThan_ClampsStatsAtZero(t *testing.T) {
+	repo, _ := setupHeartbeatTestDB(t)
+	ctx := context.Background()
+	repo.SetClock(func() time.Time { return time.Date(2026, 3, 20, 12, 0, 0, 0, time.UTC) })
+
+	// Stats that were cleared after the imports finished must not go negative.
+	_, err := repo.db.ExecContext(ctx, `INSERT INTO import_daily_stats (day, completed_count, failed_count) VALUES ('2026-03-01', 0, 0)`)
+	require.NoError(t, err)
+	_, err = repo.db.ExecContext(ctx, `INSERT INTO import_history (nzb_name, file_name, file_size, virtual_path, completed_at) VALUES
+		('a', 'a.mkv', 1, '/a.mkv', '2026-03-01 08:00:00'),
+		('b', 'b.mkv', 1, '/b.mkv', '2026-03-01 08:00:00')`)
+	require.NoError(t, err)
+
+	_, err = repo.PurgeImportHistoryOlderThan(ctx, 1)
+	require.NoError(t, err)
+
+	var completed, failed int
+	require.NoError(t, repo.db.QueryRowContext(ctx,
+		`SELECT completed_count, failed_count FROM import_daily_stats WHERE day = '2026-03-01'`).Scan(&completed, &failed))
+	assert.Zero(t, completed)
+	assert.Zero(t, failed)
+
+	_, err = repo.PurgeImportHistoryOlderThan(ctx, 0)
+	assert.Error(t, err)
+}
@@ -0,0 +1,119 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupQueueStatsSchema creates the queue_stats table, optionally seeded with
+// the default row the initial migration inserts.
+func setupQueueStatsSchema(t *testing.T, db *sql.DB, seedRow bool) {
+	t.Helper()
+
+	_, err := db.Exec(`
+		CREATE TABLE queue_stats (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			total_queued INTEGER NOT NULL DEFAULT 0,
+			total_processing INTEGER NOT NULL DEFAULT 0,
+			total_completed INTEGER NOT NULL DEFAULT 0,
+			total_failed INTEGER NOT NULL DEFAULT 0,
+			avg_processing_time_ms INTEGER DEFAULT NULL,
+			last_updated DATETIME DEFAULT CURRENT_TIMESTAMP
+		)`)
+	require.NoError(t, err)
+
+	if seedRow {
+		// Deliberately stale counts, as left behind by a crashed run.
+		_, err = db.Exec(`INSERT INTO queue_stats (total_queued, total_processing, total_completed, total_failed)
+			VALUES (0, 7, 0, 0)`)
+		require.NoError(t, err)
+	}
+}
+
+func TestVerifyQueueIntegrity_AfterStaleReset(t *testing.T) {
+	db, err := sql.Open("sqlite3", ":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+
+	setupQueueSchema(t, db)
+	setupQueueStatsSchema(t, db, true)
+
+	now := time.Now()
+	// Older than the claim query's 10-minute threshold, and one younger: both
+	// are orphaned at startup.
+	insertQueueItemWithTime(t, db, 1, "stale.nzb", "processing", now.Add(-30*time.Minute))
+	insertQueueItemWithTime(t, db, 2, "recent.nzb", "processing", now.Add(-2*time.Minute))
+	insertQueueItem(t, db, 3, "pending.nzb", "pending")
+	insertQueueItem(t, db, 4, "done.nzb", "completed")
+	insertQueueItem(t, db, 5, "broken.nzb", "failed")
+
+	recovered, err := NewQueueRepository(db, DialectSQLite).ResetStaleItems(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), recovered)
+
+	repo := NewRepository(db, DialectSQLite)
+	report, err := repo.VerifyQueueIntegrity(context.Background())
+	require.NoError(t, err)
+
+	assert.False(t, report.StatsRowCreated)
+	assert.Empty(t, report.Inconsistencies)
+
+	assert.Equal(t, "pending", getQueueItemStatus(t, db, 1))
+	assert.Equal(t, "pending", getQueueItemStatus(t, db, 2))
+	assert.Equal(t, "completed", getQueueItemStatus(t, db, 4))
+	assert.Equal(t, 0, countQueueItemsByStatus(t, db, "processing"))
+
+	var startedAt *time.Time
+	require.NoError(t, db.QueryRow("SELECT started_at FROM import_queue WHERE id = 1").Scan(&startedAt))
+	assert.Nil(t, startedAt, "started_at should be cleared so the item is claimable immediately")
+
+	require.NotNil(t, report.Stats)
+	assert.Equal(t, 3, report.Stats.TotalQueued)
+	assert.Equal(t, 0, report.Stats.TotalProcessing)
+	assert.Equal(t, 1, report.Stats.TotalCompleted)
+	assert.Equal(t, 1, report.Stats.TotalFailed)
+
+	// The recovered items are claimable right away.
+	item, err := repo.ClaimNextQueueItem(context.Background())
+	require.NoError(t, err)
+	require.NotNil(t, item)
+}
+
+func TestVerifyQueueIntegrity_CreatesMissingStatsRow(t *testing.T) {
+	db, err := sql.Open("sqlite3", ":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+
+	setupQueueSchema(t, db)
+	setupQueueStatsSchema(t, db, false)
+	insertQueueItem(t, db, 1, "pending.nzb", "pending")
+
+	report, err := NewRepository(db, DialectSQLite).VerifyQueueIntegrity(context.Background())
+	require.NoError(t, err)
+
+	assert.True(t, report.StatsRowCreated)
+	assert.Empty(t, report.Inconsistencies)
+	assert.Equal(t, 1, report.Stats.TotalQueued)
+}
+
+func TestVerifyQueueIntegrity_ReportsItemsStillProcessing(t *testing.T) {
+	db, err := sql.Open("sqlite3", ":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+
+	setupQueueSchema(t, db)
+	setupQueueStatsSchema(t, db, true)
+	insertQueueItem(t, db, 1, "orphan.nzb", "processing")
+
+	report, err := NewRepository(db, DialectSQLite).VerifyQueueIntegrity(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, "processing", getQueueItemStatus(t, db, 1), "resetting items is ResetStaleItems' job")
+	assert.Contains(t, report.Inconsistencies, "1 items still processing after reset")
+}
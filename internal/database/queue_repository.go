@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"log/slog"
 	"path/filepath"
 	"strings"
 	"time"
@@ -32,6 +33,8 @@ func inPlaceholders(n int) string {
 type QueueRepository struct {
 	db      DBQuerier
 	dialect dialectHelper
+	// clock is the scheduling clock; nil means time.Now. See SetClock.
+	clock func() time.Time
 }
 
 // NewQueueRepository creates a new queue repository
@@ -42,6 +45,27 @@ func NewQueueRepository(db *sql.DB, d Dialect) *QueueRepository {
 	}
 }
 
+// SetClock sets the clock queue scheduling runs on. Claim and heartbeat times
+// are stamped from it and reclaim cutoffs computed against it, so no decision
+// compares the application's clock with the database server's. nil restores
+// time.Now. Call it before the repository is shared.
+func (r *QueueRepository) SetClock(now func() time.Time) {
+	r.clock = now
+}
+
+// now returns the scheduling clock's current time in UTC.
+func (r *QueueRepository) now() time.Time {
+	if r.clock == nil {
+		return time.Now().UTC()
+	}
+	return r.clock().UTC()
+}
+
+// nowStamp returns now() in the layout scheduling columns are stored in.
+func (r *QueueRepository) nowStamp() string {
+	return r.now().Format("2006-01-02 15:04:05")
+}
+
 // RemoveFromQueue removes an item from the queue
 func (r *QueueRepository) RemoveFromQueue(ctx context.Context, id int64) error {
 	query := `DELETE FROM import_queue WHERE id = ?`
@@ -159,7 +183,7 @@ func (r *QueueRepository) RestartQueueItemsBulk(ctx context.Context, ids []int64
 
 			query := fmt.Sprintf(`
 				UPDATE import_queue
-				SET status = 'pending', started_at = NULL, completed_at = NULL, error_message = NULL, updated_at = datetime('now')
+				SET status = 'pending', started_at = NULL, completed_at = NULL, error_message = NULL, retry_after = NULL, updated_at = datetime('now')
 				WHERE id IN (%s) AND status != 'processing'
 			`, inPlaceholders(len(chunk)))
 
@@ -174,8 +198,8 @@ func (r *QueueRepository) RestartQueueItemsBulk(ctx context.Context, ids []int64
 // AddToQueue adds a new NZB file to the import queue
 func (r *QueueRepository) AddToQueue(ctx context.Context, item *ImportQueueItem) error {
 	query := `
-		INSERT INTO import_queue (download_id, nzb_path, relative_path, category, priority, status, retry_count, max_retries, batch_id, metadata, file_size, target_path, skip_arr_notification, skip_post_import_links, indexer, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, datetime('now'), datetime('now'))
+		INSERT INTO import_queue (download_id, nzb_path, relative_path, category, priority, status, retry_count, max_retries, batch_id, metadata, file_size, target_path, skip_arr_notification, skip_post_import_links, indexer, sequence, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ` + nextQueueSequence + `, datetime('now'), datetime('now'))
 		ON CONFLICT(nzb_path) DO UPDATE SET
 		download_id = COALESCE(excluded.download_id, import_queue.download_id),
 		priority = CASE WHEN excluded.priority < priority THEN excluded.priority ELSE priority END,
@@ -188,6 +212,7 @@ func (r *QueueRepository) AddToQueue(ctx context.Context, item *ImportQueueItem)
 		indexer = COALESCE(excluded.indexer, import_queue.indexer),
 		retry_count = 0,
 		started_at = NULL,
+		retry_after = NULL,
 		updated_at = datetime('now'),
 		relative_path = excluded.relative_path
 		WHERE status NOT IN ('processing', 'pending')
@@ -255,22 +280,36 @@ func (r *QueueRepository) IsFileInQueue(ctx context.Context, filePath string) (b
 	return true, nil
 }
 
-// ClaimNextQueueItem atomically claims and returns the next available queue item
-func (r *QueueRepository) ClaimNextQueueItem(ctx context.Context) (*ImportQueueItem, error) {
+// ClaimNextQueueItem atomically claims and returns the next available queue item.
+// With reclaimAfter > 0, a 'processing' item whose heartbeat_at (or started_at,
+// before its first heartbeat) is older than reclaimAfter is treated as orphaned
+// by a crashed worker and can be claimed again; live workers keep their items
+// out of reach by calling UpdateQueueItemHeartbeat. reclaimAfter <= 0 only
+// claims pending items.
+func (r *QueueRepository) ClaimNextQueueItem(ctx context.Context, reclaimAfter time.Duration) (*ImportQueueItem, error) {
 	// Use immediate transaction to atomically claim an item
 	var claimedItem *ImportQueueItem
 
 	err := r.withQueueTransaction(ctx, func(txRepo *QueueRepository) error {
 		// First, get the next available item ID within the transaction
 		var itemID int64
+		var prevStatus QueueStatus
+		// Pending items requeued after a transient failure wait out their
+		// retry_after backoff first.
+		where := "(status = 'pending' AND (retry_after IS NULL OR retry_after <= ?))"
+		args := []any{txRepo.nowStamp()}
+		if reclaimAfter > 0 {
+			where = "(" + where + " OR (status = 'processing' AND COALESCE(heartbeat_at, started_at) < ?))"
+			args = append(args, txRepo.now().Add(-reclaimAfter).Format("2006-01-02 15:04:05"))
+		}
 		selectQuery := `
-			SELECT id FROM import_queue
-			WHERE status = 'pending'
-			ORDER BY priority ASC, created_at ASC
+			SELECT id, status FROM import_queue
+			WHERE ` + where + `
+			ORDER BY priority ASC, sequence ASC, created_at ASC
 			LIMIT 1
 		`
 
-		err := txRepo.db.QueryRowContext(ctx, selectQuery).Scan(&itemID)
+		err := txRepo.db.QueryRowContext(ctx, selectQuery, args...).Scan(&itemID, &prevStatus)
 		if err != nil {
 			if err == sql.ErrNoRows {
 				// No items available
@@ -279,14 +318,17 @@ func (r *QueueRepository) ClaimNextQueueItem(ctx context.Context) (*ImportQueueI
 			return fmt.Errorf("failed to select queue item: %w", err)
 		}
 
-		// Now atomically update that specific item and get all its data
+		// Now atomically update that specific item and get all its data. The
+		// status guard makes a concurrent claim (or a heartbeat-refreshed
+		// reclaim candidate turning pending) lose cleanly.
 		updateQuery := `
 			UPDATE import_queue
-			SET status = 'processing', started_at = datetime('now'), updated_at = datetime('now')
-			WHERE id = ? AND status = 'pending'
+			SET status = 'processing', started_at = ?, heartbeat_at = ?, retry_after = NULL, updated_at = ?
+			WHERE id = ? AND status = ?
 		`
 
-		result, err := txRepo.db.ExecContext(ctx, updateQuery, itemID)
+		stamp := txRepo.nowStamp()
+		result, err := txRepo.db.ExecContext(ctx, updateQuery, stamp, stamp, stamp, itemID, prevStatus)
 		if err != nil {
 			return fmt.Errorf("failed to claim queue item %d: %w", itemID, err)
 		}
@@ -304,7 +346,7 @@ func (r *QueueRepository) ClaimNextQueueItem(ctx context.Context) (*ImportQueueI
 		// Get the complete claimed item data
 		getQuery := `
 			SELECT id, download_id, nzb_path, relative_path, category, priority, status, created_at, updated_at,
-			       started_at, completed_at, retry_count, max_retries, error_message, batch_id, metadata, file_size, storage_path, target_path, skip_arr_notification, skip_post_import_links, indexer
+			       started_at, completed_at, retry_count, max_retries, error_message, batch_id, metadata, file_size, storage_path, target_path, skip_arr_notification, skip_post_import_links, indexer, sequence
 			FROM import_queue
 			WHERE id = ?
 		`
@@ -313,12 +355,17 @@ func (r *QueueRepository) ClaimNextQueueItem(ctx context.Context) (*ImportQueueI
 		err = txRepo.db.QueryRowContext(ctx, getQuery, itemID).Scan(
 			&item.ID, &item.DownloadID, &item.NzbPath, &item.RelativePath, &item.Category, &item.Priority, &item.Status,
 			&item.CreatedAt, &item.UpdatedAt, &item.StartedAt, &item.CompletedAt,
-			&item.RetryCount, &item.MaxRetries, &item.ErrorMessage, &item.BatchID, &item.Metadata, &item.FileSize, &item.StoragePath, &item.TargetPath, &item.SkipArrNotification, &item.SkipPostImportLinks, &item.Indexer,
+			&item.RetryCount, &item.MaxRetries, &item.ErrorMessage, &item.BatchID, &item.Metadata, &item.FileSize, &item.StoragePath, &item.TargetPath, &item.SkipArrNotification, &item.SkipPostImportLinks, &item.Indexer, &item.Sequence,
 		)
 		if err != nil {
 			return fmt.Errorf("failed to get claimed item: %w", err)
 		}
 
+		if prevStatus == QueueStatusProcessing {
+			slog.WarnContext(ctx, "Reclaiming queue item with a stale heartbeat",
+				"queue_id", item.ID, "file", item.NzbPath, "reclaim_after", reclaimAfter)
+		}
+
 		claimedItem = &item
 		return nil
 	})
@@ -330,16 +377,33 @@ func (r *QueueRepository) ClaimNextQueueItem(ctx context.Context) (*ImportQueueI
 	return claimedItem, nil
 }
 
+// UpdateQueueItemHeartbeat records that the worker processing id is still
+// alive, keeping ClaimNextQueueItem from reclaiming it. It is a no-op once the
+// item has left the 'processing' status.
+func (r *QueueRepository) UpdateQueueItemHeartbeat(ctx context.Context, id int64) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE import_queue SET heartbeat_at = ?
+		WHERE id = ? AND status = 'processing'
+	`, r.nowStamp(), id)
+	if err != nil {
+		return fmt.Errorf("failed to update queue item heartbeat: %w", err)
+	}
+	return nil
+}
+
 // UpdateQueueItemStatus updates the status of a queue item
 func (r *QueueRepository) UpdateQueueItemStatus(ctx context.Context, id int64, status QueueStatus, errorMessage *string) error {
-	now := time.Now()
+	now := r.now()
 	var query string
 	var args []any
 
 	switch status {
 	case QueueStatusProcessing:
-		query = `UPDATE import_queue SET status = ?, started_at = ?, updated_at = ? WHERE id = ?`
-		args = []any{status, now, now, id}
+		// started_at and heartbeat_at drive reclaiming; store them the way
+		// ClaimNextQueueItem compares them.
+		stamp := now.Format("2006-01-02 15:04:05")
+		query = `UPDATE import_queue SET status = ?, started_at = ?, heartbeat_at = ?, updated_at = ? WHERE id = ?`
+		args = []any{status, stamp, stamp, now, id}
 	case QueueStatusCompleted:
 		query = `UPDATE import_queue SET status = ?, completed_at = ?, updated_at = ?, error_message = NULL WHERE id = ?`
 		args = []any{status, now, now, id}
@@ -480,15 +544,16 @@ func (r *QueueRepository) GetImportHistory(ctx context.Context, days int) ([]*Im
 	return r.GetImportDailyStats(ctx, days)
 }
 
-// AddImportHistory records a successful file import in the persistent history table
+// AddImportHistory records a successful file import in the persistent history table.
+// When history.BatchID is nil the batch id is taken from the queue item NzbID refers to.
 func (r *QueueRepository) AddImportHistory(ctx context.Context, history *ImportHistory) error {
 	query := `
-		INSERT INTO import_history (download_id, nzb_id, nzb_name, file_name, file_size, virtual_path, category, metadata, indexer, completed_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, datetime('now'))
+		INSERT INTO import_history (download_id, nzb_id, nzb_name, file_name, file_size, virtual_path, category, metadata, indexer, batch_id, completed_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, COALESCE(?, (SELECT batch_id FROM import_queue WHERE id = ?)), datetime('now'))
 	`
 	_, err := r.db.ExecContext(ctx, query,
 		history.DownloadID, history.NzbID, history.NzbName, history.FileName, history.FileSize,
-		history.VirtualPath, history.Category, history.Metadata, history.Indexer)
+		history.VirtualPath, history.Category, history.Metadata, history.Indexer, history.BatchID, history.NzbID)
 	if err != nil {
 		return fmt.Errorf("failed to add import history: %w", err)
 	}
@@ -498,7 +563,7 @@ func (r *QueueRepository) AddImportHistory(ctx context.Context, history *ImportH
 // ListImportHistory retrieves the last N successful imports from the persistent history
 func (r *QueueRepository) ListImportHistory(ctx context.Context, limit int) ([]*ImportHistory, error) {
 	query := `
-		SELECT h.id, h.download_id, h.nzb_id, h.nzb_name, h.file_name, h.file_size, h.virtual_path, f.library_path, h.category, h.metadata, h.indexer, h.completed_at
+		SELECT h.id, h.download_id, h.nzb_id, h.nzb_name, h.file_name, h.file_size, h.virtual_path, f.library_path, h.category, h.metadata, h.indexer, h.batch_id, h.completed_at
 		FROM import_history h
 		LEFT JOIN file_health f ON h.virtual_path = f.file_path
 		ORDER BY h.completed_at DESC
@@ -513,7 +578,7 @@ func (r *QueueRepository) ListImportHistory(ctx context.Context, limit int) ([]*
 	var history []*ImportHistory
 	for rows.Next() {
 		var h ImportHistory
-		err := rows.Scan(&h.ID, &h.DownloadID, &h.NzbID, &h.NzbName, &h.FileName, &h.FileSize, &h.VirtualPath, &h.LibraryPath, &h.Category, &h.Metadata, &h.Indexer, &h.CompletedAt)
+		err := rows.Scan(&h.ID, &h.DownloadID, &h.NzbID, &h.NzbName, &h.FileName, &h.FileSize, &h.VirtualPath, &h.LibraryPath, &h.Category, &h.Metadata, &h.Indexer, &h.BatchID, &h.CompletedAt)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan import history: %w", err)
 		}
@@ -687,8 +752,8 @@ func (r *QueueRepository) AddBatchToQueue(ctx context.Context, items []*ImportQu
 	return r.withQueueTransaction(ctx, func(txRepo *QueueRepository) error {
 		// Prepare batch insert statement
 		query := `
-			INSERT INTO import_queue (download_id, nzb_path, relative_path, category, priority, status, retry_count, max_retries, batch_id, metadata, file_size, skip_arr_notification, skip_post_import_links, created_at, updated_at)
-			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, datetime('now'), datetime('now'))
+			INSERT INTO import_queue (download_id, nzb_path, relative_path, category, priority, status, retry_count, max_retries, batch_id, metadata, file_size, skip_arr_notification, skip_post_import_links, sequence, created_at, updated_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ` + nextQueueSequence + `, datetime('now'), datetime('now'))
 			ON CONFLICT(nzb_path) DO UPDATE SET
 			download_id = COALESCE(excluded.download_id, import_queue.download_id),
 			priority = CASE WHEN excluded.priority < priority THEN excluded.priority ELSE priority END,
@@ -808,7 +873,7 @@ func (r *QueueRepository) withQueueTransaction(ctx context.Context, fn func(*Que
 	}
 
 	// Create a repository that uses the transaction
-	txRepo := &QueueRepository{db: tx, dialect: r.dialect}
+	txRepo := &QueueRepository{db: tx, dialect: r.dialect, clock: r.clock}
 
 	err = fn(txRepo)
 	if err != nil {
@@ -877,18 +942,94 @@ func (r *QueueRepository) DeleteFailedItemsOlderThan(ctx context.Context, olderT
 	return deletedItems, nil
 }
 
-// DeleteImportHistoryOlderThan deletes import_history records completed before olderThan.
-func (r *QueueRepository) DeleteImportHistoryOlderThan(ctx context.Context, olderThan time.Time) error {
-	_, err := r.db.ExecContext(ctx,
-		`DELETE FROM import_history WHERE completed_at < ?`, olderThan)
+// PurgeImportHistoryOlderThan enforces a retention of days on import_history:
+// records that completed more than days ago are deleted, and the per-day
+// completed counts in import_daily_stats are reduced by the imports removed
+// from each day. A day counted one completion per queue item, so records of
+// the same item count once; records no longer linked to an item count
+// individually. The queue itself is left alone: finished items are cleaned
+// up by their own retention and back duplicate detection until then.
+// It returns the number of history records purged.
+func (r *QueueRepository) PurgeImportHistoryOlderThan(ctx context.Context, days int) (int64, error) {
+	if days <= 0 {
+		return 0, fmt.Errorf("invalid retention: %d days", days)
+	}
+
+	var purged int64
+	err := r.withQueueTransaction(ctx, func(txRepo *QueueRepository) error {
+		cutoff := txRepo.now().AddDate(0, 0, -days).Format("2006-01-02 15:04:05")
+
+		rows, err := txRepo.db.QueryContext(ctx, `
+			SELECT id, nzb_id, completed_at
+			FROM import_history
+			WHERE completed_at < ?`, cutoff)
+		if err != nil {
+			return fmt.Errorf("failed to query expired import history: %w", err)
+		}
+		defer rows.Close()
+
+		type itemKey struct {
+			day string
+			id  int64
+		}
+		seen := make(map[itemKey]struct{})
+		perDay := make(map[string]int)
+		for rows.Next() {
+			var id int64
+			var nzbID sql.NullInt64
+			var completedAt time.Time
+			if err := rows.Scan(&id, &nzbID, &completedAt); err != nil {
+				return fmt.Errorf("failed to scan expired import history: %w", err)
+			}
+			day := completedAt.UTC().Format("2006-01-02")
+			// Unlinked records get a key no queue item id can collide with.
+			key := itemKey{day: day, id: -id}
+			if nzbID.Valid {
+				key.id = nzbID.Int64
+			}
+			if _, ok := seen[key]; ok {
+				continue
+			}
+			seen[key] = struct{}{}
+			perDay[day]++
+		}
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("failed to read expired import history: %w", err)
+		}
+		rows.Close()
+
+		for day, completed := range perDay {
+			_, err := txRepo.db.ExecContext(ctx, `
+				UPDATE import_daily_stats
+				SET completed_count = CASE WHEN completed_count > ? THEN completed_count - ? ELSE 0 END,
+				    updated_at = datetime('now')
+				WHERE day = ?`,
+				completed, completed, day)
+			if err != nil {
+				return fmt.Errorf("failed to decrement daily stats for %s: %w", day, err)
+			}
+		}
+
+		result, err := txRepo.db.ExecContext(ctx,
+			`DELETE FROM import_history WHERE completed_at < ?`, cutoff)
+		if err != nil {
+			return fmt.Errorf("failed to delete old import history: %w", err)
+		}
+		purged, err = result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to get rows affected: %w", err)
+		}
+		return nil
+	})
 	if err != nil {
-		return fmt.Errorf("failed to delete old import history: %w", err)
+		return 0, err
 	}
-	return nil
+	return purged, nil
 }
 
 // ResetStaleItems resets processing items back to pending on service startup
-func (r *QueueRepository) ResetStaleItems(ctx context.Context) error {
+// and returns how many were reset.
+func (r *QueueRepository) ResetStaleItems(ctx context.Context) (int64, error) {
 	// Reset all items that are in 'processing' status
 	// Since the service is just starting up, any item marked as processing is from a previous interrupted run
 	query := `
@@ -898,15 +1039,15 @@ func (r *QueueRepository) ResetStaleItems(ctx context.Context) error {
 
 	result, err := r.db.ExecContext(ctx, query)
 	if err != nil {
-		return fmt.Errorf("failed to reset stale queue items: %w", err)
+		return 0, fmt.Errorf("failed to reset stale queue items: %w", err)
 	}
 
-	_, err = result.RowsAffected()
+	reset, err := result.RowsAffected()
 	if err != nil {
-		return fmt.Errorf("failed to get rows affected: %w", err)
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
 	}
 
-	return nil
+	return reset, nil
 }
 
 // ClearImportHistory deletes all records from the import_history and import_daily_stats tables
@@ -34,7 +34,7 @@ func TestResetStaleItems_ResetsAllProcessingItems(t *testing.T) {
 	repo := NewQueueRepository(db, DialectSQLite)
 
 	// Test: Reset stale items
-	err = repo.ResetStaleItems(context.Background())
+	_, err = repo.ResetStaleItems(context.Background())
 	require.NoError(t, err, "ResetStaleItems should not error")
 
 	// Verify: Both processing items were reset
@@ -63,7 +63,7 @@ func TestResetStaleItems_NoItemsToReset(t *testing.T) {
 	repo := NewQueueRepository(db, DialectSQLite)
 
 	// Test: Reset with no items
-	err = repo.ResetStaleItems(context.Background())
+	_, err = repo.ResetStaleItems(context.Background())
 	require.NoError(t, err, "Should not error on empty queue")
 
 	// Verify: No items in queue
@@ -90,7 +90,7 @@ func TestResetStaleItems_MixedStatuses(t *testing.T) {
 	repo := NewQueueRepository(db, DialectSQLite)
 
 	// Test: Reset stale items
-	err = repo.ResetStaleItems(context.Background())
+	_, err = repo.ResetStaleItems(context.Background())
 	require.NoError(t, err)
 
 	// Verify: Only processing items are affected
@@ -123,7 +123,7 @@ func TestResetStaleItems_VeryOldItems(t *testing.T) {
 	repo := NewQueueRepository(db, DialectSQLite)
 
 	// Test: Reset stale items
-	err = repo.ResetStaleItems(context.Background())
+	_, err = repo.ResetStaleItems(context.Background())
 	require.NoError(t, err)
 
 	// Verify: All very old items were reset
@@ -186,7 +186,7 @@ func TestResetStaleItems_UpdatedAtFieldUpdated(t *testing.T) {
 	repo := NewQueueRepository(db, DialectSQLite)
 
 	// Test: Reset stale items
-	err = repo.ResetStaleItems(context.Background())
+	_, err = repo.ResetStaleItems(context.Background())
 	require.NoError(t, err)
 
 	// Verify: updated_at was changed
@@ -0,0 +1,52 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// RequeueFilter selects the failed queue items RequeueFailed resets. Empty
+// fields match everything.
+type RequeueFilter struct {
+	Category string     // case-insensitive category match
+	Search   string     // substring of nzb_path or relative_path
+	Since    *time.Time // only items that failed at or after this time
+}
+
+// RequeueFailed resets every failed queue item matching filter back to
+// pending with a cleared retry count and error, and returns how many items
+// were requeued. Typically used once a provider outage is over.
+func (r *Repository) RequeueFailed(ctx context.Context, filter RequeueFilter) (int, error) {
+	conditions, args := queueFilterConditions(filter.Search, filter.Category)
+	conditions = append([]string{"status = 'failed'"}, conditions...)
+
+	if filter.Since != nil {
+		conditions = append(conditions, "updated_at >= ?")
+		args = append(args, filter.Since.UTC().Format("2006-01-02 15:04:05"))
+	}
+
+	query := `
+		UPDATE import_queue
+		SET status = 'pending',
+		    retry_count = 0,
+		    retry_after = NULL,
+		    error_message = NULL,
+		    started_at = NULL,
+		    completed_at = NULL,
+		    updated_at = datetime('now')
+		WHERE ` + strings.Join(conditions, " AND ")
+
+	result, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to requeue failed queue items: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return int(rowsAffected), nil
+}
@@ -0,0 +1,94 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequeueFailed(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+	repo := NewRepository(db.Connection(), db.Dialect())
+
+	seed := func(t *testing.T) {
+		t.Helper()
+		_, err := db.Connection().ExecContext(ctx, `DELETE FROM import_queue`)
+		require.NoError(t, err)
+		for _, row := range []struct {
+			path, category string
+			status         QueueStatus
+			updatedAt      string
+		}{
+			{"/nzbs/tv/show.s01e01.nzb", "tv", QueueStatusFailed, "datetime('now')"},
+			{"/nzbs/tv/show.s01e02.nzb", "TV", QueueStatusFailed, "datetime('now', '-3 days')"},
+			{"/nzbs/tv/other.s01e01.nzb", "tv", QueueStatusFailed, "datetime('now')"},
+			{"/nzbs/tv/show.s01e03.nzb", "tv", QueueStatusCompleted, "datetime('now')"},
+			{"/nzbs/tv/show.s01e04.nzb", "tv", QueueStatusPending, "datetime('now')"},
+			{"/nzbs/movies/show.nzb", "movies", QueueStatusFailed, "datetime('now')"},
+		} {
+			_, err := db.Connection().ExecContext(ctx,
+				`INSERT INTO import_queue (nzb_path, category, status, retry_count, error_message, updated_at)
+				 VALUES (?, ?, ?, 3, 'provider down', `+row.updatedAt+`)`,
+				row.path, row.category, row.status)
+			require.NoError(t, err)
+		}
+	}
+
+	requeued := func(t *testing.T) []string {
+		t.Helper()
+		rows, err := db.Connection().QueryContext(ctx,
+			`SELECT nzb_path FROM import_queue
+			 WHERE status = 'pending' AND retry_count = 0 AND error_message IS NULL
+			 ORDER BY nzb_path`)
+		require.NoError(t, err)
+		defer rows.Close()
+		var paths []string
+		for rows.Next() {
+			var p string
+			require.NoError(t, rows.Scan(&p))
+			paths = append(paths, p)
+		}
+		require.NoError(t, rows.Err())
+		return paths
+	}
+
+	t.Run("category and search", func(t *testing.T) {
+		seed(t)
+		n, err := repo.RequeueFailed(ctx, RequeueFilter{Category: "tv", Search: "show."})
+		require.NoError(t, err)
+		assert.Equal(t, 2, n)
+		assert.Equal(t, []string{"/nzbs/tv/show.s01e01.nzb", "/nzbs/tv/show.s01e02.nzb"}, requeued(t))
+	})
+
+	t.Run("since", func(t *testing.T) {
+		seed(t)
+		since := time.Now().Add(-24 * time.Hour)
+		n, err := repo.RequeueFailed(ctx, RequeueFilter{Category: "tv", Since: &since})
+		require.NoError(t, err)
+		assert.Equal(t, 2, n)
+		assert.Equal(t, []string{"/nzbs/tv/other.s01e01.nzb", "/nzbs/tv/show.s01e01.nzb"}, requeued(t))
+	})
+
+	t.Run("no filter requeues every failed item", func(t *testing.T) {
+		seed(t)
+		n, err := repo.RequeueFailed(ctx, RequeueFilter{})
+		require.NoError(t, err)
+		assert.Equal(t, 4, n)
+
+		failed := QueueStatusFailed
+		count, err := repo.CountQueueItems(ctx, &failed, "", "")
+		require.NoError(t, err)
+		assert.Zero(t, count)
+	})
+
+	t.Run("nothing matches", func(t *testing.T) {
+		seed(t)
+		n, err := repo.RequeueFailed(ctx, RequeueFilter{Category: "music"})
+		require.NoError(t, err)
+		assert.Zero(t, n)
+	})
+}
@@ -0,0 +1,91 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// nextQueueSequence is the SQL expression new queue rows take as their
+// sequence, placing them after everything already queued.
+const nextQueueSequence = `(SELECT COALESCE(MAX(sequence), 0) + 1 FROM import_queue)`
+
+var (
+	// ErrQueueItemNotFound is returned when a reorder names a missing item.
+	ErrQueueItemNotFound = errors.New("queue item not found")
+	// ErrQueueItemProcessing is returned when a reorder targets an item a
+	// worker has already claimed.
+	ErrQueueItemProcessing = errors.New("queue item is being processed")
+)
+
+// MoveQueueItemBefore places item id immediately before targetID in claim
+// order, adopting the target's priority so items can be dragged across tiers.
+func (r *Repository) MoveQueueItemBefore(ctx context.Context, id, targetID int64) error {
+	return r.moveQueueItem(ctx, id, targetID, false)
+}
+
+// MoveQueueItemAfter places item id immediately after targetID in claim
+// order, adopting the target's priority.
+func (r *Repository) MoveQueueItemAfter(ctx context.Context, id, targetID int64) error {
+	return r.moveQueueItem(ctx, id, targetID, true)
+}
+
+// moveQueueItem opens a gap at the target's position by shifting every row
+// of the tier at or past it up by one, then drops the moved item into the gap.
+// Relative order of all other rows is preserved.
+func (r *Repository) moveQueueItem(ctx context.Context, id, targetID int64, after bool) error {
+	if id == targetID {
+		return fmt.Errorf("cannot move queue item %d relative to itself", id)
+	}
+
+	return r.WithImmediateTransaction(ctx, func(txRepo *Repository) error {
+		status, _, _, err := txRepo.queueItemOrder(ctx, id)
+		if err != nil {
+			return err
+		}
+		if status == QueueStatusProcessing {
+			return fmt.Errorf("failed to move queue item %d: %w", id, ErrQueueItemProcessing)
+		}
+
+		_, priority, sequence, err := txRepo.queueItemOrder(ctx, targetID)
+		if err != nil {
+			return err
+		}
+		if after {
+			sequence++
+		}
+
+		if _, err := txRepo.db.ExecContext(ctx, `
+			UPDATE import_queue SET sequence = sequence + 1
+			WHERE priority = ? AND sequence >= ? AND id != ?`,
+			priority, sequence, id); err != nil {
+			return fmt.Errorf("failed to shift queue sequence: %w", err)
+		}
+
+		if _, err := txRepo.db.ExecContext(ctx, `
+			UPDATE import_queue SET priority = ?, sequence = ?, updated_at = datetime('now')
+			WHERE id = ?`,
+			priority, sequence, id); err != nil {
+			return fmt.Errorf("failed to move queue item %d: %w", id, err)
+		}
+		return nil
+	})
+}
+
+// queueItemOrder returns the fields that decide an item's claim position.
+func (r *Repository) queueItemOrder(ctx context.Context, id int64) (QueueStatus, QueuePriority, int64, error) {
+	var status QueueStatus
+	var priority QueuePriority
+	var sequence int64
+	err := r.db.QueryRowContext(ctx,
+		`SELECT status, priority, sequence FROM import_queue WHERE id = ?`, id,
+	).Scan(&status, &priority, &sequence)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", 0, 0, fmt.Errorf("queue item %d: %w", id, ErrQueueItemNotFound)
+	}
+	if err != nil {
+		return "", 0, 0, fmt.Errorf("failed to read queue item %d: %w", id, err)
+	}
+	return status, priority, sequence, nil
+}
@@ -0,0 +1,99 @@
+package database
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func addSequenceTestItems(t *testing.T, repo *Repository, priority QueuePriority, names ...string) []int64 {
+	t.Helper()
+	ids := make([]int64, 0, len(names))
+	for _, name := range names {
+		item := &ImportQueueItem{NzbPath: "/nzbs/" + name + ".nzb", Priority: priority, Status: QueueStatusPending, MaxRetries: 3}
+		require.NoError(t, repo.AddToQueue(context.Background(), item))
+		ids = append(ids, item.ID)
+	}
+	return ids
+}
+
+func claimAllIDs(t *testing.T, repo *Repository) []int64 {
+	t.Helper()
+	var ids []int64
+	for {
+		item, err := repo.ClaimNextQueueItem(context.Background())
+		require.NoError(t, err)
+		if item == nil {
+			return ids
+		}
+		ids = append(ids, item.ID)
+	}
+}
+
+func TestQueueSequence_NewItemsQueueInInsertionOrder(t *testing.T) {
+	repo := NewRepository(newTestDB(t).Connection(), DialectSQLite)
+	ids := addSequenceTestItems(t, repo, QueuePriorityNormal, "a", "b", "c")
+
+	var last int64
+	for _, id := range ids {
+		item, err := repo.GetQueueItem(context.Background(), id)
+		require.NoError(t, err)
+		assert.Greater(t, item.Sequence, last)
+		last = item.Sequence
+	}
+	assert.Equal(t, ids, claimAllIDs(t, repo))
+}
+
+func TestQueueSequence_ReorderChangesClaimOrder(t *testing.T) {
+	repo := NewRepository(newTestDB(t).Connection(), DialectSQLite)
+	ctx := context.Background()
+	ids := addSequenceTestItems(t, repo, QueuePriorityNormal, "a", "b", "c", "d")
+	a, b, c, d := ids[0], ids[1], ids[2], ids[3]
+
+	require.NoError(t, repo.MoveQueueItemBefore(ctx, d, b)) // a d b c
+	require.NoError(t, repo.MoveQueueItemAfter(ctx, a, c))  // d b c a
+	require.NoError(t, repo.MoveQueueItemAfter(ctx, b, c))  // d c b a
+
+	assert.Equal(t, []int64{d, c, b, a}, claimAllIDs(t, repo))
+}
+
+func TestQueueSequence_PriorityStillWins(t *testing.T) {
+	repo := NewRepository(newTestDB(t).Connection(), DialectSQLite)
+	ctx := context.Background()
+	low := addSequenceTestItems(t, repo, QueuePriorityLow, "low1", "low2")
+	high := addSequenceTestItems(t, repo, QueuePriorityHigh, "high1")
+
+	// Reordering within the low tier never lifts it above the high tier.
+	require.NoError(t, repo.MoveQueueItemBefore(ctx, low[1], low[0]))
+	assert.Equal(t, []int64{high[0], low[1], low[0]}, claimAllIDs(t, repo))
+}
+
+func TestQueueSequence_MoveAcrossTiersAdoptsPriority(t *testing.T) {
+	repo := NewRepository(newTestDB(t).Connection(), DialectSQLite)
+	ctx := context.Background()
+	high := addSequenceTestItems(t, repo, QueuePriorityHigh, "high1", "high2")
+	normal := addSequenceTestItems(t, repo, QueuePriorityNormal, "normal1")
+
+	require.NoError(t, repo.MoveQueueItemAfter(ctx, normal[0], high[0]))
+
+	item, err := repo.GetQueueItem(ctx, normal[0])
+	require.NoError(t, err)
+	assert.Equal(t, QueuePriorityHigh, item.Priority)
+	assert.Equal(t, []int64{high[0], normal[0], high[1]}, claimAllIDs(t, repo))
+}
+
+func TestQueueSequence_MoveRejections(t *testing.T) {
+	repo := NewRepository(newTestDB(t).Connection(), DialectSQLite)
+	ctx := context.Background()
+	ids := addSequenceTestItems(t, repo, QueuePriorityNormal, "a", "b")
+
+	assert.Error(t, repo.MoveQueueItemBefore(ctx, ids[0], ids[0]))
+	assert.ErrorIs(t, repo.MoveQueueItemBefore(ctx, ids[0], 999), ErrQueueItemNotFound)
+	assert.ErrorIs(t, repo.MoveQueueItemBefore(ctx, 999, ids[0]), ErrQueueItemNotFound)
+
+	claimed, err := repo.ClaimNextQueueItem(ctx)
+	require.NoError(t, err)
+	assert.ErrorIs(t, repo.MoveQueueItemAfter(ctx, claimed.ID, ids[1]), ErrQueueItemProcessing)
+}
@@ -0,0 +1,77 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// RecomputeQueueStats rebuilds queue_stats from scratch. UpdateQueueStats
+// only refreshes the latest row in place, so it never repairs duplicate rows,
+// an empty table, or an average thrown off by items whose completed_at
+// precedes started_at; this replaces the whole table with a single row
+// derived from the current import_queue.
+//
+// The average covers completed items whose timestamps are in order. When no
+// such item remains (e.g. completed rows were cleared), the previously
+// persisted average is carried forward as the only historical figure left
+// rather than being reset to NULL.
+func (r *Repository) RecomputeQueueStats(ctx context.Context) (*QueueStats, error) {
+	var stats *QueueStats
+	err := r.WithTransaction(ctx, func(txRepo *Repository) error {
+		prev, err := txRepo.latestQueueStats(ctx)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+
+		var queued, processing, completed, failed int
+		err = txRepo.db.QueryRowContext(ctx, `
+			SELECT
+				COALESCE(SUM(CASE WHEN status = 'pending' THEN 1 ELSE 0 END), 0),
+				COALESCE(SUM(CASE WHEN status = 'processing' THEN 1 ELSE 0 END), 0),
+				COALESCE(SUM(CASE WHEN status = 'completed' THEN 1 ELSE 0 END), 0),
+				COALESCE(SUM(CASE WHEN status = 'failed' THEN 1 ELSE 0 END), 0)
+			FROM import_queue`).Scan(&queued, &processing, &completed, &failed)
+		if err != nil {
+			return fmt.Errorf("failed to count queue items: %w", err)
+		}
+
+		var avgFloat sql.NullFloat64
+		err = txRepo.db.QueryRowContext(ctx, fmt.Sprintf(`
+			SELECT AVG(%s)
+			FROM import_queue
+			WHERE status = 'completed' AND started_at IS NOT NULL AND completed_at IS NOT NULL
+			  AND completed_at >= started_at
+		`, txRepo.dialect.AvgProcessingTimeMS("started_at", "completed_at"))).Scan(&avgFloat)
+		if err != nil {
+			return fmt.Errorf("failed to calculate average processing time: %w", err)
+		}
+
+		var avgTime any
+		if avgFloat.Valid {
+			avgTime = int64(avgFloat.Float64)
+		} else if prev != nil && prev.AvgProcessingTimeMs != nil {
+			avgTime = int64(*prev.AvgProcessingTimeMs)
+		}
+
+		if _, err := txRepo.db.ExecContext(ctx, `DELETE FROM queue_stats`); err != nil {
+			return fmt.Errorf("failed to clear queue stats: %w", err)
+		}
+		if _, err := txRepo.db.ExecContext(ctx, `
+			INSERT INTO queue_stats (total_queued, total_processing, total_completed, total_failed,
+			                         avg_processing_time_ms, last_updated)
+			VALUES (?, ?, ?, ?, ?, ?)`,
+			queued, processing, completed, failed, avgTime, time.Now()); err != nil {
+			return fmt.Errorf("failed to insert queue stats: %w", err)
+		}
+
+		stats, err = txRepo.latestQueueStats(ctx)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
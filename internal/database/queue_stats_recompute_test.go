@@ -0,0 +1,89 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func insertTimedQueueItem(t *testing.T, db *sql.DB, id int64, status, startedAt, completedAt string) {
+	t.Helper()
+	_, err := db.Exec(`
+		INSERT INTO import_queue (id, nzb_path, status, priority, started_at, completed_at)
+		VALUES (?, ?, ?, 1, ?, ?)`, id, fmt.Sprintf("item-%d.nzb", id), status, startedAt, completedAt)
+	require.NoError(t, err)
+}
+
+func TestRecomputeQueueStats_RebuildsTotalsAndAverage(t *testing.T) {
+	ctx := context.Background()
+	db := openMigratedTo(t, 36)
+	repo := NewRepository(db, DialectSQLite)
+
+	insertQueueItem(t, db, 1, "a.nzb", "pending")
+	insertQueueItem(t, db, 2, "b.nzb", "pending")
+	insertQueueItem(t, db, 3, "c.nzb", "processing")
+	insertQueueItem(t, db, 4, "d.nzb", "failed")
+	insertTimedQueueItem(t, db, 5, "completed", "2026-01-01 10:00:00", "2026-01-01 10:00:02")
+	insertTimedQueueItem(t, db, 6, "completed", "2026-01-01 10:00:00", "2026-01-01 10:00:04")
+	// A clock jump left completed_at before started_at; it must not drag the
+	// average negative.
+	insertTimedQueueItem(t, db, 7, "completed", "2026-01-01 10:00:00", "2026-01-01 09:00:00")
+
+	// Drift: a duplicate stale row on top of the migration's seed row.
+	_, err := db.Exec(`INSERT INTO queue_stats (total_queued, total_processing, total_completed, total_failed, avg_processing_time_ms)
+		VALUES (40, 9, 100, 3, 999999)`)
+	require.NoError(t, err)
+
+	stats, err := repo.RecomputeQueueStats(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, stats.TotalQueued)
+	assert.Equal(t, 1, stats.TotalProcessing)
+	assert.Equal(t, 3, stats.TotalCompleted)
+	assert.Equal(t, 1, stats.TotalFailed)
+	require.NotNil(t, stats.AvgProcessingTimeMs)
+	assert.InDelta(t, 3000, *stats.AvgProcessingTimeMs, 1)
+
+	var rows int
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM queue_stats`).Scan(&rows))
+	assert.Equal(t, 1, rows, "duplicate rows are collapsed")
+}
+
+func TestRecomputeQueueStats_KeepsAverageWhenCompletedRowsAreGone(t *testing.T) {
+	ctx := context.Background()
+	db := openMigratedTo(t, 36)
+	repo := NewRepository(db, DialectSQLite)
+
+	insertTimedQueueItem(t, db, 1, "completed", "2026-01-01 10:00:00", "2026-01-01 10:00:05")
+	_, err := repo.RecomputeQueueStats(ctx)
+	require.NoError(t, err)
+
+	_, err = db.Exec(`DELETE FROM import_queue`)
+	require.NoError(t, err)
+	insertQueueItem(t, db, 2, "next.nzb", "pending")
+
+	stats, err := repo.RecomputeQueueStats(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, stats.TotalQueued)
+	assert.Zero(t, stats.TotalCompleted)
+	require.NotNil(t, stats.AvgProcessingTimeMs, "the last known average is carried forward")
+	assert.InDelta(t, 5000, *stats.AvgProcessingTimeMs, 1)
+}
+
+func TestRecomputeQueueStats_EmptyTable(t *testing.T) {
+	ctx := context.Background()
+	db := openMigratedTo(t, 36)
+	repo := NewRepository(db, DialectSQLite)
+
+	_, err := db.Exec(`DELETE FROM queue_stats`)
+	require.NoError(t, err)
+	insertQueueItem(t, db, 1, "a.nzb", "pending")
+
+	stats, err := repo.RecomputeQueueStats(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, stats.TotalQueued)
+	assert.Nil(t, stats.AvgProcessingTimeMs)
+}
@@ -20,6 +20,9 @@ type DBQuerier interface {
 type Repository struct {
 	db      DBQuerier
 	dialect dialectHelper
+	// onBulkDelete, when set, is called with the number of rows removed by bulk
+	// queue/history deletes so the caller can schedule database maintenance.
+	onBulkDelete func(ctx context.Context, deleted int)
 }
 
 // NewRepository creates a new repository instance
@@ -30,6 +33,20 @@ func NewRepository(db *sql.DB, d Dialect) *Repository {
 	}
 }
 
+// SetBulkDeleteHook registers fn to be called after bulk queue and history
+// deletes with the number of rows removed. Transaction-scoped repositories do
+// not inherit the hook.
+func (r *Repository) SetBulkDeleteHook(fn func(ctx context.Context, deleted int)) {
+	r.onBulkDelete = fn
+}
+
+// notifyBulkDelete invokes the bulk-delete hook, if any, for a non-empty delete.
+func (r *Repository) notifyBulkDelete(ctx context.Context, deleted int) {
+	if r.onBulkDelete != nil && deleted > 0 {
+		r.onBulkDelete(ctx, deleted)
+	}
+}
+
 // Transaction support
 
 // WithTransaction executes a function within a database transaction
@@ -103,8 +120,8 @@ func (r *Repository) withTransactionMode(ctx context.Context, _ string, fn func(
 func (r *Repository) AddToQueue(ctx context.Context, item *ImportQueueItem) error {
 	// Use UPSERT with immediate lock to prevent conflicts during concurrent inserts
 	query := `
-		INSERT INTO import_queue (download_id, nzb_path, relative_path, category, priority, status, retry_count, max_retries, batch_id, metadata, file_size, target_path, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, datetime('now'), datetime('now'))
+		INSERT INTO import_queue (download_id, nzb_path, relative_path, category, priority, status, retry_count, max_retries, batch_id, metadata, file_size, target_path, sequence, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ` + nextQueueSequence + `, datetime('now'), datetime('now'))
 		ON CONFLICT(nzb_path) DO UPDATE SET
 		download_id = COALESCE(excluded.download_id, import_queue.download_id),
 		priority = CASE WHEN excluded.priority < priority THEN excluded.priority ELSE priority END,
@@ -160,12 +177,12 @@ func (r *Repository) ClaimNextQueueItem(ctx context.Context) (*ImportQueueItem,
 				SELECT id FROM import_queue
 				WHERE status = 'pending'
 				  AND (started_at IS NULL OR %s < datetime('now'))
-				ORDER BY priority ASC, created_at ASC
+				ORDER BY priority ASC, sequence ASC, created_at ASC
 				LIMIT 1
 			) AND status = 'pending'
 			RETURNING id, download_id, nzb_path, relative_path, category, priority, status,
 			          created_at, updated_at, started_at, completed_at,
-			          retry_count, max_retries, error_message, batch_id, metadata, file_size, target_path
+			          retry_count, max_retries, error_message, batch_id, metadata, file_size, target_path, sequence
 		`, r.dialect.ColumnPlusMinutes("started_at", 10))
 
 		var item ImportQueueItem
@@ -174,7 +191,7 @@ func (r *Repository) ClaimNextQueueItem(ctx context.Context) (*ImportQueueItem,
 			&item.Priority, &item.Status, &item.CreatedAt, &item.UpdatedAt,
 			&item.StartedAt, &item.CompletedAt, &item.RetryCount,
 			&item.MaxRetries, &item.ErrorMessage, &item.BatchID,
-			&item.Metadata, &item.FileSize, &item.TargetPath,
+			&item.Metadata, &item.FileSize, &item.TargetPath, &item.Sequence,
 		)
 		if err != nil {
 			if err == sql.ErrNoRows {
@@ -205,8 +222,8 @@ func (r *Repository) AddBatchToQueue(ctx context.Context, items []*ImportQueueIt
 	return r.WithImmediateTransaction(ctx, func(txRepo *Repository) error {
 		// Prepare batch insert statement
 		query := `
-			INSERT INTO import_queue (download_id, nzb_path, relative_path, category, priority, status, retry_count, max_retries, batch_id, metadata, file_size, target_path, created_at, updated_at)
-			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, datetime('now'), datetime('now'))
+			INSERT INTO import_queue (download_id, nzb_path, relative_path, category, priority, status, retry_count, max_retries, batch_id, metadata, file_size, target_path, sequence, created_at, updated_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ` + nextQueueSequence + `, datetime('now'), datetime('now'))
 			ON CONFLICT(nzb_path) DO UPDATE SET
 			download_id = COALESCE(excluded.download_id, import_queue.download_id),
 			priority = CASE WHEN excluded.priority < priority THEN excluded.priority ELSE priority END,
@@ -262,7 +279,7 @@ func (r *Repository) UpdateQueueItemStatus(ctx context.Context, id int64, status
 		// ClaimNextQueueItem skips pending rows whose started_at is within the
 		// last 10 minutes (orphan-recovery gate), so we must clear started_at
 		// when transitioning back to pending via retry.
-		query = `UPDATE import_queue SET status = ?, started_at = NULL, completed_at = NULL, error_message = NULL, retry_count = 0, updated_at = ? WHERE id = ?`
+		query = `UPDATE import_queue SET status = ?, started_at = NULL, completed_at = NULL, error_message = NULL, retry_count = 0, retry_after = NULL, updated_at = ? WHERE id = ?`
 		args = []any{status, now, id}
 	case QueueStatusCompleted:
 		query = `UPDATE import_queue SET status = ?, completed_at = ?, updated_at = ?, error_message = NULL WHERE id = ?`
@@ -310,7 +327,7 @@ func (r *Repository) IncrementDailyStat(ctx context.Context, statType string) er
 func (r *Repository) GetQueueItem(ctx context.Context, id int64) (*ImportQueueItem, error) {
 	query := `
 		SELECT id, download_id, nzb_path, relative_path, category, priority, status, created_at, updated_at,
-		       started_at, completed_at, retry_count, max_retries, error_message, batch_id, metadata, file_size, storage_path, target_path, indexer
+		       started_at, completed_at, retry_count, max_retries, error_message, batch_id, metadata, file_size, storage_path, target_path, indexer, sequence
 		FROM import_queue WHERE id = ?
 	`
 
@@ -318,7 +335,7 @@ func (r *Repository) GetQueueItem(ctx context.Context, id int64) (*ImportQueueIt
 	err := r.db.QueryRowContext(ctx, query, id).Scan(
 		&item.ID, &item.DownloadID, &item.NzbPath, &item.RelativePath, &item.Category, &item.Priority, &item.Status,
 		&item.CreatedAt, &item.UpdatedAt, &item.StartedAt, &item.CompletedAt,
-		&item.RetryCount, &item.MaxRetries, &item.ErrorMessage, &item.BatchID, &item.Metadata, &item.FileSize, &item.StoragePath, &item.TargetPath, &item.Indexer,
+		&item.RetryCount, &item.MaxRetries, &item.ErrorMessage, &item.BatchID, &item.Metadata, &item.FileSize, &item.StoragePath, &item.TargetPath, &item.Indexer, &item.Sequence,
 	)
 
 	if err != nil {
@@ -576,6 +593,8 @@ func (r *Repository) RemoveFromQueueBulk(ctx context.Context, ids []int64) (*Bul
 		return nil, fmt.Errorf("failed to get rows affected: %w", err)
 	}
 
+	r.notifyBulkDelete(ctx, int(rowsAffected))
+
 	return &BulkOperationResult{
 		DeletedCount:    int(rowsAffected),
 		ProcessingCount: 0,
@@ -601,6 +620,7 @@ func (r *Repository) RestartQueueItemsBulk(ctx context.Context, ids []int64) err
 		UPDATE import_queue 
 		SET status = 'pending',
 		    retry_count = 0,
+		    retry_after = NULL,
 		    error_message = NULL,
 		    started_at = NULL,
 		    completed_at = NULL,
@@ -725,13 +745,33 @@ func (r *Repository) UpdateQueueStats(ctx context.Context) error {
 	return nil
 }
 
+// queueFilterConditions builds the WHERE conditions for the search and
+// category filters shared by the queue list, count and requeue queries.
+func queueFilterConditions(search string, category string) ([]string, []any) {
+	var conditions []string
+	var args []any
+
+	if search != "" {
+		conditions = append(conditions, "(nzb_path LIKE ? OR relative_path LIKE ?)")
+		searchPattern := "%" + search + "%"
+		args = append(args, searchPattern, searchPattern)
+	}
+
+	if category != "" {
+		conditions = append(conditions, "LOWER(category) = LOWER(?)")
+		args = append(args, category)
+	}
+
+	return conditions, args
+}
+
 // ListQueueItems retrieves queue items with optional filtering
 func (r *Repository) ListQueueItems(ctx context.Context, status *QueueStatus, search string, category string, limit, offset int, sortBy, sortOrder string) ([]*ImportQueueItem, error) {
 	var query string
 	var args []any
 
 	baseSelect := `SELECT id, download_id, nzb_path, relative_path, category, priority, status, created_at, updated_at,
-	               started_at, completed_at, retry_count, max_retries, error_message, batch_id, metadata, file_size, storage_path, target_path, indexer
+	               started_at, completed_at, retry_count, max_retries, error_message, batch_id, metadata, file_size, storage_path, target_path, indexer, sequence
 	               FROM import_queue`
 
 	var conditions []string
@@ -742,16 +782,9 @@ func (r *Repository) ListQueueItems(ctx context.Context, status *QueueStatus, se
 		conditionArgs = append(conditionArgs, *status)
 	}
 
-	if search != "" {
-		conditions = append(conditions, "(nzb_path LIKE ? OR relative_path LIKE ?)")
-		searchPattern := "%" + search + "%"
-		conditionArgs = append(conditionArgs, searchPattern, searchPattern)
-	}
-
-	if category != "" {
-		conditions = append(conditions, "LOWER(category) = LOWER(?)")
-		conditionArgs = append(conditionArgs, category)
-	}
+	filterConditions, filterArgs := queueFilterConditions(search, category)
+	conditions = append(conditions, filterConditions...)
+	conditionArgs = append(conditionArgs, filterArgs...)
 
 	if len(conditions) > 0 {
 		query = baseSelect + " WHERE " + strings.Join(conditions, " AND ")
@@ -770,6 +803,9 @@ func (r *Repository) ListQueueItems(ctx context.Context, status *QueueStatus, se
 		orderByColumn = "status"
 	case "nzb_path":
 		orderByColumn = "nzb_path"
+	case "sequence":
+		// Claim order: the direction applies to sequence within each tier.
+		orderByColumn = "priority ASC, sequence"
 	default:
 		orderByColumn = "updated_at"
 	}
@@ -794,7 +830,7 @@ func (r *Repository) ListQueueItems(ctx context.Context, status *QueueStatus, se
 		err := rows.Scan(
 			&item.ID, &item.DownloadID, &item.NzbPath, &item.RelativePath, &item.Category, &item.Priority, &item.Status,
 			&item.CreatedAt, &item.UpdatedAt, &item.StartedAt, &item.CompletedAt,
-			&item.RetryCount, &item.MaxRetries, &item.ErrorMessage, &item.BatchID, &item.Metadata, &item.FileSize, &item.StoragePath, &item.TargetPath, &item.Indexer,
+			&item.RetryCount, &item.MaxRetries, &item.ErrorMessage, &item.BatchID, &item.Metadata, &item.FileSize, &item.StoragePath, &item.TargetPath, &item.Indexer, &item.Sequence,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan queue item: %w", err)
@@ -811,22 +847,15 @@ func (r *Repository) ListActiveQueueItems(ctx context.Context, search string, ca
 	var args []any
 
 	baseSelect := `SELECT id, download_id, nzb_path, relative_path, category, priority, status, created_at, updated_at,
-	               started_at, completed_at, retry_count, max_retries, error_message, batch_id, metadata, file_size, storage_path, target_path, indexer
+	               started_at, completed_at, retry_count, max_retries, error_message, batch_id, metadata, file_size, storage_path, target_path, indexer, sequence
 	               FROM import_queue`
 
 	conditions := []string{"(status = 'pending' OR status = 'processing' OR status = 'paused')"}
 	var conditionArgs []any
 
-	if search != "" {
-		conditions = append(conditions, "(nzb_path LIKE ? OR relative_path LIKE ?)")
-		searchPattern := "%" + search + "%"
-		conditionArgs = append(conditionArgs, searchPattern, searchPattern)
-	}
-
-	if category != "" {
-		conditions = append(conditions, "LOWER(category) = LOWER(?)")
-		conditionArgs = append(conditionArgs, category)
-	}
+	filterConditions, filterArgs := queueFilterConditions(search, category)
+	conditions = append(conditions, filterConditions...)
+	conditionArgs = append(conditionArgs, filterArgs...)
 
 	query = baseSelect + " WHERE " + strings.Join(conditions, " AND ")
 
@@ -841,6 +870,9 @@ func (r *Repository) ListActiveQueueItems(ctx context.Context, search string, ca
 		orderByColumn = "status"
 	case "nzb_path":
 		orderByColumn = "nzb_path"
+	case "sequence":
+		// Claim order: the direction applies to sequence within each tier.
+		orderByColumn = "priority ASC, sequence"
 	default:
 		orderByColumn = "updated_at"
 	}
@@ -865,7 +897,7 @@ func (r *Repository) ListActiveQueueItems(ctx context.Context, search string, ca
 		err := rows.Scan(
 			&item.ID, &item.DownloadID, &item.NzbPath, &item.RelativePath, &item.Category, &item.Priority, &item.Status,
 			&item.CreatedAt, &item.UpdatedAt, &item.StartedAt, &item.CompletedAt,
-			&item.RetryCount, &item.MaxRetries, &item.ErrorMessage, &item.BatchID, &item.Metadata, &item.FileSize, &item.StoragePath, &item.TargetPath, &item.Indexer,
+			&item.RetryCount, &item.MaxRetries, &item.ErrorMessage, &item.BatchID, &item.Metadata, &item.FileSize, &item.StoragePath, &item.TargetPath, &item.Indexer, &item.Sequence,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan queue item: %w", err)
@@ -891,16 +923,9 @@ func (r *Repository) CountQueueItems(ctx context.Context, status *QueueStatus, s
 		conditionArgs = append(conditionArgs, *status)
 	}
 
-	if search != "" {
-		conditions = append(conditions, "(nzb_path LIKE ? OR relative_path LIKE ?)")
-		searchPattern := "%" + search + "%"
-		conditionArgs = append(conditionArgs, searchPattern, searchPattern)
-	}
-
-	if category != "" {
-		conditions = append(conditions, "LOWER(category) = LOWER(?)")
-		conditionArgs = append(conditionArgs, category)
-	}
+	filterConditions, filterArgs := queueFilterConditions(search, category)
+	conditions = append(conditions, filterConditions...)
+	conditionArgs = append(conditionArgs, filterArgs...)
 
 	if len(conditions) > 0 {
 		query = baseQuery + " WHERE " + strings.Join(conditions, " AND ")
@@ -929,16 +954,9 @@ func (r *Repository) CountActiveQueueItems(ctx context.Context, search string, c
 	var conditions []string
 	var conditionArgs []any
 
-	if search != "" {
-		conditions = append(conditions, "(nzb_path LIKE ? OR relative_path LIKE ?)")
-		searchPattern := "%" + search + "%"
-		conditionArgs = append(conditionArgs, searchPattern, searchPattern)
-	}
-
-	if category != "" {
-		conditions = append(conditions, "LOWER(category) = LOWER(?)")
-		conditionArgs = append(conditionArgs, category)
-	}
+	filterConditions, filterArgs := queueFilterConditions(search, category)
+	conditions = append(conditions, filterConditions...)
+	conditionArgs = append(conditionArgs, filterArgs...)
 
 	if len(conditions) > 0 {
 		query = baseQuery + " AND " + strings.Join(conditions, " AND ")
@@ -1001,6 +1019,7 @@ func (r *Repository) clearQueueItemsByStatus(ctx context.Context, statuses ...Qu
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to get rows affected: %w", err)
 	}
+	r.notifyBulkDelete(ctx, int(rowsAffected))
 	return paths, int(rowsAffected), nil
 }
 
@@ -1073,15 +1092,16 @@ func (r *Repository) UpdateQueueItemsPriorityBulk(ctx context.Context, ids []int
 	return updated, skipped, nil
 }
 
-// AddImportHistory records a successful file import in the persistent history table
+// AddImportHistory records a successful file import in the persistent history table.
+// When history.BatchID is nil the batch id is taken from the queue item NzbID refers to.
 func (r *Repository) AddImportHistory(ctx context.Context, history *ImportHistory) error {
 	query := `
-		INSERT INTO import_history (download_id, nzb_id, nzb_name, file_name, file_size, virtual_path, category, indexer, completed_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, datetime('now'))
+		INSERT INTO import_history (download_id, nzb_id, nzb_name, file_name, file_size, virtual_path, category, indexer, batch_id, completed_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, COALESCE(?, (SELECT batch_id FROM import_queue WHERE id = ?)), datetime('now'))
 	`
 	_, err := r.db.ExecContext(ctx, query,
 		history.DownloadID, history.NzbID, history.NzbName, history.FileName, history.FileSize,
-		history.VirtualPath, history.Category, history.Indexer)
+		history.VirtualPath, history.Category, history.Indexer, history.BatchID, history.NzbID)
 	if err != nil {
 		return fmt.Errorf("failed to add import history: %w", err)
 	}
@@ -1091,7 +1111,7 @@ func (r *Repository) AddImportHistory(ctx context.Context, history *ImportHistor
 // GetImportHistoryByDownloadID retrieves an import history item by its DownloadID
 func (r *Repository) GetImportHistoryByDownloadID(ctx context.Context, downloadID string) (*ImportHistory, error) {
 	query := `
-		SELECT h.id, h.download_id, h.nzb_id, h.nzb_name, h.file_name, h.file_size, h.virtual_path, f.library_path, h.category, h.metadata, h.indexer, h.completed_at
+		SELECT h.id, h.download_id, h.nzb_id, h.nzb_name, h.file_name, h.file_size, h.virtual_path, f.library_path, h.category, h.metadata, h.indexer, h.batch_id, h.completed_at
 		FROM import_history h
 		LEFT JOIN file_health f ON TRIM(h.virtual_path, '/') = TRIM(f.file_path, '/')
 		WHERE h.download_id = ?
@@ -1099,7 +1119,7 @@ func (r *Repository) GetImportHistoryByDownloadID(ctx context.Context, downloadI
 	`
 
 	var h ImportHistory
-	err := r.db.QueryRowContext(ctx, query, downloadID).Scan(&h.ID, &h.DownloadID, &h.NzbID, &h.NzbName, &h.FileName, &h.FileSize, &h.VirtualPath, &h.LibraryPath, &h.Category, &h.Metadata, &h.Indexer, &h.CompletedAt)
+	err := r.db.QueryRowContext(ctx, query, downloadID).Scan(&h.ID, &h.DownloadID, &h.NzbID, &h.NzbName, &h.FileName, &h.FileSize, &h.VirtualPath, &h.LibraryPath, &h.Category, &h.Metadata, &h.Indexer, &h.BatchID, &h.CompletedAt)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
@@ -1115,7 +1135,7 @@ func (r *Repository) GetImportHistoryByDownloadID(ctx context.Context, downloadI
 // (nil, nil) when no matching row exists.
 func (r *Repository) GetImportHistoryByNzbID(ctx context.Context, nzbID int64) (*ImportHistory, error) {
 	query := `
-		SELECT h.id, h.download_id, h.nzb_id, h.nzb_name, h.file_name, h.file_size, h.virtual_path, f.library_path, h.category, h.metadata, h.indexer, h.completed_at
+		SELECT h.id, h.download_id, h.nzb_id, h.nzb_name, h.file_name, h.file_size, h.virtual_path, f.library_path, h.category, h.metadata, h.indexer, h.batch_id, h.completed_at
 		FROM import_history h
 		LEFT JOIN file_health f ON TRIM(h.virtual_path, '/') = TRIM(f.file_path, '/')
 		WHERE h.nzb_id = ?
@@ -1123,7 +1143,7 @@ func (r *Repository) GetImportHistoryByNzbID(ctx context.Context, nzbID int64) (
 	`
 
 	var h ImportHistory
-	err := r.db.QueryRowContext(ctx, query, nzbID).Scan(&h.ID, &h.DownloadID, &h.NzbID, &h.NzbName, &h.FileName, &h.FileSize, &h.VirtualPath, &h.LibraryPath, &h.Category, &h.Metadata, &h.Indexer, &h.CompletedAt)
+	err := r.db.QueryRowContext(ctx, query, nzbID).Scan(&h.ID, &h.DownloadID, &h.NzbID, &h.NzbName, &h.FileName, &h.FileSize, &h.VirtualPath, &h.LibraryPath, &h.Category, &h.Metadata, &h.Indexer, &h.BatchID, &h.CompletedAt)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
@@ -1137,7 +1157,7 @@ func (r *Repository) GetImportHistoryByNzbID(ctx context.Context, nzbID int64) (
 // GetImportHistoryByPath retrieves an import history item by its virtual path
 func (r *Repository) GetImportHistoryByPath(ctx context.Context, virtualPath string) (*ImportHistory, error) {
 	query := `
-		SELECT h.id, h.download_id, h.nzb_id, h.nzb_name, h.file_name, h.file_size, h.virtual_path, f.library_path, h.category, h.metadata, h.indexer, h.completed_at
+		SELECT h.id, h.download_id, h.nzb_id, h.nzb_name, h.file_name, h.file_size, h.virtual_path, f.library_path, h.category, h.metadata, h.indexer, h.batch_id, h.completed_at
 		FROM import_history h
 		LEFT JOIN file_health f ON TRIM(h.virtual_path, '/') = TRIM(f.file_path, '/')
 		WHERE TRIM(h.virtual_path, '/') = TRIM(?, '/')
@@ -1145,7 +1165,7 @@ func (r *Repository) GetImportHistoryByPath(ctx context.Context, virtualPath str
 	`
 
 	var h ImportHistory
-	err := r.db.QueryRowContext(ctx, query, virtualPath).Scan(&h.ID, &h.DownloadID, &h.NzbID, &h.NzbName, &h.FileName, &h.FileSize, &h.VirtualPath, &h.LibraryPath, &h.Category, &h.Metadata, &h.Indexer, &h.CompletedAt)
+	err := r.db.QueryRowContext(ctx, query, virtualPath).Scan(&h.ID, &h.DownloadID, &h.NzbID, &h.NzbName, &h.FileName, &h.FileSize, &h.VirtualPath, &h.LibraryPath, &h.Category, &h.Metadata, &h.Indexer, &h.BatchID, &h.CompletedAt)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
@@ -1156,20 +1176,22 @@ func (r *Repository) GetImportHistoryByPath(ctx context.Context, virtualPath str
 	return &h, nil
 }
 
-// ListImportHistory retrieves import history items with optional filtering and pagination
-func (r *Repository) ListImportHistory(ctx context.Context, limit, offset int, search string, category string) ([]*ImportHistory, error) {
+// ListImportHistory retrieves import history items with optional filtering and pagination.
+// A non-empty batchID limits the result to files imported from that batch.
+func (r *Repository) ListImportHistory(ctx context.Context, limit, offset int, search string, category string, batchID string) ([]*ImportHistory, error) {
 	query := `
-		SELECT h.id, h.download_id, h.nzb_id, h.nzb_name, h.file_name, h.file_size, h.virtual_path, f.library_path, h.category, h.metadata, h.indexer, h.completed_at
+		SELECT h.id, h.download_id, h.nzb_id, h.nzb_name, h.file_name, h.file_size, h.virtual_path, f.library_path, h.category, h.metadata, h.indexer, h.batch_id, h.completed_at
 		FROM import_history h
 		LEFT JOIN file_health f ON h.virtual_path = f.file_path
 		WHERE (? = '' OR h.nzb_name LIKE ? OR h.file_name LIKE ? OR h.virtual_path LIKE ?)
 		  AND (? = '' OR LOWER(h.category) = LOWER(?))
+		  AND (? = '' OR h.batch_id = ?)
 		ORDER BY h.completed_at DESC
 		LIMIT ? OFFSET ?
 	`
 
 	searchPattern := "%" + search + "%"
-	rows, err := r.db.QueryContext(ctx, query, search, searchPattern, searchPattern, searchPattern, category, category, limit, offset)
+	rows, err := r.db.QueryContext(ctx, query, search, searchPattern, searchPattern, searchPattern, category, category, batchID, batchID, limit, offset)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list import history: %w", err)
 	}
@@ -1178,7 +1200,7 @@ func (r *Repository) ListImportHistory(ctx context.Context, limit, offset int, s
 	var history []*ImportHistory
 	for rows.Next() {
 		var h ImportHistory
-		err := rows.Scan(&h.ID, &h.DownloadID, &h.NzbID, &h.NzbName, &h.FileName, &h.FileSize, &h.VirtualPath, &h.LibraryPath, &h.Category, &h.Metadata, &h.Indexer, &h.CompletedAt)
+		err := rows.Scan(&h.ID, &h.DownloadID, &h.NzbID, &h.NzbName, &h.FileName, &h.FileSize, &h.VirtualPath, &h.LibraryPath, &h.Category, &h.Metadata, &h.Indexer, &h.BatchID, &h.CompletedAt)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan import history: %w", err)
 		}
@@ -1198,7 +1220,7 @@ func (r *Repository) ListRecentImportHistory(ctx context.Context, minutes int, c
 	}
 
 	query := fmt.Sprintf(`
-		SELECT h.id, h.download_id, h.nzb_id, h.nzb_name, h.file_name, h.file_size, h.virtual_path, '' AS library_path, h.category, h.metadata, h.indexer, h.completed_at
+		SELECT h.id, h.download_id, h.nzb_id, h.nzb_name, h.file_name, h.file_size, h.virtual_path, '' AS library_path, h.category, h.metadata, h.indexer, h.batch_id, h.completed_at
 		FROM import_history h
 		WHERE h.completed_at >= %s
 		  AND (? = '' OR LOWER(h.category) = LOWER(?))
@@ -1214,7 +1236,7 @@ func (r *Repository) ListRecentImportHistory(ctx context.Context, minutes int, c
 	var history []*ImportHistory
 	for rows.Next() {
 		var h ImportHistory
-		err := rows.Scan(&h.ID, &h.DownloadID, &h.NzbID, &h.NzbName, &h.FileName, &h.FileSize, &h.VirtualPath, &h.LibraryPath, &h.Category, &h.Metadata, &h.Indexer, &h.CompletedAt)
+		err := rows.Scan(&h.ID, &h.DownloadID, &h.NzbID, &h.NzbName, &h.FileName, &h.FileSize, &h.VirtualPath, &h.LibraryPath, &h.Category, &h.Metadata, &h.Indexer, &h.BatchID, &h.CompletedAt)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan import history: %w", err)
 		}
@@ -1513,7 +1535,7 @@ func (r *Repository) GetProviderHistoricalStats(ctx context.Context, days int, i
 		if err := rows.Scan(&tsRaw, &stat.ProviderID, &stat.BytesDownloaded); err != nil {
 			return nil, fmt.Errorf("failed to scan provider historical stat: %w", err)
 		}
-		
+
 		switch v := tsRaw.(type) {
 		case time.Time:
 			stat.Timestamp = v
@@ -1695,14 +1717,14 @@ func (r *Repository) GetImportHistory(ctx context.Context, days int) ([]*ImportD
 // GetImportHistoryItem retrieves a specific import history item by ID
 func (r *Repository) GetImportHistoryItem(ctx context.Context, id int64) (*ImportHistory, error) {
 	query := `
-		SELECT h.id, h.download_id, h.nzb_id, h.nzb_name, h.file_name, h.file_size, h.virtual_path, f.library_path, h.category, h.completed_at
+		SELECT h.id, h.download_id, h.nzb_id, h.nzb_name, h.file_name, h.file_size, h.virtual_path, f.library_path, h.category, h.batch_id, h.completed_at
 		FROM import_history h
 		LEFT JOIN file_health f ON h.virtual_path = f.file_path
 		WHERE h.id = ?
 	`
 
 	var h ImportHistory
-	err := r.db.QueryRowContext(ctx, query, id).Scan(&h.ID, &h.DownloadID, &h.NzbID, &h.NzbName, &h.FileName, &h.FileSize, &h.VirtualPath, &h.LibraryPath, &h.Category, &h.CompletedAt)
+	err := r.db.QueryRowContext(ctx, query, id).Scan(&h.ID, &h.DownloadID, &h.NzbID, &h.NzbName, &h.FileName, &h.FileSize, &h.VirtualPath, &h.LibraryPath, &h.Category, &h.BatchID, &h.CompletedAt)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
@@ -1923,9 +1945,13 @@ func (r *Repository) GetSystemState(ctx context.Context, key string) (string, er
 func (r *Repository) ClearImportHistory(ctx context.Context) error {
 	// Clear history records
 	queryHistory := `DELETE FROM import_history`
-	if _, err := r.db.ExecContext(ctx, queryHistory); err != nil {
+	result, err := r.db.ExecContext(ctx, queryHistory)
+	if err != nil {
 		return fmt.Errorf("failed to clear import history: %w", err)
 	}
+	if deleted, err := result.RowsAffected(); err == nil {
+		r.notifyBulkDelete(ctx, int(deleted))
+	}
 
 	// Also clear daily stats
 	return r.ClearDailyStats(ctx)
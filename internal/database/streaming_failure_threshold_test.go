@@ -0,0 +1,92 @@
+package database
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStreamingFailureThreshold_GetSet(t *testing.T) {
+	ctx := context.Background()
+	repo := NewHealthRepository(openMigratedTo(t, 36), DialectSQLite)
+
+	_, ok, err := repo.GetStreamingFailureThreshold(ctx)
+	require.NoError(t, err)
+	assert.False(t, ok, "no override until one is set")
+
+	require.NoError(t, repo.SetStreamingFailureThreshold(ctx, 5))
+	threshold, ok, err := repo.GetStreamingFailureThreshold(ctx)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, 5, threshold)
+
+	require.NoError(t, repo.SetStreamingFailureThreshold(ctx, 0))
+	_, ok, err = repo.GetStreamingFailureThreshold(ctx)
+	require.NoError(t, err)
+	assert.False(t, ok, "0 clears the override")
+
+	assert.Error(t, repo.SetStreamingFailureThreshold(ctx, -1))
+}
+
+func TestIncrementStreamingFailureCount_UsesRuntimeThreshold(t *testing.T) {
+	ctx := context.Background()
+	db := openMigratedTo(t, 36)
+	repo := NewHealthRepository(db, DialectSQLite)
+
+	const path = "movies/Movie.mkv"
+	_, err := db.Exec(`INSERT INTO file_health (file_path, status) VALUES (?, 'healthy')`, path)
+	require.NoError(t, err)
+
+	// Configured threshold 2 would repair on the second failure; the runtime
+	// override of 3 holds masking one failure longer.
+	require.NoError(t, repo.SetStreamingFailureThreshold(ctx, 3))
+	for i := 1; i <= 2; i++ {
+		masked, repair, err := repo.IncrementStreamingFailureCount(ctx, path, 2)
+		require.NoError(t, err)
+		assert.False(t, masked, "failure %d is below the runtime threshold", i)
+		assert.False(t, repair, "failure %d is below the runtime threshold", i)
+	}
+	masked, repair, err := repo.IncrementStreamingFailureCount(ctx, path, 2)
+	require.NoError(t, err)
+	assert.True(t, masked)
+	assert.True(t, repair, "third failure reaches the runtime threshold")
+
+	// Lowering it at runtime takes effect on the very next failure.
+	require.NoError(t, repo.UnmaskFile(ctx, path))
+	require.NoError(t, repo.SetStreamingFailureThreshold(ctx, 1))
+	masked, repair, err = repo.IncrementStreamingFailureCount(ctx, path, 2)
+	require.NoError(t, err)
+	assert.True(t, masked)
+	assert.True(t, repair)
+
+	// Clearing the override falls back to the configured threshold.
+	require.NoError(t, repo.UnmaskFile(ctx, path))
+	require.NoError(t, repo.SetStreamingFailureThreshold(ctx, 0))
+	_, repair, err = repo.IncrementStreamingFailureCount(ctx, path, 2)
+	require.NoError(t, err)
+	assert.False(t, repair)
+	_, repair, err = repo.IncrementStreamingFailureCount(ctx, path, 2)
+	require.NoError(t, err)
+	assert.True(t, repair)
+}
+
+func TestIncrementStreamingFailureCount_NoThresholdNeverMasks(t *testing.T) {
+	ctx := context.Background()
+	db := openMigratedTo(t, 36)
+	repo := NewHealthRepository(db, DialectSQLite)
+
+	const path = "movies/Movie.mkv"
+	_, err := db.Exec(`INSERT INTO file_health (file_path, status) VALUES (?, 'healthy')`, path)
+	require.NoError(t, err)
+
+	masked, repair, err := repo.IncrementStreamingFailureCount(ctx, path, 0)
+	require.NoError(t, err)
+	assert.False(t, masked)
+	assert.True(t, repair)
+
+	fh, err := repo.GetFileHealth(ctx, path)
+	require.NoError(t, err)
+	assert.Zero(t, fh.StreamingFailureCount, "nothing is counted without a threshold")
+}
@@ -0,0 +1,86 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// systemSnapshotVersion is the format version ExportSystem writes and
+// ImportSystem accepts.
+const systemSnapshotVersion = 1
+
+// SystemSnapshot is the JSON form of the system_state and system_stats
+// tables written by ExportSystem.
+type SystemSnapshot struct {
+	Version int               `json:"version"`
+	State   map[string]string `json:"state"`
+	Stats   map[string]int64  `json:"stats"`
+}
+
+// ExportSystem serializes system_state and system_stats to JSON, so runtime
+// overrides such as the streaming failure threshold and the download
+// counters can be carried over to a rebuilt database with ImportSystem.
+func (r *Repository) ExportSystem(ctx context.Context) ([]byte, error) {
+	stats, err := r.GetSystemStats(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.QueryContext(ctx, `SELECT key, value FROM system_state`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get system state: %w", err)
+	}
+	defer rows.Close()
+
+	state := make(map[string]string)
+	for rows.Next() {
+		var key, value string
+		if err := rows.Scan(&key, &value); err != nil {
+			return nil, fmt.Errorf("failed to scan system state: %w", err)
+		}
+		state[key] = value
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get system state: %w", err)
+	}
+
+	data, err := json.Marshal(SystemSnapshot{Version: systemSnapshotVersion, State: state, Stats: stats})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode system snapshot: %w", err)
+	}
+	return data, nil
+}
+
+// ImportSystem replaces system_state and system_stats with the contents of a
+// snapshot written by ExportSystem, in one transaction: keys missing from the
+// snapshot are removed, so the tables end up exactly as exported.
+func (r *Repository) ImportSystem(ctx context.Context, data []byte) error {
+	var snap SystemSnapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return fmt.Errorf("failed to decode system snapshot: %w", err)
+	}
+	if snap.Version != systemSnapshotVersion {
+		return fmt.Errorf("unsupported system snapshot version %d", snap.Version)
+	}
+
+	return r.WithTransaction(ctx, func(txRepo *Repository) error {
+		if _, err := txRepo.db.ExecContext(ctx, `DELETE FROM system_state`); err != nil {
+			return fmt.Errorf("failed to clear system state: %w", err)
+		}
+		if _, err := txRepo.db.ExecContext(ctx, `DELETE FROM system_stats`); err != nil {
+			return fmt.Errorf("failed to clear system stats: %w", err)
+		}
+		for key, value := range snap.State {
+			if err := txRepo.UpdateSystemState(ctx, key, value); err != nil {
+				return err
+			}
+		}
+		for key, value := range snap.Stats {
+			if err := txRepo.UpdateSystemStat(ctx, key, value); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,64 @@
+package database
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportImportSystem(t *testing.T) {
+	ctx := context.Background()
+
+	src := NewRepository(newTestDB(t).Connection(), DialectSQLite)
+	require.NoError(t, src.BatchUpdateSystemStats(ctx, map[string]int64{
+		"bytes_downloaded":    123456789,
+		"articles_downloaded": 4242,
+		"max_download_speed":  99,
+	}))
+	require.NoError(t, src.UpdateSystemState(ctx, streamingFailureThresholdKey, "5"))
+	require.NoError(t, src.UpdateSystemState(ctx, "pool_stats", `{"a":1}`))
+
+	data, err := src.ExportSystem(ctx)
+	require.NoError(t, err)
+
+	dst := NewRepository(newTestDB(t).Connection(), DialectSQLite)
+	// Left over in the fresh database but absent from the snapshot.
+	require.NoError(t, dst.UpdateSystemState(ctx, "stale", "x"))
+	require.NoError(t, dst.ImportSystem(ctx, data))
+
+	wantStats, err := src.GetSystemStats(ctx)
+	require.NoError(t, err)
+	gotStats, err := dst.GetSystemStats(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, wantStats, gotStats)
+
+	for key, want := range map[string]string{
+		streamingFailureThresholdKey: "5",
+		"pool_stats":                 `{"a":1}`,
+		"stale":                      "",
+	} {
+		got, err := dst.GetSystemState(ctx, key)
+		require.NoError(t, err)
+		assert.Equal(t, want, got, key)
+	}
+
+	threshold, ok, err := NewHealthRepository(dst.db.(*dialectAwareDB).db, DialectSQLite).GetStreamingFailureThreshold(ctx)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, 5, threshold)
+}
+
+func TestImportSystem_RejectsBadSnapshot(t *testing.T) {
+	ctx := context.Background()
+	db := NewRepository(newTestDB(t).Connection(), DialectSQLite)
+	require.NoError(t, db.UpdateSystemStat(ctx, "bytes_downloaded", 7))
+
+	assert.Error(t, db.ImportSystem(ctx, []byte("not json")))
+	assert.Error(t, db.ImportSystem(ctx, []byte(`{"version":99,"stats":{"bytes_downloaded":1}}`)))
+
+	stats, err := db.GetSystemStats(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), stats["bytes_downloaded"], "a rejected snapshot changes nothing")
+}
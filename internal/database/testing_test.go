@@ -2,10 +2,25 @@ package database
 
 import (
 	"database/sql"
+	"path/filepath"
 	"testing"
 	"time"
 )
 
+// newTestDB opens a fresh SQLite database under t.TempDir() with the full
+// migration chain applied, so tests also cover the migrations. It is closed
+// when the test ends.
+func newTestDB(t *testing.T) *DB {
+	t.Helper()
+
+	db, err := NewDB(Config{Type: "sqlite", DatabasePath: filepath.Join(t.TempDir(), "test.db")})
+	if err != nil {
+		t.Fatalf("Failed to open test database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
 // setupQueueSchema creates the import_queue table for testing
 // This matches the production schema from migrations/001_initial_schema.sql
 func setupQueueSchema(t *testing.T, db *sql.DB) {
@@ -35,6 +50,9 @@ func setupQueueSchema(t *testing.T, db *sql.DB) {
 			skip_arr_notification BOOLEAN NOT NULL DEFAULT FALSE,
 			skip_post_import_links BOOLEAN NOT NULL DEFAULT FALSE,
 			indexer TEXT DEFAULT NULL,
+			sequence BIGINT NOT NULL DEFAULT 0,
+			heartbeat_at DATETIME DEFAULT NULL,
+			retry_after DATETIME DEFAULT NULL,
 			UNIQUE(nzb_path)
 		);
 
@@ -0,0 +1,136 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// seedHealthRows inserts one row per status with increasing updated_at so the
+// default sort (updated_at DESC) is deterministic.
+func seedHealthRows(t *testing.T, repo *HealthRepository, rows []struct {
+	path   string
+	status HealthStatus
+}) {
+	t.Helper()
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i, row := range rows {
+		_, err := repo.db.ExecContext(context.Background(), `
+			INSERT INTO file_health (file_path, status, retry_count, updated_at)
+			VALUES (?, ?, ?, ?)
+		`, row.path, row.status, i, base.Add(time.Duration(i)*time.Minute))
+		require.NoError(t, err)
+	}
+}
+
+func healthPaths(files []*FileHealth) []string {
+	out := make([]string, len(files))
+	for i, f := range files {
+		out[i] = f.FilePath
+	}
+	return out
+}
+
+func TestListUnhealthyFiles_StatusFiltering(t *testing.T) {
+	repo := setupTestDB(t)
+	ctx := context.Background()
+
+	seedHealthRows(t, repo, []struct {
+		path   string
+		status HealthStatus
+	}{
+		{"/a.mkv", HealthStatusHealthy},
+		{"/b.mkv", HealthStatusCorrupted},
+		{"/c.mkv", HealthStatusRepairTriggered},
+		{"/d.mkv", HealthStatusPending},
+		{"/e.mkv", HealthStatusChecking},
+		{"/f.mkv", HealthStatusCorrupted},
+	})
+
+	// Default: all unhealthy statuses, newest update first.
+	files, err := repo.ListUnhealthyFiles(ctx, nil, "", 50, 0, "", "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"/f.mkv", "/d.mkv", "/c.mkv", "/b.mkv"}, healthPaths(files))
+
+	count, err := repo.CountUnhealthyFiles(ctx, nil, "")
+	require.NoError(t, err)
+	assert.Equal(t, 4, count)
+
+	files, err = repo.ListUnhealthyFiles(ctx, []HealthStatus{HealthStatusCorrupted}, "", 50, 0, "", "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"/f.mkv", "/b.mkv"}, healthPaths(files))
+
+	files, err = repo.ListUnhealthyFiles(ctx, []HealthStatus{HealthStatusRepairTriggered, HealthStatusPending}, "", 50, 0, "", "")
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"/c.mkv", "/d.mkv"}, healthPaths(files))
+
+	count, err = repo.CountUnhealthyFiles(ctx, nil, "f.mkv")
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+}
+
+func TestListUnhealthyFiles_Sorting(t *testing.T) {
+	repo := setupTestDB(t)
+	ctx := context.Background()
+
+	seedHealthRows(t, repo, []struct {
+		path   string
+		status HealthStatus
+	}{
+		{"/b.mkv", HealthStatusCorrupted},
+		{"/c.mkv", HealthStatusPending},
+		{"/a.mkv", HealthStatusRepairTriggered},
+	})
+
+	files, err := repo.ListUnhealthyFiles(ctx, nil, "", 50, 0, "file_path", "asc")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"/a.mkv", "/b.mkv", "/c.mkv"}, healthPaths(files))
+
+	files, err = repo.ListUnhealthyFiles(ctx, nil, "", 50, 0, "retry_count", "desc")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"/a.mkv", "/c.mkv", "/b.mkv"}, healthPaths(files))
+
+	// Unknown sort fields fall back to updated_at instead of reaching the SQL.
+	files, err = repo.ListUnhealthyFiles(ctx, nil, "", 50, 0, "file_path; DROP TABLE file_health", "asc")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"/b.mkv", "/c.mkv", "/a.mkv"}, healthPaths(files))
+}
+
+func TestListUnhealthyFiles_PaginationIsStable(t *testing.T) {
+	repo := setupTestDB(t)
+	ctx := context.Background()
+
+	// Identical sort keys force the id tiebreaker to keep pages disjoint.
+	same := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := range 7 {
+		_, err := repo.db.ExecContext(ctx, `
+			INSERT INTO file_health (file_path, status, updated_at) VALUES (?, ?, ?)
+		`, fmt.Sprintf("/file-%d.mkv", i), HealthStatusCorrupted, same)
+		require.NoError(t, err)
+	}
+
+	var seen []string
+	for offset := 0; ; offset += 3 {
+		page, err := repo.ListUnhealthyFiles(ctx, nil, "", 3, offset, "updated_at", "desc")
+		require.NoError(t, err)
+		if len(page) == 0 {
+			break
+		}
+		assert.LessOrEqual(t, len(page), 3)
+		seen = append(seen, healthPaths(page)...)
+	}
+
+	assert.Len(t, seen, 7)
+	assert.ElementsMatch(t, []string{
+		"/file-0.mkv", "/file-1.mkv", "/file-2.mkv", "/file-3.mkv",
+		"/file-4.mkv", "/file-5.mkv", "/file-6.mkv",
+	}, seen)
+
+	count, err := repo.CountUnhealthyFiles(ctx, nil, "")
+	require.NoError(t, err)
+	assert.Equal(t, 7, count)
+}
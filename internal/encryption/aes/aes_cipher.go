@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 
+	"github.com/javi11/altmount/internal/bufpool"
 	"github.com/javi11/altmount/internal/utils"
 )
 
@@ -50,6 +51,22 @@ func (c *AesCipher) DecryptedSize(encryptedFileSize int64) (int64, error) {
 	return maxPlaintext, nil
 }
 
+// OpenOption customizes a decrypting reader created by Open.
+type OpenOption func(*openOptions)
+
+type openOptions struct {
+	bufferPool *bufpool.Pool
+}
+
+// WithBufferPool draws the reader's scratch buffer from pool instead of
+// allocating one per reader. Pools whose size is not a whole number of AES
+// blocks are ignored. A nil pool keeps the default allocation.
+func WithBufferPool(pool *bufpool.Pool) OpenOption {
+	return func(o *openOptions) {
+		o.bufferPool = pool
+	}
+}
+
 // Open creates a decrypting reader for AES-encrypted data
 // decryptedFileSize is the actual file size (output will be limited to this)
 func (c *AesCipher) Open(
@@ -59,7 +76,13 @@ func (c *AesCipher) Open(
 	key []byte,
 	iv []byte,
 	getReader func(ctx context.Context, start, end int64) (io.ReadCloser, error),
+	opts ...OpenOption,
 ) (io.ReadCloser, error) {
+	var o openOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
 	// Validate key and IV
 	if len(key) == 0 {
 		return nil, fmt.Errorf("AES key is required")
@@ -80,7 +103,7 @@ func (c *AesCipher) Open(
 	if rh != nil {
 		requestEnd = rh.End
 	}
-	decryptReader, err := newAesDecryptReader(ctx, getReader, key, iv, decryptedFileSize, encryptedSize, requestEnd)
+	decryptReader, err := newAesDecryptReader(ctx, getReader, key, iv, decryptedFileSize, encryptedSize, requestEnd, o.bufferPool)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create AES decrypt reader: %w", err)
 	}
@@ -6,8 +6,14 @@ import (
 	"crypto/cipher"
 	"fmt"
 	"io"
+	"sync"
+
+	"github.com/javi11/altmount/internal/bufpool"
 )
 
+// defaultBufferSize is the scratch buffer used when no pool is configured.
+const defaultBufferSize = aes.BlockSize * 64
+
 // aesDecryptReader wraps an io.ReadCloser with AES-CBC decryption
 // Based on the implementation from rardecode example: github.com/javi11/rardecode/blob/main/examples/rarextract/main.go
 type aesDecryptReader struct {
@@ -18,26 +24,37 @@ type aesDecryptReader struct {
 	iv            []byte
 	origIV        []byte // Original IV for recalculation during seeks
 	decrypter     cipher.BlockMode
-	buffer        []byte // Buffer for partial block reads
-	bufferPos     int    // Current position in buffer
-	bufferLen     int    // Length of valid data in buffer
-	offset        int64  // Current read position
-	size          int64  // Total size of decrypted data (for output limiting)
-	encryptedSize int64  // Total size of encrypted data (for source reading)
-	requestEnd    int64  // Caller's desired end offset in decrypted space (-1 = unbounded)
+	buffer        []byte // Scratch buffer: ciphertext is read and decrypted in place
+	pool          *bufpool.Pool
+	pooled        *[]byte // buffer's pool handle; nil when buffer is not pooled
+	bufferPos     int     // Current position in buffer
+	bufferLen     int     // Length of valid data in buffer
+	offset        int64   // Current read position
+	size          int64   // Total size of decrypted data (for output limiting)
+	encryptedSize int64   // Total size of encrypted data (for source reading)
+	requestEnd    int64   // Caller's desired end offset in decrypted space (-1 = unbounded)
 	closed        bool
+
+	// mu hands the pooled buffer back exactly once. Close may run while a Read
+	// is blocked on the source (readFullContext force-closes on cancellation),
+	// so the buffer is only returned once no Read is using it.
+	mu      sync.Mutex
+	reading bool
 }
 
 // newAesDecryptReader creates a new AES-CBC decrypt reader
 // decryptedSize is the actual file size (for output limiting)
 // encryptedSize is the padded size in segments (for source reading)
 // requestEnd is the caller's desired end offset in decrypted space (-1 = unbounded)
+// pool, when non-nil, supplies the scratch buffer; it is borrowed on first read
+// and returned on Close.
 func newAesDecryptReader(
 	ctx context.Context,
 	getReader func(ctx context.Context, start, end int64) (io.ReadCloser, error),
 	key, iv []byte,
 	decryptedSize, encryptedSize int64,
 	requestEnd int64,
+	pool *bufpool.Pool,
 ) (*aesDecryptReader, error) {
 	if len(key) != 16 && len(key) != 24 && len(key) != 32 {
 		return nil, fmt.Errorf("invalid AES key size: %d (must be 16, 24, or 32 bytes)", len(key))
@@ -64,18 +81,64 @@ func newAesDecryptReader(
 		iv:            ivCopy,
 		origIV:        iv,
 		decrypter:     cipher.NewCBCDecrypter(block, ivCopy),
-		buffer:        make([]byte, aes.BlockSize*64), // Buffer multiple blocks for efficiency
+		pool:          usablePool(pool),
 		size:          decryptedSize,
 		encryptedSize: encryptedSize,
 		requestEnd:    requestEnd,
 	}, nil
 }
 
+// usablePool returns pool if its buffers can hold whole AES blocks, nil otherwise.
+func usablePool(pool *bufpool.Pool) *bufpool.Pool {
+	if pool.Size() < aes.BlockSize || pool.Size()%aes.BlockSize != 0 {
+		return nil
+	}
+	return pool
+}
+
+// acquireBuffer lazily sets up the scratch buffer, drawing from the pool when
+// one is configured.
+func (r *aesDecryptReader) acquireBuffer() {
+	if r.buffer != nil {
+		return
+	}
+	if r.pool != nil {
+		r.pooled = r.pool.Get()
+		r.buffer = *r.pooled
+		return
+	}
+	r.buffer = make([]byte, defaultBufferSize) // Buffer multiple blocks for efficiency
+}
+
+// releaseBufferLocked returns a pooled buffer. Caller must hold r.mu.
+func (r *aesDecryptReader) releaseBufferLocked() {
+	if r.pooled != nil {
+		r.pool.Put(r.pooled)
+		r.pooled = nil
+	}
+	r.buffer = nil
+	r.bufferPos = 0
+	r.bufferLen = 0
+}
+
 // Read implements io.Reader
 func (r *aesDecryptReader) Read(p []byte) (int, error) {
+	r.mu.Lock()
 	if r.closed {
+		r.mu.Unlock()
 		return 0, io.ErrClosedPipe
 	}
+	r.reading = true
+	r.acquireBuffer()
+	r.mu.Unlock()
+	defer func() {
+		r.mu.Lock()
+		r.reading = false
+		if r.closed {
+			r.releaseBufferLocked()
+		}
+		r.mu.Unlock()
+	}()
 
 	// Lazy initialization of source reader
 	if r.source == nil {
@@ -130,8 +193,9 @@ func (r *aesDecryptReader) Read(p []byte) (int, error) {
 			return 0, io.EOF
 		}
 
-		// Read encrypted data
-		encBuf := make([]byte, readSize)
+		// Read encrypted data straight into the scratch buffer; it is only
+		// refilled once fully drained, so it can be decrypted in place.
+		encBuf := r.buffer[:readSize]
 		n, err := io.ReadFull(r.source, encBuf)
 		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
 			return totalRead, err
@@ -159,8 +223,6 @@ func (r *aesDecryptReader) Read(p []byte) (int, error) {
 				decryptedLen = int(effectiveSize - r.offset)
 			}
 
-			// Copy to buffer
-			copy(r.buffer, encBuf[:decryptedLen])
 			r.bufferLen = decryptedLen
 			r.bufferPos = 0
 		}
@@ -290,13 +352,20 @@ func (r *aesDecryptReader) Seek(offset int64, whence int) (int64, error) {
 
 // Close implements io.Closer
 func (r *aesDecryptReader) Close() error {
+	r.mu.Lock()
 	if r.closed {
+		r.mu.Unlock()
 		return nil
 	}
-
 	r.closed = true
-	if r.source != nil {
-		return r.source.Close()
+	if !r.reading {
+		r.releaseBufferLocked()
+	}
+	source := r.source
+	r.mu.Unlock()
+
+	if source != nil {
+		return source.Close()
 	}
 
 	return nil
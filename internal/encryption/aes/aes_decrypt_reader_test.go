@@ -0,0 +1,135 @@
+package aes
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"errors"
+	"io"
+	"testing"
+	"testing/iotest"
+
+	"github.com/javi11/altmount/internal/bufpool"
+	"github.com/javi11/altmount/internal/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// encryptFixture returns plaintext of size n, its AES-CBC ciphertext (zero
+// padded to a block boundary), and the key/IV used.
+func encryptFixture(t testing.TB, n int) (plain, enc, key, iv []byte) {
+	t.Helper()
+	key = bytes.Repeat([]byte{0x42}, 32)
+	iv = bytes.Repeat([]byte{0x24}, aes.BlockSize)
+	plain = make([]byte, n)
+	for i := range plain {
+		plain[i] = byte(i * 7)
+	}
+	padded := make([]byte, EncryptedSize(int64(n)))
+	copy(padded, plain)
+
+	block, err := aes.NewCipher(key)
+	require.NoError(t, err)
+	enc = make([]byte, len(padded))
+	cipher.NewCBCEncrypter(block, iv).CryptBlocks(enc, padded)
+	return plain, enc, key, iv
+}
+
+// memReader serves byte ranges of data, mimicking the segment reader.
+func memReader(data []byte) func(ctx context.Context, start, end int64) (io.ReadCloser, error) {
+	return func(_ context.Context, start, end int64) (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(data[start : end+1])), nil
+	}
+}
+
+func TestAesDecryptReader_RoundTrip(t *testing.T) {
+	plain, enc, key, iv := encryptFixture(t, 300_000+5)
+
+	cases := []struct {
+		name string
+		opts []OpenOption
+	}{
+		{"unpooled", nil},
+		{"pooled", []OpenOption{WithBufferPool(bufpool.ForSize(64 * 1024))}},
+		{"small pool", []OpenOption{WithBufferPool(bufpool.ForSize(aes.BlockSize))}},
+		{"unaligned pool ignored", []OpenOption{WithBufferPool(bufpool.ForSize(1000))}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			r, err := NewAesCipher().Open(context.Background(), nil, int64(len(plain)), key, iv, memReader(enc), tc.opts...)
+			require.NoError(t, err)
+			got, err := io.ReadAll(r)
+			require.NoError(t, err)
+			require.NoError(t, r.Close())
+			assert.Equal(t, plain, got)
+
+			// Ranged open seeks mid-block before the first read.
+			rh := &utils.RangeHeader{Start: 70_001, End: 200_000}
+			r, err = NewAesCipher().Open(context.Background(), rh, int64(len(plain)), key, iv, memReader(enc), tc.opts...)
+			require.NoError(t, err)
+			got = make([]byte, rh.End-rh.Start+1)
+			_, err = io.ReadFull(r, got)
+			require.NoError(t, err)
+			require.NoError(t, r.Close())
+			assert.Equal(t, plain[rh.Start:rh.End+1], got)
+		})
+	}
+}
+
+func TestAesDecryptReader_ReturnsBufferOnError(t *testing.T) {
+	_, enc, key, iv := encryptFixture(t, 4096)
+	pool := bufpool.ForSize(1024)
+	boom := errors.New("source failed")
+
+	failing := func(_ context.Context, start, end int64) (io.ReadCloser, error) {
+		return io.NopCloser(io.MultiReader(bytes.NewReader(enc[start:start+1024]), iotest.ErrReader(boom))), nil
+	}
+
+	r, err := NewAesCipher().Open(context.Background(), nil, 4096, key, iv, failing, WithBufferPool(pool))
+	require.NoError(t, err)
+	_, err = io.ReadAll(r)
+	require.ErrorIs(t, err, boom)
+
+	dr := r.(*aesDecryptReader)
+	require.NotNil(t, dr.pooled, "buffer is held until Close")
+	require.NoError(t, r.Close())
+	assert.Nil(t, dr.pooled)
+	assert.Nil(t, dr.buffer)
+
+	// Reads after Close fail without re-acquiring a buffer.
+	_, err = r.Read(make([]byte, 1))
+	assert.ErrorIs(t, err, io.ErrClosedPipe)
+	assert.Nil(t, dr.buffer)
+}
+
+func benchmarkAesRead(b *testing.B, opts ...OpenOption) {
+	plain, enc, key, iv := encryptFixture(b, 256*1024)
+	out := make([]byte, 32*1024)
+	c := NewAesCipher()
+
+	b.ReportAllocs()
+	b.SetBytes(int64(len(plain)))
+	for b.Loop() {
+		r, err := c.Open(context.Background(), nil, int64(len(plain)), key, iv, memReader(enc), opts...)
+		if err != nil {
+			b.Fatal(err)
+		}
+		for {
+			if _, err := r.Read(out); err == io.EOF {
+				break
+			} else if err != nil {
+				b.Fatal(err)
+			}
+		}
+		r.Close()
+	}
+}
+
+func BenchmarkAesDecryptReader_Unpooled(b *testing.B) {
+	benchmarkAesRead(b)
+}
+
+func BenchmarkAesDecryptReader_Pooled(b *testing.B) {
+	benchmarkAesRead(b, WithBufferPool(bufpool.ForSize(64*1024)))
+}
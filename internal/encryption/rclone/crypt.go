@@ -3,6 +3,7 @@ package rclone
 import (
 	"context"
 	"errors"
+	"fmt"
 	"io"
 	"log/slog"
 	"sync"
@@ -32,6 +33,10 @@ var (
 	}
 )
 
+// ErrDecryptedSizeMismatch is returned by VerifyDecryptedSize when the
+// ciphertext does not end where the stored decrypted size says it should.
+var ErrDecryptedSizeMismatch = errors.New("stored decrypted size does not match the encrypted data")
+
 // RcloneCrypt handles rclone-style file encryption/decryption
 type RcloneCrypt struct {
 	// Cipher to use for encrypting/decrypting
@@ -141,6 +146,65 @@ func (o *RcloneCrypt) Open(
 	}, nil
 }
 
+// sizeCheckTail is how many plaintext bytes VerifyDecryptedSize reads from
+// the end of a file. Decryption works on whole blocks, so the final block is
+// authenticated in full regardless.
+const sizeCheckTail = 4 * 1024
+
+// VerifyDecryptedSize checks that fileSize, the decrypted size stored at
+// import, matches the ciphertext behind getReader. encryptedLength is the
+// ciphertext's known length, or -1 when unknown; a length that disagrees with
+// fileSize is a mismatch without any read. Otherwise the file's tail is
+// decrypted: each block is sealed with a nonce derived from its index, so the
+// final block only authenticates when read at the exact offset and length
+// fileSize implies. The first block is decrypted too so that wrong
+// credentials are reported as such rather than as a size mismatch.
+func (o *RcloneCrypt) VerifyDecryptedSize(
+	ctx context.Context,
+	fileSize int64,
+	encryptedLength int64,
+	password string,
+	salt string,
+	getReader func(ctx context.Context, start, end int64) (io.ReadCloser, error),
+) error {
+	if fileSize <= 0 {
+		return nil
+	}
+
+	if want := o.EncryptedSize(fileSize); encryptedLength >= 0 && encryptedLength != want {
+		return fmt.Errorf("%w: stored size implies %d encrypted bytes, found %d",
+			ErrDecryptedSizeMismatch, want, encryptedLength)
+	}
+
+	read := func(start, end int64) error {
+		rc, err := o.Open(ctx, &utils.RangeHeader{Start: start, End: end}, fileSize, password, salt, getReader)
+		if err != nil {
+			return err
+		}
+		defer rc.Close()
+
+		buf := make([]byte, end-start+1)
+		_, err = io.ReadFull(rc, buf)
+		return err
+	}
+
+	if fileSize > blockDataSize {
+		if err := read(0, 0); err != nil {
+			return err
+		}
+	}
+
+	if err := read(max(0, fileSize-sizeCheckTail), fileSize-1); err != nil {
+		if errors.Is(err, ErrorEncryptedBadBlock) || errors.Is(err, ErrorEncryptedFileBadHeader) ||
+			errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF) {
+			return errors.Join(ErrDecryptedSizeMismatch, err)
+		}
+		return err
+	}
+
+	return nil
+}
+
 func (o *RcloneCrypt) DecryptedSize(fileSize int64) (int64, error) {
 	return o.cipher.DecryptedSize(fileSize)
 }
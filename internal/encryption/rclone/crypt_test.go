@@ -0,0 +1,81 @@
+package rclone
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+
+	"github.com/javi11/altmount/internal/encryption"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// encryptForTest returns plaintext rclone-encrypted with password/salt.
+func encryptForTest(t *testing.T, password, salt string, plaintext []byte) []byte {
+	t.Helper()
+	c, err := NewCipher(NameEncryptionOff, "", "", false, nil)
+	require.NoError(t, err)
+	k, err := GenerateKey(password, salt)
+	require.NoError(t, err)
+	r, err := c.EncryptData(bytes.NewReader(plaintext), k)
+	require.NoError(t, err)
+	ciphertext, err := io.ReadAll(r)
+	require.NoError(t, err)
+	return ciphertext
+}
+
+// bytesReader serves inclusive [start, end] ranges of data, truncated at its end.
+func bytesReader(data []byte) func(ctx context.Context, start, end int64) (io.ReadCloser, error) {
+	return func(_ context.Context, start, end int64) (io.ReadCloser, error) {
+		start = min(start, int64(len(data)))
+		end = min(end+1, int64(len(data)))
+		return io.NopCloser(bytes.NewReader(data[start:end])), nil
+	}
+}
+
+func TestVerifyDecryptedSize(t *testing.T) {
+	const password, salt = "pass", "salt"
+	plaintext := bytes.Repeat([]byte("altmount"), (3*blockDataSize+1000)/8)
+	ciphertext := encryptForTest(t, password, salt, plaintext)
+	getReader := bytesReader(ciphertext)
+
+	crypt, err := NewRcloneCipher(&encryption.Config{})
+	require.NoError(t, err)
+	ctx := context.Background()
+	size := int64(len(plaintext))
+
+	assert.NoError(t, crypt.VerifyDecryptedSize(ctx, size, -1, password, salt, getReader))
+
+	for name, wrong := range map[string]int64{
+		"encrypted length":  int64(len(ciphertext)),
+		"one byte short":    size - 1,
+		"one byte over":     size + 1,
+		"whole block short": size - blockDataSize,
+	} {
+		err := crypt.VerifyDecryptedSize(ctx, wrong, -1, password, salt, getReader)
+		assert.ErrorIs(t, err, ErrDecryptedSizeMismatch, name)
+	}
+
+	// A size short by whole blocks still ends on a valid block; only the
+	// ciphertext length gives it away.
+	short := int64(3 * blockDataSize)
+	assert.NoError(t, crypt.VerifyDecryptedSize(ctx, short, -1, password, salt, getReader))
+	assert.ErrorIs(t, crypt.VerifyDecryptedSize(ctx, short, int64(len(ciphertext)), password, salt, getReader), ErrDecryptedSizeMismatch)
+
+	err = crypt.VerifyDecryptedSize(ctx, size, -1, "wrong", salt, getReader)
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, ErrDecryptedSizeMismatch, "bad credentials are not a size mismatch")
+}
+
+func TestVerifyDecryptedSize_SmallFile(t *testing.T) {
+	plaintext := []byte("a file smaller than one crypt block")
+	getReader := bytesReader(encryptForTest(t, "pass", "", plaintext))
+
+	crypt, err := NewRcloneCipher(&encryption.Config{})
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	assert.NoError(t, crypt.VerifyDecryptedSize(ctx, int64(len(plaintext)), -1, "pass", "", getReader))
+	assert.ErrorIs(t, crypt.VerifyDecryptedSize(ctx, int64(len(plaintext))+16, -1, "pass", "", getReader), ErrDecryptedSizeMismatch)
+}
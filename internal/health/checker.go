@@ -47,7 +47,10 @@ type CheckOptions struct {
 	ForceFullCheck bool
 }
 
-// HealthChecker manages file health checking logic
+// HealthChecker manages file health checking logic. Checks STAT the sampled
+// segments on the providers and never read through the streaming segment
+// cache, so a segment that is cached but no longer available upstream is
+// still reported missing.
 type HealthChecker struct {
 	healthRepo      *database.HealthRepository
 	metadataService *metadata.MetadataService
@@ -73,6 +76,14 @@ func NewHealthChecker(
 	}
 }
 
+// providersHeldOut reports whether the pool's circuit breaker holds a provider
+// out. Articles only that provider has would be reported missing meanwhile, so
+// checks must wait for it to come back.
+func (hc *HealthChecker) providersHeldOut() bool {
+	s, ok := hc.poolManager.(pool.CircuitBreakerStatus)
+	return ok && s.ProvidersHeldOut()
+}
+
 // healthCheckInput holds the fields extracted from FileMetadata that the
 // health check path actually needs. Passing this lean struct — instead of the
 // full *metapb.FileMetadata — lets the proto wrapper be GC'd while the NNTP
@@ -177,6 +188,14 @@ func (hc *HealthChecker) prepareCheck(ctx context.Context, filePath string, opts
 
 	prep.sourceNzbPath = input.sourceNzbPath
 
+	// Empty files (zero-byte archive entries) have no segments to check.
+	if input.fileSize == 0 {
+		event := baseResultEvent(filePath, input.sourceNzbPath)
+		event.Type = EventTypeFileHealthy
+		prep.earlyEvent = &event
+		return prep
+	}
+
 	if len(input.segments) == 0 {
 		event := baseResultEvent(filePath, input.sourceNzbPath)
 		event.Type = EventTypeCheckFailed
@@ -407,10 +426,11 @@ func (l *metadataSegmentLoader) GetSegment(index int) (usenet.Segment, []string,
 	}
 
 	s := l.segments[index]
+	start, end := s.BodyRange()
 	return usenet.Segment{
 		Id:    s.Id,
-		Start: s.StartOffset,
-		End:   s.EndOffset,
+		Start: start,
+		End:   end,
 		Size:  s.SegmentSize,
 	}, []string{}, true
 }
@@ -224,3 +224,18 @@ func TestRunHealthCheckCycle_BatchExceedsMaxJobs(t *testing.T) {
 	).Scan(&stuck))
 	assert.Equal(t, 0, stuck, "no files should remain due after one cycle")
 }
+
+func TestCheckFile_EmptyFileIsHealthy(t *testing.T) {
+	env := newBatchTestEnv(t, t.TempDir(), fakepool.New())
+
+	// Zero-byte archive entries are imported without segments.
+	meta := env.metadataService.CreateFileMetadata(
+		0, "test.nzb", metapb.FileStatus_FILE_STATUS_HEALTHY,
+		nil, metapb.Encryption_NONE, "", "", nil, nil, 0, nil, "",
+	)
+	require.NoError(t, env.metadataService.WriteFileMetadata("empty.txt", meta))
+
+	event := env.healthChecker.CheckFile(context.Background(), "empty.txt")
+	assert.Equal(t, EventTypeFileHealthy, event.Type)
+	assert.NoError(t, event.Error)
+}
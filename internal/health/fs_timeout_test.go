@@ -0,0 +1,108 @@
+package health
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/javi11/altmount/internal/config"
+	"github.com/javi11/altmount/internal/database"
+	"github.com/javi11/altmount/internal/metadata"
+	metapb "github.com/javi11/altmount/internal/metadata/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errHungReleased = errors.New("hung filesystem released")
+
+// hungFileOps stands in for a hung mount: mutating calls block until the test
+// ends, then fail without touching the disk.
+type hungFileOps struct {
+	release chan struct{}
+}
+
+func (h *hungFileOps) Stat(name string) (fs.FileInfo, error) { return os.Stat(name) }
+func (h *hungFileOps) MkdirAll(string, fs.FileMode) error    { <-h.release; return errHungReleased }
+func (h *hungFileOps) Rename(string, string) error           { <-h.release; return errHungReleased }
+func (h *hungFileOps) Remove(string) error                   { <-h.release; return errHungReleased }
+
+func (h *hungFileOps) WriteFile(string, []byte, fs.FileMode) error {
+	<-h.release
+	return errHungReleased
+}
+
+// newHungFSWorker builds a worker whose metadata filesystem hangs and whose
+// filesystem deadline is one second. virtualPath gets a metadata file first.
+func newHungFSWorker(t *testing.T, mountPath, virtualPath string) (*HealthWorker, *database.HealthRepository, *sql.DB) {
+	t.Helper()
+	tempDir := t.TempDir()
+	db := newResilienceDB(t)
+	healthRepo := database.NewHealthRepository(db, database.DialectSQLite)
+	metadataService := metadata.NewMetadataService(tempDir)
+
+	meta := metadataService.CreateFileMetadata(
+		1024, "test.nzb", metapb.FileStatus_FILE_STATUS_HEALTHY,
+		nil, metapb.Encryption_NONE, "", "", nil, nil, 0, nil, "",
+	)
+	require.NoError(t, metadataService.WriteFileMetadata(virtualPath, meta))
+
+	hung := &hungFileOps{release: make(chan struct{})}
+	t.Cleanup(func() { close(hung.release) })
+	metadataService.SetFileOps(hung)
+
+	healthEnabled := true
+	cfg := config.DefaultConfig()
+	cfg.Health.Enabled = &healthEnabled
+	cfg.Health.FilesystemTimeoutSeconds = 1
+	cfg.Metadata.RootPath = tempDir
+	cfg.MountPath = mountPath
+	configManager := config.NewManager(cfg, "")
+
+	hw := NewHealthWorker(nil, healthRepo, metadataService,
+		&mockARRsService{}, &mockImportService{}, configManager.GetConfig, nil)
+	return hw, healthRepo, db
+}
+
+func TestCleanupZombieRecord_HungFilesystemFailsFast(t *testing.T) {
+	const mountPath = "/mnt/test"
+	virtualPath := filepath.Join("movies", "zombie.mkv")
+	filePath := "mnt/test/" + virtualPath
+	hw, healthRepo, db := newHungFSWorker(t, mountPath, virtualPath)
+
+	_, err := db.Exec(`
+		INSERT INTO file_health (file_path, status, retry_count, max_retries, repair_retry_count, max_repair_retries)
+		VALUES (?, 'pending', 0, 3, 0, 3)
+	`, filePath)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	start := time.Now()
+	hw.cleanupZombieRecord(ctx, &database.FileHealth{FilePath: "/" + filePath})
+	assert.Less(t, time.Since(start), 5*time.Second, "cleanup gave up at the filesystem deadline")
+
+	// The database step is independent of the hung filesystem.
+	count, err := healthRepo.CountHealthItems(ctx, nil, nil, "")
+	require.NoError(t, err)
+	assert.Equal(t, 0, count)
+}
+
+func TestMoveMetadataToSafetyFolder_HungFilesystemFailsFast(t *testing.T) {
+	const mountPath = "/mnt/test"
+	virtualPath := filepath.Join("movies", "broken.mkv")
+	hw, _, _ := newHungFSWorker(t, mountPath, virtualPath)
+
+	libraryPath := "/library/movies/broken.mkv"
+	item := &database.FileHealth{
+		FilePath:    mountPath + "/" + virtualPath,
+		LibraryPath: &libraryPath,
+	}
+
+	start := time.Now()
+	hw.moveMetadataToSafetyFolder(context.Background(), item)
+	assert.Less(t, time.Since(start), 5*time.Second, "move gave up at the filesystem deadline")
+}
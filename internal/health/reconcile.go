@@ -0,0 +1,144 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/javi11/altmount/internal/database"
+)
+
+// ErrLibrarySyncRunning is returned by ReconcileHealthRecords while a library
+// sync is in progress, since both rewrite the same health rows.
+var ErrLibrarySyncRunning = errors.New("library sync is running")
+
+// HealthReconcileResult summarizes a ReconcileHealthRecords pass.
+type HealthReconcileResult struct {
+	// MissingRecords are metadata files that had no file_health row.
+	MissingRecords []string `json:"missing_records"`
+	// OrphanRecords are file_health rows whose metadata file no longer exists.
+	OrphanRecords []string `json:"orphan_records"`
+	// CorruptedMetadata are metadata files that could not be read; they are
+	// registered as corrupted so the health worker can trigger a repair.
+	CorruptedMetadata []string `json:"corrupted_metadata"`
+	// Added and Removed count the rows actually written; both stay 0 on a dry run.
+	Added   int  `json:"added"`
+	Removed int  `json:"removed"`
+	DryRun  bool `json:"dry_run"`
+}
+
+// ReconcileHealthRecords cross-references the metadata tree against the
+// file_health table without touching the library directory. Metadata files
+// without a health record get a pending record so they are checked on the next
+// cycle (files in health-excluded categories are left alone), and health rows
+// whose metadata file is gone are removed so they stop showing up as zombies.
+// With dryRun set the inconsistencies are only reported.
+//
+// Orphan removal is skipped when the metadata tree is empty but health rows
+// exist: that almost always means a misconfigured or unmounted metadata root,
+// not a library that was deleted wholesale.
+func (lsw *LibrarySyncWorker) ReconcileHealthRecords(ctx context.Context, dryRun bool) (*HealthReconcileResult, error) {
+	lsw.progressMu.RLock()
+	syncing := lsw.progress != nil
+	lsw.progressMu.RUnlock()
+	if syncing {
+		return nil, ErrLibrarySyncRunning
+	}
+
+	cfg := lsw.configGetter()
+
+	metadataFiles, err := lsw.getAllMetadataFiles(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get metadata files: %w", err)
+	}
+
+	dbRecords, err := lsw.healthRepo.GetAllHealthCheckRecords(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get health records: %w", err)
+	}
+
+	maps := lsw.buildSyncMaps(metadataFiles, dbRecords)
+	excludedPrefixes := buildExcludedCategoryPrefixes(cfg)
+	result := &HealthReconcileResult{DryRun: dryRun}
+
+	var toAdd []database.HealthCheckUpsert
+	for path := range maps.metaFileSet {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if _, exists := maps.dbPathSet[path]; exists || pathHasExcludedPrefix(path, excludedPrefixes) {
+			continue
+		}
+
+		fileMeta, err := lsw.metadataService.ReadFileMetadata(path)
+		if err != nil {
+			result.CorruptedMetadata = append(result.CorruptedMetadata, path)
+			if !dryRun {
+				if regErr := lsw.healthRepo.RegisterCorruptedFile(ctx, path, nil, err.Error()); regErr != nil {
+					slog.ErrorContext(ctx, "Failed to register corrupted file", "path", path, "error", regErr)
+				}
+			}
+			continue
+		}
+		if fileMeta == nil {
+			continue
+		}
+
+		result.MissingRecords = append(result.MissingRecords, path)
+
+		releaseDate := fileMeta.ReleaseDate
+		if releaseDate == 0 {
+			releaseDate = fileMeta.CreatedAt
+		}
+		releaseDateAsTime := time.Unix(releaseDate, 0)
+		record := database.HealthCheckUpsert{
+			FilePath:         path,
+			Priority:         database.HealthPriorityNormal,
+			MaxRetries:       cfg.GetMaxRetries(),
+			MaxRepairRetries: cfg.GetMaxRepairRetries(),
+			ReleaseDate:      &releaseDateAsTime,
+		}
+		if fileMeta.SourceNzbPath != "" {
+			record.SourceNzbPath = &fileMeta.SourceNzbPath
+		}
+		toAdd = append(toAdd, record)
+	}
+
+	result.OrphanRecords = lsw.findFilesToDelete(ctx, dbRecords, maps.metaFileSet, nil)
+
+	slices.Sort(result.MissingRecords)
+	slices.Sort(result.OrphanRecords)
+	slices.Sort(result.CorruptedMetadata)
+
+	if dryRun {
+		return result, nil
+	}
+
+	if err := lsw.healthRepo.BatchAddFileToHealthCheck(ctx, toAdd); err != nil {
+		return nil, fmt.Errorf("failed to add missing health records: %w", err)
+	}
+	result.Added = len(toAdd)
+
+	if len(result.OrphanRecords) > 0 {
+		if len(metadataFiles) == 0 {
+			slog.WarnContext(ctx, "Metadata tree is empty while health records exist, skipping orphan cleanup",
+				"health_records", len(dbRecords))
+		} else {
+			removed, err := lsw.healthRepo.DeleteHealthRecordsBulk(ctx, result.OrphanRecords)
+			if err != nil {
+				return nil, fmt.Errorf("failed to delete orphan health records: %w", err)
+			}
+			result.Removed = int(removed)
+		}
+	}
+
+	slog.InfoContext(ctx, "Reconciled health records with metadata",
+		"added", result.Added,
+		"removed", result.Removed,
+		"corrupted_metadata", len(result.CorruptedMetadata))
+
+	return result, nil
+}
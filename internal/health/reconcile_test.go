@@ -0,0 +1,150 @@
+package health
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/javi11/altmount/internal/config"
+	"github.com/javi11/altmount/internal/database"
+	"github.com/javi11/altmount/internal/metadata"
+	metapb "github.com/javi11/altmount/internal/metadata/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newReconcileTestWorker(t *testing.T, configure ...func(*config.Config)) (*LibrarySyncWorker, *database.HealthRepository) {
+	t.Helper()
+
+	db, err := database.NewDB(database.Config{Type: "sqlite", DatabasePath: filepath.Join(t.TempDir(), "test.db")})
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	healthRepo := database.NewHealthRepository(db.Connection(), db.Dialect())
+
+	metaRoot := t.TempDir()
+	cfg := config.DefaultConfig()
+	cfg.Metadata.RootPath = metaRoot
+	for _, fn := range configure {
+		fn(cfg)
+	}
+
+	worker := NewLibrarySyncWorker(metadata.NewMetadataService(metaRoot), healthRepo,
+		func() *config.Config { return cfg }, nil, &MockRcloneClient{})
+	return worker, healthRepo
+}
+
+func writeReconcileMeta(t *testing.T, lsw *LibrarySyncWorker, virtualPath string) {
+	t.Helper()
+	meta := lsw.metadataService.CreateFileMetadata(
+		100, "test.nzb", metapb.FileStatus_FILE_STATUS_HEALTHY, nil,
+		metapb.Encryption_NONE, "", "", nil, nil, 0, nil, "",
+	)
+	require.NoError(t, lsw.metadataService.WriteFileMetadata(virtualPath, meta))
+}
+
+func TestReconcileHealthRecords(t *testing.T) {
+	ctx := context.Background()
+	lsw, repo := newReconcileTestWorker(t)
+
+	// Consistent: metadata and health record.
+	writeReconcileMeta(t, lsw, "complete/tv/tracked.mkv")
+	require.NoError(t, repo.AddFileToHealthCheck(ctx, "complete/tv/tracked.mkv", nil, 3, 3, nil, database.HealthPriorityNormal))
+	require.NoError(t, repo.UpdateFileHealth(ctx, "complete/tv/tracked.mkv", database.HealthStatusHealthy, nil, nil, nil, false))
+
+	// Metadata without a health record: never checked.
+	writeReconcileMeta(t, lsw, "complete/tv/unchecked.mkv")
+
+	// Health record without metadata: zombie.
+	require.NoError(t, repo.AddFileToHealthCheck(ctx, "complete/tv/zombie.mkv", nil, 3, 3, nil, database.HealthPriorityNormal))
+
+	// Unreadable metadata.
+	brokenPath := lsw.metadataService.GetMetadataFilePath("complete/tv/broken.mkv")
+	require.NoError(t, os.WriteFile(brokenPath, []byte("not a protobuf"), 0644))
+
+	t.Run("dry run only reports", func(t *testing.T) {
+		result, err := lsw.ReconcileHealthRecords(ctx, true)
+		require.NoError(t, err)
+		assert.True(t, result.DryRun)
+		assert.Equal(t, []string{"complete/tv/unchecked.mkv"}, result.MissingRecords)
+		assert.Equal(t, []string{"complete/tv/zombie.mkv"}, result.OrphanRecords)
+		assert.Equal(t, []string{"complete/tv/broken.mkv"}, result.CorruptedMetadata)
+		assert.Zero(t, result.Added)
+		assert.Zero(t, result.Removed)
+
+		fh, err := repo.GetFileHealth(ctx, "complete/tv/unchecked.mkv")
+		require.NoError(t, err)
+		assert.Nil(t, fh)
+		fh, err = repo.GetFileHealth(ctx, "complete/tv/zombie.mkv")
+		require.NoError(t, err)
+		assert.NotNil(t, fh)
+	})
+
+	t.Run("repairs inconsistencies", func(t *testing.T) {
+		result, err := lsw.ReconcileHealthRecords(ctx, false)
+		require.NoError(t, err)
+		assert.Equal(t, 1, result.Added)
+		assert.Equal(t, 1, result.Removed)
+
+		fh, err := repo.GetFileHealth(ctx, "complete/tv/unchecked.mkv")
+		require.NoError(t, err)
+		require.NotNil(t, fh)
+		assert.Equal(t, database.HealthStatusPending, fh.Status)
+
+		fh, err = repo.GetFileHealth(ctx, "complete/tv/zombie.mkv")
+		require.NoError(t, err)
+		assert.Nil(t, fh)
+
+		fh, err = repo.GetFileHealth(ctx, "complete/tv/broken.mkv")
+		require.NoError(t, err)
+		require.NotNil(t, fh, "unreadable metadata is registered for repair")
+		assert.Equal(t, database.HealthStatusPending, fh.Status)
+
+		fh, err = repo.GetFileHealth(ctx, "complete/tv/tracked.mkv")
+		require.NoError(t, err)
+		require.NotNil(t, fh)
+		assert.Equal(t, database.HealthStatusHealthy, fh.Status, "consistent records are untouched")
+	})
+
+	t.Run("second pass is a no-op", func(t *testing.T) {
+		result, err := lsw.ReconcileHealthRecords(ctx, false)
+		require.NoError(t, err)
+		assert.Empty(t, result.MissingRecords)
+		assert.Empty(t, result.OrphanRecords)
+		assert.Empty(t, result.CorruptedMetadata)
+	})
+}
+
+func TestReconcileHealthRecords_SkipsExcludedCategories(t *testing.T) {
+	ctx := context.Background()
+	lsw, repo := newReconcileTestWorker(t, func(cfg *config.Config) {
+		cfg.SABnzbd.CompleteDir = "complete"
+		cfg.Health.ExcludedCategories = []string{"music"}
+	})
+
+	writeReconcileMeta(t, lsw, "complete/music/song.flac")
+
+	result, err := lsw.ReconcileHealthRecords(ctx, false)
+	require.NoError(t, err)
+	assert.Empty(t, result.MissingRecords)
+
+	fh, err := repo.GetFileHealth(ctx, "complete/music/song.flac")
+	require.NoError(t, err)
+	assert.Nil(t, fh)
+}
+
+func TestReconcileHealthRecords_EmptyMetadataKeepsRecords(t *testing.T) {
+	ctx := context.Background()
+	lsw, repo := newReconcileTestWorker(t)
+
+	require.NoError(t, repo.AddFileToHealthCheck(ctx, "complete/tv/a.mkv", nil, 3, 3, nil, database.HealthPriorityNormal))
+
+	result, err := lsw.ReconcileHealthRecords(ctx, false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"complete/tv/a.mkv"}, result.OrphanRecords)
+	assert.Zero(t, result.Removed, "an empty metadata tree must not wipe the health table")
+
+	fh, err := repo.GetFileHealth(ctx, "complete/tv/a.mkv")
+	require.NoError(t, err)
+	assert.NotNil(t, fh)
+}
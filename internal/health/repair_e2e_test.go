@@ -128,7 +128,8 @@ func newRepairTestEnv(t *testing.T, tempDir string, arrsErr error, configure ...
 			streaming_failure_count INTEGER DEFAULT 0,
 			is_masked BOOLEAN DEFAULT FALSE,
 			indexer TEXT DEFAULT NULL,
-			download_id TEXT DEFAULT NULL
+			download_id TEXT DEFAULT NULL,
+			last_verified_at DATETIME DEFAULT NULL
 		);
 
 		CREATE TABLE IF NOT EXISTS system_state (
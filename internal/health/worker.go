@@ -6,10 +6,12 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"math/rand"
 	"os"
 	"path/filepath"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/javi11/altmount/internal/arrs"
@@ -32,6 +34,10 @@ type ARRsRepairService interface {
 	DiscoverFileMetadata(ctx context.Context, filePath, relativePath, nzbName, libraryPath string) (*model.WebhookMetadata, error)
 }
 
+// ErrProviderHeldOut is returned by on-demand checks while the pool's circuit
+// breaker holds a provider out; the check is left pending until it is back.
+var ErrProviderHeldOut = errors.New("a provider is held out of the pool by the circuit breaker; check again after its cooldown")
+
 // WorkerStatus represents the current status of the health worker
 type WorkerStatus string
 
@@ -40,6 +46,7 @@ const (
 	WorkerStatusStarting WorkerStatus = "starting"
 	WorkerStatusRunning  WorkerStatus = "running"
 	WorkerStatusStopping WorkerStatus = "stopping"
+	WorkerStatusPaused   WorkerStatus = "paused"
 )
 
 // WorkerStats represents statistics about the health worker
@@ -55,6 +62,11 @@ type WorkerStats struct {
 	CurrentRunFilesChecked int          `json:"current_run_files_checked"`
 	LastError              *string      `json:"last_error,omitempty"`
 	ErrorCount             int64        `json:"error_count"`
+
+	// MaxConcurrentJobs is the concurrency the latest cycle ran with, which is
+	// lower than the configured value inside health peak-hours windows.
+	MaxConcurrentJobs int  `json:"max_concurrent_jobs"`
+	PeakHoursActive   bool `json:"peak_hours_active"`
 }
 
 // HealthWorker manages continuous health monitoring and manual check requests
@@ -70,6 +82,7 @@ type HealthWorker struct {
 	// Worker state
 	status       WorkerStatus
 	running      bool
+	paused       bool // Running, but skipping check cycles (Pause/Resume)
 	cycleRunning bool // Flag to prevent overlapping cycles
 	stopChan     chan struct{}
 	wg           sync.WaitGroup
@@ -85,6 +98,24 @@ type HealthWorker struct {
 
 	// Singleflight for metadata discovery
 	discoverySF singleflight.Group
+
+	// streamCanceller, when set, terminates active streams of files moved to
+	// corrupted_metadata. Guarded by mu.
+	streamCanceller StreamCanceller
+
+	// now is the clock used for peak-hours scheduling; replaced in tests.
+	now func() time.Time
+
+	// interval is the jittered delay the run loop last armed its ticker with,
+	// reported as NextRunTime; 0 until the loop starts.
+	interval atomic.Int64
+}
+
+// StreamCanceller terminates the active streams reading a virtual path.
+// Implemented by api.StreamTracker; declared here to avoid a health -> api
+// import dependency.
+type StreamCanceller interface {
+	CancelStreamsForPath(virtualPath string) int
 }
 
 // NewHealthWorker creates a new health worker
@@ -111,6 +142,7 @@ func NewHealthWorker(
 		stats: WorkerStats{
 			Status: WorkerStatusStopped,
 		},
+		now: time.Now,
 	}
 }
 
@@ -178,6 +210,7 @@ func (hw *HealthWorker) Stop(ctx context.Context) error {
 	}
 
 	hw.status = WorkerStatusStopping
+	hw.paused = false
 	hw.updateStats(func(s *WorkerStats) {
 		s.Status = WorkerStatusStopping
 	})
@@ -200,6 +233,60 @@ func (hw *HealthWorker) Stop(ctx context.Context) error {
 	return nil
 }
 
+// Pause stops the worker from starting new check cycles while keeping it
+// running and its statistics intact. A cycle already in progress finishes.
+func (hw *HealthWorker) Pause(ctx context.Context) error {
+	hw.mu.Lock()
+	defer hw.mu.Unlock()
+
+	if !hw.running {
+		return fmt.Errorf("health worker not running")
+	}
+	if hw.paused {
+		return nil
+	}
+
+	hw.paused = true
+	hw.status = WorkerStatusPaused
+	hw.updateStats(func(s *WorkerStats) {
+		s.Status = WorkerStatusPaused
+	})
+
+	slog.InfoContext(ctx, "Health worker paused")
+	hw.broadcastHealthChanged()
+	return nil
+}
+
+// Resume lets a paused worker start check cycles again from its next tick.
+func (hw *HealthWorker) Resume(ctx context.Context) error {
+	hw.mu.Lock()
+	defer hw.mu.Unlock()
+
+	if !hw.running {
+		return fmt.Errorf("health worker not running")
+	}
+	if !hw.paused {
+		return nil
+	}
+
+	hw.paused = false
+	hw.status = WorkerStatusRunning
+	hw.updateStats(func(s *WorkerStats) {
+		s.Status = WorkerStatusRunning
+	})
+
+	slog.InfoContext(ctx, "Health worker resumed")
+	hw.broadcastHealthChanged()
+	return nil
+}
+
+// IsPaused returns whether the health worker is paused
+func (hw *HealthWorker) IsPaused() bool {
+	hw.mu.RLock()
+	defer hw.mu.RUnlock()
+	return hw.paused
+}
+
 // IsRunning returns whether the health worker is currently running
 func (hw *HealthWorker) IsRunning() bool {
 	hw.mu.RLock()
@@ -254,7 +341,7 @@ func (hw *HealthWorker) IsCheckActive(filePath string) bool {
 
 // run is the main worker loop
 func (hw *HealthWorker) run(ctx context.Context) {
-	ticker := time.NewTicker(hw.getCheckInterval())
+	ticker := time.NewTicker(hw.nextCheckInterval())
 	defer ticker.Stop()
 
 	for {
@@ -266,28 +353,46 @@ func (hw *HealthWorker) run(ctx context.Context) {
 			slog.InfoContext(ctx, "Health worker stopped by stop signal")
 			return
 		case <-ticker.C:
-			// Check if a cycle is already running
-			hw.mu.RLock()
-			isCycleRunning := hw.cycleRunning
-			hw.mu.RUnlock()
-
-			if isCycleRunning {
-				slog.DebugContext(ctx, "Skipping health check cycle - previous cycle still running")
-				continue
-			}
-
-			if err := hw.safeRunHealthCheckCycle(ctx); err != nil {
-				slog.ErrorContext(ctx, "Health check cycle failed", "error", err)
-				hw.updateStats(func(s *WorkerStats) {
-					s.ErrorCount++
-					errMsg := err.Error()
-					s.LastError = &errMsg
-				})
-			}
+			ticker.Reset(hw.nextCheckInterval())
+			hw.tick(ctx)
 		}
 	}
 }
 
+// tick runs a health check cycle unless the worker is paused, the circuit
+// breaker holds a provider out of the pool or the previous cycle is still
+// running.
+func (hw *HealthWorker) tick(ctx context.Context) {
+	hw.mu.RLock()
+	isPaused := hw.paused
+	isCycleRunning := hw.cycleRunning
+	hw.mu.RUnlock()
+
+	if isPaused {
+		slog.DebugContext(ctx, "Skipping health check cycle - worker paused")
+		return
+	}
+
+	if hw.providersHeldOut() {
+		slog.DebugContext(ctx, "Skipping health check cycle - circuit breaker holds a provider out of the pool")
+		return
+	}
+
+	if isCycleRunning {
+		slog.DebugContext(ctx, "Skipping health check cycle - previous cycle still running")
+		return
+	}
+
+	if err := hw.safeRunHealthCheckCycle(ctx); err != nil {
+		slog.ErrorContext(ctx, "Health check cycle failed", "error", err)
+		hw.updateStats(func(s *WorkerStats) {
+			s.ErrorCount++
+			errMsg := err.Error()
+			s.LastError = &errMsg
+		})
+	}
+}
+
 // safeRunHealthCheckCycle runs a health check cycle with panic recovery
 func (hw *HealthWorker) safeRunHealthCheckCycle(ctx context.Context) (err error) {
 	defer func() {
@@ -412,9 +517,13 @@ func (hw *HealthWorker) prepareUpdateForResult(ctx context.Context, fh *database
 		update.Type = database.UpdateTypeHealthy
 		update.Status = database.HealthStatusHealthy
 		update.ScheduledCheckAt = nextCheck
+		update.Unmask = fh.IsMasked && hw.configGetter().Streaming.FailureMasking.AutoUnmaskOnHealthy
 
 		sideEffect = func() error {
 			slog.InfoContext(ctx, "File is healthy", "file_path", fh.FilePath)
+			if update.Unmask {
+				slog.InfoContext(ctx, "Unmasking file after healthy check", "file_path", fh.FilePath)
+			}
 
 			return hw.metadataService.UpdateFileStatus(fh.FilePath, metapb.FileStatus_FILE_STATUS_HEALTHY)
 		}
@@ -719,6 +828,10 @@ func (hw *HealthWorker) performDirectCheck(ctx context.Context, filePath string)
 	default:
 	}
 
+	if hw.providersHeldOut() {
+		return ErrProviderHeldOut
+	}
+
 	// Get current file state first to determine check options
 	fh, err := hw.healthRepo.GetFileHealth(ctx, filePath)
 	if err != nil {
@@ -791,8 +904,15 @@ func (hw *HealthWorker) runHealthCheckCycle(ctx context.Context) error {
 		s.CurrentRunFilesChecked = 0
 	})
 
-	maxJobs := hw.getMaxConcurrentJobs()
+	// Concurrency is re-evaluated every cycle so peak-hours windows take
+	// effect without restarting the worker.
 	cfg := hw.configGetter()
+	maxJobs := hw.getMaxConcurrentJobs()
+	peakActive := maxJobs < cfg.GetMaxConcurrentJobs()
+	hw.updateStats(func(s *WorkerStats) {
+		s.MaxConcurrentJobs = maxJobs
+		s.PeakHoursActive = peakActive
+	})
 	strategy := string(cfg.Import.ImportStrategy)
 	libraryDir := ""
 	if cfg.Health.LibraryDir != nil {
@@ -829,7 +949,7 @@ func (hw *HealthWorker) runHealthCheckCycle(ctx context.Context) error {
 			s.CurrentRunFilesChecked = 0
 			s.TotalRunsCompleted++
 			s.LastRunTime = &now
-			nextRun := now.Add(hw.getCheckInterval())
+			nextRun := now.Add(hw.currentCheckInterval())
 			s.NextRunTime = &nextRun
 		})
 		return nil
@@ -839,7 +959,8 @@ func (hw *HealthWorker) runHealthCheckCycle(ctx context.Context) error {
 		"health_check_files", len(unhealthyFiles),
 		"repair_notification_files", len(repairFiles),
 		"total", totalFiles,
-		"max_concurrent_jobs", maxJobs)
+		"max_concurrent_jobs", maxJobs,
+		"peak_hours", peakActive)
 
 	// Transition the whole batch to 'checking' in one write instead of one UPDATE per
 	// file: under SQLite's single writer N per-file transitions would serialize against
@@ -997,7 +1118,7 @@ func (hw *HealthWorker) runHealthCheckCycle(ctx context.Context) error {
 		s.CurrentRunFilesChecked = 0
 		s.TotalRunsCompleted++
 		s.LastRunTime = &now
-		nextRun := now.Add(hw.getCheckInterval())
+		nextRun := now.Add(hw.currentCheckInterval())
 		s.NextRunTime = &nextRun
 	})
 
@@ -1010,6 +1131,12 @@ func (hw *HealthWorker) runHealthCheckCycle(ctx context.Context) error {
 	return nil
 }
 
+// providersHeldOut reports whether checks must wait for a provider the
+// circuit breaker holds out of the pool.
+func (hw *HealthWorker) providersHeldOut() bool {
+	return hw.healthChecker != nil && hw.healthChecker.providersHeldOut()
+}
+
 // updateStats safely updates worker statistics
 func (hw *HealthWorker) updateStats(updateFunc func(*WorkerStats)) {
 	hw.statsMu.Lock()
@@ -1022,8 +1149,33 @@ func (hw *HealthWorker) getCheckInterval() time.Duration {
 	return hw.configGetter().GetCheckInterval()
 }
 
+// nextCheckInterval returns the delay until the next cycle: the configured
+// interval stretched or shortened at random by up to Health.IntervalJitter,
+// and never below one second.
+func (hw *HealthWorker) nextCheckInterval() time.Duration {
+	interval := hw.getCheckInterval()
+	if jitter := hw.configGetter().GetIntervalJitter(); jitter > 0 {
+		spread := float64(interval) * jitter
+		interval += time.Duration((rand.Float64()*2 - 1) * spread)
+		interval = max(interval, time.Second)
+	}
+	hw.interval.Store(int64(interval))
+	return interval
+}
+
+// currentCheckInterval returns the interval the run loop is waiting out,
+// or the configured one before the loop has started.
+func (hw *HealthWorker) currentCheckInterval() time.Duration {
+	if interval := time.Duration(hw.interval.Load()); interval > 0 {
+		return interval
+	}
+	return hw.getCheckInterval()
+}
+
+// getMaxConcurrentJobs returns the concurrency to use right now, honoring
+// Health.PeakHours.
 func (hw *HealthWorker) getMaxConcurrentJobs() int {
-	return hw.configGetter().GetMaxConcurrentJobs()
+	return hw.configGetter().GetEffectiveMaxConcurrentJobs(hw.now())
 }
 
 // repairOutcome describes the result of a repair trigger attempt.
@@ -1094,12 +1246,18 @@ func (hw *HealthWorker) resolvePathForRescan(item *database.FileHealth) string {
 
 // cleanupZombieRecord deletes the health record and associated metadata for a file that is
 // no longer tracked by ARR (zombie or orphan). Errors are logged but not returned because
-// cleanup is best-effort.
+// cleanup is best-effort. Each filesystem step is bounded by Health.FilesystemTimeoutSeconds
+// so a hung mount fails this record instead of stalling the cycle.
 func (hw *HealthWorker) cleanupZombieRecord(ctx context.Context, item *database.FileHealth) {
+	cfg := hw.configGetter()
+
 	// Delete library symlink/STRM if it exists (only for ARR-relinked records; an
 	// import-time placeholder points at the virtual mount, not a real library file).
 	if p, ok := item.EffectiveLibraryPath(); ok {
-		if err := os.Remove(p); err != nil && !os.IsNotExist(err) {
+		fsCtx, cancel := context.WithTimeout(ctx, cfg.GetHealthFilesystemTimeout())
+		err := utils.RunWithContext(fsCtx, func() error { return os.Remove(p) })
+		cancel()
+		if err != nil && !os.IsNotExist(err) {
 			slog.ErrorContext(ctx, "Failed to delete library file during zombie cleanup",
 				"path", p, "error", err)
 		}
@@ -1109,12 +1267,13 @@ func (hw *HealthWorker) cleanupZombieRecord(ctx context.Context, item *database.
 		slog.ErrorContext(ctx, "Failed to delete health record during cleanup", "file_path", item.FilePath, "error", delErr)
 	}
 
-	cfg := hw.configGetter()
 	relativePath := strings.TrimPrefix(item.FilePath, cfg.MountPath)
 	relativePath = strings.TrimPrefix(relativePath, "/")
 
+	fsCtx, cancel := context.WithTimeout(ctx, cfg.GetHealthFilesystemTimeout())
+	defer cancel()
 	deleteSourceNzb := cfg.Metadata.ShouldDeleteSourceNzb()
-	if delMetaErr := hw.metadataService.DeleteFileMetadataWithSourceNzb(ctx, relativePath, deleteSourceNzb); delMetaErr != nil {
+	if delMetaErr := hw.metadataService.DeleteFileMetadataWithSourceNzb(fsCtx, relativePath, deleteSourceNzb); delMetaErr != nil {
 		slog.ErrorContext(ctx, "Failed to delete metadata during cleanup", "file_path", item.FilePath, "error", delMetaErr)
 	}
 }
@@ -1347,6 +1506,14 @@ func (hw *HealthWorker) ensureMetadata(ctx context.Context, item *database.FileH
 	return nil
 }
 
+// SetStreamCanceller wires the tracker used to cancel in-progress streams of a
+// file once its metadata is moved to corrupted_metadata. Pass nil to clear.
+func (hw *HealthWorker) SetStreamCanceller(c StreamCanceller) {
+	hw.mu.Lock()
+	hw.streamCanceller = c
+	hw.mu.Unlock()
+}
+
 func (hw *HealthWorker) moveMetadataToSafetyFolder(ctx context.Context, item *database.FileHealth) {
 	if !item.IsImported() {
 		return
@@ -1355,7 +1522,21 @@ func (hw *HealthWorker) moveMetadataToSafetyFolder(ctx context.Context, item *da
 	relativePath := strings.TrimPrefix(item.FilePath, cfg.MountPath)
 	relativePath = strings.TrimPrefix(relativePath, "/")
 	slog.InfoContext(ctx, "Moving metadata file for corrupted item to safety folder to trigger replacement", "file_path", item.FilePath)
-	if moveErr := hw.metadataService.MoveToCorrupted(ctx, relativePath); moveErr != nil {
+	fsCtx, cancel := context.WithTimeout(ctx, cfg.GetHealthFilesystemTimeout())
+	defer cancel()
+	if moveErr := hw.metadataService.MoveToCorrupted(fsCtx, relativePath); moveErr != nil {
 		slog.WarnContext(ctx, "Failed to move corrupted metadata file", "error", moveErr)
+		return
+	}
+
+	// Clients still streaming the file would keep hitting the broken source;
+	// cut them off so they re-resolve once the replacement lands.
+	hw.mu.RLock()
+	canceller := hw.streamCanceller
+	hw.mu.RUnlock()
+	if canceller != nil {
+		if n := canceller.CancelStreamsForPath(relativePath); n > 0 {
+			slog.InfoContext(ctx, "Cancelled active streams for corrupted file", "file_path", item.FilePath, "streams", n)
+		}
 	}
 }
@@ -0,0 +1,53 @@
+package health
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/javi11/altmount/internal/config"
+)
+
+func newJitterTestWorker(intervalSeconds, jitter int) *HealthWorker {
+	cfg := &config.Config{}
+	cfg.Health.CheckIntervalSeconds = intervalSeconds
+	cfg.Health.IntervalJitter = jitter
+	return NewHealthWorker(nil, nil, nil, nil, nil, func() *config.Config { return cfg }, nil)
+}
+
+func TestNextCheckInterval(t *testing.T) {
+	t.Run("no jitter keeps the configured interval", func(t *testing.T) {
+		hw := newJitterTestWorker(60, 0)
+		for range 20 {
+			assert.Equal(t, time.Minute, hw.nextCheckInterval())
+		}
+	})
+
+	t.Run("jitter varies within bounds", func(t *testing.T) {
+		hw := newJitterTestWorker(60, 25)
+		seen := make(map[time.Duration]bool)
+		for range 200 {
+			interval := hw.nextCheckInterval()
+			assert.GreaterOrEqual(t, interval, 45*time.Second)
+			assert.LessOrEqual(t, interval, 75*time.Second)
+			assert.Equal(t, interval, hw.currentCheckInterval(), "reported interval tracks the armed one")
+			seen[interval] = true
+		}
+		assert.Greater(t, len(seen), 1, "consecutive intervals should differ")
+	})
+
+	t.Run("full jitter never drops below a second", func(t *testing.T) {
+		hw := newJitterTestWorker(1, 100)
+		for range 200 {
+			interval := hw.nextCheckInterval()
+			assert.GreaterOrEqual(t, interval, time.Second)
+			assert.LessOrEqual(t, interval, 2*time.Second)
+		}
+	})
+}
+
+func TestCurrentCheckIntervalBeforeStart(t *testing.T) {
+	hw := newJitterTestWorker(30, 50)
+	assert.Equal(t, 30*time.Second, hw.currentCheckInterval())
+}
@@ -0,0 +1,85 @@
+package health
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHealthWorkerPauseResume(t *testing.T) {
+	env := newRepairTestEnv(t, t.TempDir(), nil)
+	hw := env.hw
+	ctx := context.Background()
+
+	require.Error(t, hw.Pause(ctx), "pausing a stopped worker")
+	require.Error(t, hw.Resume(ctx), "resuming a stopped worker")
+
+	require.NoError(t, hw.Start(ctx))
+	t.Cleanup(func() { _ = hw.Stop(ctx) })
+
+	hw.tick(ctx)
+	require.Equal(t, int64(1), hw.GetStats().TotalRunsCompleted)
+
+	require.NoError(t, hw.Pause(ctx))
+	require.NoError(t, hw.Pause(ctx), "pausing twice is a no-op")
+	assert.True(t, hw.IsPaused())
+	assert.True(t, hw.IsRunning(), "paused worker keeps running")
+	assert.Equal(t, WorkerStatusPaused, hw.GetStats().Status)
+
+	hw.tick(ctx)
+	hw.tick(ctx)
+	stats := hw.GetStats()
+	assert.Equal(t, int64(1), stats.TotalRunsCompleted, "paused worker must skip cycles")
+	assert.NotNil(t, stats.LastRunTime, "stats survive the pause")
+
+	require.NoError(t, hw.Resume(ctx))
+	assert.False(t, hw.IsPaused())
+	assert.Equal(t, WorkerStatusRunning, hw.GetStats().Status)
+
+	hw.tick(ctx)
+	assert.Equal(t, int64(2), hw.GetStats().TotalRunsCompleted, "resumed worker runs cycles again")
+}
+
+func TestHealthWorkerStopClearsPause(t *testing.T) {
+	env := newRepairTestEnv(t, t.TempDir(), nil)
+	hw := env.hw
+	ctx := context.Background()
+
+	require.NoError(t, hw.Start(ctx))
+	require.NoError(t, hw.Pause(ctx))
+	require.NoError(t, hw.Stop(ctx))
+
+	assert.False(t, hw.IsPaused())
+	assert.Equal(t, WorkerStatusStopped, hw.GetStats().Status)
+}
+
+// heldOutPoolManager reports a provider held out by the circuit breaker while
+// heldOut is set.
+type heldOutPoolManager struct {
+	mockPoolManager
+	heldOut bool
+}
+
+func (m *heldOutPoolManager) ProvidersHeldOut() bool { return m.heldOut }
+
+func TestHealthWorkerWaitsWhileProviderHeldOut(t *testing.T) {
+	env := newRepairTestEnv(t, t.TempDir(), nil)
+	hw := env.hw
+	ctx := context.Background()
+
+	pm := &heldOutPoolManager{heldOut: true}
+	env.healthChecker.poolManager = pm
+
+	require.NoError(t, hw.Start(ctx))
+	t.Cleanup(func() { _ = hw.Stop(ctx) })
+
+	hw.tick(ctx)
+	assert.Equal(t, int64(0), hw.GetStats().TotalRunsCompleted, "cycles wait for the held-out provider")
+	assert.ErrorIs(t, hw.performDirectCheck(ctx, "movies/film.mkv"), ErrProviderHeldOut)
+
+	pm.heldOut = false
+	hw.tick(ctx)
+	assert.Equal(t, int64(1), hw.GetStats().TotalRunsCompleted, "cycles resume once it is back")
+}
@@ -0,0 +1,91 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/javi11/altmount/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// insertExhaustedRepairs seeds n repair_triggered records with a spent repair
+// budget. The repair-notification pass fetches at most maxJobs of them per
+// cycle and finalizes each as corrupted, so the count finalized by one cycle
+// shows the concurrency it ran with.
+func insertExhaustedRepairs(t *testing.T, env *repairTestEnv, n int) {
+	t.Helper()
+	for i := range n {
+		_, err := env.db.Exec(`
+			INSERT INTO file_health
+				(file_path, library_path, status, retry_count, max_retries,
+				 repair_retry_count, max_repair_retries, scheduled_check_at)
+			VALUES (?, ?, 'repair_triggered', 2, 3, 3, 3, datetime('now', '-1 second'))
+		`, fmt.Sprintf("series/peak.s01e%02d.mkv", i), fmt.Sprintf("/media/library/peak.s01e%02d.mkv", i))
+		require.NoError(t, err)
+	}
+}
+
+func countCorrupted(t *testing.T, env *repairTestEnv) int {
+	t.Helper()
+	var n int
+	require.NoError(t, env.db.QueryRow(`SELECT COUNT(*) FROM file_health WHERE status = 'corrupted'`).Scan(&n))
+	return n
+}
+
+func TestRunHealthCheckCycle_PeakHoursConcurrency(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("peak-hour cycle runs in the repair env, which uses POSIX mount paths")
+	}
+
+	peakConfig := func(cfg *config.Config) {
+		cfg.Health.MaxConcurrentJobs = 3
+		cfg.Health.PeakHours = config.HealthPeakHoursConfig{
+			Windows:           []string{"18:00-23:00"},
+			MaxConcurrentJobs: 1,
+		}
+	}
+
+	t.Run("reduced inside window", func(t *testing.T) {
+		env := newRepairTestEnv(t, t.TempDir(), nil, peakConfig)
+		env.hw.now = func() time.Time { return time.Date(2026, 3, 1, 20, 0, 0, 0, time.Local) }
+		insertExhaustedRepairs(t, env, 5)
+
+		require.NoError(t, env.hw.runHealthCheckCycle(context.Background()))
+
+		assert.Equal(t, 1, countCorrupted(t, env))
+		stats := env.hw.GetStats()
+		assert.Equal(t, 1, stats.MaxConcurrentJobs)
+		assert.True(t, stats.PeakHoursActive)
+	})
+
+	t.Run("full outside window", func(t *testing.T) {
+		env := newRepairTestEnv(t, t.TempDir(), nil, peakConfig)
+		env.hw.now = func() time.Time { return time.Date(2026, 3, 1, 9, 0, 0, 0, time.Local) }
+		insertExhaustedRepairs(t, env, 5)
+
+		require.NoError(t, env.hw.runHealthCheckCycle(context.Background()))
+
+		assert.Equal(t, 3, countCorrupted(t, env))
+		stats := env.hw.GetStats()
+		assert.Equal(t, 3, stats.MaxConcurrentJobs)
+		assert.False(t, stats.PeakHoursActive)
+	})
+
+	t.Run("re-evaluated every cycle", func(t *testing.T) {
+		env := newRepairTestEnv(t, t.TempDir(), nil, peakConfig)
+		clock := time.Date(2026, 3, 1, 22, 59, 0, 0, time.Local)
+		env.hw.now = func() time.Time { return clock }
+		insertExhaustedRepairs(t, env, 5)
+
+		require.NoError(t, env.hw.runHealthCheckCycle(context.Background()))
+		assert.Equal(t, 1, countCorrupted(t, env))
+
+		clock = clock.Add(2 * time.Minute) // past the window end
+		require.NoError(t, env.hw.runHealthCheckCycle(context.Background()))
+		assert.Equal(t, 4, countCorrupted(t, env))
+	})
+}
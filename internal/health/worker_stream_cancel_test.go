@@ -0,0 +1,47 @@
+package health
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingCanceller records the paths whose streams the worker asked to cancel.
+type recordingCanceller struct {
+	mu    sync.Mutex
+	paths []string
+}
+
+func (c *recordingCanceller) CancelStreamsForPath(virtualPath string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.paths = append(c.paths, virtualPath)
+	return 1
+}
+
+// TestE2E_RepairCancelsActiveStreams verifies that moving a file to the
+// corrupted folder during repair cancels the streams reading it.
+func TestE2E_RepairCancelsActiveStreams(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("repair's corrupted-folder move runs in the POSIX-path repair env")
+	}
+	env := newRepairTestEnv(t, t.TempDir(), nil)
+	canceller := &recordingCanceller{}
+	env.hw.SetStreamCanceller(canceller)
+
+	ctx := context.Background()
+	filePath := "series/show.s01e01.mkv"
+	maxRetries := 3
+	require.NoError(t, env.metadataService.WriteFileMetadata(filePath, validSegmentMeta(env.metadataService, 1024)))
+	insertFileHealth(t, env.db, filePath, "/media/library/show.s01e01.mkv", maxRetries-1, maxRetries)
+
+	require.NoError(t, env.hw.runHealthCheckCycle(ctx))
+
+	canceller.mu.Lock()
+	defer canceller.mu.Unlock()
+	assert.Equal(t, []string{filePath}, canceller.paths)
+}
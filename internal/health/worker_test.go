@@ -45,7 +45,8 @@ func setupWorkerTestDB(t *testing.T) (*database.HealthRepository, *sql.DB) {
 			streaming_failure_count INTEGER DEFAULT 0,
 			is_masked BOOLEAN DEFAULT FALSE,
 			indexer TEXT DEFAULT NULL,
-			download_id TEXT DEFAULT NULL
+			download_id TEXT DEFAULT NULL,
+			last_verified_at DATETIME DEFAULT NULL
 		);
 	`)
 	require.NoError(t, err)
@@ -0,0 +1,78 @@
+package health
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/javi11/altmount/internal/config"
+	"github.com/javi11/altmount/internal/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestHealthyCheckAutoUnmask verifies that a healthy result clears the failure
+// mask only when Streaming.FailureMasking.AutoUnmaskOnHealthy is enabled.
+func TestHealthyCheckAutoUnmask(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		autoUnmask bool
+		wantMasked bool
+		wantCount  int
+	}{
+		{name: "enabled unmasks", autoUnmask: true, wantMasked: false, wantCount: 0},
+		{name: "disabled keeps mask", autoUnmask: false, wantMasked: true, wantCount: 4},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			env := newRepairTestEnv(t, t.TempDir(), nil, func(cfg *config.Config) {
+				cfg.Streaming.FailureMasking.AutoUnmaskOnHealthy = tc.autoUnmask
+			})
+			ctx := context.Background()
+
+			filePath := "movies/masked-" + t.Name() + ".mkv"
+			_, err := env.db.Exec(`
+				INSERT INTO file_health (file_path, status, streaming_failure_count, is_masked)
+				VALUES (?, 'pending', 4, TRUE)
+			`, filePath)
+			require.NoError(t, err)
+
+			fh, err := env.healthRepo.GetFileHealth(ctx, filePath)
+			require.NoError(t, err)
+			require.True(t, fh.IsMasked)
+
+			update, sideEffect := env.hw.prepareUpdateForResult(ctx, fh, HealthEvent{
+				Type:     EventTypeFileHealthy,
+				FilePath: filePath,
+				Status:   database.HealthStatusHealthy,
+			})
+			assert.Equal(t, tc.autoUnmask, update.Unmask)
+			require.NoError(t, env.healthRepo.UpdateHealthStatusBulk(ctx, []database.HealthStatusUpdate{*update}))
+			_ = sideEffect() // no metadata file; only the database state matters here
+
+			got, err := env.healthRepo.GetFileHealth(ctx, filePath)
+			require.NoError(t, err)
+			assert.Equal(t, database.HealthStatusHealthy, got.Status)
+			assert.Equal(t, tc.wantMasked, got.IsMasked)
+			assert.Equal(t, tc.wantCount, got.StreamingFailureCount)
+		})
+	}
+}
+
+// TestHealthyCheckUnmaskIgnoresUnmaskedFiles verifies an unmasked file keeps
+// its failure count: auto-unmask only resets files that were masked.
+func TestHealthyCheckUnmaskIgnoresUnmaskedFiles(t *testing.T) {
+	env := newRepairTestEnv(t, t.TempDir(), nil, func(cfg *config.Config) {
+		cfg.Streaming.FailureMasking.AutoUnmaskOnHealthy = true
+	})
+
+	fh := &database.FileHealth{
+		FilePath:  "movies/unmasked.mkv",
+		Status:    database.HealthStatusPending,
+		CreatedAt: time.Now().UTC(),
+	}
+	update, _ := env.hw.prepareUpdateForResult(context.Background(), fh, HealthEvent{
+		Type:     EventTypeFileHealthy,
+		FilePath: fh.FilePath,
+	})
+	assert.False(t, update.Unmask)
+}
@@ -0,0 +1,52 @@
+package importer
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/javi11/altmount/internal/importer/archive/rar"
+	"github.com/javi11/altmount/internal/importer/archive/sevenzip"
+	"github.com/javi11/altmount/internal/importer/parser"
+	"github.com/javi11/altmount/internal/pool"
+	"github.com/javi11/altmount/internal/progress"
+)
+
+// SetMaxConcurrentAnalyses caps how many RAR and 7zip archive analyses may run
+// at once across all imports (Import.MaxConcurrentAnalyses). Analysis opens
+// every volume of an archive and is the memory-heavy phase of an import, so
+// it is bounded separately from the queue worker count. 0 = unlimited.
+func (proc *Processor) SetMaxConcurrentAnalyses(n int) {
+	proc.analysisGate.SetCap(n)
+}
+
+// gatedRarProcessor holds an analysis slot for the duration of each RAR
+// analysis.
+type gatedRarProcessor struct {
+	rar.Processor
+	gate *pool.ImportAdmission
+}
+
+func (p gatedRarProcessor) AnalyzeRarContentFromNzb(ctx context.Context, rarFiles []parser.ParsedFile, password string, progressTracker *progress.Tracker) ([]rar.Content, error) {
+	release, err := p.gate.Acquire(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("archive analysis cancelled: %w", err)
+	}
+	defer release()
+	return p.Processor.AnalyzeRarContentFromNzb(ctx, rarFiles, password, progressTracker)
+}
+
+// gatedSevenZipProcessor holds an analysis slot for the duration of each
+// 7zip analysis.
+type gatedSevenZipProcessor struct {
+	sevenzip.Processor
+	gate *pool.ImportAdmission
+}
+
+func (p gatedSevenZipProcessor) AnalyzeSevenZipContentFromNzb(ctx context.Context, sevenZipFiles []parser.ParsedFile, password string, progressTracker *progress.Tracker) ([]sevenzip.Content, error) {
+	release, err := p.gate.Acquire(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("archive analysis cancelled: %w", err)
+	}
+	defer release()
+	return p.Processor.AnalyzeSevenZipContentFromNzb(ctx, sevenZipFiles, password, progressTracker)
+}
@@ -0,0 +1,120 @@
+package importer
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/javi11/altmount/internal/importer/archive/rar"
+	"github.com/javi11/altmount/internal/importer/archive/sevenzip"
+	"github.com/javi11/altmount/internal/importer/parser"
+	"github.com/javi11/altmount/internal/pool"
+	"github.com/javi11/altmount/internal/progress"
+)
+
+// concurrencyProbe records the high-water mark of overlapping analyses.
+type concurrencyProbe struct {
+	inFlight atomic.Int32
+	max      atomic.Int32
+}
+
+func (p *concurrencyProbe) analyze() {
+	n := p.inFlight.Add(1)
+	for {
+		old := p.max.Load()
+		if n <= old || p.max.CompareAndSwap(old, n) {
+			break
+		}
+	}
+	time.Sleep(5 * time.Millisecond)
+	p.inFlight.Add(-1)
+}
+
+type probeRarProcessor struct {
+	rar.Processor
+	probe *concurrencyProbe
+}
+
+func (p probeRarProcessor) AnalyzeRarContentFromNzb(context.Context, []parser.ParsedFile, string, *progress.Tracker) ([]rar.Content, error) {
+	p.probe.analyze()
+	return nil, nil
+}
+
+type probeSevenZipProcessor struct {
+	sevenzip.Processor
+	probe *concurrencyProbe
+}
+
+func (p probeSevenZipProcessor) AnalyzeSevenZipContentFromNzb(context.Context, []parser.ParsedFile, string, *progress.Tracker) ([]sevenzip.Content, error) {
+	p.probe.analyze()
+	return nil, nil
+}
+
+// newProbedProcessor returns a processor whose RAR and 7zip analyses share
+// one probe behind the processor's analysis gate.
+func newProbedProcessor(probe *concurrencyProbe) *Processor {
+	gate := pool.NewImportAdmission()
+	return &Processor{
+		rarProcessor:      gatedRarProcessor{probeRarProcessor{probe: probe}, gate},
+		sevenZipProcessor: gatedSevenZipProcessor{probeSevenZipProcessor{probe: probe}, gate},
+		analysisGate:      gate,
+	}
+}
+
+func runAnalyses(t *testing.T, proc *Processor, n int) {
+	t.Helper()
+	ctx := context.Background()
+	var wg sync.WaitGroup
+	for i := range n {
+		wg.Go(func() {
+			var err error
+			if i%2 == 0 {
+				_, err = proc.rarProcessor.AnalyzeRarContentFromNzb(ctx, nil, "", nil)
+			} else {
+				_, err = proc.sevenZipProcessor.AnalyzeSevenZipContentFromNzb(ctx, nil, "", nil)
+			}
+			assert.NoError(t, err)
+		})
+	}
+	wg.Wait()
+}
+
+func TestAnalysisGateBoundsConcurrentAnalyses(t *testing.T) {
+	probe := &concurrencyProbe{}
+	proc := newProbedProcessor(probe)
+	proc.SetMaxConcurrentAnalyses(2)
+
+	runAnalyses(t, proc, 24)
+
+	assert.LessOrEqual(t, probe.max.Load(), int32(2), "analysis concurrency exceeded the cap")
+	assert.Positive(t, probe.max.Load())
+	assert.Zero(t, probe.inFlight.Load())
+}
+
+func TestAnalysisGateUnlimitedByDefault(t *testing.T) {
+	probe := &concurrencyProbe{}
+	proc := newProbedProcessor(probe)
+
+	runAnalyses(t, proc, 8)
+
+	assert.Greater(t, probe.max.Load(), int32(2), "analyses should overlap without a cap")
+}
+
+func TestAnalysisGateHonorsCancellation(t *testing.T) {
+	proc := newProbedProcessor(&concurrencyProbe{})
+	proc.SetMaxConcurrentAnalyses(1)
+
+	release, err := proc.analysisGate.Acquire(context.Background())
+	require.NoError(t, err)
+	defer release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err = proc.rarProcessor.AnalyzeRarContentFromNzb(ctx, nil, "", nil)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
@@ -43,6 +43,9 @@ type NestedSource struct {
 	InnerOffset     int64                 // Offset within decrypted inner volume where file data starts
 	InnerLength     int64                 // Bytes of target file from this source
 	InnerVolumeSize int64                 // Total decrypted size of inner volume (for AES cipher)
+	Encryption      metapb.Encryption     // Inner volume encryption; NONE with AesKey set means AES
+	Password        string                // Rclone password (RCLONE only; empty uses the global one)
+	Salt            string                // Rclone salt (RCLONE only; empty uses the global one)
 }
 
 // Content represents a file within an archive for processing
@@ -91,6 +94,48 @@ func GetContentSegments(content Content) []*metapb.SegmentData {
 	return content.Segments
 }
 
+// ValidateNestedContent checks that a file mapped out of an archive nested in
+// another accounts for exactly its Size. With nested sources (encrypted
+// outer archive) their InnerLength must add up to Size and each must lie
+// within its inner volume; otherwise the flattened segments must cover Size.
+// A mismatch means the inner volumes were parsed inconsistently, and the file
+// would read short, long or garbled. Nested files are always stored, so no
+// tolerance applies.
+func ValidateNestedContent(c Content) error {
+	if c.IsDirectory {
+		return nil
+	}
+
+	var total int64
+	if len(c.NestedSources) > 0 {
+		for i, ns := range c.NestedSources {
+			if ns.InnerOffset < 0 || ns.InnerLength < 0 {
+				return fmt.Errorf("%s: nested source %d has negative offset %d or length %d",
+					c.Filename, i, ns.InnerOffset, ns.InnerLength)
+			}
+			if ns.InnerVolumeSize > 0 && ns.InnerOffset+ns.InnerLength > ns.InnerVolumeSize {
+				return fmt.Errorf("%s: nested source %d spans bytes %d-%d of an inner volume of %d bytes",
+					c.Filename, i, ns.InnerOffset, ns.InnerOffset+ns.InnerLength, ns.InnerVolumeSize)
+			}
+			total += ns.InnerLength
+		}
+		if total != c.Size {
+			return fmt.Errorf("%s: %d nested sources hold %d bytes, but the file is %d bytes",
+				c.Filename, len(c.NestedSources), total, c.Size)
+		}
+		return nil
+	}
+
+	for _, seg := range c.Segments {
+		total += seg.EndOffset - seg.StartOffset + 1
+	}
+	if total != c.Size {
+		return fmt.Errorf("%s: nested segments hold %d bytes, but the file is %d bytes",
+			c.Filename, total, c.Size)
+	}
+	return nil
+}
+
 // ValidateSegmentIntegrity checks if the segments provided for a file actually cover the expected size.
 // Returns an error if segment coverage is significantly lower than expected (1% shortfall threshold).
 func ValidateSegmentIntegrity(ctx context.Context, content Content) error {
@@ -56,3 +56,80 @@ func TestValidateSegmentIntegrityUsesPackedSize(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateNestedContent(t *testing.T) {
+	tests := []struct {
+		name    string
+		content Content
+		wantErr bool
+	}{
+		{
+			name: "nested sources add up to Size",
+			content: Content{
+				Filename: "movie.mkv",
+				Size:     200,
+				NestedSources: []NestedSource{
+					{InnerOffset: 30, InnerLength: 120, InnerVolumeSize: 150},
+					{InnerOffset: 20, InnerLength: 80, InnerVolumeSize: 150},
+				},
+			},
+		},
+		{
+			name: "nested sources short of Size",
+			content: Content{
+				Filename: "movie.mkv",
+				Size:     250,
+				NestedSources: []NestedSource{
+					{InnerOffset: 30, InnerLength: 120, InnerVolumeSize: 150},
+					{InnerOffset: 20, InnerLength: 80, InnerVolumeSize: 150},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "nested source past the end of its inner volume",
+			content: Content{
+				Filename: "movie.mkv",
+				Size:     200,
+				NestedSources: []NestedSource{
+					{InnerOffset: 50, InnerLength: 120, InnerVolumeSize: 150},
+					{InnerOffset: 20, InnerLength: 80, InnerVolumeSize: 150},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "flat segments cover Size",
+			content: Content{
+				Filename: "movie.mkv",
+				Size:     150,
+				Segments: []*metapb.SegmentData{seg("a@x", 100), seg("b@x", 50)},
+			},
+		},
+		{
+			name: "flat segments short of Size",
+			content: Content{
+				Filename: "movie.mkv",
+				Size:     150,
+				Segments: []*metapb.SegmentData{seg("a@x", 100)},
+			},
+			wantErr: true,
+		},
+		{
+			name:    "directory",
+			content: Content{Filename: "Extras", IsDirectory: true, Size: 10},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateNestedContent(tt.content)
+			if tt.wantErr && err == nil {
+				t.Fatalf("expected error, got nil")
+			}
+			if !tt.wantErr && err != nil {
+				t.Fatalf("expected nil, got %v", err)
+			}
+		})
+	}
+}
@@ -78,13 +78,17 @@ type nestedSourceShareKey struct {
 	aesKey          string
 	aesIv           string
 	innerVolumeSize int64
+	encryption      metapb.Encryption
+	password        string
+	salt            string
 }
 
 // shareKeyFor builds a sharing key. It uses the backing-array pointer of
 // the Segments slice (cheap O(1) check) plus the slice length to catch
-// accidental pointer reuse across distinct slices. The AES key/iv and
-// inner_volume_size complete the identity — two sources are only
-// shareable when those match exactly.
+// accidental pointer reuse across distinct slices. The encryption type and
+// credentials (AES key/iv or rclone password/salt) and inner_volume_size
+// complete the identity — two sources are only shareable when those match
+// exactly.
 func shareKeyFor(ns NestedSource) nestedSourceShareKey {
 	var ptr uintptr
 	if len(ns.Segments) > 0 {
@@ -96,6 +100,9 @@ func shareKeyFor(ns NestedSource) nestedSourceShareKey {
 		aesKey:          string(ns.AesKey),
 		aesIv:           string(ns.AesIV),
 		innerVolumeSize: ns.InnerVolumeSize,
+		encryption:      ns.Encryption,
+		password:        ns.Password,
+		salt:            ns.Salt,
 	}
 }
 
@@ -138,6 +145,9 @@ func appendNestedSourcesWithDedupe(meta *metapb.FileMetadata, sources []NestedSo
 			AesKey:          ns.AesKey,
 			AesIv:           ns.AesIV,
 			InnerVolumeSize: ns.InnerVolumeSize,
+			Encryption:      ns.Encryption,
+			Password:        ns.Password,
+			Salt:            ns.Salt,
 		})
 		keyToIndex[key] = int32(len(meta.SharedOuterSources)) // 1-based
 	}
@@ -156,6 +166,9 @@ func appendNestedSourcesWithDedupe(meta *metapb.FileMetadata, sources []NestedSo
 			entry.AesKey = ns.AesKey
 			entry.AesIv = ns.AesIV
 			entry.InnerVolumeSize = ns.InnerVolumeSize
+			entry.Encryption = ns.Encryption
+			entry.Password = ns.Password
+			entry.Salt = ns.Salt
 		}
 		meta.NestedSources = append(meta.NestedSources, entry)
 	}
@@ -0,0 +1,95 @@
+package archive
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/javi11/altmount/internal/config"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/unicode/norm"
+)
+
+// NormalizeMemberName turns a name read from an archive into a
+// filesystem-safe UTF-8 path. Names that are not valid UTF-8 are decoded with
+// enc, byte order marks are dropped, control characters and any bytes
+// left invalid are replaced with '_', backslash separators become '/', and
+// the result is NFC-normalized so names from macOS archives match their
+// precomposed form.
+func NormalizeMemberName(name string, enc config.FilenameEncoding) string {
+	if !utf8.ValidString(name) {
+		name = decodeLegacyName(name, enc)
+	}
+	name = strings.ReplaceAll(name, "\ufeff", "")
+	name = strings.ToValidUTF8(name, "_")
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return '_'
+		}
+		return r
+	}, name)
+	name = strings.ReplaceAll(name, "\\", "/")
+	return norm.NFC.String(name)
+}
+
+// decodeLegacyName decodes a name stored in a single-byte code page. With
+// FilenameEncodingUTF8 the name is returned as is for the caller to replace
+// the invalid bytes.
+func decodeLegacyName(name string, enc config.FilenameEncoding) string {
+	var cm *charmap.Charmap
+	switch enc {
+	case config.FilenameEncodingUTF8:
+		return name
+	case config.FilenameEncodingCP437:
+		cm = charmap.CodePage437
+	case config.FilenameEncodingCP850:
+		cm = charmap.CodePage850
+	case config.FilenameEncodingWindows1251:
+		cm = charmap.Windows1251
+	case config.FilenameEncodingWindows1252:
+		cm = charmap.Windows1252
+	default:
+		// Auto: Windows-1252 covers most names from Western Windows
+		// archivers; the few bytes it leaves unmapped only occur in DOS
+		// OEM names, which CP437 decodes in full.
+		cm = charmap.Windows1252
+		if !mapsAllBytes(cm, name) {
+			cm = charmap.CodePage437
+		}
+	}
+	decoded, err := cm.NewDecoder().String(name)
+	if err != nil {
+		return name
+	}
+	return decoded
+}
+
+// mapsAllBytes reports whether every byte of s has a mapping in cm.
+func mapsAllBytes(cm *charmap.Charmap, s string) bool {
+	for i := 0; i < len(s); i++ {
+		if cm.DecodeByte(s[i]) == utf8.RuneError {
+			return false
+		}
+	}
+	return true
+}
+
+// UniqueMemberPath returns virtualPath, or the first free name_1.ext,
+// name_2.ext, … when another member of the same archive already took it, and
+// records the result in used. Without it, two members that land on one
+// virtual path (a flattened layout, or a/x.mkv next to a collapsed x.mkv)
+// would silently overwrite each other.
+func UniqueMemberPath(virtualPath string, used map[string]struct{}) string {
+	candidate := virtualPath
+	ext := path.Ext(virtualPath)
+	stem := strings.TrimSuffix(virtualPath, ext)
+	for i := 1; ; i++ {
+		if _, taken := used[candidate]; !taken {
+			used[candidate] = struct{}{}
+			return candidate
+		}
+		candidate = fmt.Sprintf("%s_%d%s", stem, i, ext)
+	}
+}
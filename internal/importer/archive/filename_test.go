@@ -0,0 +1,57 @@
+package archive
+
+import (
+	"testing"
+	"unicode/utf8"
+
+	"github.com/javi11/altmount/internal/config"
+)
+
+func TestNormalizeMemberName(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		enc  config.FilenameEncoding
+		want string
+	}{
+		{"utf8 kept", "Movies/Amélie.mkv", "", "Movies/Amélie.mkv"},
+		{"backslashes", `Movies\Amélie.mkv`, "", "Movies/Amélie.mkv"},
+		{"bom dropped", "\ufeffAmélie.mkv", "", "Amélie.mkv"},
+		{"nfd composed", "Ame\u0301lie.mkv", "", "Amélie.mkv"},
+		{"control replaced", "a\x01b.mkv", "", "a_b.mkv"},
+		{"auto windows-1252", "Am\xe9lie.mkv", config.FilenameEncodingAuto, "Amélie.mkv"},
+		{"auto falls back to cp437", "Am\x82lie\x81.mkv", "", "Amélieü.mkv"},
+		{"cp437", "Am\x82lie.mkv", config.FilenameEncodingCP437, "Amélie.mkv"},
+		{"cp850", "Espa\xa4a.mkv", config.FilenameEncodingCP850, "España.mkv"},
+		{"windows-1251", "\xcf\xf0\xe8\xe2\xe5\xf2.mkv", config.FilenameEncodingWindows1251, "Привет.mkv"},
+		{"utf8 replaces invalid", "Am\xe9lie.mkv", config.FilenameEncodingUTF8, "Am_lie.mkv"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NormalizeMemberName(tt.in, tt.enc)
+			if got != tt.want {
+				t.Errorf("NormalizeMemberName(%q, %q) = %q, want %q", tt.in, tt.enc, got, tt.want)
+			}
+			if !utf8.ValidString(got) {
+				t.Errorf("result %q is not valid UTF-8", got)
+			}
+		})
+	}
+}
+
+func TestUniqueMemberPath(t *testing.T) {
+	used := make(map[string]struct{})
+	for _, tt := range []struct{ in, want string }{
+		{"movies/X/file.mkv", "movies/X/file.mkv"},
+		{"movies/X/file.mkv", "movies/X/file_1.mkv"},
+		{"movies/X/file.mkv", "movies/X/file_2.mkv"},
+		{"movies/X/file_1.mkv", "movies/X/file_1_1.mkv"},
+		{"movies/X/other.mkv", "movies/X/other.mkv"},
+		{"movies/X/README", "movies/X/README"},
+		{"movies/X/README", "movies/X/README_1"},
+	} {
+		if got := UniqueMemberPath(tt.in, used); got != tt.want {
+			t.Errorf("UniqueMemberPath(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
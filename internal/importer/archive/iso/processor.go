@@ -205,6 +205,8 @@ func sliceSegmentsForRange(segments []*metapb.SegmentData, offset int64, size in
 				StartOffset: relStart,
 				EndOffset:   relEnd,
 				SegmentSize: seg.SegmentSize,
+				UsableStart: seg.UsableStart,
+				UsableEnd:   seg.UsableEnd,
 			})
 			covered += relEnd - relStart + 1
 			if overlapEnd == targetEnd {
@@ -0,0 +1,30 @@
+package archive
+
+import "github.com/javi11/altmount/internal/importer/utils"
+
+// LimitCheck vets the size of an archive import once analysis knows what it
+// would expose, before any metadata is written. A non-nil error rejects the
+// whole import.
+type LimitCheck func(files int, totalBytes int64) error
+
+// CheckLimits counts the files in contents that would be imported (allowed
+// extensions, samples filtered) and passes the totals to check. A nil check
+// accepts everything.
+func CheckLimits(check LimitCheck, contents []Content, allowedExtensions []string, filterSamples bool) error {
+	if check == nil {
+		return nil
+	}
+	files := 0
+	var totalBytes int64
+	for _, c := range contents {
+		if c.IsDirectory {
+			continue
+		}
+		if utils.IsAllowedFile(c.InternalPath, c.Size, allowedExtensions, filterSamples) ||
+			utils.IsAllowedFile(c.Filename, c.Size, allowedExtensions, filterSamples) {
+			files++
+			totalBytes += c.Size
+		}
+	}
+	return check(files, totalBytes)
+}
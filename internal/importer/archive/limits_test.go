@@ -0,0 +1,36 @@
+package archive
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCheckLimitsCountsImportedFilesOnly(t *testing.T) {
+	contents := []Content{
+		{Filename: "Movie.mkv", InternalPath: "Movie.mkv", Size: 4 << 30},
+		{Filename: "Movie.srt", InternalPath: "Movie.srt", Size: 40 << 10},
+		{Filename: "notes.exe", InternalPath: "notes.exe", Size: 1 << 20},
+		{Filename: "Extras", InternalPath: "Extras", IsDirectory: true},
+	}
+
+	var gotFiles int
+	var gotBytes int64
+	err := CheckLimits(func(files int, totalBytes int64) error {
+		gotFiles, gotBytes = files, totalBytes
+		return nil
+	}, contents, []string{".mkv", ".srt"}, false)
+	if err != nil {
+		t.Fatalf("CheckLimits() = %v", err)
+	}
+	if gotFiles != 2 || gotBytes != 4<<30+40<<10 {
+		t.Errorf("check saw %d files / %d bytes, want 2 files / %d bytes", gotFiles, gotBytes, int64(4<<30+40<<10))
+	}
+
+	reject := errors.New("too big")
+	if err := CheckLimits(func(int, int64) error { return reject }, contents, nil, false); !errors.Is(err, reject) {
+		t.Errorf("CheckLimits() = %v, want the check's error", err)
+	}
+	if err := CheckLimits(nil, contents, nil, false); err != nil {
+		t.Errorf("CheckLimits(nil) = %v, want nil", err)
+	}
+}
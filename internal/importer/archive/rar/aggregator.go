@@ -15,6 +15,7 @@ import (
 
 	concpool "github.com/sourcegraph/conc/pool"
 
+	"github.com/javi11/altmount/internal/config"
 	"github.com/javi11/altmount/internal/encryption/aes"
 	"github.com/javi11/altmount/internal/importer/archive"
 	"github.com/javi11/altmount/internal/importer/filesystem"
@@ -102,10 +103,23 @@ type ProcessArchiveOptions struct {
 	ExpandBlurayIso        bool
 	FilterSamples          bool
 	RenameToNzbName        bool
+	// FlattenStructure places every member directly in VirtualDir instead of
+	// mirroring the archive's internal directories (Import.PreserveArchiveStructure
+	// set to false). Members that then share a name are suffixed _1, _2, ….
+	FlattenStructure bool
 	// SegmentIndex + StoreRef enable direct v3 store-backed metadata writes. When
 	// StoreRef is empty the aggregator falls back to v1 inline-segment metadata.
 	SegmentIndex map[string]int64
 	StoreRef     string
+	// VerifyReadback, when set, reads back each file's metadata through the
+	// streaming pipeline before it is written; files that fail are skipped.
+	VerifyReadback func(ctx context.Context, virtualPath string, meta *metapb.FileMetadata) error
+	// CheckLimits, when set, vets the analyzed archive's file count and size
+	// before anything is written; an error fails the import.
+	CheckLimits archive.LimitCheck
+	// OnPathCollision governs extracted files landing on a path held by a
+	// healthy file. Unset skips them, leaving the existing file in place.
+	OnPathCollision config.PathCollision
 }
 
 // ProcessArchive analyzes and processes RAR archive files, creating metadata for all extracted files.
@@ -224,6 +238,11 @@ func ProcessArchive(ctx context.Context, opts ProcessArchiveOptions) error {
 		return err
 	}
 
+	if err := archive.CheckLimits(opts.CheckLimits, rarContents, allowedFileExtensions, filterSamples); err != nil {
+		slog.WarnContext(ctx, "RAR archive rejected by import limits", "error", err)
+		return err
+	}
+
 	slog.InfoContext(ctx, "Starting RAR archive processing",
 		"total_files", len(rarContents))
 
@@ -260,6 +279,11 @@ func ProcessArchive(ctx context.Context, opts ProcessArchiveOptions) error {
 
 	var filesToProcess []fileToProcess
 	preProcessedCount := 0 // healthy files already counted as processed
+	// reserver keeps versioned paths unique within the archive as well as
+	// against disk; claims are held for the whole import.
+	reserver := filesystem.NewPathReserver(metadataService)
+	// memberPaths keeps two members of this archive off the same virtual path.
+	memberPaths := make(map[string]struct{})
 
 	for _, rarContent := range rarContents {
 		if rarContent.IsDirectory {
@@ -306,6 +330,10 @@ func ProcessArchive(ctx context.Context, opts ProcessArchiveOptions) error {
 			internalSubDir = "."
 		}
 
+		if opts.FlattenStructure {
+			internalSubDir = "."
+		}
+
 		var virtualFilePath string
 		if internalSubDir == "." || internalSubDir == "" {
 			virtualFilePath = filepath.Join(virtualDir, baseFilename)
@@ -317,15 +345,24 @@ func ProcessArchive(ctx context.Context, opts ProcessArchiveOptions) error {
 			virtualFilePath = filepath.Join(subDir, baseFilename)
 		}
 		virtualFilePath = strings.ReplaceAll(virtualFilePath, string(filepath.Separator), "/")
+		if unique := archive.UniqueMemberPath(virtualFilePath, memberPaths); unique != virtualFilePath {
+			slog.InfoContext(ctx, "Renaming RAR member that shares a path with another member",
+				"original", virtualFilePath,
+				"renamed", unique)
+			virtualFilePath = unique
+		}
 
-		if existingMeta, err := metadataService.ReadFileMetadata(virtualFilePath); err == nil && existingMeta != nil {
-			if existingMeta.Status == metapb.FileStatus_FILE_STATUS_HEALTHY {
+		switch opts.OnPathCollision.Or(config.PathCollisionSkip) {
+		case config.PathCollisionSkip:
+			if _, ok := filesystem.ResolvePathCollision(virtualFilePath, config.PathCollisionSkip, metadataService); !ok {
 				slog.InfoContext(ctx, "Skipping re-import of healthy RAR-extracted file",
 					"file", baseFilename,
 					"virtual_path", virtualFilePath)
 				preProcessedCount++
 				continue
 			}
+		case config.PathCollisionVersion:
+			virtualFilePath = reserver.Reserve(virtualFilePath)
 		}
 
 		isPreExtracted := false
@@ -394,6 +431,15 @@ func ProcessArchive(ctx context.Context, opts ProcessArchiveOptions) error {
 
 			fileMeta := rarProcessor.CreateFileMetadataFromRarContent(item.content, nzbPath, releaseDate, item.content.NzbdavID)
 
+			if opts.VerifyReadback != nil {
+				if err := opts.VerifyReadback(ctx, item.virtualFilePath, fileMeta); err != nil {
+					slog.ErrorContext(ctx, "Skipping RAR file due to readback verification failure",
+						"file", item.baseFilename,
+						"error", err)
+					return nil
+				}
+			}
+
 			metadataPath := metadataService.GetMetadataFilePath(item.virtualFilePath)
 			if _, err := os.Stat(metadataPath); err == nil {
 				_ = metadataService.DeleteFileMetadata(item.virtualFilePath)
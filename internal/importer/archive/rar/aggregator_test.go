@@ -225,6 +225,7 @@ func TestProcessArchivePreservesInternalFolderStructure(t *testing.T) {
 		virtualDir       string
 		nzbPath          string
 		renameToNzbName  bool
+		flatten          bool                       // FlattenStructure
 		extractedFiles   []parser.ExtractedFileInfo // override; nil = auto-build from contents
 		wantMetaPaths    []string                   // virtual paths expected to have metadata
 		notWantMetaPaths []string                   // virtual paths that must NOT have metadata
@@ -316,6 +317,45 @@ func TestProcessArchivePreservesInternalFolderStructure(t *testing.T) {
 			wantMetaPaths:    []string{"movies/MyMovie/Extras/featurette.mkv"},
 			notWantMetaPaths: []string{"movies/MyMovie/featurette.mkv"},
 		},
+		{
+			name:       "same name in two directories: both kept",
+			virtualDir: "movies/MyMovie",
+			nzbPath:    "movies/MyMovie.nzb",
+			contents: []Content{
+				{InternalPath: "a/file.mkv", Filename: "file.mkv", Size: 1000,
+					Segments: []*metapb.SegmentData{{Id: "seg1", StartOffset: 0, EndOffset: 999}}},
+				{InternalPath: "b/file.mkv", Filename: "file.mkv", Size: 1000,
+					Segments: []*metapb.SegmentData{{Id: "seg2", StartOffset: 0, EndOffset: 999}}},
+			},
+			wantMetaPaths:    []string{"movies/MyMovie/a/file.mkv", "movies/MyMovie/b/file.mkv"},
+			notWantMetaPaths: []string{"movies/MyMovie/file.mkv"},
+		},
+		{
+			name:       "same name flattened: suffixed instead of overwritten",
+			virtualDir: "movies/MyMovie",
+			nzbPath:    "movies/MyMovie.nzb",
+			flatten:    true,
+			contents: []Content{
+				{InternalPath: "a/file.mkv", Filename: "file.mkv", Size: 1000,
+					Segments: []*metapb.SegmentData{{Id: "seg1", StartOffset: 0, EndOffset: 999}}},
+				{InternalPath: "b/file.mkv", Filename: "file.mkv", Size: 1000,
+					Segments: []*metapb.SegmentData{{Id: "seg2", StartOffset: 0, EndOffset: 999}}},
+			},
+			wantMetaPaths:    []string{"movies/MyMovie/file.mkv", "movies/MyMovie/file_1.mkv"},
+			notWantMetaPaths: []string{"movies/MyMovie/a/file.mkv", "movies/MyMovie/b/file.mkv"},
+		},
+		{
+			name:       "collapsed release folder next to a same-named file: suffixed",
+			virtualDir: "movies/MyMovie",
+			nzbPath:    "movies/MyMovie.nzb",
+			contents: []Content{
+				{InternalPath: "file.mkv", Filename: "file.mkv", Size: 1000,
+					Segments: []*metapb.SegmentData{{Id: "seg1", StartOffset: 0, EndOffset: 999}}},
+				{InternalPath: "MyMovie/file.mkv", Filename: "file.mkv", Size: 1000,
+					Segments: []*metapb.SegmentData{{Id: "seg2", StartOffset: 0, EndOffset: 999}}},
+			},
+			wantMetaPaths: []string{"movies/MyMovie/file.mkv", "movies/MyMovie/file_1.mkv"},
+		},
 	}
 
 	for _, tt := range tests {
@@ -354,6 +394,7 @@ func TestProcessArchivePreservesInternalFolderStructure(t *testing.T) {
 				ExpandBlurayIso:        false,
 				FilterSamples:          false,
 				RenameToNzbName:        tt.renameToNzbName,
+				FlattenStructure:       tt.flatten,
 			})
 			require.NoError(t, err)
 
@@ -0,0 +1,78 @@
+package rar
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/javi11/altmount/internal/importer/archive"
+	metapb "github.com/javi11/altmount/internal/metadata/proto"
+	"github.com/javi11/rardecode/v2"
+	"github.com/stretchr/testify/require"
+)
+
+// innerVolumes indexes two decrypted inner volumes of 150 bytes each.
+func innerVolumes() partLocator[*Content] {
+	vols := []*Content{
+		{Filename: "inner.part1.rar", Size: 150, Segments: []*metapb.SegmentData{seg("v1", 150)}},
+		{Filename: "inner.part2.rar", Size: 150, Segments: []*metapb.SegmentData{seg("v2", 150)}},
+	}
+	idx := newPartLocator[*Content](len(vols))
+	for _, v := range vols {
+		idx.add(v.Filename, v)
+	}
+	return idx
+}
+
+func TestMapNestedFileEncryptedConsistent(t *testing.T) {
+	rh := &rarProcessor{log: slog.Default()}
+	af := rardecode.ArchiveFileInfo{
+		Name:              "movie.mkv",
+		TotalUnpackedSize: 200,
+		TotalPackedSize:   200,
+		Parts: []rardecode.FilePartInfo{
+			{Path: "inner.part1.rar", DataOffset: 30, PackedSize: 120},
+			{Path: "inner.part2.rar", DataOffset: 20, PackedSize: 80},
+		},
+	}
+
+	content, err := rh.mapNestedFileEncrypted(context.Background(), af, af.Name, innerVolumes())
+	require.NoError(t, err)
+	require.Len(t, content.NestedSources, 2)
+	require.NoError(t, archive.ValidateNestedContent(content))
+}
+
+func TestMapNestedFileEncryptedInconsistentSizes(t *testing.T) {
+	rh := &rarProcessor{log: slog.Default()}
+	af := rardecode.ArchiveFileInfo{
+		Name:              "movie.mkv",
+		TotalUnpackedSize: 210, // ten bytes more than the parts hold
+		TotalPackedSize:   200,
+		Parts: []rardecode.FilePartInfo{
+			{Path: "inner.part1.rar", DataOffset: 30, PackedSize: 120},
+			{Path: "inner.part2.rar", DataOffset: 20, PackedSize: 80},
+		},
+	}
+
+	content, err := rh.mapNestedFileEncrypted(context.Background(), af, af.Name, innerVolumes())
+	require.NoError(t, err)
+	err = archive.ValidateNestedContent(content)
+	require.ErrorContains(t, err, "movie.mkv: 2 nested sources hold 200 bytes, but the file is 210 bytes")
+}
+
+func TestMapNestedFileFlatMissingVolume(t *testing.T) {
+	rh := &rarProcessor{log: slog.Default()}
+	af := rardecode.ArchiveFileInfo{
+		Name:              "movie.mkv",
+		TotalUnpackedSize: 200,
+		TotalPackedSize:   200,
+		Parts: []rardecode.FilePartInfo{
+			{Path: "inner.part1.rar", DataOffset: 30, PackedSize: 120},
+			{Path: "inner.part3.rar", DataOffset: 20, PackedSize: 80}, // not in the index
+		},
+	}
+
+	content, err := rh.mapNestedFileFlat(context.Background(), af, af.Name, innerVolumes())
+	require.NoError(t, err)
+	require.Error(t, archive.ValidateNestedContent(content))
+}
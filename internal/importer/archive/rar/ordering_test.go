@@ -0,0 +1,97 @@
+package rar
+
+import (
+	"context"
+	stderrors "errors"
+	"log/slog"
+	"testing"
+
+	"github.com/javi11/altmount/internal/errors"
+	"github.com/javi11/rardecode/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func storedFile(name string, declared int64, parts ...rardecode.FilePartInfo) rardecode.ArchiveFileInfo {
+	var packed int64
+	for _, p := range parts {
+		packed += p.PackedSize
+	}
+	return rardecode.ArchiveFileInfo{
+		Name:              name,
+		TotalUnpackedSize: declared,
+		TotalPackedSize:   packed,
+		AllStored:         true,
+		Parts:             parts,
+	}
+}
+
+func requireMisordered(t *testing.T, err error) *MisorderedVolumesError {
+	t.Helper()
+	require.Error(t, err)
+	assert.True(t, errors.IsNonRetryable(err))
+	var mErr *MisorderedVolumesError
+	require.True(t, stderrors.As(err, &mErr), "want MisorderedVolumesError, got %v", err)
+	return mErr
+}
+
+func TestCheckPartOrdering(t *testing.T) {
+	log := slog.Default()
+	ctx := context.Background()
+	volumes := makeVolumes("Movie", 4, 100)
+
+	t.Run("consistent set passes", func(t *testing.T) {
+		agg := []rardecode.ArchiveFileInfo{storedFile("movie.mkv", 350,
+			rardecode.FilePartInfo{Path: "Movie.part1.rar", PackedSize: 100},
+			rardecode.FilePartInfo{Path: "Movie.part2.rar", PackedSize: 100},
+			rardecode.FilePartInfo{Path: "Movie.part3.rar", PackedSize: 100},
+			rardecode.FilePartInfo{Path: "Movie.part4.rar", PackedSize: 50},
+		)}
+		assert.NoError(t, checkPartOrdering(ctx, log, agg, volumes))
+	})
+
+	t.Run("final volume swapped into the middle ends the file early", func(t *testing.T) {
+		// part3 carries the short final volume, so the file stops there and the
+		// real third volume, labelled part4, is never followed.
+		agg := []rardecode.ArchiveFileInfo{storedFile("movie.mkv", 350,
+			rardecode.FilePartInfo{Path: "Movie.part1.rar", PackedSize: 100},
+			rardecode.FilePartInfo{Path: "Movie.part2.rar", PackedSize: 100},
+			rardecode.FilePartInfo{Path: "Movie.part3.rar", PackedSize: 50},
+		)}
+		mErr := requireMisordered(t, checkPartOrdering(ctx, log, agg, volumes))
+		assert.Equal(t, "movie.mkv", mErr.File)
+		assert.Equal(t, int64(350), mErr.DeclaredSize)
+		assert.Equal(t, int64(250), mErr.ComputedSize)
+		assert.Equal(t, []string{"Movie.part3.rar", "Movie.part4.rar"}, mErr.SuspectParts)
+		assert.Contains(t, mErr.Error(), "Movie.part3.rar, Movie.part4.rar")
+	})
+
+	t.Run("swapped part numbers are named", func(t *testing.T) {
+		agg := []rardecode.ArchiveFileInfo{storedFile("movie.mkv", 350,
+			rardecode.FilePartInfo{Path: "Movie.part1.rar", PackedSize: 100},
+			rardecode.FilePartInfo{Path: "Movie.part3.rar", PackedSize: 100},
+			rardecode.FilePartInfo{Path: "Movie.part2.rar", PackedSize: 100},
+		)}
+		mErr := requireMisordered(t, checkPartOrdering(ctx, log, agg, volumes))
+		assert.Equal(t, []string{"Movie.part3.rar", "Movie.part2.rar"}, mErr.SuspectParts)
+	})
+
+	t.Run("parts running past the declared size are named", func(t *testing.T) {
+		agg := []rardecode.ArchiveFileInfo{storedFile("movie.mkv", 200,
+			rardecode.FilePartInfo{Path: "Movie.part1.rar", PackedSize: 100},
+			rardecode.FilePartInfo{Path: "Movie.part2.rar", PackedSize: 100},
+			rardecode.FilePartInfo{Path: "Movie.part3.rar", PackedSize: 100},
+		)}
+		mErr := requireMisordered(t, checkPartOrdering(ctx, log, agg, volumes))
+		assert.Equal(t, int64(300), mErr.ComputedSize)
+		assert.Equal(t, []string{"Movie.part3.rar"}, mErr.SuspectParts)
+	})
+
+	t.Run("encrypted and compressed files are not judged", func(t *testing.T) {
+		encrypted := storedFile("enc.mkv", 90, rardecode.FilePartInfo{Path: "Movie.part1.rar", PackedSize: 96})
+		encrypted.AnyEncrypted = true
+		compressed := storedFile("packed.mkv", 500, rardecode.FilePartInfo{Path: "Movie.part1.rar", PackedSize: 100})
+		compressed.AllStored = false
+		assert.NoError(t, checkPartOrdering(ctx, log, []rardecode.ArchiveFileInfo{encrypted, compressed}, volumes))
+	})
+}
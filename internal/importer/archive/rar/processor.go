@@ -2,6 +2,7 @@ package rar
 
 import (
 	"context"
+	stderrors "errors"
 	"fmt"
 	"log/slog"
 	"path/filepath"
@@ -157,6 +158,12 @@ func (rh *rarProcessor) AnalyzeRarContentFromNzb(ctx context.Context, rarFiles [
 	// Create iterator for memory-efficient archive traversal
 	aggregatedFiles, err := rardecode.ListArchiveInfo(mainRarFile, opts...)
 	if err != nil {
+		// Volumes that record their own number (RAR5, newer RAR4) are rejected by
+		// rardecode when opened under the wrong name — say what that means.
+		if stderrors.Is(err, rardecode.ErrBadVolumeNumber) {
+			return nil, errors.NewNonRetryableError(
+				fmt.Sprintf("RAR archive %q has misordered volumes: a volume's header does not match its NZB part number (check NZB part numbering)", mainRarFile), err)
+		}
 		// Check if error indicates incomplete RAR archive with missing volume segments
 		return nil, errors.NewNonRetryableError(fmt.Sprintf("failed to iterate RAR archive %q", mainRarFile), err)
 	}
@@ -182,6 +189,14 @@ func (rh *rarProcessor) AnalyzeRarContentFromNzb(ctx context.Context, rarFiles [
 		return nil, err
 	}
 
+	// A set whose NZB part numbering does not match the real volume order still
+	// analyzes, but slicePartSegments then maps bytes from the wrong volumes. For
+	// stored files the parts must add up to the declared size exactly, so a
+	// mismatch points at swapped volumes — name them instead of importing garbage.
+	if err := checkPartOrdering(ctx, rh.log, aggregatedFiles, normalizedFiles); err != nil {
+		return nil, err
+	}
+
 	duration := time.Since(start)
 	rh.log.InfoContext(ctx, "RAR analysis completed", "duration_s", duration.Seconds(), "files_in_archive", len(aggregatedFiles))
 
@@ -269,6 +284,125 @@ func checkVolumeCoverage(ctx context.Context, log *slog.Logger, aggregatedFiles
 	)
 }
 
+// MisorderedVolumesError reports a stored file whose parts do not add up to
+// its declared size, naming the volumes most likely out of place.
+type MisorderedVolumesError struct {
+	File         string
+	DeclaredSize int64
+	ComputedSize int64
+	// SuspectParts are the volume file names most likely misordered, in the
+	// order they were followed; empty when no single volume stands out.
+	SuspectParts []string
+}
+
+func (e *MisorderedVolumesError) Error() string {
+	suspects := "unknown volumes"
+	if len(e.SuspectParts) > 0 {
+		suspects = strings.Join(e.SuspectParts, ", ")
+	}
+	return fmt.Sprintf("RAR file %q spans %d bytes across its volumes but declares %d; volumes look misordered (check NZB part numbering): %s",
+		e.File, e.ComputedSize, e.DeclaredSize, suspects)
+}
+
+// checkPartOrdering cross-checks each stored, unencrypted file's computed size
+// (the sum of its part payloads) against the size declared in its header. The
+// two only disagree when volumes were followed in the wrong order: a misplaced
+// final volume ends the file early, a misplaced middle one drags in another
+// file's data. Encrypted parts are skipped because their payload is padded.
+func checkPartOrdering(ctx context.Context, log *slog.Logger, aggregatedFiles []rardecode.ArchiveFileInfo, volumes []parser.ParsedFile) error {
+	referenced := make(map[string]struct{})
+	for _, af := range aggregatedFiles {
+		for _, part := range af.Parts {
+			referenced[filepath.Base(part.Path)] = struct{}{}
+		}
+	}
+
+	for _, af := range aggregatedFiles {
+		if !af.AllStored || af.AnyEncrypted || af.TotalUnpackedSize <= 0 {
+			continue
+		}
+
+		var computed int64
+		for _, part := range af.Parts {
+			if part.PackedSize > 0 {
+				computed += part.PackedSize
+			}
+		}
+		if computed == af.TotalUnpackedSize {
+			continue
+		}
+
+		mErr := &MisorderedVolumesError{
+			File:         af.Name,
+			DeclaredSize: af.TotalUnpackedSize,
+			ComputedSize: computed,
+			SuspectParts: suspectMisorderedParts(af, computed, referenced, volumes),
+		}
+		log.ErrorContext(ctx, "RAR file parts do not add up to its declared size; volumes are likely misordered",
+			"file", af.Name,
+			"declared_size", af.TotalUnpackedSize,
+			"computed_size", computed,
+			"suspect_parts", mErr.SuspectParts,
+		)
+		return errors.NewNonRetryableError("RAR volume order check failed", mErr)
+	}
+	return nil
+}
+
+// suspectMisorderedParts picks the volumes most likely out of place for a file
+// whose parts don't add up. Gaps or reversals in the part numbering are named
+// first. Otherwise, a short file points at its last followed part (which ended
+// the file early) plus the later volumes no file referenced, and a long file
+// points at the parts that run past its declared size.
+func suspectMisorderedParts(af rardecode.ArchiveFileInfo, computed int64, referenced map[string]struct{}, volumes []parser.ParsedFile) []string {
+	var suspects []string
+	add := func(name string) {
+		if !slices.Contains(suspects, name) {
+			suspects = append(suspects, name)
+		}
+	}
+
+	prev, havePrev := 0, false
+	for _, part := range af.Parts {
+		_, n, ok := rarVolumeNumber(part.Path)
+		if ok && havePrev && n != prev+1 {
+			add(filepath.Base(part.Path))
+		}
+		prev, havePrev = n, ok
+	}
+	if len(suspects) > 0 || len(af.Parts) == 0 {
+		return suspects
+	}
+
+	if computed < af.TotalUnpackedSize {
+		last := af.Parts[len(af.Parts)-1]
+		add(filepath.Base(last.Path))
+		_, lastNum, ok := rarVolumeNumber(last.Path)
+		if !ok {
+			return suspects
+		}
+		for _, v := range volumes {
+			name := filepath.Base(v.Filename)
+			if _, seen := referenced[name]; seen {
+				continue
+			}
+			if _, n, ok := rarVolumeNumber(name); ok && n > lastNum {
+				add(name)
+			}
+		}
+		return suspects
+	}
+
+	var cumulative int64
+	for _, part := range af.Parts {
+		cumulative += max(part.PackedSize, 0)
+		if cumulative > af.TotalUnpackedSize {
+			add(filepath.Base(part.Path))
+		}
+	}
+	return suspects
+}
+
 // contentCoverageMinPercent is the minimum fraction of a file's declared size that
 // its mapped segments must cover for the analysis to be trusted. RAR archives handled
 // here are always STORED (compressed files are rejected by checkForCompressedFiles), so
@@ -491,6 +625,14 @@ func (rh *rarProcessor) parseRarFilename(filename string) (base string, part int
 	return filename, 999999
 }
 
+// filenameEncoding returns the configured decoding for non-UTF-8 member names.
+func (rh *rarProcessor) filenameEncoding() config.FilenameEncoding {
+	if rh.configGetter == nil {
+		return ""
+	}
+	return rh.configGetter().Import.FilenameEncoding
+}
+
 // convertAggregatedFilesToRarContent converts rarlist.AggregatedFile results to RarContent
 // Note: AES credentials are extracted per-file from each file's first part, similar to
 // the reference implementation in github.com/javi11/rardecode/blob/main/examples/rarextract/main.go
@@ -509,8 +651,8 @@ func (rh *rarProcessor) convertAggregatedFilesToRarContent(ctx context.Context,
 	out := make([]Content, 0, len(aggregatedFiles))
 
 	for _, af := range aggregatedFiles {
-		// Normalize backslashes in path (Windows-style paths in RAR archives)
-		normalizedName := strings.ReplaceAll(af.Name, "\\", "/")
+		// Normalize encoding and backslashes (Windows-style paths in RAR archives)
+		normalizedName := archive.NormalizeMemberName(af.Name, rh.filenameEncoding())
 
 		// Extract AES credentials from this file's first part (if encrypted)
 		// Each file can have its own encryption credentials
@@ -631,6 +773,8 @@ func patchMissingSegment(segments []*metapb.SegmentData, expectedSize, coveredSi
 		StartOffset: lastSeg.StartOffset,
 		EndOffset:   lastSeg.StartOffset + shortfall - 1,
 		SegmentSize: lastSeg.SegmentSize,
+		UsableStart: lastSeg.UsableStart,
+		UsableEnd:   lastSeg.UsableEnd,
 	}
 
 	patchedSegments := append(segments, patchSeg)
@@ -800,6 +944,9 @@ func (rh *rarProcessor) processNestedRarContent(ctx context.Context, innerRarCon
 				rh.log.WarnContext(ctx, "Failed to map nested file (encrypted)", "file", af.Name, "error", err)
 				continue
 			}
+			if err := archive.ValidateNestedContent(content); err != nil {
+				return nil, errors.NewNonRetryableError("inconsistent nested archive sizes", err)
+			}
 			result = append(result, content)
 		} else {
 			content, err := rh.mapNestedFileFlat(ctx, af, normalizedName, innerVolumeIndex)
@@ -807,6 +954,9 @@ func (rh *rarProcessor) processNestedRarContent(ctx context.Context, innerRarCon
 				rh.log.WarnContext(ctx, "Failed to map nested file (flat)", "file", af.Name, "error", err)
 				continue
 			}
+			if err := archive.ValidateNestedContent(content); err != nil {
+				return nil, errors.NewNonRetryableError("inconsistent nested archive sizes", err)
+			}
 			result = append(result, content)
 		}
 	}
@@ -951,6 +1101,8 @@ func slicePartSegments(segments []*metapb.SegmentData, dataOffset int64, length
 				StartOffset: relStart,
 				EndOffset:   relEnd,
 				SegmentSize: seg.SegmentSize,
+				UsableStart: seg.UsableStart,
+				UsableEnd:   seg.UsableEnd,
 			})
 			covered += (relEnd - relStart + 1)
 			if overlapEnd == targetEnd { // done
@@ -69,6 +69,19 @@ func TestConvertAggregatedFilesToRarContentSinglePart(t *testing.T) {
 	require.Equal(t, int64(69), s.EndOffset)
 }
 
+func TestConvertAggregatedFilesToRarContentLegacyName(t *testing.T) {
+	rp := &rarProcessor{}
+	rarFiles := []parser.ParsedFile{{Filename: "vol1.rar", Segments: []*metapb.SegmentData{seg("s1", 100)}}}
+	// Windows-1252 name with a DOS separator, as written by older WinRAR.
+	ag := []rardecode.ArchiveFileInfo{{Name: "Movies\\Am\xe9lie.mkv", TotalUnpackedSize: 100, TotalPackedSize: 60, Parts: []rardecode.FilePartInfo{{Path: "vol1.rar", DataOffset: 10, PackedSize: 60}}}}
+
+	out, err := rp.convertAggregatedFilesToRarContent(context.Background(), ag, rarFiles)
+	require.NoError(t, err)
+	require.Len(t, out, 1)
+	require.Equal(t, "Movies/Amélie.mkv", out[0].InternalPath)
+	require.Equal(t, "Amélie.mkv", out[0].Filename)
+}
+
 func TestConvertAggregatedFilesToRarContentMultiPart(t *testing.T) {
 	rp := &rarProcessor{}
 	rarFiles := []parser.ParsedFile{
@@ -12,6 +12,7 @@ import (
 
 	concpool "github.com/sourcegraph/conc/pool"
 
+	"github.com/javi11/altmount/internal/config"
 	"github.com/javi11/altmount/internal/importer/archive"
 	"github.com/javi11/altmount/internal/importer/filesystem"
 	"github.com/javi11/altmount/internal/importer/parser"
@@ -98,10 +99,23 @@ type ProcessArchiveOptions struct {
 	ExpandBlurayIso        bool
 	FilterSamples          bool
 	RenameToNzbName        bool
+	// FlattenStructure places every member directly in VirtualDir instead of
+	// mirroring the archive's internal directories (Import.PreserveArchiveStructure
+	// set to false). Members that then share a name are suffixed _1, _2, ….
+	FlattenStructure bool
 	// SegmentIndex + StoreRef enable direct v3 store-backed metadata writes. When
 	// StoreRef is empty the aggregator falls back to v1 inline-segment metadata.
 	SegmentIndex map[string]int64
 	StoreRef     string
+	// VerifyReadback, when set, reads back each file's metadata through the
+	// streaming pipeline before it is written; files that fail are skipped.
+	VerifyReadback func(ctx context.Context, virtualPath string, meta *metapb.FileMetadata) error
+	// CheckLimits, when set, vets the analyzed archive's file count and size
+	// before anything is written; an error fails the import.
+	CheckLimits archive.LimitCheck
+	// OnPathCollision governs extracted files landing on a path held by a
+	// healthy file. Unset skips them, leaving the existing file in place.
+	OnPathCollision config.PathCollision
 }
 
 // ProcessArchive analyzes and processes 7zip archive files, creating metadata for all extracted files.
@@ -166,14 +180,20 @@ func ProcessArchive(ctx context.Context, opts ProcessArchiveOptions) error {
 		return err
 	}
 
+	if err := archive.CheckLimits(opts.CheckLimits, sevenZipContents, allowedFileExtensions, filterSamples); err != nil {
+		slog.WarnContext(ctx, "7zip archive rejected by import limits", "error", err)
+		return err
+	}
+
 	slog.InfoContext(ctx, "Starting 7zip archive processing",
 		"total_files", len(sevenZipContents))
 
 	// Determine if we should rename the file to match the NZB basename
-	// Only do this if there's exactly one media file in the archive
+	// Only do this if there's exactly one media file in the archive.
+	// Zero-byte files never count and keep their own names.
 	mediaFilesCount := 0
 	for _, content := range sevenZipContents {
-		if !content.IsDirectory && (utils.IsAllowedFile(content.InternalPath, content.Size, allowedFileExtensions, filterSamples) ||
+		if !content.IsDirectory && content.Size > 0 && (utils.IsAllowedFile(content.InternalPath, content.Size, allowedFileExtensions, filterSamples) ||
 			utils.IsAllowedFile(content.Filename, content.Size, allowedFileExtensions, filterSamples)) {
 			mediaFilesCount++
 		}
@@ -201,6 +221,11 @@ func ProcessArchive(ctx context.Context, opts ProcessArchiveOptions) error {
 
 	var filesToProcess []fileToProcess
 	preProcessedCount := 0 // healthy files already counted as processed
+	// reserver keeps versioned paths unique within the archive as well as
+	// against disk; claims are held for the whole import.
+	reserver := filesystem.NewPathReserver(metadataService)
+	// memberPaths keeps two members of this archive off the same virtual path.
+	memberPaths := make(map[string]struct{})
 
 	for _, sevenZipContent := range sevenZipContents {
 		if sevenZipContent.IsDirectory {
@@ -228,7 +253,7 @@ func ProcessArchive(ctx context.Context, opts ProcessArchiveOptions) error {
 				"original", sevenZipContent.Filename,
 				"renamed", baseFilename)
 			internalSubDir = "."
-		} else if shouldNormalizeName && (utils.IsAllowedFile(sevenZipContent.InternalPath, sevenZipContent.Size, allowedFileExtensions, filterSamples) ||
+		} else if shouldNormalizeName && sevenZipContent.Size > 0 && (utils.IsAllowedFile(sevenZipContent.InternalPath, sevenZipContent.Size, allowedFileExtensions, filterSamples) ||
 			utils.IsAllowedFile(sevenZipContent.Filename, sevenZipContent.Size, allowedFileExtensions, filterSamples)) {
 			baseFilename = normalizeArchiveReleaseFilename(nzbName, baseFilename)
 			slog.InfoContext(ctx, "Normalizing obfuscated filename in 7zip archive",
@@ -237,6 +262,10 @@ func ProcessArchive(ctx context.Context, opts ProcessArchiveOptions) error {
 			internalSubDir = "."
 		}
 
+		if opts.FlattenStructure {
+			internalSubDir = "."
+		}
+
 		var virtualFilePath string
 		if internalSubDir == "." || internalSubDir == "" {
 			virtualFilePath = filepath.Join(virtualDir, baseFilename)
@@ -248,15 +277,24 @@ func ProcessArchive(ctx context.Context, opts ProcessArchiveOptions) error {
 			virtualFilePath = filepath.Join(subDir, baseFilename)
 		}
 		virtualFilePath = strings.ReplaceAll(virtualFilePath, string(filepath.Separator), "/")
+		if unique := archive.UniqueMemberPath(virtualFilePath, memberPaths); unique != virtualFilePath {
+			slog.InfoContext(ctx, "Renaming 7zip member that shares a path with another member",
+				"original", virtualFilePath,
+				"renamed", unique)
+			virtualFilePath = unique
+		}
 
-		if existingMeta, err := metadataService.ReadFileMetadata(virtualFilePath); err == nil && existingMeta != nil {
-			if existingMeta.Status == metapb.FileStatus_FILE_STATUS_HEALTHY {
+		switch opts.OnPathCollision.Or(config.PathCollisionSkip) {
+		case config.PathCollisionSkip:
+			if _, ok := filesystem.ResolvePathCollision(virtualFilePath, config.PathCollisionSkip, metadataService); !ok {
 				slog.InfoContext(ctx, "Skipping re-import of healthy 7zip-extracted file",
 					"file", baseFilename,
 					"virtual_path", virtualFilePath)
 				preProcessedCount++
 				continue
 			}
+		case config.PathCollisionVersion:
+			virtualFilePath = reserver.Reserve(virtualFilePath)
 		}
 
 		isPreExtracted := false
@@ -286,6 +324,9 @@ func ProcessArchive(ctx context.Context, opts ProcessArchiveOptions) error {
 				slog.InfoContext(ctx, "Skipping validation for pre-extracted file (found in database)",
 					"file", item.baseFilename,
 					"size", item.content.Size)
+			} else if item.content.Size == 0 {
+				// Zero-byte files have no segments to validate.
+				slog.DebugContext(ctx, "Importing empty file from 7zip archive", "file", item.baseFilename)
 			} else {
 				if err := validateSegmentIntegrity(ctx, item.content); err != nil {
 					slog.ErrorContext(ctx, "Skipping SevenZip file due to segment integrity failure (missing segments in NZB)",
@@ -329,6 +370,15 @@ func ProcessArchive(ctx context.Context, opts ProcessArchiveOptions) error {
 
 			fileMeta := sevenZipProcessor.CreateFileMetadataFromSevenZipContent(item.content, nzbPath, releaseDate, item.content.NzbdavID)
 
+			if opts.VerifyReadback != nil {
+				if err := opts.VerifyReadback(ctx, item.virtualFilePath, fileMeta); err != nil {
+					slog.ErrorContext(ctx, "Skipping 7zip file due to readback verification failure",
+						"file", item.baseFilename,
+						"error", err)
+					return nil
+				}
+			}
+
 			metadataPath := metadataService.GetMetadataFilePath(item.virtualFilePath)
 			if _, err := os.Stat(metadataPath); err == nil {
 				_ = metadataService.DeleteFileMetadata(item.virtualFilePath)
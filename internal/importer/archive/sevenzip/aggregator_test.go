@@ -24,6 +24,10 @@ func (m *mockSevenZipProcessor) AnalyzeSevenZipContentFromNzb(_ context.Context,
 	return m.contents, nil
 }
 
+func (m *mockSevenZipProcessor) TestPassword(_ context.Context, _ []parser.ParsedFile, _ string) (bool, error) {
+	return true, nil
+}
+
 func (m *mockSevenZipProcessor) CreateFileMetadataFromSevenZipContent(content Content, _ string, _ int64, _ string) *metapb.FileMetadata {
 	return &metapb.FileMetadata{
 		FileSize:    content.Size,
@@ -49,6 +53,7 @@ func TestProcessArchivePreservesInternalFolderStructure(t *testing.T) {
 		virtualDir       string
 		nzbPath          string
 		renameToNzbName  bool
+		flatten          bool // FlattenStructure
 		extractedFiles   []parser.ExtractedFileInfo // override; nil = auto-build from contents
 		wantMetaPaths    []string
 		notWantMetaPaths []string
@@ -118,6 +123,33 @@ func TestProcessArchivePreservesInternalFolderStructure(t *testing.T) {
 			wantMetaPaths:    []string{"movies/MyMovie/Extras/featurette.mkv"},
 			notWantMetaPaths: []string{"movies/MyMovie/featurette.mkv"},
 		},
+		{
+			name:       "same name in two directories: both kept",
+			virtualDir: "movies/MyMovie",
+			nzbPath:    "movies/MyMovie.nzb",
+			contents: []Content{
+				{InternalPath: "a/file.mkv", Filename: "file.mkv", Size: 1000,
+					Segments: []*metapb.SegmentData{{Id: "seg1", StartOffset: 0, EndOffset: 999}}},
+				{InternalPath: "b/file.mkv", Filename: "file.mkv", Size: 1000,
+					Segments: []*metapb.SegmentData{{Id: "seg2", StartOffset: 0, EndOffset: 999}}},
+			},
+			wantMetaPaths:    []string{"movies/MyMovie/a/file.mkv", "movies/MyMovie/b/file.mkv"},
+			notWantMetaPaths: []string{"movies/MyMovie/file.mkv"},
+		},
+		{
+			name:       "same name flattened: suffixed instead of overwritten",
+			virtualDir: "movies/MyMovie",
+			nzbPath:    "movies/MyMovie.nzb",
+			flatten:    true,
+			contents: []Content{
+				{InternalPath: "a/file.mkv", Filename: "file.mkv", Size: 1000,
+					Segments: []*metapb.SegmentData{{Id: "seg1", StartOffset: 0, EndOffset: 999}}},
+				{InternalPath: "b/file.mkv", Filename: "file.mkv", Size: 1000,
+					Segments: []*metapb.SegmentData{{Id: "seg2", StartOffset: 0, EndOffset: 999}}},
+			},
+			wantMetaPaths:    []string{"movies/MyMovie/file.mkv", "movies/MyMovie/file_1.mkv"},
+			notWantMetaPaths: []string{"movies/MyMovie/a/file.mkv", "movies/MyMovie/b/file.mkv"},
+		},
 	}
 
 	for _, tt := range tests {
@@ -156,6 +188,7 @@ func TestProcessArchivePreservesInternalFolderStructure(t *testing.T) {
 				ExpandBlurayIso:         false,
 				FilterSamples:           false,
 				RenameToNzbName:         tt.renameToNzbName,
+				FlattenStructure:        tt.flatten,
 			})
 			require.NoError(t, err)
 
@@ -0,0 +1,653 @@
+package sevenzip
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spf13/afero"
+	"github.com/ulikunitz/xz/lzma"
+)
+
+// The sevenzip library only reports whether a file's folder is "compressed"
+// (any coder other than Copy/AES). To tell users *which* coder made an archive
+// unstreamable, this file carries a minimal 7z header reader that extracts the
+// coder chain of every folder, decoding an LZMA/LZMA2-packed header when needed.
+
+// 7z header property IDs.
+const (
+	idEnd                   = 0x00
+	idHeader                = 0x01
+	idArchiveProperties     = 0x02
+	idAdditionalStreamsInfo = 0x03
+	idMainStreamsInfo       = 0x04
+	idPackInfo              = 0x06
+	idUnpackInfo            = 0x07
+	idSubStreamsInfo        = 0x08
+	idSize                  = 0x09
+	idCRC                   = 0x0a
+	idFolder                = 0x0b
+	idCodersUnpackSize      = 0x0c
+	idEncodedHeader         = 0x17
+)
+
+const (
+	signatureHeaderSize = 32
+	// maxHeaderSize bounds how much header data is read or decoded; real
+	// headers are a few KB even for archives with thousands of files.
+	maxHeaderSize = 64 << 20
+)
+
+var (
+	sevenZipSignature = []byte{'7', 'z', 0xbc, 0xaf, 0x27, 0x1c}
+
+	errNotSevenZip      = errors.New("not a 7z archive")
+	errEncryptedHeader  = errors.New("7z header is encrypted")
+	errMalformedHeader  = errors.New("malformed 7z header")
+	errUnsupportedCoder = errors.New("unsupported 7z header coder")
+	errHeaderTooLarge   = errors.New("7z header exceeds size limit")
+)
+
+// Well-known 7z method IDs, keyed by their raw byte string.
+var sevenZipMethodNames = map[string]string{
+	"\x00":             "Copy",
+	"\x03":             "Delta filter",
+	"\x04":             "BCJ x86 filter",
+	"\x05":             "PPC filter",
+	"\x06":             "IA64 filter",
+	"\x07":             "ARM filter",
+	"\x08":             "ARM Thumb filter",
+	"\x09":             "SPARC filter",
+	"\x0a":             "ARM64 filter",
+	"\x21":             "LZMA2 compression",
+	"\x03\x01\x01":     "LZMA compression",
+	"\x03\x03\x01\x03": "BCJ x86 filter",
+	"\x03\x03\x01\x1b": "BCJ2 x86 filter",
+	"\x03\x03\x02\x05": "PPC filter",
+	"\x03\x03\x04\x01": "IA64 filter",
+	"\x03\x03\x05\x01": "ARM filter",
+	"\x03\x03\x07\x01": "ARM Thumb filter",
+	"\x03\x03\x08\x05": "SPARC filter",
+	"\x03\x04\x01":     "PPMd compression",
+	"\x04\x01\x08":     "Deflate compression",
+	"\x04\x01\x09":     "Deflate64 compression",
+	"\x04\x02\x02":     "BZip2 compression",
+	"\x04\xf7\x11\x01": "Zstandard compression",
+	"\x04\xf7\x11\x02": "Brotli compression",
+	"\x04\xf7\x11\x04": "LZ4 compression",
+	"\x06\xf1\x07\x01": "AES-256 encryption",
+}
+
+// methodName returns a human-readable name for a 7z coder method ID.
+func methodName(id []byte) string {
+	if name, ok := sevenZipMethodNames[string(id)]; ok {
+		return name
+	}
+	return "unknown coder 0x" + hex.EncodeToString(id)
+}
+
+// isStreamableMethod reports whether a coder leaves file data addressable by
+// offset: Copy (stored) data is, and AES is handled by the streaming decrypter.
+func isStreamableMethod(id []byte) bool {
+	return bytes.Equal(id, []byte{0x00}) || bytes.Equal(id, []byte{0x06, 0xf1, 0x07, 0x01})
+}
+
+// UnsupportedCodersError reports the coders that prevent a 7z archive's
+// files from being streamed directly.
+type UnsupportedCodersError struct {
+	Methods []string
+}
+
+func (e *UnsupportedCodersError) Error() string {
+	return fmt.Sprintf("7z archive uses unsupported coders: %s (only stored/Copy archives can be streamed)",
+		strings.Join(e.Methods, ", "))
+}
+
+// sevenZipCoder is one coder of a folder's decoding chain.
+type sevenZipCoder struct {
+	id         []byte
+	props      []byte
+	numIn      uint64
+	numOut     uint64
+	unpackSize uint64
+}
+
+type sevenZipFolder struct {
+	coders []sevenZipCoder
+}
+
+// unsupportedCoders returns the names of all non-streamable coders used by
+// the given folders, in first-seen order and without duplicates.
+func unsupportedCoders(folders []sevenZipFolder) []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, f := range folders {
+		for _, c := range f.coders {
+			if isStreamableMethod(c.id) {
+				continue
+			}
+			name := methodName(c.id)
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
+
+// checkArchiveCoders inspects the 7z archive in r and returns an
+// *UnsupportedCodersError naming every coder that is neither Copy nor AES, or
+// nil when all folders are streamable.
+func checkArchiveCoders(ctx context.Context, r io.ReaderAt, size int64) error {
+	folders, err := readArchiveFolders(ctx, r, size)
+	if err != nil {
+		return err
+	}
+	if names := unsupportedCoders(folders); len(names) > 0 {
+		return &UnsupportedCodersError{Methods: names}
+	}
+	return nil
+}
+
+// readArchiveFolders parses the 7z signature header and the (possibly
+// encoded) header it points to, returning the main stream's folders.
+func readArchiveFolders(ctx context.Context, r io.ReaderAt, size int64) ([]sevenZipFolder, error) {
+	sig := make([]byte, signatureHeaderSize)
+	if _, err := r.ReadAt(sig, 0); err != nil {
+		return nil, fmt.Errorf("failed to read 7z signature header: %w", err)
+	}
+	if !bytes.Equal(sig[:6], sevenZipSignature) {
+		return nil, errNotSevenZip
+	}
+
+	nextOffset := binary.LittleEndian.Uint64(sig[12:20])
+	nextSize := binary.LittleEndian.Uint64(sig[20:28])
+	if nextSize > maxHeaderSize {
+		return nil, errHeaderTooLarge
+	}
+	if nextSize == 0 || nextOffset > uint64(size) || signatureHeaderSize+nextOffset+nextSize > uint64(size) {
+		return nil, errMalformedHeader
+	}
+
+	header := make([]byte, nextSize)
+	if _, err := r.ReadAt(header, int64(signatureHeaderSize+nextOffset)); err != nil {
+		return nil, fmt.Errorf("failed to read 7z header: %w", err)
+	}
+
+	// An encoded header may (in theory) decode to another encoded header.
+	for range 4 {
+		if len(header) == 0 {
+			return nil, errMalformedHeader
+		}
+		br := bytes.NewReader(header[1:])
+		switch header[0] {
+		case idHeader:
+			return parseHeader(br)
+		case idEncodedHeader:
+			decoded, err := decodeHeader(ctx, r, br)
+			if err != nil {
+				return nil, err
+			}
+			header = decoded
+		default:
+			return nil, errMalformedHeader
+		}
+	}
+	return nil, errMalformedHeader
+}
+
+// decodeHeader unpacks an encoded header, described by the streams info in br.
+func decodeHeader(ctx context.Context, r io.ReaderAt, br *bytes.Reader) ([]byte, error) {
+	packPos, packSizes, folders, err := parseStreamsInfo(br)
+	if err != nil {
+		return nil, err
+	}
+	if len(folders) != 1 || len(packSizes) == 0 {
+		return nil, errMalformedHeader
+	}
+	for _, c := range folders[0].coders {
+		if bytes.Equal(c.id, []byte{0x06, 0xf1, 0x07, 0x01}) {
+			return nil, errEncryptedHeader
+		}
+	}
+	if len(folders[0].coders) != 1 {
+		return nil, errUnsupportedCoder
+	}
+	coder := folders[0].coders[0]
+	if packSizes[0] > maxHeaderSize || coder.unpackSize > maxHeaderSize {
+		return nil, errHeaderTooLarge
+	}
+
+	packed := make([]byte, packSizes[0])
+	if _, err := r.ReadAt(packed, int64(signatureHeaderSize+packPos)); err != nil {
+		return nil, fmt.Errorf("failed to read packed 7z header: %w", err)
+	}
+
+	var dec io.Reader
+	switch {
+	case bytes.Equal(coder.id, []byte{0x00}):
+		dec = bytes.NewReader(packed)
+	case bytes.Equal(coder.id, []byte{0x03, 0x01, 0x01}):
+		// Rebuild the classic .lzma header: 5 property bytes + uncompressed size.
+		hdr := make([]byte, 0, len(coder.props)+8)
+		hdr = append(hdr, coder.props...)
+		hdr = binary.LittleEndian.AppendUint64(hdr, coder.unpackSize)
+		lr, err := lzma.NewReader(io.MultiReader(bytes.NewReader(hdr), bytes.NewReader(packed)))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode 7z header: %w", err)
+		}
+		dec = lr
+	case bytes.Equal(coder.id, []byte{0x21}):
+		if len(coder.props) != 1 || coder.props[0] > 40 {
+			return nil, errMalformedHeader
+		}
+		dictCap := uint64(0xffffffff)
+		if p := coder.props[0]; p < 40 {
+			dictCap = (2 | uint64(p&1)) << (p/2 + 11)
+		}
+		lr, err := lzma.Reader2Config{DictCap: int(dictCap)}.NewReader2(bytes.NewReader(packed))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode 7z header: %w", err)
+		}
+		dec = lr
+	default:
+		return nil, fmt.Errorf("%w: %s", errUnsupportedCoder, methodName(coder.id))
+	}
+
+	out, err := readDecodedHeader(ctx, dec, coder.unpackSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode 7z header: %w", err)
+	}
+	return out, nil
+}
+
+// readDecodedHeader reads exactly size bytes from dec, stopping as soon as
+// ctx is done. The buffer grows with the data actually decoded, so a header
+// that claims a large size but decodes to less never costs the full amount.
+func readDecodedHeader(ctx context.Context, dec io.Reader, size uint64) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.Grow(int(min(size, 64<<10)))
+	n, err := buf.ReadFrom(io.LimitReader(&ctxReader{ctx: ctx, r: dec}, int64(size)))
+	if err != nil {
+		return nil, err
+	}
+	if uint64(n) != size {
+		return nil, io.ErrUnexpectedEOF
+	}
+	return buf.Bytes(), nil
+}
+
+// ctxReader fails reads once ctx is done, so decompression can be cancelled
+// between chunks.
+type ctxReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (c *ctxReader) Read(p []byte) (int, error) {
+	if err := c.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return c.r.Read(p)
+}
+
+// parseHeader walks a plain header up to the main streams info.
+func parseHeader(br *bytes.Reader) ([]sevenZipFolder, error) {
+	for {
+		id, err := br.ReadByte()
+		if err != nil {
+			return nil, errMalformedHeader
+		}
+		switch id {
+		case idArchiveProperties:
+			if err := skipArchiveProperties(br); err != nil {
+				return nil, err
+			}
+		case idAdditionalStreamsInfo:
+			if _, _, _, err := parseStreamsInfo(br); err != nil {
+				return nil, err
+			}
+		case idMainStreamsInfo:
+			_, _, folders, err := parseStreamsInfo(br)
+			return folders, err
+		default:
+			// No main streams (e.g. an archive of empty files).
+			return nil, nil
+		}
+	}
+}
+
+func skipArchiveProperties(br *bytes.Reader) error {
+	for {
+		t, err := br.ReadByte()
+		if err != nil {
+			return errMalformedHeader
+		}
+		if t == idEnd {
+			return nil
+		}
+		n, err := readNumber(br)
+		if err != nil {
+			return err
+		}
+		if _, err := br.Seek(int64(n), io.SeekCurrent); err != nil {
+			return errMalformedHeader
+		}
+	}
+}
+
+// parseStreamsInfo parses pack and unpack info. Sub-streams info is not
+// needed to learn the coders, so parsing stops there.
+func parseStreamsInfo(br *bytes.Reader) (packPos uint64, packSizes []uint64, folders []sevenZipFolder, err error) {
+	for {
+		id, err := br.ReadByte()
+		if err != nil {
+			return 0, nil, nil, errMalformedHeader
+		}
+		switch id {
+		case idEnd, idSubStreamsInfo:
+			return packPos, packSizes, folders, nil
+		case idPackInfo:
+			if packPos, packSizes, err = parsePackInfo(br); err != nil {
+				return 0, nil, nil, err
+			}
+		case idUnpackInfo:
+			if folders, err = parseUnpackInfo(br); err != nil {
+				return 0, nil, nil, err
+			}
+		default:
+			return 0, nil, nil, errMalformedHeader
+		}
+	}
+}
+
+func parsePackInfo(br *bytes.Reader) (uint64, []uint64, error) {
+	packPos, err := readNumber(br)
+	if err != nil {
+		return 0, nil, err
+	}
+	numPackStreams, err := readNumber(br)
+	if err != nil {
+		return 0, nil, err
+	}
+	if numPackStreams > uint64(br.Len()) {
+		return 0, nil, errMalformedHeader
+	}
+
+	var sizes []uint64
+	for {
+		id, err := br.ReadByte()
+		if err != nil {
+			return 0, nil, errMalformedHeader
+		}
+		switch id {
+		case idEnd:
+			return packPos, sizes, nil
+		case idSize:
+			sizes = make([]uint64, numPackStreams)
+			for i := range sizes {
+				if sizes[i], err = readNumber(br); err != nil {
+					return 0, nil, err
+				}
+			}
+		case idCRC:
+			if err := skipDigests(br, numPackStreams); err != nil {
+				return 0, nil, err
+			}
+		default:
+			return 0, nil, errMalformedHeader
+		}
+	}
+}
+
+func parseUnpackInfo(br *bytes.Reader) ([]sevenZipFolder, error) {
+	if id, err := br.ReadByte(); err != nil || id != idFolder {
+		return nil, errMalformedHeader
+	}
+	numFolders, err := readNumber(br)
+	if err != nil {
+		return nil, err
+	}
+	if numFolders > uint64(br.Len()) {
+		return nil, errMalformedHeader
+	}
+	if external, err := br.ReadByte(); err != nil || external != 0 {
+		return nil, errMalformedHeader
+	}
+
+	folders := make([]sevenZipFolder, numFolders)
+	for i := range folders {
+		if folders[i], err = parseFolder(br); err != nil {
+			return nil, err
+		}
+	}
+
+	if id, err := br.ReadByte(); err != nil || id != idCodersUnpackSize {
+		return nil, errMalformedHeader
+	}
+	for i := range folders {
+		for j := range folders[i].coders {
+			c := &folders[i].coders[j]
+			// Coders with several outputs (none in practice) report one size
+			// per output; the first one is kept.
+			for k := range c.numOut {
+				size, err := readNumber(br)
+				if err != nil {
+					return nil, err
+				}
+				if k == 0 {
+					c.unpackSize = size
+				}
+			}
+		}
+	}
+
+	for {
+		id, err := br.ReadByte()
+		if err != nil {
+			return nil, errMalformedHeader
+		}
+		switch id {
+		case idEnd:
+			return folders, nil
+		case idCRC:
+			if err := skipDigests(br, numFolders); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, errMalformedHeader
+		}
+	}
+}
+
+func parseFolder(br *bytes.Reader) (sevenZipFolder, error) {
+	numCoders, err := readNumber(br)
+	if err != nil {
+		return sevenZipFolder{}, err
+	}
+	if numCoders == 0 || numCoders > 64 {
+		return sevenZipFolder{}, errMalformedHeader
+	}
+
+	f := sevenZipFolder{coders: make([]sevenZipCoder, numCoders)}
+	var totalIn, totalOut uint64
+	for i := range f.coders {
+		flags, err := br.ReadByte()
+		if err != nil {
+			return sevenZipFolder{}, errMalformedHeader
+		}
+		if flags&0x80 != 0 {
+			// Alternative methods were never used by any 7-Zip release.
+			return sevenZipFolder{}, errMalformedHeader
+		}
+		c := sevenZipCoder{id: make([]byte, flags&0x0f), numIn: 1, numOut: 1}
+		if _, err := io.ReadFull(br, c.id); err != nil {
+			return sevenZipFolder{}, errMalformedHeader
+		}
+		if flags&0x10 != 0 {
+			if c.numIn, err = readNumber(br); err != nil {
+				return sevenZipFolder{}, err
+			}
+			if c.numOut, err = readNumber(br); err != nil {
+				return sevenZipFolder{}, err
+			}
+			if c.numIn > 64 || c.numOut > 64 {
+				return sevenZipFolder{}, errMalformedHeader
+			}
+		}
+		if flags&0x20 != 0 {
+			n, err := readNumber(br)
+			if err != nil {
+				return sevenZipFolder{}, err
+			}
+			if n > uint64(br.Len()) {
+				return sevenZipFolder{}, errMalformedHeader
+			}
+			c.props = make([]byte, n)
+			if _, err := io.ReadFull(br, c.props); err != nil {
+				return sevenZipFolder{}, errMalformedHeader
+			}
+		}
+		totalIn += c.numIn
+		totalOut += c.numOut
+		f.coders[i] = c
+	}
+
+	// Bind pairs (in index, out index) link coder outputs to inputs.
+	numBindPairs := totalOut - 1
+	for range numBindPairs * 2 {
+		if _, err := readNumber(br); err != nil {
+			return sevenZipFolder{}, err
+		}
+	}
+	if totalIn < numBindPairs {
+		return sevenZipFolder{}, errMalformedHeader
+	}
+	if numPacked := totalIn - numBindPairs; numPacked > 1 {
+		for range numPacked {
+			if _, err := readNumber(br); err != nil {
+				return sevenZipFolder{}, err
+			}
+		}
+	}
+	return f, nil
+}
+
+// skipDigests skips a CRC digest list for n items.
+func skipDigests(br *bytes.Reader, n uint64) error {
+	allDefined, err := br.ReadByte()
+	if err != nil {
+		return errMalformedHeader
+	}
+	defined := n
+	if allDefined == 0 {
+		defined = 0
+		bits := make([]byte, (n+7)/8)
+		if _, err := io.ReadFull(br, bits); err != nil {
+			return errMalformedHeader
+		}
+		for i := range n {
+			if bits[i/8]&(0x80>>(i%8)) != 0 {
+				defined++
+			}
+		}
+	}
+	if _, err := br.Seek(int64(defined*4), io.SeekCurrent); err != nil {
+		return errMalformedHeader
+	}
+	return nil
+}
+
+// readNumber reads a 7z variable-length number: the count of leading one
+// bits in the first byte is the number of extra little-endian bytes.
+func readNumber(br io.ByteReader) (uint64, error) {
+	first, err := br.ReadByte()
+	if err != nil {
+		return 0, errMalformedHeader
+	}
+	var value uint64
+	mask := byte(0x80)
+	for i := range 8 {
+		if first&mask == 0 {
+			return value | uint64(first&(mask-1))<<(8*i), nil
+		}
+		b, err := br.ReadByte()
+		if err != nil {
+			return 0, errMalformedHeader
+		}
+		value |= uint64(b) << (8 * i)
+		mask >>= 1
+	}
+	return value, nil
+}
+
+// volumeReaderAt presents the volumes of a (possibly split) 7z archive as one
+// contiguous io.ReaderAt, matching the offsets the 7z header refers to.
+type volumeReaderAt struct {
+	files   []afero.File
+	offsets []int64 // start offset of each volume
+	size    int64
+}
+
+func openVolumes(fs afero.Fs, names []string) (*volumeReaderAt, error) {
+	v := &volumeReaderAt{}
+	for _, name := range names {
+		f, err := fs.Open(name)
+		if err != nil {
+			v.Close()
+			return nil, fmt.Errorf("failed to open 7z volume %q: %w", name, err)
+		}
+		st, err := f.Stat()
+		if err != nil {
+			_ = f.Close()
+			v.Close()
+			return nil, fmt.Errorf("failed to stat 7z volume %q: %w", name, err)
+		}
+		v.files = append(v.files, f)
+		v.offsets = append(v.offsets, v.size)
+		v.size += st.Size()
+	}
+	return v, nil
+}
+
+func (v *volumeReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	n := 0
+	for i, f := range v.files {
+		if n == len(p) {
+			break
+		}
+		end := v.size
+		if i+1 < len(v.offsets) {
+			end = v.offsets[i+1]
+		}
+		pos := off + int64(n)
+		if pos < v.offsets[i] || pos >= end {
+			continue
+		}
+		want := min(int64(len(p)-n), end-pos)
+		m, err := f.ReadAt(p[n:n+int(want)], pos-v.offsets[i])
+		n += m
+		if int64(m) < want {
+			if err == nil || errors.Is(err, io.EOF) {
+				err = io.ErrUnexpectedEOF
+			}
+			return n, err
+		}
+	}
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+func (v *volumeReaderAt) Close() {
+	for _, f := range v.files {
+		_ = f.Close()
+	}
+}
@@ -0,0 +1,222 @@
+package sevenzip
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ulikunitz/xz/lzma"
+)
+
+// Fixtures in testdata/ come from the sevenzip library's test corpus.
+
+func checkFixture(t *testing.T, name string) error {
+	t.Helper()
+	data, err := os.ReadFile("testdata/" + name)
+	require.NoError(t, err)
+	return checkArchiveCoders(context.Background(), bytes.NewReader(data), int64(len(data)))
+}
+
+func TestCheckArchiveCoders_NamesFilters(t *testing.T) {
+	tests := []struct {
+		file string
+		want []string
+	}{
+		{"bcj.7z", []string{"LZMA2 compression", "BCJ x86 filter"}},
+		{"delta.7z", []string{"Delta filter"}},
+		{"lzma2.7z", []string{"LZMA2 compression"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			err := checkFixture(t, tt.file)
+			var codecErr *UnsupportedCodersError
+			require.True(t, errors.As(err, &codecErr), "got %v", err)
+			assert.Equal(t, tt.want, codecErr.Methods)
+			for _, name := range tt.want {
+				assert.Contains(t, err.Error(), name)
+			}
+		})
+	}
+}
+
+func TestCheckArchiveCoders_StreamableArchives(t *testing.T) {
+	// Stored archive, and stored + AES (encrypted but still streamable).
+	assert.NoError(t, checkFixture(t, "t0.7z"))
+	assert.NoError(t, checkFixture(t, "t5.7z"))
+}
+
+func TestCheckArchiveCoders_NotSevenZip(t *testing.T) {
+	data := bytes.Repeat([]byte{0x42}, 64)
+	assert.ErrorIs(t, checkArchiveCoders(context.Background(), bytes.NewReader(data), int64(len(data))), errNotSevenZip)
+}
+
+func TestMethodName(t *testing.T) {
+	assert.Equal(t, "BCJ x86 filter", methodName([]byte{0x03, 0x03, 0x01, 0x03}))
+	assert.Equal(t, "BCJ2 x86 filter", methodName([]byte{0x03, 0x03, 0x01, 0x1b}))
+	assert.Equal(t, "ARM64 filter", methodName([]byte{0x0a}))
+	assert.Equal(t, "unknown coder 0x7f01", methodName([]byte{0x7f, 0x01}))
+}
+
+func TestReadNumber(t *testing.T) {
+	tests := []struct {
+		in   []byte
+		want uint64
+	}{
+		{[]byte{0x00}, 0},
+		{[]byte{0x7f}, 0x7f},
+		{[]byte{0x80, 0x80}, 0x80},
+		{[]byte{0xbf, 0xff}, 0x3fff},
+		{[]byte{0xc0, 0x00, 0x40}, 0x4000},
+		{[]byte{0xff, 1, 2, 3, 4, 5, 6, 7, 8}, 0x0807060504030201},
+	}
+	for _, tt := range tests {
+		got, err := readNumber(bytes.NewReader(tt.in))
+		require.NoError(t, err)
+		assert.Equal(t, tt.want, got, "% x", tt.in)
+	}
+
+	_, err := readNumber(bytes.NewReader([]byte{0xc0, 0x00}))
+	assert.ErrorIs(t, err, errMalformedHeader)
+}
+
+func TestVolumeReaderAt_SplitArchive(t *testing.T) {
+	data, err := os.ReadFile("testdata/bcj.7z")
+	require.NoError(t, err)
+
+	// Split into uneven volumes so the header spans a boundary.
+	fs := afero.NewMemMapFs()
+	cuts := []int{0, 700, 1500, len(data)}
+	var names []string
+	for i := 1; i < len(cuts); i++ {
+		name := "movie.7z.00" + string(rune('0'+i))
+		require.NoError(t, afero.WriteFile(fs, name, data[cuts[i-1]:cuts[i]], 0644))
+		names = append(names, name)
+	}
+
+	vr, err := openVolumes(fs, names)
+	require.NoError(t, err)
+	defer vr.Close()
+	assert.Equal(t, int64(len(data)), vr.size)
+
+	whole, err := io.ReadAll(io.NewSectionReader(vr, 0, vr.size))
+	require.NoError(t, err)
+	assert.Equal(t, data, whole)
+
+	var codecErr *UnsupportedCodersError
+	require.True(t, errors.As(checkArchiveCoders(context.Background(), vr, vr.size), &codecErr))
+	assert.Contains(t, codecErr.Methods, "BCJ x86 filter")
+}
+
+// appendNumber appends n in the 7z variable-length number encoding.
+func appendNumber(b []byte, n uint64) []byte {
+	if n < 0x80 {
+		return append(b, byte(n))
+	}
+	return binary.LittleEndian.AppendUint64(append(b, 0xff), n)
+}
+
+// lzma2Header compresses a plain header whose archive properties carry
+// fillerSize bytes of padding, with the LZMA2 dictionary 7-Zip would record
+// as property byte 16 (1 MiB).
+func lzma2Header(t *testing.T, fillerSize int) (plain, packed []byte) {
+	t.Helper()
+	plain = []byte{idHeader, idArchiveProperties, 0x19}
+	plain = appendNumber(plain, uint64(fillerSize))
+	plain = append(plain, make([]byte, fillerSize)...)
+	plain = append(plain, idEnd, idEnd)
+
+	var buf bytes.Buffer
+	w, err := lzma.Writer2Config{DictCap: 1 << 20}.NewWriter2(&buf)
+	require.NoError(t, err)
+	_, err = w.Write(plain)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	return plain, buf.Bytes()
+}
+
+// encodedHeaderArchive builds a 7z archive whose header is LZMA2-packed and
+// declares unpackSize decoded bytes.
+func encodedHeaderArchive(packed []byte, unpackSize uint64) []byte {
+	enc := []byte{idEncodedHeader, idPackInfo}
+	enc = appendNumber(enc, 0) // pack position
+	enc = appendNumber(enc, 1) // one pack stream
+	enc = append(enc, idSize)
+	enc = appendNumber(enc, uint64(len(packed)))
+	enc = append(enc, idEnd, idUnpackInfo, idFolder)
+	enc = appendNumber(enc, 1)             // one folder
+	enc = append(enc, 0)                   // not external
+	enc = appendNumber(enc, 1)             // one coder
+	enc = append(enc, 0x20|1, 0x21, 1, 16) // LZMA2 with one property byte
+	enc = append(enc, idCodersUnpackSize)
+	enc = appendNumber(enc, unpackSize)
+	enc = append(enc, idEnd, idEnd)
+
+	sig := make([]byte, signatureHeaderSize)
+	copy(sig, sevenZipSignature)
+	binary.LittleEndian.PutUint64(sig[12:20], uint64(len(packed)))
+	binary.LittleEndian.PutUint64(sig[20:28], uint64(len(enc)))
+
+	archive := append(sig, packed...)
+	return append(archive, enc...)
+}
+
+func TestCheckArchiveCoders_LargeEncodedHeader(t *testing.T) {
+	plain, packed := lzma2Header(t, 8<<20)
+	archive := encodedHeaderArchive(packed, uint64(len(plain)))
+
+	// No main streams: the header decodes and parses without coders to report.
+	assert.NoError(t, checkArchiveCoders(context.Background(), bytes.NewReader(archive), int64(len(archive))))
+}
+
+func TestCheckArchiveCoders_OversizedEncodedHeader(t *testing.T) {
+	_, packed := lzma2Header(t, 1024)
+	archive := encodedHeaderArchive(packed, maxHeaderSize+1)
+
+	err := checkArchiveCoders(context.Background(), bytes.NewReader(archive), int64(len(archive)))
+	assert.ErrorIs(t, err, errHeaderTooLarge)
+}
+
+func TestCheckArchiveCoders_TruncatedEncodedHeader(t *testing.T) {
+	plain, packed := lzma2Header(t, 1024)
+	archive := encodedHeaderArchive(packed, uint64(len(plain))+100)
+
+	err := checkArchiveCoders(context.Background(), bytes.NewReader(archive), int64(len(archive)))
+	assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+}
+
+// cancelAfterReader cancels its context once limit bytes have been read.
+type cancelAfterReader struct {
+	r      io.Reader
+	limit  int
+	read   int
+	cancel context.CancelFunc
+}
+
+func (c *cancelAfterReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	if c.read += n; c.read >= c.limit {
+		c.cancel()
+	}
+	return n, err
+}
+
+func TestReadDecodedHeader_CancelledMidDecompress(t *testing.T) {
+	plain, packed := lzma2Header(t, 8<<20)
+	dec, err := lzma.Reader2Config{DictCap: 1 << 20}.NewReader2(bytes.NewReader(packed))
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	src := &cancelAfterReader{r: dec, limit: 1 << 20, cancel: cancel}
+
+	_, err = readDecodedHeader(ctx, src, uint64(len(plain)))
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Less(t, src.read, len(plain), "decoding stopped before the end of the header")
+}
@@ -0,0 +1,168 @@
+package sevenzip
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"time"
+
+	"github.com/javi11/altmount/internal/errors"
+	"github.com/javi11/altmount/internal/importer/filesystem"
+	"github.com/javi11/altmount/internal/importer/parser"
+	"github.com/javi11/sevenzip"
+	"github.com/spf13/afero"
+)
+
+// passwordProbeBudget caps how many bytes of one entry TestPassword decrypts
+// when the archive header is not encrypted. 7z's AES coder has no password
+// check value, so the only proof is an entry's CRC.
+const passwordProbeBudget = 4 << 20
+
+// ErrPasswordNotVerifiable is returned by TestPassword when the archive has
+// no encrypted header and no encrypted entry that starts its stream and is
+// small enough to check against its CRC within passwordProbeBudget.
+var ErrPasswordNotVerifiable = stderrors.New("7zip password cannot be verified without reading the whole archive")
+
+// TestPassword reports whether password decrypts the archive, reading only
+// its header and at most one small entry rather than analyzing the whole
+// archive. An archive without encryption accepts any password.
+func (sz *sevenZipProcessor) TestPassword(ctx context.Context, sevenZipFiles []parser.ParsedFile, password string) (bool, error) {
+	if sz.poolManager == nil {
+		return false, errors.NewNonRetryableError("no pool manager available", nil)
+	}
+
+	cfg := sz.configGetter()
+	readTimeout := time.Duration(cfg.Import.ReadTimeoutSeconds) * time.Second
+	if readTimeout == 0 {
+		readTimeout = 5 * time.Minute
+	}
+
+	sortedFiles, err := renameSevenZipFilesAndSort(sevenZipFiles)
+	if err != nil {
+		return false, err
+	}
+	fileNames := make([]string, len(sortedFiles))
+	for i, file := range sortedFiles {
+		fileNames[i] = file.Filename
+	}
+	mainSevenZipFile, err := sz.getFirstSevenZipPart(fileNames)
+	if err != nil {
+		return false, err
+	}
+
+	ufs := filesystem.NewUsenetFileSystem(ctx, sz.poolManager, sortedFiles, cfg.Import.MaxDownloadPrefetch, nil, readTimeout)
+	ok, err := testSevenZipPassword(ctx, filesystem.NewAferoAdapter(ufs), mainSevenZipFile, password)
+	sz.log.DebugContext(ctx, "Tested 7zip archive password",
+		"archive", mainSevenZipFile,
+		"ok", ok,
+		"error", err)
+	return ok, err
+}
+
+// testSevenZipPassword checks password against the archive name in fs. An
+// encrypted header settles it on open; otherwise one small encrypted entry
+// chosen by passwordProbe is decrypted and checked against its CRC.
+func testSevenZipPassword(ctx context.Context, fs afero.Fs, name, password string) (bool, error) {
+	headerEncrypted := false
+	if plain, err := sevenzip.OpenReader(name, fs); err == nil {
+		_ = plain.Close()
+	} else if isEncryptedReadError(err) {
+		headerEncrypted = true
+	} else {
+		return false, fmt.Errorf("failed to open 7zip archive %q: %w", name, err)
+	}
+
+	reader, err := sevenzip.OpenReaderWithPassword(name, password, fs)
+	if err != nil {
+		if isEncryptedReadError(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to open 7zip archive %q: %w", name, err)
+	}
+	defer reader.Close()
+	if headerEncrypted {
+		return true, nil
+	}
+
+	fileInfos, err := reader.ListFilesWithOffsets()
+	if err != nil {
+		return false, fmt.Errorf("failed to list files in 7zip archive %q: %w", name, err)
+	}
+	encrypted := make(map[string]bool, len(fileInfos))
+	for _, fi := range fileInfos {
+		if fi.Encrypted {
+			encrypted[fi.Name] = true
+		}
+	}
+	if len(encrypted) == 0 {
+		return true, nil
+	}
+
+	probe := passwordProbe(reader.File, encrypted)
+	if probe == nil {
+		return false, ErrPasswordNotVerifiable
+	}
+
+	rc, err := probe.Open()
+	if err != nil {
+		if isEncryptedReadError(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to open %q in 7zip archive %q: %w", probe.Name, name, err)
+	}
+	defer rc.Close()
+
+	// A compressed entry usually fails to decode within the budget when the
+	// key is wrong; a stored one has to be read to the end for its CRC.
+	h := crc32.NewIEEE()
+	n, err := io.Copy(h, io.LimitReader(&ctxReader{ctx: ctx, r: rc}, passwordProbeBudget))
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return false, ctxErr
+		}
+		if isEncryptedReadError(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to read %q in 7zip archive %q: %w", probe.Name, name, err)
+	}
+	if uint64(n) < probe.UncompressedSize {
+		return false, ErrPasswordNotVerifiable
+	}
+	return h.Sum32() == probe.CRC32, nil
+}
+
+// passwordProbe picks the entry testSevenZipPassword decrypts: the smallest
+// encrypted one with a recorded CRC that fits in passwordProbeBudget and is
+// the first entry of its stream. A later entry of a solid stream is only
+// reached by decoding everything before it, so it is never picked. Returns
+// nil when no entry qualifies.
+func passwordProbe(files []*sevenzip.File, encrypted map[string]bool) *sevenzip.File {
+	seenStream := make(map[int]bool)
+	var probe *sevenzip.File
+	for _, f := range files {
+		if f.UncompressedSize == 0 {
+			// Directories and empty files have no stream.
+			continue
+		}
+		first := !seenStream[f.Stream]
+		seenStream[f.Stream] = true
+
+		// A CRC of 0 is how the header says "none recorded".
+		if !first || !encrypted[f.Name] || f.CRC32 == 0 || f.UncompressedSize > passwordProbeBudget {
+			continue
+		}
+		if probe == nil || f.UncompressedSize < probe.UncompressedSize {
+			probe = f
+		}
+	}
+	return probe
+}
+
+// isEncryptedReadError reports whether err is a sevenzip read error that the
+// library attributes to encryption, i.e. a wrong or missing password.
+func isEncryptedReadError(err error) bool {
+	var readErr *sevenzip.ReadError
+	return stderrors.As(err, &readErr) && readErr.Encrypted
+}
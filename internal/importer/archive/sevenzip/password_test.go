@@ -0,0 +1,91 @@
+package sevenzip
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/javi11/sevenzip"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTestSevenZipPassword(t *testing.T) {
+	fs := afero.NewBasePathFs(afero.NewOsFs(), "testdata")
+	tests := []struct {
+		name, file string
+	}{
+		{"encrypted header", "t2.7z"},
+		{"plain header, compressed entries", "t4.7z"},
+		{"plain header, stored entries", "t5.7z"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+
+			ok, err := testSevenZipPassword(ctx, fs, tt.file, "password")
+			require.NoError(t, err)
+			assert.True(t, ok, "correct password")
+
+			ok, err = testSevenZipPassword(ctx, fs, tt.file, "notpassword")
+			require.NoError(t, err)
+			assert.False(t, ok, "wrong password")
+		})
+	}
+}
+
+func TestTestSevenZipPassword_Unencrypted(t *testing.T) {
+	fs := afero.NewBasePathFs(afero.NewOsFs(), "testdata")
+	ok, err := testSevenZipPassword(context.Background(), fs, "lzma2.7z", "anything")
+	require.NoError(t, err)
+	assert.True(t, ok)
+}
+
+func TestTestSevenZipPassword_NotAnArchive(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "junk.7z", []byte("not a 7zip archive"), 0644))
+	_, err := testSevenZipPassword(context.Background(), fs, "junk.7z", "password")
+	assert.Error(t, err)
+}
+
+func TestPasswordProbe(t *testing.T) {
+	entry := func(name string, stream int, size uint64) *sevenzip.File {
+		return &sevenzip.File{FileHeader: sevenzip.FileHeader{
+			Name: name, Stream: stream, UncompressedSize: size, CRC32: 0xdeadbeef,
+		}}
+	}
+	all := func(files ...*sevenzip.File) map[string]bool {
+		m := make(map[string]bool, len(files))
+		for _, f := range files {
+			m[f.Name] = true
+		}
+		return m
+	}
+
+	t.Run("smallest first entry of a stream", func(t *testing.T) {
+		files := []*sevenzip.File{entry("a.mkv", 0, 3000), entry("b.nfo", 1, 200), entry("c.srt", 2, 900)}
+		assert.Equal(t, "b.nfo", passwordProbe(files, all(files...)).Name)
+	})
+
+	t.Run("solid stream only offers its first entry", func(t *testing.T) {
+		files := []*sevenzip.File{entry("a.mkv", 0, 3000), entry("b.nfo", 0, 200), entry("c.srt", 0, 900)}
+		assert.Equal(t, "a.mkv", passwordProbe(files, all(files...)).Name)
+	})
+
+	t.Run("entries over the budget are skipped", func(t *testing.T) {
+		files := []*sevenzip.File{entry("a.mkv", 0, passwordProbeBudget+1), entry("b.nfo", 0, 200)}
+		assert.Nil(t, passwordProbe(files, all(files...)), "b.nfo sits behind a.mkv in a solid stream")
+
+		files = append(files, entry("c.srt", 1, passwordProbeBudget))
+		assert.Equal(t, "c.srt", passwordProbe(files, all(files...)).Name)
+	})
+
+	t.Run("unencrypted or without a CRC", func(t *testing.T) {
+		noCRC := entry("b.nfo", 1, 200)
+		noCRC.CRC32 = 0
+		files := []*sevenzip.File{entry("a.mkv", 0, 3000), noCRC}
+		assert.Nil(t, passwordProbe(files, map[string]bool{"b.nfo": true}))
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"crypto/sha256"
 	"encoding/binary"
+	stderrors "errors"
 	"fmt"
 	"log/slog"
 	"path/filepath"
@@ -24,6 +25,7 @@ import (
 	"github.com/javi11/altmount/internal/progress"
 	"github.com/javi11/rardecode/v2"
 	"github.com/javi11/sevenzip"
+	"github.com/spf13/afero"
 	"golang.org/x/text/encoding/unicode"
 )
 
@@ -123,9 +125,16 @@ func (sz *sevenZipProcessor) AnalyzeSevenZipContentFromNzb(ctx context.Context,
 	if cfg.Import.AllowNestedRarExtraction != nil {
 		allowNestedRarExtraction = *cfg.Import.AllowNestedRarExtraction
 	}
+	keepEmptyFiles := true
+	if cfg.Import.KeepEmptyArchiveFiles != nil {
+		keepEmptyFiles = *cfg.Import.KeepEmptyArchiveFiles
+	}
 
 	// Rename 7zip files to match the first file's base name and sort
-	sortedFiles := renameSevenZipFilesAndSort(sevenZipFiles)
+	sortedFiles, err := renameSevenZipFilesAndSort(sevenZipFiles)
+	if err != nil {
+		return nil, err
+	}
 
 	// Create Usenet filesystem for 7zip access - this enables sevenzip to access
 	// 7zip part files directly from Usenet without downloading
@@ -185,6 +194,12 @@ func (sz *sevenZipProcessor) AnalyzeSevenZipContentFromNzb(ctx context.Context,
 		return nil, errors.NewNonRetryableError("no valid files found in 7zip archive. Compressed or encrypted archives are not supported", nil)
 	}
 
+	// ListFilesWithOffsets skips entries without a data stream, which drops
+	// zero-byte files along with directories.
+	if keepEmptyFiles {
+		fileInfos = append(fileInfos, emptyFileInfos(reader.File)...)
+	}
+
 	sz.log.DebugContext(ctx, "Successfully analyzed 7zip archive",
 		"main_file", mainSevenZipFile,
 		"files_found", len(fileInfos))
@@ -203,8 +218,12 @@ func (sz *sevenZipProcessor) AnalyzeSevenZipContentFromNzb(ctx context.Context,
 		return nil, errors.NewNonRetryableError("failed to convert 7zip results to content", err)
 	}
 
-	// Verify we have valid files after filtering
-	if len(contents) == 0 {
+	// Verify we have valid files after filtering. Empty files alone do not
+	// make an importable archive.
+	if !slices.ContainsFunc(contents, func(c Content) bool { return c.Size > 0 }) {
+		if codecErr := sz.describeCompressedArchive(ctx, aferoFS, reader.Volumes(), fileInfos); codecErr != nil {
+			return nil, errors.NewNonRetryableError("no valid files found in 7zip archive after filtering", codecErr)
+		}
 		return nil, errors.NewNonRetryableError("no valid files found in 7zip archive after filtering. Only uncompressed files are supported", nil)
 	}
 
@@ -327,6 +346,54 @@ func (sz *sevenZipProcessor) parseSevenZipFilename(filename string) (base string
 	return filename, 999999
 }
 
+// describeCompressedArchive names the coders that made an archive's files
+// unstreamable, so the import failure says e.g. "BCJ x86 filter" rather than
+// just "compressed". Returns nil when no file was skipped as compressed or the
+// header cannot be inspected (the caller then falls back to the generic error).
+func (sz *sevenZipProcessor) describeCompressedArchive(ctx context.Context, fs afero.Fs, volumes []string, fileInfos []sevenzip.FileInfo) error {
+	if !slices.ContainsFunc(fileInfos, func(fi sevenzip.FileInfo) bool { return fi.Compressed }) {
+		return nil
+	}
+
+	vr, err := openVolumes(fs, volumes)
+	if err != nil {
+		sz.log.DebugContext(ctx, "Could not open 7zip volumes to inspect coders", "error", err)
+		return nil
+	}
+	defer vr.Close()
+
+	var codecErr *UnsupportedCodersError
+	if err := checkArchiveCoders(ctx, vr, vr.size); !stderrors.As(err, &codecErr) {
+		if err != nil {
+			sz.log.DebugContext(ctx, "Could not inspect 7zip coders", "error", err)
+		}
+		return nil
+	}
+	sz.log.WarnContext(ctx, "7zip archive uses unsupported coders", "coders", codecErr.Methods)
+	return codecErr
+}
+
+// emptyFileInfos returns FileInfo entries for the archive's zero-byte regular
+// files. Directories are told apart by their header attributes, not by size.
+func emptyFileInfos(files []*sevenzip.File) []sevenzip.FileInfo {
+	var out []sevenzip.FileInfo
+	for _, f := range files {
+		if f.UncompressedSize != 0 || f.FileInfo().IsDir() || strings.HasSuffix(f.Name, "/") {
+			continue
+		}
+		out = append(out, sevenzip.FileInfo{Name: f.Name})
+	}
+	return out
+}
+
+// filenameEncoding returns the configured decoding for non-UTF-8 member names.
+func (sz *sevenZipProcessor) filenameEncoding() config.FilenameEncoding {
+	if sz.configGetter == nil {
+		return ""
+	}
+	return sz.configGetter().Import.FilenameEncoding
+}
+
 // convertFileInfosToSevenZipContent converts sevenzip FileInfo results to Content
 // Note: AES credentials are extracted per-file from each file's encryption metadata
 func (sz *sevenZipProcessor) convertFileInfosToSevenZipContent(fileInfos []sevenzip.FileInfo, sevenZipFiles []parser.ParsedFile, password string) ([]Content, error) {
@@ -334,7 +401,7 @@ func (sz *sevenZipProcessor) convertFileInfosToSevenZipContent(fileInfos []seven
 
 	for _, fi := range fileInfos {
 		// Skip directories (7zip lists directories as files with trailing slash)
-		isDirectory := strings.HasSuffix(fi.Name, "/") || fi.Size == 0
+		isDirectory := strings.HasSuffix(fi.Name, "/")
 		if isDirectory {
 			sz.log.DebugContext(context.Background(), "Skipping directory in 7zip archive", "path", fi.Name)
 			continue
@@ -346,8 +413,8 @@ func (sz *sevenZipProcessor) convertFileInfosToSevenZipContent(fileInfos []seven
 			continue
 		}
 
-		// Normalize backslashes in path (Windows-style paths in 7zip archives)
-		normalizedName := strings.ReplaceAll(fi.Name, "\\", "/")
+		// Normalize encoding and backslashes (Windows-style paths in 7zip archives)
+		normalizedName := archive.NormalizeMemberName(fi.Name, sz.filenameEncoding())
 
 		// Extract AES credentials from this file's encryption metadata (if encrypted)
 		// Each file can have its own encryption credentials
@@ -381,6 +448,12 @@ func (sz *sevenZipProcessor) convertFileInfosToSevenZipContent(fileInfos []seven
 			NzbdavID:     nzbdavID,
 		}
 
+		// Zero-byte files have no data in the archive and are served as empty
+		if fi.Size == 0 {
+			out = append(out, content)
+			continue
+		}
+
 		// Map the file's offset and size to segments from the 7z parts
 		segments, err := sz.mapOffsetToSegments(fi, sevenZipFiles)
 		if err != nil {
@@ -507,6 +580,8 @@ func sliceSegmentsForRange(segments []*metapb.SegmentData, offset int64, size in
 				StartOffset: relStart,
 				EndOffset:   relEnd,
 				SegmentSize: seg.SegmentSize,
+				UsableStart: seg.UsableStart,
+				UsableEnd:   seg.UsableEnd,
 			})
 			covered += (relEnd - relStart + 1)
 
@@ -531,78 +606,72 @@ func extractBaseFilenameSevenZip(filename string) string {
 	return strings.TrimSuffix(filename, filepath.Ext(filename))
 }
 
-// renameSevenZipFilesAndSort renames all 7z files to have the same base name and sorts them
-func renameSevenZipFilesAndSort(sevenZipFiles []parser.ParsedFile) []parser.ParsedFile {
-	// Check if any files have no extension or if there are duplicate filenames - if so, we'll treat it as allFilesNoExt and add extensions
-	allFilesNoExt := false
-	seenNames := make(map[string]struct{})
+// renameSevenZipFilesAndSort renames all 7z files to <base>.7z.NNN, where base
+// is the first part's base name, and returns them sorted by part number.
+//
+// Files whose name carries a part number (.7z.NNN, .7z, .NNN) keep it. The
+// rest — obfuscated names without a recognizable extension, or names shared by
+// several files — take the part numbers left free, in NZB order
+// (OriginalIndex), so a set mixing both kinds orders correctly. The resulting
+// numbering must be contiguous from part 1; a missing or duplicated part is
+// reported as a non-retryable error instead of handing the reader a misordered
+// set.
+func renameSevenZipFilesAndSort(sevenZipFiles []parser.ParsedFile) ([]parser.ParsedFile, error) {
+	if len(sevenZipFiles) == 0 {
+		return nil, nil
+	}
+
+	nameCount := make(map[string]int, len(sevenZipFiles))
 	for _, file := range sevenZipFiles {
-		if !archive.HasExtension(file.Filename) {
-			allFilesNoExt = true
-			break
+		nameCount[file.Filename]++
+	}
+
+	parts := make([]int, len(sevenZipFiles)) // 0 = not numbered by name
+	byPart := make(map[int]int, len(sevenZipFiles))
+	var unnumbered []int
+	for i, file := range sevenZipFiles {
+		part := sevenZipVolumeNumber(file.Filename)
+		if part == 0 || nameCount[file.Filename] > 1 {
+			unnumbered = append(unnumbered, i)
+			continue
 		}
-		if _, exists := seenNames[file.Filename]; exists {
-			allFilesNoExt = true
-			break
+		if prev, dup := byPart[part]; dup {
+			return nil, errors.NewNonRetryableError(fmt.Sprintf(
+				"7zip archive has two files for part %d: %q and %q", part, sevenZipFiles[prev].Filename, file.Filename), nil)
 		}
-		seenNames[file.Filename] = struct{}{}
+		byPart[part] = i
+		parts[i] = part
 	}
 
-	// Get base filename from first file if all files have no extension
-	baseFilename := ""
-	if allFilesNoExt {
-		// Sort files alphabetically to ensure consistent base filename selection
-		sort.Slice(sevenZipFiles, func(i, j int) bool {
-			return sevenZipFiles[i].Filename < sevenZipFiles[j].Filename
-		})
-		// Use the first file's name as the base for all parts (stripping any added extension)
-		if len(sevenZipFiles) > 0 {
-			baseFilename = sevenZipFiles[0].Filename
-			if ext := filepath.Ext(baseFilename); ext != "" {
-				baseFilename = strings.TrimSuffix(baseFilename, ext)
-			}
+	// Hand the free part numbers to the unnumbered files in NZB order.
+	sort.SliceStable(unnumbered, func(a, b int) bool {
+		fa, fb := sevenZipFiles[unnumbered[a]], sevenZipFiles[unnumbered[b]]
+		if fa.OriginalIndex != fb.OriginalIndex {
+			return fa.OriginalIndex < fb.OriginalIndex
 		}
-	} else {
-		// Sort files by part number BEFORE extracting base filename
-		// This ensures we use the correct first part's base name
-		sort.Slice(sevenZipFiles, func(i, j int) bool {
-			partI := extractSevenZipPartNumber(sevenZipFiles[i].Filename)
-			partJ := extractSevenZipPartNumber(sevenZipFiles[j].Filename)
-			return partI < partJ
-		})
-
-		// Get the base name of the first 7zip file (for existing extension handling)
-		firstFileBase := extractBaseFilenameSevenZip(sevenZipFiles[0].Filename)
-
-		// Rename all files to match the base name of the first file while preserving original part naming
-		for i := range sevenZipFiles {
-			originalFileName := sevenZipFiles[i].Filename
-
-			// Try to extract the part suffix from the original filename
-			partSuffix := getPartSuffixSevenZip(originalFileName)
-
-			// Construct new filename with first file's base name and original part suffix
-			sevenZipFiles[i].Filename = firstFileBase + partSuffix
+		return fa.Filename < fb.Filename
+	})
+	next := 1
+	for _, i := range unnumbered {
+		for _, taken := byPart[next]; taken; _, taken = byPart[next] {
+			next++
 		}
+		byPart[next] = i
+		parts[i] = next
 	}
 
-	// Apply normalization with unified base filename support
-	normalizedFiles := make([]parser.ParsedFile, len(sevenZipFiles))
-	for i, file := range sevenZipFiles {
-		normalizedFiles[i] = file
-		// Use OriginalIndex to preserve part numbering from original NZB order
-		// Pass total file count for zero-padding and base filename for unified naming
-		normalizedFiles[i].Filename = normalize7zPartFilename(file.Filename, file.OriginalIndex, allFilesNoExt, len(sevenZipFiles), baseFilename)
+	if missing := missingSevenZipParts(byPart); len(missing) > 0 {
+		return nil, errors.NewNonRetryableError(fmt.Sprintf(
+			"7zip archive is missing part(s) %v of %d", missing, len(byPart)+len(missing)), nil)
 	}
 
-	// Sort files by part number
-	sort.Slice(normalizedFiles, func(i, j int) bool {
-		partI := extractSevenZipPartNumber(normalizedFiles[i].Filename)
-		partJ := extractSevenZipPartNumber(normalizedFiles[j].Filename)
-		return partI < partJ
-	})
-
-	return normalizedFiles
+	baseFilename := extractBaseFilenameSevenZip(sevenZipFiles[byPart[1]].Filename)
+	sorted := make([]parser.ParsedFile, len(sevenZipFiles))
+	for i, file := range sevenZipFiles {
+		file.Filename = fmt.Sprintf("%s.7z.%03d", baseFilename, parts[i])
+		sorted[parts[i]-1] = file
+	}
+	return sorted, nil
 }
 
 // getPartSuffixSevenZip returns the canonical .7z.NNN suffix for a 7zip volume filename.
@@ -818,6 +887,8 @@ func patchMissingSegment(segments []*metapb.SegmentData, expectedSize, coveredSi
 		StartOffset: lastSeg.StartOffset,
 		EndOffset:   lastSeg.StartOffset + shortfall - 1,
 		SegmentSize: lastSeg.SegmentSize,
+		UsableStart: lastSeg.UsableStart,
+		UsableEnd:   lastSeg.UsableEnd,
 	}
 
 	patchedSegments := append(segments, patchSeg)
@@ -834,7 +905,7 @@ func (sz *sevenZipProcessor) detectAndProcessNestedRars(ctx context.Context, out
 	rarContentsByBase := make(map[string][]Content)
 
 	for _, c := range outerContents {
-		if c.IsDirectory || !isRarArchiveFile(c.Filename) {
+		if c.IsDirectory || c.Size == 0 || !isRarArchiveFile(c.Filename) {
 			nonRarContents = append(nonRarContents, c)
 			continue
 		}
@@ -974,6 +1045,9 @@ func (sz *sevenZipProcessor) processNestedRarContent(ctx context.Context, innerR
 				sz.log.WarnContext(ctx, "Failed to map nested file (encrypted)", "file", af.Name, "error", err)
 				continue
 			}
+			if err := archive.ValidateNestedContent(content); err != nil {
+				return nil, errors.NewNonRetryableError("inconsistent nested archive sizes", err)
+			}
 			result = append(result, content)
 		} else {
 			content, err := sz.mapNestedFileFlat(ctx, af, normalizedName, innerVolumeIndex)
@@ -981,6 +1055,9 @@ func (sz *sevenZipProcessor) processNestedRarContent(ctx context.Context, innerR
 				sz.log.WarnContext(ctx, "Failed to map nested file (flat)", "file", af.Name, "error", err)
 				continue
 			}
+			if err := archive.ValidateNestedContent(content); err != nil {
+				return nil, errors.NewNonRetryableError("inconsistent nested archive sizes", err)
+			}
 			result = append(result, content)
 		}
 	}
@@ -2,6 +2,7 @@ package sevenzip
 
 import (
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/javi11/altmount/internal/importer/parser"
@@ -111,7 +112,10 @@ func TestRenameSevenZipFilesAndSort_NewFormat(t *testing.T) {
 		{Filename: base + ".7z.004", OriginalIndex: 3},
 	}
 
-	result := renameSevenZipFilesAndSort(files)
+	result, err := renameSevenZipFilesAndSort(files)
+	if err != nil {
+		t.Fatalf("renameSevenZipFilesAndSort: %v", err)
+	}
 
 	if len(result) != 5 {
 		t.Fatalf("expected 5 files, got %d", len(result))
@@ -135,14 +139,17 @@ func TestRenameSevenZipFilesAndSort_OldFormat(t *testing.T) {
 	// Old format: name.7z (vol 1) + name.002 .. name.005 (vols 2-5)
 	base := "Puniru.is.a.Kawaii.Slime.2024.S01.Ger.Sub.AAC.1080p.WEB-DL.H.264-HiSHiRO"
 	files := []parser.ParsedFile{
-		{Filename: base + ".016", OriginalIndex: 3},
+		{Filename: base + ".004", OriginalIndex: 3},
 		{Filename: base + ".7z", OriginalIndex: 0},
-		{Filename: base + ".005", OriginalIndex: 2},
-		{Filename: base + ".003", OriginalIndex: 1},
-		{Filename: base + ".068", OriginalIndex: 4},
+		{Filename: base + ".003", OriginalIndex: 2},
+		{Filename: base + ".002", OriginalIndex: 1},
+		{Filename: base + ".005", OriginalIndex: 4},
 	}
 
-	result := renameSevenZipFilesAndSort(files)
+	result, err := renameSevenZipFilesAndSort(files)
+	if err != nil {
+		t.Fatalf("renameSevenZipFilesAndSort: %v", err)
+	}
 
 	if len(result) != 5 {
 		t.Fatalf("expected 5 files, got %d", len(result))
@@ -151,10 +158,10 @@ func TestRenameSevenZipFilesAndSort_OldFormat(t *testing.T) {
 	// All must be renamed to .7z.NNN and sorted numerically
 	expected := []string{
 		base + ".7z.001", // was .7z
+		base + ".7z.002", // was .002
 		base + ".7z.003", // was .003
+		base + ".7z.004", // was .004
 		base + ".7z.005", // was .005
-		base + ".7z.016", // was .016
-		base + ".7z.068", // was .068
 	}
 	for i, want := range expected {
 		if result[i].Filename != want {
@@ -176,7 +183,10 @@ func TestRenameSevenZipFilesAndSort_OldFormat_68Parts(t *testing.T) {
 		})
 	}
 
-	result := renameSevenZipFilesAndSort(files)
+	result, err := renameSevenZipFilesAndSort(files)
+	if err != nil {
+		t.Fatalf("renameSevenZipFilesAndSort: %v", err)
+	}
 
 	if len(result) != 68 {
 		t.Fatalf("expected 68 files, got %d", len(result))
@@ -202,7 +212,10 @@ func TestRenameSevenZipFilesAndSort_SingleFile(t *testing.T) {
 		{Filename: "movie.7z", OriginalIndex: 0},
 	}
 
-	result := renameSevenZipFilesAndSort(files)
+	result, err := renameSevenZipFilesAndSort(files)
+	if err != nil {
+		t.Fatalf("renameSevenZipFilesAndSort: %v", err)
+	}
 
 	if len(result) != 1 {
 		t.Fatalf("expected 1 file, got %d", len(result))
@@ -212,6 +225,129 @@ func TestRenameSevenZipFilesAndSort_SingleFile(t *testing.T) {
 	}
 }
 
+func TestRenameSevenZipFilesAndSort_MixedObfuscated(t *testing.T) {
+	// Parts 2 and 4 lost their names to obfuscation; they must take the free
+	// part numbers in NZB order, not sort after the named parts.
+	base := "Show.S01.1080p"
+	files := []parser.ParsedFile{
+		{Filename: base + ".7z.005", OriginalIndex: 9},
+		{Filename: "f3a9c1d2e8", OriginalIndex: 7},
+		{Filename: base + ".7z.001", OriginalIndex: 5},
+		{Filename: base + ".7z.003", OriginalIndex: 8},
+		{Filename: "0b7e44a1c9", OriginalIndex: 6},
+	}
+
+	result, err := renameSevenZipFilesAndSort(files)
+	if err != nil {
+		t.Fatalf("renameSevenZipFilesAndSort: %v", err)
+	}
+
+	expected := []struct{ name, from string }{
+		{base + ".7z.001", base + ".7z.001"},
+		{base + ".7z.002", "0b7e44a1c9"},
+		{base + ".7z.003", base + ".7z.003"},
+		{base + ".7z.004", "f3a9c1d2e8"},
+		{base + ".7z.005", base + ".7z.005"},
+	}
+	if len(result) != len(expected) {
+		t.Fatalf("expected %d files, got %d", len(expected), len(result))
+	}
+	for i, want := range expected {
+		if result[i].Filename != want.name {
+			t.Errorf("result[%d].Filename = %q; want %q", i, result[i].Filename, want.name)
+		}
+		if orig := files[indexByOriginal(files, result[i].OriginalIndex)].Filename; orig != want.from {
+			t.Errorf("result[%d] came from %q; want %q", i, orig, want.from)
+		}
+	}
+}
+
+func TestRenameSevenZipFilesAndSort_AllObfuscatedUsesNzbOrder(t *testing.T) {
+	files := []parser.ParsedFile{
+		{Filename: "zz91", OriginalIndex: 2},
+		{Filename: "aa17", OriginalIndex: 3},
+		{Filename: "mm42", OriginalIndex: 1},
+	}
+
+	result, err := renameSevenZipFilesAndSort(files)
+	if err != nil {
+		t.Fatalf("renameSevenZipFilesAndSort: %v", err)
+	}
+
+	// mm42 is first in the NZB, so it is part 1 and names the set.
+	wantOrder := []int{1, 2, 3}
+	for i, want := range []string{"mm42.7z.001", "mm42.7z.002", "mm42.7z.003"} {
+		if result[i].Filename != want || result[i].OriginalIndex != wantOrder[i] {
+			t.Errorf("result[%d] = %q (index %d); want %q (index %d)",
+				i, result[i].Filename, result[i].OriginalIndex, want, wantOrder[i])
+		}
+	}
+}
+
+func TestRenameSevenZipFilesAndSort_Gaps(t *testing.T) {
+	tests := []struct {
+		name  string
+		files []parser.ParsedFile
+		want  string
+	}{
+		{
+			name: "interior part missing",
+			files: []parser.ParsedFile{
+				{Filename: "movie.7z.001", OriginalIndex: 0},
+				{Filename: "movie.7z.002", OriginalIndex: 1},
+				{Filename: "movie.7z.004", OriginalIndex: 2},
+			},
+			want: "missing part(s) [3] of 4",
+		},
+		{
+			name: "first part missing",
+			files: []parser.ParsedFile{
+				{Filename: "movie.7z.002", OriginalIndex: 0},
+				{Filename: "movie.7z.003", OriginalIndex: 1},
+			},
+			want: "missing part(s) [1] of 3",
+		},
+		{
+			name: "obfuscated part cannot fill two holes",
+			files: []parser.ParsedFile{
+				{Filename: "movie.7z.001", OriginalIndex: 0},
+				{Filename: "c0ffee", OriginalIndex: 1},
+				{Filename: "movie.7z.004", OriginalIndex: 2},
+			},
+			want: "missing part(s) [3] of 4",
+		},
+		{
+			name: "duplicate part",
+			files: []parser.ParsedFile{
+				{Filename: "movie.7z", OriginalIndex: 0},
+				{Filename: "movie.7z.001", OriginalIndex: 1},
+			},
+			want: "two files for part 1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := renameSevenZipFilesAndSort(tt.files)
+			if err == nil {
+				t.Fatalf("expected an error containing %q", tt.want)
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %q; want it to contain %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func indexByOriginal(files []parser.ParsedFile, originalIndex int) int {
+	for i, f := range files {
+		if f.OriginalIndex == originalIndex {
+			return i
+		}
+	}
+	return -1
+}
+
 // ---------------------------------------------------------------------------
 // parseSevenZipFilename
 // ---------------------------------------------------------------------------
@@ -25,4 +25,8 @@ type Processor interface {
 	// CreateFileMetadataFromSevenZipContent creates FileMetadata from Content for the metadata
 	// system. This is used to convert Content into the protobuf format used by the metadata system.
 	CreateFileMetadataFromSevenZipContent(content Content, sourceNzbPath string, releaseDate int64, nzbdavId string) *metapb.FileMetadata
+	// TestPassword reports whether password decrypts the archive, reading only
+	// the header and at most one small entry. Returns ErrPasswordNotVerifiable
+	// when the archive offers nothing small enough to check.
+	TestPassword(ctx context.Context, sevenZipFiles []parser.ParsedFile, password string) (bool, error)
 }
@@ -0,0 +1,178 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"testing"
+	"unicode/utf16"
+
+	"github.com/javi11/altmount/internal/testsupport/nzbbuild"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sevenZipEntry describes one entry of a hand-built 7z archive.
+type sevenZipEntry struct {
+	name string
+	data []byte // nil for directories and zero-byte files
+	dir  bool
+}
+
+// buildStoredSevenZip assembles a minimal store-mode (copy coder) 7z archive.
+// Entries with data share one folder in order; the rest are written as empty
+// streams, with directories told apart only by their attributes. No 7z
+// binary is needed, so the fixture can carry entries the generated ones lack.
+func buildStoredSevenZip(t *testing.T, entries []sevenZipEntry) []byte {
+	t.Helper()
+
+	var packed []byte
+	var emptyStream, emptyFile []bool
+	var names []byte
+	var attrs []byte
+	for _, e := range entries {
+		isEmpty := len(e.data) == 0
+		emptyStream = append(emptyStream, isEmpty)
+		if isEmpty {
+			emptyFile = append(emptyFile, !e.dir)
+		}
+		packed = append(packed, e.data...)
+
+		for _, u := range utf16.Encode([]rune(e.name)) {
+			names = binary.LittleEndian.AppendUint16(names, u)
+		}
+		names = append(names, 0, 0)
+
+		attr := uint32(0x20) // FILE_ATTRIBUTE_ARCHIVE
+		if e.dir {
+			attr = 0x10 // FILE_ATTRIBUTE_DIRECTORY
+		}
+		attrs = binary.LittleEndian.AppendUint32(attrs, attr)
+	}
+	dataStreams := 0
+	for _, empty := range emptyStream {
+		if !empty {
+			dataStreams++
+		}
+	}
+	require.Equal(t, 1, dataStreams, "fixture supports exactly one data entry")
+
+	var h bytes.Buffer
+	num := func(v uint64) { h.Write(sevenZipNumber(v)) }
+	prop := func(id byte, payload []byte) {
+		h.WriteByte(id)
+		num(uint64(len(payload)))
+		h.Write(payload)
+	}
+
+	h.WriteByte(0x01) // Header
+	h.WriteByte(0x04) // MainStreamsInfo
+	h.WriteByte(0x06) // PackInfo
+	num(0)            // pack position
+	num(1)            // pack streams
+	h.WriteByte(0x09) // Size
+	num(uint64(len(packed)))
+	h.WriteByte(0x00)
+	h.WriteByte(0x07) // UnpackInfo
+	h.WriteByte(0x0B) // Folder
+	num(1)            // folders
+	h.WriteByte(0x00) // not external
+	num(1)            // coders
+	h.WriteByte(0x01) // simple coder, 1-byte id
+	h.WriteByte(0x00) // copy
+	h.WriteByte(0x0C) // CodersUnpackSize
+	num(uint64(len(packed)))
+	h.WriteByte(0x00)
+	h.WriteByte(0x00) // end MainStreamsInfo
+
+	h.WriteByte(0x05) // FilesInfo
+	num(uint64(len(entries)))
+	prop(0x0E, sevenZipBitVector(emptyStream))
+	prop(0x0F, sevenZipBitVector(emptyFile))
+	prop(0x11, append([]byte{0x00}, names...))
+	prop(0x15, append([]byte{0x01, 0x00}, attrs...))
+	h.WriteByte(0x00)
+	h.WriteByte(0x00) // end Header
+
+	header := h.Bytes()
+	start := make([]byte, 20)
+	binary.LittleEndian.PutUint64(start[0:], uint64(len(packed)))
+	binary.LittleEndian.PutUint64(start[8:], uint64(len(header)))
+	binary.LittleEndian.PutUint32(start[16:], crc32.ChecksumIEEE(header))
+
+	out := []byte{'7', 'z', 0xBC, 0xAF, 0x27, 0x1C, 0x00, 0x04}
+	out = binary.LittleEndian.AppendUint32(out, crc32.ChecksumIEEE(start))
+	out = append(out, start...)
+	out = append(out, packed...)
+	return append(out, header...)
+}
+
+// sevenZipNumber encodes v in the 7z variable-length number format: the
+// count of leading one bits in the first byte gives the number of
+// little-endian bytes that follow.
+func sevenZipNumber(v uint64) []byte {
+	for n := 0; n < 8; n++ {
+		if v < 1<<(7*(n+1)) {
+			out := []byte{byte(uint16(0xFF00)>>n) | byte(v>>(8*n))}
+			for i := 0; i < n; i++ {
+				out = append(out, byte(v>>(8*i)))
+			}
+			return out
+		}
+	}
+	return append([]byte{0xFF}, binary.LittleEndian.AppendUint64(nil, v)...)
+}
+
+func sevenZipBitVector(bits []bool) []byte {
+	out := make([]byte, (len(bits)+7)/8)
+	for i, b := range bits {
+		if b {
+			out[i/8] |= 0x80 >> (i % 8)
+		}
+	}
+	return out
+}
+
+func TestImportSevenZip_EmptyFileImportsAndLists(t *testing.T) {
+	env := newBatteryEnv(t)
+
+	payload := bytes.Repeat([]byte("altmount"), 20_000)
+	archive := buildStoredSevenZip(t, []sevenZipEntry{
+		{name: "movie.bin", data: payload},
+		{name: "empty.bin"},
+		{name: "extras", dir: true},
+	})
+	segs := env.registerContent("7z-empty", archive, archivePartSize, 1.0, nil)
+	nzb := nzbbuild.Build(nzbbuild.File{Subject: "archive.7z", Segments: segs})
+
+	_, _, err := env.runImport(nzb, "archive")
+	require.NoError(t, err)
+
+	// The data file is the only media file, so it alone takes the release name.
+	assert.ElementsMatch(t, []string{"archive.bin", "empty.bin"}, env.listDir("/archive"))
+	assert.Equal(t, int64(len(payload)), env.readMeta("/archive/archive.bin").FileSize)
+
+	empty := env.readMeta("/archive/empty.bin")
+	require.NotNil(t, empty)
+	assert.Zero(t, empty.FileSize)
+	assert.Empty(t, empty.SegmentData)
+	assert.Empty(t, empty.NestedSources)
+}
+
+func TestImportSevenZip_EmptyFilesDroppedWhenDisabled(t *testing.T) {
+	env := newBatteryEnv(t)
+	keep := false
+	env.cfg.Import.KeepEmptyArchiveFiles = &keep
+
+	archive := buildStoredSevenZip(t, []sevenZipEntry{
+		{name: "movie.bin", data: bytes.Repeat([]byte("altmount"), 20_000)},
+		{name: "empty.bin"},
+	})
+	segs := env.registerContent("7z-empty-off", archive, archivePartSize, 1.0, nil)
+	nzb := nzbbuild.Build(nzbbuild.File{Subject: "archive.7z", Segments: segs})
+
+	_, _, err := env.runImport(nzb, "archive")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"archive.bin"}, env.listDir("/archive"))
+}
@@ -56,6 +56,9 @@ func newMinimalServiceForPersistTest(t *testing.T) *Service {
 			skip_arr_notification BOOLEAN NOT NULL DEFAULT FALSE,
 			skip_post_import_links BOOLEAN NOT NULL DEFAULT FALSE,
 			indexer TEXT DEFAULT NULL,
+			sequence BIGINT NOT NULL DEFAULT 0,
+			heartbeat_at DATETIME DEFAULT NULL,
+			retry_after DATETIME DEFAULT NULL,
 			UNIQUE(nzb_path)
 		);
 		CREATE INDEX IF NOT EXISTS idx_queue_nzb_path ON import_queue(nzb_path);
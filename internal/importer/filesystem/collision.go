@@ -0,0 +1,38 @@
+package filesystem
+
+import (
+	"github.com/javi11/altmount/internal/config"
+	"github.com/javi11/altmount/internal/metadata"
+)
+
+// ResolvePathCollision applies policy to virtualPath before an import writes
+// it, consulting the metadata already on disk. It returns the path to write,
+// or false when policy is skip and a healthy file already holds the path.
+// Overwrite returns virtualPath unchanged; version picks the first free _N
+// suffix as EnsureUniqueVirtualPath does. Non-healthy metadata never counts
+// as a collision, so a broken file is always replaced in place.
+func ResolvePathCollision(virtualPath string, policy config.PathCollision, ms *metadata.MetadataService) (string, bool) {
+	switch policy {
+	case config.PathCollisionSkip:
+		if isHealthyMetadata(virtualPath, ms) {
+			return "", false
+		}
+	case config.PathCollisionVersion:
+		return EnsureUniqueVirtualPath(virtualPath, ms), true
+	}
+	return virtualPath, true
+}
+
+// Claim claims desired exactly, without suffixing. It reports false when a
+// sibling in the batch already holds desired or healthy metadata exists at
+// it. A successful claim must be Released like one from Reserve.
+func (r *PathReserver) Claim(desired string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, taken := r.claimed[desired]; taken || isHealthyMetadata(desired, r.ms) {
+		return false
+	}
+	r.claimed[desired] = struct{}{}
+	return true
+}
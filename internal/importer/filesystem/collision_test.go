@@ -0,0 +1,44 @@
+package filesystem
+
+import (
+	"testing"
+
+	"github.com/javi11/altmount/internal/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolvePathCollision(t *testing.T) {
+	const p = "/complete/tv/show.S01E01.mkv"
+	ms := newTestMetadataService(t)
+
+	for _, policy := range []config.PathCollision{config.PathCollisionOverwrite, config.PathCollisionSkip, config.PathCollisionVersion} {
+		got, ok := ResolvePathCollision(p, policy, ms)
+		assert.True(t, ok, "%s: free path is always written", policy)
+		assert.Equal(t, p, got, "%s: free path is unchanged", policy)
+	}
+
+	writeHealthyMeta(t, ms, p)
+
+	got, ok := ResolvePathCollision(p, config.PathCollisionOverwrite, ms)
+	assert.True(t, ok)
+	assert.Equal(t, p, got)
+
+	_, ok = ResolvePathCollision(p, config.PathCollisionSkip, ms)
+	assert.False(t, ok)
+
+	got, ok = ResolvePathCollision(p, config.PathCollisionVersion, ms)
+	assert.True(t, ok)
+	assert.Equal(t, "/complete/tv/show.S01E01_1.mkv", got)
+}
+
+func TestPathReserverClaim(t *testing.T) {
+	ms := newTestMetadataService(t)
+	writeHealthyMeta(t, ms, "/complete/tv/taken.mkv")
+	r := NewPathReserver(ms)
+
+	assert.False(t, r.Claim("/complete/tv/taken.mkv"), "healthy file on disk")
+	assert.True(t, r.Claim("/complete/tv/free.mkv"))
+	assert.False(t, r.Claim("/complete/tv/free.mkv"), "claimed by a sibling")
+	r.Release("/complete/tv/free.mkv")
+	assert.True(t, r.Claim("/complete/tv/free.mkv"))
+}
@@ -419,11 +419,12 @@ func (dl dbSegmentLoader) GetSegment(index int) (segment usenet.Segment, groups
 		return usenet.Segment{}, nil, false
 	}
 	seg := dl.segs[index]
+	start, end := seg.BodyRange()
 
 	return usenet.Segment{
 		Id:    seg.Id,
-		Start: seg.StartOffset,
-		End:   seg.EndOffset,
+		Start: start,
+		End:   end,
 		Size:  seg.SegmentSize,
 	}, nil, true
 }
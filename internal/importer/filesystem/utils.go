@@ -57,14 +57,15 @@ func CalculateVirtualDirectory(nzbPath, relativePath string) string {
 	return filepath.Clean(virtualPath)
 }
 
-// SeparateFiles separates files by type (regular, archive, PAR2) based on NZB type
+// SeparateFiles separates files by type (regular, archive, PAR2) based on NZB type.
+// The PAR2 group holds every verification file, .sfv included (see IsVerificationFile).
 func SeparateFiles(files []parser.ParsedFile, nzbType parser.NzbType) (regular, archive, par2 []parser.ParsedFile) {
 	switch nzbType {
 	case parser.NzbTypeRarArchive:
 		for _, file := range files {
 			if file.IsRarArchive {
 				archive = append(archive, file)
-			} else if file.IsPar2Archive || IsPar2File(file.Filename) {
+			} else if file.IsPar2Archive || IsVerificationFile(file.Filename) {
 				par2 = append(par2, file)
 			} else {
 				regular = append(regular, file)
@@ -73,7 +74,7 @@ func SeparateFiles(files []parser.ParsedFile, nzbType parser.NzbType) (regular,
 
 	case parser.NzbType7zArchive:
 		for _, file := range files {
-			if file.IsPar2Archive || IsPar2File(file.Filename) {
+			if file.IsPar2Archive || IsVerificationFile(file.Filename) {
 				par2 = append(par2, file)
 			} else {
 				// When the NZB is a 7z archive, all non-par2 files are archive parts.
@@ -87,7 +88,7 @@ func SeparateFiles(files []parser.ParsedFile, nzbType parser.NzbType) (regular,
 	default:
 		// For single file and multi-file types, just separate PAR2 files
 		for _, file := range files {
-			if file.IsPar2Archive || IsPar2File(file.Filename) {
+			if file.IsPar2Archive || IsVerificationFile(file.Filename) {
 				par2 = append(par2, file)
 			} else {
 				regular = append(regular, file)
@@ -104,6 +105,12 @@ func IsPar2File(filename string) bool {
 	return strings.HasSuffix(lower, ".par2")
 }
 
+// IsVerificationFile reports whether filename is a PAR2 or SFV file: one that
+// checks or repairs a release rather than being part of its content.
+func IsVerificationFile(filename string) bool {
+	return IsPar2File(filename) || strings.EqualFold(filepath.Ext(filename), ".sfv")
+}
+
 // EnsureDirectoryExists creates directory structure in the metadata filesystem
 func EnsureDirectoryExists(virtualDir string, metadataService *metadata.MetadataService) error {
 	if virtualDir == "/" {
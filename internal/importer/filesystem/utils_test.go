@@ -176,6 +176,23 @@ func TestSeparateFiles_MultiFile(t *testing.T) {
 	}
 }
 
+func TestSeparateFiles_SfvIsVerification(t *testing.T) {
+	files := []parser.ParsedFile{
+		{Filename: "movie.mkv"},
+		{Filename: "movie.SFV"},
+		{Filename: "movie.vol00+01.par2"},
+	}
+
+	regular, _, par2 := SeparateFiles(files, parser.NzbTypeMultiFile)
+
+	if len(regular) != 1 || regular[0].Filename != "movie.mkv" {
+		t.Errorf("expected only movie.mkv as regular, got %v", regular)
+	}
+	if len(par2) != 2 {
+		t.Errorf("expected the sfv and par2 as verification files, got %d", len(par2))
+	}
+}
+
 // ---------------------------------------------------------------------------
 // EnsureUniqueVirtualPath
 // ---------------------------------------------------------------------------
@@ -58,9 +58,17 @@ func newMoveToFailedTestService(t *testing.T) *Service {
 			skip_arr_notification BOOLEAN NOT NULL DEFAULT FALSE,
 			skip_post_import_links BOOLEAN NOT NULL DEFAULT FALSE,
 			indexer TEXT DEFAULT NULL,
+			sequence BIGINT NOT NULL DEFAULT 0,
+			heartbeat_at DATETIME DEFAULT NULL,
+			retry_after DATETIME DEFAULT NULL,
 			UNIQUE(nzb_path)
 		);
 		CREATE INDEX IF NOT EXISTS idx_queue_nzb_path ON import_queue(nzb_path);
+		CREATE TABLE IF NOT EXISTS import_dead_letter (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			queue_id INTEGER NOT NULL,
+			nzb_path TEXT NOT NULL
+		);
 	`)
 	require.NoError(t, err)
 
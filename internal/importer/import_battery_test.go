@@ -470,6 +470,44 @@ func TestImportBattery_RarWidthMismatchVolumeNaming(t *testing.T) {
 	}
 }
 
+// TestImportBattery_RarSwappedVolumes imports a multi-volume RAR whose NZB labels
+// two volumes with each other's part numbers. The volume headers give the swap
+// away, so the import must fail with a misordered-volume error rather than map
+// bytes from the wrong volumes.
+func TestImportBattery_RarSwappedVolumes(t *testing.T) {
+	env := newBatteryEnv(t)
+
+	const swapA, swapB = 3, 5
+	name := func(n int) string {
+		if n >= 10 {
+			return fmt.Sprintf("archive.part%03d.rar", n)
+		}
+		return fmt.Sprintf("archive.part%02d.rar", n)
+	}
+
+	var files []nzbbuild.File
+	for n := 1; n <= 14; n++ {
+		src := n
+		switch n {
+		case swapA:
+			src = swapB
+		case swapB:
+			src = swapA
+		}
+		data := loadFixture(t, filepath.Join("rar_widthmismatch", name(src)))
+		segs := env.registerContent(fmt.Sprintf("rar-swap-%03d", n), data, archivePartSize, 1.0, nil)
+		files = append(files, nzbbuild.File{Subject: name(n), Segments: segs})
+	}
+
+	_, _, err := env.runImport(nzbbuild.Build(files...), "archive")
+	if err == nil {
+		t.Fatal("expected swapped volumes to fail the import, got nil")
+	}
+	if !strings.Contains(err.Error(), "misordered volumes") {
+		t.Errorf("err = %v, want a misordered-volume error", err)
+	}
+}
+
 // TestImportBattery_SevenZipSingle verifies import of a single-volume 7z archive.
 func TestImportBattery_SevenZipSingle(t *testing.T) {
 	entries := loadManifest(t, "7z_single")
@@ -53,6 +53,9 @@ func partitionISOFiles(files []parser.ParsedFile) (isos, rest []parser.ParsedFil
 type expandBareISODeps struct {
 	expand        func(ctx context.Context, enabled bool, contents []archive.Content) ([]archive.Content, error)
 	writeMetadata func(virtualPath string, meta *metapb.FileMetadata) error
+	// resolvePath applies Import.OnPathCollision to each expanded path
+	// before it is written; false skips the file. nil writes paths as-is.
+	resolvePath func(virtualPath string) (string, bool)
 	// enabled is the resolved value of Import.ExpandBlurayIso. Pulled
 	// out of deps so tests can flip it without touching config.
 	enabled bool
@@ -116,6 +119,16 @@ func expandBareISOFiles(
 		pl.Go(func(ctx context.Context) error {
 			meta := archive.NewFileMetadataFromContent(c, sourceNzbPath, releaseDate, c.NzbdavID)
 			virtualPath := path.Join(virtualDir, c.Filename)
+			if deps.resolvePath != nil {
+				resolved, ok := deps.resolvePath(virtualPath)
+				if !ok {
+					slog.InfoContext(ctx, "Skipping expanded ISO file: healthy file already exists at path",
+						"release", releaseName,
+						"path", virtualPath)
+					return nil
+				}
+				virtualPath = resolved
+			}
 			if err := deps.writeMetadata(virtualPath, meta); err != nil {
 				return fmt.Errorf("write metadata %q: %w", virtualPath, err)
 			}
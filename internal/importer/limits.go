@@ -0,0 +1,50 @@
+package importer
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/javi11/altmount/internal/config"
+	"github.com/javi11/altmount/internal/importer/archive"
+	"github.com/javi11/altmount/internal/importer/parser"
+)
+
+// ErrImportLimitExceeded marks imports rejected by Import.MaxFiles or
+// Import.MaxTotalBytes. handleProcessingFailure keys quarantine on it.
+var ErrImportLimitExceeded = errors.New("import exceeds configured limits")
+
+// checkImportLimits rejects an import exposing files files of totalBytes in
+// total when either exceeds its configured limit.
+func checkImportLimits(cfg config.ImportConfig, files int, totalBytes int64) error {
+	if cfg.MaxFiles > 0 && files > cfg.MaxFiles {
+		return NewNonRetryableError("import rejected",
+			fmt.Errorf("%w: %d files exceeds max_files %d", ErrImportLimitExceeded, files, cfg.MaxFiles))
+	}
+	if cfg.MaxTotalBytes > 0 && totalBytes > cfg.MaxTotalBytes {
+		return NewNonRetryableError("import rejected",
+			fmt.Errorf("%w: %d bytes exceeds max_total_bytes %d", ErrImportLimitExceeded, totalBytes, cfg.MaxTotalBytes))
+	}
+	return nil
+}
+
+// archiveLimitCheck returns the check the archive aggregators run after
+// analysis, counting regularFiles imported alongside the archive too, or nil
+// when no limit is configured.
+func (proc *Processor) archiveLimitCheck(regularFiles []parser.ParsedFile) archive.LimitCheck {
+	cfg := proc.configGetter().Import
+	if cfg.MaxFiles <= 0 && cfg.MaxTotalBytes <= 0 {
+		return nil
+	}
+	regularBytes := totalFileSize(regularFiles)
+	return func(files int, totalBytes int64) error {
+		return checkImportLimits(cfg, files+len(regularFiles), totalBytes+regularBytes)
+	}
+}
+
+func totalFileSize(files []parser.ParsedFile) int64 {
+	var total int64
+	for _, f := range files {
+		total += f.Size
+	}
+	return total
+}
@@ -0,0 +1,67 @@
+package importer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/javi11/altmount/internal/config"
+	"github.com/javi11/altmount/internal/database"
+	"github.com/javi11/altmount/internal/importer/parser"
+)
+
+func TestCheckImportLimits(t *testing.T) {
+	cfg := config.ImportConfig{MaxFiles: 10, MaxTotalBytes: 1 << 30}
+
+	assert.NoError(t, checkImportLimits(cfg, 10, 1<<30), "at the limits proceeds")
+	assert.NoError(t, checkImportLimits(config.ImportConfig{}, 1_000_000, 1<<50), "zero limits are unlimited")
+
+	err := checkImportLimits(cfg, 11, 1)
+	require.ErrorIs(t, err, ErrImportLimitExceeded)
+	assert.True(t, IsNonRetryable(err))
+	assert.Contains(t, err.Error(), "11 files exceeds max_files 10")
+
+	err = checkImportLimits(cfg, 1, 1<<30+1)
+	require.ErrorIs(t, err, ErrImportLimitExceeded)
+	assert.Contains(t, err.Error(), "exceeds max_total_bytes")
+}
+
+func TestArchiveLimitCheckIncludesRegularFiles(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Import.MaxFiles = 3
+	proc := &Processor{configGetter: func() *config.Config { return cfg }}
+	regular := []parser.ParsedFile{{Filename: "a.nfo", Size: 10}, {Filename: "b.srt", Size: 20}}
+
+	check := proc.archiveLimitCheck(regular)
+	require.NotNil(t, check)
+	assert.NoError(t, check(1, 100), "1 analyzed + 2 regular files is within max_files")
+	err := check(2, 100)
+	require.ErrorIs(t, err, ErrImportLimitExceeded)
+	assert.Contains(t, err.Error(), "4 files exceeds max_files 3")
+
+	cfg.Import.MaxFiles = 0
+	assert.Nil(t, proc.archiveLimitCheck(regular), "no check without limits")
+}
+
+func TestMoveToQuarantineFolder(t *testing.T) {
+	svc := newMoveToFailedTestService(t)
+	ctx := context.Background()
+
+	nzbPath := filepath.Join(t.TempDir(), "7-huge.release.nzb")
+	require.NoError(t, os.WriteFile(nzbPath, []byte("<nzb/>"), 0644))
+	item := &database.ImportQueueItem{NzbPath: nzbPath, Status: database.QueueStatusPending}
+	require.NoError(t, svc.database.Repository.AddToQueue(ctx, item))
+
+	require.NoError(t, svc.MoveToQuarantineFolder(ctx, item))
+
+	quarantineDir := svc.GetQuarantineNzbFolder()
+	assert.True(t, strings.HasPrefix(item.NzbPath, quarantineDir+string(filepath.Separator)),
+		"item.NzbPath should point into the quarantine folder, got %q", item.NzbPath)
+	assert.FileExists(t, item.NzbPath)
+	assert.NoFileExists(t, nzbPath)
+}
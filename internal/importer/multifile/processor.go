@@ -12,6 +12,7 @@ import (
 	"sync/atomic"
 	"time"
 
+	"github.com/javi11/altmount/internal/config"
 	"github.com/javi11/altmount/internal/importer/filesystem"
 	"github.com/javi11/altmount/internal/importer/parser"
 	"github.com/javi11/altmount/internal/importer/utils"
@@ -27,6 +28,8 @@ var ErrNoFilesProcessed = errors.New("no regular files were successfully process
 // ProcessRegularFiles processes multiple regular files.
 // Returns the virtual paths of all metadata files successfully written, plus any error.
 // writtenPaths is populated even on partial failure (first-error mode).
+// onPathCollision governs files landing on a path held by a healthy file;
+// unset versions them with a _N suffix.
 func ProcessRegularFiles(
 	ctx context.Context,
 	virtualDir string,
@@ -39,6 +42,7 @@ func ProcessRegularFiles(
 	tracker *progress.Tracker,
 	storeIndex map[string]int64,
 	storeRef string,
+	onPathCollision config.PathCollision,
 ) ([]string, error) {
 	if len(files) == 0 {
 		return nil, nil
@@ -79,6 +83,10 @@ func ProcessRegularFiles(
 	var processed int64
 	total := len(files)
 
+	// skipped counts files left in place by the skip collision policy. The
+	// healthy copy already serves them, so they count toward success.
+	var skipped int64
+
 	// Throttle progress broadcasts. The SSE subscriber channel is buffered and
 	// drops on overflow, so firing one update per file (thousands, in well under
 	// a second) floods it and nearly all are dropped — leaving the bar visually
@@ -104,10 +112,22 @@ func ProcessRegularFiles(
 			virtualPath := filepath.Join(parentPath, filename)
 			virtualPath = strings.ReplaceAll(virtualPath, string(filepath.Separator), "/")
 
-			// Atomically pick and reserve a unique path, checking both on-disk
+			// Atomically pick and reserve the path, checking both on-disk
 			// healthy metadata and paths already claimed by sibling goroutines.
-			virtualPath = reserver.Reserve(virtualPath)
-			defer reserver.Release(virtualPath)
+			switch onPathCollision.Or(config.PathCollisionVersion) {
+			case config.PathCollisionVersion:
+				virtualPath = reserver.Reserve(virtualPath)
+				defer reserver.Release(virtualPath)
+			case config.PathCollisionSkip:
+				if !reserver.Claim(virtualPath) {
+					slog.InfoContext(ctx, "Skipping file: healthy file already exists at path",
+						"file", filename,
+						"virtual_path", virtualPath)
+					atomic.AddInt64(&skipped, 1)
+					return nil
+				}
+				defer reserver.Release(virtualPath)
+			}
 
 			if !utils.IsAllowedFile(filename, file.Size, allowedFileExtensions, filterSamples) {
 				return nil
@@ -138,6 +158,10 @@ func ProcessRegularFiles(
 				par2Refs,
 				file.NzbdavID,
 			)
+			// PAR2 hashes the posted bytes, which for an encrypted file are ciphertext.
+			if file.Encryption == metapb.Encryption_NONE {
+				fileMeta.ContentMd5 = file.ContentMD5
+			}
 
 			metadataPath := metadataService.GetMetadataFilePath(virtualPath)
 			if _, err := os.Stat(metadataPath); err == nil {
@@ -164,7 +188,7 @@ func ProcessRegularFiles(
 		return writtenPaths, err
 	}
 
-	if len(writtenPaths) == 0 {
+	if len(writtenPaths) == 0 && atomic.LoadInt64(&skipped) == 0 {
 		return writtenPaths, ErrNoFilesProcessed
 	}
 
@@ -5,10 +5,12 @@ import (
 	"errors"
 	"os"
 	"path/filepath"
+	"slices"
 	"sync"
 	"testing"
 	"time"
 
+	"github.com/javi11/altmount/internal/config"
 	"github.com/javi11/altmount/internal/importer/parser"
 	"github.com/javi11/altmount/internal/metadata"
 	metapb "github.com/javi11/altmount/internal/metadata/proto"
@@ -63,6 +65,7 @@ func TestProcessRegularFilesSkipsFileWithSizeMismatch(t *testing.T) {
 		nil,
 		nil,
 		"",
+		"",
 	)
 	if err != nil {
 		t.Fatalf("ProcessRegularFiles returned error: %v", err)
@@ -101,6 +104,7 @@ func TestProcessRegularFilesFailsWhenAllFilesFailValidation(t *testing.T) {
 		nil,
 		nil,
 		"",
+		"",
 	)
 	if err == nil {
 		t.Fatal("ProcessRegularFiles returned nil error, want all-files-failed error")
@@ -145,6 +149,7 @@ func TestProcessRegularFilesManyCollidingNames(t *testing.T) {
 		tracker,
 		nil,
 		"",
+		"",
 	)
 	if err != nil {
 		t.Fatalf("ProcessRegularFiles returned error: %v", err)
@@ -178,6 +183,68 @@ func TestProcessRegularFilesManyCollidingNames(t *testing.T) {
 	}
 }
 
+func TestProcessRegularFilesPathCollision(t *testing.T) {
+	const dir = "tv/Show/Season 01"
+	const existing = dir + "/Show.S01E01.mkv"
+
+	tests := []struct {
+		policy      config.PathCollision
+		wantWritten []string
+		wantSource  string // source NZB of the metadata left at existing
+	}{
+		{config.PathCollisionOverwrite, []string{existing}, "new.nzb"},
+		{config.PathCollisionSkip, nil, "old.nzb"},
+		{config.PathCollisionVersion, []string{dir + "/Show.S01E01_1.mkv"}, "old.nzb"},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.policy), func(t *testing.T) {
+			ctx := context.Background()
+			metaRoot := t.TempDir()
+			svc := metadata.NewMetadataService(metaRoot)
+
+			old := svc.CreateFileMetadata(100, "old.nzb", metapb.FileStatus_FILE_STATUS_HEALTHY,
+				nil, metapb.Encryption_NONE, "", "", nil, nil, 0, nil, "")
+			if err := svc.WriteFileMetadata(existing, old); err != nil {
+				t.Fatalf("seed metadata: %v", err)
+			}
+
+			writtenPaths, err := ProcessRegularFiles(
+				ctx,
+				dir,
+				[]parser.ParsedFile{parsedTestFile("Show.S01E01.mkv", "seg")},
+				nil,
+				"new.nzb",
+				svc,
+				[]string{".mkv"},
+				true,
+				nil,
+				nil,
+				"",
+				tt.policy,
+			)
+			if err != nil {
+				t.Fatalf("ProcessRegularFiles returned error: %v", err)
+			}
+			if !slices.Equal(writtenPaths, tt.wantWritten) {
+				t.Fatalf("writtenPaths = %v, want %v", writtenPaths, tt.wantWritten)
+			}
+
+			meta, err := svc.ReadFileMetadata(existing)
+			if err != nil {
+				t.Fatalf("read existing metadata: %v", err)
+			}
+			if meta.SourceNzbPath != tt.wantSource {
+				t.Fatalf("existing file source = %q, want %q", meta.SourceNzbPath, tt.wantSource)
+			}
+			for _, p := range tt.wantWritten {
+				if !metadataExists(t, metaRoot, p) {
+					t.Fatalf("metadata not written to disk for %s", p)
+				}
+			}
+		})
+	}
+}
+
 // parsedTestFile creates a file where declared size matches segment bytes.
 func parsedTestFile(filename, segmentID string) parser.ParsedFile {
 	return parser.ParsedFile{
@@ -0,0 +1,278 @@
+package importer
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/javi11/nzbparser"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/javi11/altmount/internal/importer/archive"
+	"github.com/javi11/altmount/internal/importer/archive/rar"
+	"github.com/javi11/altmount/internal/importer/filesystem"
+	"github.com/javi11/altmount/internal/importer/parser"
+	"github.com/javi11/altmount/internal/metadata"
+	metapb "github.com/javi11/altmount/internal/metadata/proto"
+	"github.com/javi11/altmount/internal/nzbfile"
+)
+
+var (
+	// ErrNotArchiveImport is returned when re-analysis is requested for a file
+	// whose source NZB is not a RAR or 7z release.
+	ErrNotArchiveImport = errors.New("file was not imported from an archive")
+	// ErrNestedExtractionDisabled is returned when nested RAR extraction is
+	// turned off, which would make re-analysis reproduce the flat layout.
+	ErrNestedExtractionDisabled = errors.New("nested RAR extraction is disabled (import.allow_nested_rar_extraction)")
+	// ErrReanalysisNoMatch is returned when the re-analyzed archive has no
+	// entry matching the virtual file.
+	ErrReanalysisNoMatch = errors.New("no matching file in re-analyzed archive")
+)
+
+// NestedReanalysisOptions controls a nested-archive re-analysis run.
+type NestedReanalysisOptions struct {
+	// DryRun reports whether the layout would change without rewriting metadata.
+	DryRun bool
+	// Password overrides the archive password from the source NZB. Needed when
+	// the NZB is regenerated from its store, which does not keep the password.
+	Password string
+}
+
+// NestedReanalysisResult reports the outcome of re-analyzing one file.
+type NestedReanalysisResult struct {
+	VirtualPath string `json:"virtual_path"`
+	// Changed is true when the re-analyzed segment layout differs from the
+	// stored one.
+	Changed bool `json:"changed"`
+	// Updated is true when the metadata was rewritten (Changed and not DryRun).
+	Updated bool `json:"updated"`
+	// OldNestedSources and NewNestedSources count nested sources before and
+	// after; 0 means the file is served from flat segments.
+	OldNestedSources int `json:"old_nested_sources"`
+	NewNestedSources int `json:"new_nested_sources"`
+}
+
+// ReanalyzeNestedArchive re-runs archive analysis — including nested RAR
+// detection — against the source NZB of an already-imported file, and
+// rewrites its metadata when the resulting segment layout differs. This lets
+// files imported before a nested-RAR fix pick up the corrected layout without
+// a full re-import.
+func (proc *Processor) ReanalyzeNestedArchive(ctx context.Context, virtualPath string, opts NestedReanalysisOptions) (*NestedReanalysisResult, error) {
+	cfg := proc.configGetter()
+	if cfg.Import.AllowNestedRarExtraction != nil && !*cfg.Import.AllowNestedRarExtraction {
+		return nil, ErrNestedExtractionDisabled
+	}
+	if proc.poolManager == nil || !proc.poolManager.HasPool() {
+		return nil, fmt.Errorf("no NNTP providers configured")
+	}
+
+	oldMeta, err := proc.metadataService.ReadFileMetadata(virtualPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read metadata: %w", err)
+	}
+	if oldMeta == nil {
+		return nil, fmt.Errorf("metadata not found for %s", virtualPath)
+	}
+
+	parsed, err := proc.parseSourceNzb(ctx, oldMeta)
+	if err != nil {
+		return nil, err
+	}
+	if parsed.Type != parser.NzbTypeRarArchive && parsed.Type != parser.NzbType7zArchive {
+		return nil, ErrNotArchiveImport
+	}
+
+	password := opts.Password
+	if password == "" {
+		password = parsed.GetPassword()
+	}
+
+	_, archiveFiles, _ := filesystem.SeparateFiles(parsed.Files, parsed.Type)
+	if len(archiveFiles) == 0 {
+		return nil, ErrNotArchiveImport
+	}
+
+	analyzeCtx, cancel := context.WithTimeout(ctx, 10*time.Minute)
+	defer cancel()
+
+	var contents []archive.Content
+	if parsed.Type == parser.NzbTypeRarArchive {
+		for _, group := range rar.GroupArchivesByBaseName(archiveFiles) {
+			groupContents, err := proc.rarProcessor.AnalyzeRarContentFromNzb(analyzeCtx, group, password, nil)
+			if err != nil {
+				return nil, fmt.Errorf("failed to analyze RAR archive %q: %w", group[0].Filename, err)
+			}
+			contents = append(contents, groupContents...)
+		}
+	} else {
+		contents, err = proc.sevenZipProcessor.AnalyzeSevenZipContentFromNzb(analyzeCtx, archiveFiles, password, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to analyze 7zip archive: %w", err)
+		}
+	}
+
+	content, ok := matchReanalyzedContent(contents, virtualPath, oldMeta.FileSize)
+	if !ok {
+		return nil, ErrReanalysisNoMatch
+	}
+
+	newMeta := archive.NewFileMetadataFromContent(content, oldMeta.SourceNzbPath, oldMeta.ReleaseDate, oldMeta.NzbdavId)
+	newMeta.CreatedAt = oldMeta.CreatedAt
+	newMeta.Par2Files = oldMeta.Par2Files
+
+	result := &NestedReanalysisResult{
+		VirtualPath:      virtualPath,
+		OldNestedSources: len(oldMeta.NestedSources),
+		NewNestedSources: len(newMeta.NestedSources),
+	}
+
+	oldLayout, err := segmentLayoutDigest(oldMeta)
+	if err != nil {
+		return nil, err
+	}
+	// oldMeta already carries inherited .dirmeta credentials, so compare it
+	// against the effective new metadata rather than the bare analysis.
+	newLayout, err := segmentLayoutDigest(proc.metadataService.WithDirectoryDefaults(virtualPath, newMeta))
+	if err != nil {
+		return nil, err
+	}
+	result.Changed = oldLayout != newLayout
+	if !result.Changed || opts.DryRun {
+		return result, nil
+	}
+
+	// Known holes are indexed against the old segment layout and would point
+	// at the wrong data, so they are intentionally not carried over.
+	if err := proc.metadataService.WriteFileMetadataAuto(ctx, virtualPath, newMeta, parsed.SegmentIndex, oldMeta.StoreRef); err != nil {
+		return nil, fmt.Errorf("failed to write re-analyzed metadata: %w", err)
+	}
+	result.Updated = true
+
+	proc.log.InfoContext(ctx, "Re-analyzed nested archive layout",
+		"path", virtualPath,
+		"old_nested_sources", result.OldNestedSources,
+		"new_nested_sources", result.NewNestedSources)
+
+	return result, nil
+}
+
+// parseSourceNzb parses the NZB a file was imported from. The original NZB is
+// preferred because it keeps the archive password; store-backed metadata
+// falls back to regenerating the NZB from its store.
+func (proc *Processor) parseSourceNzb(ctx context.Context, meta *metapb.FileMetadata) (*parser.ParsedNzb, error) {
+	var r io.ReadCloser
+	nzbPath := meta.SourceNzbPath
+	if nzbPath != "" && !strings.HasSuffix(nzbPath, ".nzbz") {
+		if f, err := nzbfile.Open(nzbPath); err == nil {
+			r = f
+		}
+	}
+	if r == nil && meta.StoreRef != "" {
+		regen, err := proc.metadataService.Store().RegenerateNZB(meta.StoreRef)
+		if err != nil {
+			return nil, fmt.Errorf("failed to regenerate NZB from store: %w", err)
+		}
+		if regen != nil {
+			r = io.NopCloser(bytes.NewReader(regen))
+			nzbPath = meta.StoreRef
+		}
+	}
+	if r == nil {
+		return nil, fmt.Errorf("source NZB not available for re-analysis (source=%q, store=%q)", meta.SourceNzbPath, meta.StoreRef)
+	}
+	defer r.Close()
+
+	n, err := nzbparser.Parse(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse source NZB: %w", err)
+	}
+	parser.SanitizeNzbFilenames(n)
+
+	parsed, err := proc.parser.ParseNzb(ctx, n, nzbPath, nil, parser.ParseOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse source NZB: %w", err)
+	}
+	return parsed, nil
+}
+
+// matchReanalyzedContent finds the archive entry backing virtualPath: by file
+// name first, then — because imports may rename files to the release name —
+// by a unique size match.
+func matchReanalyzedContent(contents []archive.Content, virtualPath string, size int64) (archive.Content, bool) {
+	name := filepath.Base(virtualPath)
+	for _, c := range contents {
+		if c.IsDirectory {
+			continue
+		}
+		if strings.EqualFold(filepath.Base(c.Filename), name) ||
+			strings.EqualFold(filepath.Base(filepath.ToSlash(c.InternalPath)), name) {
+			return c, true
+		}
+	}
+
+	var match archive.Content
+	found := 0
+	for _, c := range contents {
+		if !c.IsDirectory && c.Size == size {
+			match = c
+			found++
+		}
+	}
+	return match, found == 1
+}
+
+// segmentLayoutDigest fingerprints the parts of a file's metadata that decide
+// which bytes are read from where: size, encryption, flat segments, and
+// nested sources (with shared outer sources resolved).
+func segmentLayoutDigest(meta *metapb.FileMetadata) (string, error) {
+	m := proto.Clone(meta).(*metapb.FileMetadata)
+	if err := metadata.ExpandSharedOuterSources(m); err != nil {
+		return "", err
+	}
+
+	h := sha256.New()
+	writeInt := func(v int64) { _ = binary.Write(h, binary.LittleEndian, v) }
+	writeBytes := func(b []byte) {
+		writeInt(int64(len(b)))
+		h.Write(b)
+	}
+	writeSegments := func(segs []*metapb.SegmentData) {
+		writeInt(int64(len(segs)))
+		for _, s := range segs {
+			writeBytes([]byte(s.Id))
+			writeInt(s.StartOffset)
+			writeInt(s.EndOffset)
+			writeInt(s.SegmentSize)
+		}
+	}
+
+	writeInt(m.FileSize)
+	writeInt(int64(m.Encryption))
+	writeBytes(m.AesKey)
+	writeBytes(m.AesIv)
+	writeSegments(m.SegmentData)
+	writeInt(int64(len(m.NestedSources)))
+	for _, ns := range m.NestedSources {
+		writeSegments(ns.Segments)
+		writeBytes(ns.AesKey)
+		writeBytes(ns.AesIv)
+		writeInt(ns.InnerOffset)
+		writeInt(ns.InnerLength)
+		writeInt(ns.InnerVolumeSize)
+		writeInt(int64(ns.Encryption))
+	}
+	return fmt.Sprintf("%x", h.Sum(nil)), nil
+}
+
+// ReanalyzeNestedArchive re-runs nested archive detection for an imported
+// file under operator control. See Processor.ReanalyzeNestedArchive.
+func (s *Service) ReanalyzeNestedArchive(ctx context.Context, virtualPath string, opts NestedReanalysisOptions) (*NestedReanalysisResult, error) {
+	return s.processor.ReanalyzeNestedArchive(ctx, virtualPath, opts)
+}
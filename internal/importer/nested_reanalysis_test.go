@@ -0,0 +1,127 @@
+package importer
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/javi11/altmount/internal/importer/archive/rar"
+	"github.com/javi11/altmount/internal/importer/parser"
+	metapb "github.com/javi11/altmount/internal/metadata/proto"
+	"github.com/javi11/altmount/internal/progress"
+	"github.com/javi11/altmount/internal/testsupport/nzbbuild"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// nestingRarProcessor wraps the real RAR processor and reports every file as
+// served through a nested source, standing in for an improved nested-RAR
+// detector that finds a layout the original import flattened.
+type nestingRarProcessor struct {
+	rar.Processor
+}
+
+func (p nestingRarProcessor) AnalyzeRarContentFromNzb(ctx context.Context, files []parser.ParsedFile, password string, tracker *progress.Tracker) ([]rar.Content, error) {
+	contents, err := p.Processor.AnalyzeRarContentFromNzb(ctx, files, password, tracker)
+	if err != nil {
+		return nil, err
+	}
+	for i, c := range contents {
+		if c.IsDirectory {
+			continue
+		}
+		contents[i].NestedSources = []rar.NestedSource{{
+			Segments:        c.Segments,
+			InnerLength:     c.Size,
+			InnerVolumeSize: c.Size,
+		}}
+		contents[i].Segments = nil
+	}
+	return contents, nil
+}
+
+func importFlatRarSingle(t *testing.T) (*batteryEnv, string) {
+	t.Helper()
+	entries := loadManifest(t, "rar_single")
+	env := newBatteryEnv(t)
+
+	rarBytes := loadFixture(t, filepath.Join("rar_single", "archive.rar"))
+	segs := env.registerContent("rar-reanalyze", rarBytes, archivePartSize, 1.0, nil)
+	nzb := nzbbuild.Build(nzbbuild.File{Subject: "archive.rar", Segments: segs})
+	_, _, err := env.runImport(nzb, "archive")
+	require.NoError(t, err)
+
+	inner := env.listDir("/archive")
+	require.NotEmpty(t, inner)
+	virtualPath := "/archive/" + inner[0]
+
+	meta := env.readMeta(virtualPath)
+	require.Equal(t, int64(entries[0].Size), meta.FileSize)
+	require.Empty(t, meta.NestedSources, "baseline import is flat")
+	require.NotEmpty(t, meta.SegmentData)
+	return env, virtualPath
+}
+
+func TestReanalyzeNestedArchive_CorrectsFlattenedLayout(t *testing.T) {
+	env, virtualPath := importFlatRarSingle(t)
+	env.proc.rarProcessor = nestingRarProcessor{Processor: env.proc.rarProcessor}
+	before := env.readMeta(virtualPath)
+
+	// A dry run reports the change without touching metadata.
+	res, err := env.proc.ReanalyzeNestedArchive(context.Background(), virtualPath, NestedReanalysisOptions{DryRun: true})
+	require.NoError(t, err)
+	assert.True(t, res.Changed)
+	assert.False(t, res.Updated)
+	assert.Empty(t, env.readMeta(virtualPath).NestedSources)
+
+	res, err = env.proc.ReanalyzeNestedArchive(context.Background(), virtualPath, NestedReanalysisOptions{})
+	require.NoError(t, err)
+	assert.True(t, res.Changed)
+	assert.True(t, res.Updated)
+	assert.Equal(t, 0, res.OldNestedSources)
+	assert.Equal(t, 1, res.NewNestedSources)
+
+	after := env.readMeta(virtualPath)
+	require.Len(t, after.NestedSources, 1)
+	assert.Empty(t, after.SegmentData)
+	assert.Equal(t, before.FileSize, after.FileSize)
+	assert.Equal(t, before.CreatedAt, after.CreatedAt)
+	assert.Equal(t, before.SourceNzbPath, after.SourceNzbPath)
+
+	// Once corrected, re-analysis is a no-op.
+	res, err = env.proc.ReanalyzeNestedArchive(context.Background(), virtualPath, NestedReanalysisOptions{})
+	require.NoError(t, err)
+	assert.False(t, res.Changed)
+	assert.False(t, res.Updated)
+}
+
+func TestReanalyzeNestedArchive_UnchangedLayout(t *testing.T) {
+	env, virtualPath := importFlatRarSingle(t)
+
+	res, err := env.proc.ReanalyzeNestedArchive(context.Background(), virtualPath, NestedReanalysisOptions{})
+	require.NoError(t, err)
+	assert.False(t, res.Changed)
+	assert.False(t, res.Updated)
+}
+
+func TestReanalyzeNestedArchive_InheritedDirMetaIsUnchanged(t *testing.T) {
+	env, virtualPath := importFlatRarSingle(t)
+	require.NoError(t, env.proc.metadataService.WriteDirectoryMetadata("archive", &metapb.FileMetadata{
+		AesKey: []byte("0123456789abcdef0123456789abcdef"),
+		AesIv:  []byte("0123456789abcdef"),
+	}))
+
+	res, err := env.proc.ReanalyzeNestedArchive(context.Background(), virtualPath, NestedReanalysisOptions{})
+	require.NoError(t, err)
+	assert.False(t, res.Changed, "a key inherited from .dirmeta is not a layout change")
+	assert.False(t, res.Updated)
+}
+
+func TestReanalyzeNestedArchive_DisabledByConfig(t *testing.T) {
+	env, virtualPath := importFlatRarSingle(t)
+	disabled := false
+	env.cfg.Import.AllowNestedRarExtraction = &disabled
+
+	_, err := env.proc.ReanalyzeNestedArchive(context.Background(), virtualPath, NestedReanalysisOptions{})
+	assert.ErrorIs(t, err, ErrNestedExtractionDisabled)
+}
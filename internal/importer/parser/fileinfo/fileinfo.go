@@ -22,17 +22,20 @@ var (
 
 // GetFileInfos extracts file information from NZB files with first segment data
 // Similar to C# GetFileInfosStep.GetFileInfos
+// preferPar2Names makes a PAR2 descriptor match win outright over every other
+// filename source, instead of competing with them on priority.
 func GetFileInfos(
 	files []*NzbFileWithFirstSegment,
 	par2Descriptors map[[16]byte]*par2.FileDescriptor,
 	nzbFilename string,
+	preferPar2Names bool,
 ) []*FileInfo {
 	// Strip .nzb extension for use as last-resort filename stem
 	nzbStem := nzbtrim.TrimNzbExtension(nzbFilename)
 
 	fileInfos := make([]*FileInfo, 0, len(files))
 	for _, file := range files {
-		info := getFileInfo(file, par2Descriptors, nzbStem)
+		info := getFileInfo(file, par2Descriptors, nzbStem, preferPar2Names)
 		fileInfos = append(fileInfos, info)
 	}
 
@@ -44,9 +47,11 @@ func getFileInfo(
 	file *NzbFileWithFirstSegment,
 	hashToDescMap map[[16]byte]*par2.FileDescriptor,
 	nzbFilenameStem string,
+	preferPar2Names bool,
 ) *FileInfo {
 	par2Filename := ""
 	par2FileSize := int64(0)
+	var par2MD5 []byte
 
 	if len(hashToDescMap) > 0 {
 		// Gap 1: PAR2 Hash16k is MD5 of the first 16384 bytes, zero-padded if file is shorter.
@@ -59,6 +64,7 @@ func getFileInfo(
 		if ok {
 			par2Filename = desc.Name
 			par2FileSize = int64(desc.Length)
+			par2MD5 = desc.FileMD5[:]
 		}
 	}
 
@@ -71,6 +77,9 @@ func getFileInfo(
 
 	// Select best filename using priority system (PAR2 > subject > yEnc header > subject header)
 	filename := selectBestFilename(par2Filename, subjectFilename, headerFilename, file.SubjectHeader)
+	if preferPar2Names && par2Filename != "" {
+		filename = par2Filename
+	}
 	fromPar2 := par2Filename != "" && filename == par2Filename
 
 	// Gap 4: Correct extension based on magic bytes when filename appears obfuscated.
@@ -121,6 +130,7 @@ func getFileInfo(
 		ReleaseDate:   file.ReleaseDate,
 		IsPar2Archive: isPar2Archive,
 		FileSize:      fileSize,
+		Par2MD5:       par2MD5,
 		IsRar:         isRar,
 		Is7z:          is7z,
 		YencHeaders:   file.Headers,
@@ -277,7 +277,7 @@ func TestGetFileInfo_Par2DetectionViaSubject(t *testing.T) {
 				},
 				First16KB: make([]byte, 16),
 			}
-			info := getFileInfo(file, nil, "nzb-stem")
+			info := getFileInfo(file, nil, "nzb-stem", false)
 			if info.IsPar2Archive != tt.wantIsPar2 {
 				t.Errorf("getFileInfo IsPar2Archive = %v, want %v (subject=%q header=%q selected=%q)",
 					info.IsPar2Archive, tt.wantIsPar2, tt.subjectFilename, tt.headerFilename, info.Filename)
@@ -307,7 +307,7 @@ func TestGetFileInfo_Par2NameSurvivesGap5(t *testing.T) {
 		First16KB: content,
 	}
 
-	info := getFileInfo(file, par2DescFor(content, "yay.rar"), "Example.Show.S01E01.1080p.WEB-DL-GRP")
+	info := getFileInfo(file, par2DescFor(content, "yay.rar"), "Example.Show.S01E01.1080p.WEB-DL-GRP", false)
 	if info.Filename != "yay.rar" {
 		t.Errorf("Filename = %q, want %q (PAR2 name was clobbered by Gap 5)", info.Filename, "yay.rar")
 	}
@@ -321,8 +321,51 @@ func TestGetFileInfo_Gap5StillFiresWithoutPar2(t *testing.T) {
 		First16KB: make([]byte, 16),
 	}
 
-	info := getFileInfo(file, nil, "My.Show.S01E01")
+	info := getFileInfo(file, nil, "My.Show.S01E01", false)
 	if info.Filename != "My.Show.S01E01" {
 		t.Errorf("Filename = %q, want %q (Gap 5 should still substitute the NZB stem)", info.Filename, "My.Show.S01E01")
 	}
 }
+
+// TestGetFileInfo_PreferPar2Names covers Import.UsePar2Names: a PAR2 match
+// normally competes on priority and loses to a clean subject when its own name
+// looks obfuscated, but with preferPar2Names it wins outright.
+func TestGetFileInfo_PreferPar2Names(t *testing.T) {
+	content := []byte("video payload")
+	file := &NzbFileWithFirstSegment{
+		NzbFile:   &nzbparser.NzbFile{Filename: "Holiday.Video.2019.mkv"},
+		First16KB: content,
+	}
+	desc := par2DescFor(content, "yay.mkv")
+
+	if info := getFileInfo(file, desc, "", false); info.Filename != "Holiday.Video.2019.mkv" {
+		t.Errorf("Filename = %q, want the subject name without preferPar2Names", info.Filename)
+	}
+	if info := getFileInfo(file, desc, "", true); info.Filename != "yay.mkv" {
+		t.Errorf("Filename = %q, want the PAR2 name with preferPar2Names", info.Filename)
+	}
+}
+
+// TestGetFileInfo_Par2MD5 checks that a PAR2 match carries the whole-file MD5
+// through for read-time verification, and that no match leaves it unset.
+func TestGetFileInfo_Par2MD5(t *testing.T) {
+	content := []byte("video payload")
+	file := &NzbFileWithFirstSegment{
+		NzbFile:   &nzbparser.NzbFile{Filename: "Movie.mkv"},
+		First16KB: content,
+	}
+	desc := par2DescFor(content, "Movie.mkv")
+	for _, d := range desc {
+		d.FileMD5 = md5.Sum(content)
+	}
+
+	info := getFileInfo(file, desc, "", false)
+	if want := md5.Sum(content); string(info.Par2MD5) != string(want[:]) {
+		t.Errorf("Par2MD5 = %x, want %x", info.Par2MD5, want)
+	}
+
+	info = getFileInfo(file, nil, "", false)
+	if info.Par2MD5 != nil {
+		t.Errorf("Par2MD5 = %x without a PAR2 match, want nil", info.Par2MD5)
+	}
+}
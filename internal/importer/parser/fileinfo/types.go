@@ -26,6 +26,7 @@ type FileInfo struct {
 	Filename      string             // Selected filename (using priority system)
 	ReleaseDate   time.Time          // Release date from NZB metadata
 	FileSize      *int64             // File size (from PAR2 or yEnc headers, nil if unknown)
+	Par2MD5       []byte             // MD5 of the whole file from its PAR2 description (nil if unmatched)
 	IsRar         bool               // Whether this is a RAR archive (detected by magic or extension)
 	Is7z          bool               // Whether this is a 7z archive (detected by extension)
 	IsPar2Archive bool               // Whether this is a PAR2 archive (detected by extension)
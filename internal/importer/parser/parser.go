@@ -132,6 +132,10 @@ func (p *Parser) ParseNzb(ctx context.Context, n *nzbparser.Nzb, nzbPath string,
 		Files:    make([]ParsedFile, 0, len(n.Files)),
 	}
 
+	// Segments must be in file order before anything picks a "first" segment
+	// or assigns store indices.
+	orderNzbSegments(ctx, n)
+
 	// Build the shared NzbStore and segment index for v3 format.
 	// Must be built from the raw *nzbparser.Nzb BEFORE any per-file processing
 	// (which may filter/reorder files). The index maps message-id → flat store index.
@@ -157,11 +161,12 @@ func (p *Parser) ParseNzb(ctx context.Context, n *nzbparser.Nzb, nzbPath string,
 	// or a member of a .partNN.rar set whose volumes all have distinct (obfuscated) bases
 	// (hasObfuscatedVolumeSet). When every name is already clean, downloading the PAR2
 	// index and completing files to 16KB would only confirm what we already trust — skip
-	// both entirely.
+	// both entirely. Import.UsePar2Names distrusts clean names too, so it always matches.
+	usePar2Names := p.usePar2Names()
 	par2MatchingUseful := p.hasPar2IndexCandidate(firstSegmentCache) &&
-		(anyFileNeedsPar2Matching(firstSegmentCache) || hasObfuscatedVolumeSet(firstSegmentCache))
+		(usePar2Names || anyFileNeedsPar2Matching(firstSegmentCache) || hasObfuscatedVolumeSet(firstSegmentCache))
 	if par2MatchingUseful {
-		p.complete16KBReads(ctx, firstSegmentCache, notFoundIDs)
+		p.complete16KBReads(ctx, firstSegmentCache, notFoundIDs, usePar2Names)
 	}
 
 	// Create a map of first segment ID to yEnc info for optimization in normalizeSegmentSizesWithYenc.
@@ -292,7 +297,7 @@ func (p *Parser) ParseNzb(ctx context.Context, n *nzbparser.Nzb, nzbPath string,
 
 	// Get file infos with priority-based filename selection
 	// GetFileInfos processes ALL files including PAR2 files; SeparateFiles handles the split
-	fileInfos := fileinfo.GetFileInfos(filesWithFirstSegment, par2Descriptors, parsed.Filename)
+	fileInfos := fileinfo.GetFileInfos(filesWithFirstSegment, par2Descriptors, parsed.Filename, usePar2Names)
 	if len(fileInfos) == 0 {
 		p.log.WarnContext(ctx, "Failed to get file infos from network, falling back to NZB XML data",
 			"nzb_path", nzbPath)
@@ -385,16 +390,22 @@ func (p *Parser) parseFile(ctx context.Context, meta map[string]string, nzbFilen
 		return nil, fmt.Errorf("file has no segments")
 	}
 
-	sort.Sort(info.NzbFile.Segments)
+	segs, err := validateSegmentOrder(ctx, info.Filename, info.NzbFile.Segments)
+	if err != nil {
+		return nil, fmt.Errorf("invalid segment layout for %q: %w", info.Filename, err)
+	}
+	info.NzbFile.Segments = segs
 
 	// Normalize segment sizes using yEnc PartSize headers if needed
 	// This handles cases where NZB segment sizes include yEnc encoding overhead
+	var lastPartJunk int64
 	if p.poolManager != nil && p.poolManager.HasPool() {
 		// Look up cached first segment yEnc info to avoid redundant fetching
 		// Safe to access Segments[0] since files without segments are filtered earlier
 		cachedFirstSegment := firstSegmentSizeCache[info.NzbFile.Segments[0].ID]
 
-		err := p.normalizeSegmentSizesWithYenc(ctx, info.NzbFile.Segments, cachedFirstSegment, nzbStandardPartSize, notFoundIDs)
+		junk, err := p.normalizeSegmentSizesWithYenc(ctx, info.NzbFile.Segments, cachedFirstSegment, nzbStandardPartSize, notFoundIDs)
+		lastPartJunk = junk
 		if err != nil {
 			if stderrors.Is(err, nntppool.ErrArticleNotFound) {
 				// A segment required to determine the real (decoded) sizes is missing
@@ -425,6 +436,13 @@ func (p *Parser) parseFile(ctx context.Context, meta map[string]string, nzbFilen
 			SegmentSize: int64(seg.Bytes),
 		}
 	}
+	// A last part that repeats the tail of the one before it decodes to more
+	// bytes than it adds; its new data starts after the repeated ones.
+	if lastPartJunk > 0 {
+		last := segments[len(segments)-1]
+		last.UsableStart = lastPartJunk
+		last.SegmentSize += lastPartJunk
+	}
 
 	// Also build SegmentRefs for v3 store-based format
 	var segmentRefs []*metapb.SegmentRef
@@ -437,6 +455,11 @@ func (p *Parser) parseFile(ctx context.Context, meta map[string]string, nzbFilen
 				EndOffset:   int64(seg.Bytes - 1),
 			}
 		}
+		if lastPartJunk > 0 {
+			last := segmentRefs[len(segmentRefs)-1]
+			last.UsableStart = lastPartJunk
+			last.DecodedBytes = segments[len(segments)-1].SegmentSize
+		}
 	}
 
 	// Get file size from fileInfo (priority-based: PAR2 > yEnc headers)
@@ -573,6 +596,12 @@ func (p *Parser) parseFile(ctx context.Context, meta map[string]string, nzbFilen
 		NzbdavID:      nzbdavID,
 	}
 
+	// The PAR2 checksum covers the PAR2-described length only; once the size
+	// has been corrected from the segments it no longer describes this file.
+	if info.FileSize != nil && totalSize == *info.FileSize {
+		parsedFile.ContentMD5 = info.Par2MD5
+	}
+
 	// Attach the warm first-segment bytes (decoded leading payload at offset 0)
 	// so the archive analysis phase can serve this file's header read from memory.
 	// Keyed by the same first-segment ID domain as firstSegmentSizeCache.
@@ -696,6 +725,9 @@ func (p *Parser) fetchAllFirstSegments(ctx context.Context, files []nzbparser.Nz
 		return cache, notFoundIDs, nil
 	}
 
+	// PAR2 naming hashes every file's first 16KB, so no fetch may be skipped.
+	usePar2Names := p.usePar2Names()
+
 	cp, err := p.poolManager.GetPool()
 	if err != nil {
 		p.log.DebugContext(context.Background(), "Failed to get connection pool for first segment fetching", "error", err)
@@ -768,7 +800,7 @@ func (p *Parser) fetchAllFirstSegments(ctx context.Context, files []nzbparser.Nz
 			// first-segment size is filled in later from the NZB-wide representative
 			// part size; naming/type come from the subject. Saves one full-segment
 			// transfer per clean-named multipart file.
-			if shouldSkipFirstSegmentFetch(fileToFetch) {
+			if !usePar2Names && shouldSkipFirstSegmentFetch(fileToFetch) {
 				return fetchResult{
 					segmentID: fileToFetch.Segments[0].ID,
 					data: &FirstSegmentData{
@@ -965,6 +997,19 @@ func isPar2SidecarExtension(filename string) bool {
 // philosophy as shouldSkipFirstSegmentFetch. Skipped/missing files have no first-16KB
 // bytes to hash and can never be matched; PAR2 files describe others, not themselves.
 func needsPar2Matching(d *FirstSegmentData) bool {
+	if !par2Matchable(d) {
+		return false
+	}
+	// An empty, extension-less, or obfuscated name is exactly what PAR2
+	// descriptors can recover. Anything else is trusted as-is.
+	name := d.File.Filename
+	return name == "" || !fileinfo.HasValidExtensionLength(name) || fileinfo.IsProbablyObfuscated(name)
+}
+
+// par2Matchable reports whether a file could be matched against PAR2
+// descriptors at all, whatever its name: it has fetched first-segment bytes to
+// hash and is neither a PAR2 file nor a small sidecar.
+func par2Matchable(d *FirstSegmentData) bool {
 	if d == nil || d.File == nil || d.MissingFirstSegment || d.SkippedFirstSegment {
 		return false
 	}
@@ -972,12 +1017,13 @@ func needsPar2Matching(d *FirstSegmentData) bool {
 		return false
 	}
 	name := d.File.Filename
-	if fileinfo.IsPar2File(name) || isPar2SidecarExtension(name) {
-		return false
-	}
-	// An empty, extension-less, or obfuscated name is exactly what PAR2
-	// descriptors can recover. Anything else is trusted as-is.
-	return name == "" || !fileinfo.HasValidExtensionLength(name) || fileinfo.IsProbablyObfuscated(name)
+	return !fileinfo.IsPar2File(name) && !isPar2SidecarExtension(name)
+}
+
+// usePar2Names reports whether Import.UsePar2Names is enabled.
+func (p *Parser) usePar2Names() bool {
+	v := p.getConfig().Import.UsePar2Names
+	return v != nil && *v
 }
 
 // anyFileNeedsPar2Matching reports whether at least one file in the NZB would
@@ -1028,10 +1074,14 @@ func hasObfuscatedVolumeSet(cache []*FirstSegmentData) bool {
 
 // needs16KBCompletion decides whether a file is worth completing up to 16KB
 // from additional segments. Only files that need PAR2 matching (untrustworthy
-// names) qualify — clean names are trusted and sidecars/PAR2 files never benefit
-// from Hash16k matching.
-func needs16KBCompletion(d *FirstSegmentData, maxRead int) bool {
-	if !needsPar2Matching(d) {
+// names) qualify — clean names are trusted unless matchAll is set, and
+// sidecars/PAR2 files never benefit from Hash16k matching.
+func needs16KBCompletion(d *FirstSegmentData, maxRead int, matchAll bool) bool {
+	if matchAll {
+		if !par2Matchable(d) {
+			return false
+		}
+	} else if !needsPar2Matching(d) {
 		return false
 	}
 	if len(d.RawBytes) >= maxRead {
@@ -1046,8 +1096,9 @@ func needs16KBCompletion(d *FirstSegmentData, maxRead int) bool {
 // complete16KBReads fetches additional segments for files whose first segment
 // returned less than 16KB. Only called when the NZB actually contains PAR2
 // descriptors that could match the resulting MD5(first16KB). Best-effort:
-// missing or failed segments leave RawBytes as-is.
-func (p *Parser) complete16KBReads(ctx context.Context, cache []*FirstSegmentData, notFoundIDs map[string]struct{}) {
+// missing or failed segments leave RawBytes as-is. matchAll completes every
+// matchable file, not just those with untrustworthy names.
+func (p *Parser) complete16KBReads(ctx context.Context, cache []*FirstSegmentData, notFoundIDs map[string]struct{}, matchAll bool) {
 	const maxRead = 16 * 1024
 	if p.poolManager == nil || !p.poolManager.HasPool() {
 		return
@@ -1059,7 +1110,7 @@ func (p *Parser) complete16KBReads(ctx context.Context, cache []*FirstSegmentDat
 
 	var targets []*FirstSegmentData
 	for _, d := range cache {
-		if needs16KBCompletion(d, maxRead) {
+		if needs16KBCompletion(d, maxRead, matchAll) {
 			targets = append(targets, d)
 		}
 	}
@@ -1234,18 +1285,21 @@ func deriveLastPartSize(fileSize, firstPartSize, standardPartSize int64, numSegm
 // nzbStandardPartSize, when >0, is a representative middle-segment PartSize shared across the NZB;
 // passing it here skips the per-file second-segment network call for files with 3+ segments.
 // notFoundIDs is the set of segment IDs known to return 430; those are skipped without a network call.
-func (p *Parser) normalizeSegmentSizesWithYenc(ctx context.Context, segments []nzbparser.NzbSegment, firstSegment firstSegmentYencInfo, nzbStandardPartSize int64, notFoundIDs map[string]struct{}) error {
+// When the last part's headers are fetched and show it repeating the tail of the part before
+// it, its size is set to the new data only and the repeated byte count is returned (see
+// partOverlap); it is 0 otherwise.
+func (p *Parser) normalizeSegmentSizesWithYenc(ctx context.Context, segments []nzbparser.NzbSegment, firstSegment firstSegmentYencInfo, nzbStandardPartSize int64, notFoundIDs map[string]struct{}) (int64, error) {
 	firstPartSize := firstSegment.PartSize
 	fileSize := firstSegment.FileSize
 	if firstPartSize <= 0 {
 		if _, known404 := notFoundIDs[segments[0].ID]; known404 {
-			return fmt.Errorf("first segment %s is known not found: %w", segments[0].ID, nntppool.ErrArticleNotFound)
+			return 0, fmt.Errorf("first segment %s is known not found: %w", segments[0].ID, nntppool.ErrArticleNotFound)
 		}
 		// Fetch PartSize from first segment if not in cache. The same headers carry the
 		// total file size, which enables last-part derivation below.
 		firstPartHeaders, err := p.fetchYencHeaders(ctx, segments[0], nil)
 		if err != nil {
-			return fmt.Errorf("failed to fetch first segment yEnc part size: %w", err)
+			return 0, fmt.Errorf("failed to fetch first segment yEnc part size: %w", err)
 		}
 		firstPartSize = firstPartHeaders.PartSize
 		if fileSize <= 0 {
@@ -1255,7 +1309,7 @@ func (p *Parser) normalizeSegmentSizesWithYenc(ctx context.Context, segments []n
 
 	if len(segments) == 1 {
 		segments[0].Bytes = int(firstPartSize)
-		return nil
+		return 0, nil
 	}
 
 	// Handle files with exactly 2 segments (first and last only)
@@ -1264,20 +1318,21 @@ func (p *Parser) normalizeSegmentSizesWithYenc(ctx context.Context, segments []n
 
 		if last, ok := deriveLastPartSize(fileSize, firstPartSize, 0, 2); ok {
 			segments[1].Bytes = int(last)
-			return nil
+			return 0, nil
 		}
 
 		if _, known404 := notFoundIDs[segments[1].ID]; known404 {
-			return fmt.Errorf("second segment %s is known not found: %w", segments[1].ID, nntppool.ErrArticleNotFound)
+			return 0, fmt.Errorf("second segment %s is known not found: %w", segments[1].ID, nntppool.ErrArticleNotFound)
 		}
 		// Fetch PartSize from last segment
 		lastPartHeaders, err := p.fetchYencHeaders(ctx, segments[1], nil)
 		if err != nil {
-			return fmt.Errorf("failed to fetch last segment yEnc part size: %w", err)
+			return 0, fmt.Errorf("failed to fetch last segment yEnc part size: %w", err)
 		}
-		segments[1].Bytes = int(lastPartHeaders.PartSize)
+		junk := partOverlap(lastPartHeaders, firstPartSize)
+		segments[1].Bytes = int(lastPartHeaders.PartSize - junk)
 
-		return nil
+		return junk, nil
 	}
 
 	// Determine the standard (middle-segment) part size and the actual last-segment size.
@@ -1288,17 +1343,18 @@ func (p *Parser) normalizeSegmentSizesWithYenc(ctx context.Context, segments []n
 
 	standardPartSize := nzbStandardPartSize
 	var lastPartSize int64
+	var lastPartHeaders nntppool.YEncMeta // set only when the last part's headers were fetched
 
 	if standardPartSize <= 0 && fileSize <= 0 {
 		// Neither a shared part size nor a total file size: both the second and last
 		// segments must be fetched — do it in parallel as before.
 		if _, known404 := notFoundIDs[segments[1].ID]; known404 {
-			return fmt.Errorf("second segment %s is known not found: %w", segments[1].ID, nntppool.ErrArticleNotFound)
+			return 0, fmt.Errorf("second segment %s is known not found: %w", segments[1].ID, nntppool.ErrArticleNotFound)
 		}
 		if _, known404 := notFoundIDs[segments[lastSegmentIndex].ID]; known404 {
-			return fmt.Errorf("last segment %s is known not found: %w", segments[lastSegmentIndex].ID, nntppool.ErrArticleNotFound)
+			return 0, fmt.Errorf("last segment %s is known not found: %w", segments[lastSegmentIndex].ID, nntppool.ErrArticleNotFound)
 		}
-		var secondPartHeaders, lastPartHeaders nntppool.YEncMeta
+		var secondPartHeaders nntppool.YEncMeta
 		g, gctx := errgroup.WithContext(ctx)
 		g.Go(func() error {
 			h, err := p.fetchYencHeaders(gctx, segments[1], nil)
@@ -1317,7 +1373,7 @@ func (p *Parser) normalizeSegmentSizesWithYenc(ctx context.Context, segments []n
 			return nil
 		})
 		if err := g.Wait(); err != nil {
-			return err
+			return 0, err
 		}
 		standardPartSize = secondPartHeaders.PartSize
 		lastPartSize = lastPartHeaders.PartSize
@@ -1325,11 +1381,11 @@ func (p *Parser) normalizeSegmentSizesWithYenc(ctx context.Context, segments []n
 		if standardPartSize <= 0 {
 			// No NZB-wide representative — fetch this file's second segment once.
 			if _, known404 := notFoundIDs[segments[1].ID]; known404 {
-				return fmt.Errorf("second segment %s is known not found: %w", segments[1].ID, nntppool.ErrArticleNotFound)
+				return 0, fmt.Errorf("second segment %s is known not found: %w", segments[1].ID, nntppool.ErrArticleNotFound)
 			}
 			h, err := p.fetchYencHeaders(ctx, segments[1], nil)
 			if err != nil {
-				return fmt.Errorf("failed to fetch second segment yEnc part size: %w", err)
+				return 0, fmt.Errorf("failed to fetch second segment yEnc part size: %w", err)
 			}
 			standardPartSize = h.PartSize
 		}
@@ -1338,12 +1394,13 @@ func (p *Parser) normalizeSegmentSizesWithYenc(ctx context.Context, segments []n
 			lastPartSize = last
 		} else {
 			if _, known404 := notFoundIDs[segments[lastSegmentIndex].ID]; known404 {
-				return fmt.Errorf("last segment %s is known not found: %w", segments[lastSegmentIndex].ID, nntppool.ErrArticleNotFound)
+				return 0, fmt.Errorf("last segment %s is known not found: %w", segments[lastSegmentIndex].ID, nntppool.ErrArticleNotFound)
 			}
 			h, err := p.fetchYencHeaders(ctx, segments[lastSegmentIndex], nil)
 			if err != nil {
-				return fmt.Errorf("failed to fetch last segment yEnc part size: %w", err)
+				return 0, fmt.Errorf("failed to fetch last segment yEnc part size: %w", err)
 			}
+			lastPartHeaders = h
 			lastPartSize = h.PartSize
 		}
 	}
@@ -1357,10 +1414,28 @@ func (p *Parser) normalizeSegmentSizesWithYenc(ctx context.Context, segments []n
 		segments[i].Bytes = int(standardPartSize)
 	}
 
-	// - Last segment: use its actual (fetched or derived) size
-	segments[lastSegmentIndex].Bytes = int(lastPartSize)
+	// - Last segment: use its actual (fetched or derived) size, less any bytes it repeats
+	junk := partOverlap(lastPartHeaders, firstPartSize+int64(len(segments)-2)*standardPartSize)
+	segments[lastSegmentIndex].Bytes = int(lastPartSize - junk)
 
-	return nil
+	return junk, nil
+}
+
+// partOverlap returns how many leading bytes of a part repeat data the parts before it
+// already carry: the distance between where its =ypart header says it begins and
+// expectedBegin, where the earlier parts end. Some posters re-send the tail of the previous
+// part at the start of the next; read naively, those bytes would be served twice and shift
+// everything after them. Returns 0 when the part does not overlap or its header carries no
+// usable part position.
+func partOverlap(h nntppool.YEncMeta, expectedBegin int64) int64 {
+	if h.Part <= 1 || h.PartBegin >= expectedBegin {
+		return 0
+	}
+	overlap := expectedBegin - h.PartBegin
+	if overlap >= h.PartSize {
+		return 0
+	}
+	return overlap
 }
 
 // fallbackGetFileInfos is a "dumb" fallback that extracts file info directly from NZB XML
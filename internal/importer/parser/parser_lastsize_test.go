@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/javi11/altmount/internal/testsupport/fakepool"
+	"github.com/javi11/nntppool/v4"
 	"github.com/javi11/nzbparser"
 )
 
@@ -101,7 +102,7 @@ func TestNormalizeSegmentSizes_DerivesLastWithoutFetch(t *testing.T) {
 	segs := normalizeSegs(4)
 	first := firstSegmentYencInfo{PartSize: 700000, FileSize: 700000*3 + 120000}
 
-	err := p.normalizeSegmentSizesWithYenc(context.Background(), segs, first, 700000, nil)
+	_, err := p.normalizeSegmentSizesWithYenc(context.Background(), segs, first, 700000, nil)
 	if err != nil {
 		t.Fatalf("normalize returned error: %v", err)
 	}
@@ -124,7 +125,7 @@ func TestNormalizeSegmentSizes_TwoSegmentDerive(t *testing.T) {
 	segs := normalizeSegs(2)
 	first := firstSegmentYencInfo{PartSize: 700000, FileSize: 700000 + 50000}
 
-	err := p.normalizeSegmentSizesWithYenc(context.Background(), segs, first, 0, nil)
+	_, err := p.normalizeSegmentSizesWithYenc(context.Background(), segs, first, 0, nil)
 	if err != nil {
 		t.Fatalf("normalize returned error: %v", err)
 	}
@@ -146,7 +147,7 @@ func TestNormalizeSegmentSizes_FallsBackToFetchWhenFileSizeUnknown(t *testing.T)
 
 	// The fake pool yields no yEnc headers, so the fetch attempt fails — what
 	// matters here is that the fetch WAS attempted (BodyAsync issued).
-	_ = p.normalizeSegmentSizesWithYenc(context.Background(), segs, first, 700000, nil)
+	_, _ = p.normalizeSegmentSizesWithYenc(context.Background(), segs, first, 700000, nil)
 	if got := fp.BodyAsyncCalls(); got == 0 {
 		t.Fatal("BodyAsyncCalls = 0, want a last-segment fetch fallback when FileSize is unknown")
 	}
@@ -161,8 +162,59 @@ func TestNormalizeSegmentSizes_FallsBackToFetchOnInsaneDerivation(t *testing.T)
 	// FileSize wildly larger than the parts can account for → derivation rejected.
 	first := firstSegmentYencInfo{PartSize: 700000, FileSize: 700000 * 100}
 
-	_ = p.normalizeSegmentSizesWithYenc(context.Background(), segs, first, 700000, nil)
+	_, _ = p.normalizeSegmentSizesWithYenc(context.Background(), segs, first, 700000, nil)
 	if got := fp.BodyAsyncCalls(); got == 0 {
 		t.Fatal("BodyAsyncCalls = 0, want a last-segment fetch fallback on insane derivation")
 	}
 }
+
+func TestNormalizeSegmentSizes_LastPartOverlap(t *testing.T) {
+	tests := []struct {
+		name      string
+		numSegs   int
+		partBegin int64
+		partSize  int64
+		wantJunk  int64
+		wantLast  int
+	}{
+		{
+			name: "repeats the previous tail", numSegs: 4,
+			partBegin: 700000*3 - 4096, partSize: 120000 + 4096,
+			wantJunk: 4096, wantLast: 120000,
+		},
+		{
+			name: "starts where expected", numSegs: 4,
+			partBegin: 700000 * 3, partSize: 120000,
+			wantJunk: 0, wantLast: 120000,
+		},
+		{
+			name: "two segments", numSegs: 2,
+			partBegin: 700000 - 100, partSize: 50000 + 100,
+			wantJunk: 100, wantLast: 50000,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			fp := fakepool.New()
+			fp.SetBehavior("norm-seg", fakepool.SegmentBehavior{YEnc: nntppool.YEncMeta{
+				Part: int64(tc.numSegs), PartBegin: tc.partBegin, PartSize: tc.partSize,
+			}})
+			p := NewParser(newFakeFullPoolManager(fp), stormConfigGetter(4))
+
+			segs := normalizeSegs(tc.numSegs)
+			// No file size, so the last part's headers are fetched.
+			first := firstSegmentYencInfo{PartSize: 700000}
+
+			junk, err := p.normalizeSegmentSizesWithYenc(context.Background(), segs, first, 700000, nil)
+			if err != nil {
+				t.Fatalf("normalize returned error: %v", err)
+			}
+			if junk != tc.wantJunk {
+				t.Errorf("junk = %d, want %d", junk, tc.wantJunk)
+			}
+			if got := segs[len(segs)-1].Bytes; got != tc.wantLast {
+				t.Errorf("last segment size = %d, want %d", got, tc.wantLast)
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package parser
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/javi11/altmount/internal/config"
+	"github.com/javi11/altmount/internal/testsupport/fakepool"
+	"github.com/javi11/altmount/internal/testsupport/par2gen"
+	"github.com/javi11/nntppool/v4"
+	"github.com/javi11/nzbparser"
+)
+
+// par2NamesNzb builds an NZB whose video files carry clean-looking but wrong
+// names, plus a PAR2 index holding the real ones. It returns the NZB and the
+// real names by posted name.
+func par2NamesNzb(fp *fakepool.Client) (*nzbparser.Nzb, map[string]string) {
+	const partSize = 20_000 // > 16 KB so Hash16k comes from real bytes
+	posted := []string{"Holiday.Video.2019.mkv", "Garden.Party.2018.mkv"}
+	realNames := []string{"Real.Movie.2020.1080p.WEB-DL.mkv", "Real.Movie.2020.1080p.WEB-DL.Extras.mkv"}
+
+	var files nzbparser.NzbFiles
+	var entries []par2gen.FileEntry
+	names := make(map[string]string)
+	for i, name := range posted {
+		content := bytes.Repeat([]byte{byte('A' + i)}, 3*partSize)
+		var segs nzbparser.NzbSegments
+		for s := range 3 {
+			id := fmt.Sprintf("file-%d-seg%d", i, s)
+			fp.SetBehavior(id, fakepool.SegmentBehavior{
+				Bytes: content[s*partSize : (s+1)*partSize],
+				YEnc:  nntppool.YEncMeta{FileName: name, PartSize: partSize, FileSize: int64(len(content))},
+			})
+			segs = append(segs, nzbparser.NzbSegment{Bytes: partSize, Number: s + 1, ID: id})
+		}
+		files = append(files, nzbparser.NzbFile{Filename: name, Segments: segs})
+		entries = append(entries, par2gen.FileEntry{Name: realNames[i], Content: content})
+		names[name] = realNames[i]
+	}
+
+	par2Bytes := par2gen.Build(entries...)
+	fp.SetBehavior("par2-seg0", fakepool.SegmentBehavior{Bytes: par2Bytes})
+	files = append(files, nzbparser.NzbFile{
+		Filename: "Holiday.Video.2019.par2",
+		Segments: nzbparser.NzbSegments{{Bytes: len(par2Bytes), Number: 1, ID: "par2-seg0"}},
+	})
+	return &nzbparser.Nzb{Files: files}, names
+}
+
+func par2NamesConfigGetter(enabled bool) config.ConfigGetter {
+	cfg := stormConfigGetter(4)()
+	cfg.Import.UsePar2Names = &enabled
+	return func() *config.Config { return cfg }
+}
+
+// TestParseNzbUsePar2NamesRenamesCleanLookingFiles verifies that with
+// Import.UsePar2Names the parser recovers real names from the PAR2 index even
+// when the posted names look trustworthy, and that without it those names are
+// kept and the PAR2 index is never downloaded.
+func TestParseNzbUsePar2NamesRenamesCleanLookingFiles(t *testing.T) {
+	t.Run("enabled", func(t *testing.T) {
+		fp := fakepool.New()
+		n, names := par2NamesNzb(fp)
+		p := NewParser(newFakeFullPoolManager(fp), par2NamesConfigGetter(true))
+
+		parsed, err := p.ParseNzb(context.Background(), n, "test.nzb", nil, ParseOptions{})
+		if err != nil {
+			t.Fatalf("ParseNzb error = %v", err)
+		}
+		got := make(map[string]bool)
+		for _, f := range parsed.Files {
+			got[f.Filename] = true
+		}
+		for posted, realName := range names {
+			if !got[realName] {
+				t.Errorf("%q was not renamed to its PAR2 name %q; files = %v", posted, realName, got)
+			}
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		fp := fakepool.New()
+		n, names := par2NamesNzb(fp)
+		p := NewParser(newFakeFullPoolManager(fp), par2NamesConfigGetter(false))
+
+		parsed, err := p.ParseNzb(context.Background(), n, "test.nzb", nil, ParseOptions{})
+		if err != nil {
+			t.Fatalf("ParseNzb error = %v", err)
+		}
+		got := make(map[string]bool)
+		for _, f := range parsed.Files {
+			got[f.Filename] = true
+		}
+		for posted := range names {
+			if !got[posted] {
+				t.Errorf("%q should keep its posted name without UsePar2Names; files = %v", posted, got)
+			}
+		}
+		if calls := fp.PerMessageCalls("file-0-seg0"); calls != 0 {
+			t.Errorf("first segment of a clean-named video fetched %d times, want 0 (skip gate)", calls)
+		}
+	})
+}
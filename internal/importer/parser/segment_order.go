@@ -0,0 +1,68 @@
+package parser
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+
+	"github.com/javi11/nzbparser"
+)
+
+// orderNzbSegments puts every file's segments into ascending part-number
+// order. nzbparser sorts what it parses itself, but NZBs handed over already
+// built (or hand-written by other producers) may list segments interleaved,
+// and every offset computed downstream assumes file order. The sort is stable
+// so segments without part numbers keep their listed order.
+func orderNzbSegments(ctx context.Context, n *nzbparser.Nzb) {
+	for i := range n.Files {
+		segs := n.Files[i].Segments
+		if sort.IsSorted(segs) {
+			continue
+		}
+		sort.Stable(segs)
+		slog.WarnContext(ctx, "NZB file segments were out of order, sorted by part number",
+			"file", n.Files[i].Filename,
+			"segments", len(segs))
+	}
+}
+
+// validateSegmentOrder checks that segments are in ascending part order and
+// drops repeated parts. When two articles claim the same part number the first
+// one listed is kept: a second copy (a repost, or a different article posted
+// under the same number) would shift every later offset by a segment. Repeats
+// are logged and skipped rather than failing the file. Segments without a
+// part number (0) cannot be checked and are kept as listed.
+func validateSegmentOrder(ctx context.Context, filename string, segs nzbparser.NzbSegments) (nzbparser.NzbSegments, error) {
+	var prev nzbparser.NzbSegment
+	var kept nzbparser.NzbSegments
+	for i, seg := range segs {
+		if i > 0 && seg.Number > 0 && prev.Number > 0 {
+			if seg.Number < prev.Number {
+				return nil, fmt.Errorf("segment %s (part %d) follows part %d: segments are not in file order",
+					seg.ID, seg.Number, prev.Number)
+			}
+			if seg.Number == prev.Number {
+				if kept == nil {
+					kept = append(make(nzbparser.NzbSegments, 0, len(segs)-1), segs[:i]...)
+				}
+				if seg.ID != prev.ID {
+					slog.WarnContext(ctx, "Two articles claim the same part, keeping the first",
+						"file", filename,
+						"part", seg.Number,
+						"kept", prev.ID,
+						"dropped", seg.ID)
+				}
+				continue
+			}
+		}
+		if kept != nil {
+			kept = append(kept, seg)
+		}
+		prev = seg
+	}
+	if kept != nil {
+		return kept, nil
+	}
+	return segs, nil
+}
@@ -0,0 +1,86 @@
+package parser
+
+import (
+	"context"
+	"testing"
+
+	"github.com/javi11/altmount/internal/testsupport/fakepool"
+	"github.com/javi11/nntppool/v4"
+	"github.com/javi11/nzbparser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestParseNzbSortsInterleavedSegments verifies that segments listed out of
+// part order are stored in file order, so the first segment probed and every
+// computed offset refer to the right article.
+func TestParseNzbSortsInterleavedSegments(t *testing.T) {
+	fp := fakepool.New()
+	for _, id := range []string{"part-1", "part-4"} {
+		fp.SetBehavior(id, fakepool.SegmentBehavior{
+			YEnc: nntppool.YEncMeta{FileName: "movie.mkv", PartSize: 1024},
+		})
+	}
+	pm := newFakeFullPoolManager(fp)
+
+	n := &nzbparser.Nzb{
+		Files: nzbparser.NzbFiles{
+			{
+				Filename: "movie.mkv",
+				Segments: nzbparser.NzbSegments{
+					{Bytes: 1024, Number: 3, ID: "part-3"},
+					{Bytes: 1024, Number: 1, ID: "part-1"},
+					{Bytes: 1024, Number: 4, ID: "part-4"},
+					{Bytes: 1024, Number: 2, ID: "part-2"},
+				},
+			},
+		},
+	}
+
+	p := NewParser(pm, stormConfigGetter(1))
+	parsed, err := p.ParseNzb(context.Background(), n, "test.nzb", nil, ParseOptions{})
+	require.NoError(t, err)
+	require.Len(t, parsed.Files, 1)
+
+	var ids []string
+	for _, seg := range parsed.Files[0].Segments {
+		ids = append(ids, seg.Id)
+	}
+	assert.Equal(t, []string{"part-1", "part-2", "part-3", "part-4"}, ids)
+	assert.NotZero(t, fp.PerMessageCalls("part-1"), "the real first segment is probed")
+	assert.Zero(t, fp.PerMessageCalls("part-3"))
+}
+
+func TestValidateSegmentOrder(t *testing.T) {
+	segs := func(numbers ...int) nzbparser.NzbSegments {
+		out := make(nzbparser.NzbSegments, len(numbers))
+		for i, n := range numbers {
+			out[i] = nzbparser.NzbSegment{Number: n, ID: string(rune('a' + i))}
+		}
+		return out
+	}
+
+	ids := func(segs nzbparser.NzbSegments) string {
+		var out string
+		for _, seg := range segs {
+			out += seg.ID
+		}
+		return out
+	}
+	ctx := context.Background()
+
+	got, err := validateSegmentOrder(ctx, "f", segs(1, 2, 3))
+	require.NoError(t, err)
+	assert.Equal(t, "abc", ids(got))
+
+	got, err = validateSegmentOrder(ctx, "f", segs(0, 0, 0))
+	require.NoError(t, err, "unnumbered segments are not checked")
+	assert.Equal(t, "abc", ids(got))
+
+	_, err = validateSegmentOrder(ctx, "f", segs(1, 3, 2))
+	assert.ErrorContains(t, err, "not in file order")
+
+	got, err = validateSegmentOrder(ctx, "f", segs(1, 2, 2, 2, 3))
+	require.NoError(t, err)
+	assert.Equal(t, "abe", ids(got), "the first article claiming a part is kept")
+}
@@ -78,6 +78,7 @@ type ParsedFile struct {
 	NzbdavID      string            // Original ID from nzbdav (for backward compatibility)
 	AesKey        []byte            // AES encryption key (for nzbdav compatibility)
 	AesIv         []byte            // AES initialization vector (for nzbdav compatibility)
+	ContentMD5    []byte            // MD5 of the whole decoded file from PAR2 (nil if unknown)
 
 	// FirstSegmentBytes holds the decoded leading bytes (≤16KB) of this file's first
 	// segment, captured when the parser fetched it during first-segment analysis. It
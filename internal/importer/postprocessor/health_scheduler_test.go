@@ -48,7 +48,8 @@ func setupSchedulerTest(t *testing.T, mutate ...func(*config.Config)) (*Coordina
 			streaming_failure_count INTEGER DEFAULT 0,
 			is_masked BOOLEAN DEFAULT FALSE,
 			indexer TEXT DEFAULT NULL,
-			download_id TEXT DEFAULT NULL
+			download_id TEXT DEFAULT NULL,
+			last_verified_at DATETIME DEFAULT NULL
 		);
 	`)
 	require.NoError(t, err)
@@ -16,6 +16,7 @@ import (
 
 	"github.com/javi11/altmount/internal/config"
 	"github.com/javi11/altmount/internal/database"
+	"github.com/javi11/altmount/internal/encryption/rclone"
 	"github.com/javi11/altmount/internal/holes"
 	"github.com/javi11/altmount/internal/importer/archive"
 	"github.com/javi11/altmount/internal/importer/archive/rar"
@@ -29,6 +30,7 @@ import (
 	"github.com/javi11/altmount/internal/metadata"
 	metapb "github.com/javi11/altmount/internal/metadata/proto"
 	"github.com/javi11/altmount/internal/nzbfile"
+	"github.com/javi11/altmount/internal/nzbfilesystem"
 	"github.com/javi11/altmount/internal/pool"
 	"github.com/javi11/altmount/internal/progress"
 )
@@ -44,7 +46,8 @@ type Processor struct {
 	metadataService   *metadata.MetadataService
 	rarProcessor      rar.Processor
 	sevenZipProcessor sevenzip.Processor
-	poolManager       pool.Manager // Pool manager for dynamic pool access
+	analysisGate      *pool.ImportAdmission // bounds concurrent archive analyses
+	poolManager       pool.Manager          // Pool manager for dynamic pool access
 	configGetter      config.ConfigGetter
 	validationTimeout time.Duration
 	log               *slog.Logger
@@ -58,12 +61,14 @@ type Processor struct {
 
 // NewProcessor creates a new NZB processor using metadata storage
 func NewProcessor(metadataService *metadata.MetadataService, poolManager pool.Manager, broadcaster *progress.ProgressBroadcaster, configGetter config.ConfigGetter, recorder HistoryRecorder) *Processor {
+	analysisGate := pool.NewImportAdmission()
 	return &Processor{
 		parser:            parser.NewParser(poolManager, configGetter),
 		strmParser:        parser.NewStrmParser(),
 		metadataService:   metadataService,
-		rarProcessor:      rar.NewProcessor(poolManager, configGetter),
-		sevenZipProcessor: sevenzip.NewProcessor(poolManager, configGetter),
+		rarProcessor:      gatedRarProcessor{rar.NewProcessor(poolManager, configGetter), analysisGate},
+		sevenZipProcessor: gatedSevenZipProcessor{sevenzip.NewProcessor(poolManager, configGetter), analysisGate},
+		analysisGate:      analysisGate,
 		poolManager:       poolManager,
 		configGetter:      configGetter,
 		validationTimeout: 30 * time.Second, // Default validation timeout for imports
@@ -91,59 +96,58 @@ func (proc *Processor) SetRecorder(recorder HistoryRecorder) {
 	proc.recorder = recorder
 }
 
-func (proc *Processor) isCategoryFolder(path string, category *string) bool {
-	cfg := proc.configGetter()
-	normalizedPath := strings.Trim(filepath.ToSlash(path), "/")
-	completeDir := strings.Trim(filepath.ToSlash(cfg.SABnzbd.CompleteDir), "/")
-
-	// Helper to check if a name matches a category
-	matchesCategory := func(name string) bool {
-		name = strings.Trim(filepath.ToSlash(name), "/")
-		if name == "" {
-			return false
-		}
-
-		// Check exact match
-		if normalizedPath == name {
-			return true
-		}
-
-		// Check match with complete_dir prefix (e.g. complete/tv)
-		// We must ensure it's at a directory boundary
-		if completeDir != "" {
-			prefix := strings.Trim(completeDir+"/"+name, "/")
-			if normalizedPath == prefix {
-				return true
-			}
-		}
-
-		return false
+// readbackVerifier returns the check archive imports run on each extracted
+// file before writing its metadata, or nil when Import.VerifyReadback is off.
+func (proc *Processor) readbackVerifier() func(ctx context.Context, virtualPath string, meta *metapb.FileMetadata) error {
+	if v := proc.configGetter().Import.VerifyReadback; v == nil || !*v {
+		return nil
 	}
-
-	// Check if path matches the provided category (for auto-detected categories)
-	if category != nil && *category != "" {
-		if matchesCategory(*category) {
-			return true
-		}
+	return func(ctx context.Context, virtualPath string, meta *metapb.FileMetadata) error {
+		return nzbfilesystem.VerifyReadback(ctx, proc.poolManager, proc.configGetter, virtualPath, meta)
 	}
+}
 
-	// Check complete_dir itself
-	if normalizedPath == completeDir {
-		return true
+// verifyRcloneSizes decrypts the tail of every rclone-encrypted file before
+// its metadata is written and fails the import when the stored decrypted size
+// doesn't match the ciphertext. Other probe failures (unreachable articles,
+// wrong credentials) are logged and left to the usual streaming paths.
+func (proc *Processor) verifyRcloneSizes(ctx context.Context, files []parser.ParsedFile) error {
+	if proc.poolManager == nil || !proc.poolManager.HasPool() {
+		return nil
 	}
 
-	// Check configured categories
-	for _, cat := range cfg.SABnzbd.Categories {
-		// Check both the category name and its specific directory if set
-		if matchesCategory(cat.Name) {
-			return true
+	for _, f := range files {
+		if f.Encryption != metapb.Encryption_RCLONE {
+			continue
+		}
+
+		meta := &metapb.FileMetadata{
+			FileSize:    f.Size,
+			SegmentData: f.Segments,
+			Encryption:  f.Encryption,
+			Password:    f.Password,
+			Salt:        f.Salt,
 		}
-		if cat.Dir != "" && matchesCategory(cat.Dir) {
-			return true
+		err := nzbfilesystem.VerifyRcloneSize(ctx, proc.poolManager, proc.configGetter, f.Filename, meta)
+		if errors.Is(err, rclone.ErrDecryptedSizeMismatch) {
+			return NewNonRetryableError(fmt.Sprintf("rclone file %s has a mismatched decrypted size", f.Filename), err)
+		}
+		if err != nil {
+			proc.log.WarnContext(ctx, "Could not verify decrypted size of rclone file",
+				"file", f.Filename, "error", err)
 		}
 	}
+	return nil
+}
 
-	return false
+// isCategoryFolder reports whether path is complete_dir or a category's mapped
+// directory; category (when set) is matched even if it is not configured.
+func (proc *Processor) isCategoryFolder(path string, category *string) bool {
+	var extra []string
+	if category != nil {
+		extra = append(extra, *category)
+	}
+	return proc.configGetter().IsCategoryDir(path, extra...)
 }
 
 // updateProgress emits a progress update if broadcaster is available
@@ -515,6 +519,18 @@ func (proc *Processor) ProcessNzbFile(ctx context.Context, filePath, relativePat
 
 	// Step 3: Separate files by type (regular, archive, PAR2)
 	regularFiles, archiveFiles, par2Files := filesystem.SeparateFiles(parsed.Files, parsed.Type)
+	verificationMode := cfg.Import.VerificationFiles.Or(config.VerificationFilesRetain)
+	if verificationMode == config.VerificationFilesDrop {
+		par2Files = nil
+	}
+
+	// Archives are vetted against the import limits once analysis knows their
+	// contents (see archiveLimitCheck); everything else is known already.
+	if parsed.Type != parser.NzbTypeRarArchive && parsed.Type != parser.NzbType7zArchive {
+		if err := checkImportLimits(cfg.Import, len(regularFiles), totalFileSize(regularFiles)); err != nil {
+			return "", nil, err
+		}
+	}
 
 	// Check for cancellation before main processing
 	if err := proc.checkCancellation(ctx); err != nil {
@@ -532,33 +548,7 @@ func (proc *Processor) ProcessNzbFile(ctx context.Context, filePath, relativePat
 	var storeRef string
 	var storeIndex map[string]int64
 	if parsed.Store != nil && len(parsed.SegmentIndex) > 0 && parsed.Type != parser.NzbTypeStrm {
-		cfg := proc.configGetter()
-		configDir := filepath.Dir(cfg.Database.Path)
-		if !filepath.IsAbs(configDir) {
-			if abs, err := filepath.Abs(configDir); err == nil {
-				configDir = abs
-			}
-		}
-		var categoryStr string
-		if category != nil && *category != "" {
-			categoryStr = *category
-			// Sanitize category to prevent path traversal.
-			categoryStr = strings.ReplaceAll(categoryStr, `\`, "/")
-			categoryStr = strings.Trim(categoryStr, "/")
-			for _, part := range strings.Split(categoryStr, "/") {
-				if part == ".." || part == "." {
-					categoryStr = ""
-					break
-				}
-			}
-		}
-		nzbStoreDir := filepath.Join(configDir, ".nzbs", categoryStr)
-		allowedBase := filepath.Clean(configDir) + string(os.PathSeparator)
-		if !strings.HasPrefix(filepath.Clean(nzbStoreDir)+string(os.PathSeparator), allowedBase) {
-			proc.log.WarnContext(ctx, "category produced path outside configDir; falling back to root .nzbs/",
-				"category", categoryStr, "resolved", nzbStoreDir)
-			nzbStoreDir = filepath.Join(configDir, ".nzbs")
-		}
+		nzbStoreDir := proc.nzbStoreDir(ctx, category)
 		if mkErr := os.MkdirAll(nzbStoreDir, 0755); mkErr != nil {
 			proc.log.WarnContext(ctx, "failed to create nzb store dir; metadata stays v1",
 				"dir", nzbStoreDir, "error", mkErr)
@@ -625,6 +615,10 @@ func (proc *Processor) ProcessNzbFile(ctx context.Context, filePath, relativePat
 			writeMetadata: func(virtualPath string, meta *metapb.FileMetadata) error {
 				return proc.metadataService.WriteFileMetadataAuto(ctx, virtualPath, meta, storeIndex, storeRef)
 			},
+			resolvePath: func(virtualPath string) (string, bool) {
+				return filesystem.ResolvePathCollision(virtualPath,
+					importCfg.OnPathCollision.Or(config.PathCollisionOverwrite), proc.metadataService)
+			},
 		}, regularFiles, virtualDir, proc.getCleanNzbName(parsed.Path, queueID), parsed.Path, isoReleaseDate)
 		if isoErr != nil {
 			return "", writtenPaths, NewNonRetryableError("bare-ISO expansion failed", isoErr)
@@ -648,6 +642,11 @@ func (proc *Processor) ProcessNzbFile(ctx context.Context, filePath, relativePat
 	// already return their full set of written paths (including "DIR:"
 	// prefixed cleanup markers) so we just concatenate.
 	var dispatchPaths []string
+	if parsed.Type == parser.NzbTypeSingleFile || parsed.Type == parser.NzbTypeMultiFile {
+		if err := proc.verifyRcloneSizes(ctx, regularFiles); err != nil {
+			return "", writtenPaths, err
+		}
+	}
 	switch parsed.Type {
 	case parser.NzbTypeSingleFile:
 		proc.updateProgressWithStage(queueID, 30, "Validating segments")
@@ -674,6 +673,16 @@ func (proc *Processor) ProcessNzbFile(ctx context.Context, filePath, relativePat
 	}
 	writtenPaths = append(writtenPaths, dispatchPaths...)
 
+	if err == nil && verificationMode == config.VerificationFilesInclude && len(par2Files) > 0 &&
+		(parsed.Type == parser.NzbTypeSingleFile || parsed.Type == parser.NzbTypeMultiFile) {
+		writtenPaths = append(writtenPaths,
+			proc.includeVerificationFiles(ctx, result, par2Files, parsed.Path, storeIndex, storeRef)...)
+	}
+
+	if err == nil && cfg.GetRetainSourceNzb() {
+		proc.retainSourceNzb(ctx, filePath, category, queueID, writtenPaths)
+	}
+
 	// Update progress: complete
 	if err == nil {
 		proc.updateProgress(queueID, 100)
@@ -684,6 +693,34 @@ func (proc *Processor) ProcessNzbFile(ctx context.Context, filePath, relativePat
 	return result, writtenPaths, err
 }
 
+// nzbStoreDir returns the directory under the NZB root that holds an import's
+// .nzbz store and retained NZB: the category's subdirectory, or the root itself
+// when there is no category or it would escape the root.
+func (proc *Processor) nzbStoreDir(ctx context.Context, category *string) string {
+	nzbRoot := proc.configGetter().GetNzbRoot()
+	var categoryStr string
+	if category != nil && *category != "" {
+		categoryStr = *category
+		// Sanitize category to prevent path traversal.
+		categoryStr = strings.ReplaceAll(categoryStr, `\`, "/")
+		categoryStr = strings.Trim(categoryStr, "/")
+		for _, part := range strings.Split(categoryStr, "/") {
+			if part == ".." || part == "." {
+				categoryStr = ""
+				break
+			}
+		}
+	}
+	nzbStoreDir := filepath.Join(nzbRoot, categoryStr)
+	allowedBase := filepath.Clean(nzbRoot) + string(os.PathSeparator)
+	if !strings.HasPrefix(filepath.Clean(nzbStoreDir)+string(os.PathSeparator), allowedBase) {
+		proc.log.WarnContext(ctx, "category produced path outside the NZB root; falling back to the root",
+			"category", categoryStr, "resolved", nzbStoreDir)
+		nzbStoreDir = nzbRoot
+	}
+	return nzbStoreDir
+}
+
 // processSingleFile handles single file imports
 func (proc *Processor) processSingleFile(
 	ctx context.Context,
@@ -767,6 +804,7 @@ func (proc *Processor) processSingleFile(
 		filterSampleFiles,
 		storeIndex,
 		storeRef,
+		proc.configGetter().Import.OnPathCollision,
 	)
 	var writtenPaths []string
 	if writtenPath != "" {
@@ -865,6 +903,7 @@ func (proc *Processor) processMultiFile(
 		writeTracker,
 		storeIndex,
 		storeRef,
+		importCfg.OnPathCollision,
 	)
 	if err != nil {
 		return "", writtenPaths, err
@@ -961,6 +1000,7 @@ func (proc *Processor) processRarArchive(
 			nil, // archive progress is tracked by the archive tracker below
 			storeIndex,
 			storeRef,
+			importCfg.OnPathCollision,
 		); err != nil {
 			slog.DebugContext(ctx, "Failed to process regular files", "error", err)
 		}
@@ -994,8 +1034,12 @@ func (proc *Processor) processRarArchive(
 			ExpandBlurayIso:        expandBlurayIso,
 			FilterSamples:          filterSampleFiles,
 			RenameToNzbName:        renameToNzbName,
+			FlattenStructure:       !proc.configGetter().GetPreserveArchiveStructure(),
 			SegmentIndex:           storeIndex,
 			StoreRef:               storeRef,
+			VerifyReadback:         proc.readbackVerifier(),
+			CheckLimits:            proc.archiveLimitCheck(regularFiles),
+			OnPathCollision:        importCfg.OnPathCollision,
 		})
 		if err != nil {
 			return nzbFolder, writtenPaths, err
@@ -1094,6 +1138,7 @@ func (proc *Processor) processSevenZipArchive(
 			nil, // archive progress is tracked by the archive tracker below
 			storeIndex,
 			storeRef,
+			importCfg.OnPathCollision,
 		); err != nil {
 			slog.DebugContext(ctx, "Failed to process regular files", "error", err)
 		}
@@ -1126,8 +1171,12 @@ func (proc *Processor) processSevenZipArchive(
 			ExpandBlurayIso:        expandBlurayIso,
 			FilterSamples:          filterSampleFiles,
 			RenameToNzbName:        renameToNzbName,
+			FlattenStructure:       !proc.configGetter().GetPreserveArchiveStructure(),
 			SegmentIndex:           storeIndex,
 			StoreRef:               storeRef,
+			VerifyReadback:         proc.readbackVerifier(),
+			CheckLimits:            proc.archiveLimitCheck(regularFiles),
+			OnPathCollision:        importCfg.OnPathCollision,
 		})
 		if err != nil {
 			return nzbFolder, writtenPaths, err
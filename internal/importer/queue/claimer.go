@@ -15,7 +15,7 @@ import (
 
 // QueueRepository defines the interface for queue database operations
 type QueueRepository interface {
-	ClaimNextQueueItem(ctx context.Context) (*database.ImportQueueItem, error)
+	ClaimNextQueueItem(ctx context.Context, reclaimAfter time.Duration) (*database.ImportQueueItem, error)
 }
 
 // Claimer handles claiming queue items with retry logic
@@ -32,13 +32,14 @@ func NewClaimer(repo QueueRepository) *Claimer {
 	}
 }
 
-// ClaimWithRetry attempts to claim a queue item with exponential backoff retry logic
-func (c *Claimer) ClaimWithRetry(ctx context.Context, workerID int) (*database.ImportQueueItem, error) {
+// ClaimWithRetry attempts to claim a queue item with exponential backoff retry logic.
+// Processing items without a heartbeat for reclaimAfter are claimable too (0 disables).
+func (c *Claimer) ClaimWithRetry(ctx context.Context, workerID int, reclaimAfter time.Duration) (*database.ImportQueueItem, error) {
 	var item *database.ImportQueueItem
 
 	err := retry.Do(
 		func() error {
-			claimedItem, err := c.repo.ClaimNextQueueItem(ctx)
+			claimedItem, err := c.repo.ClaimNextQueueItem(ctx, reclaimAfter)
 			if err != nil {
 				return err
 			}
@@ -233,6 +233,9 @@ func (m *Manager) ExecuteItem(ctx context.Context, itemID int64) error {
 			m.cancelMu.Unlock()
 		}()
 
+		stopHeartbeat := m.startHeartbeat(itemCtx, item.ID, heartbeatInterval(m.configGetter().GetProcessingReclaimTimeout()))
+		defer stopHeartbeat()
+
 		resultingPath, processingErr := m.processor.ProcessItem(itemCtx, item)
 
 		if processingErr != nil {
@@ -320,8 +323,10 @@ func (m *Manager) workerLoop(workerID int, loopCtx context.Context) {
 
 // processNextItem claims and processes the next queue item
 func (m *Manager) processNextItem(ctx context.Context, workerID int) {
+	reclaimAfter := m.configGetter().GetProcessingReclaimTimeout()
+
 	m.claimMu.Lock()
-	item, err := m.claimer.ClaimWithRetry(ctx, workerID)
+	item, err := m.claimer.ClaimWithRetry(ctx, workerID, reclaimAfter)
 	m.claimMu.Unlock()
 
 	if err != nil {
@@ -352,6 +357,9 @@ func (m *Manager) processNextItem(ctx context.Context, workerID int) {
 		m.cancelMu.Unlock()
 	}()
 
+	stopHeartbeat := m.startHeartbeat(ctx, item.ID, heartbeatInterval(reclaimAfter))
+	defer stopHeartbeat()
+
 	resultingPath, processingErr := m.processor.ProcessItem(itemCtx, item)
 
 	if processingErr != nil {
@@ -360,3 +368,46 @@ func (m *Manager) processNextItem(ctx context.Context, workerID int) {
 		m.processor.HandleSuccess(ctx, item, resultingPath)
 	}
 }
+
+// heartbeatInterval returns how often a worker refreshes the heartbeat of the
+// item it is processing: a quarter of the reclaim timeout, at most a minute, so
+// several beats can be missed before the item looks orphaned. 0 (no
+// heartbeat) when reclaiming is disabled.
+func heartbeatInterval(reclaimAfter time.Duration) time.Duration {
+	if reclaimAfter <= 0 {
+		return 0
+	}
+	return min(reclaimAfter/4, time.Minute)
+}
+
+// startHeartbeat refreshes the item's heartbeat every interval until the
+// returned stop function is called, so a slow but alive import is never
+// reclaimed by another worker. A non-positive interval starts nothing.
+func (m *Manager) startHeartbeat(ctx context.Context, itemID int64, interval time.Duration) (stop func()) {
+	if interval <= 0 || m.repository == nil {
+		return func() {}
+	}
+
+	hbCtx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-hbCtx.Done():
+				return
+			case <-ticker.C:
+				if err := m.repository.UpdateQueueItemHeartbeat(hbCtx, itemID); err != nil && hbCtx.Err() == nil {
+					m.log.WarnContext(hbCtx, "Failed to update queue item heartbeat", "queue_id", itemID, "error", err)
+				}
+			}
+		}
+	}()
+
+	return func() {
+		cancel()
+		<-done
+	}
+}
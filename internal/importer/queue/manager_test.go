@@ -2,6 +2,7 @@ package queue
 
 import (
 	"context"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -147,3 +148,55 @@ func TestResize_ScaleUpThenDown(t *testing.T) {
 	time.Sleep(100 * time.Millisecond)
 	assert.Equal(t, 3, m.workerCount)
 }
+
+func TestHeartbeatInterval(t *testing.T) {
+	assert.Zero(t, heartbeatInterval(0))
+	assert.Equal(t, 15*time.Second, heartbeatInterval(time.Minute))
+	assert.Equal(t, time.Minute, heartbeatInterval(10*time.Minute))
+}
+
+func TestStartHeartbeat_KeepsItemFromBeingReclaimed(t *testing.T) {
+	db, err := database.NewDB(database.Config{Type: "sqlite", DatabasePath: filepath.Join(t.TempDir(), "test.db")})
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	repo := db.Repository
+	ctx := context.Background()
+
+	item := &database.ImportQueueItem{NzbPath: "/nzbs/slow.nzb", Priority: database.QueuePriorityNormal, Status: database.QueueStatusPending, MaxRetries: 3}
+	require.NoError(t, repo.AddToQueue(ctx, item))
+	claimed, err := repo.ClaimNextQueueItem(ctx, time.Minute)
+	require.NoError(t, err)
+	require.NotNil(t, claimed)
+
+	age := func() {
+		then := time.Now().UTC().Add(-time.Hour).Format("2006-01-02 15:04:05")
+		_, err := db.Connection().ExecContext(ctx,
+			`UPDATE import_queue SET started_at = ?, heartbeat_at = ? WHERE id = ?`, then, then, item.ID)
+		require.NoError(t, err)
+	}
+	heartbeatAge := func() time.Duration {
+		var hb time.Time
+		require.NoError(t, db.Connection().QueryRowContext(ctx,
+			`SELECT heartbeat_at FROM import_queue WHERE id = ?`, item.ID).Scan(&hb))
+		return time.Since(hb)
+	}
+
+	m := NewManager(ManagerConfig{Workers: 1, ConfigGetter: testConfigGetter}, repo, &mockProcessor{}, nil)
+
+	// A slow import whose worker is alive: the heartbeat pulls the item back
+	// inside the reclaim window.
+	age()
+	stop := m.startHeartbeat(ctx, item.ID, 20*time.Millisecond)
+	require.Eventually(t, func() bool { return heartbeatAge() < time.Minute }, 5*time.Second, 20*time.Millisecond)
+	again, err := repo.ClaimNextQueueItem(ctx, time.Minute)
+	require.NoError(t, err)
+	assert.Nil(t, again, "a heartbeating item must not be reclaimed")
+
+	// The worker dies: without heartbeats the item is reclaimed after the timeout.
+	stop()
+	age()
+	again, err = repo.ClaimNextQueueItem(ctx, time.Minute)
+	require.NoError(t, err)
+	require.NotNil(t, again)
+	assert.Equal(t, item.ID, again.ID)
+}
@@ -0,0 +1,101 @@
+package importer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	metapb "github.com/javi11/altmount/internal/metadata/proto"
+	"github.com/javi11/altmount/internal/utils"
+)
+
+// retainSourceNzb keeps the NZB an import was read from (Import.RetainSourceNzb).
+// The queue's copy is deleted once the import completes and the .nzbz store
+// cannot reproduce it verbatim — it drops the archive password — so the NZB
+// is copied into the NZB root next to the store and every written file's
+// source_nzb_path is pointed at the copy. An NZB already inside the root is
+// recorded where it is. Failures are logged and leave the import as it is.
+//
+// The copy shares the store's lifetime: deleting the last file that uses the
+// store removes both. Files that fell back to v1 metadata have no store, so
+// their copy is only removed by a delete that asks for the source NZB too.
+func (proc *Processor) retainSourceNzb(ctx context.Context, filePath string, category *string, queueID int, writtenPaths []string) {
+	retained, err := proc.copySourceNzb(ctx, filePath, category, queueID)
+	if err != nil {
+		proc.log.WarnContext(ctx, "Failed to retain source NZB",
+			"nzb_path", filePath, "queue_id", queueID, "error", err)
+		return
+	}
+
+	for _, virtualPath := range proc.writtenFiles(ctx, writtenPaths) {
+		if err := proc.metadataService.UpdateFileMetadata(virtualPath, func(m *metapb.FileMetadata) {
+			m.SourceNzbPath = retained
+		}); err != nil {
+			proc.log.WarnContext(ctx, "Failed to record retained source NZB",
+				"virtual_path", virtualPath, "nzb_path", retained, "error", err)
+		}
+	}
+}
+
+// writtenFiles expands the "DIR:" entries of writtenPaths into the metadata
+// files beneath them; archive imports only report their NZB folder.
+func (proc *Processor) writtenFiles(ctx context.Context, writtenPaths []string) []string {
+	var files []string
+	var walk func(dir string)
+	walk = func(dir string) {
+		dirs, names, err := proc.metadataService.ListDirectoryAll(dir)
+		if err != nil {
+			proc.log.WarnContext(ctx, "Failed to list imported directory", "dir", dir, "error", err)
+			return
+		}
+		for _, name := range names {
+			files = append(files, path.Join(dir, name))
+		}
+		for _, d := range dirs {
+			walk(path.Join(dir, d.Name()))
+		}
+	}
+	for _, p := range writtenPaths {
+		if dir, isDir := strings.CutPrefix(p, "DIR:"); isDir {
+			walk(dir)
+		} else {
+			files = append(files, p)
+		}
+	}
+	return files
+}
+
+// copySourceNzb copies filePath into the import's NZB store directory and
+// returns the copy's path, or filePath itself when it already lies inside the
+// NZB root.
+func (proc *Processor) copySourceNzb(ctx context.Context, filePath string, category *string, queueID int) (string, error) {
+	absPath, err := filepath.Abs(filePath)
+	if err != nil {
+		return "", err
+	}
+	if isWithinNzbRoot(proc.configGetter().GetNzbRoot(), absPath) {
+		return absPath, nil
+	}
+
+	dir := proc.nzbStoreDir(ctx, category)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create nzb store dir: %w", err)
+	}
+	name := filepath.Base(absPath)
+	if queueID > 0 {
+		name = fmt.Sprintf("%d-%s", queueID, name)
+	}
+	retained := filepath.Join(dir, name)
+	if err := utils.CopyFile(absPath, retained); err != nil {
+		return "", err
+	}
+	return retained, nil
+}
+
+// isWithinNzbRoot reports whether the absolute path p lies under nzbRoot.
+func isWithinNzbRoot(nzbRoot, p string) bool {
+	return strings.HasPrefix(filepath.Clean(p), filepath.Clean(nzbRoot)+string(os.PathSeparator))
+}
@@ -0,0 +1,115 @@
+package importer
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/javi11/altmount/internal/testsupport/nzbbuild"
+)
+
+// assertRetainedSource checks that virtualPath's source NZB lies in the NZB
+// root and still holds the imported NZB after the queue copy is gone.
+func assertRetainedSource(t *testing.T, env *batteryEnv, virtualPath string, want []byte) {
+	t.Helper()
+	meta := env.readMeta(virtualPath)
+	src := meta.SourceNzbPath
+	if !isWithinNzbRoot(env.cfg.GetNzbRoot(), src) {
+		t.Fatalf("%s: SourceNzbPath = %q, want a path under %q", virtualPath, src, env.cfg.GetNzbRoot())
+	}
+	if strings.HasSuffix(src, ".nzbz") {
+		t.Errorf("%s: SourceNzbPath = %q points at the store, not the retained NZB", virtualPath, src)
+	}
+	got, err := os.ReadFile(src)
+	if err != nil {
+		t.Fatalf("%s: retained NZB not readable: %v", virtualPath, err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("%s: retained NZB differs from the imported one", virtualPath)
+	}
+	if meta.StoreRef == "" {
+		t.Errorf("%s: StoreRef cleared; the store must still back the segments", virtualPath)
+	}
+}
+
+func TestRetainSourceNzb_SingleFile(t *testing.T) {
+	env := newBatteryEnv(t)
+	retain := true
+	env.cfg.Import.RetainSourceNzb = &retain
+
+	segs := env.registerContent("retain-single", bytes.Repeat([]byte("R"), 30_000), 10_000, 1.0, nil)
+	nzb := nzbbuild.Build(nzbbuild.File{Subject: "Retained.2024.mkv", Segments: segs})
+	nzbPath := nzbbuild.WriteTemp(t, nzb, "Retained.2024.mkv")
+	original, err := os.ReadFile(nzbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	category := "movies"
+	if _, _, err := env.proc.ProcessNzbFile(context.Background(), nzbPath, filepath.Dir(nzbPath),
+		7, nil, nil, nil, &category, nil, nil); err != nil {
+		t.Fatalf("import failed: %v", err)
+	}
+	// The queue deletes its copy once the import completes.
+	if err := os.Remove(nzbPath); err != nil {
+		t.Fatal(err)
+	}
+
+	assertRetainedSource(t, env, "/Retained.2024.mkv", original)
+	if got := env.readMeta("/Retained.2024.mkv").SourceNzbPath; filepath.Dir(got) != filepath.Join(env.cfg.GetNzbRoot(), category) {
+		t.Errorf("retained NZB %q is not in the category's store directory", got)
+	}
+}
+
+func TestRetainSourceNzb_Archive(t *testing.T) {
+	env := newBatteryEnv(t)
+	retain := true
+	env.cfg.Import.RetainSourceNzb = &retain
+
+	rarBytes := loadFixture(t, filepath.Join("rar_single", "archive.rar"))
+	segs := env.registerContent("retain-rar", rarBytes, archivePartSize, 1.0, nil)
+	nzb := nzbbuild.Build(nzbbuild.File{Subject: "archive.rar", Segments: segs})
+	nzbPath := nzbbuild.WriteTemp(t, nzb, "archive")
+	original, err := os.ReadFile(nzbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err := env.proc.ProcessNzbFile(context.Background(), nzbPath, filepath.Dir(nzbPath),
+		1, nil, nil, nil, nil, nil, nil); err != nil {
+		t.Fatalf("import failed: %v", err)
+	}
+	if err := os.Remove(nzbPath); err != nil {
+		t.Fatal(err)
+	}
+
+	inner := env.listDir("/archive")
+	if len(inner) == 0 {
+		t.Fatal("no inner files written inside /archive")
+	}
+	for _, name := range inner {
+		assertRetainedSource(t, env, "/archive/"+name, original)
+	}
+}
+
+func TestRetainSourceNzb_DisabledKeepsStoreAsSource(t *testing.T) {
+	env := newBatteryEnv(t)
+
+	segs := env.registerContent("retain-off", bytes.Repeat([]byte("O"), 30_000), 10_000, 1.0, nil)
+	nzb := nzbbuild.Build(nzbbuild.File{Subject: "NotRetained.mkv", Segments: segs})
+	if _, _, err := env.runImport(nzb, "NotRetained.mkv"); err != nil {
+		t.Fatalf("import failed: %v", err)
+	}
+
+	meta := env.readMeta("/NotRetained.mkv")
+	if meta.SourceNzbPath != meta.StoreRef {
+		t.Errorf("SourceNzbPath = %q, want the store %q", meta.SourceNzbPath, meta.StoreRef)
+	}
+	matches, _ := filepath.Glob(filepath.Join(env.cfg.GetNzbRoot(), "*.nzb"))
+	if len(matches) != 0 {
+		t.Errorf("NZB copies written with retention off: %v", matches)
+	}
+}
@@ -0,0 +1,81 @@
+package importer
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/javi11/altmount/internal/database"
+)
+
+// isTransientFailure reports whether a failed import may succeed on another
+// attempt. Errors classified non-retryable (compressed archives, unsupported
+// codecs, missing articles, ...), over-limit rejections and cancellations are
+// permanent; anything else is assumed transient.
+func isTransientFailure(err error) bool {
+	if err == nil || IsNonRetryable(err) || errors.Is(err, ErrImportLimitExceeded) {
+		return false
+	}
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+	msg := err.Error()
+	return !strings.Contains(msg, "context canceled") && !strings.Contains(msg, "processing cancelled")
+}
+
+// transientRetryBaseDelay is how long an item requeued after its first
+// transient failure waits before it is claimed again. Each further retry
+// doubles the wait up to transientRetryMaxDelay, so a provider outage does not
+// use up every retry within seconds.
+const (
+	transientRetryBaseDelay = 30 * time.Second
+	transientRetryMaxDelay  = 10 * time.Minute
+)
+
+// transientRetryBackoff is the wait before retry number retryCount+1.
+func transientRetryBackoff(retryCount int) time.Duration {
+	if retryCount >= 5 {
+		return transientRetryMaxDelay
+	}
+	return min(transientRetryBaseDelay<<retryCount, transientRetryMaxDelay)
+}
+
+// retryTransientFailure requeues an item that failed with a transient error
+// when Import.RetryTransientFailures is enabled, moving it to the dead-letter
+// table once its retries are used up. Reports whether the item was requeued;
+// when it was not (including when it was just dead-lettered) the caller runs
+// the normal failure handling.
+func (s *Service) retryTransientFailure(ctx context.Context, item *database.ImportQueueItem, processingErr error, errorMessage string) bool {
+	if !s.configGetter().GetRetryTransientFailures() || !isTransientFailure(processingErr) {
+		return false
+	}
+
+	backoff := transientRetryBackoff(item.RetryCount)
+	deadLettered, err := s.database.Repository.RetryOrDeadLetter(ctx, item.ID, &errorMessage, backoff)
+	if err != nil {
+		s.log.ErrorContext(ctx, "Failed to requeue item for retry", "queue_id", item.ID, "error", err)
+		return false
+	}
+
+	if !deadLettered {
+		s.log.WarnContext(ctx, "Item requeued for retry",
+			"queue_id", item.ID,
+			"file", item.NzbPath,
+			"attempt", item.RetryCount+1,
+			"max_retries", item.MaxRetries,
+			"retry_in", backoff)
+		if s.broadcaster != nil {
+			s.broadcaster.ClearProgress(int(item.ID))
+			s.broadcaster.NotifyStatus(int(item.ID), string(database.QueueStatusPending))
+			s.broadcaster.BroadcastQueueChanged()
+		}
+		return true
+	}
+
+	s.log.ErrorContext(ctx, "Item dead-lettered after exhausting retries",
+		"queue_id", item.ID,
+		"file", item.NzbPath,
+		"max_retries", item.MaxRetries)
+	return false
+}
@@ -0,0 +1,144 @@
+package importer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/javi11/altmount/internal/config"
+	"github.com/javi11/altmount/internal/database"
+)
+
+func newRetryTestService(t *testing.T, retry bool) *Service {
+	t.Helper()
+
+	db, err := database.NewDB(database.Config{Type: "sqlite", DatabasePath: filepath.Join(t.TempDir(), "test.db")})
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	return &Service{
+		database: db,
+		configGetter: func() *config.Config {
+			return &config.Config{Import: config.ImportConfig{RetryTransientFailures: &retry}}
+		},
+		log: slog.Default(),
+	}
+}
+
+func addRetryTestItem(t *testing.T, svc *Service, name string) *database.ImportQueueItem {
+	t.Helper()
+	item := &database.ImportQueueItem{
+		NzbPath:    "/nzbs/" + name,
+		Status:     database.QueueStatusProcessing,
+		MaxRetries: 2,
+	}
+	require.NoError(t, svc.database.Repository.AddToQueue(context.Background(), item))
+	return item
+}
+
+func TestIsTransientFailure(t *testing.T) {
+	assert.True(t, isTransientFailure(errors.New("connection reset by peer")))
+	assert.True(t, isTransientFailure(fmt.Errorf("analyze archive: %w", errors.New("i/o timeout"))))
+
+	assert.False(t, isTransientFailure(NewNonRetryableError("compressed files are not supported: a.mkv", nil)))
+	assert.False(t, isTransientFailure(fmt.Errorf("process: %w", NewNonRetryableError("unsupported coder", nil))))
+	assert.False(t, isTransientFailure(ErrArticlesNotFound))
+	assert.False(t, isTransientFailure(fmt.Errorf("%w: 12 files", ErrImportLimitExceeded)))
+	assert.False(t, isTransientFailure(context.Canceled))
+	assert.False(t, isTransientFailure(errors.New("processing cancelled")))
+}
+
+func TestTransientRetryBackoff(t *testing.T) {
+	assert.Equal(t, transientRetryBaseDelay, transientRetryBackoff(0))
+	assert.Equal(t, 2*transientRetryBaseDelay, transientRetryBackoff(1))
+	assert.Equal(t, transientRetryMaxDelay, transientRetryBackoff(10))
+	assert.Equal(t, transientRetryMaxDelay, transientRetryBackoff(100))
+}
+
+func TestRetryTransientFailure(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("transient error retries until dead-lettered", func(t *testing.T) {
+		svc := newRetryTestService(t, true)
+		item := addRetryTestItem(t, svc, "transient.nzb")
+		procErr := errors.New("connection reset by peer")
+
+		for attempt := 1; attempt <= 2; attempt++ {
+			require.True(t, svc.retryTransientFailure(ctx, item, procErr, procErr.Error()))
+			got, err := svc.database.Repository.GetQueueItem(ctx, item.ID)
+			require.NoError(t, err)
+			require.NotNil(t, got)
+			assert.Equal(t, database.QueueStatusPending, got.Status)
+			assert.Equal(t, attempt, got.RetryCount)
+			item = got
+		}
+
+		require.False(t, svc.retryTransientFailure(ctx, item, procErr, procErr.Error()),
+			"a dead-lettered item still goes through the normal failure handling")
+		got, err := svc.database.Repository.GetQueueItem(ctx, item.ID)
+		require.NoError(t, err)
+		assert.Nil(t, got, "item should leave the queue once retries are used up")
+
+		dead, err := svc.database.Repository.ListDeadLetterItems(ctx, 10, 0)
+		require.NoError(t, err)
+		require.Len(t, dead, 1)
+		assert.Equal(t, item.ID, dead[0].QueueID)
+	})
+
+	t.Run("non-retryable error skips retries", func(t *testing.T) {
+		svc := newRetryTestService(t, true)
+		item := addRetryTestItem(t, svc, "compressed.nzb")
+		procErr := NewNonRetryableError("compressed files are not supported: a.mkv", nil)
+
+		assert.False(t, svc.retryTransientFailure(ctx, item, procErr, procErr.Error()))
+
+		got, err := svc.database.Repository.GetQueueItem(ctx, item.ID)
+		require.NoError(t, err)
+		assert.Equal(t, database.QueueStatusProcessing, got.Status, "left for the caller to mark failed")
+		assert.Zero(t, got.RetryCount)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		svc := newRetryTestService(t, false)
+		item := addRetryTestItem(t, svc, "disabled.nzb")
+
+		assert.False(t, svc.retryTransientFailure(ctx, item, errors.New("i/o timeout"), "i/o timeout"))
+
+		got, err := svc.database.Repository.GetQueueItem(ctx, item.ID)
+		require.NoError(t, err)
+		assert.Zero(t, got.RetryCount)
+	})
+}
+
+func TestMoveToFailedFolder_DeadLetteredItemFollowsNzb(t *testing.T) {
+	ctx := context.Background()
+	svc := newRetryTestService(t, true)
+	nzbRoot := t.TempDir()
+	svc.configGetter = func() *config.Config {
+		return &config.Config{Metadata: config.MetadataConfig{NzbRoot: nzbRoot}}
+	}
+
+	nzbPath := filepath.Join(t.TempDir(), "outage.nzb")
+	require.NoError(t, os.WriteFile(nzbPath, []byte("<nzb/>"), 0644))
+	item := &database.ImportQueueItem{NzbPath: nzbPath, Status: database.QueueStatusProcessing}
+	require.NoError(t, svc.database.Repository.AddToQueue(ctx, item))
+	msg := "connection reset by peer"
+	deadLettered, err := svc.database.Repository.RetryOrDeadLetter(ctx, item.ID, &msg, 0)
+	require.NoError(t, err)
+	require.True(t, deadLettered)
+
+	require.NoError(t, svc.MoveToFailedFolder(ctx, item))
+
+	dead, err := svc.database.Repository.ListDeadLetterItems(ctx, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, dead, 1)
+	assert.Equal(t, item.NzbPath, dead[0].NzbPath, "a requeue must find the moved NZB")
+	assert.FileExists(t, dead[0].NzbPath)
+}
@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
@@ -188,6 +189,7 @@ type Service struct {
 	healthRepo      *database.HealthRepository    // Health repository for updating health status
 	broadcaster     *progress.ProgressBroadcaster // WebSocket progress broadcaster
 	userRepo        *database.UserRepository      // User repository for API key lookup
+	mainRepo        *database.Repository          // Main repository for queue stats reconciliation
 	poolManager     pool.Manager                  // Pool manager — used to push admission caps on config change
 	log             *slog.Logger
 
@@ -213,7 +215,7 @@ type Service struct {
 }
 
 // NewService creates a new NZB import service with manual scanning and queue processing capabilities
-func NewService(config ServiceConfig, metadataService *metadata.MetadataService, database *database.DB, poolManager pool.Manager, rcloneClient rclonecli.RcloneRcClient, configGetter config.ConfigGetter, healthRepo *database.HealthRepository, broadcaster *progress.ProgressBroadcaster, userRepo *database.UserRepository) (*Service, error) {
+func NewService(config ServiceConfig, metadataService *metadata.MetadataService, database *database.DB, poolManager pool.Manager, rcloneClient rclonecli.RcloneRcClient, configGetter config.ConfigGetter, healthRepo *database.HealthRepository, broadcaster *progress.ProgressBroadcaster, userRepo *database.UserRepository, mainRepo *database.Repository) (*Service, error) {
 	// Set defaults
 	if config.Workers == 0 {
 		config.Workers = 2
@@ -250,6 +252,7 @@ func NewService(config ServiceConfig, metadataService *metadata.MetadataService,
 		sabnzbdClient:   sabnzbd.NewSABnzbdClient(httpclient.NewForExternal(configGetter().Network, httpclient.LongTimeout)),
 		broadcaster:     broadcaster,
 		userRepo:        userRepo,
+		mainRepo:        mainRepo,
 		poolManager:     poolManager,
 		log:             slog.Default().With("component", "importer-service"),
 		ctx:             ctx,
@@ -265,6 +268,11 @@ func NewService(config ServiceConfig, metadataService *metadata.MetadataService,
 			poolManager.SetAdmissionCap(cfg.GetMaxConcurrentImports())
 		}
 	}
+	if configGetter != nil {
+		if cfg := configGetter(); cfg != nil {
+			processor.SetMaxConcurrentAnalyses(cfg.GetMaxConcurrentAnalyses())
+		}
+	}
 
 	// Set recorder for processor
 	processor.SetRecorder(service)
@@ -323,10 +331,25 @@ func (s *Service) Start(ctx context.Context) error {
 		"max_connections", s.config.Workers+4)
 
 	// Reset any stale queue items from processing back to pending
-	if err := s.database.Repository.ResetStaleItems(ctx); err != nil {
+	recovered, err := s.database.Repository.ResetStaleItems(ctx)
+	if err != nil {
 		s.log.ErrorContext(ctx, "Failed to reset stale queue items", "error", err)
 		return fmt.Errorf("failed to reset stale queue items: %w", err)
 	}
+	if recovered > 0 {
+		s.log.InfoContext(ctx, "Recovered orphaned queue items", "count", recovered)
+	}
+
+	// Reconcile the persisted queue stats before workers start claiming.
+	report, err := s.mainRepo.VerifyQueueIntegrity(ctx)
+	if err != nil {
+		s.log.ErrorContext(ctx, "Failed to verify queue integrity", "error", err)
+		return fmt.Errorf("failed to verify queue integrity: %w", err)
+	}
+	if len(report.Inconsistencies) > 0 {
+		s.log.WarnContext(ctx, "Queue stats inconsistent after reconciliation",
+			"inconsistencies", report.Inconsistencies)
+	}
 
 	// Delegate worker management to queue manager
 	if err := s.queueManager.Start(ctx); err != nil {
@@ -345,6 +368,9 @@ func (s *Service) Start(ctx context.Context) error {
 	// Start background sweeper for grabbed indexers cache
 	go s.runGrabbedIndexerSweeper(s.ctx)
 
+	// Periodically rebuild queue stats so drift does not accumulate
+	go s.runQueueStatsRecompute(s.ctx)
+
 	s.running = true
 	s.log.InfoContext(ctx, fmt.Sprintf("NZB import service started successfully with %d workers", s.config.Workers))
 
@@ -436,6 +462,12 @@ func (s *Service) RegisterConfigChangeHandler(configManager any) {
 			s.log.InfoContext(s.ctx, "Import admission cap updated",
 				"max_concurrent_imports", cap)
 		}
+
+		if newConfig.GetMaxConcurrentAnalyses() != oldConfig.GetMaxConcurrentAnalyses() {
+			s.processor.SetMaxConcurrentAnalyses(newConfig.GetMaxConcurrentAnalyses())
+			s.log.InfoContext(s.ctx, "Archive analysis cap updated",
+				"max_concurrent_analyses", newConfig.GetMaxConcurrentAnalyses())
+		}
 	})
 }
 
@@ -583,9 +615,7 @@ func (s *Service) IsFileInQueue(ctx context.Context, filePath string) (bool, err
 
 // GetNzbFolder returns the path to the persistent NZB storage directory
 func (s *Service) GetNzbFolder() string {
-	cfg := s.configGetter()
-	configDir := filepath.Dir(cfg.Database.Path)
-	return filepath.Join(configDir, ".nzbs")
+	return s.configGetter().GetNzbRoot()
 }
 
 // GetFailedNzbFolder returns the path to the directory for failed NZB files
@@ -593,24 +623,43 @@ func (s *Service) GetFailedNzbFolder() string {
 	return filepath.Join(s.GetNzbFolder(), "failed")
 }
 
+// GetQuarantineNzbFolder returns the path to the directory for NZB files
+// rejected by the import size limits
+func (s *Service) GetQuarantineNzbFolder() string {
+	return filepath.Join(s.GetNzbFolder(), "quarantine")
+}
+
 // MoveToFailedFolder moves a failed NZB file to the failed directory
 func (s *Service) MoveToFailedFolder(ctx context.Context, item *database.ImportQueueItem) error {
-	failedDir := s.GetFailedNzbFolder()
+	return s.moveNzbToFolder(ctx, item, s.GetFailedNzbFolder(), "failed")
+}
+
+// MoveToQuarantineFolder moves an NZB rejected by the import size limits to
+// the quarantine directory, where failed-item cleanup leaves it alone.
+func (s *Service) MoveToQuarantineFolder(ctx context.Context, item *database.ImportQueueItem) error {
+	return s.moveNzbToFolder(ctx, item, s.GetQuarantineNzbFolder(), "quarantine")
+}
 
-	// Add category subfolder if present to keep failed items organized
+// moveNzbToFolder moves item's NZB file into baseDir (under a category
+// subfolder when set) and records the new path. label names the folder in
+// errors and logs.
+func (s *Service) moveNzbToFolder(ctx context.Context, item *database.ImportQueueItem, baseDir, label string) error {
+	targetDir := baseDir
+
+	// Add category subfolder if present to keep items organized
 	if item.Category != nil && *item.Category != "" {
-		failedDir = filepath.Join(failedDir, *item.Category)
+		targetDir = filepath.Join(targetDir, *item.Category)
 	}
 
-	if err := os.MkdirAll(failedDir, 0755); err != nil {
-		return fmt.Errorf("failed to create failed directory: %w", err)
+	if err := os.MkdirAll(targetDir, 0755); err != nil {
+		return fmt.Errorf("failed to create %s directory: %w", label, err)
 	}
 
 	// import_queue.nzb_path is UNIQUE. Two failed items can share a basename (e.g. a re-acquired
 	// release reuses the same NZB filename), so a prior failed item may already occupy the plain
 	// destination -- without disambiguation the DB update below fails with a UNIQUE constraint
 	// violation. uniqueFailedNzbPath namespaces the copy by the queue item ID on collision.
-	newPath := uniqueFailedNzbPath(failedDir, item.NzbPath, item.ID)
+	newPath := uniqueFailedNzbPath(targetDir, item.NzbPath, item.ID)
 
 	// Check if source exists
 	if _, err := os.Stat(item.NzbPath); os.IsNotExist(err) {
@@ -618,24 +667,28 @@ func (s *Service) MoveToFailedFolder(ctx context.Context, item *database.ImportQ
 		return nil
 	}
 
-	// Avoid moving if already in failed folder (e.g. retry of failed item)
-	if filepath.Dir(item.NzbPath) == failedDir {
+	// Avoid moving if already in the target folder (e.g. retry of failed item)
+	if filepath.Dir(item.NzbPath) == targetDir {
 		return nil
 	}
 
 	// Move file
 	if err := utils.MoveFile(item.NzbPath, newPath); err != nil {
-		return fmt.Errorf("failed to move NZB to failed folder: %w", err)
+		return fmt.Errorf("failed to move NZB to %s folder: %w", label, err)
 	}
 
 	// Update DB
 	if err := s.database.Repository.UpdateQueueItemNzbPath(ctx, item.ID, newPath); err != nil {
 		return fmt.Errorf("failed to update DB with new NZB path: %w", err)
 	}
+	// A dead-lettered item has left the queue; keep its dead-letter row in step.
+	if err := s.database.Repository.UpdateDeadLetterNzbPath(ctx, item.ID, newPath); err != nil {
+		return fmt.Errorf("failed to update DB with new NZB path: %w", err)
+	}
 
 	// Update struct
 	item.NzbPath = newPath
-	s.log.InfoContext(ctx, "Moved failed NZB to failed directory", "new_path", newPath)
+	s.log.InfoContext(ctx, "Moved NZB to "+label+" directory", "new_path", newPath)
 	return nil
 }
 
@@ -1085,20 +1138,14 @@ func (s *Service) ensurePersistentNzb(ctx context.Context, item *database.Import
 // regenerateNzbFile rebuilds the raw .nzb file for item from its .nzbz metadata store when the
 // raw file has been deleted (handleProcessingSuccess removes it after a successful import) but
 // the item is being reprocessed. It reconstructs the store path the same way processor.go writes
-// it and handleDownloadNZB reads it (configDir/.nzbs/{category}/{queueID}-{nzbBase}.nzbz), writes
+// it and handleDownloadNZB reads it (nzbRoot/{category}/{queueID}-{nzbBase}.nzbz), writes
 // the regenerated XML back into the persistent temp queue dir, and updates nzb_path in the DB.
 func (s *Service) regenerateNzbFile(ctx context.Context, item *database.ImportQueueItem) error {
 	if s.metadataService == nil {
 		return fmt.Errorf("raw NZB file is missing and no metadata service is available to regenerate it")
 	}
 
-	cfg := s.configGetter()
-	configDir := filepath.Dir(cfg.Database.Path)
-	if !filepath.IsAbs(configDir) {
-		if abs, err := filepath.Abs(configDir); err == nil {
-			configDir = abs
-		}
-	}
+	nzbRoot := s.configGetter().GetNzbRoot()
 
 	var categoryStr string
 	if item.Category != nil && *item.Category != "" {
@@ -1113,7 +1160,7 @@ func (s *Service) regenerateNzbFile(ctx context.Context, item *database.ImportQu
 	}
 
 	nzbBase := nzbtrim.TrimNzbExtension(filepath.Base(item.NzbPath))
-	storeRef := filepath.Join(configDir, ".nzbs", categoryStr, fmt.Sprintf("%d-%s.nzbz", item.ID, nzbBase))
+	storeRef := filepath.Join(nzbRoot, categoryStr, fmt.Sprintf("%d-%s.nzbz", item.ID, nzbBase))
 
 	nzbXML, err := s.metadataService.Store().RegenerateNZB(storeRef)
 	if err != nil {
@@ -1173,26 +1220,11 @@ func (s *Service) buildCategoryPath(category string) string {
 // resolveCategoryPath performs the actual category-to-directory resolution.
 func (s *Service) resolveCategoryPath(category string) string {
 	cfg := s.configGetter()
-	if cfg == nil || len(cfg.SABnzbd.Categories) == 0 {
-		if strings.EqualFold(category, config.DefaultCategoryName) {
-			return config.DefaultCategoryDir
-		}
-		return category
+	if cfg == nil {
+		cfg = &config.Config{}
 	}
-
-	for _, cat := range cfg.SABnzbd.Categories {
-		if strings.EqualFold(cat.Name, category) {
-			if cat.Dir != "" {
-				return cat.Dir
-			}
-			if strings.EqualFold(category, config.DefaultCategoryName) {
-				return config.DefaultCategoryDir
-			}
-			return category
-		}
-	}
-
-	return category
+	dir, _ := cfg.GetCategoryDir(category)
+	return dir
 }
 
 // resolveIndexerFromArrs asks the ARRs service to resolve the indexer for a
@@ -1282,8 +1314,11 @@ func (s *Service) handleProcessingSuccess(ctx context.Context, item *database.Im
 		return err
 	}
 
-	// Delete the on-disk NZB — the .nzbz store can regenerate it on demand.
-	if item.NzbPath != "" {
+	// Delete the on-disk NZB — the .nzbz store can regenerate it on demand. A
+	// queue NZB that already sits in the NZB root is the retained source when
+	// Import.RetainSourceNzb is on, so it stays.
+	retained := s.configGetter().GetRetainSourceNzb() && isWithinNzbRoot(s.GetNzbFolder(), item.NzbPath)
+	if item.NzbPath != "" && !retained {
 		if err := os.Remove(item.NzbPath); err != nil && !os.IsNotExist(err) {
 			s.log.WarnContext(ctx, "Failed to delete NZB after successful import",
 				"queue_id", item.ID, "nzb_path", item.NzbPath, "error", err)
@@ -1310,10 +1345,12 @@ func (s *Service) handleProcessingSuccess(ctx context.Context, item *database.Im
 	return nil
 }
 
-// OnItemClaimed implements queue.QueueEventListener. It broadcasts a queue-changed
-// notification whenever a worker claims a pending item (pending → processing transition).
+// OnItemClaimed implements queue.QueueEventListener. It broadcasts the item's
+// pending → processing transition plus a queue-changed notification whenever a
+// worker claims a pending item.
 func (s *Service) OnItemClaimed(ctx context.Context, item *database.ImportQueueItem) {
 	if s.broadcaster != nil {
+		s.broadcaster.NotifyStatus(int(item.ID), string(database.QueueStatusProcessing))
 		s.broadcaster.BroadcastQueueChanged()
 	}
 }
@@ -1430,7 +1467,13 @@ func (s *Service) handleProcessingFailure(ctx context.Context, item *database.Im
 			"error", processingErr)
 	}
 
-	// Mark as failed in queue database (no automatic retry)
+	// Transient failures go back to the queue when retries are enabled;
+	// non-retryable ones always fail here.
+	if s.retryTransientFailure(ctx, item, processingErr, errorMessage) {
+		return
+	}
+
+	// Mark as failed in queue database
 	if err := s.database.Repository.UpdateQueueItemStatus(ctx, item.ID, database.QueueStatusFailed, &errorMessage); err != nil {
 		s.log.ErrorContext(ctx, "Failed to mark item as failed", "queue_id", item.ID, "error", err)
 	} else {
@@ -1453,6 +1496,17 @@ func (s *Service) handleProcessingFailure(ctx context.Context, item *database.Im
 		s.broadcaster.BroadcastQueueChanged()
 	}
 
+	// Over-limit imports are kept for review when quarantine is enabled; they
+	// are exactly what the limits exist to keep away, so no fallback either.
+	if errors.Is(processingErr, ErrImportLimitExceeded) {
+		if q := s.configGetter().Import.QuarantineOverLimit; q != nil && *q {
+			if moveErr := s.MoveToQuarantineFolder(ctx, item); moveErr != nil {
+				s.log.ErrorContext(ctx, "Failed to move NZB to quarantine folder", "error", moveErr)
+			}
+			return
+		}
+	}
+
 	// Delegate fallback handling to post-processor
 	if err := s.postProcessor.HandleFailure(ctx, item, processingErr); err == nil {
 		// Fallback succeeded - remove item from queue since ownership transfers to external SABnzbd
@@ -1534,9 +1588,10 @@ func (s *Service) cleanupFailedItems(ctx context.Context) {
 		return
 	}
 
-	// Remove NZB files for deleted items
+	// Remove NZB files for deleted items. Quarantined NZBs stay for review.
+	quarantineDir := s.GetQuarantineNzbFolder() + string(filepath.Separator)
 	for _, item := range deletedItems {
-		if item.NzbPath != "" {
+		if item.NzbPath != "" && !strings.HasPrefix(item.NzbPath, quarantineDir) {
 			if rmErr := os.Remove(item.NzbPath); rmErr != nil && !os.IsNotExist(rmErr) {
 				s.log.WarnContext(ctx, "Failed to remove NZB file during cleanup", "file", item.NzbPath, "error", rmErr)
 			}
@@ -1551,16 +1606,24 @@ func (s *Service) cleanupFailedItems(ctx context.Context) {
 		"retention_hours", retentionHours)
 }
 
-// cleanupOldHistory deletes import_history records older than the configured retention period.
+// cleanupOldHistory purges import history older than the configured
+// retention period, adjusting the daily stats to match.
 func (s *Service) cleanupOldHistory(ctx context.Context) {
 	cfg := s.configGetter()
 	if cfg.Import.HistoryRetentionDays == nil || *cfg.Import.HistoryRetentionDays <= 0 {
 		return // disabled
 	}
 
-	cutoff := time.Now().Add(-time.Duration(*cfg.Import.HistoryRetentionDays) * 24 * time.Hour)
-	if err := s.database.Repository.DeleteImportHistoryOlderThan(ctx, cutoff); err != nil {
+	retentionDays := *cfg.Import.HistoryRetentionDays
+	purged, err := s.database.Repository.PurgeImportHistoryOlderThan(ctx, retentionDays)
+	if err != nil {
 		s.log.ErrorContext(ctx, "Failed to clean up old import history", "error", err)
+		return
+	}
+	if purged > 0 {
+		s.log.InfoContext(ctx, "Purged old import history",
+			"count", purged,
+			"retention_days", retentionDays)
 	}
 }
 
@@ -1870,6 +1933,24 @@ func (s *Service) runGrabbedIndexerSweeper(ctx context.Context) {
 	}
 }
 
+// runQueueStatsRecompute periodically rebuilds the persisted queue stats from
+// the live queue to correct drift.
+func (s *Service) runQueueStatsRecompute(ctx context.Context) {
+	ticker := time.NewTicker(1 * time.Hour)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := s.mainRepo.RecomputeQueueStats(ctx); err != nil {
+				s.log.WarnContext(ctx, "Failed to recompute queue stats", "error", err)
+			}
+		}
+	}
+}
+
 func (s *Service) pruneGrabbedIndexers() {
 	now := time.Now()
 	s.grabbedIndexers.Range(func(key, val any) bool {
@@ -167,6 +167,50 @@ func TestCalculateProcessVirtualDir_WindowsDriveLetterStripped(t *testing.T) {
 	assert.Equal(t, "/complete/rclone/altmount/nzb", filepath.ToSlash(got))
 }
 
+func TestCalculateProcessVirtualDir_MappedCategoryDir(t *testing.T) {
+	cfg := &config.Config{
+		Database: config.DatabaseConfig{Path: "/config/altmount.db"},
+		SABnzbd: config.SABnzbdConfig{
+			CompleteDir: "/complete",
+			Categories: []config.SABnzbdCategory{
+				{Name: "tv", Dir: "media/tv"},
+				{Name: "movies"},
+			},
+		},
+	}
+	getter := func() *config.Config { return cfg }
+	s := &Service{configGetter: getter}
+	proc := &Processor{configGetter: getter}
+
+	cases := []struct {
+		name, category, basePath, want string
+	}{
+		{"mapped dir", "tv", "", "/complete/media/tv"},
+		{"mapped dir, category case differs", "TV", "", "/complete/media/tv"},
+		{"mapped dir already in base path", "tv", "/complete/media/tv", "/complete/media/tv"},
+		{"no dir falls back to name", "movies", "", "/complete/movies"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			category := tc.category
+			item := &database.ImportQueueItem{
+				ID:       5,
+				NzbPath:  filepath.Join(os.TempDir(), ".altmount-queue", "5-Show.S01E01.nzb"),
+				Category: &category,
+			}
+			basePath := tc.basePath
+
+			got := filepath.ToSlash(s.calculateProcessVirtualDir(item, &basePath))
+			assert.Equal(t, tc.want, got)
+
+			// The importer must treat the mapped directory as a category root so
+			// releases get their own job folder inside it.
+			assert.True(t, proc.isCategoryFolder(got, &category))
+			assert.False(t, proc.isCategoryFolder(got+"/Show.S01E01", &category))
+		})
+	}
+}
+
 func TestSanitizeVirtualPath(t *testing.T) {
 	cases := []struct {
 		in, want string
@@ -1,12 +1,16 @@
 package importer
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/javi11/altmount/internal/database"
 	"github.com/javi11/altmount/internal/importer/queue"
+	"github.com/javi11/altmount/internal/progress"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -232,3 +236,27 @@ func TestRetryBackoff_JitterDistribution(t *testing.T) {
 			"Jitter spreads worker retries across time window")
 	})
 }
+
+func TestOnItemClaimedBroadcastsStatus(t *testing.T) {
+	broadcaster := progress.NewProgressBroadcaster()
+	subID, ch := broadcaster.Subscribe()
+	defer broadcaster.Unsubscribe(subID)
+
+	s := &Service{broadcaster: broadcaster}
+	s.OnItemClaimed(context.Background(), &database.ImportQueueItem{ID: 7})
+
+	var got []progress.ProgressUpdate
+	timeout := time.After(time.Second)
+	for len(got) < 2 {
+		select {
+		case update := <-ch:
+			got = append(got, update)
+		case <-timeout:
+			t.Fatalf("expected 2 events, got %d", len(got))
+		}
+	}
+
+	assert.Equal(t, 7, got[0].QueueID)
+	assert.Equal(t, string(database.QueueStatusProcessing), got[0].Status)
+	assert.Equal(t, "queue_changed", got[1].Status)
+}
@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/javi11/altmount/internal/config"
 	"github.com/javi11/altmount/internal/importer/filesystem"
 	"github.com/javi11/altmount/internal/importer/parser"
 	"github.com/javi11/altmount/internal/importer/utils"
@@ -17,7 +18,8 @@ import (
 
 // ProcessSingleFile processes a single file (creates and writes metadata).
 // Returns (virtualDir, writtenMetaPath, error). writtenMetaPath is the virtual path of the
-// metadata file written to disk; it is empty if no metadata was written.
+// metadata file written to disk; it is empty if no metadata was written, which
+// includes onPathCollision skip finding a healthy file already at the path.
 func ProcessSingleFile(
 	ctx context.Context,
 	virtualDir string,
@@ -29,6 +31,7 @@ func ProcessSingleFile(
 	filterSamples bool,
 	storeIndex map[string]int64,
 	storeRef string,
+	onPathCollision config.PathCollision,
 ) (string, string, error) {
 	// Validate file extension before processing
 	if !utils.HasAllowedFilesInRegular([]parser.ParsedFile{file}, allowedFileExtensions, filterSamples) {
@@ -38,13 +41,20 @@ func ProcessSingleFile(
 		return "", "", fmt.Errorf("file '%s' does not match allowed extensions (allowed: %v)", file.Filename, allowedFileExtensions)
 	}
 
-	// Create virtual file path, then ensure it is unique.
-	// If a healthy file already exists at this path, a _1, _2, … suffix is
-	// appended to the stem so the new import lands alongside the existing one
-	// rather than being silently skipped.
+	// Create virtual file path, then resolve any collision with a healthy
+	// file already there. By default a _1, _2, … suffix is appended to the
+	// stem so the new import lands alongside the existing one.
 	virtualFilePath := filepath.Join(virtualDir, file.Filename)
 	virtualFilePath = strings.ReplaceAll(virtualFilePath, string(filepath.Separator), "/")
-	virtualFilePath = filesystem.EnsureUniqueVirtualPath(virtualFilePath, metadataService)
+	resolved, ok := filesystem.ResolvePathCollision(virtualFilePath,
+		onPathCollision.Or(config.PathCollisionVersion), metadataService)
+	if !ok {
+		slog.InfoContext(ctx, "Skipping single file: healthy file already exists at path",
+			"file", file.Filename,
+			"virtual_path", virtualFilePath)
+		return virtualDir, "", nil
+	}
+	virtualFilePath = resolved
 
 	// Double check if this specific file is allowed
 	if !utils.IsAllowedFile(file.Filename, file.Size, allowedFileExtensions, filterSamples) {
@@ -86,6 +96,10 @@ func ProcessSingleFile(
 		par2Refs,
 		file.NzbdavID,
 	)
+	// PAR2 hashes the posted bytes, which for an encrypted file are ciphertext.
+	if file.Encryption == metapb.Encryption_NONE {
+		fileMeta.ContentMd5 = file.ContentMD5
+	}
 
 	// Write file metadata to disk (v3 store-backed when available, else v1)
 	if err := metadataService.WriteFileMetadataAuto(ctx, virtualFilePath, fileMeta, storeIndex, storeRef); err != nil {
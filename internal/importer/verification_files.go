@@ -0,0 +1,36 @@
+package importer
+
+import (
+	"context"
+
+	"github.com/javi11/altmount/internal/importer/multifile"
+	"github.com/javi11/altmount/internal/importer/parser"
+)
+
+// includeVerificationFiles writes the .par2/.sfv files of a single- or
+// multi-file import into dir, the directory its content landed in, for
+// Import.VerificationFiles = include. They bypass the allowed-extension and
+// sample filters, which exist for the content itself. A failure is logged and
+// leaves the import as it is: the content and its repair references are
+// already written.
+func (proc *Processor) includeVerificationFiles(ctx context.Context, dir string, files []parser.ParsedFile, nzbPath string, storeIndex map[string]int64, storeRef string) []string {
+	written, err := multifile.ProcessRegularFiles(
+		ctx,
+		dir,
+		files,
+		nil,
+		nzbPath,
+		proc.metadataService,
+		nil,
+		false,
+		nil,
+		storeIndex,
+		storeRef,
+		proc.configGetter().Import.OnPathCollision,
+	)
+	if err != nil {
+		proc.log.WarnContext(ctx, "Failed to include verification files",
+			"dir", dir, "files", len(files), "error", err)
+	}
+	return written
+}
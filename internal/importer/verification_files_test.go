@@ -0,0 +1,94 @@
+package importer
+
+import (
+	"bytes"
+	"slices"
+	"testing"
+
+	"github.com/javi11/altmount/internal/config"
+	"github.com/javi11/altmount/internal/testsupport/nzbbuild"
+	"github.com/javi11/altmount/internal/testsupport/par2gen"
+)
+
+// importWithVerificationFiles imports a two-episode release posted with a
+// PAR2 index and an SFV under mode, returning the env for inspection.
+func importWithVerificationFiles(t *testing.T, mode config.VerificationFileMode) *batteryEnv {
+	t.Helper()
+	env := newBatteryEnv(t)
+	env.cfg.Import.VerificationFiles = mode
+
+	c1 := bytes.Repeat([]byte("V"), 20_000)
+	c2 := bytes.Repeat([]byte("W"), 20_000)
+	par2Bytes := par2gen.Build(par2gen.FileEntry{Name: "Show.S01E01.mkv", Content: c1})
+	sfvBytes := []byte("Show.S01E01.mkv 00000000\nShow.S01E02.mkv 00000000\n")
+
+	nzb := nzbbuild.Build(
+		nzbbuild.File{Subject: "Show.S01E01.mkv", Segments: env.registerContent("verify-ep1", c1, 10_000, 1.0, nil)},
+		nzbbuild.File{Subject: "Show.S01E02.mkv", Segments: env.registerContent("verify-ep2", c2, 10_000, 1.0, nil)},
+		nzbbuild.File{Subject: "Show.S01.par2", Segments: env.registerContent("verify-par2", par2Bytes, len(par2Bytes), 1.0, nil)},
+		nzbbuild.File{Subject: "Show.S01.sfv", Segments: env.registerContent("verify-sfv", sfvBytes, len(sfvBytes), 1.0, nil)},
+	)
+	if _, _, err := env.runImport(nzb, "Show.S01"); err != nil {
+		t.Fatalf("import failed: %v", err)
+	}
+	return env
+}
+
+// par2RefNames returns the names of the verification files referenced by
+// virtualPath's metadata.
+func par2RefNames(env *batteryEnv, virtualPath string) []string {
+	var names []string
+	for _, ref := range env.readMeta(virtualPath).Par2Files {
+		names = append(names, ref.Filename)
+	}
+	slices.Sort(names)
+	return names
+}
+
+func TestVerificationFiles_RetainHidesButKeepsReferences(t *testing.T) {
+	for _, mode := range []config.VerificationFileMode{"", config.VerificationFilesRetain} {
+		env := importWithVerificationFiles(t, mode)
+
+		listing := env.listDir("/Show.S01")
+		slices.Sort(listing)
+		if want := []string{"Show.S01E01.mkv", "Show.S01E02.mkv"}; !slices.Equal(listing, want) {
+			t.Errorf("mode %q: listing = %v, want %v", mode, listing, want)
+		}
+
+		for _, ep := range []string{"/Show.S01/Show.S01E01.mkv", "/Show.S01/Show.S01E02.mkv"} {
+			if got, want := par2RefNames(env, ep), []string{"Show.S01.par2", "Show.S01.sfv"}; !slices.Equal(got, want) {
+				t.Errorf("mode %q: %s references = %v, want %v", mode, ep, got, want)
+			}
+		}
+		for _, ref := range env.readMeta("/Show.S01/Show.S01E01.mkv").Par2Files {
+			if len(ref.SegmentData) == 0 {
+				t.Errorf("mode %q: reference %s has no segments", mode, ref.Filename)
+			}
+		}
+	}
+}
+
+func TestVerificationFiles_Include(t *testing.T) {
+	env := importWithVerificationFiles(t, config.VerificationFilesInclude)
+
+	listing := env.listDir("/Show.S01")
+	slices.Sort(listing)
+	want := []string{"Show.S01.par2", "Show.S01.sfv", "Show.S01E01.mkv", "Show.S01E02.mkv"}
+	if !slices.Equal(listing, want) {
+		t.Errorf("listing = %v, want %v", listing, want)
+	}
+	if got := par2RefNames(env, "/Show.S01/Show.S01E01.mkv"); len(got) != 2 {
+		t.Errorf("references = %v, want the par2 and sfv", got)
+	}
+}
+
+func TestVerificationFiles_Drop(t *testing.T) {
+	env := importWithVerificationFiles(t, config.VerificationFilesDrop)
+
+	if listing := env.listDir("/Show.S01"); len(listing) != 2 {
+		t.Errorf("listing = %v, want only the episodes", listing)
+	}
+	if got := par2RefNames(env, "/Show.S01/Show.S01E01.mkv"); len(got) != 0 {
+		t.Errorf("references = %v, want none", got)
+	}
+}
@@ -0,0 +1,44 @@
+package metadata
+
+import (
+	"context"
+	"io/fs"
+	"path/filepath"
+)
+
+// HasPathConflict reports whether virtualPath exists both as a directory and
+// as a metadata file. Imports should never produce this; when one does, the
+// directory hides the file unless Metadata.OnPathConflict says otherwise.
+func (ms *MetadataService) HasPathConflict(virtualPath string) bool {
+	return ms.DirectoryExists(virtualPath) && ms.FileExists(virtualPath)
+}
+
+// ScanPathConflicts walks the metadata tree and returns, in walk order, every
+// virtual path that exists both as a directory and as a metadata file. The
+// walk is bounded by the maximum directory depth; a deeper tree returns the
+// conflicts found so far along with ErrMaxDepthExceeded.
+func (ms *MetadataService) ScanPathConflicts(ctx context.Context) ([]string, error) {
+	var conflicts []string
+	root := ms.RootPath()
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err != nil || !d.IsDir() || path == root {
+			return nil // skip unreadable entries
+		}
+		if err := ms.checkDepth(root, path); err != nil {
+			return err
+		}
+
+		virtualPath, relErr := filepath.Rel(root, path)
+		if relErr != nil {
+			return nil
+		}
+		if ms.FileExists(virtualPath) {
+			conflicts = append(conflicts, filepath.ToSlash(virtualPath))
+		}
+		return nil
+	})
+	return conflicts, err
+}
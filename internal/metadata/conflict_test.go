@@ -0,0 +1,40 @@
+package metadata
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	metapb "github.com/javi11/altmount/internal/metadata/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScanPathConflicts(t *testing.T) {
+	root := t.TempDir()
+	ms := NewMetadataService(root)
+	write := func(p string) {
+		meta := ms.CreateFileMetadata(1024, "test.nzb", metapb.FileStatus_FILE_STATUS_HEALTHY,
+			nil, metapb.Encryption_NONE, "", "", nil, nil, 0, nil, "")
+		require.NoError(t, ms.WriteFileMetadata(p, meta))
+	}
+
+	write("tv/Show/episode.mkv")
+	write("tv/Show") // conflicts with the directory above
+	write("movies/Film/film.mkv")
+	write("movies/Film.mkv") // sibling, not a conflict
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "tv", "Show", "Extras", "deep"), 0755))
+	write("tv/Show/Extras") // nested conflict
+
+	assert.True(t, ms.HasPathConflict("tv/Show"))
+	assert.False(t, ms.HasPathConflict("movies/Film"))
+
+	conflicts, err := ms.ScanPathConflicts(context.Background())
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"tv/Show", "tv/Show/Extras"}, conflicts)
+
+	ms.SetMaxDirectoryDepth(2)
+	_, err = ms.ScanPathConflicts(context.Background())
+	assert.ErrorIs(t, err, ErrMaxDepthExceeded)
+}
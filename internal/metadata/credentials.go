@@ -0,0 +1,162 @@
+package metadata
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	metapb "github.com/javi11/altmount/internal/metadata/proto"
+	"google.golang.org/protobuf/proto"
+)
+
+// ErrNoCredentialVerifier is returned when a rotation is requested without a
+// way to prove the new credentials actually decrypt the affected files.
+var ErrNoCredentialVerifier = errors.New("credential rotation requires a verifier")
+
+// CredentialVerifier checks that candidate — the file's metadata with the new
+// credentials applied and directory defaults merged — can decrypt a sample of
+// the file at virtualPath. It must not modify candidate.
+type CredentialVerifier func(ctx context.Context, virtualPath string, candidate *metapb.FileMetadata) error
+
+// CredentialRotationResult summarizes a credential rotation run.
+type CredentialRotationResult struct {
+	// Matched is the number of files that carried the old credentials.
+	Matched int
+	// Rotated lists the virtual paths whose metadata now holds the new credentials.
+	Rotated []string
+	// Failed maps virtual paths that matched but were left untouched to the
+	// verification or write error that stopped them.
+	Failed map[string]error
+}
+
+// RotateCredentials rewrites the rclone credentials of every file below
+// virtualDir whose stored salt equals oldSalt. Each file is only updated after
+// verify confirms newPassword/newSalt decrypt a sample of it, so a wrong
+// password never replaces working credentials. An empty oldSalt selects files
+// that rely on directory or global defaults, pinning the new credentials into
+// them.
+func (ms *MetadataService) RotateCredentials(ctx context.Context, virtualDir, oldSalt, newPassword, newSalt string, verify CredentialVerifier) (*CredentialRotationResult, error) {
+	match := func(stored *metapb.FileMetadata) bool {
+		return stored.Encryption == metapb.Encryption_RCLONE && stored.Salt == oldSalt
+	}
+	apply := func(m *metapb.FileMetadata) {
+		m.Password = newPassword
+		m.Salt = newSalt
+	}
+	return ms.rotateCredentials(ctx, virtualDir, match, apply, verify)
+}
+
+// RotateAesCredentials replaces oldKey with newKey on every file below
+// virtualDir that uses it, either as its own AES key or as the key of one of
+// its nested sources. IVs are kept: they come from the archive headers and do
+// not change with the password. As with RotateCredentials, each file is only
+// updated after verify accepts the new key; AES-CBC cannot reject a wrong key
+// by itself, so the verifier must check the decrypted sample against known
+// plaintext and fail files it cannot check.
+func (ms *MetadataService) RotateAesCredentials(ctx context.Context, virtualDir string, oldKey, newKey []byte, verify CredentialVerifier) (*CredentialRotationResult, error) {
+	if len(oldKey) == 0 || len(newKey) == 0 {
+		return nil, fmt.Errorf("old and new AES keys are required")
+	}
+
+	match := func(stored *metapb.FileMetadata) bool {
+		if bytes.Equal(stored.AesKey, oldKey) {
+			return true
+		}
+		for _, src := range stored.NestedSources {
+			if bytes.Equal(src.AesKey, oldKey) {
+				return true
+			}
+		}
+		return false
+	}
+	apply := func(m *metapb.FileMetadata) {
+		if bytes.Equal(m.AesKey, oldKey) {
+			m.AesKey = newKey
+		}
+		for _, src := range m.NestedSources {
+			if bytes.Equal(src.AesKey, oldKey) {
+				src.AesKey = newKey
+			}
+		}
+	}
+	return ms.rotateCredentials(ctx, virtualDir, match, apply, verify)
+}
+
+// rotateCredentials walks the .meta files below virtualDir and, for each file
+// whose stored metadata satisfies match, verifies and persists apply. Matching
+// uses the stored values (not inherited directory defaults) so only files that
+// actually carry the old credentials are selected.
+func (ms *MetadataService) rotateCredentials(
+	ctx context.Context,
+	virtualDir string,
+	match func(stored *metapb.FileMetadata) bool,
+	apply func(m *metapb.FileMetadata),
+	verify CredentialVerifier,
+) (*CredentialRotationResult, error) {
+	if verify == nil {
+		return nil, ErrNoCredentialVerifier
+	}
+
+	result := &CredentialRotationResult{Failed: make(map[string]error)}
+	root := ms.GetMetadataDirectoryPath(virtualDir)
+
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		if err != nil {
+			if path == root {
+				return err
+			}
+			return nil // skip unreadable entries
+		}
+		if d.IsDir() {
+			if d.Name() == ".ids" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		// .ids/ entries are symlinks to real .meta files; only visit the originals.
+		if d.Type()&os.ModeSymlink != 0 || !strings.HasSuffix(d.Name(), ".meta") {
+			return nil
+		}
+
+		rel, relErr := filepath.Rel(ms.RootPath(), path)
+		if relErr != nil {
+			return nil
+		}
+		virtualPath := filepath.ToSlash(strings.TrimSuffix(rel, ".meta"))
+
+		stored, readErr := ms.readFileMetadata(virtualPath)
+		if readErr != nil || stored == nil || !match(stored) {
+			return nil
+		}
+		result.Matched++
+
+		candidate := proto.Clone(stored).(*metapb.FileMetadata)
+		apply(candidate)
+		ms.applyDirectoryDefaults(virtualPath, candidate)
+
+		if verifyErr := verify(ctx, virtualPath, candidate); verifyErr != nil {
+			result.Failed[virtualPath] = fmt.Errorf("new credentials failed verification: %w", verifyErr)
+			return nil
+		}
+
+		if updateErr := ms.UpdateFileMetadata(virtualPath, apply); updateErr != nil {
+			result.Failed[virtualPath] = updateErr
+			return nil
+		}
+		result.Rotated = append(result.Rotated, virtualPath)
+		return nil
+	})
+	if err != nil {
+		return result, fmt.Errorf("failed to rotate credentials under %s: %w", virtualDir, err)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,164 @@
+package metadata
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+
+	"github.com/javi11/altmount/internal/encryption/rclone"
+	metapb "github.com/javi11/altmount/internal/metadata/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// encryptedStore holds rclone-encrypted file contents keyed by virtual path and
+// verifies candidate credentials by decrypting them, standing in for the
+// usenet-backed sample read used in production.
+type encryptedStore struct {
+	cipher *rclone.Cipher
+	blobs  map[string][]byte
+}
+
+func newEncryptedStore(t *testing.T) *encryptedStore {
+	t.Helper()
+	c, err := rclone.NewCipher(rclone.NameEncryptionOff, "", "", false, nil)
+	require.NoError(t, err)
+	return &encryptedStore{cipher: c, blobs: make(map[string][]byte)}
+}
+
+func (s *encryptedStore) put(t *testing.T, virtualPath, password, salt string, plaintext []byte) {
+	t.Helper()
+	k, err := rclone.GenerateKey(password, salt)
+	require.NoError(t, err)
+	r, err := s.cipher.EncryptData(bytes.NewReader(plaintext), k)
+	require.NoError(t, err)
+	blob, err := io.ReadAll(r)
+	require.NoError(t, err)
+	s.blobs[virtualPath] = blob
+}
+
+func (s *encryptedStore) decrypt(virtualPath, password, salt string) ([]byte, error) {
+	k, err := rclone.GenerateKey(password, salt)
+	if err != nil {
+		return nil, err
+	}
+	rc, err := s.cipher.DecryptData(io.NopCloser(bytes.NewReader(s.blobs[virtualPath])), k)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return io.ReadAll(rc)
+}
+
+func (s *encryptedStore) verify(_ context.Context, virtualPath string, candidate *metapb.FileMetadata) error {
+	_, err := s.decrypt(virtualPath, candidate.Password, candidate.Salt)
+	return err
+}
+
+func writeRcloneMeta(t *testing.T, ms *MetadataService, virtualPath, password, salt string) {
+	t.Helper()
+	meta := ms.CreateFileMetadata(
+		1024, "test.nzb", metapb.FileStatus_FILE_STATUS_HEALTHY,
+		nil, metapb.Encryption_RCLONE, password, salt, nil, nil, 0, nil, "",
+	)
+	require.NoError(t, ms.WriteFileMetadata(virtualPath, meta))
+}
+
+func TestRotateCredentials_UpdatesVerifiedFiles(t *testing.T) {
+	ms := NewMetadataService(t.TempDir())
+	store := newEncryptedStore(t)
+	plaintext := []byte("the quick brown fox jumps over the lazy dog")
+
+	// Stored credentials are stale; the data was really encrypted with new-pass.
+	writeRcloneMeta(t, ms, "movies/a/a.mkv", "old-pass", "old-salt")
+	store.put(t, "movies/a/a.mkv", "new-pass", "new-salt", plaintext)
+	// Same old salt but outside the requested directory.
+	writeRcloneMeta(t, ms, "tv/b/b.mkv", "old-pass", "old-salt")
+	store.put(t, "tv/b/b.mkv", "new-pass", "new-salt", plaintext)
+	// Different salt: not selected.
+	writeRcloneMeta(t, ms, "movies/c/c.mkv", "other-pass", "other-salt")
+
+	res, err := ms.RotateCredentials(context.Background(), "movies", "old-salt", "new-pass", "new-salt", store.verify)
+	require.NoError(t, err)
+	assert.Equal(t, 1, res.Matched)
+	assert.Equal(t, []string{"movies/a/a.mkv"}, res.Rotated)
+	assert.Empty(t, res.Failed)
+
+	got, err := ms.ReadFileMetadata("movies/a/a.mkv")
+	require.NoError(t, err)
+	assert.Equal(t, "new-pass", got.Password)
+	assert.Equal(t, "new-salt", got.Salt)
+
+	// The rotated metadata now decrypts the file.
+	decrypted, err := store.decrypt("movies/a/a.mkv", got.Password, got.Salt)
+	require.NoError(t, err)
+	assert.Equal(t, plaintext, decrypted)
+
+	untouched, err := ms.ReadFileMetadata("tv/b/b.mkv")
+	require.NoError(t, err)
+	assert.Equal(t, "old-pass", untouched.Password)
+
+	other, err := ms.ReadFileMetadata("movies/c/c.mkv")
+	require.NoError(t, err)
+	assert.Equal(t, "other-pass", other.Password)
+}
+
+func TestRotateCredentials_RejectsCredentialsThatDoNotDecrypt(t *testing.T) {
+	ms := NewMetadataService(t.TempDir())
+	store := newEncryptedStore(t)
+
+	writeRcloneMeta(t, ms, "movies/a/a.mkv", "real-pass", "salt")
+	store.put(t, "movies/a/a.mkv", "real-pass", "salt", []byte("payload"))
+
+	res, err := ms.RotateCredentials(context.Background(), "movies", "salt", "typo-pass", "salt", store.verify)
+	require.NoError(t, err)
+	assert.Equal(t, 1, res.Matched)
+	assert.Empty(t, res.Rotated)
+	assert.Contains(t, res.Failed, "movies/a/a.mkv")
+
+	got, err := ms.ReadFileMetadata("movies/a/a.mkv")
+	require.NoError(t, err)
+	assert.Equal(t, "real-pass", got.Password, "failed verification must keep the old credentials")
+}
+
+func TestRotateCredentials_RequiresVerifier(t *testing.T) {
+	ms := NewMetadataService(t.TempDir())
+
+	_, err := ms.RotateCredentials(context.Background(), "", "", "p", "s", nil)
+	assert.ErrorIs(t, err, ErrNoCredentialVerifier)
+}
+
+func TestRotateAesCredentials_UpdatesNestedSourceKeys(t *testing.T) {
+	ms := NewMetadataService(t.TempDir())
+	oldKey, newKey := []byte("old-key-0123456789abcdef"), []byte("new-key-0123456789abcdef")
+	iv := []byte("iv-0123456789abc")
+
+	meta := ms.CreateFileMetadata(
+		2048, "test.nzb", metapb.FileStatus_FILE_STATUS_HEALTHY,
+		nil, metapb.Encryption_NONE, "", "", nil, nil, 0, nil, "",
+	)
+	meta.NestedSources = []*metapb.NestedSegmentSource{
+		{AesKey: oldKey, AesIv: iv, InnerLength: 1024},
+		{AesKey: []byte("unrelated-key-0123456789"), AesIv: iv, InnerLength: 1024},
+	}
+	require.NoError(t, ms.WriteFileMetadata("movies/x/x.mkv", meta))
+
+	var verified []byte
+	verify := func(_ context.Context, _ string, candidate *metapb.FileMetadata) error {
+		verified = candidate.NestedSources[0].AesKey
+		return nil
+	}
+
+	res, err := ms.RotateAesCredentials(context.Background(), "movies", oldKey, newKey, verify)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"movies/x/x.mkv"}, res.Rotated)
+	assert.Equal(t, newKey, verified)
+
+	got, err := ms.ReadFileMetadata("movies/x/x.mkv")
+	require.NoError(t, err)
+	require.Len(t, got.NestedSources, 2)
+	assert.Equal(t, newKey, got.NestedSources[0].AesKey)
+	assert.Equal(t, iv, got.NestedSources[0].AesIv)
+	assert.Equal(t, []byte("unrelated-key-0123456789"), got.NestedSources[1].AesKey)
+}
@@ -0,0 +1,44 @@
+package metadata
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// defaultMaxDirectoryDepth bounds recursive metadata operations when no limit
+// is configured. Real libraries are a handful of levels deep.
+const defaultMaxDirectoryDepth = 64
+
+// ErrMaxDepthExceeded is returned by recursive metadata operations that reach
+// a directory nested deeper than the configured maximum.
+var ErrMaxDepthExceeded = errors.New("metadata directory tree exceeds max depth")
+
+// SetMaxDirectoryDepth sets how many directory levels below their starting
+// point recursive operations (empty-directory cleanup, directory deletes, ID
+// searches) descend before giving up. n <= 0 selects the default.
+func (ms *MetadataService) SetMaxDirectoryDepth(n int) {
+	ms.maxDirDepth.Store(int64(n))
+}
+
+func (ms *MetadataService) maxDirectoryDepth() int {
+	if n := ms.maxDirDepth.Load(); n > 0 {
+		return int(n)
+	}
+	return defaultMaxDirectoryDepth
+}
+
+// checkDepth returns ErrMaxDepthExceeded when dir lies more than the maximum
+// number of levels below root.
+func (ms *MetadataService) checkDepth(root, dir string) error {
+	rel, err := filepath.Rel(root, dir)
+	if err != nil || rel == "." {
+		return nil
+	}
+	depth := strings.Count(rel, string(filepath.Separator)) + 1
+	if limit := ms.maxDirectoryDepth(); depth > limit {
+		return fmt.Errorf("%w: %s is %d levels below %s (max %d)", ErrMaxDepthExceeded, dir, depth, root, limit)
+	}
+	return nil
+}
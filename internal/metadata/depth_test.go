@@ -0,0 +1,115 @@
+package metadata
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	metapb "github.com/javi11/altmount/internal/metadata/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// deepPath returns a virtual path nested depth directories below base.
+func deepPath(base string, depth int) string {
+	parts := []string{base}
+	for range depth {
+		parts = append(parts, "d")
+	}
+	return filepath.Join(parts...)
+}
+
+func writeDeepFile(t *testing.T, ms *MetadataService, dir, nzbdavID string) string {
+	t.Helper()
+	virtualPath := filepath.Join(dir, "file.mkv")
+	meta := ms.CreateFileMetadata(
+		1024, "test.nzb", metapb.FileStatus_FILE_STATUS_HEALTHY,
+		nil, metapb.Encryption_NONE, "", "", nil, nil, 0, nil, nzbdavID,
+	)
+	require.NoError(t, ms.WriteFileMetadata(virtualPath, meta))
+	if nzbdavID != "" {
+		require.NoError(t, os.WriteFile(ms.GetMetadataFilePath(virtualPath)+".id", []byte(nzbdavID), 0644))
+	}
+	return virtualPath
+}
+
+type nopStoreRefCounter struct{}
+
+func (nopStoreRefCounter) IncStoreRef(context.Context, string) error { return nil }
+func (nopStoreRefCounter) DecStoreRef(context.Context, string) (int64, error) {
+	return 0, nil
+}
+
+func TestCleanupEmptyDirectories_StopsAtMaxDepth(t *testing.T) {
+	ms := NewMetadataService(t.TempDir())
+	ms.SetMaxDirectoryDepth(5)
+
+	shallow := deepPath("shallow", 4)
+	deep := deepPath("deep", 8)
+	require.NoError(t, ms.CreateDirectory(shallow))
+	require.NoError(t, ms.CreateDirectory(deep))
+
+	require.NoError(t, ms.CleanupEmptyDirectories("", nil))
+
+	assert.False(t, ms.DirectoryExists("shallow"), "tree within the bound is cleaned up")
+	assert.True(t, ms.DirectoryExists(deepPath("deep", 4)), "levels above the bound are kept")
+	assert.True(t, ms.DirectoryExists(deep), "levels below the bound are never visited")
+}
+
+func TestDeleteDirectoryPreserving_RejectsTreeBeyondMaxDepth(t *testing.T) {
+	ms := NewMetadataService(t.TempDir())
+	ms.SetMaxDirectoryDepth(3)
+
+	file := writeDeepFile(t, ms, deepPath("show", 6), "")
+
+	kept, err := ms.DeleteDirectoryPreserving(context.Background(), "show", false, func(string) bool { return false })
+	require.ErrorIs(t, err, ErrMaxDepthExceeded)
+	assert.Zero(t, kept)
+	assert.True(t, ms.FileExists(file), "nothing is deleted when the walk is refused")
+
+	ms.SetMaxDirectoryDepth(0) // default bound admits the tree
+	_, err = ms.DeleteDirectoryPreserving(context.Background(), "show", false, func(string) bool { return false })
+	require.NoError(t, err)
+	assert.False(t, ms.FileExists(file))
+}
+
+func TestDeleteDirectory_RejectsTreeBeyondMaxDepthWithRefCounting(t *testing.T) {
+	ms := NewMetadataService(t.TempDir())
+	ms.SetStoreRefCounter(nopStoreRefCounter{})
+	ms.SetMaxDirectoryDepth(3)
+
+	file := writeDeepFile(t, ms, deepPath("show", 6), "")
+
+	err := ms.DeleteDirectory("show")
+	require.ErrorIs(t, err, ErrMaxDepthExceeded)
+	assert.True(t, ms.FileExists(file))
+}
+
+func TestFindFileByNzbdavID_SkipsBelowMaxDepth(t *testing.T) {
+	ms := NewMetadataService(t.TempDir())
+	ms.SetMaxDirectoryDepth(4)
+	ctx := context.Background()
+
+	shallow := writeDeepFile(t, ms, deepPath("a", 2), "shallow-id")
+	writeDeepFile(t, ms, deepPath("b", 10), "deep-id")
+
+	found, err := ms.FindFileByNzbdavID(ctx, "shallow-id")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.ToSlash(shallow), found)
+
+	found, err = ms.FindFileByNzbdavID(ctx, "deep-id")
+	require.NoError(t, err)
+	assert.Empty(t, found, "files below the bound are not searched")
+}
+
+func TestCheckDepth(t *testing.T) {
+	ms := NewMetadataService(t.TempDir())
+	ms.SetMaxDirectoryDepth(2)
+
+	assert.NoError(t, ms.checkDepth("/r", "/r"))
+	assert.NoError(t, ms.checkDepth("/r", "/r/a/b"))
+	err := ms.checkDepth("/r", "/r/a/b/c")
+	require.ErrorIs(t, err, ErrMaxDepthExceeded)
+	assert.Contains(t, err.Error(), "3 levels")
+}
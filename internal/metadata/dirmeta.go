@@ -0,0 +1,126 @@
+package metadata
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	metapb "github.com/javi11/altmount/internal/metadata/proto"
+	"google.golang.org/protobuf/proto"
+)
+
+// dirMetaFilename is the name of the optional per-directory defaults file.
+// It is stored next to the .meta files of the directory it applies to and is
+// never listed as a virtual file (its extension is not ".meta").
+const dirMetaFilename = ".dirmeta"
+
+// WriteDirectoryMetadata writes directory-level default metadata for virtualDir.
+// Only the credential fields (password, salt, AES key/IV) are meaningful; every
+// file below virtualDir whose own value is empty inherits it on read. The
+// defaults are encoded as a FileMetadata proto so no new schema is required.
+func (ms *MetadataService) WriteDirectoryMetadata(virtualDir string, defaults *metapb.FileMetadata) error {
+	ms.migrateMu.RLock()
+	defer ms.migrateMu.RUnlock()
+
+	data, err := proto.Marshal(&metapb.FileMetadata{
+		Password: defaults.Password,
+		Salt:     defaults.Salt,
+		AesKey:   defaults.AesKey,
+		AesIv:    defaults.AesIv,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal directory metadata: %w", err)
+	}
+
+	metadataPath := filepath.Join(ms.RootPath(), virtualDir, dirMetaFilename)
+	if err := ms.writeFileAtomic(metadataPath, dirMetaFilename, data); err != nil {
+		return fmt.Errorf("failed to write directory metadata file: %w", err)
+	}
+	return nil
+}
+
+// ReadDirectoryMetadata reads the defaults stored directly in virtualDir.
+// Returns nil, nil when the directory has no .dirmeta file.
+func (ms *MetadataService) ReadDirectoryMetadata(virtualDir string) (*metapb.FileMetadata, error) {
+	data, err := os.ReadFile(filepath.Join(ms.RootPath(), virtualDir, dirMetaFilename))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read directory metadata: %w", err)
+	}
+
+	defaults := &metapb.FileMetadata{}
+	if err := proto.Unmarshal(data, defaults); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal directory metadata: %w", err)
+	}
+	return defaults, nil
+}
+
+// DeleteDirectoryMetadata removes the .dirmeta file of virtualDir, if any.
+func (ms *MetadataService) DeleteDirectoryMetadata(virtualDir string) error {
+	ms.migrateMu.RLock()
+	defer ms.migrateMu.RUnlock()
+
+	err := os.Remove(filepath.Join(ms.RootPath(), virtualDir, dirMetaFilename))
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete directory metadata: %w", err)
+	}
+	return nil
+}
+
+// WithDirectoryDefaults returns a copy of metadata with the .dirmeta defaults
+// that apply to virtualPath merged in, exactly as ReadFileMetadata would see
+// it. metadata itself is left untouched, so it can still be written without
+// inlining inherited values.
+func (ms *MetadataService) WithDirectoryDefaults(virtualPath string, metadata *metapb.FileMetadata) *metapb.FileMetadata {
+	effective := proto.Clone(metadata).(*metapb.FileMetadata)
+	ms.applyDirectoryDefaults(virtualPath, effective)
+	return effective
+}
+
+// applyDirectoryDefaults fills empty credential fields of metadata from the
+// nearest .dirmeta found walking from the file's directory up to the root.
+// File-level values always win; a closer directory wins over a farther one.
+// Unreadable .dirmeta files are skipped so a bad default never blocks a read.
+// Unencrypted files never use the credentials, so they skip the walk and its
+// per-ancestor reads entirely.
+func (ms *MetadataService) applyDirectoryDefaults(virtualPath string, metadata *metapb.FileMetadata) {
+	if metadata.Encryption == metapb.Encryption_NONE {
+		return
+	}
+
+	needPassword := metadata.Password == ""
+	needSalt := metadata.Salt == ""
+	needKey := len(metadata.AesKey) == 0
+	needIv := len(metadata.AesIv) == 0
+
+	dir := filepath.Dir(strings.TrimPrefix(filepath.Clean("/"+virtualPath), "/"))
+	for needPassword || needSalt || needKey || needIv {
+		defaults, err := ms.ReadDirectoryMetadata(dir)
+		if err == nil && defaults != nil {
+			if needPassword && defaults.Password != "" {
+				metadata.Password = defaults.Password
+				needPassword = false
+			}
+			if needSalt && defaults.Salt != "" {
+				metadata.Salt = defaults.Salt
+				needSalt = false
+			}
+			if needKey && len(defaults.AesKey) > 0 {
+				metadata.AesKey = defaults.AesKey
+				needKey = false
+			}
+			if needIv && len(defaults.AesIv) > 0 {
+				metadata.AesIv = defaults.AesIv
+				needIv = false
+			}
+		}
+
+		if dir == "." || dir == "" {
+			break
+		}
+		dir = filepath.Dir(dir)
+	}
+}
@@ -0,0 +1,112 @@
+package metadata
+
+import (
+	"path/filepath"
+	"testing"
+
+	metapb "github.com/javi11/altmount/internal/metadata/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadFileMetadata_InheritsDirectoryDefaults(t *testing.T) {
+	root := t.TempDir()
+	ms := NewMetadataService(root)
+
+	require.NoError(t, ms.WriteDirectoryMetadata("movies", &metapb.FileMetadata{
+		Password: "dir-pass",
+		Salt:     "dir-salt",
+		AesKey:   []byte{1, 2, 3},
+		AesIv:    []byte{4, 5, 6},
+	}))
+
+	virtualPath := filepath.Join("movies", "film", "film.mkv")
+	meta := ms.CreateFileMetadata(
+		1024, "test.nzb", metapb.FileStatus_FILE_STATUS_HEALTHY,
+		nil, metapb.Encryption_RCLONE, "", "", nil, nil, 0, nil, "",
+	)
+	require.NoError(t, ms.WriteFileMetadata(virtualPath, meta))
+
+	got, err := ms.ReadFileMetadata(virtualPath)
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	assert.Equal(t, "dir-pass", got.Password)
+	assert.Equal(t, "dir-salt", got.Salt)
+	assert.Equal(t, []byte{1, 2, 3}, got.AesKey)
+	assert.Equal(t, []byte{4, 5, 6}, got.AesIv)
+
+	// The .dirmeta file must not show up as a virtual file.
+	files, err := ms.ListDirectory("movies")
+	require.NoError(t, err)
+	assert.Empty(t, files)
+}
+
+func TestReadFileMetadata_FileValuesOverrideDirectoryDefaults(t *testing.T) {
+	root := t.TempDir()
+	ms := NewMetadataService(root)
+
+	require.NoError(t, ms.WriteDirectoryMetadata("", &metapb.FileMetadata{
+		Password: "root-pass",
+		Salt:     "root-salt",
+	}))
+	require.NoError(t, ms.WriteDirectoryMetadata("movies", &metapb.FileMetadata{
+		Password: "dir-pass",
+	}))
+
+	virtualPath := filepath.Join("movies", "film.mkv")
+	meta := ms.CreateFileMetadata(
+		1024, "test.nzb", metapb.FileStatus_FILE_STATUS_HEALTHY,
+		nil, metapb.Encryption_RCLONE, "file-pass", "", nil, nil, 0, nil, "",
+	)
+	require.NoError(t, ms.WriteFileMetadata(virtualPath, meta))
+
+	got, err := ms.ReadFileMetadata(virtualPath)
+	require.NoError(t, err)
+	assert.Equal(t, "file-pass", got.Password, "file-level value must win")
+	assert.Equal(t, "root-salt", got.Salt, "missing value falls back to the nearest ancestor that has it")
+}
+
+func TestUpdateFileMetadata_DoesNotPersistInheritedDefaults(t *testing.T) {
+	root := t.TempDir()
+	ms := NewMetadataService(root)
+
+	require.NoError(t, ms.WriteDirectoryMetadata("movies", &metapb.FileMetadata{Password: "old-pass"}))
+
+	virtualPath := filepath.Join("movies", "film.mkv")
+	meta := ms.CreateFileMetadata(
+		1024, "test.nzb", metapb.FileStatus_FILE_STATUS_HEALTHY,
+		nil, metapb.Encryption_RCLONE, "", "", nil, nil, 0, nil, "",
+	)
+	require.NoError(t, ms.WriteFileMetadata(virtualPath, meta))
+	require.NoError(t, ms.UpdateFileStatus(virtualPath, metapb.FileStatus_FILE_STATUS_CORRUPTED))
+
+	// Rotating the directory credentials is picked up by every inheriting file.
+	require.NoError(t, ms.WriteDirectoryMetadata("movies", &metapb.FileMetadata{Password: "new-pass"}))
+
+	got, err := ms.ReadFileMetadata(virtualPath)
+	require.NoError(t, err)
+	assert.Equal(t, "new-pass", got.Password)
+	assert.Equal(t, metapb.FileStatus_FILE_STATUS_CORRUPTED, got.Status)
+}
+
+func TestReadFileMetadata_UnencryptedFileIgnoresDirectoryDefaults(t *testing.T) {
+	root := t.TempDir()
+	ms := NewMetadataService(root)
+
+	require.NoError(t, ms.WriteDirectoryMetadata("movies", &metapb.FileMetadata{
+		Password: "dir-pass",
+		AesKey:   []byte{1, 2, 3},
+	}))
+
+	virtualPath := filepath.Join("movies", "film.mkv")
+	meta := ms.CreateFileMetadata(
+		1024, "test.nzb", metapb.FileStatus_FILE_STATUS_HEALTHY,
+		nil, metapb.Encryption_NONE, "", "", nil, nil, 0, nil, "",
+	)
+	require.NoError(t, ms.WriteFileMetadata(virtualPath, meta))
+
+	got, err := ms.ReadFileMetadata(virtualPath)
+	require.NoError(t, err)
+	assert.Empty(t, got.Password)
+	assert.Empty(t, got.AesKey)
+}
@@ -8,7 +8,7 @@ import (
 
 // ExpandSharedOuterSources resolves NestedSegmentSource.SharedOuterSourceIndex
 // references in-place. Sources with a non-zero index inherit Segments, AesKey,
-// AesIv, and (if unset) InnerVolumeSize from
+// AesIv, Encryption, Password, Salt, and (if unset) InnerVolumeSize from
 // meta.SharedOuterSources[index-1]. Slice headers share their underlying
 // array — RAM cost is unchanged from the legacy layout. Safe to call on any
 // FileMetadata; a no-op when SharedOuterSources is empty.
@@ -34,6 +34,9 @@ func ExpandSharedOuterSources(meta *metapb.FileMetadata) error {
 		ns.Segments = shared.Segments
 		ns.AesKey = shared.AesKey
 		ns.AesIv = shared.AesIv
+		ns.Encryption = shared.Encryption
+		ns.Password = shared.Password
+		ns.Salt = shared.Salt
 		if ns.InnerVolumeSize == 0 {
 			ns.InnerVolumeSize = shared.InnerVolumeSize
 		}
@@ -0,0 +1,43 @@
+package metadata
+
+import (
+	"io/fs"
+	"os"
+)
+
+// FileOps is the set of filesystem calls the metadata writes, moves and
+// deletes run through. The default is the os package; tests swap in a slow
+// shim to check that callers' deadlines are honored, or a flaky one to check
+// that transient write failures are retried.
+type FileOps interface {
+	Stat(name string) (fs.FileInfo, error)
+	MkdirAll(path string, perm fs.FileMode) error
+	WriteFile(name string, data []byte, perm fs.FileMode) error
+	Rename(oldpath, newpath string) error
+	Remove(name string) error
+}
+
+type osFileOps struct{}
+
+func (osFileOps) Stat(name string) (fs.FileInfo, error)        { return os.Stat(name) }
+func (osFileOps) MkdirAll(path string, perm fs.FileMode) error { return os.MkdirAll(path, perm) }
+func (osFileOps) Rename(oldpath, newpath string) error         { return os.Rename(oldpath, newpath) }
+func (osFileOps) Remove(name string) error                     { return os.Remove(name) }
+
+func (osFileOps) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	return os.WriteFile(name, data, perm)
+}
+
+// SetFileOps replaces the filesystem used by metadata writes, MoveToCorrupted,
+// DeleteFileMetadataWithSourceNzb and .ids/ symlink updates. nil restores the
+// os package.
+func (ms *MetadataService) SetFileOps(ops FileOps) {
+	ms.ops = ops
+}
+
+func (ms *MetadataService) fileOps() FileOps {
+	if ms.ops == nil {
+		return osFileOps{}
+	}
+	return ms.ops
+}
@@ -0,0 +1,151 @@
+package metadata
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"testing"
+	"time"
+
+	metapb "github.com/javi11/altmount/internal/metadata/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// slowFileOps blocks every mutating call until release is closed, simulating
+// a hung network mount. Released calls fail without touching the disk, so an
+// abandoned operation can't race the test's temp-dir cleanup.
+var errShimReleased = errors.New("slow filesystem shim released")
+
+type slowFileOps struct {
+	osFileOps // Stat passes through
+	release   chan struct{}
+}
+
+func newSlowFileOps(t *testing.T) *slowFileOps {
+	s := &slowFileOps{release: make(chan struct{})}
+	t.Cleanup(func() { close(s.release) })
+	return s
+}
+
+func (s *slowFileOps) MkdirAll(string, fs.FileMode) error {
+	<-s.release
+	return errShimReleased
+}
+
+func (s *slowFileOps) Rename(string, string) error {
+	<-s.release
+	return errShimReleased
+}
+
+func (s *slowFileOps) Remove(string) error {
+	<-s.release
+	return errShimReleased
+}
+
+func writeTestMeta(t *testing.T, ms *MetadataService, virtualPath string) {
+	t.Helper()
+	meta := ms.CreateFileMetadata(1024, "test.nzb", metapb.FileStatus_FILE_STATUS_HEALTHY,
+		nil, metapb.Encryption_NONE, "", "", nil, nil, 0, nil, "")
+	require.NoError(t, ms.WriteFileMetadata(virtualPath, meta))
+}
+
+func TestMoveToCorrupted_HonorsDeadlineOnSlowFilesystem(t *testing.T) {
+	ms := NewMetadataService(t.TempDir())
+	writeTestMeta(t, ms, "movies/slow.mkv")
+	ms.SetFileOps(newSlowFileOps(t))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	err := ms.MoveToCorrupted(ctx, "movies/slow.mkv")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), 2*time.Second, "returned promptly instead of blocking")
+}
+
+func TestMoveToCorrupted_CancelledContext(t *testing.T) {
+	ms := NewMetadataService(t.TempDir())
+	writeTestMeta(t, ms, "movies/file.mkv")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(t, ms.MoveToCorrupted(ctx, "movies/file.mkv"), context.Canceled)
+	assert.True(t, ms.FileExists("movies/file.mkv"), "nothing is moved once the context is done")
+}
+
+func TestMoveToCorrupted_DefaultFileOps(t *testing.T) {
+	root := t.TempDir()
+	ms := NewMetadataService(root)
+	writeTestMeta(t, ms, "movies/file.mkv")
+
+	require.NoError(t, ms.MoveToCorrupted(context.Background(), "movies/file.mkv"))
+	assert.False(t, ms.FileExists("movies/file.mkv"))
+	_, err := os.Stat(root + "/corrupted_metadata/movies/file.mkv.meta")
+	assert.NoError(t, err)
+}
+
+func TestDeleteFileMetadataWithSourceNzb_HonorsDeadlineOnSlowFilesystem(t *testing.T) {
+	ms := NewMetadataService(t.TempDir())
+	writeTestMeta(t, ms, "movies/slow.mkv")
+	ms.SetFileOps(newSlowFileOps(t))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	err := ms.DeleteFileMetadataWithSourceNzb(ctx, "movies/slow.mkv", false)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), 2*time.Second, "returned promptly instead of blocking")
+}
+
+// gatedRemoveOps blocks Remove until release is closed, then removes for real.
+type gatedRemoveOps struct {
+	osFileOps
+	release chan struct{}
+}
+
+func (g *gatedRemoveOps) Remove(name string) error {
+	<-g.release
+	return os.Remove(name)
+}
+
+// decSignalCounter reports each decremented store ref on decs.
+type decSignalCounter struct{ decs chan string }
+
+func (decSignalCounter) IncStoreRef(context.Context, string) error { return nil }
+
+func (c decSignalCounter) DecStoreRef(ctx context.Context, storePath string) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	c.decs <- storePath
+	return 1, nil
+}
+
+func TestDeleteFileMetadataWithSourceNzb_ReleasesStoreRefAfterDeadline(t *testing.T) {
+	ms := NewMetadataService(t.TempDir())
+	counter := decSignalCounter{decs: make(chan string, 1)}
+	ms.SetStoreRefCounter(counter)
+	require.NoError(t, ms.WriteFileMetadata("movies/slow.mkv", &metapb.FileMetadata{
+		FileSize: 1, Status: metapb.FileStatus_FILE_STATUS_HEALTHY, StoreRef: "/stores/slow.nzbz",
+	}))
+	ops := &gatedRemoveOps{release: make(chan struct{})}
+	ms.SetFileOps(ops)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	err := ms.DeleteFileMetadataWithSourceNzb(ctx, "movies/slow.mkv", false)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// The abandoned delete finishes later and still releases its reference.
+	close(ops.release)
+	select {
+	case ref := <-counter.decs:
+		assert.Equal(t, "/stores/slow.nzbz", ref)
+	case <-time.After(2 * time.Second):
+		t.Fatal("store ref was not released after the delete completed")
+	}
+	assert.False(t, ms.FileExists("movies/slow.mkv"))
+}
@@ -0,0 +1,63 @@
+package metadata
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+
+	metapb "github.com/javi11/altmount/internal/metadata/proto"
+)
+
+// SetComputeFingerprint enables stamping files written by
+// WriteFileMetadataAuto with ContentFingerprint (Import.ComputeFingerprint).
+func (ms *MetadataService) SetComputeFingerprint(enabled bool) {
+	ms.computeFingerprint.Store(enabled)
+}
+
+// ContentFingerprint returns a cheap fingerprint of a file's content: its
+// size and a hash of its first and last segment message IDs. Two imports of
+// the same posted content yield the same fingerprint; a different post, or
+// the same post cut to a different size, does not. Returns "" when the file
+// has no segments to derive it from.
+func ContentFingerprint(meta *metapb.FileMetadata) string {
+	first, last := segmentIDBounds(meta)
+	if first == "" {
+		return ""
+	}
+	h := sha256.New()
+	h.Write([]byte(first))
+	h.Write([]byte{0})
+	h.Write([]byte(last))
+	return strconv.FormatInt(meta.FileSize, 10) + ":" + hex.EncodeToString(h.Sum(nil)[:8])
+}
+
+// segmentIDBounds returns the message IDs of the first and last segment a
+// file reads from: its own segments, or those of its first and last nested
+// sources, following shared outer sources.
+func segmentIDBounds(meta *metapb.FileMetadata) (first, last string) {
+	if n := len(meta.SegmentData); n > 0 {
+		return meta.SegmentData[0].Id, meta.SegmentData[n-1].Id
+	}
+	for _, ns := range meta.NestedSources {
+		segs := nestedSourceSegments(meta, ns)
+		if len(segs) == 0 {
+			continue
+		}
+		if first == "" {
+			first = segs[0].Id
+		}
+		last = segs[len(segs)-1].Id
+	}
+	return first, last
+}
+
+func nestedSourceSegments(meta *metapb.FileMetadata, ns *metapb.NestedSegmentSource) []*metapb.SegmentData {
+	if len(ns.Segments) > 0 || ns.SharedOuterSourceIndex <= 0 {
+		return ns.Segments
+	}
+	idx := int(ns.SharedOuterSourceIndex) - 1
+	if idx >= len(meta.SharedOuterSources) {
+		return nil
+	}
+	return meta.SharedOuterSources[idx].Segments
+}
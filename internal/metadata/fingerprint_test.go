@@ -0,0 +1,83 @@
+package metadata
+
+import (
+	"context"
+	"testing"
+
+	metapb "github.com/javi11/altmount/internal/metadata/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func fingerprintMeta(size int64, ids ...string) *metapb.FileMetadata {
+	meta := &metapb.FileMetadata{FileSize: size}
+	for _, id := range ids {
+		meta.SegmentData = append(meta.SegmentData, &metapb.SegmentData{Id: id, SegmentSize: 100, EndOffset: 99})
+	}
+	return meta
+}
+
+func TestContentFingerprint(t *testing.T) {
+	base := ContentFingerprint(fingerprintMeta(300, "a@x", "b@x", "c@x"))
+	require.NotEmpty(t, base)
+
+	t.Run("identical content matches", func(t *testing.T) {
+		assert.Equal(t, base, ContentFingerprint(fingerprintMeta(300, "a@x", "b@x", "c@x")))
+		// Only the first and last segment take part.
+		assert.Equal(t, base, ContentFingerprint(fingerprintMeta(300, "a@x", "other@x", "c@x")))
+	})
+
+	t.Run("different content differs", func(t *testing.T) {
+		assert.NotEqual(t, base, ContentFingerprint(fingerprintMeta(301, "a@x", "b@x", "c@x")), "size")
+		assert.NotEqual(t, base, ContentFingerprint(fingerprintMeta(300, "z@x", "b@x", "c@x")), "first segment")
+		assert.NotEqual(t, base, ContentFingerprint(fingerprintMeta(300, "a@x", "b@x", "z@x")), "last segment")
+		assert.NotEqual(t,
+			ContentFingerprint(fingerprintMeta(300, "ab", "c")),
+			ContentFingerprint(fingerprintMeta(300, "a", "bc")), "ID boundaries")
+	})
+
+	t.Run("nested sources", func(t *testing.T) {
+		flat := fingerprintMeta(300, "a@x", "c@x")
+		nested := &metapb.FileMetadata{
+			FileSize: 300,
+			NestedSources: []*metapb.NestedSegmentSource{
+				{SharedOuterSourceIndex: 1},
+				{Segments: []*metapb.SegmentData{{Id: "c@x"}}},
+			},
+			SharedOuterSources: []*metapb.NestedSegmentSource{
+				{Segments: []*metapb.SegmentData{{Id: "a@x"}, {Id: "b@x"}}},
+			},
+		}
+		assert.Equal(t, ContentFingerprint(flat), ContentFingerprint(nested))
+	})
+
+	t.Run("no segments", func(t *testing.T) {
+		assert.Empty(t, ContentFingerprint(&metapb.FileMetadata{FileSize: 300}))
+	})
+}
+
+func TestWriteFileMetadataAutoFingerprint(t *testing.T) {
+	ms := NewMetadataService(t.TempDir())
+	ctx := context.Background()
+
+	require.NoError(t, ms.WriteFileMetadataAuto(ctx, "movies/off.mkv", fingerprintMeta(300, "a@x", "c@x"), nil, ""))
+	got, err := ms.ReadFileMetadata("movies/off.mkv")
+	require.NoError(t, err)
+	assert.Empty(t, got.Fingerprint, "fingerprinting is off by default")
+
+	ms.SetComputeFingerprint(true)
+	for _, name := range []string{"movies/one.mkv", "movies/two.mkv"} {
+		require.NoError(t, ms.WriteFileMetadataAuto(ctx, name, fingerprintMeta(300, "a@x", "c@x"), nil, ""))
+	}
+	one, err := ms.ReadFileMetadata("movies/one.mkv")
+	require.NoError(t, err)
+	two, err := ms.ReadFileMetadata("movies/two.mkv")
+	require.NoError(t, err)
+	assert.NotEmpty(t, one.Fingerprint)
+	assert.Equal(t, one.Fingerprint, two.Fingerprint)
+
+	require.NoError(t, ms.WriteFileMetadataAuto(ctx, "movies/three.mkv", fingerprintMeta(300, "a@x", "d@x"), nil, ""))
+	three, err := ms.ReadFileMetadata("movies/three.mkv")
+	require.NoError(t, err)
+	assert.NotEqual(t, one.Fingerprint, three.Fingerprint)
+}
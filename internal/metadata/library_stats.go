@@ -0,0 +1,99 @@
+package metadata
+
+import (
+	"context"
+	"io/fs"
+	"log/slog"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// libraryStatsTTL is how long ComputeLibraryStats reuses a finished walk.
+const libraryStatsTTL = 5 * time.Minute
+
+// LibraryStats totals the library as recorded in metadata: the virtual files
+// and the directories holding them. .ids/ and corrupted_metadata/ are not part
+// of the library and are skipped.
+type LibraryStats struct {
+	TotalSize   int64     `json:"total_size"`
+	Files       int       `json:"files"`
+	Directories int       `json:"directories"`
+	ComputedAt  time.Time `json:"computed_at"`
+}
+
+// ComputeLibraryStats walks the metadata tree summing each file's FileSize.
+// The walk reads only the head of every .meta file, but it still touches the
+// whole tree, so a result is reused for libraryStatsTTL; concurrent callers
+// wait for a single walk. InvalidateLibraryStats forces the next call to walk
+// again.
+func (ms *MetadataService) ComputeLibraryStats(ctx context.Context) (LibraryStats, error) {
+	ms.libraryStatsMu.Lock()
+	defer ms.libraryStatsMu.Unlock()
+
+	if cached := ms.libraryStats; cached != nil && time.Since(cached.ComputedAt) < libraryStatsTTL {
+		return *cached, nil
+	}
+
+	stats, err := ms.walkLibraryStats(ctx)
+	if err != nil {
+		return LibraryStats{}, err
+	}
+	ms.libraryStats = &stats
+	return stats, nil
+}
+
+// InvalidateLibraryStats drops the cached ComputeLibraryStats result.
+func (ms *MetadataService) InvalidateLibraryStats() {
+	ms.libraryStatsMu.Lock()
+	ms.libraryStats = nil
+	ms.libraryStatsMu.Unlock()
+}
+
+func (ms *MetadataService) walkLibraryStats(ctx context.Context) (LibraryStats, error) {
+	var stats LibraryStats
+	root := ms.RootPath()
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		if err != nil {
+			return nil // skip unreadable entries
+		}
+		if d.IsDir() {
+			if path == root {
+				return nil
+			}
+			if d.Name() == ".ids" || d.Name() == "corrupted_metadata" {
+				return filepath.SkipDir
+			}
+			if depthErr := ms.checkDepth(root, path); depthErr != nil {
+				slog.WarnContext(ctx, "Skipping metadata directory below max depth", "path", path, "error", depthErr)
+				return filepath.SkipDir
+			}
+			stats.Directories++
+			return nil
+		}
+		if !strings.HasSuffix(d.Name(), ".meta") {
+			return nil
+		}
+
+		rel, relErr := filepath.Rel(root, path)
+		if relErr != nil {
+			return nil
+		}
+		lite, readErr := ms.ReadFileMetadataLite(strings.TrimSuffix(rel, ".meta"))
+		if readErr != nil || lite == nil {
+			slog.DebugContext(ctx, "Skipping unreadable metadata in library stats", "path", path, "error", readErr)
+			return nil
+		}
+		stats.Files++
+		stats.TotalSize += lite.FileSize
+		return nil
+	})
+	if err != nil {
+		return LibraryStats{}, err
+	}
+	stats.ComputedAt = time.Now()
+	return stats, nil
+}
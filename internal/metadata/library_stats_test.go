@@ -0,0 +1,87 @@
+package metadata
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	metapb "github.com/javi11/altmount/internal/metadata/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeSizedMeta(t *testing.T, ms *MetadataService, virtualPath string, size int64) {
+	t.Helper()
+	meta := ms.CreateFileMetadata(size, "test.nzb", metapb.FileStatus_FILE_STATUS_HEALTHY,
+		nil, metapb.Encryption_NONE, "", "", nil, nil, 0, nil, "")
+	require.NoError(t, ms.WriteFileMetadata(virtualPath, meta))
+}
+
+// newLibraryFixture writes a small library plus the bookkeeping entries the
+// stats must ignore.
+func newLibraryFixture(t *testing.T) *MetadataService {
+	t.Helper()
+	root := t.TempDir()
+	ms := NewMetadataService(root)
+
+	writeSizedMeta(t, ms, "movies/Film (2020)/film.mkv", 4_000_000_000)
+	writeSizedMeta(t, ms, "movies/Film (2020)/film.srt", 50_000)
+	writeSizedMeta(t, ms, "tv/Show/Season 1/s01e01.mkv", 1_500_000_000)
+	writeSizedMeta(t, ms, "tv/Show/Season 1/s01e02.mkv", 1_400_000_000)
+	writeSizedMeta(t, ms, "empty.bin", 0)
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "tv", "Show", "Season 2"), 0755))
+
+	// Not part of the library: ID symlinks, corrupted metadata, sidecars.
+	require.NoError(t, os.MkdirAll(filepath.Join(root, ".ids", "a", "b"), 0755))
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "corrupted_metadata", "movies"), 0755))
+	writeSizedMeta(t, ms, "corrupted_metadata/movies/bad.mkv", 9_000_000_000)
+	require.NoError(t, os.WriteFile(filepath.Join(root, "movies", "Film (2020)", "film.mkv.meta.id"), []byte("id-1"), 0644))
+	return ms
+}
+
+func TestComputeLibraryStats_Totals(t *testing.T) {
+	ms := newLibraryFixture(t)
+
+	stats, err := ms.ComputeLibraryStats(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, int64(4_000_000_000+50_000+1_500_000_000+1_400_000_000), stats.TotalSize)
+	assert.Equal(t, 5, stats.Files)
+	// movies, movies/Film (2020), tv, tv/Show, tv/Show/Season 1, tv/Show/Season 2
+	assert.Equal(t, 6, stats.Directories)
+	assert.False(t, stats.ComputedAt.IsZero())
+}
+
+func TestComputeLibraryStats_CachedUntilInvalidated(t *testing.T) {
+	ms := newLibraryFixture(t)
+	ctx := context.Background()
+
+	first, err := ms.ComputeLibraryStats(ctx)
+	require.NoError(t, err)
+
+	writeSizedMeta(t, ms, "movies/new.mkv", 1000)
+	cached, err := ms.ComputeLibraryStats(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, first, cached, "a recent result is reused")
+
+	ms.InvalidateLibraryStats()
+	fresh, err := ms.ComputeLibraryStats(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, first.Files+1, fresh.Files)
+	assert.Equal(t, first.TotalSize+1000, fresh.TotalSize)
+}
+
+func TestComputeLibraryStats_Cancelled(t *testing.T) {
+	ms := newLibraryFixture(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := ms.ComputeLibraryStats(ctx)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	// A failed walk is not cached.
+	stats, err := ms.ComputeLibraryStats(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 5, stats.Files)
+}
@@ -0,0 +1,254 @@
+package metadata
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	metapb "github.com/javi11/altmount/internal/metadata/proto"
+	"google.golang.org/protobuf/proto"
+)
+
+// migrateRootVerifySample is how many copied metadata files, and separately
+// how many .ids/ symlinks, MigrateRoot checks before switching roots.
+const migrateRootVerifySample = 32
+
+// MigrateRoot copies the metadata tree to newRoot and, once a sample of the
+// copy checks out, makes newRoot the active root. newRoot must be empty or
+// not exist yet, and must not overlap the current root.
+//
+// Symlinks are recreated as links: relative ones (every .ids/ link this
+// service writes) are kept verbatim, absolute ones pointing into the old root
+// are repointed into the new one. Absolute source NZB and store paths inside
+// the old root are rewritten the same way. Before the switch, a sample of the
+// copied files is parsed and a sample of the ID symlinks resolved; on any
+// failure the copy is removed and the old root stays active.
+//
+// Writes through this service wait while the copy runs and then land in
+// the new root. The old tree is left in place. Metadata.RootPath must be
+// updated for the move to survive a restart.
+func (ms *MetadataService) MigrateRoot(ctx context.Context, newRoot string) error {
+	ms.migrateMu.Lock()
+	defer ms.migrateMu.Unlock()
+
+	oldRoot, err := filepath.Abs(ms.RootPath())
+	if err != nil {
+		return fmt.Errorf("failed to resolve metadata root: %w", err)
+	}
+	newRoot, err = filepath.Abs(newRoot)
+	if err != nil {
+		return fmt.Errorf("failed to resolve new metadata root: %w", err)
+	}
+	if _, inside := rebasePath(newRoot, oldRoot, ""); inside {
+		return fmt.Errorf("new metadata root %s overlaps %s", newRoot, oldRoot)
+	}
+	if _, inside := rebasePath(oldRoot, newRoot, ""); inside {
+		return fmt.Errorf("new metadata root %s overlaps %s", newRoot, oldRoot)
+	}
+	if entries, err := os.ReadDir(newRoot); err == nil && len(entries) > 0 {
+		return fmt.Errorf("new metadata root %s is not empty", newRoot)
+	} else if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to read new metadata root: %w", err)
+	}
+	if err := os.MkdirAll(newRoot, 0755); err != nil {
+		return fmt.Errorf("failed to create new metadata root: %w", err)
+	}
+
+	metaFiles, links, err := ms.copyRoot(ctx, oldRoot, newRoot)
+	if err == nil {
+		err = verifyMigratedRoot(newRoot, metaFiles, links)
+	}
+	if err != nil {
+		clearDir(newRoot)
+		return err
+	}
+
+	ms.root.Store(&newRoot)
+	ms.liteCache.Purge()
+	ms.InvalidateLibraryStats()
+	slog.InfoContext(ctx, "Metadata root migrated",
+		"from", oldRoot, "to", newRoot, "files", len(metaFiles), "symlinks", len(links))
+	return nil
+}
+
+// copyRoot copies the tree under oldRoot to newRoot as MigrateRoot describes.
+// It returns the virtual paths of the copied metadata files and the paths,
+// relative to the root, of the symlinks whose targets resolved before the
+// copy.
+func (ms *MetadataService) copyRoot(ctx context.Context, oldRoot, newRoot string) (metaFiles, links []string, err error) {
+	err = filepath.WalkDir(oldRoot, func(path string, d fs.DirEntry, err error) error {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(oldRoot, path)
+		if err != nil || rel == "." {
+			return err
+		}
+		target := filepath.Join(newRoot, rel)
+
+		switch {
+		case d.IsDir():
+			// Stopping short would silently drop the deeper files.
+			if err := ms.checkDepth(oldRoot, path); err != nil {
+				return err
+			}
+			return os.MkdirAll(target, 0755)
+
+		case d.Type()&fs.ModeSymlink != 0:
+			dest, err := os.Readlink(path)
+			if err != nil {
+				return fmt.Errorf("failed to read symlink %s: %w", path, err)
+			}
+			if moved, ok := rebasePath(dest, oldRoot, newRoot); ok {
+				dest = moved
+			}
+			if err := os.Symlink(dest, target); err != nil {
+				return fmt.Errorf("failed to copy symlink %s: %w", path, err)
+			}
+			if _, err := os.Stat(path); err == nil {
+				links = append(links, rel)
+			}
+			return nil
+
+		case d.Type().IsRegular():
+			// Leftovers of interrupted atomic writes.
+			if strings.HasSuffix(d.Name(), ".tmp") {
+				return nil
+			}
+			rewrite := func(data []byte) []byte { return data }
+			if strings.HasSuffix(d.Name(), ".meta") {
+				rewrite = func(data []byte) []byte { return rebaseMetaPaths(data, oldRoot, newRoot) }
+				metaFiles = append(metaFiles, filepath.ToSlash(strings.TrimSuffix(rel, ".meta")))
+			}
+			if err := copyFile(path, target, rewrite); err != nil {
+				return fmt.Errorf("failed to copy %s: %w", path, err)
+			}
+			return nil
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to copy metadata root: %w", err)
+	}
+	return metaFiles, links, nil
+}
+
+// copyFile copies src to dst through rewrite, keeping src's permissions and
+// modification time.
+func copyFile(src, dst string, rewrite func([]byte) []byte) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+	data, err := io.ReadAll(in)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(dst, rewrite(data), info.Mode().Perm()); err != nil {
+		return err
+	}
+	return os.Chtimes(dst, info.ModTime(), info.ModTime())
+}
+
+// rebaseMetaPaths returns a metadata file's bytes with absolute source NZB
+// and store paths inside oldRoot moved under newRoot. Files that need no
+// change, or cannot be parsed, are returned as they are.
+func rebaseMetaPaths(data []byte, oldRoot, newRoot string) []byte {
+	var prefix []byte
+	payload := data
+	if isV3Meta(data) {
+		prefix, payload = metaMagicV3, data[len(metaMagicV3):]
+	}
+
+	meta := &metapb.FileMetadata{}
+	if err := proto.Unmarshal(payload, meta); err != nil {
+		return data
+	}
+	nzb, nzbMoved := rebasePath(meta.SourceNzbPath, oldRoot, newRoot)
+	ref, refMoved := rebasePath(meta.StoreRef, oldRoot, newRoot)
+	if !nzbMoved && !refMoved {
+		return data
+	}
+	if nzbMoved {
+		meta.SourceNzbPath = nzb
+	}
+	if refMoved {
+		meta.StoreRef = ref
+	}
+
+	raw, err := proto.Marshal(meta)
+	if err != nil {
+		return data
+	}
+	return append(append([]byte{}, prefix...), raw...)
+}
+
+// rebasePath reports whether p is an absolute path inside oldRoot and, if so,
+// returns it moved under newRoot.
+func rebasePath(p, oldRoot, newRoot string) (string, bool) {
+	if !filepath.IsAbs(p) {
+		return p, false
+	}
+	rel, err := filepath.Rel(oldRoot, p)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return p, false
+	}
+	return filepath.Join(newRoot, rel), true
+}
+
+// verifyMigratedRoot parses an evenly spread sample of the copied metadata
+// files through a service rooted at newRoot, and checks that a sample of the
+// copied symlinks still resolve.
+func verifyMigratedRoot(newRoot string, metaFiles, links []string) error {
+	probe := NewMetadataService(newRoot)
+	for _, virtualPath := range sample(metaFiles, migrateRootVerifySample) {
+		meta, err := probe.readFileMetadata(virtualPath)
+		if err != nil {
+			return fmt.Errorf("migrated metadata %s does not parse: %w", virtualPath, err)
+		}
+		if meta == nil {
+			return fmt.Errorf("migrated metadata %s is missing", virtualPath)
+		}
+	}
+	for _, rel := range sample(links, migrateRootVerifySample) {
+		if _, err := os.Stat(filepath.Join(newRoot, rel)); err != nil {
+			return fmt.Errorf("migrated symlink %s does not resolve: %w", rel, err)
+		}
+	}
+	return nil
+}
+
+// sample returns up to n items spread evenly across items, always including
+// the first.
+func sample(items []string, n int) []string {
+	if len(items) <= n {
+		return items
+	}
+	out := make([]string, 0, n)
+	for i := range n {
+		out = append(out, items[i*len(items)/n])
+	}
+	return out
+}
+
+// clearDir removes everything inside dir, leaving dir itself.
+func clearDir(dir string) {
+	entries, _ := os.ReadDir(dir)
+	for _, e := range entries {
+		_ = os.RemoveAll(filepath.Join(dir, e.Name()))
+	}
+}
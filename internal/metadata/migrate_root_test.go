@@ -0,0 +1,140 @@
+package metadata
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	metapb "github.com/javi11/altmount/internal/metadata/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMigrateRoot(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("MigrateRoot re-creates .ids symlinks under the new root")
+	}
+	oldRoot := t.TempDir()
+	ms := NewMetadataService(oldRoot)
+	writeTestMeta(t, ms, "movies/a.mkv")
+	writeTestMeta(t, ms, "tv/show/s01e01.mkv")
+	require.NoError(t, ms.RepairIDSymlink("abcdef123", "movies/a.mkv"))
+	linkTarget, err := os.Readlink(ms.idSymlinkPath("abcdef123"))
+	require.NoError(t, err)
+
+	insideNzb := filepath.Join(oldRoot, ".nzbs", "a.nzb")
+	require.NoError(t, ms.UpdateFileMetadata("movies/a.mkv", func(m *metapb.FileMetadata) {
+		m.SourceNzbPath = insideNzb
+	}))
+	require.NoError(t, ms.UpdateFileMetadata("tv/show/s01e01.mkv", func(m *metapb.FileMetadata) {
+		m.SourceNzbPath = "/elsewhere/show.nzb"
+	}))
+	modTime := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	require.NoError(t, os.Chtimes(ms.GetMetadataFilePath("movies/a.mkv"), modTime, modTime))
+
+	newRoot := filepath.Join(t.TempDir(), "metadata")
+	require.NoError(t, ms.MigrateRoot(context.Background(), newRoot))
+	assert.Equal(t, newRoot, ms.RootPath())
+
+	// Nothing is served from the old tree any more.
+	require.NoError(t, os.RemoveAll(oldRoot))
+
+	a, err := ms.ReadFileMetadata("movies/a.mkv")
+	require.NoError(t, err)
+	require.NotNil(t, a)
+	assert.Equal(t, filepath.Join(newRoot, ".nzbs", "a.nzb"), a.SourceNzbPath, "paths inside the old root move with it")
+	ep, err := ms.ReadFileMetadata("tv/show/s01e01.mkv")
+	require.NoError(t, err)
+	assert.Equal(t, "/elsewhere/show.nzb", ep.SourceNzbPath, "paths outside the old root are kept")
+
+	info, err := os.Stat(ms.GetMetadataFilePath("movies/a.mkv"))
+	require.NoError(t, err)
+	assert.True(t, info.ModTime().Equal(modTime), "modification times are kept")
+
+	// The relative ID symlink is copied verbatim and resolves in the new tree.
+	link := ms.idSymlinkPath("abcdef123")
+	require.True(t, strings.HasPrefix(link, newRoot+string(filepath.Separator)), link)
+	target, err := os.Readlink(link)
+	require.NoError(t, err)
+	assert.Equal(t, linkTarget, target)
+	_, err = os.Stat(link)
+	assert.NoError(t, err)
+
+	// New writes land in the new root.
+	writeTestMeta(t, ms, "movies/b.mkv")
+	assert.FileExists(t, filepath.Join(newRoot, "movies", "b.mkv.meta"))
+}
+
+func TestMigrateRoot_Refuses(t *testing.T) {
+	oldRoot := t.TempDir()
+	ms := NewMetadataService(oldRoot)
+	writeTestMeta(t, ms, "movies/a.mkv")
+
+	occupied := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(occupied, "keep.txt"), nil, 0644))
+
+	for name, newRoot := range map[string]string{
+		"same root":     oldRoot,
+		"inside root":   filepath.Join(oldRoot, "moved"),
+		"contains root": filepath.Dir(oldRoot),
+		"not empty":     occupied,
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert.Error(t, ms.MigrateRoot(context.Background(), newRoot))
+			assert.Equal(t, oldRoot, ms.RootPath())
+		})
+	}
+	assert.FileExists(t, filepath.Join(occupied, "keep.txt"))
+}
+
+func TestMigrateRoot_KeepsOldRootWhenVerificationFails(t *testing.T) {
+	oldRoot := t.TempDir()
+	ms := NewMetadataService(oldRoot)
+	writeTestMeta(t, ms, "movies/a.mkv")
+	require.NoError(t, os.WriteFile(ms.GetMetadataFilePath("movies/broken.mkv"), []byte{0xff, 0xff, 0xff}, 0644))
+
+	newRoot := t.TempDir()
+	err := ms.MigrateRoot(context.Background(), newRoot)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "movies/broken.mkv")
+
+	assert.Equal(t, oldRoot, ms.RootPath())
+	entries, err := os.ReadDir(newRoot)
+	require.NoError(t, err)
+	assert.Empty(t, entries, "the partial copy is removed")
+	meta, err := ms.ReadFileMetadata("movies/a.mkv")
+	require.NoError(t, err)
+	assert.NotNil(t, meta)
+}
+
+func TestMigrateRoot_WritesWaitForSwitch(t *testing.T) {
+	ms := NewMetadataService(t.TempDir())
+	newRoot := t.TempDir()
+
+	// Stand in for a migration in progress.
+	ms.migrateMu.Lock()
+	meta := ms.CreateFileMetadata(1024, "test.nzb", metapb.FileStatus_FILE_STATUS_HEALTHY,
+		nil, metapb.Encryption_NONE, "", "", nil, nil, 0, nil, "")
+	var writeErr error
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		writeErr = ms.WriteFileMetadata("movies/late.mkv", meta)
+	}()
+
+	select {
+	case <-done:
+		t.Fatal("write finished while the migration held the root")
+	case <-time.After(50 * time.Millisecond):
+	}
+	ms.root.Store(&newRoot)
+	ms.migrateMu.Unlock()
+	<-done
+
+	require.NoError(t, writeErr)
+	assert.FileExists(t, filepath.Join(newRoot, "movies", "late.mkv.meta"), "the write lands in the new root")
+}
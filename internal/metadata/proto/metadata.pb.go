@@ -133,6 +133,8 @@ type SegmentData struct {
 	StartOffset   int64                  `protobuf:"varint,3,opt,name=start_offset,json=startOffset,proto3" json:"start_offset,omitempty"` // Start byte offset in the data stream
 	EndOffset     int64                  `protobuf:"varint,4,opt,name=end_offset,json=endOffset,proto3" json:"end_offset,omitempty"`       // End byte offset in the data stream
 	Id            string                 `protobuf:"bytes,5,opt,name=id,proto3" json:"id,omitempty"`                                       // Usenet message ID
+	UsableStart   int64                  `protobuf:"varint,7,opt,name=usable_start,json=usableStart,proto3" json:"usable_start,omitempty"` // Junk bytes before the part's data in the decoded body
+	UsableEnd     int64                  `protobuf:"varint,8,opt,name=usable_end,json=usableEnd,proto3" json:"usable_end,omitempty"`       // Body offset of the part's last data byte; 0 = end of body
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -195,6 +197,20 @@ func (x *SegmentData) GetId() string {
 	return ""
 }
 
+func (x *SegmentData) GetUsableStart() int64 {
+	if x != nil {
+		return x.UsableStart
+	}
+	return 0
+}
+
+func (x *SegmentData) GetUsableEnd() int64 {
+	if x != nil {
+		return x.UsableEnd
+	}
+	return 0
+}
+
 // Par2FileReference stores information about PAR2 repair files
 type Par2FileReference struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -290,8 +306,14 @@ type NestedSegmentSource struct {
 	// layout, so old .meta files keep working without migration.
 	SharedOuterSourceIndex int32         `protobuf:"varint,7,opt,name=shared_outer_source_index,json=sharedOuterSourceIndex,proto3" json:"shared_outer_source_index,omitempty"`
 	SegmentRefs            []*SegmentRef `protobuf:"bytes,8,rep,name=segment_refs,json=segmentRefs,proto3" json:"segment_refs,omitempty"`
-	unknownFields          protoimpl.UnknownFields
-	sizeCache              protoimpl.SizeCache
+	// Encryption of the inner volume data. NONE with aes_key set is the legacy
+	// AES layout; RCLONE decrypts with password/salt, falling back to the
+	// global rclone credentials when they are empty.
+	Encryption    Encryption `protobuf:"varint,9,opt,name=encryption,proto3,enum=metadata.Encryption" json:"encryption,omitempty"`
+	Password      string     `protobuf:"bytes,10,opt,name=password,proto3" json:"password,omitempty"` // Password for rclone-encrypted inner volumes
+	Salt          string     `protobuf:"bytes,11,opt,name=salt,proto3" json:"salt,omitempty"`         // Salt for rclone-encrypted inner volumes
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *NestedSegmentSource) Reset() {
@@ -380,6 +402,27 @@ func (x *NestedSegmentSource) GetSegmentRefs() []*SegmentRef {
 	return nil
 }
 
+func (x *NestedSegmentSource) GetEncryption() Encryption {
+	if x != nil {
+		return x.Encryption
+	}
+	return Encryption_NONE
+}
+
+func (x *NestedSegmentSource) GetPassword() string {
+	if x != nil {
+		return x.Password
+	}
+	return ""
+}
+
+func (x *NestedSegmentSource) GetSalt() string {
+	if x != nil {
+		return x.Salt
+	}
+	return ""
+}
+
 // ClipBoundary is one clip in a byte-concatenated multi-clip BD main feature.
 // byte_len is the clip's size in the virtual file (a whole number of 192-byte
 // BDAV source packets). delta_90k is the signed 90 kHz offset added to PTS/DTS
@@ -529,10 +572,14 @@ type FileMetadata struct {
 	// inner_offset + inner_length per-extent. Cuts the on-disk .meta size
 	// from O(extents * segments) to O(extents + segments) for these files.
 	SharedOuterSources []*NestedSegmentSource `protobuf:"bytes,16,rep,name=shared_outer_sources,json=sharedOuterSources,proto3" json:"shared_outer_sources,omitempty"`
-	StoreRef           string                 `protobuf:"bytes,18,opt,name=store_ref,json=storeRef,proto3" json:"store_ref,omitempty"`          // id/path of the shared NzbStore
-	SegmentRefs        []*SegmentRef          `protobuf:"bytes,19,rep,name=segment_refs,json=segmentRefs,proto3" json:"segment_refs,omitempty"` // v3 replacement for segment_data
-	SegmentRuns        []*SegmentRun          `protobuf:"bytes,20,rep,name=segment_runs,json=segmentRuns,proto3" json:"segment_runs,omitempty"` // compact run encoding; preferred over segment_refs when present
-	KnownHoles         []*HoleRun             `protobuf:"bytes,21,rep,name=known_holes,json=knownHoles,proto3" json:"known_holes,omitempty"`    // segments confirmed missing on all providers (zero-filled during playback)
+	StoreRef           string                 `protobuf:"bytes,18,opt,name=store_ref,json=storeRef,proto3" json:"store_ref,omitempty"`                            // id/path of the shared NzbStore
+	SegmentRefs        []*SegmentRef          `protobuf:"bytes,19,rep,name=segment_refs,json=segmentRefs,proto3" json:"segment_refs,omitempty"`                   // v3 replacement for segment_data
+	SegmentRuns        []*SegmentRun          `protobuf:"bytes,20,rep,name=segment_runs,json=segmentRuns,proto3" json:"segment_runs,omitempty"`                   // compact run encoding; preferred over segment_refs when present
+	KnownHoles         []*HoleRun             `protobuf:"bytes,21,rep,name=known_holes,json=knownHoles,proto3" json:"known_holes,omitempty"`                      // segments confirmed missing on all providers (zero-filled during playback)
+	Fingerprint        string                 `protobuf:"bytes,22,opt,name=fingerprint,proto3" json:"fingerprint,omitempty"`                                      // quick content fingerprint (size + first/last segment ids); empty when not computed
+	PreferredProvider  string                 `protobuf:"bytes,23,opt,name=preferred_provider,json=preferredProvider,proto3" json:"preferred_provider,omitempty"` // provider id or name reads should try first; empty uses normal provider selection
+	ContentMd5         []byte                 `protobuf:"bytes,24,opt,name=content_md5,json=contentMd5,proto3" json:"content_md5,omitempty"`                      // MD5 of the whole decoded file from its PAR2 description; empty when unknown
+	Growing            bool                   `protobuf:"varint,25,opt,name=growing,proto3" json:"growing,omitempty"`                                             // import is still appending segments; reads at the end may wait for more
 	unknownFields      protoimpl.UnknownFields
 	sizeCache          protoimpl.SizeCache
 }
@@ -714,6 +761,34 @@ func (x *FileMetadata) GetKnownHoles() []*HoleRun {
 	return nil
 }
 
+func (x *FileMetadata) GetFingerprint() string {
+	if x != nil {
+		return x.Fingerprint
+	}
+	return ""
+}
+
+func (x *FileMetadata) GetPreferredProvider() string {
+	if x != nil {
+		return x.PreferredProvider
+	}
+	return ""
+}
+
+func (x *FileMetadata) GetContentMd5() []byte {
+	if x != nil {
+		return x.ContentMd5
+	}
+	return nil
+}
+
+func (x *FileMetadata) GetGrowing() bool {
+	if x != nil {
+		return x.Growing
+	}
+	return false
+}
+
 // NzbStore is the complete original NZB for a release, stored zstd-compressed at
 // the (renamed) source_nzb_path. Single source of truth for streaming + NZB regen.
 type NzbStore struct {
@@ -903,6 +978,8 @@ type SegmentRef struct {
 	StartOffset   int64                  `protobuf:"varint,2,opt,name=start_offset,json=startOffset,proto3" json:"start_offset,omitempty"` // usable byte range within that segment
 	EndOffset     int64                  `protobuf:"varint,3,opt,name=end_offset,json=endOffset,proto3" json:"end_offset,omitempty"`
 	DecodedBytes  int64                  `protobuf:"varint,4,opt,name=decoded_bytes,json=decodedBytes,proto3" json:"decoded_bytes,omitempty"` // actual decoded segment size; 0 means use NzbSeg.bytes
+	UsableStart   int64                  `protobuf:"varint,5,opt,name=usable_start,json=usableStart,proto3" json:"usable_start,omitempty"`    // SegmentData.usable_start
+	UsableEnd     int64                  `protobuf:"varint,6,opt,name=usable_end,json=usableEnd,proto3" json:"usable_end,omitempty"`          // SegmentData.usable_end
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -965,6 +1042,20 @@ func (x *SegmentRef) GetDecodedBytes() int64 {
 	return 0
 }
 
+func (x *SegmentRef) GetUsableStart() int64 {
+	if x != nil {
+		return x.UsableStart
+	}
+	return 0
+}
+
+func (x *SegmentRef) GetUsableEnd() int64 {
+	if x != nil {
+		return x.UsableEnd
+	}
+	return 0
+}
+
 // SegmentRun compactly encodes a consecutive range of full-segment refs:
 // start_offset=0, end_offset=decoded_bytes-1, store_index increasing by 1.
 // Used in place of repeated SegmentRef when a file's segments map 1:1 onto a
@@ -1033,19 +1124,22 @@ var File_internal_metadata_proto_metadata_proto protoreflect.FileDescriptor
 
 const file_internal_metadata_proto_metadata_proto_rawDesc = "" +
 	"\n" +
-	"&internal/metadata/proto/metadata.proto\x12\bmetadata\"\x82\x01\n" +
+	"&internal/metadata/proto/metadata.proto\x12\bmetadata\"\xc4\x01\n" +
 	"\vSegmentData\x12!\n" +
 	"\fsegment_size\x18\x01 \x01(\x03R\vsegmentSize\x12!\n" +
 	"\fstart_offset\x18\x03 \x01(\x03R\vstartOffset\x12\x1d\n" +
 	"\n" +
 	"end_offset\x18\x04 \x01(\x03R\tendOffset\x12\x0e\n" +
-	"\x02id\x18\x05 \x01(\tR\x02id\"\xf8\x01\n" +
+	"\x02id\x18\x05 \x01(\tR\x02id\x12!\n" +
+	"\fusable_start\x18\a \x01(\x03R\vusableStart\x12\x1d\n" +
+	"\n" +
+	"usable_end\x18\b \x01(\x03R\tusableEnd\"\xf8\x01\n" +
 	"\x11Par2FileReference\x12\x1a\n" +
 	"\bfilename\x18\x01 \x01(\tR\bfilename\x12\x1b\n" +
 	"\tfile_size\x18\x02 \x01(\x03R\bfileSize\x128\n" +
 	"\fsegment_data\x18\x03 \x03(\v2\x15.metadata.SegmentDataR\vsegmentData\x127\n" +
 	"\fsegment_refs\x18\x04 \x03(\v2\x14.metadata.SegmentRefR\vsegmentRefs\x127\n" +
-	"\fsegment_runs\x18\x05 \x03(\v2\x14.metadata.SegmentRunR\vsegmentRuns\"\xde\x02\n" +
+	"\fsegment_runs\x18\x05 \x03(\v2\x14.metadata.SegmentRunR\vsegmentRuns\"\xc4\x03\n" +
 	"\x13NestedSegmentSource\x121\n" +
 	"\bsegments\x18\x01 \x03(\v2\x15.metadata.SegmentDataR\bsegments\x12\x17\n" +
 	"\aaes_key\x18\x02 \x01(\fR\x06aesKey\x12\x15\n" +
@@ -1054,13 +1148,19 @@ const file_internal_metadata_proto_metadata_proto_rawDesc = "" +
 	"\finner_length\x18\x05 \x01(\x03R\vinnerLength\x12*\n" +
 	"\x11inner_volume_size\x18\x06 \x01(\x03R\x0finnerVolumeSize\x129\n" +
 	"\x19shared_outer_source_index\x18\a \x01(\x05R\x16sharedOuterSourceIndex\x127\n" +
-	"\fsegment_refs\x18\b \x03(\v2\x14.metadata.SegmentRefR\vsegmentRefs\"F\n" +
+	"\fsegment_refs\x18\b \x03(\v2\x14.metadata.SegmentRefR\vsegmentRefs\x124\n" +
+	"\n" +
+	"encryption\x18\t \x01(\x0e2\x14.metadata.EncryptionR\n" +
+	"encryption\x12\x1a\n" +
+	"\bpassword\x18\n" +
+	" \x01(\tR\bpassword\x12\x12\n" +
+	"\x04salt\x18\v \x01(\tR\x04salt\"F\n" +
 	"\fClipBoundary\x12\x19\n" +
 	"\bbyte_len\x18\x01 \x01(\x03R\abyteLen\x12\x1b\n" +
 	"\tdelta_90k\x18\x02 \x01(\x03R\bdelta90k\"D\n" +
 	"\aHoleRun\x12#\n" +
 	"\rstart_segment\x18\x01 \x01(\x03R\fstartSegment\x12\x14\n" +
-	"\x05count\x18\x02 \x01(\x03R\x05count\"\xa8\a\n" +
+	"\x05count\x18\x02 \x01(\x03R\x05count\"\xb4\b\n" +
 	"\fFileMetadata\x12\x1b\n" +
 	"\tfile_size\x18\x01 \x01(\x03R\bfileSize\x12&\n" +
 	"\x0fsource_nzb_path\x18\x02 \x01(\tR\rsourceNzbPath\x12,\n" +
@@ -1089,7 +1189,11 @@ const file_internal_metadata_proto_metadata_proto_rawDesc = "" +
 	"\fsegment_refs\x18\x13 \x03(\v2\x14.metadata.SegmentRefR\vsegmentRefs\x127\n" +
 	"\fsegment_runs\x18\x14 \x03(\v2\x14.metadata.SegmentRunR\vsegmentRuns\x122\n" +
 	"\vknown_holes\x18\x15 \x03(\v2\x11.metadata.HoleRunR\n" +
-	"knownHoles\"8\n" +
+	"knownHoles\x12 \n" +
+	"\vfingerprint\x18\x16 \x01(\tR\vfingerprint\x12-\n" +
+	"\x12preferred_provider\x18\x17 \x01(\tR\x11preferredProvider\x12\x1f\n" +
+	"\vcontent_md5\x18\x18 \x01(\fR\ncontentMd5\x12\x18\n" +
+	"\agrowing\x18\x19 \x01(\bR\agrowing\"8\n" +
 	"\bNzbStore\x12,\n" +
 	"\x05files\x18\x01 \x03(\v2\x16.metadata.NzbFileEntryR\x05files\"\x9a\x01\n" +
 	"\fNzbFileEntry\x12\x18\n" +
@@ -1101,7 +1205,7 @@ const file_internal_metadata_proto_metadata_proto_rawDesc = "" +
 	"\x06NzbSeg\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12\x16\n" +
 	"\x06number\x18\x02 \x01(\x05R\x06number\x12\x14\n" +
-	"\x05bytes\x18\x03 \x01(\x03R\x05bytes\"\x94\x01\n" +
+	"\x05bytes\x18\x03 \x01(\x03R\x05bytes\"\xd6\x01\n" +
 	"\n" +
 	"SegmentRef\x12\x1f\n" +
 	"\vstore_index\x18\x01 \x01(\x03R\n" +
@@ -1109,7 +1213,10 @@ const file_internal_metadata_proto_metadata_proto_rawDesc = "" +
 	"\fstart_offset\x18\x02 \x01(\x03R\vstartOffset\x12\x1d\n" +
 	"\n" +
 	"end_offset\x18\x03 \x01(\x03R\tendOffset\x12#\n" +
-	"\rdecoded_bytes\x18\x04 \x01(\x03R\fdecodedBytes\"q\n" +
+	"\rdecoded_bytes\x18\x04 \x01(\x03R\fdecodedBytes\x12!\n" +
+	"\fusable_start\x18\x05 \x01(\x03R\vusableStart\x12\x1d\n" +
+	"\n" +
+	"usable_end\x18\x06 \x01(\x03R\tusableEnd\"q\n" +
 	"\n" +
 	"SegmentRun\x12(\n" +
 	"\x10base_store_index\x18\x01 \x01(\x03R\x0ebaseStoreIndex\x12\x14\n" +
@@ -1164,23 +1271,24 @@ var file_internal_metadata_proto_metadata_proto_depIdxs = []int32{
 	12, // 2: metadata.Par2FileReference.segment_runs:type_name -> metadata.SegmentRun
 	2,  // 3: metadata.NestedSegmentSource.segments:type_name -> metadata.SegmentData
 	11, // 4: metadata.NestedSegmentSource.segment_refs:type_name -> metadata.SegmentRef
-	1,  // 5: metadata.FileMetadata.status:type_name -> metadata.FileStatus
-	0,  // 6: metadata.FileMetadata.encryption:type_name -> metadata.Encryption
-	2,  // 7: metadata.FileMetadata.segment_data:type_name -> metadata.SegmentData
-	3,  // 8: metadata.FileMetadata.par2_files:type_name -> metadata.Par2FileReference
-	4,  // 9: metadata.FileMetadata.nested_sources:type_name -> metadata.NestedSegmentSource
-	5,  // 10: metadata.FileMetadata.clip_boundaries:type_name -> metadata.ClipBoundary
-	4,  // 11: metadata.FileMetadata.shared_outer_sources:type_name -> metadata.NestedSegmentSource
-	11, // 12: metadata.FileMetadata.segment_refs:type_name -> metadata.SegmentRef
-	12, // 13: metadata.FileMetadata.segment_runs:type_name -> metadata.SegmentRun
-	6,  // 14: metadata.FileMetadata.known_holes:type_name -> metadata.HoleRun
-	9,  // 15: metadata.NzbStore.files:type_name -> metadata.NzbFileEntry
-	10, // 16: metadata.NzbFileEntry.segments:type_name -> metadata.NzbSeg
-	17, // [17:17] is the sub-list for method output_type
-	17, // [17:17] is the sub-list for method input_type
-	17, // [17:17] is the sub-list for extension type_name
-	17, // [17:17] is the sub-list for extension extendee
-	0,  // [0:17] is the sub-list for field type_name
+	0,  // 5: metadata.NestedSegmentSource.encryption:type_name -> metadata.Encryption
+	1,  // 6: metadata.FileMetadata.status:type_name -> metadata.FileStatus
+	0,  // 7: metadata.FileMetadata.encryption:type_name -> metadata.Encryption
+	2,  // 8: metadata.FileMetadata.segment_data:type_name -> metadata.SegmentData
+	3,  // 9: metadata.FileMetadata.par2_files:type_name -> metadata.Par2FileReference
+	4,  // 10: metadata.FileMetadata.nested_sources:type_name -> metadata.NestedSegmentSource
+	5,  // 11: metadata.FileMetadata.clip_boundaries:type_name -> metadata.ClipBoundary
+	4,  // 12: metadata.FileMetadata.shared_outer_sources:type_name -> metadata.NestedSegmentSource
+	11, // 13: metadata.FileMetadata.segment_refs:type_name -> metadata.SegmentRef
+	12, // 14: metadata.FileMetadata.segment_runs:type_name -> metadata.SegmentRun
+	6,  // 15: metadata.FileMetadata.known_holes:type_name -> metadata.HoleRun
+	9,  // 16: metadata.NzbStore.files:type_name -> metadata.NzbFileEntry
+	10, // 17: metadata.NzbFileEntry.segments:type_name -> metadata.NzbSeg
+	18, // [18:18] is the sub-list for method output_type
+	18, // [18:18] is the sub-list for method input_type
+	18, // [18:18] is the sub-list for extension type_name
+	18, // [18:18] is the sub-list for extension extendee
+	0,  // [0:18] is the sub-list for field type_name
 }
 
 func init() { file_internal_metadata_proto_metadata_proto_init() }
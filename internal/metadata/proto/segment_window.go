@@ -0,0 +1,26 @@
+package __
+
+// Some articles decode to a body with junk around the part's data, such as a
+// yEnc part that repeats the tail of the one before it. UsableStart and
+// UsableEnd mark the data inside the body; StartOffset and EndOffset are
+// relative to that window, so slicing a segment for an archive entry never
+// needs to know about the junk, and SegmentSize stays the size of the whole
+// body. Readers go through BodyRange and UsableLen rather than the raw offsets.
+
+// BodyRange returns the inclusive range of the decoded article body that
+// holds this segment's bytes of the file: StartOffset and EndOffset moved
+// past UsableStart and, when UsableEnd is set, clamped to it.
+func (x *SegmentData) BodyRange() (start, end int64) {
+	start = x.GetUsableStart() + x.GetStartOffset()
+	end = x.GetUsableStart() + x.GetEndOffset()
+	if usableEnd := x.GetUsableEnd(); usableEnd > 0 && end > usableEnd {
+		end = usableEnd
+	}
+	return start, end
+}
+
+// UsableLen returns how many bytes this segment contributes to the file.
+func (x *SegmentData) UsableLen() int64 {
+	start, end := x.BodyRange()
+	return end - start + 1
+}
@@ -0,0 +1,81 @@
+package metadata
+
+import (
+	"context"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+
+	"github.com/sourcegraph/conc/pool"
+)
+
+// RelinkIDSymlinksUnder repoints the .ids/ symlink of every file below
+// virtualDir at the file's current location. A directory rename moves each
+// file's .meta and .id sidecar along with it but leaves its symlink aimed at
+// the old path; this brings them back in line. Up to concurrency files are
+// relinked at once (<= 0 means one at a time). Files without an ID or without
+// an existing symlink are skipped, and a file that fails is logged and does
+// not stop the rest. Returns how many symlinks were updated.
+func (ms *MetadataService) RelinkIDSymlinksUnder(ctx context.Context, virtualDir string, concurrency int) (int, error) {
+	dirPath := ms.GetMetadataDirectoryPath(virtualDir)
+
+	var sidecars []string
+	err := filepath.WalkDir(dirPath, func(path string, d fs.DirEntry, err error) error {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		if err != nil {
+			return nil // skip unreadable entries
+		}
+		if d.IsDir() {
+			if depthErr := ms.checkDepth(dirPath, path); depthErr != nil {
+				slog.WarnContext(ctx, "Skipping metadata directory below max depth", "path", path, "error", depthErr)
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if strings.HasSuffix(d.Name(), ".meta.id") {
+			sidecars = append(sidecars, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	var updated atomic.Int64
+	p := pool.New().WithMaxGoroutines(max(concurrency, 1))
+	for _, idPath := range sidecars {
+		if ctx.Err() != nil {
+			break
+		}
+		p.Go(func() {
+			data, err := os.ReadFile(idPath)
+			if err != nil {
+				return
+			}
+			nzbdavID := strings.TrimSpace(string(data))
+			rel, err := filepath.Rel(ms.RootPath(), strings.TrimSuffix(idPath, ".meta.id"))
+			if nzbdavID == "" || err != nil {
+				return
+			}
+			virtualPath := filepath.ToSlash(rel)
+
+			ok, err := ms.UpdateIDSymlink(nzbdavID, virtualPath)
+			if err != nil {
+				slog.WarnContext(ctx, "Failed to update ID symlink after directory rename",
+					"path", virtualPath, "nzbdav_id", nzbdavID, "error", err)
+				return
+			}
+			if ok {
+				updated.Add(1)
+			}
+		})
+	}
+	p.Wait()
+
+	return int(updated.Load()), ctx.Err()
+}
@@ -0,0 +1,96 @@
+package metadata
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// latencyFileOps delays every rename, standing in for a slow metadata mount.
+type latencyFileOps struct {
+	osFileOps
+	delay time.Duration
+}
+
+func (l latencyFileOps) Rename(oldpath, newpath string) error {
+	time.Sleep(l.delay)
+	return os.Rename(oldpath, newpath)
+}
+
+// writeLinkedFiles writes n metadata files under dir, each with an .id
+// sidecar and an .ids/ symlink, and returns their IDs in order.
+func writeLinkedFiles(t *testing.T, ms *MetadataService, dir string, n int) []string {
+	t.Helper()
+	ids := make([]string, n)
+	for i := range n {
+		virtualPath := fmt.Sprintf("%s/Show.S01E%02d.mkv", dir, i)
+		writeTestMeta(t, ms, virtualPath)
+		ids[i] = fmt.Sprintf("%05x-relink-%d", i, i)
+		require.NoError(t, os.WriteFile(ms.GetMetadataFilePath(virtualPath)+".id", []byte(ids[i]), 0644))
+		require.NoError(t, ms.RepairIDSymlink(ids[i], virtualPath))
+	}
+	return ids
+}
+
+func assertLinkedTo(t *testing.T, ms *MetadataService, dir string, ids []string) {
+	t.Helper()
+	for i, id := range ids {
+		resolved, err := filepath.EvalSymlinks(ms.idSymlinkPath(id))
+		require.NoError(t, err, "symlink for %s", id)
+		expected, err := filepath.EvalSymlinks(ms.GetMetadataFilePath(fmt.Sprintf("%s/Show.S01E%02d.mkv", dir, i)))
+		require.NoError(t, err)
+		assert.Equal(t, expected, resolved)
+	}
+}
+
+func TestRelinkIDSymlinksUnder(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("relinking re-creates .ids symlinks")
+	}
+	const n = 40
+	const delay = 10 * time.Millisecond
+
+	relink := func(t *testing.T, concurrency int) time.Duration {
+		ms := NewMetadataService(t.TempDir())
+		ids := writeLinkedFiles(t, ms, "tv/Old Show", n)
+		require.NoError(t, os.Rename(ms.GetMetadataDirectoryPath("tv/Old Show"), ms.GetMetadataDirectoryPath("tv/New Show")))
+		ms.SetFileOps(latencyFileOps{delay: delay})
+
+		start := time.Now()
+		updated, err := ms.RelinkIDSymlinksUnder(context.Background(), "tv/New Show", concurrency)
+		elapsed := time.Since(start)
+		require.NoError(t, err)
+		assert.Equal(t, n, updated)
+		assertLinkedTo(t, ms, "tv/New Show", ids)
+		return elapsed
+	}
+
+	sequential := relink(t, 1)
+	parallel := relink(t, 8)
+	assert.GreaterOrEqual(t, sequential, n*delay)
+	assert.Less(t, parallel, sequential/2, "relinking %d files 8 at a time should beat one at a time", n)
+}
+
+func TestRelinkIDSymlinksUnder_SkipsFilesWithoutSymlink(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fixture creates .ids symlinks for the linked files")
+	}
+	ms := NewMetadataService(t.TempDir())
+	writeLinkedFiles(t, ms, "movies/Pack", 2)
+	writeTestMeta(t, ms, "movies/Pack/no-id.mkv")
+	require.NoError(t, os.WriteFile(ms.GetMetadataFilePath("movies/Pack/unlinked.mkv")+".id", []byte("abcde-unlinked"), 0644))
+	writeTestMeta(t, ms, "movies/Pack/unlinked.mkv")
+
+	updated, err := ms.RelinkIDSymlinksUnder(context.Background(), "movies/Pack", 4)
+	require.NoError(t, err)
+	assert.Equal(t, 2, updated)
+	_, err = os.Lstat(ms.idSymlinkPath("abcde-unlinked"))
+	assert.True(t, os.IsNotExist(err), "a file without a symlink does not get one")
+}
@@ -0,0 +1,93 @@
+package metadata
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// renameJournalName is the file under the metadata root that records a
+// directory rename in progress. It has no .meta extension, so listings never
+// show it.
+const renameJournalName = ".rename-journal"
+
+// ErrDirRenamePending is returned by BeginDirRename while an earlier rename is
+// still recorded. Finish or drop that one first (see PendingDirRename).
+var ErrDirRenamePending = errors.New("a directory rename is already pending")
+
+// DirRename is a directory rename recorded by BeginDirRename. Old and New are
+// virtual paths.
+type DirRename struct {
+	Old       string    `json:"old"`
+	New       string    `json:"new"`
+	StartedAt time.Time `json:"started_at"`
+}
+
+func (ms *MetadataService) renameJournalPath() string {
+	return filepath.Join(ms.RootPath(), renameJournalName)
+}
+
+// BeginDirRename records that oldPath is about to be renamed to newPath. The
+// record survives a crash until EndDirRename removes it, so PendingDirRename
+// can tell on the next start that the per-file updates following the
+// directory move may not have run. Only one rename is recorded at a time:
+// while one is pending BeginDirRename returns ErrDirRenamePending rather than
+// overwrite it. Callers serialize directory renames.
+func (ms *MetadataService) BeginDirRename(oldPath, newPath string) error {
+	ms.migrateMu.RLock()
+	defer ms.migrateMu.RUnlock()
+
+	journalPath := ms.renameJournalPath()
+	if _, err := os.Lstat(journalPath); err == nil {
+		return ErrDirRenamePending
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to check rename journal: %w", err)
+	}
+
+	data, err := json.Marshal(DirRename{Old: oldPath, New: newPath, StartedAt: time.Now().UTC()})
+	if err != nil {
+		return err
+	}
+
+	tmpPath := journalPath + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write rename journal: %w", err)
+	}
+	if err := os.Rename(tmpPath, journalPath); err != nil {
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("failed to write rename journal: %w", err)
+	}
+	return nil
+}
+
+// EndDirRename clears the record written by BeginDirRename.
+func (ms *MetadataService) EndDirRename() error {
+	ms.migrateMu.RLock()
+	defer ms.migrateMu.RUnlock()
+
+	if err := os.Remove(ms.renameJournalPath()); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to clear rename journal: %w", err)
+	}
+	return nil
+}
+
+// PendingDirRename returns the directory rename left unfinished by a previous
+// run, or nil when there is none.
+func (ms *MetadataService) PendingDirRename() (*DirRename, error) {
+	data, err := os.ReadFile(ms.renameJournalPath())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rename journal: %w", err)
+	}
+
+	var rename DirRename
+	if err := json.Unmarshal(data, &rename); err != nil {
+		return nil, fmt.Errorf("failed to parse rename journal: %w", err)
+	}
+	return &rename, nil
+}
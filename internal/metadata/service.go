@@ -9,6 +9,8 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	lru "github.com/hashicorp/golang-lru/v2"
@@ -57,7 +59,12 @@ type FileMetadataLite struct {
 // bounds steady-state memory at ~liteCache_entries × 40 bytes instead of the
 // previous unbounded segment retention.
 type MetadataService struct {
-	rootPath string
+	// root is the metadata root directory; swapped by MigrateRoot under
+	// migrateMu's write lock. Methods that write under the root hold its read
+	// lock, so they finish before a migration copies the tree or start after
+	// the switch.
+	root      atomic.Pointer[string]
+	migrateMu sync.RWMutex
 	// liteCache caches lightweight metadata (size, modtime, status) used by
 	// Readdir/Stat/Getattr, and populated as a side effect of ReadFileMetadata
 	// so info-only callers still benefit.
@@ -67,16 +74,43 @@ type MetadataService struct {
 	// storeRefCounter tracks reference counts for shared NzbStore files.
 	// nil means reference counting is disabled.
 	storeRefCounter StoreRefCounter
+	// maxDirDepth bounds recursive directory operations; <= 0 means
+	// defaultMaxDirectoryDepth. See SetMaxDirectoryDepth.
+	maxDirDepth atomic.Int64
+	// ops is the filesystem behind corrupted-moves and deletes; nil means
+	// the os package. See SetFileOps.
+	ops FileOps
+	// nzbRoot is the directory source NZB paths are stored relative to; nil
+	// stores them as given. See SetNzbRoot.
+	nzbRoot atomic.Pointer[string]
+	// computeFingerprint makes WriteFileMetadataAuto stamp imported files
+	// with a content fingerprint. See SetComputeFingerprint.
+	computeFingerprint atomic.Bool
+	// writeRetries and writeRetryDelay bound how metadata writes that fail
+	// with a transient error are retried. See SetWriteRetry.
+	writeRetries    atomic.Int64
+	writeRetryDelay atomic.Int64
+	// libraryStats is the last ComputeLibraryStats result, guarded by
+	// libraryStatsMu, which also serializes the walks.
+	libraryStatsMu sync.Mutex
+	libraryStats   *LibraryStats
 }
 
 // NewMetadataService creates a new metadata service
 func NewMetadataService(rootPath string) *MetadataService {
 	liteCache, _ := lru.New[string, *FileMetadataLite](defaultMetadataCacheSize)
-	return &MetadataService{
-		rootPath:  rootPath,
+	ms := &MetadataService{
 		liteCache: liteCache,
 		store:     NewStoreService(rootPath),
 	}
+	ms.root.Store(&rootPath)
+	ms.SetWriteRetry(defaultWriteRetries, defaultWriteRetryDelay)
+	return ms
+}
+
+// RootPath returns the active metadata root directory.
+func (ms *MetadataService) RootPath() string {
+	return *ms.root.Load()
 }
 
 // Store returns the StoreService used by this MetadataService.
@@ -105,22 +139,50 @@ func (ms *MetadataService) IncStoreRef(ctx context.Context, storePath string) {
 	}
 }
 
-// readStoreRef reads just the StoreRef field from a .meta file without resolving segments.
-// Returns "" if the file is not v3 or cannot be read.
-func (ms *MetadataService) readStoreRef(metaFilePath string) string {
+// readStoreRef reads just the StoreRef field from a .meta file without resolving segments,
+// as stored: relative to the NZB root when the store lies inside it. That form is the
+// store's reference-count key; ResolveSourceNzbPath gives its location.
+// retainedNzb is the copy of the source NZB kept next to the store
+// (Import.RetainSourceNzb), which shares the store's lifetime: a source NZB path
+// inside the NZB root that differs from the store. Both are "" if the file is not
+// v3 or cannot be read.
+func (ms *MetadataService) readStoreRef(metaFilePath string) (storeRef, retainedNzb string) {
 	data, err := os.ReadFile(metaFilePath)
 	if err != nil {
-		return ""
+		return "", ""
 	}
 	if !isV3Meta(data) {
-		return ""
+		return "", ""
 	}
 	payload := data[len(metaMagicV3):]
 	var fm metapb.FileMetadata
 	if err := proto.Unmarshal(payload, &fm); err != nil {
-		return ""
+		return "", ""
+	}
+	if fm.StoreRef != "" && fm.SourceNzbPath != "" && !filepath.IsAbs(fm.SourceNzbPath) &&
+		ms.ResolveSourceNzbPath(fm.SourceNzbPath) != ms.ResolveSourceNzbPath(fm.StoreRef) {
+		retainedNzb = fm.SourceNzbPath
 	}
-	return fm.StoreRef
+	return fm.StoreRef, retainedNzb
+}
+
+// storeRefKey returns the form storeRef is stored in by the .meta file at
+// metadataPath. A rewrite keeps the form the file already has, because its
+// store's reference count is keyed by it: refs written as absolute paths
+// before SetNzbRoot stay absolute. New refs are stored relative to the NZB
+// root.
+func (ms *MetadataService) storeRefKey(metadataPath, storeRef string) string {
+	if existing, _ := ms.readStoreRef(metadataPath); existing != "" &&
+		ms.ResolveSourceNzbPath(existing) == ms.ResolveSourceNzbPath(storeRef) {
+		return existing
+	}
+	return ms.relativeSourceNzbPath(storeRef)
+}
+
+// metadataWritePath is the .meta file WriteFileMetadata writes virtualPath to.
+func (ms *MetadataService) metadataWritePath(virtualPath string) string {
+	metadataDir := filepath.Join(ms.RootPath(), filepath.Dir(virtualPath))
+	return filepath.Join(metadataDir, ms.truncateFilename(filepath.Base(virtualPath))+".meta")
 }
 
 // truncateFilename truncates the filename if it's too long to prevent filesystem issues
@@ -141,11 +203,10 @@ func (ms *MetadataService) truncateFilename(filename string) string {
 
 // WriteFileMetadata writes file metadata to disk
 func (ms *MetadataService) WriteFileMetadata(virtualPath string, metadata *metapb.FileMetadata) error {
-	// Ensure the directory exists
-	metadataDir := filepath.Join(ms.rootPath, filepath.Dir(virtualPath))
-	if err := os.MkdirAll(metadataDir, 0755); err != nil {
-		return fmt.Errorf("failed to create metadata directory: %w", err)
-	}
+	ms.migrateMu.RLock()
+	defer ms.migrateMu.RUnlock()
+
+	metadataDir := filepath.Join(ms.RootPath(), filepath.Dir(virtualPath))
 
 	// Create metadata file path (filename + .meta extension)
 	filename := filepath.Base(virtualPath)
@@ -165,6 +226,8 @@ func (ms *MetadataService) WriteFileMetadata(virtualPath string, metadata *metap
 		// SharedOuterSources dedup is dissolved: each NestedSource carries inline SegmentRefs,
 		// so the read path does not need to expand shared entries.
 		structural := proto.Clone(metadata).(*metapb.FileMetadata)
+		structural.SourceNzbPath = ms.relativeSourceNzbPath(structural.SourceNzbPath)
+		structural.StoreRef = ms.storeRefKey(metadataPath, structural.StoreRef)
 		structural.SegmentData = nil
 		structural.SharedOuterSources = nil // v3: dedup dissolved
 		for _, p := range structural.Par2Files {
@@ -182,7 +245,10 @@ func (ms *MetadataService) WriteFileMetadata(virtualPath string, metadata *metap
 		writeData = append(metaMagicV3, raw...)
 	} else {
 		// v1: marshal as-is (existing behavior).
+		sourceNzbPath := metadata.SourceNzbPath
+		metadata.SourceNzbPath = ms.relativeSourceNzbPath(sourceNzbPath)
 		raw, err := proto.Marshal(metadata)
+		metadata.SourceNzbPath = sourceNzbPath
 		if err != nil {
 			metadata.NzbdavId = nzbdavId // Restore on error
 			return fmt.Errorf("failed to marshal metadata: %w", err)
@@ -190,30 +256,9 @@ func (ms *MetadataService) WriteFileMetadata(virtualPath string, metadata *metap
 		writeData = raw
 	}
 
-	// Write atomically using a uniquely-named temporary file so concurrent
-	// writes to the same final path don't race on the same .tmp name.
-	tmpFile, err := os.CreateTemp(metadataDir, "."+truncatedFilename+".*.tmp")
-	if err != nil {
+	if err := ms.writeFileAtomic(metadataPath, "."+truncatedFilename, writeData); err != nil {
 		metadata.NzbdavId = nzbdavId
-		return fmt.Errorf("failed to create temporary metadata file: %w", err)
-	}
-	tmpPath := tmpFile.Name()
-	if _, writeErr := tmpFile.Write(writeData); writeErr != nil {
-		tmpFile.Close()
-		_ = os.Remove(tmpPath)
-		metadata.NzbdavId = nzbdavId
-		return fmt.Errorf("failed to write temporary metadata file: %w", writeErr)
-	}
-	if closeErr := tmpFile.Close(); closeErr != nil {
-		_ = os.Remove(tmpPath)
-		metadata.NzbdavId = nzbdavId
-		return fmt.Errorf("failed to close temporary metadata file: %w", closeErr)
-	}
-
-	if err := os.Rename(tmpPath, metadataPath); err != nil {
-		_ = os.Remove(tmpPath)
-		metadata.NzbdavId = nzbdavId
-		return fmt.Errorf("failed to rename metadata file: %w", err)
+		return fmt.Errorf("failed to write metadata file: %w", err)
 	}
 
 	metadata.NzbdavId = nzbdavId // Restore for in-memory use
@@ -250,6 +295,7 @@ func (ms *MetadataService) WriteFileMetadataV3(ctx context.Context, virtualPath
 	// The raw .nzb is deleted after import; the .nzbz store is the persistent source
 	// of truth (NZBs are regenerated from it). Point source_nzb_path at the store so a
 	// single, real artifact is recorded instead of a path to a now-deleted .nzb file.
+	// With Import.RetainSourceNzb the importer repoints it at the kept copy afterwards.
 	m.SourceNzbPath = storeRef
 
 	mainRefs, err := segDataToRefs(m.SegmentData, index)
@@ -275,12 +321,15 @@ func (ms *MetadataService) WriteFileMetadataV3(ctx context.Context, virtualPath
 	}
 	m.SharedOuterSources = nil
 
+	// The count is keyed by the ref as written; see storeRefKey.
+	key := ms.storeRefKey(ms.metadataWritePath(virtualPath), storeRef)
+
 	// WriteFileMetadata's v3 branch (StoreRef set) clears inline SegmentData/
 	// SharedOuterSources/NestedSource.Segments and keeps SegmentRefs/SegmentRuns.
 	if err := ms.WriteFileMetadata(virtualPath, m); err != nil {
 		return err
 	}
-	ms.IncStoreRef(ctx, storeRef)
+	ms.IncStoreRef(ctx, key)
 	return nil
 }
 
@@ -289,6 +338,9 @@ func (ms *MetadataService) WriteFileMetadataV3(ctx context.Context, virtualPath
 // problem on one file never blocks the import). With an empty storeRef it writes v1.
 // This is the single entry point import processors should use.
 func (ms *MetadataService) WriteFileMetadataAuto(ctx context.Context, virtualPath string, metadata *metapb.FileMetadata, index map[string]int64, storeRef string) error {
+	if ms.computeFingerprint.Load() && metadata.Fingerprint == "" {
+		metadata.Fingerprint = ContentFingerprint(metadata)
+	}
 	if storeRef == "" {
 		return ms.WriteFileMetadata(virtualPath, metadata)
 	}
@@ -305,10 +357,26 @@ func (ms *MetadataService) WriteFileMetadataAuto(ctx context.Context, virtualPat
 // those slices dominate heap usage and must not be retained beyond the
 // caller's handle. As a side effect, the lightweight projection is cached so
 // subsequent Readdir/Stat calls are fast without a disk read.
+//
+// Empty credential fields (password, salt, AES key/IV) of encrypted files are
+// filled from the nearest directory-level .dirmeta defaults; file-level values
+// always win.
 func (ms *MetadataService) ReadFileMetadata(virtualPath string) (*metapb.FileMetadata, error) {
+	metadata, err := ms.readFileMetadata(virtualPath)
+	if err != nil || metadata == nil {
+		return metadata, err
+	}
+	ms.applyDirectoryDefaults(virtualPath, metadata)
+	return metadata, nil
+}
+
+// readFileMetadata reads file metadata exactly as stored on disk, without
+// merging directory defaults. Used by read-modify-write paths so inherited
+// values are never persisted into the file itself.
+func (ms *MetadataService) readFileMetadata(virtualPath string) (*metapb.FileMetadata, error) {
 	// Create metadata file path
 	filename := filepath.Base(virtualPath)
-	metadataDir := filepath.Join(ms.rootPath, filepath.Dir(virtualPath))
+	metadataDir := filepath.Join(ms.RootPath(), filepath.Dir(virtualPath))
 	metadataPath := filepath.Join(metadataDir, filename+".meta")
 
 	// Read file
@@ -327,6 +395,7 @@ func (ms *MetadataService) ReadFileMetadata(virtualPath string) (*metapb.FileMet
 			return nil, fmt.Errorf("failed to unmarshal metadata: %w", err)
 		}
 		if metadata.StoreRef != "" {
+			metadata.StoreRef = ms.ResolveSourceNzbPath(metadata.StoreRef)
 			store, err := ms.store.ReadStore(metadata.StoreRef)
 			if err != nil {
 				return nil, fmt.Errorf("failed to read store %q: %w", metadata.StoreRef, err)
@@ -361,6 +430,8 @@ func (ms *MetadataService) ReadFileMetadata(virtualPath string) (*metapb.FileMet
 		return nil, fmt.Errorf("failed to expand shared outer sources: %w", err)
 	}
 
+	metadata.SourceNzbPath = ms.ResolveSourceNzbPath(metadata.SourceNzbPath)
+
 	// Read ID from sidecar file (compatibility mode)
 	idPath := metadataPath + ".id"
 	if idData, err := os.ReadFile(idPath); err == nil {
@@ -402,7 +473,7 @@ func (ms *MetadataService) ReadFileMetadataLite(virtualPath string) (*FileMetada
 
 	// Cache miss — read the head of the file and scan wire-format fields.
 	filename := filepath.Base(virtualPath)
-	metadataDir := filepath.Join(ms.rootPath, filepath.Dir(virtualPath))
+	metadataDir := filepath.Join(ms.RootPath(), filepath.Dir(virtualPath))
 	metadataPath := filepath.Join(metadataDir, filename+".meta")
 
 	f, err := os.Open(metadataPath)
@@ -506,7 +577,7 @@ func parseLiteFields(buf []byte) (*FileMetadataLite, bool) {
 // fields within liteScanBytes.
 func (ms *MetadataService) readFileMetadataLiteFull(virtualPath string) (*FileMetadataLite, error) {
 	filename := filepath.Base(virtualPath)
-	metadataDir := filepath.Join(ms.rootPath, filepath.Dir(virtualPath))
+	metadataDir := filepath.Join(ms.RootPath(), filepath.Dir(virtualPath))
 	metadataPath := filepath.Join(metadataDir, filename+".meta")
 
 	data, err := os.ReadFile(metadataPath)
@@ -539,23 +610,91 @@ func (ms *MetadataService) readFileMetadataLiteFull(virtualPath string) (*FileMe
 func (ms *MetadataService) FileExists(virtualPath string) bool {
 	filename := filepath.Base(virtualPath)
 	truncatedFilename := ms.truncateFilename(filename)
-	metadataDir := filepath.Join(ms.rootPath, filepath.Dir(virtualPath))
+	metadataDir := filepath.Join(ms.RootPath(), filepath.Dir(virtualPath))
 	metadataPath := filepath.Join(metadataDir, truncatedFilename+".meta")
 
 	_, err := os.Stat(metadataPath)
 	return err == nil
 }
 
+// ResolvePathCase maps virtualPath to the casing actually stored on disk,
+// matching each path component case-insensitively. The exact path is tried
+// first; a directory scan only happens on a miss, and only for the components
+// that don't match exactly. Returns false when nothing matches. The final
+// component may name either a directory or a metadata file.
+func (ms *MetadataService) ResolvePathCase(virtualPath string) (string, bool) {
+	if ms.DirectoryExists(virtualPath) || ms.FileExists(virtualPath) {
+		return virtualPath, true
+	}
+
+	parts := strings.Split(strings.Trim(filepath.ToSlash(virtualPath), "/"), "/")
+	resolved := make([]string, 0, len(parts))
+	for i, part := range parts {
+		if part == "" || part == "." {
+			continue
+		}
+		dir := filepath.Join(append([]string{ms.RootPath()}, resolved...)...)
+		last := i == len(parts)-1
+
+		// Exact component first: the common case for every segment but the
+		// mis-cased one.
+		if info, err := os.Stat(filepath.Join(dir, part)); err == nil && info.IsDir() {
+			resolved = append(resolved, part)
+			continue
+		}
+		if last {
+			if _, err := os.Stat(filepath.Join(dir, ms.truncateFilename(part)+".meta")); err == nil {
+				resolved = append(resolved, part)
+				continue
+			}
+		}
+
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return "", false
+		}
+		match := ""
+		for _, entry := range entries {
+			name := entry.Name()
+			if entry.IsDir() {
+				if strings.EqualFold(name, part) {
+					match = name
+					break
+				}
+				continue
+			}
+			if last && strings.HasSuffix(name, ".meta") &&
+				strings.EqualFold(strings.TrimSuffix(name, ".meta"), ms.truncateFilename(part)) {
+				match = strings.TrimSuffix(name, ".meta")
+				break
+			}
+		}
+		if match == "" {
+			return "", false
+		}
+		resolved = append(resolved, match)
+	}
+
+	if len(resolved) == 0 {
+		return "", false
+	}
+	out := strings.Join(resolved, "/")
+	if strings.HasPrefix(virtualPath, "/") {
+		out = "/" + out
+	}
+	return out, true
+}
+
 // DirectoryExists checks if a metadata directory exists
 func (ms *MetadataService) DirectoryExists(virtualPath string) bool {
-	metadataDir := filepath.Join(ms.rootPath, virtualPath)
+	metadataDir := filepath.Join(ms.RootPath(), virtualPath)
 	info, err := os.Stat(metadataDir)
 	return err == nil && info.IsDir()
 }
 
 // ListDirectory lists all metadata files in a directory
 func (ms *MetadataService) ListDirectory(virtualPath string) ([]string, error) {
-	metadataDir := filepath.Join(ms.rootPath, virtualPath)
+	metadataDir := filepath.Join(ms.RootPath(), virtualPath)
 
 	entries, err := os.ReadDir(metadataDir)
 	if err != nil {
@@ -581,7 +720,7 @@ func (ms *MetadataService) ListDirectory(virtualPath string) ([]string, error) {
 // file names from a single os.ReadDir call. This is used by Readdir to avoid
 // two separate directory reads.
 func (ms *MetadataService) ListDirectoryAll(virtualPath string) (dirs []fs.FileInfo, fileNames []string, err error) {
-	metadataDir := filepath.Join(ms.rootPath, virtualPath)
+	metadataDir := filepath.Join(ms.RootPath(), virtualPath)
 
 	entries, err := os.ReadDir(metadataDir)
 	if err != nil {
@@ -605,6 +744,28 @@ func (ms *MetadataService) ListDirectoryAll(virtualPath string) (dirs []fs.FileI
 	return dirs, fileNames, nil
 }
 
+// ListDirectoryEntries is ListDirectoryAll without the per-directory stat:
+// subdirectories come back as the os.DirEntry values of the read, whose Info
+// is only fetched when asked for.
+func (ms *MetadataService) ListDirectoryEntries(virtualPath string) (dirs []fs.DirEntry, fileNames []string, err error) {
+	entries, err := os.ReadDir(filepath.Join(ms.RootPath(), virtualPath))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil, nil
+		}
+		return nil, nil, fmt.Errorf("failed to read directory: %w", err)
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() {
+			dirs = append(dirs, entry)
+		} else if name, ok := strings.CutSuffix(entry.Name(), ".meta"); ok {
+			fileNames = append(fileNames, name)
+		}
+	}
+	return dirs, fileNames, nil
+}
+
 // CreateFileMetadata creates a new FileMetadata with basic fields
 func (ms *MetadataService) CreateFileMetadata(
 	fileSize int64,
@@ -642,8 +803,8 @@ func (ms *MetadataService) CreateFileMetadata(
 
 // UpdateFileMetadata updates the modified timestamp of metadata
 func (ms *MetadataService) UpdateFileMetadata(virtualPath string, updateFunc func(*metapb.FileMetadata)) error {
-	// Read existing metadata
-	metadata, err := ms.ReadFileMetadata(virtualPath)
+	// Read existing metadata (without directory defaults, so they stay inherited)
+	metadata, err := ms.readFileMetadata(virtualPath)
 	if err != nil {
 		return fmt.Errorf("failed to read metadata: %w", err)
 	}
@@ -673,69 +834,109 @@ func (ms *MetadataService) DeleteFileMetadata(virtualPath string) error {
 	return ms.DeleteFileMetadataWithSourceNzb(context.Background(), virtualPath, false)
 }
 
-// DeleteFileMetadataWithSourceNzb deletes a metadata file and optionally its source NZB
+// DeleteFileMetadataWithSourceNzb deletes a metadata file and optionally its source NZB.
+// The filesystem work honors ctx: if it is still blocked when ctx ends, the
+// call returns ctx's error and the work carries on in the background. The
+// store reference is released once the metadata file is actually removed,
+// whether or not the caller is still waiting.
 func (ms *MetadataService) DeleteFileMetadataWithSourceNzb(ctx context.Context, virtualPath string, deleteSourceNzb bool) error {
+	ms.migrateMu.RLock()
+	defer ms.migrateMu.RUnlock()
+
+	return ms.deleteFileMetadata(ctx, virtualPath, deleteSourceNzb)
+}
+
+// deleteFileMetadata is DeleteFileMetadataWithSourceNzb for callers already
+// holding migrateMu.
+func (ms *MetadataService) deleteFileMetadata(ctx context.Context, virtualPath string, deleteSourceNzb bool) error {
 	ms.liteCache.Remove(virtualPath)
 
 	filename := filepath.Base(virtualPath)
-	metadataDir := filepath.Join(ms.rootPath, filepath.Dir(virtualPath))
+	metadataDir := filepath.Join(ms.RootPath(), filepath.Dir(virtualPath))
 	metadataPath := filepath.Join(metadataDir, filename+".meta")
-
-	// Always read metadata first to capture SourceNzbPath and StoreRef before deletion.
-	var sourceNzbPath string
-	var storeRef string
-	if metadata, err := ms.ReadFileMetadata(virtualPath); err == nil && metadata != nil {
+	ops := ms.fileOps()
+	// Bookkeeping may outlive an abandoned call, so it must not inherit the
+	// caller's cancellation.
+	bgCtx := context.WithoutCancel(ctx)
+
+	return utils.RunWithContext(ctx, func() error {
+		// Always read metadata first to capture SourceNzbPath and StoreRef before deletion.
+		var sourceNzbPath string
 		if deleteSourceNzb {
-			sourceNzbPath = metadata.SourceNzbPath
+			if metadata, err := ms.ReadFileMetadata(virtualPath); err == nil && metadata != nil {
+				sourceNzbPath = metadata.SourceNzbPath
+			}
 		}
-		storeRef = metadata.StoreRef
-	}
+		storeRef, retainedNzb := ms.readStoreRef(metadataPath)
 
-	// Delete the metadata file
-	err := os.Remove(metadataPath)
-	if err != nil && !os.IsNotExist(err) {
-		return fmt.Errorf("failed to delete metadata file: %w", err)
-	}
+		// Delete the metadata file
+		err := ops.Remove(metadataPath)
+		if err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to delete metadata file: %w", err)
+		}
+		if err == nil {
+			ms.releaseStoreRef(bgCtx, storeRef, retainedNzb)
+		}
 
-	// Clean up .id sidecar file
-	idPath := metadataPath + ".id"
-	if removeErr := os.Remove(idPath); removeErr != nil && !os.IsNotExist(removeErr) {
-		slog.DebugContext(ctx, "Failed to remove .id sidecar file", "path", idPath, "error", removeErr)
-	}
+		// Clean up .id sidecar file
+		idPath := metadataPath + ".id"
+		if removeErr := ops.Remove(idPath); removeErr != nil && !os.IsNotExist(removeErr) {
+			slog.DebugContext(bgCtx, "Failed to remove .id sidecar file", "path", idPath, "error", removeErr)
+		}
 
-	// Clean up empty parent directories in metadata path
-	utils.RemoveEmptyDirs(ms.rootPath, metadataDir)
+		// Clean up empty parent directories in metadata path
+		utils.RemoveEmptyDirs(ms.RootPath(), metadataDir)
 
-	// Optionally delete the source NZB file (error-tolerant)
-	if deleteSourceNzb && sourceNzbPath != "" {
-		if err := os.Remove(sourceNzbPath); err != nil {
-			if !os.IsNotExist(err) {
-				slog.DebugContext(ctx, "Failed to delete source NZB file",
+		// Optionally delete the source NZB file (error-tolerant)
+		if deleteSourceNzb && sourceNzbPath != "" {
+			if err := ops.Remove(sourceNzbPath); err != nil {
+				if !os.IsNotExist(err) {
+					slog.DebugContext(bgCtx, "Failed to delete source NZB file",
+						"nzb_path", sourceNzbPath,
+						"error", err)
+				}
+			} else {
+				slog.DebugContext(bgCtx, "Deleted source NZB file",
 					"nzb_path", sourceNzbPath,
-					"error", err)
+					"virtual_path", virtualPath)
 			}
-		} else {
-			slog.DebugContext(ctx, "Deleted source NZB file",
-				"nzb_path", sourceNzbPath,
-				"virtual_path", virtualPath)
 		}
-	}
+		return nil
+	})
+}
 
-	// Decrement reference count for shared store file and delete it if no more refs.
-	if ms.storeRefCounter != nil && storeRef != "" {
-		newCount, err := ms.storeRefCounter.DecStoreRef(ctx, storeRef)
-		if err != nil {
-			slog.WarnContext(ctx, "failed to decrement store ref count",
-				"store_path", storeRef, "error", err)
-		} else if newCount == 0 {
-			if removeErr := os.Remove(storeRef); removeErr != nil && !os.IsNotExist(removeErr) {
-				slog.WarnContext(ctx, "failed to delete orphaned store file",
-					"store_path", storeRef, "error", removeErr)
-			}
-		}
+// releaseStoreRef drops one reference to the shared store file storeRef, as
+// stored in metadata, and deletes the store, and the source NZB retained next
+// to it if any, once nothing references it.
+func (ms *MetadataService) releaseStoreRef(ctx context.Context, storeRef, retainedNzb string) {
+	if ms.storeRefCounter == nil || storeRef == "" {
+		return
+	}
+	newCount, err := ms.storeRefCounter.DecStoreRef(ctx, storeRef)
+	if err != nil {
+		slog.WarnContext(ctx, "failed to decrement store ref count",
+			"store_path", storeRef, "error", err)
+		return
+	}
+	if newCount == 0 {
+		ms.removeOrphanedStore(ctx, storeRef, retainedNzb)
 	}
+}
 
-	return nil
+// removeOrphanedStore deletes a store file nothing references anymore, along
+// with the source NZB retained next to it (retainedNzb, "" for none).
+func (ms *MetadataService) removeOrphanedStore(ctx context.Context, storeRef, retainedNzb string) {
+	if removeErr := os.Remove(ms.ResolveSourceNzbPath(storeRef)); removeErr != nil && !os.IsNotExist(removeErr) {
+		slog.WarnContext(ctx, "failed to delete orphaned store file",
+			"store_path", storeRef, "error", removeErr)
+	}
+	if retainedNzb == "" {
+		return
+	}
+	if removeErr := os.Remove(ms.ResolveSourceNzbPath(retainedNzb)); removeErr != nil && !os.IsNotExist(removeErr) {
+		slog.WarnContext(ctx, "failed to delete retained source NZB",
+			"nzb_path", retainedNzb, "error", removeErr)
+	}
 }
 
 // DeleteCorruptedFile removes a file's metadata (and optionally its source NZB), then
@@ -761,6 +962,10 @@ func (ms *MetadataService) DeleteCorruptedFile(ctx context.Context, virtualPath
 
 // DeleteDirectory deletes a metadata directory and all its contents
 func (ms *MetadataService) DeleteDirectory(virtualPath string) error {
+	ms.migrateMu.RLock()
+	defer ms.migrateMu.RUnlock()
+
+	root := ms.RootPath()
 	ctx := context.Background()
 
 	// Purge all cached entries under this directory
@@ -771,27 +976,42 @@ func (ms *MetadataService) DeleteDirectory(virtualPath string) error {
 		}
 	}
 
-	metadataDir := filepath.Join(ms.rootPath, virtualPath)
+	metadataDir := filepath.Join(root, virtualPath)
 
 	// HARD SAFETY: Never delete the root metadata path
 	cleanMetadataDir := filepath.Clean(metadataDir)
-	if cleanMetadataDir == filepath.Clean(ms.rootPath) || cleanMetadataDir == "/" || cleanMetadataDir == "." {
+	if cleanMetadataDir == filepath.Clean(root) || cleanMetadataDir == "/" || cleanMetadataDir == "." {
 		return fmt.Errorf("safety block: refusing to remove root metadata directory: %s", cleanMetadataDir)
 	}
 
 	// Pre-pass: if refcounting is enabled, collect all v3 store refs before deletion.
+	// A tree deeper than the max depth is refused outright: deleting it would
+	// drop refs the pre-pass never counted.
 	var storeRefCounts map[string]int
+	retainedNzbs := make(map[string]string)
 	if ms.storeRefCounter != nil {
 		storeRefCounts = make(map[string]int)
-		_ = filepath.WalkDir(metadataDir, func(path string, d fs.DirEntry, err error) error {
-			if err != nil || d.IsDir() || !strings.HasSuffix(path, ".meta") {
+		walkErr := filepath.WalkDir(metadataDir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return nil
+			}
+			if d.IsDir() {
+				return ms.checkDepth(metadataDir, path)
+			}
+			if !strings.HasSuffix(path, ".meta") {
 				return nil
 			}
-			if ref := ms.readStoreRef(path); ref != "" {
+			if ref, retained := ms.readStoreRef(path); ref != "" {
 				storeRefCounts[ref]++
+				if retained != "" {
+					retainedNzbs[ref] = retained
+				}
 			}
 			return nil
 		})
+		if walkErr != nil {
+			return fmt.Errorf("failed to delete metadata directory: %w", walkErr)
+		}
 	}
 
 	err := os.RemoveAll(metadataDir)
@@ -814,10 +1034,7 @@ func (ms *MetadataService) DeleteDirectory(virtualPath string) error {
 				lastCount = newCount
 			}
 			if lastCount == 0 {
-				if removeErr := os.Remove(storePath); removeErr != nil && !os.IsNotExist(removeErr) {
-					slog.WarnContext(ctx, "failed to delete orphaned store file",
-						"store_path", storePath, "error", removeErr)
-				}
+				ms.removeOrphanedStore(ctx, storePath, retainedNzbs[storePath])
 			}
 		}
 	}
@@ -825,18 +1042,84 @@ func (ms *MetadataService) DeleteDirectory(virtualPath string) error {
 	return nil
 }
 
+// DeleteDirectoryPreserving deletes the files under virtualPath one by one,
+// skipping those for which keep returns true, then removes subdirectories left
+// empty. The directory itself survives while any kept file remains. keep
+// receives virtual paths relative to the metadata root (no leading slash).
+// Returns how many files were kept.
+func (ms *MetadataService) DeleteDirectoryPreserving(ctx context.Context, virtualPath string, deleteSourceNzb bool, keep func(virtualPath string) bool) (int, error) {
+	ms.migrateMu.RLock()
+	defer ms.migrateMu.RUnlock()
+
+	root := ms.RootPath()
+	metadataDir := filepath.Join(root, virtualPath)
+
+	cleanMetadataDir := filepath.Clean(metadataDir)
+	if cleanMetadataDir == filepath.Clean(root) || cleanMetadataDir == "/" || cleanMetadataDir == "." {
+		return 0, fmt.Errorf("safety block: refusing to remove root metadata directory: %s", cleanMetadataDir)
+	}
+
+	var files, dirs []string
+	err := filepath.WalkDir(metadataDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil // skip unreadable entries
+		}
+		if d.IsDir() {
+			if depthErr := ms.checkDepth(metadataDir, path); depthErr != nil {
+				return depthErr
+			}
+			dirs = append(dirs, path)
+			return nil
+		}
+		if !strings.HasSuffix(path, ".meta") {
+			return nil
+		}
+		rel, relErr := filepath.Rel(root, path)
+		if relErr != nil {
+			return nil
+		}
+		files = append(files, filepath.ToSlash(strings.TrimSuffix(rel, ".meta")))
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to walk metadata directory: %w", err)
+	}
+
+	kept := 0
+	for _, file := range files {
+		if keep(file) {
+			kept++
+			continue
+		}
+		if err := ms.deleteFileMetadata(ctx, file, deleteSourceNzb); err != nil {
+			return kept, err
+		}
+	}
+
+	// Deepest first, so parents empty out before they are tried. Non-empty
+	// directories fail to remove and are left alone.
+	for i := len(dirs) - 1; i >= 0; i-- {
+		_ = os.Remove(dirs[i])
+	}
+
+	return kept, nil
+}
+
 // RenameFileMetadata atomically renames a metadata file (and its .id sidecar) from oldVirtualPath to newVirtualPath.
 // Uses os.Rename for atomicity on the same filesystem, falling back to read-write-delete for cross-device moves.
 func (ms *MetadataService) RenameFileMetadata(oldVirtualPath, newVirtualPath string) error {
+	ms.migrateMu.RLock()
+	defer ms.migrateMu.RUnlock()
+
 	ms.liteCache.Remove(oldVirtualPath)
 	ms.liteCache.Remove(newVirtualPath)
 
 	oldFilename := filepath.Base(oldVirtualPath)
-	oldDir := filepath.Join(ms.rootPath, filepath.Dir(oldVirtualPath))
+	oldDir := filepath.Join(ms.RootPath(), filepath.Dir(oldVirtualPath))
 	oldMetaPath := filepath.Join(oldDir, oldFilename+".meta")
 
 	newFilename := filepath.Base(newVirtualPath)
-	newDir := filepath.Join(ms.rootPath, filepath.Dir(newVirtualPath))
+	newDir := filepath.Join(ms.RootPath(), filepath.Dir(newVirtualPath))
 	newMetaPath := filepath.Join(newDir, newFilename+".meta")
 
 	// Ensure destination directory exists
@@ -864,33 +1147,44 @@ func (ms *MetadataService) RenameFileMetadata(oldVirtualPath, newVirtualPath str
 // GetMetadataFilePath returns the filesystem path for a metadata file
 func (ms *MetadataService) GetMetadataFilePath(virtualPath string) string {
 	filename := filepath.Base(virtualPath)
-	metadataDir := filepath.Join(ms.rootPath, filepath.Dir(virtualPath))
+	metadataDir := filepath.Join(ms.RootPath(), filepath.Dir(virtualPath))
 	return filepath.Join(metadataDir, filename+".meta")
 }
 
 // GetMetadataDirectoryPath returns the filesystem path for a metadata directory
 func (ms *MetadataService) GetMetadataDirectoryPath(virtualPath string) string {
-	return filepath.Join(ms.rootPath, virtualPath)
+	return filepath.Join(ms.RootPath(), virtualPath)
 }
 
 func (ms *MetadataService) CreateDirectory(name string) error {
-	return os.MkdirAll(filepath.Join(ms.rootPath, name), 0755)
+	ms.migrateMu.RLock()
+	defer ms.migrateMu.RUnlock()
+
+	return os.MkdirAll(filepath.Join(ms.RootPath(), name), 0755)
 }
 
 // CleanupEmptyDirectories recursively removes empty directories under the given virtual path.
 // Uses a bottom-up approach to ensure parent directories are also removed if they become empty.
 func (ms *MetadataService) CleanupEmptyDirectories(virtualPath string, protected []string) error {
-	fullPath := filepath.Join(ms.rootPath, virtualPath)
+	ms.migrateMu.RLock()
+	defer ms.migrateMu.RUnlock()
+
+	fullPath := filepath.Join(ms.RootPath(), virtualPath)
 
 	// Check if path exists
 	if _, err := os.Stat(fullPath); os.IsNotExist(err) {
 		return nil
 	}
 
-	return ms.cleanupEmptyDirsRecursive(fullPath, protected)
+	return ms.cleanupEmptyDirsRecursive(fullPath, fullPath, protected)
 }
 
-func (ms *MetadataService) cleanupEmptyDirsRecursive(path string, protected []string) error {
+func (ms *MetadataService) cleanupEmptyDirsRecursive(root, path string, protected []string) error {
+	if err := ms.checkDepth(root, path); err != nil {
+		slog.WarnContext(context.Background(), "Skipping empty directory cleanup below max depth", "path", path, "error", err)
+		return err
+	}
+
 	entries, err := os.ReadDir(path)
 	if err != nil {
 		return err
@@ -900,7 +1194,7 @@ func (ms *MetadataService) cleanupEmptyDirsRecursive(path string, protected []st
 	for _, entry := range entries {
 		if entry.IsDir() {
 			subPath := filepath.Join(path, entry.Name())
-			if err := ms.cleanupEmptyDirsRecursive(subPath, protected); err != nil {
+			if err := ms.cleanupEmptyDirsRecursive(root, subPath, protected); err != nil {
 				slog.DebugContext(context.Background(), "Failed to cleanup sub-directory", "path", subPath, "error", err)
 				isEmpty = false // Keep parent if sub-cleanup failed
 				continue
@@ -917,7 +1211,7 @@ func (ms *MetadataService) cleanupEmptyDirsRecursive(path string, protected []st
 	}
 
 	// Don't delete the root of the cleanup
-	if isEmpty && path != ms.rootPath && !ms.isCompleteDir(path) {
+	if isEmpty && path != ms.RootPath() && !ms.isCompleteDir(path) {
 		// Check protected list
 		base := filepath.Base(path)
 		if strings.EqualFold(base, "corrupted_metadata") {
@@ -937,8 +1231,13 @@ func (ms *MetadataService) cleanupEmptyDirsRecursive(path string, protected []st
 	return nil
 }
 
-// MoveToCorrupted moves a metadata file to a special corrupted directory for safety
+// MoveToCorrupted moves a metadata file to a special corrupted directory for safety.
+// The move honors ctx: if the filesystem is still blocked when ctx ends, the
+// call returns ctx's error instead of hanging the caller.
 func (ms *MetadataService) MoveToCorrupted(ctx context.Context, virtualPath string) error {
+	ms.migrateMu.RLock()
+	defer ms.migrateMu.RUnlock()
+
 	ms.liteCache.Remove(virtualPath)
 
 	// Normalize path and remove leading slashes to ensure it joins correctly
@@ -947,49 +1246,54 @@ func (ms *MetadataService) MoveToCorrupted(ctx context.Context, virtualPath stri
 	filename := filepath.Base(cleanPath)
 
 	truncatedFilename := ms.truncateFilename(filename)
-	metadataPath := filepath.Join(ms.rootPath, dir, truncatedFilename+".meta")
-
-	// Check if source exists
-	if _, err := os.Stat(metadataPath); os.IsNotExist(err) {
-		return nil
-	}
+	metadataPath := filepath.Join(ms.RootPath(), dir, truncatedFilename+".meta")
 
 	// Define corrupted directory path (root/corrupted_metadata/...)
 	// We use a visible folder name as requested.
-	corruptedRoot := filepath.Join(ms.rootPath, "corrupted_metadata")
+	corruptedRoot := filepath.Join(ms.RootPath(), "corrupted_metadata")
 	targetDir := filepath.Join(corruptedRoot, dir)
+	targetPath := filepath.Join(targetDir, truncatedFilename+".meta")
+	ops := ms.fileOps()
 
-	if err := os.MkdirAll(targetDir, 0755); err != nil {
-		return fmt.Errorf("failed to create corrupted metadata directory: %w", err)
-	}
+	return utils.RunWithContext(ctx, func() error {
+		// Check if source exists
+		if _, err := ops.Stat(metadataPath); os.IsNotExist(err) {
+			return nil
+		}
 
-	targetPath := filepath.Join(targetDir, truncatedFilename+".meta")
+		if err := ops.MkdirAll(targetDir, 0755); err != nil {
+			return fmt.Errorf("failed to create corrupted metadata directory: %w", err)
+		}
 
-	// Move the .meta file
-	if err := os.Rename(metadataPath, targetPath); err != nil {
-		slog.WarnContext(ctx, "Failed to move corrupted metadata, trying copy fallback", "error", err)
-		// Rename can fail across different volumes, though usually metadata is on one volume.
-		// For simplicity, we return the error here as it's unexpected for metadata.
-		return err
-	}
+		// Move the .meta file
+		if err := ops.Rename(metadataPath, targetPath); err != nil {
+			slog.WarnContext(ctx, "Failed to move corrupted metadata, trying copy fallback", "error", err)
+			// Rename can fail across different volumes, though usually metadata is on one volume.
+			// For simplicity, we return the error here as it's unexpected for metadata.
+			return err
+		}
 
-	// Also try to move the .id file if it exists
-	idPath := metadataPath + ".id"
-	if _, err := os.Stat(idPath); err == nil {
-		_ = os.Rename(idPath, targetPath+".id")
-	}
+		// Also try to move the .id file if it exists
+		idPath := metadataPath + ".id"
+		if _, err := ops.Stat(idPath); err == nil {
+			_ = ops.Rename(idPath, targetPath+".id")
+		}
 
-	slog.InfoContext(ctx, "Moved corrupted metadata to safety folder preserving structure",
-		"original", metadataPath,
-		"target", targetPath)
-	return nil
+		slog.InfoContext(ctx, "Moved corrupted metadata to safety folder preserving structure",
+			"original", metadataPath,
+			"target", targetPath)
+		return nil
+	})
 }
 
 // CleanupOrphanedIDSymlinks walks the .ids/ directory and removes symlinks whose
 // targets no longer exist. Empty shard directories are cleaned up afterwards.
 // Returns the number of removed symlinks.
 func (ms *MetadataService) CleanupOrphanedIDSymlinks(ctx context.Context) (int, error) {
-	idsRoot := filepath.Join(ms.rootPath, ".ids")
+	ms.migrateMu.RLock()
+	defer ms.migrateMu.RUnlock()
+
+	idsRoot := filepath.Join(ms.RootPath(), ".ids")
 	if _, err := os.Stat(idsRoot); os.IsNotExist(err) {
 		return 0, nil
 	}
@@ -1039,11 +1343,140 @@ func (ms *MetadataService) CleanupOrphanedIDSymlinks(ctx context.Context) (int,
 	}
 
 	// Clean empty shard directories bottom-up
-	utils.RemoveEmptyDirs(ms.rootPath, idsRoot)
+	utils.RemoveEmptyDirs(ms.RootPath(), idsRoot)
 
 	return removed, nil
 }
 
+// idSymlinkPath returns the sharded .ids/ symlink path for an nzbdav ID:
+// the first five characters become nested shard directories, e.g.
+// .ids/4/0/e/9/a/40e9a6c9-....meta. Returns "" for IDs shorter than five chars.
+func (ms *MetadataService) idSymlinkPath(nzbdavID string) string {
+	if len(nzbdavID) < 5 {
+		return ""
+	}
+	parts := []string{ms.RootPath(), ".ids"}
+	for _, c := range nzbdavID[:5] {
+		parts = append(parts, string(c))
+	}
+	parts = append(parts, nzbdavID+".meta")
+	return filepath.Join(parts...)
+}
+
+// UpdateIDSymlink repoints an existing .ids/ symlink for nzbdavID at the
+// metadata file of virtualPath, using a relative target so the metadata root
+// stays relocatable. Returns false when no symlink exists for the ID; the
+// symlink is replaced atomically via a temporary link and rename.
+func (ms *MetadataService) UpdateIDSymlink(nzbdavID, virtualPath string) (bool, error) {
+	ms.migrateMu.RLock()
+	defer ms.migrateMu.RUnlock()
+
+	linkPath := ms.idSymlinkPath(nzbdavID)
+	if linkPath == "" {
+		return false, nil
+	}
+	if info, err := os.Lstat(linkPath); err != nil || info.Mode()&os.ModeSymlink == 0 {
+		return false, nil
+	}
+
+	if err := ms.writeIDSymlink(linkPath, virtualPath); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// RepairIDSymlink points the .ids/ symlink for nzbdavID at the metadata file
+// of virtualPath, creating the shard directories and the symlink if missing.
+func (ms *MetadataService) RepairIDSymlink(nzbdavID, virtualPath string) error {
+	ms.migrateMu.RLock()
+	defer ms.migrateMu.RUnlock()
+
+	linkPath := ms.idSymlinkPath(nzbdavID)
+	if linkPath == "" {
+		return fmt.Errorf("invalid nzbdav ID %q", nzbdavID)
+	}
+	if err := os.MkdirAll(filepath.Dir(linkPath), 0755); err != nil {
+		return fmt.Errorf("failed to create ID shard directory: %w", err)
+	}
+	return ms.writeIDSymlink(linkPath, virtualPath)
+}
+
+// writeIDSymlink atomically (re)creates linkPath with a relative target at
+// the metadata file of virtualPath, so the metadata root stays relocatable.
+func (ms *MetadataService) writeIDSymlink(linkPath, virtualPath string) error {
+	target, err := filepath.Rel(filepath.Dir(linkPath), ms.GetMetadataFilePath(virtualPath))
+	if err != nil {
+		return fmt.Errorf("failed to compute ID symlink target: %w", err)
+	}
+
+	tmpPath := linkPath + ".tmp"
+	return ms.retryWrite(linkPath, func() error {
+		_ = os.Remove(tmpPath)
+		if err := os.Symlink(target, tmpPath); err != nil {
+			return fmt.Errorf("failed to create ID symlink: %w", err)
+		}
+		if err := ms.fileOps().Rename(tmpPath, linkPath); err != nil {
+			_ = os.Remove(tmpPath)
+			return fmt.Errorf("failed to replace ID symlink: %w", err)
+		}
+		return nil
+	})
+}
+
+// FindFileByNzbdavID searches the .id sidecars under the metadata root for
+// nzbdavID and returns the virtual path of the matching file. It returns ""
+// when no file carries the ID. This walks the whole tree, so it is meant as a
+// fallback for stale .ids/ symlinks rather than a primary lookup.
+func (ms *MetadataService) FindFileByNzbdavID(ctx context.Context, nzbdavID string) (string, error) {
+	if nzbdavID == "" {
+		return "", nil
+	}
+
+	var found string
+	root := ms.RootPath()
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		if err != nil {
+			return nil // skip unreadable entries
+		}
+		if d.IsDir() {
+			if path != root && (d.Name() == ".ids" || d.Name() == "corrupted_metadata") {
+				return filepath.SkipDir
+			}
+			if depthErr := ms.checkDepth(root, path); depthErr != nil {
+				slog.WarnContext(ctx, "Skipping metadata directory below max depth", "path", path, "error", depthErr)
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(d.Name(), ".meta.id") {
+			return nil
+		}
+
+		data, readErr := os.ReadFile(path)
+		if readErr != nil || strings.TrimSpace(string(data)) != nzbdavID {
+			return nil
+		}
+
+		metaPath := strings.TrimSuffix(path, ".id")
+		if _, statErr := os.Stat(metaPath); statErr != nil {
+			return nil // orphaned sidecar
+		}
+		rel, relErr := filepath.Rel(root, metaPath)
+		if relErr != nil {
+			return nil
+		}
+		found = filepath.ToSlash(strings.TrimSuffix(rel, ".meta"))
+		return filepath.SkipAll
+	})
+	if err != nil {
+		return "", err
+	}
+	return found, nil
+}
+
 func (ms *MetadataService) isCompleteDir(path string) bool {
 	// Simple check to avoid deleting the 'complete' folder itself
 	return filepath.Base(path) == "complete"
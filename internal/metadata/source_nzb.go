@@ -0,0 +1,154 @@
+package metadata
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	metapb "github.com/javi11/altmount/internal/metadata/proto"
+	"google.golang.org/protobuf/proto"
+)
+
+// sourceNzbMigrationSentinel marks a metadata root whose absolute source NZB
+// paths have been rewritten relative to the NZB root.
+const sourceNzbMigrationSentinel = ".migration_relative_source_nzb_v1"
+
+// SetNzbRoot sets the directory source NZB paths and v3 store refs are stored
+// relative to. Paths inside it are written relative and resolved against the
+// current root when read, so the directory can be moved by pointing the root
+// at its new location. Paths outside it are kept as given, and so are store
+// refs written as absolute paths before this was introduced, including when
+// their file is rewritten (see storeRefKey). "" disables the rewrite.
+func (ms *MetadataService) SetNzbRoot(root string) {
+	if root == "" {
+		ms.nzbRoot.Store(nil)
+		return
+	}
+	root = filepath.Clean(root)
+	ms.nzbRoot.Store(&root)
+}
+
+func (ms *MetadataService) nzbRootDir() string {
+	if root := ms.nzbRoot.Load(); root != nil {
+		return *root
+	}
+	return ""
+}
+
+// relativeSourceNzbPath returns p relative to the NZB root when p lies inside
+// it, and p unchanged otherwise. Used for source NZB paths and store refs.
+func (ms *MetadataService) relativeSourceNzbPath(p string) string {
+	root := ms.nzbRootDir()
+	if root == "" || !filepath.IsAbs(p) {
+		return p
+	}
+	rel, err := filepath.Rel(root, p)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return p
+	}
+	return rel
+}
+
+// ResolveSourceNzbPath returns the absolute path of a stored source NZB path:
+// relative paths are joined to the current NZB root, anything else is
+// returned unchanged.
+func (ms *MetadataService) ResolveSourceNzbPath(p string) string {
+	root := ms.nzbRootDir()
+	if root == "" || p == "" || filepath.IsAbs(p) {
+		return p
+	}
+	return filepath.Join(root, p)
+}
+
+// MigrateSourceNzbPaths rewrites metadata files whose source NZB path is an
+// absolute path inside the NZB root so it is stored relative to it. It runs
+// once per metadata root: completion is recorded in a sentinel file and later
+// calls return immediately. Files that cannot be read or rewritten are logged
+// and skipped. Returns how many files were rewritten.
+func (ms *MetadataService) MigrateSourceNzbPaths(ctx context.Context) (int, error) {
+	ms.migrateMu.RLock()
+	defer ms.migrateMu.RUnlock()
+
+	if ms.nzbRootDir() == "" {
+		return 0, nil
+	}
+	root := ms.RootPath()
+	sentinelPath := filepath.Join(root, sourceNzbMigrationSentinel)
+	if _, err := os.Stat(sentinelPath); err == nil {
+		return 0, nil
+	}
+
+	var count int
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err != nil || !d.Type().IsRegular() || !strings.HasSuffix(d.Name(), ".meta") {
+			return nil // skip unreadable entries, directories and .ids symlinks
+		}
+
+		rewritten, rewriteErr := ms.relativizeSourceNzbPath(path)
+		if rewriteErr != nil {
+			slog.WarnContext(ctx, "Source NZB migration: failed to rewrite metadata",
+				"path", path, "error", rewriteErr)
+			return nil
+		}
+		if rewritten {
+			count++
+		}
+		return nil
+	})
+	if err != nil {
+		return count, fmt.Errorf("source NZB migration walk failed: %w", err)
+	}
+
+	if writeErr := os.WriteFile(sentinelPath, []byte("done\n"), 0644); writeErr != nil {
+		slog.WarnContext(ctx, "Source NZB migration: failed to write sentinel file",
+			"path", sentinelPath, "error", writeErr)
+	}
+	if count > 0 {
+		slog.InfoContext(ctx, "Source NZB path migration complete", "rewritten", count)
+	}
+	return count, nil
+}
+
+// relativizeSourceNzbPath rewrites the .meta file at metadataPath in place
+// when its source NZB path can be stored relative to the NZB root. The proto
+// is rewritten as stored, keeping the v3 prefix and segment references.
+func (ms *MetadataService) relativizeSourceNzbPath(metadataPath string) (bool, error) {
+	data, err := os.ReadFile(metadataPath)
+	if err != nil {
+		return false, err
+	}
+
+	var prefix []byte
+	payload := data
+	if isV3Meta(data) {
+		prefix, payload = metaMagicV3, data[len(metaMagicV3):]
+	}
+
+	metadata := &metapb.FileMetadata{}
+	if err := proto.Unmarshal(payload, metadata); err != nil {
+		return false, fmt.Errorf("failed to unmarshal metadata: %w", err)
+	}
+	rel := ms.relativeSourceNzbPath(metadata.SourceNzbPath)
+	if rel == metadata.SourceNzbPath {
+		return false, nil
+	}
+	metadata.SourceNzbPath = rel
+
+	raw, err := proto.Marshal(metadata)
+	if err != nil {
+		return false, fmt.Errorf("failed to marshal metadata: %w", err)
+	}
+	out := append(append([]byte{}, prefix...), raw...)
+
+	if err := ms.writeFileAtomic(metadataPath, "."+filepath.Base(metadataPath), out); err != nil {
+		return false, fmt.Errorf("failed to write metadata file: %w", err)
+	}
+	return true, nil
+}
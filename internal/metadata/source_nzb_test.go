@@ -0,0 +1,259 @@
+package metadata
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	metapb "github.com/javi11/altmount/internal/metadata/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+// storedSourceNzbPath returns the source NZB path as written on disk.
+func storedSourceNzbPath(t *testing.T, ms *MetadataService, virtualPath string) string {
+	t.Helper()
+	data, err := os.ReadFile(ms.GetMetadataFilePath(virtualPath))
+	require.NoError(t, err)
+	if isV3Meta(data) {
+		data = data[len(metaMagicV3):]
+	}
+	meta := &metapb.FileMetadata{}
+	require.NoError(t, proto.Unmarshal(data, meta))
+	return meta.SourceNzbPath
+}
+
+func writeSourceMeta(t *testing.T, ms *MetadataService, virtualPath, sourceNzbPath string) {
+	t.Helper()
+	meta := ms.CreateFileMetadata(
+		1024, sourceNzbPath, metapb.FileStatus_FILE_STATUS_HEALTHY,
+		nil, metapb.Encryption_NONE, "", "", nil, nil, 0, nil, "",
+	)
+	require.NoError(t, ms.WriteFileMetadata(virtualPath, meta))
+	assert.Equal(t, sourceNzbPath, meta.SourceNzbPath, "caller's metadata keeps the absolute path")
+}
+
+func TestSourceNzbPath_ResolvesAgainstRelocatedRoot(t *testing.T) {
+	ms := NewMetadataService(t.TempDir())
+	oldRoot := filepath.Join(t.TempDir(), "nzbs")
+	newRoot := filepath.Join(t.TempDir(), "moved")
+	ms.SetNzbRoot(oldRoot)
+
+	virtualPath := filepath.Join("tv", "show.mkv")
+	writeSourceMeta(t, ms, virtualPath, filepath.Join(oldRoot, "tv", "show.nzb"))
+	assert.Equal(t, filepath.Join("tv", "show.nzb"), storedSourceNzbPath(t, ms, virtualPath))
+
+	// Relocate the NZB directory and point the root at it.
+	relocated := filepath.Join(newRoot, "tv", "show.nzb")
+	require.NoError(t, os.MkdirAll(filepath.Dir(relocated), 0755))
+	require.NoError(t, os.WriteFile(relocated, []byte("<nzb/>"), 0644))
+	ms.SetNzbRoot(newRoot)
+
+	meta, err := ms.ReadFileMetadata(virtualPath)
+	require.NoError(t, err)
+	assert.Equal(t, relocated, meta.SourceNzbPath)
+
+	// Source deletion follows the relocated path.
+	require.NoError(t, ms.DeleteFileMetadataWithSourceNzb(context.Background(), virtualPath, true))
+	assert.NoFileExists(t, relocated)
+}
+
+// mapStoreRefCounter counts store references by key.
+type mapStoreRefCounter map[string]int64
+
+func (m mapStoreRefCounter) IncStoreRef(_ context.Context, storePath string) error {
+	m[storePath]++
+	return nil
+}
+
+func (m mapStoreRefCounter) DecStoreRef(_ context.Context, storePath string) (int64, error) {
+	if m[storePath] > 0 {
+		m[storePath]--
+	}
+	return m[storePath], nil
+}
+
+func TestStoreRef_ResolvesAgainstRelocatedRoot(t *testing.T) {
+	ms := NewMetadataService(t.TempDir())
+	counts := mapStoreRefCounter{}
+	ms.SetStoreRefCounter(counts)
+	oldRoot := filepath.Join(t.TempDir(), "nzbs")
+	newRoot := filepath.Join(t.TempDir(), "moved")
+	ms.SetNzbRoot(oldRoot)
+
+	storeRef := filepath.Join(oldRoot, "movie.nzbz")
+	store := &metapb.NzbStore{Files: []*metapb.NzbFileEntry{
+		{Subject: "Movie.mkv", Segments: []*metapb.NzbSeg{{Id: "a@n", Number: 1, Bytes: 100}}},
+	}}
+	require.NoError(t, ms.Store().WriteStore(storeRef, store))
+
+	meta := &metapb.FileMetadata{
+		FileSize:    100,
+		Status:      metapb.FileStatus_FILE_STATUS_HEALTHY,
+		SegmentData: []*metapb.SegmentData{{Id: "a@n", SegmentSize: 100, StartOffset: 0, EndOffset: 99}},
+	}
+	virtualPath := filepath.Join("movies", "Movie.mkv")
+	require.NoError(t, ms.WriteFileMetadataV3(context.Background(), virtualPath, meta, map[string]int64{"a@n": 0}, storeRef))
+	ref, _ := ms.readStoreRef(ms.GetMetadataFilePath(virtualPath))
+	assert.Equal(t, "movie.nzbz", ref)
+	assert.Equal(t, int64(1), counts["movie.nzbz"], "refcount keyed by the stored ref")
+
+	// Relocate the NZB directory and point the root at it.
+	require.NoError(t, os.Rename(oldRoot, newRoot))
+	ms.SetNzbRoot(newRoot)
+
+	got, err := ms.ReadFileMetadata(virtualPath)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(newRoot, "movie.nzbz"), got.StoreRef)
+	require.Len(t, got.SegmentData, 1)
+	assert.Equal(t, "a@n", got.SegmentData[0].Id)
+
+	// Dropping the last reference removes the store at its new location.
+	require.NoError(t, ms.DeleteFileMetadataWithSourceNzb(context.Background(), virtualPath, false))
+	assert.Equal(t, int64(0), counts["movie.nzbz"])
+	assert.NoFileExists(t, filepath.Join(newRoot, "movie.nzbz"))
+}
+
+func TestRetainedSourceNzb_RemovedWithStore(t *testing.T) {
+	ms := NewMetadataService(t.TempDir())
+	counts := mapStoreRefCounter{}
+	ms.SetStoreRefCounter(counts)
+	nzbRoot := filepath.Join(t.TempDir(), "nzbs")
+	ms.SetNzbRoot(nzbRoot)
+
+	storeRef := filepath.Join(nzbRoot, "movies", "7-Movie.nzbz")
+	retained := filepath.Join(nzbRoot, "movies", "7-Movie.nzb")
+	require.NoError(t, ms.Store().WriteStore(storeRef, &metapb.NzbStore{Files: []*metapb.NzbFileEntry{
+		{Subject: "Movie.mkv", Segments: []*metapb.NzbSeg{{Id: "a@n", Number: 1, Bytes: 100}}},
+	}}))
+	require.NoError(t, os.WriteFile(retained, []byte("<nzb/>"), 0644))
+
+	write := func(virtualPath string) {
+		meta := &metapb.FileMetadata{
+			FileSize:    100,
+			Status:      metapb.FileStatus_FILE_STATUS_HEALTHY,
+			SegmentData: []*metapb.SegmentData{{Id: "a@n", SegmentSize: 100, StartOffset: 0, EndOffset: 99}},
+		}
+		require.NoError(t, ms.WriteFileMetadataV3(context.Background(), virtualPath, meta, map[string]int64{"a@n": 0}, storeRef))
+		require.NoError(t, ms.UpdateFileMetadata(virtualPath, func(m *metapb.FileMetadata) {
+			m.SourceNzbPath = retained
+		}))
+	}
+	write("movies/Movie/Movie.mkv")
+	write("movies/Movie/Featurette.mkv")
+	write("movies/Other/Movie.mkv")
+
+	// A file still using the store keeps both.
+	require.NoError(t, ms.DeleteFileMetadataWithSourceNzb(context.Background(), "movies/Other/Movie.mkv", false))
+	assert.FileExists(t, storeRef)
+	assert.FileExists(t, retained)
+
+	// Deleting the last ones removes the retained NZB with the store.
+	require.NoError(t, ms.DeleteDirectory("movies/Movie"))
+	assert.Equal(t, int64(0), counts["movies/7-Movie.nzbz"])
+	assert.NoFileExists(t, storeRef)
+	assert.NoFileExists(t, retained)
+}
+
+func TestLegacyAbsoluteStoreRef_UpdateThenDeleteKeepsSharedStore(t *testing.T) {
+	ms := NewMetadataService(t.TempDir())
+	counts := mapStoreRefCounter{}
+	ms.SetStoreRefCounter(counts)
+	nzbRoot := filepath.Join(t.TempDir(), "nzbs")
+
+	storeRef := filepath.Join(nzbRoot, "tv", "9-Show.nzbz")
+	require.NoError(t, ms.Store().WriteStore(storeRef, &metapb.NzbStore{Files: []*metapb.NzbFileEntry{
+		{Subject: "Show.mkv", Segments: []*metapb.NzbSeg{{Id: "a@n", Number: 1, Bytes: 100}}},
+	}}))
+
+	// Imported before the NZB root was configured: refs and counts are absolute.
+	for _, virtualPath := range []string{"tv/Show/E01.mkv", "tv/Show/E02.mkv"} {
+		meta := &metapb.FileMetadata{
+			FileSize:    100,
+			Status:      metapb.FileStatus_FILE_STATUS_HEALTHY,
+			SegmentData: []*metapb.SegmentData{{Id: "a@n", SegmentSize: 100, StartOffset: 0, EndOffset: 99}},
+		}
+		require.NoError(t, ms.WriteFileMetadataV3(context.Background(), virtualPath, meta, map[string]int64{"a@n": 0}, storeRef))
+	}
+	require.Equal(t, int64(2), counts[storeRef])
+	ms.SetNzbRoot(nzbRoot)
+
+	// Rewriting a legacy file keeps its ref in the form it is counted under.
+	require.NoError(t, ms.UpdateFileStatus("tv/Show/E01.mkv", metapb.FileStatus_FILE_STATUS_CORRUPTED))
+	stored, _ := ms.readStoreRef(ms.GetMetadataFilePath("tv/Show/E01.mkv"))
+	assert.Equal(t, storeRef, stored)
+
+	require.NoError(t, ms.DeleteFileMetadataWithSourceNzb(context.Background(), "tv/Show/E01.mkv", false))
+	assert.FileExists(t, storeRef, "the sibling still uses the store")
+	assert.Equal(t, int64(1), counts[storeRef])
+
+	require.NoError(t, ms.DeleteFileMetadataWithSourceNzb(context.Background(), "tv/Show/E02.mkv", false))
+	assert.NoFileExists(t, storeRef)
+}
+
+func TestSourceNzbPath_OutsideRootKeptAbsolute(t *testing.T) {
+	ms := NewMetadataService(t.TempDir())
+	ms.SetNzbRoot(filepath.Join(t.TempDir(), "nzbs"))
+
+	outside := filepath.Join(t.TempDir(), "elsewhere", "movie.nzb")
+	virtualPath := filepath.Join("movies", "movie.mkv")
+	writeSourceMeta(t, ms, virtualPath, outside)
+	assert.Equal(t, outside, storedSourceNzbPath(t, ms, virtualPath))
+
+	meta, err := ms.ReadFileMetadata(virtualPath)
+	require.NoError(t, err)
+	assert.Equal(t, outside, meta.SourceNzbPath)
+}
+
+func TestMigrateSourceNzbPaths(t *testing.T) {
+	ms := NewMetadataService(t.TempDir())
+	nzbRoot := filepath.Join(t.TempDir(), "nzbs")
+	outside := filepath.Join(t.TempDir(), "other.nzb")
+
+	// Metadata written before the root was known holds absolute paths.
+	writeSourceMeta(t, ms, "tv/a.mkv", filepath.Join(nzbRoot, "tv", "a.nzb"))
+	writeSourceMeta(t, ms, "tv/b.mkv", outside)
+	v3 := append(append([]byte{}, metaMagicV3...), mustMarshal(t, &metapb.FileMetadata{
+		FileSize:      2048,
+		SourceNzbPath: filepath.Join(nzbRoot, "c.nzbz"),
+	})...)
+	require.NoError(t, os.WriteFile(ms.GetMetadataFilePath("c.mkv"), v3, 0644))
+
+	ms.SetNzbRoot(nzbRoot)
+	ctx := context.Background()
+	count, err := ms.MigrateSourceNzbPaths(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	assert.Equal(t, filepath.Join("tv", "a.nzb"), storedSourceNzbPath(t, ms, "tv/a.mkv"))
+	assert.Equal(t, outside, storedSourceNzbPath(t, ms, "tv/b.mkv"))
+	assert.Equal(t, "c.nzbz", storedSourceNzbPath(t, ms, "c.mkv"))
+
+	data, err := os.ReadFile(ms.GetMetadataFilePath("c.mkv"))
+	require.NoError(t, err)
+	assert.True(t, isV3Meta(data), "v3 prefix is preserved")
+
+	meta, err := ms.ReadFileMetadata("c.mkv")
+	require.NoError(t, err)
+	assert.Equal(t, int64(2048), meta.FileSize)
+	assert.Equal(t, filepath.Join(nzbRoot, "c.nzbz"), meta.SourceNzbPath)
+
+	// The sentinel makes later runs a no-op.
+	ms.SetNzbRoot("")
+	late := filepath.Join(nzbRoot, "tv", "d.nzb")
+	writeSourceMeta(t, ms, "tv/d.mkv", late)
+	ms.SetNzbRoot(nzbRoot)
+	count, err = ms.MigrateSourceNzbPaths(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, count)
+	assert.Equal(t, late, storedSourceNzbPath(t, ms, "tv/d.mkv"))
+}
+
+func mustMarshal(t *testing.T, m proto.Message) []byte {
+	t.Helper()
+	raw, err := proto.Marshal(m)
+	require.NoError(t, err)
+	return raw
+}
@@ -133,6 +133,8 @@ func resolveRefs(flat []*metapb.NzbSeg, refs []*metapb.SegmentRef) ([]*metapb.Se
 			SegmentSize: size,
 			StartOffset: r.StartOffset,
 			EndOffset:   r.EndOffset,
+			UsableStart: r.UsableStart,
+			UsableEnd:   r.UsableEnd,
 		}
 	}
 	return out, nil
@@ -192,16 +194,20 @@ func segDataToRefs(segments []*metapb.SegmentData, index map[string]int64) ([]*m
 			StartOffset:  seg.StartOffset,
 			EndOffset:    seg.EndOffset,
 			DecodedBytes: seg.SegmentSize,
+			UsableStart:  seg.UsableStart,
+			UsableEnd:    seg.UsableEnd,
 		}
 	}
 	return refs, nil
 }
 
-// isFullUse reports whether a ref uses its whole segment (no archive slicing):
-// start at 0 and end at the last decoded byte. Only full-use refs can be folded
-// into a SegmentRun, which implies start_offset=0 / end_offset=decoded-1.
+// isFullUse reports whether a ref uses its whole segment (no archive slicing
+// and no junk around the data): start at 0 and end at the last decoded byte.
+// Only full-use refs can be folded into a SegmentRun, which implies
+// start_offset=0 / end_offset=decoded-1.
 func isFullUse(r *metapb.SegmentRef) bool {
-	return r.StartOffset == 0 && r.DecodedBytes != 0 && r.EndOffset == r.DecodedBytes-1
+	return r.StartOffset == 0 && r.DecodedBytes != 0 && r.EndOffset == r.DecodedBytes-1 &&
+		r.UsableStart == 0 && r.UsableEnd == 0
 }
 
 // splitRefs partitions refs into compact SegmentRuns (maximal stretches of
@@ -286,6 +292,7 @@ func resolveSegments(flat []*metapb.NzbSeg, runs []*metapb.SegmentRun, refs []*m
 		}
 		entries = append(entries, entry{idx: r.StoreIndex, sd: &metapb.SegmentData{
 			Id: seg.Id, SegmentSize: size, StartOffset: r.StartOffset, EndOffset: r.EndOffset,
+			UsableStart: r.UsableStart, UsableEnd: r.UsableEnd,
 		}})
 	}
 	for _, run := range runs {
@@ -139,3 +139,32 @@ func TestResolveSegments_MixedMatchesExplicit(t *testing.T) {
 			"segment %d mismatch: want %+v got %+v", i, want[i], got[i])
 	}
 }
+
+func TestSegmentRefs_KeepUsableWindow(t *testing.T) {
+	flat := make([]*metapb.NzbSeg, 3)
+	index := make(map[string]int64, len(flat))
+	for i := range flat {
+		flat[i] = &metapb.NzbSeg{Id: fmt.Sprintf("seg-%d", i), Number: int32(i + 1), Bytes: 1000}
+		index[flat[i].Id] = int64(i)
+	}
+	// A full-use body followed by a last part that repeats 100 bytes of it.
+	segs := []*metapb.SegmentData{
+		{Id: "seg-0", SegmentSize: 1000, StartOffset: 0, EndOffset: 999},
+		{Id: "seg-1", SegmentSize: 1000, StartOffset: 0, EndOffset: 999},
+		{Id: "seg-2", SegmentSize: 600, StartOffset: 0, EndOffset: 499, UsableStart: 100},
+	}
+
+	refs, err := segDataToRefs(segs, index)
+	require.NoError(t, err)
+	runs, leftover := splitRefs(refs)
+	require.Len(t, runs, 1)
+	require.Len(t, leftover, 1, "a segment with a usable window must not fold into a run")
+
+	got, err := resolveSegments(flat, runs, leftover)
+	require.NoError(t, err)
+	require.Len(t, got, len(segs))
+	for i := range segs {
+		assert.Truef(t, proto.Equal(segs[i], got[i]),
+			"segment %d mismatch: want %+v got %+v", i, segs[i], got[i])
+	}
+}
@@ -0,0 +1,90 @@
+package metadata
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"path/filepath"
+	"syscall"
+	"time"
+)
+
+// Metadata writes are retried this many times, starting this far apart, until
+// SetWriteRetry says otherwise.
+const (
+	defaultWriteRetries    = 2
+	defaultWriteRetryDelay = 100 * time.Millisecond
+)
+
+// transientWriteErrnos are the errors network filesystems return for a brief
+// outage rather than a real problem with the write.
+var transientWriteErrnos = []syscall.Errno{
+	syscall.EAGAIN,
+	syscall.EINTR,
+	syscall.EBUSY,
+	syscall.ETIMEDOUT,
+	syscall.ESTALE,
+}
+
+// SetWriteRetry sets how many more times a metadata write failing with a
+// transient filesystem error is attempted, and the wait before the first
+// retry, doubled for each one after. retries <= 0 fails on the first error.
+func (ms *MetadataService) SetWriteRetry(retries int, delay time.Duration) {
+	ms.writeRetries.Store(int64(max(retries, 0)))
+	ms.writeRetryDelay.Store(int64(max(delay, 0)))
+}
+
+// isTransientWriteError reports whether a failed write is worth retrying.
+func isTransientWriteError(err error) bool {
+	for _, errno := range transientWriteErrnos {
+		if errors.Is(err, errno) {
+			return true
+		}
+	}
+	return false
+}
+
+// retryWrite runs fn, running it again after a growing delay while it fails
+// with a transient error, up to the configured number of retries. path names
+// what is being written in the log.
+func (ms *MetadataService) retryWrite(path string, fn func() error) error {
+	retries := int(ms.writeRetries.Load())
+	delay := time.Duration(ms.writeRetryDelay.Load())
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil || attempt > retries || !isTransientWriteError(err) {
+			return err
+		}
+		slog.Warn("Transient metadata write failure, retrying",
+			"path", path, "attempt", attempt, "retry_in", delay, "error", err)
+		time.Sleep(delay)
+		delay *= 2
+	}
+}
+
+// writeFileAtomic creates path's directory and replaces path with data via a
+// uniquely-named temporary file beside it, so readers never see a partial
+// file and concurrent writes to the same path don't race on the temporary
+// name. The temporary file is named tmpPrefix.<random>.tmp. Each step is
+// retried on transient errors; a retried write starts over with a fresh
+// temporary file.
+func (ms *MetadataService) writeFileAtomic(path, tmpPrefix string, data []byte) error {
+	ops := ms.fileOps()
+	dir := filepath.Dir(path)
+	if err := ms.retryWrite(dir, func() error { return ops.MkdirAll(dir, 0755) }); err != nil {
+		return fmt.Errorf("failed to create metadata directory: %w", err)
+	}
+	return ms.retryWrite(path, func() error {
+		tmpPath := filepath.Join(dir, fmt.Sprintf("%s.%016x.tmp", tmpPrefix, rand.Uint64()))
+		if err := ops.WriteFile(tmpPath, data, 0600); err != nil {
+			_ = ops.Remove(tmpPath)
+			return fmt.Errorf("failed to write temporary file: %w", err)
+		}
+		if err := ops.Rename(tmpPath, path); err != nil {
+			_ = ops.Remove(tmpPath)
+			return fmt.Errorf("failed to rename temporary file: %w", err)
+		}
+		return nil
+	})
+}
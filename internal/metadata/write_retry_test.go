@@ -0,0 +1,148 @@
+package metadata
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// flakyFileOps fails the first writeFailures WriteFile calls and the first
+// renameFailures Rename calls with err, then passes through to the os package,
+// like a network mount recovering from a brief outage.
+type flakyFileOps struct {
+	osFileOps
+	err error
+
+	mu             sync.Mutex
+	writeFailures  int
+	renameFailures int
+	writes         int
+	renames        int
+}
+
+func (f *flakyFileOps) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	f.mu.Lock()
+	f.writes++
+	fail := f.writes <= f.writeFailures
+	f.mu.Unlock()
+	if fail {
+		return &fs.PathError{Op: "write", Path: name, Err: f.err}
+	}
+	return f.osFileOps.WriteFile(name, data, perm)
+}
+
+func (f *flakyFileOps) Rename(oldpath, newpath string) error {
+	f.mu.Lock()
+	f.renames++
+	fail := f.renames <= f.renameFailures
+	f.mu.Unlock()
+	if fail {
+		return &os.LinkError{Op: "rename", Old: oldpath, New: newpath, Err: f.err}
+	}
+	return f.osFileOps.Rename(oldpath, newpath)
+}
+
+// tmpFiles lists the temporary files left under dir.
+func tmpFiles(t *testing.T, dir string) []string {
+	t.Helper()
+	var found []string
+	require.NoError(t, filepath.WalkDir(dir, func(path string, _ fs.DirEntry, err error) error {
+		if strings.HasSuffix(path, ".tmp") {
+			found = append(found, path)
+		}
+		return err
+	}))
+	return found
+}
+
+func TestWriteFileMetadata_RetriesTransientWriteFailure(t *testing.T) {
+	root := t.TempDir()
+	ms := NewMetadataService(root)
+	ms.SetWriteRetry(2, time.Millisecond)
+	ops := &flakyFileOps{err: syscall.EAGAIN, writeFailures: 1}
+	ms.SetFileOps(ops)
+
+	writeTestMeta(t, ms, "movies/flaky.mkv")
+
+	assert.Equal(t, 2, ops.writes)
+	meta, err := ms.ReadFileMetadata("movies/flaky.mkv")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1024), meta.FileSize)
+	assert.Empty(t, tmpFiles(t, root))
+}
+
+func TestWriteFileMetadata_RetriesTransientRenameFailure(t *testing.T) {
+	root := t.TempDir()
+	ms := NewMetadataService(root)
+	ms.SetWriteRetry(2, time.Millisecond)
+	ops := &flakyFileOps{err: syscall.ESTALE, renameFailures: 2}
+	ms.SetFileOps(ops)
+
+	writeTestMeta(t, ms, "movies/flaky.mkv")
+
+	assert.Equal(t, 3, ops.renames)
+	assert.True(t, ms.FileExists("movies/flaky.mkv"))
+	assert.Empty(t, tmpFiles(t, root), "failed attempts clean up their temporary file")
+}
+
+func TestWriteFileMetadata_GivesUp(t *testing.T) {
+	meta := func(ms *MetadataService) error {
+		return ms.WriteFileMetadata("movies/flaky.mkv", ms.CreateFileMetadata(1024, "test.nzb", 0,
+			nil, 0, "", "", nil, nil, 0, nil, ""))
+	}
+
+	t.Run("retries disabled", func(t *testing.T) {
+		ms := NewMetadataService(t.TempDir())
+		ms.SetWriteRetry(0, time.Millisecond)
+		ops := &flakyFileOps{err: syscall.EAGAIN, writeFailures: 1}
+		ms.SetFileOps(ops)
+
+		err := meta(ms)
+		assert.ErrorIs(t, err, syscall.EAGAIN)
+		assert.Equal(t, 1, ops.writes)
+	})
+
+	t.Run("retries exhausted", func(t *testing.T) {
+		ms := NewMetadataService(t.TempDir())
+		ms.SetWriteRetry(2, time.Millisecond)
+		ops := &flakyFileOps{err: syscall.EAGAIN, writeFailures: 5}
+		ms.SetFileOps(ops)
+
+		err := meta(ms)
+		assert.ErrorIs(t, err, syscall.EAGAIN)
+		assert.Equal(t, 3, ops.writes)
+	})
+
+	t.Run("permanent error", func(t *testing.T) {
+		ms := NewMetadataService(t.TempDir())
+		ms.SetWriteRetry(2, time.Millisecond)
+		ops := &flakyFileOps{err: syscall.ENOSPC, writeFailures: 1}
+		ms.SetFileOps(ops)
+
+		err := meta(ms)
+		assert.ErrorIs(t, err, syscall.ENOSPC)
+		assert.Equal(t, 1, ops.writes, "only transient errors are retried")
+	})
+}
+
+func TestRepairIDSymlink_RetriesTransientRenameFailure(t *testing.T) {
+	ms := NewMetadataService(t.TempDir())
+	ms.SetWriteRetry(2, time.Millisecond)
+	writeTestMeta(t, ms, "movies/linked.mkv")
+	ops := &flakyFileOps{err: syscall.EBUSY, renameFailures: 1}
+	ms.SetFileOps(ops)
+
+	require.NoError(t, ms.RepairIDSymlink("abcdef123", "movies/linked.mkv"))
+	assert.Equal(t, 2, ops.renames)
+	target, err := os.Readlink(ms.idSymlinkPath("abcdef123"))
+	require.NoError(t, err)
+	assert.True(t, strings.HasSuffix(target, "linked.mkv.meta"), target)
+}
@@ -0,0 +1,48 @@
+package nzbfilesystem
+
+import (
+	"bytes"
+
+	metapb "github.com/javi11/altmount/internal/metadata/proto"
+)
+
+// aesSampleHeaders maps file extensions to a check of the header a correctly
+// decrypted sample starts with. AES-CBC is unauthenticated, so a wrong key
+// still decrypts, into noise; the container header is the known plaintext
+// that tells the two apart.
+var aesSampleHeaders = map[string]func(head []byte) bool{
+	".mkv":  isMatroskaHeader,
+	".mka":  isMatroskaHeader,
+	".webm": isMatroskaHeader,
+	".mp4":  isMP4Header,
+	".m4v":  isMP4Header,
+	".m4a":  isMP4Header,
+	".mov":  isMP4Header,
+	".avi":  func(h []byte) bool { return len(h) >= 12 && string(h[0:4]) == "RIFF" && string(h[8:12]) == "AVI " },
+	".ts":   func(h []byte) bool { return len(h) > 188 && h[0] == 0x47 && h[188] == 0x47 },
+	".m2ts": func(h []byte) bool { return len(h) > 196 && h[4] == 0x47 && h[196] == 0x47 },
+	".mts":  func(h []byte) bool { return len(h) > 196 && h[4] == 0x47 && h[196] == 0x47 },
+	".mpg":  func(h []byte) bool { return bytes.HasPrefix(h, []byte{0x00, 0x00, 0x01, 0xBA}) },
+	".mpeg": func(h []byte) bool { return bytes.HasPrefix(h, []byte{0x00, 0x00, 0x01, 0xBA}) },
+	".iso": func(h []byte) bool {
+		// ISO 9660 or UDF volume descriptor at the start of sector 16.
+		return len(h) >= 0x8006 && (string(h[0x8001:0x8006]) == "CD001" || string(h[0x8001:0x8006]) == "BEA01")
+	},
+}
+
+func isMatroskaHeader(h []byte) bool { return bytes.HasPrefix(h, []byte{0x1A, 0x45, 0xDF, 0xA3}) }
+func isMP4Header(h []byte) bool      { return len(h) >= 8 && string(h[4:8]) == "ftyp" }
+
+// usesAes reports whether reading meta decrypts AES, either for the whole
+// file or for one of its nested sources.
+func usesAes(meta *metapb.FileMetadata) bool {
+	if meta.Encryption == metapb.Encryption_AES {
+		return true
+	}
+	for _, src := range meta.NestedSources {
+		if len(src.AesKey) > 0 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,65 @@
+package nzbfilesystem
+
+import (
+	"context"
+	"io"
+	"io/fs"
+	"os"
+	"strings"
+
+	"github.com/javi11/altmount/internal/config"
+	"github.com/javi11/altmount/internal/utils"
+)
+
+// arrCorruptView returns how a corrupted file at normalizedName is presented
+// to this request: Health.ArrCorruptView, or "" (as stored) when the request
+// asked to see corrupted files or lies inside corrupted_metadata.
+func (mrf *MetadataRemoteFile) arrCorruptView(ctx context.Context, normalizedName string) config.ArrCorruptView {
+	if sc, _ := ctx.Value(utils.ShowCorrupted).(bool); sc {
+		return ""
+	}
+	if normalizedName == corruptedMetadataDir || strings.HasPrefix(normalizedName, corruptedMetadataDir+"/") {
+		return ""
+	}
+	if mrf.configGetter == nil {
+		return ""
+	}
+	return mrf.configGetter().Health.ArrCorruptView
+}
+
+// emptyFile is the read-only, zero-byte handle a corrupted file opens as
+// under config.ArrCorruptViewZero.
+type emptyFile struct {
+	name string
+	info fs.FileInfo
+	pos  int64
+}
+
+func (f *emptyFile) Read([]byte) (int, error)          { return 0, io.EOF }
+func (f *emptyFile) ReadAt([]byte, int64) (int, error) { return 0, io.EOF }
+func (f *emptyFile) Close() error                      { return nil }
+func (f *emptyFile) Name() string                      { return f.name }
+func (f *emptyFile) Stat() (fs.FileInfo, error)        { return f.info, nil }
+func (f *emptyFile) Sync() error                       { return nil }
+
+func (f *emptyFile) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart, io.SeekEnd:
+	case io.SeekCurrent:
+		offset += f.pos
+	default:
+		return 0, ErrInvalidWhence
+	}
+	if offset < 0 {
+		return 0, ErrSeekNegative
+	}
+	f.pos = offset
+	return offset, nil
+}
+
+func (f *emptyFile) Readdir(int) ([]fs.FileInfo, error) { return nil, ErrNotDirectory }
+func (f *emptyFile) Readdirnames(int) ([]string, error) { return nil, ErrNotDirectory }
+func (f *emptyFile) Write([]byte) (int, error)          { return 0, os.ErrPermission }
+func (f *emptyFile) WriteAt([]byte, int64) (int, error) { return 0, os.ErrPermission }
+func (f *emptyFile) WriteString(string) (int, error)    { return 0, os.ErrPermission }
+func (f *emptyFile) Truncate(int64) error               { return os.ErrPermission }
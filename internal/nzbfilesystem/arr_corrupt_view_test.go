@@ -0,0 +1,118 @@
+package nzbfilesystem
+
+import (
+	"context"
+	"io"
+	"io/fs"
+	"testing"
+
+	"github.com/javi11/altmount/internal/config"
+	metapb "github.com/javi11/altmount/internal/metadata/proto"
+	"github.com/javi11/altmount/internal/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newArrCorruptViewFS returns a filesystem over complete/Movie holding a
+// healthy good.mkv and a corrupted bad.mkv, and the config it reads.
+func newArrCorruptViewFS(t *testing.T) (*MetadataRemoteFile, *config.Config) {
+	t.Helper()
+	repo, _, ms := setupStreamHealthEnv(t)
+	writeStreamMeta(t, ms, "complete/Movie/good.mkv")
+	bad := ms.CreateFileMetadata(
+		4096, "test.nzb", metapb.FileStatus_FILE_STATUS_CORRUPTED,
+		nil, metapb.Encryption_NONE, "", "", nil, nil, 0, nil, "",
+	)
+	require.NoError(t, ms.WriteFileMetadata("complete/Movie/bad.mkv", bad))
+
+	cfg := config.DefaultConfig()
+	return &MetadataRemoteFile{
+		metadataService:  ms,
+		healthRepository: repo,
+		configGetter:     func() *config.Config { return cfg },
+	}, cfg
+}
+
+// listSizes lists complete/Movie, mapping each file to its size.
+func listSizes(t *testing.T, ctx context.Context, mrf *MetadataRemoteFile) map[string]int64 {
+	t.Helper()
+	ok, dir, err := mrf.OpenFile(ctx, "/complete/Movie")
+	require.NoError(t, err)
+	require.True(t, ok)
+	infos, err := dir.Readdir(0)
+	require.NoError(t, err)
+	sizes := make(map[string]int64, len(infos))
+	for _, fi := range infos {
+		sizes[fi.Name()] = fi.Size()
+	}
+	return sizes
+}
+
+func TestArrCorruptView(t *testing.T) {
+	arrCtx := context.Background()
+	adminCtx := context.WithValue(arrCtx, utils.ShowCorrupted, true)
+
+	t.Run("hide", func(t *testing.T) {
+		mrf, cfg := newArrCorruptViewFS(t)
+		cfg.Health.ArrCorruptView = config.ArrCorruptViewHide
+
+		assert.Equal(t, map[string]int64{"good.mkv": 1024}, listSizes(t, arrCtx, mrf))
+		_, _, err := mrf.Stat(arrCtx, "/complete/Movie/bad.mkv")
+		assert.ErrorIs(t, err, fs.ErrNotExist)
+		ok, _, err := mrf.OpenFile(arrCtx, "/complete/Movie/bad.mkv")
+		require.NoError(t, err)
+		assert.False(t, ok)
+	})
+
+	t.Run("zero", func(t *testing.T) {
+		mrf, cfg := newArrCorruptViewFS(t)
+		cfg.Health.ArrCorruptView = config.ArrCorruptViewZero
+
+		assert.Equal(t, map[string]int64{"good.mkv": 1024, "bad.mkv": 0}, listSizes(t, arrCtx, mrf))
+		ok, info, err := mrf.Stat(arrCtx, "/complete/Movie/bad.mkv")
+		require.NoError(t, err)
+		require.True(t, ok)
+		assert.Zero(t, info.Size())
+
+		ok, f, err := mrf.OpenFile(arrCtx, "/complete/Movie/bad.mkv")
+		require.NoError(t, err)
+		require.True(t, ok)
+		defer f.Close()
+		data, err := io.ReadAll(f)
+		require.NoError(t, err)
+		assert.Empty(t, data)
+		info, err = f.Stat()
+		require.NoError(t, err)
+		assert.Equal(t, "bad.mkv", info.Name())
+		assert.Zero(t, info.Size())
+	})
+
+	for _, view := range []config.ArrCorruptView{"", config.ArrCorruptViewHide, config.ArrCorruptViewZero} {
+		t.Run("admin view/"+string(view), func(t *testing.T) {
+			mrf, cfg := newArrCorruptViewFS(t)
+			cfg.Health.ArrCorruptView = view
+
+			assert.Equal(t, map[string]int64{"good.mkv": 1024, "bad.mkv": 4096}, listSizes(t, adminCtx, mrf))
+			ok, info, err := mrf.Stat(adminCtx, "/complete/Movie/bad.mkv")
+			require.NoError(t, err)
+			require.True(t, ok)
+			assert.Equal(t, int64(4096), info.Size())
+			_, _, err = mrf.OpenFile(adminCtx, "/complete/Movie/bad.mkv")
+			var corrupted *CorruptedFileError
+			assert.ErrorAs(t, err, &corrupted)
+		})
+	}
+
+	t.Run("unset keeps the previous behavior", func(t *testing.T) {
+		mrf, _ := newArrCorruptViewFS(t)
+
+		assert.Equal(t, map[string]int64{"good.mkv": 1024}, listSizes(t, arrCtx, mrf))
+		ok, info, err := mrf.Stat(arrCtx, "/complete/Movie/bad.mkv")
+		require.NoError(t, err)
+		require.True(t, ok)
+		assert.Equal(t, int64(4096), info.Size())
+		_, _, err = mrf.OpenFile(arrCtx, "/complete/Movie/bad.mkv")
+		var corrupted *CorruptedFileError
+		assert.ErrorAs(t, err, &corrupted)
+	})
+}
@@ -0,0 +1,58 @@
+package nzbfilesystem
+
+import (
+	"context"
+	"io/fs"
+	"testing"
+
+	"github.com/javi11/altmount/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newCaseRemoteFile(t *testing.T, caseInsensitive bool) *MetadataRemoteFile {
+	t.Helper()
+	_, _, ms := setupStreamHealthEnv(t)
+	writeStreamMeta(t, ms, "complete/Movies/Some.Movie.2024/Some.Movie.2024.mkv")
+
+	cfg := config.DefaultConfig()
+	cfg.Metadata.CaseInsensitive = &caseInsensitive
+	return &MetadataRemoteFile{
+		metadataService: ms,
+		configGetter:    func() *config.Config { return cfg },
+	}
+}
+
+func TestStat_CaseInsensitiveResolvesOnDiskCasing(t *testing.T) {
+	mrf := newCaseRemoteFile(t, true)
+	ctx := context.Background()
+
+	ok, info, err := mrf.Stat(ctx, "/complete/movies/some.movie.2024/SOME.MOVIE.2024.MKV")
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, "Some.Movie.2024.mkv", info.Name())
+	assert.Equal(t, int64(1024), info.Size())
+
+	ok, info, err = mrf.Stat(ctx, "/COMPLETE/MOVIES/")
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.True(t, info.IsDir())
+
+	// Exact casing keeps working, and a genuinely missing name still misses.
+	ok, _, err = mrf.Stat(ctx, "/complete/Movies/Some.Movie.2024/Some.Movie.2024.mkv")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	ok, _, err = mrf.Stat(ctx, "/complete/movies/some.movie.2024/other.mkv")
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+	assert.False(t, ok)
+}
+
+func TestStat_CaseSensitiveByDefault(t *testing.T) {
+	mrf := newCaseRemoteFile(t, false)
+
+	ok, _, err := mrf.Stat(context.Background(), "/complete/movies/some.movie.2024/SOME.MOVIE.2024.MKV")
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+	assert.False(t, ok)
+
+	assert.False(t, config.DefaultConfig().Metadata.IsCaseInsensitive())
+}
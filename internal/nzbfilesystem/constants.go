@@ -51,6 +51,21 @@ func (e *CorruptedFileError) Unwrap() error {
 	return e.UnderlyingErr
 }
 
+// TooManyStreamsError is returned by OpenFile when a client IP already holds
+// Streaming.MaxStreamsPerIP open streams.
+type TooManyStreamsError struct {
+	ClientIP string
+	Limit    int
+}
+
+func (e *TooManyStreamsError) Error() string {
+	return fmt.Sprintf("too many concurrent streams for client %s (limit %d)", e.ClientIP, e.Limit)
+}
+
+func (e *TooManyStreamsError) Unwrap() error {
+	return ErrTooManyStreams
+}
+
 // Error message constants
 var (
 	ErrCannotRemoveRoot    = errors.New("cannot remove root directory")
@@ -63,6 +78,13 @@ var (
 	ErrNoEncryptionParams  = errors.New("no NZB data available for encryption parameters")
 	ErrFileIsCorrupted     = errors.New("file is corrupted, there are some missing segments")
 	ErrFileClosed          = errors.New("file closed")
+	ErrTooManyStreams      = errors.New("too many concurrent streams")
+	ErrPathConflict        = errors.New("path exists as both a file and a directory")
+	ErrInvalidRange        = errors.New("invalid byte range")
+	ErrTooManyWarmups      = errors.New("too many concurrent warm-ups")
+	// ErrAesKeyUnverifiable is returned by VerifyCredentials for an
+	// AES-encrypted file whose type has no known header to check against.
+	ErrAesKeyUnverifiable = errors.New("AES key cannot be verified for this file type")
 )
 
 // Database operation error message templates
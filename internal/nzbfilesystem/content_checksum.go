@@ -0,0 +1,152 @@
+package nzbfilesystem
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"fmt"
+	"hash"
+	"log/slog"
+	"time"
+
+	"github.com/javi11/altmount/internal/database"
+)
+
+// contentChecksum hashes a handle's bytes as they are read so that a full
+// sequential read — normal playback — doubles as a verification pass against
+// the file's stored MD5. A read at offset 0 starts the pass over; a read that
+// does not continue where the last one ended abandons it until then.
+type contentChecksum struct {
+	hash hash.Hash
+	next int64 // offset the pass continues at; -1 once abandoned or finished
+}
+
+// checksumEligible reports whether this handle's reads can be checked against
+// meta.ContentMD5: only when one is stored and the handle serves the bytes it
+// was computed over. The continuous-timeline remux rewrites timestamps, and
+// zero-filled holes stand in for bytes that never arrived.
+func (mvf *MetadataVirtualFile) checksumEligible() bool {
+	return len(mvf.meta.ContentMD5) == md5.Size &&
+		!mvf.meta.SizeUnknown &&
+		len(mvf.meta.KnownHoles) == 0 &&
+		!mvf.remuxActive()
+}
+
+// feedChecksum passes the bytes just read at off to the checksum pass and,
+// once they complete a full sequential read, records the verdict in health.
+// A match counts as a passed check. A mismatch only schedules a prompt health
+// check: one bad pass can come from a provider serving a damaged copy of an
+// article, so it is not enough on its own to start an irreversible repair.
+// Callers must hold mvf.mu.
+func (mvf *MetadataVirtualFile) feedChecksum(p []byte, off int64) {
+	if len(p) == 0 {
+		return
+	}
+	c := mvf.checksum
+	if c == nil {
+		if off != 0 || !mvf.checksumEligible() {
+			return
+		}
+		c = &contentChecksum{hash: md5.New()}
+		mvf.checksum = c
+	}
+	if off == 0 {
+		c.hash.Reset()
+		c.next = 0
+	}
+	if off != c.next {
+		c.next = -1
+		return
+	}
+
+	c.hash.Write(p)
+	c.next += int64(len(p))
+	if c.next < mvf.meta.FileSize {
+		return
+	}
+	c.next = -1
+
+	if mvf.paddedHoles() {
+		slog.DebugContext(mvf.ctx, "Skipping checksum verification: missing segments were zero-filled",
+			"file", mvf.name)
+		return
+	}
+	if sum := c.hash.Sum(nil); !bytes.Equal(sum, mvf.meta.ContentMD5) {
+		slog.WarnContext(mvf.ctx, "Content checksum mismatch after full read",
+			"file", mvf.name,
+			"got", fmt.Sprintf("%x", sum),
+			"want", fmt.Sprintf("%x", mvf.meta.ContentMD5))
+		mvf.scheduleChecksumRecheck()
+		return
+	}
+	mvf.markChecksumVerified()
+}
+
+// paddedHoles reports whether this handle zero-filled any missing segment.
+func (mvf *MetadataVirtualFile) paddedHoles() bool {
+	mvf.holeMu.Lock()
+	defer mvf.holeMu.Unlock()
+	return mvf.holeAcc != nil && mvf.holeAcc.Total() > 0
+}
+
+// scheduleChecksumRecheck moves the file to the front of the health check
+// queue after a full read did not match its stored checksum. Only pending and
+// healthy records, or a missing one, are touched: a file already flagged for
+// repair is left to the health worker.
+func (mvf *MetadataVirtualFile) scheduleChecksumRecheck() {
+	if mvf.healthRepository == nil || mvf.configGetter == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(mvf.ctx, 5*time.Second)
+	defer cancel()
+
+	fh, err := mvf.healthRepository.GetFileHealth(ctx, mvf.name)
+	if err != nil {
+		slog.WarnContext(ctx, "Failed to read health record for checksum recheck", "file", mvf.name, "error", err)
+		return
+	}
+	if fh != nil && fh.Status != database.HealthStatusPending && fh.Status != database.HealthStatusHealthy {
+		return
+	}
+
+	var sourceNzbPath *string
+	if mvf.meta.SourceNzbPath != "" {
+		sourceNzbPath = &mvf.meta.SourceNzbPath
+	}
+	cfg := mvf.configGetter()
+	if err := mvf.healthRepository.AddFileToHealthCheck(ctx, mvf.name, nil, cfg.GetMaxRetries(), cfg.GetMaxRepairRetries(), sourceNzbPath, database.HealthPriorityNext); err != nil {
+		slog.WarnContext(ctx, "Failed to schedule checksum recheck", "file", mvf.name, "error", err)
+	}
+}
+
+// markChecksumVerified records a full read that matched the stored checksum
+// as a passed health check. Only pending and healthy records are touched: a
+// file already flagged for repair is left to the health worker. The next
+// scheduled check is kept, but never left in the past.
+func (mvf *MetadataVirtualFile) markChecksumVerified() {
+	if mvf.healthRepository == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(mvf.ctx, 5*time.Second)
+	defer cancel()
+
+	fh, err := mvf.healthRepository.GetFileHealth(ctx, mvf.name)
+	if err != nil || fh == nil {
+		slog.DebugContext(ctx, "Content checksum verified; no health record to update",
+			"file", mvf.name, "error", err)
+		return
+	}
+	if fh.Status != database.HealthStatusPending && fh.Status != database.HealthStatusHealthy {
+		return
+	}
+
+	next := time.Now()
+	if fh.ScheduledCheckAt != nil && fh.ScheduledCheckAt.After(next) {
+		next = *fh.ScheduledCheckAt
+	}
+	if err := mvf.healthRepository.MarkAsHealthy(ctx, fh.FilePath, next); err != nil {
+		slog.WarnContext(ctx, "Failed to record verified checksum", "file", mvf.name, "error", err)
+		return
+	}
+	slog.InfoContext(ctx, "Content checksum verified by full read", "file", mvf.name)
+}
@@ -0,0 +1,149 @@
+package nzbfilesystem
+
+import (
+	"context"
+	"crypto/md5"
+	"io"
+	"testing"
+
+	"github.com/javi11/altmount/internal/config"
+	"github.com/javi11/altmount/internal/database"
+	metapb "github.com/javi11/altmount/internal/metadata/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// checksumFixture is four segments of distinct bytes.
+func checksumFixture() []byte {
+	data := make([]byte, 4*thumbTestSegSize)
+	for i := range data {
+		data[i] = byte(i * 7)
+	}
+	return data
+}
+
+// newChecksumMVF serves data from the fake pool as name, with want stored as
+// its checksum and a pending health record to update.
+func newChecksumMVF(t *testing.T, name string, data, want []byte) (*MetadataVirtualFile, *database.HealthRepository) {
+	t.Helper()
+	repo, db, ms := setupStreamHealthEnv(t)
+	writeStreamMeta(t, ms, name)
+	_, err := db.Exec(
+		`INSERT INTO file_health (file_path, status, scheduled_check_at) VALUES (?, 'pending', datetime('now', '-1 hour'))`,
+		name,
+	)
+	require.NoError(t, err)
+
+	enabled := true
+	cfg := config.DefaultConfig()
+	cfg.Health.Enabled = &enabled
+	cfg.MountPath = ""
+
+	mvf := newThumbnailTestMVF(t, data)
+	mvf.name = name
+	mvf.meta.ContentMD5 = want
+	mvf.metadataService = ms
+	mvf.healthRepository = repo
+	mvf.configGetter = func() *config.Config { return cfg }
+	return mvf, repo
+}
+
+func healthStatus(t *testing.T, repo *database.HealthRepository, name string) database.HealthStatus {
+	t.Helper()
+	fh, err := repo.GetFileHealth(context.Background(), name)
+	require.NoError(t, err)
+	require.NotNil(t, fh)
+	return fh.Status
+}
+
+func TestChecksum_FullReadConfirms(t *testing.T) {
+	data := checksumFixture()
+	sum := md5.Sum(data)
+
+	t.Run("read", func(t *testing.T) {
+		mvf, repo := newChecksumMVF(t, "checksum/read.mkv", data, sum[:])
+		got, err := io.ReadAll(mvf)
+		require.NoError(t, err)
+		require.Equal(t, data, got)
+		assert.Equal(t, database.HealthStatusHealthy, healthStatus(t, repo, "checksum/read.mkv"))
+	})
+
+	t.Run("sequential ReadAt", func(t *testing.T) {
+		mvf, repo := newChecksumMVF(t, "checksum/readat.mkv", data, sum[:])
+		buf := make([]byte, 1500)
+		for off := int64(0); off < int64(len(data)); {
+			n, err := mvf.ReadAt(buf, off)
+			require.NoError(t, err)
+			off += int64(n)
+		}
+		assert.Equal(t, database.HealthStatusHealthy, healthStatus(t, repo, "checksum/readat.mkv"))
+	})
+}
+
+func TestChecksum_TamperedSchedulesRecheck(t *testing.T) {
+	data := checksumFixture()
+	tampered := append([]byte{}, data...)
+	tampered[len(tampered)/2] ^= 0xFF
+	sum := md5.Sum(data)
+
+	mvf, repo := newChecksumMVF(t, "checksum/tampered.mkv", tampered, sum[:])
+	_, err := io.ReadAll(mvf)
+	require.NoError(t, err, "the read itself succeeds; the verdict goes to health")
+
+	fh, err := repo.GetFileHealth(context.Background(), "checksum/tampered.mkv")
+	require.NoError(t, err)
+	require.NotNil(t, fh)
+	assert.Equal(t, database.HealthStatusPending, fh.Status)
+	assert.Equal(t, database.HealthPriorityNext, fh.Priority, "the recheck jumps the queue")
+
+	meta, err := mvf.metadataService.ReadFileMetadata("checksum/tampered.mkv")
+	require.NoError(t, err)
+	assert.NotEqual(t, metapb.FileStatus_FILE_STATUS_CORRUPTED, meta.Status, "a mismatch alone never starts a repair")
+}
+
+func TestChecksum_TamperedLeavesRepairAlone(t *testing.T) {
+	data := checksumFixture()
+	tampered := append([]byte{}, data...)
+	tampered[0] ^= 0xFF
+	sum := md5.Sum(data)
+
+	mvf, repo := newChecksumMVF(t, "checksum/repairing.mkv", tampered, sum[:])
+	require.NoError(t, repo.UpdateFileHealth(context.Background(), "checksum/repairing.mkv",
+		database.HealthStatusRepairTriggered, nil, nil, nil, false))
+	_, err := io.ReadAll(mvf)
+	require.NoError(t, err)
+
+	assert.Equal(t, database.HealthStatusRepairTriggered, healthStatus(t, repo, "checksum/repairing.mkv"))
+}
+
+func TestChecksum_PartialReadsDoNotVerify(t *testing.T) {
+	data := checksumFixture()
+	wrong := md5.Sum([]byte("something else"))
+
+	t.Run("out of order", func(t *testing.T) {
+		mvf, repo := newChecksumMVF(t, "checksum/scrub.mkv", data, wrong[:])
+		buf := make([]byte, thumbTestSegSize)
+		for _, seg := range []int64{0, 2, 1, 3} {
+			_, err := mvf.ReadAt(buf, seg*thumbTestSegSize)
+			require.NoError(t, err)
+		}
+		assert.Equal(t, database.HealthStatusPending, healthStatus(t, repo, "checksum/scrub.mkv"))
+	})
+
+	t.Run("not from the start", func(t *testing.T) {
+		mvf, repo := newChecksumMVF(t, "checksum/tail.mkv", data, wrong[:])
+		_, err := mvf.Seek(thumbTestSegSize, io.SeekStart)
+		require.NoError(t, err)
+		_, err = io.ReadAll(mvf)
+		require.NoError(t, err)
+		assert.Equal(t, database.HealthStatusPending, healthStatus(t, repo, "checksum/tail.mkv"))
+	})
+
+	t.Run("no stored checksum", func(t *testing.T) {
+		mvf, repo := newChecksumMVF(t, "checksum/none.mkv", data, nil)
+		_, err := io.ReadAll(mvf)
+		require.NoError(t, err)
+		assert.Equal(t, database.HealthStatusPending, healthStatus(t, repo, "checksum/none.mkv"))
+		assert.Nil(t, mvf.checksum)
+	})
+}
@@ -0,0 +1,107 @@
+package nzbfilesystem
+
+import (
+	"log/slog"
+	"mime"
+	"path/filepath"
+	"time"
+
+	"github.com/hashicorp/golang-lru/v2/expirable"
+	"github.com/javi11/altmount/internal/config"
+)
+
+// contentTypeSniffSize is how much of a file SniffContentTypes reads, the
+// most net/http's sniffer looks at.
+const contentTypeSniffSize = 512
+
+// contentTypeFor returns the Content-Type a file named name is served with:
+// its Streaming.ContentTypeOverrides entry, else the type registered for its
+// extension, else "" when neither knows it.
+func contentTypeFor(cfg *config.Config, name string) string {
+	if cfg != nil {
+		if ctype := cfg.Streaming.ContentTypeOverride(name); ctype != "" {
+			return ctype
+		}
+	}
+	return mime.TypeByExtension(filepath.Ext(name))
+}
+
+// ContentType returns the Content-Type the file is served with, or "" when
+// it is unknown and callers should fall back to their own default.
+func (mfi *MetadataFileInfo) ContentType() string { return mfi.contentType }
+
+// contentType returns the handle's Content-Type: the name's override or
+// extension type, else the type sniffed when the handle was opened.
+func (mvf *MetadataVirtualFile) contentType() string {
+	var cfg *config.Config
+	if mvf.configGetter != nil {
+		cfg = mvf.configGetter()
+	}
+	if ctype := contentTypeFor(cfg, mvf.name); ctype != "" {
+		return ctype
+	}
+	return mvf.sniffedContentType
+}
+
+// contentType returns the Content-Type Stat reports for the file at name:
+// the name's override or extension type, else the type sniffed when the file
+// was last opened, so listings and opened handles agree.
+func (mrf *MetadataRemoteFile) contentType(name string) string {
+	cfg := mrf.configGetter()
+	if ctype := contentTypeFor(cfg, name); ctype != "" {
+		return ctype
+	}
+	if mrf.sniffedTypes == nil || !cfg.Streaming.SniffContentTypes {
+		return ""
+	}
+	ctype, _ := mrf.sniffedTypes.Get(name)
+	return ctype
+}
+
+// sniffContentType fills in vf's sniffedContentType when its name says
+// nothing and Streaming.SniffContentTypes is set. It runs while the handle is
+// being opened, before any other goroutine can see it, and reuses the result
+// of an earlier open of the same path. A failed sniff is cached as "" so a
+// file whose first segment is missing is not read again on every open.
+func (mrf *MetadataRemoteFile) sniffContentType(vf *MetadataVirtualFile, name string) {
+	cfg := mrf.configGetter()
+	if !cfg.Streaming.SniffContentTypes || contentTypeFor(cfg, name) != "" || vf.meta.FileSize <= 0 {
+		return
+	}
+	if mrf.sniffedTypes != nil {
+		if ctype, ok := mrf.sniffedTypes.Get(name); ok {
+			vf.sniffedContentType = ctype
+			return
+		}
+	}
+
+	head, err := vf.readRange(0, min(vf.meta.FileSize, contentTypeSniffSize))
+	if err != nil {
+		slog.DebugContext(vf.ctx, "Failed to sniff content type", "file", name, "error", err)
+		if vf.ctx.Err() != nil {
+			// The open was abandoned; that says nothing about the file.
+			return
+		}
+	} else {
+		vf.sniffedContentType = SniffContentType(head)
+	}
+	if mrf.sniffedTypes != nil {
+		mrf.sniffedTypes.Add(name, vf.sniffedContentType)
+	}
+}
+
+const (
+	// sniffedTypeTTL is how long a sniffed Content-Type, or a failed sniff,
+	// is reused before the file is read again.
+	sniffedTypeTTL = 30 * time.Minute
+	// sniffedTypeCacheSize bounds how many paths are remembered.
+	sniffedTypeCacheSize = 4096
+)
+
+// sniffedTypeCache maps virtual paths to the type sniffed from their first
+// bytes; "" records a sniff that failed.
+type sniffedTypeCache = expirable.LRU[string, string]
+
+func newSniffedTypeCache() *sniffedTypeCache {
+	return expirable.NewLRU[string, string](sniffedTypeCacheSize, nil, sniffedTypeTTL)
+}
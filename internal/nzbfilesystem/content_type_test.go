@@ -0,0 +1,108 @@
+package nzbfilesystem
+
+import (
+	"context"
+	"testing"
+
+	"github.com/javi11/altmount/internal/config"
+	"github.com/javi11/altmount/internal/testsupport/fakepool"
+	"github.com/javi11/altmount/internal/testsupport/segments"
+	"github.com/javi11/nntppool/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// statContentType returns the content type Stat reports for path.
+func statContentType(t *testing.T, mrf *MetadataRemoteFile, path string) string {
+	t.Helper()
+	ok, info, err := mrf.Stat(context.Background(), path)
+	require.NoError(t, err)
+	require.True(t, ok)
+	return info.(*MetadataFileInfo).ContentType()
+}
+
+func TestStatContentType_Override(t *testing.T) {
+	repo, _, ms := setupStreamHealthEnv(t)
+	for _, name := range []string{"movie.m2ts", "poster.jpg", "movie.strm"} {
+		writeStreamMeta(t, ms, "complete/Movie/"+name)
+	}
+	cfg := config.DefaultConfig()
+	cfg.Streaming.ContentTypeOverrides = map[string]string{"m2ts": "video/mp2t", "STRM": "text/plain"}
+	mrf := &MetadataRemoteFile{
+		metadataService:  ms,
+		healthRepository: repo,
+		configGetter:     func() *config.Config { return cfg },
+	}
+
+	assert.Equal(t, "video/mp2t", statContentType(t, mrf, "/complete/Movie/movie.m2ts"))
+	assert.Equal(t, "text/plain", statContentType(t, mrf, "/complete/Movie/movie.strm"))
+	assert.Equal(t, "image/jpeg", statContentType(t, mrf, "/complete/Movie/poster.jpg"),
+		"extensions without an override keep their registered type")
+}
+
+func TestSniffContentType_AtOpen(t *testing.T) {
+	const name = "complete/Movie/movie.bin1"
+	// An EBML header, as at the start of a Matroska file.
+	data := filler(thumbTestSegSize, 0)
+	copy(data, []byte{0x1A, 0x45, 0xDF, 0xA3, 0x01, 0x00, 0x00, 0x00})
+
+	cfg := config.DefaultConfig()
+	mrf := &MetadataRemoteFile{
+		configGetter: func() *config.Config { return cfg },
+		sniffedTypes: newSniffedTypeCache(),
+	}
+	open := func(data []byte) *MetadataVirtualFile {
+		mvf := newThumbnailTestMVF(t, data)
+		mvf.name = "/" + name
+		mvf.configGetter = mrf.configGetter
+		mrf.sniffContentType(mvf, name)
+		return mvf
+	}
+	statType := func(mvf *MetadataVirtualFile) string {
+		info, err := mvf.Stat()
+		require.NoError(t, err)
+		return info.(*MetadataFileInfo).ContentType()
+	}
+
+	assert.Empty(t, statType(open(data)), "sniffing is off by default")
+	assert.Zero(t, mrf.sniffedTypes.Len())
+
+	cfg.Streaming.SniffContentTypes = true
+	assert.Equal(t, "video/x-matroska", statType(open(data)))
+	assert.Equal(t, "video/x-matroska", mrf.contentType(name), "listings report the type the handle was served with")
+
+	// Later opens reuse the result instead of reading the file again.
+	png := filler(thumbTestSegSize, 0)
+	copy(png, []byte("\x89PNG\r\n\x1a\n"))
+	assert.Equal(t, "video/x-matroska", statType(open(png)))
+
+	// A known extension is never sniffed.
+	mvf := open(data)
+	mvf.name = "/complete/Movie/poster.png"
+	assert.Equal(t, "image/png", statType(mvf))
+}
+
+func TestSniffContentType_CachesFailures(t *testing.T) {
+	const name = "complete/Movie/movie.bin1"
+	cfg := config.DefaultConfig()
+	cfg.Streaming.SniffContentTypes = true
+	mrf := &MetadataRemoteFile{
+		configGetter: func() *config.Config { return cfg },
+		sniffedTypes: newSniffedTypeCache(),
+	}
+
+	fp := fakepool.New()
+	fp.SetBehavior(segments.MessageID(0), fakepool.SegmentBehavior{Err: nntppool.ErrArticleNotFound})
+	mvf := newTestMVF(t, context.Background(), fp, 1, thumbTestSegSize, 1)
+	mvf.configGetter = mrf.configGetter
+	mrf.sniffContentType(mvf, name)
+
+	assert.Empty(t, mvf.sniffedContentType)
+	ctype, cached := mrf.sniffedTypes.Get(name)
+	assert.True(t, cached, "a failed sniff is remembered")
+	assert.Empty(t, ctype)
+
+	calls := fp.TotalCalls()
+	mrf.sniffContentType(mvf, name)
+	assert.Equal(t, calls, fp.TotalCalls(), "the file is not read again")
+}
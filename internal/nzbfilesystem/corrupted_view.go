@@ -0,0 +1,239 @@
+package nzbfilesystem
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/javi11/altmount/internal/utils"
+	"github.com/spf13/afero"
+)
+
+// corruptedViewDir is the read-only folder each category directory gains when
+// Metadata.CorruptedCategoryViews is enabled. Its tree mirrors the category's
+// files that health has flagged as bad; nothing is stored for it on disk.
+const corruptedViewDir = ".corrupted"
+
+// corruptedMetadataDir is where corrupted metadata is moved for safety.
+const corruptedMetadataDir = "corrupted_metadata"
+
+// corruptedView addresses a path inside a category's .corrupted folder.
+type corruptedView struct {
+	category string // category directory, mount-relative without a leading slash
+	rel      string // path below .corrupted; "" for the view root
+}
+
+// corruptedViewFile is a flagged file visible in a view.
+type corruptedViewFile struct {
+	rel    string // path below the category directory
+	source string // metadata path currently holding the file
+}
+
+// parseCorruptedView reports whether normalizedName lies inside a category's
+// .corrupted view and splits it into the category and the path below the view.
+func (mrf *MetadataRemoteFile) parseCorruptedView(normalizedName string) (corruptedView, bool) {
+	if !mrf.corruptedViewsEnabled() {
+		return corruptedView{}, false
+	}
+
+	parts := strings.Split(strings.Trim(normalizedName, "/"), "/")
+	for i, part := range parts {
+		if part != corruptedViewDir {
+			continue
+		}
+		category := strings.Join(parts[:i], "/")
+		if !mrf.isCorruptedViewCategory(category) {
+			return corruptedView{}, false
+		}
+		return corruptedView{category: category, rel: strings.Join(parts[i+1:], "/")}, true
+	}
+	return corruptedView{}, false
+}
+
+func (mrf *MetadataRemoteFile) corruptedViewsEnabled() bool {
+	return mrf.healthRepository != nil && mrf.configGetter != nil &&
+		mrf.configGetter().Metadata.ShowCorruptedCategoryViews()
+}
+
+// isCorruptedViewCategory reports whether dir is a category directory that
+// carries a view. complete_dir itself does not; without configured categories
+// every directory directly under it is treated as one.
+func (mrf *MetadataRemoteFile) isCorruptedViewCategory(dir string) bool {
+	dir = strings.Trim(dir, "/")
+	if dir == "" {
+		return false
+	}
+	cfg := mrf.configGetter()
+	completeDir := strings.Trim(normalizePath(cfg.SABnzbd.CompleteDir), "/")
+	if strings.EqualFold(dir, completeDir) {
+		return false
+	}
+	if len(cfg.SABnzbd.Categories) == 0 {
+		parent := path.Dir(dir)
+		if parent == "." {
+			parent = ""
+		}
+		return strings.EqualFold(parent, completeDir)
+	}
+	return mrf.isCategoryFolder(dir)
+}
+
+// corruptedViewFiles returns the flagged files under a category whose
+// metadata still exists, either in place or in the corrupted_metadata folder.
+func (mrf *MetadataRemoteFile) corruptedViewFiles(ctx context.Context, category string) ([]corruptedViewFile, error) {
+	paths, err := mrf.healthRepository.GetCorruptedPathsByPrefix(ctx, category)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]corruptedViewFile, 0, len(paths))
+	for _, p := range paths {
+		source := p
+		if !mrf.metadataService.FileExists(source) {
+			source = path.Join(corruptedMetadataDir, p)
+			if !mrf.metadataService.FileExists(source) {
+				continue
+			}
+		}
+		files = append(files, corruptedViewFile{rel: strings.TrimPrefix(p, category+"/"), source: source})
+	}
+	return files, nil
+}
+
+// resolveCorruptedView looks up a view path. It returns the backing file when
+// the path names one, or isDir when it names a directory of the view; ok is
+// false when nothing flagged lives at the path.
+func (mrf *MetadataRemoteFile) resolveCorruptedView(ctx context.Context, view corruptedView) (file corruptedViewFile, isDir, ok bool, err error) {
+	files, err := mrf.corruptedViewFiles(ctx, view.category)
+	if err != nil {
+		return corruptedViewFile{}, false, false, err
+	}
+	for _, f := range files {
+		if f.rel == view.rel {
+			return f, false, true, nil
+		}
+		if view.rel == "" || strings.HasPrefix(f.rel, view.rel+"/") {
+			isDir = true
+		}
+	}
+	return corruptedViewFile{}, isDir, isDir, nil
+}
+
+// listCorruptedView lists one directory level of a view: subdirectories
+// first, then files, each read-only.
+func (mrf *MetadataRemoteFile) listCorruptedView(ctx context.Context, view corruptedView) ([]fs.FileInfo, error) {
+	files, err := mrf.corruptedViewFiles(ctx, view.category)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := ""
+	if view.rel != "" {
+		prefix = view.rel + "/"
+	}
+
+	var dirs, entries []fs.FileInfo
+	seenDirs := make(map[string]bool)
+	for _, f := range files {
+		rest, found := strings.CutPrefix(f.rel, prefix)
+		if !found {
+			continue
+		}
+		if dir, _, nested := strings.Cut(rest, "/"); nested {
+			if !seenDirs[dir] {
+				seenDirs[dir] = true
+				dirs = append(dirs, corruptedViewDirInfo(dir))
+			}
+			continue
+		}
+
+		meta, err := mrf.metadataService.ReadFileMetadataLite(f.source)
+		if err != nil || meta == nil {
+			continue
+		}
+		entries = append(entries, &MetadataFileInfo{
+			name:    rest,
+			size:    meta.FileSize,
+			mode:    0444,
+			modTime: time.Unix(meta.ModifiedAt, 0),
+		})
+	}
+	return append(dirs, entries...), nil
+}
+
+// categoryViewEntry returns the .corrupted entry to show in a category
+// directory listing, or nil when views are off or the category has no
+// flagged files.
+func (mrf *MetadataRemoteFile) categoryViewEntry(ctx context.Context, normalizedDir string) fs.FileInfo {
+	if !mrf.corruptedViewsEnabled() || !mrf.isCorruptedViewCategory(normalizedDir) {
+		return nil
+	}
+	files, err := mrf.corruptedViewFiles(ctx, strings.Trim(normalizedDir, "/"))
+	if err != nil || len(files) == 0 {
+		return nil
+	}
+	return corruptedViewDirInfo(corruptedViewDir)
+}
+
+// openCorruptedView opens a directory or file inside a view. Files are opened
+// from their backing metadata with corrupted files shown.
+func (mrf *MetadataRemoteFile) openCorruptedView(ctx context.Context, name, normalizedName string, view corruptedView) (bool, afero.File, error) {
+	file, isDir, ok, err := mrf.resolveCorruptedView(ctx, view)
+	if err != nil {
+		return false, nil, err
+	}
+	if !ok {
+		return false, nil, nil
+	}
+	if isDir {
+		return true, &MetadataVirtualDirectory{
+			name:             name,
+			normalizedPath:   normalizedName,
+			metadataService:  mrf.metadataService,
+			healthRepository: mrf.healthRepository,
+			configGetter:     mrf.configGetter,
+			showCorrupted:    true,
+			listView: func() ([]fs.FileInfo, error) {
+				return mrf.listCorruptedView(ctx, view)
+			},
+		}, nil
+	}
+	return mrf.OpenFile(context.WithValue(ctx, utils.ShowCorrupted, true), file.source)
+}
+
+// statCorruptedView stats a directory or file inside a view.
+func (mrf *MetadataRemoteFile) statCorruptedView(ctx context.Context, normalizedName string, view corruptedView) (bool, fs.FileInfo, error) {
+	file, isDir, ok, err := mrf.resolveCorruptedView(ctx, view)
+	if err != nil {
+		return false, nil, err
+	}
+	if !ok {
+		return false, nil, fs.ErrNotExist
+	}
+	if isDir {
+		return true, corruptedViewDirInfo(path.Base(normalizedName)), nil
+	}
+
+	found, info, err := mrf.Stat(context.WithValue(ctx, utils.ShowCorrupted, true), file.source)
+	if err != nil || !found {
+		return found, info, err
+	}
+	if mfi, isMeta := info.(*MetadataFileInfo); isMeta {
+		readOnly := *mfi
+		readOnly.mode = 0444
+		return true, &readOnly, nil
+	}
+	return true, info, nil
+}
+
+func corruptedViewDirInfo(name string) fs.FileInfo {
+	return &MetadataFileInfo{
+		name:    name,
+		mode:    os.ModeDir | 0555,
+		modTime: time.Now(),
+		isDir:   true,
+	}
+}
@@ -0,0 +1,119 @@
+package nzbfilesystem
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"testing"
+
+	"github.com/javi11/altmount/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newCorruptedViewRemoteFile lays out a tv and a movies category with a mix of
+// healthy and flagged files.
+func newCorruptedViewRemoteFile(t *testing.T, enabled bool) *MetadataRemoteFile {
+	t.Helper()
+	repo, db, ms := setupStreamHealthEnv(t)
+	ctx := context.Background()
+
+	files := map[string]string{
+		"complete/tv/Show/Show.S01E01.mkv":     "corrupted",
+		"complete/tv/Show/Show.S01E02.mkv":     "healthy",
+		"complete/tv/Show/Show.S01E03.mkv":     "repair_triggered",
+		"complete/tv/Other.S02E01.mkv":         "healthy",
+		"complete/movies/Movie/Movie.2024.mkv": "corrupted",
+	}
+	for p, status := range files {
+		writeStreamMeta(t, ms, p)
+		_, err := db.Exec(`INSERT INTO file_health (file_path, status) VALUES (?, ?)`, p, status)
+		require.NoError(t, err)
+	}
+	// A masked file counts too, whatever its status.
+	writeStreamMeta(t, ms, "complete/tv/Show/Show.S01E04.mkv")
+	_, err := db.Exec(`INSERT INTO file_health (file_path, status, is_masked) VALUES (?, 'healthy', TRUE)`, "complete/tv/Show/Show.S01E04.mkv")
+	require.NoError(t, err)
+	// Repair moved this one's metadata into corrupted_metadata.
+	require.NoError(t, ms.MoveToCorrupted(ctx, "complete/tv/Show/Show.S01E03.mkv"))
+
+	cfg := config.DefaultConfig()
+	cfg.SABnzbd.CompleteDir = "/complete"
+	cfg.SABnzbd.Categories = []config.SABnzbdCategory{{Name: "tv"}, {Name: "movies"}}
+	cfg.Metadata.CorruptedCategoryViews = &enabled
+	return &MetadataRemoteFile{
+		metadataService:  ms,
+		healthRepository: repo,
+		configGetter:     func() *config.Config { return cfg },
+	}
+}
+
+func listNames(t *testing.T, mrf *MetadataRemoteFile, dir string) []string {
+	t.Helper()
+	ok, f, err := mrf.OpenFile(context.Background(), dir)
+	require.NoError(t, err)
+	require.True(t, ok, "%s should exist", dir)
+	names, err := f.Readdirnames(0)
+	require.NoError(t, err)
+	return names
+}
+
+func TestCorruptedView_ListsCategoryCorruptedFiles(t *testing.T) {
+	mrf := newCorruptedViewRemoteFile(t, true)
+
+	assert.Contains(t, listNames(t, mrf, "/complete/tv"), corruptedViewDir)
+	assert.Equal(t, []string{"Show"}, listNames(t, mrf, "/complete/tv/.corrupted"))
+	assert.ElementsMatch(t,
+		[]string{"Show.S01E01.mkv", "Show.S01E03.mkv", "Show.S01E04.mkv"},
+		listNames(t, mrf, "/complete/tv/.corrupted/Show"),
+		"corrupted, repair-triggered and masked files appear; healthy ones do not")
+
+	assert.Equal(t, []string{"Movie"}, listNames(t, mrf, "/complete/movies/.corrupted"),
+		"each category only shows its own files")
+}
+
+func TestCorruptedView_Stat(t *testing.T) {
+	mrf := newCorruptedViewRemoteFile(t, true)
+	ctx := context.Background()
+
+	ok, info, err := mrf.Stat(ctx, "/complete/tv/.corrupted/Show/Show.S01E03.mkv")
+	require.NoError(t, err)
+	require.True(t, ok, "metadata moved to corrupted_metadata is still reachable")
+	assert.Equal(t, "Show.S01E03.mkv", info.Name())
+	assert.Equal(t, int64(1024), info.Size())
+	assert.Equal(t, os.FileMode(0444), info.Mode())
+
+	ok, info, err = mrf.Stat(ctx, "/complete/tv/.corrupted/Show/Show.S01E04.mkv")
+	require.NoError(t, err)
+	require.True(t, ok, "masked files are visible inside the view")
+	assert.Equal(t, int64(1024), info.Size())
+
+	ok, info, err = mrf.Stat(ctx, "/complete/tv/.corrupted")
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.True(t, info.IsDir())
+
+	_, _, err = mrf.Stat(ctx, "/complete/tv/.corrupted/Show/Show.S01E02.mkv")
+	assert.ErrorIs(t, err, fs.ErrNotExist, "healthy files are not mirrored")
+}
+
+func TestCorruptedView_ReadOnly(t *testing.T) {
+	mrf := newCorruptedViewRemoteFile(t, true)
+	ctx := context.Background()
+
+	_, err := mrf.RemoveFile(ctx, "/complete/tv/.corrupted/Show/Show.S01E01.mkv")
+	assert.ErrorIs(t, err, os.ErrPermission)
+	_, err = mrf.RenameFile(ctx, "/complete/tv/.corrupted/Show/Show.S01E01.mkv", "/complete/tv/Show.S01E01.mkv")
+	assert.ErrorIs(t, err, os.ErrPermission)
+	assert.ErrorIs(t, mrf.Mkdir(ctx, "/complete/tv/.corrupted/New", 0755), os.ErrPermission)
+
+	assert.True(t, mrf.metadataService.FileExists("complete/tv/Show/Show.S01E01.mkv"), "the original is untouched")
+}
+
+func TestCorruptedView_Disabled(t *testing.T) {
+	mrf := newCorruptedViewRemoteFile(t, false)
+
+	assert.NotContains(t, listNames(t, mrf, "/complete/tv"), corruptedViewDir)
+	_, _, err := mrf.Stat(context.Background(), "/complete/tv/.corrupted/Show/Show.S01E01.mkv")
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+}
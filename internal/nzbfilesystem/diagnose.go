@@ -0,0 +1,128 @@
+package nzbfilesystem
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/javi11/altmount/internal/usenet"
+	"github.com/javi11/altmount/internal/utils"
+)
+
+// ReadDiagnosis is the outcome of DiagnoseRead.
+type ReadDiagnosis struct {
+	Path       string             `json:"path"`
+	Start      int64              `json:"start"`
+	End        int64              `json:"end"`
+	FileSize   int64              `json:"file_size"`
+	BytesRead  int64              `json:"bytes_read"`
+	DurationMs float64            `json:"duration_ms"`
+	Segments   []SegmentDiagnosis `json:"segments"`
+	// Error is why the read stopped before End, if it did.
+	Error string `json:"error,omitempty"`
+}
+
+// SegmentDiagnosis reports how one segment of a diagnosed read was obtained.
+type SegmentDiagnosis struct {
+	Index      int     `json:"index"`
+	MessageID  string  `json:"message_id"`
+	Bytes      int     `json:"bytes"`
+	DurationMs float64 `json:"duration_ms"`
+	Attempts   int     `json:"attempts"`
+	// Provider is inferred from the pool's counters; see usenet.SegmentTrace.
+	Provider string `json:"provider,omitempty"`
+	CacheHit bool   `json:"cache_hit"`
+	Padded   bool   `json:"padded,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+// MaxDiagnoseReadBytes caps how much of a file one DiagnoseRead reads, so a
+// diagnostic request cannot turn into a full download of a large file.
+const MaxDiagnoseReadBytes = 64 << 20
+
+// DiagnoseRead reads bytes start through end of the file at name through the
+// normal reader and reports how long each segment took, which provider served
+// it, whether it came from the segment cache and what failed. end < 0 reads
+// to the end of the file; either way at most MaxDiagnoseReadBytes are read
+// and End reports where the read actually stopped. Segments are fetched one at a time so timings and
+// provider attribution are per segment, and reported in fetch order; the read
+// is not tracked as a stream.
+// A read that fails part-way is not an error: the diagnosis records it.
+func (nfs *NzbFilesystem) DiagnoseRead(ctx context.Context, name string, start, end int64) (*ReadDiagnosis, error) {
+	ctx = context.WithValue(ctx, utils.SuppressStreamTrackingKey, true)
+	ctx = context.WithValue(ctx, utils.MaxPrefetchKey, 1)
+
+	f, err := nfs.Open(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	mvf, ok := f.(*MetadataVirtualFile)
+	if !ok {
+		return nil, ErrCannotReadDirectory
+	}
+	return mvf.diagnoseRead(name, start, end)
+}
+
+// diagnoseRead is DiagnoseRead on an open handle.
+func (mvf *MetadataVirtualFile) diagnoseRead(name string, start, end int64) (*ReadDiagnosis, error) {
+	mvf.mu.Lock()
+	defer mvf.mu.Unlock()
+
+	if mvf.meta == nil {
+		return nil, ErrFileClosed
+	}
+	size := mvf.meta.FileSize
+	if end < 0 || end >= size {
+		end = size - 1
+	}
+	if start < 0 || start > end {
+		return nil, fmt.Errorf("%w: %d-%d of a %d byte file", ErrInvalidRange, start, end, size)
+	}
+	end = min(end, start+MaxDiagnoseReadBytes-1)
+
+	diag := &ReadDiagnosis{Path: name, Start: start, End: end, FileSize: size}
+	var tracesMu sync.Mutex
+	mvf.segmentTrace = func(tr usenet.SegmentTrace) {
+		sd := SegmentDiagnosis{
+			Index:      tr.Index,
+			MessageID:  tr.MessageID,
+			Bytes:      tr.Bytes,
+			DurationMs: durationMs(tr.Duration),
+			Attempts:   tr.Attempts,
+			Provider:   tr.Provider,
+			CacheHit:   tr.CacheHit,
+			Padded:     tr.Padded,
+		}
+		if tr.Err != nil {
+			sd.Error = tr.Err.Error()
+		}
+		tracesMu.Lock()
+		diag.Segments = append(diag.Segments, sd)
+		tracesMu.Unlock()
+	}
+	defer func() { mvf.segmentTrace = nil }()
+
+	began := time.Now()
+	r, err := mvf.createReaderAtOffset(start, end)
+	if err == nil {
+		diag.BytesRead, err = io.Copy(io.Discard, r)
+		r.Close()
+	}
+	diag.DurationMs = durationMs(time.Since(began))
+	if err != nil {
+		diag.Error = err.Error()
+	}
+
+	tracesMu.Lock()
+	defer tracesMu.Unlock()
+	return diag, nil
+}
+
+// durationMs converts d to fractional milliseconds.
+func durationMs(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
@@ -0,0 +1,89 @@
+package nzbfilesystem
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/javi11/altmount/internal/testsupport/fakepool"
+	"github.com/javi11/altmount/internal/testsupport/segments"
+	"github.com/javi11/nntppool/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const diagSegSize = 1024
+
+func newDiagnoseTestMVF(t *testing.T, fp *fakepool.Client, n int) *MetadataVirtualFile {
+	t.Helper()
+	return newTestMVF(t, context.Background(), fp, n, diagSegSize, 1)
+}
+
+func TestDiagnoseRead_ReportsSegmentTimings(t *testing.T) {
+	fp := fakepool.New()
+	fp.SetDefaultBehavior(fakepool.SegmentBehavior{
+		Bytes:   make([]byte, diagSegSize),
+		Latency: 5 * time.Millisecond,
+	})
+	mvf := newDiagnoseTestMVF(t, fp, 4)
+
+	// Bytes 1000-2100 span segments 0, 1 and 2.
+	diag, err := mvf.diagnoseRead("/movies/a.mkv", 1000, 2100)
+	require.NoError(t, err)
+	assert.Empty(t, diag.Error)
+	assert.Equal(t, int64(1101), diag.BytesRead)
+	assert.Equal(t, int64(4*diagSegSize), diag.FileSize)
+	assert.GreaterOrEqual(t, diag.DurationMs, 15.0)
+
+	require.Len(t, diag.Segments, 3)
+	for i, sd := range diag.Segments {
+		assert.Equal(t, i, sd.Index)
+		assert.Equal(t, segments.MessageID(i), sd.MessageID)
+		assert.Equal(t, 1, sd.Attempts)
+		assert.False(t, sd.CacheHit)
+		assert.GreaterOrEqual(t, sd.DurationMs, 5.0, "segment %d", i)
+		assert.Empty(t, sd.Error)
+	}
+}
+
+func TestDiagnoseRead_SurfacesSegmentFailure(t *testing.T) {
+	fp := fakepool.New()
+	fp.SetDefaultBehavior(fakepool.SegmentBehavior{Bytes: make([]byte, diagSegSize)})
+	fp.SetBehavior(segments.MessageID(2), fakepool.SegmentBehavior{Err: nntppool.ErrArticleNotFound})
+	mvf := newDiagnoseTestMVF(t, fp, 4)
+
+	diag, err := mvf.diagnoseRead("/movies/a.mkv", 0, -1)
+	require.NoError(t, err, "a failed read is reported, not returned")
+	assert.Equal(t, int64(4*diagSegSize-1), diag.End)
+	assert.Equal(t, int64(2*diagSegSize), diag.BytesRead)
+	assert.NotEmpty(t, diag.Error)
+
+	require.Len(t, diag.Segments, 3)
+	failed := diag.Segments[2]
+	assert.Equal(t, segments.MessageID(2), failed.MessageID)
+	assert.Contains(t, failed.Error, nntppool.ErrArticleNotFound.Error())
+	assert.Zero(t, failed.Bytes)
+}
+
+func TestDiagnoseRead_CapsRange(t *testing.T) {
+	fp := fakepool.New()
+	fp.SetDefaultBehavior(fakepool.SegmentBehavior{Err: nntppool.ErrArticleNotFound})
+	mvf := newTestMVF(t, context.Background(), fp, 2, MaxDiagnoseReadBytes, 1)
+
+	diag, err := mvf.diagnoseRead("/movies/a.mkv", 100, -1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(100+MaxDiagnoseReadBytes-1), diag.End, "an open-ended read stops at the cap")
+
+	diag, err = mvf.diagnoseRead("/movies/a.mkv", 0, 2*MaxDiagnoseReadBytes-1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(MaxDiagnoseReadBytes-1), diag.End, "an explicit end is capped too")
+}
+
+func TestDiagnoseRead_InvalidRange(t *testing.T) {
+	mvf := newDiagnoseTestMVF(t, fakepool.New(), 2)
+
+	_, err := mvf.diagnoseRead("/movies/a.mkv", 5000, -1)
+	assert.ErrorIs(t, err, ErrInvalidRange)
+	_, err = mvf.diagnoseRead("/movies/a.mkv", 100, 50)
+	assert.ErrorIs(t, err, ErrInvalidRange)
+}
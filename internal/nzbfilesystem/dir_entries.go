@@ -0,0 +1,97 @@
+package nzbfilesystem
+
+import (
+	"io/fs"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/javi11/altmount/internal/config"
+)
+
+// fileEntry is a file in a names-only listing. Its metadata is only read if
+// Info is called.
+type fileEntry struct {
+	name string
+	dir  *MetadataVirtualDirectory
+}
+
+func (e *fileEntry) Name() string      { return e.name }
+func (e *fileEntry) IsDir() bool       { return false }
+func (e *fileEntry) Type() fs.FileMode { return 0 }
+
+// Info reads the file's lightweight metadata, as Readdir would have.
+func (e *fileEntry) Info() (fs.FileInfo, error) {
+	virtualFilePath := filepath.Join(e.dir.normalizedPath, e.name)
+	fileMeta, err := e.dir.metadataService.ReadFileMetadataLite(virtualFilePath)
+	if err != nil {
+		return nil, err
+	}
+	if fileMeta == nil {
+		return nil, fs.ErrNotExist
+	}
+	return &MetadataFileInfo{
+		name:    e.name,
+		size:    fileMeta.FileSize,
+		mode:    0644,
+		modTime: time.Unix(fileMeta.ModifiedAt, 0),
+	}, nil
+}
+
+func (e *fileEntry) String() string { return fs.FormatDirEntry(e) }
+
+// ReadDir lists the directory by name and type alone, for callers such as
+// recursive walks that don't need sizes or modification times: it reads the
+// metadata directory once and takes every .meta file as a file, without
+// parsing any of them. Entries are ordered like Readdir's, directories
+// first, and count limits them the same way.
+//
+// Because no metadata is read, files Readdir hides for being corrupted,
+// masked or unparseable are included; only the extension filter and hidden
+// entries apply.
+// Info on an entry reads that file's metadata on demand.
+func (mvd *MetadataVirtualDirectory) ReadDir(count int) ([]fs.DirEntry, error) {
+	if mvd.listView != nil {
+		infos, err := mvd.Readdir(count)
+		if err != nil {
+			return nil, err
+		}
+		entries := make([]fs.DirEntry, len(infos))
+		for i, info := range infos {
+			entries[i] = fs.FileInfoToDirEntry(info)
+		}
+		return entries, nil
+	}
+
+	dirs, fileNames, err := mvd.metadataService.ListDirectoryEntries(mvd.normalizedPath)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := mvd.configGetter()
+	dirs = slices.DeleteFunc(dirs, func(d fs.DirEntry) bool {
+		return mvd.hidesEntry(cfg, d.Name())
+	})
+	if mvd.viewEntry != nil {
+		if entry := mvd.viewEntry(); entry != nil {
+			dirs = append(dirs, fs.FileInfoToDirEntry(entry))
+		}
+	}
+
+	entries := dirs
+	for _, fileName := range fileNames {
+		if cfg.Streaming.ExtensionFilter.Hides(fileName) || mvd.hidesEntry(cfg, fileName) {
+			continue
+		}
+		entries = append(entries, &fileEntry{name: fileName, dir: mvd})
+	}
+
+	if sortMode := cfg.Metadata.ListingSort; sortMode != "" && sortMode != config.ListingSortNone {
+		sortEntries(entries[:len(dirs)], sortMode)
+		sortEntries(entries[len(dirs):], sortMode)
+	}
+	if count > 0 && len(entries) > count {
+		entries = entries[:count]
+	}
+	return entries, nil
+}
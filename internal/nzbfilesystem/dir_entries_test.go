@@ -0,0 +1,142 @@
+package nzbfilesystem
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"testing"
+
+	"github.com/javi11/altmount/internal/config"
+	"github.com/javi11/altmount/internal/metadata"
+	metapb "github.com/javi11/altmount/internal/metadata/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeListingTree writes that many healthy files and two subdirectories under
+// dir and returns the metadata root.
+func writeListingTree(tb testing.TB, dir string, files int) string {
+	tb.Helper()
+	root := tb.TempDir()
+	ms := metadata.NewMetadataService(root)
+	for i := range files {
+		meta := ms.CreateFileMetadata(
+			int64(1024*(i+1)), "test.nzb", metapb.FileStatus_FILE_STATUS_HEALTHY,
+			nil, metapb.Encryption_NONE, "", "", nil, nil, 0, nil, "",
+		)
+		require.NoError(tb, ms.WriteFileMetadata(fmt.Sprintf("%s/file.ep%d.mkv", dir, i), meta))
+	}
+	require.NoError(tb, os.MkdirAll(ms.GetMetadataDirectoryPath(dir+"/season 10"), 0755))
+	require.NoError(tb, os.MkdirAll(ms.GetMetadataDirectoryPath(dir+"/season 2"), 0755))
+	return root
+}
+
+// listingDir opens dir with a fresh metadata service, so nothing is served
+// from a warm metadata cache.
+func listingDir(root, dir string, cfg *config.Config) *MetadataVirtualDirectory {
+	return &MetadataVirtualDirectory{
+		name:            dir,
+		normalizedPath:  dir,
+		metadataService: metadata.NewMetadataService(root),
+		configGetter:    func() *config.Config { return cfg },
+	}
+}
+
+func listingConfig() *config.Config {
+	masking := false
+	cfg := &config.Config{}
+	cfg.Streaming.FailureMasking.Enabled = &masking
+	return cfg
+}
+
+func TestReadDir_MatchesReaddir(t *testing.T) {
+	root := writeListingTree(t, "tv", 12)
+	cfg := listingConfig()
+
+	for _, mode := range []config.ListingSort{config.ListingSortNone, config.ListingSortName, config.ListingSortNatural} {
+		for _, count := range []int{0, 3} {
+			cfg.Metadata.ListingSort = mode
+			infos, err := listingDir(root, "tv", cfg).Readdir(count)
+			require.NoError(t, err)
+			entries, err := listingDir(root, "tv", cfg).ReadDir(count)
+			require.NoError(t, err)
+
+			require.Len(t, entries, len(infos), "%s/%d", mode, count)
+			for i, info := range infos {
+				assert.Equal(t, info.Name(), entries[i].Name(), "%s/%d", mode, count)
+				assert.Equal(t, info.IsDir(), entries[i].IsDir(), info.Name())
+				assert.Equal(t, info.Mode().Type(), entries[i].Type(), info.Name())
+			}
+		}
+	}
+}
+
+func TestReadDir_InfoReadsMetadataOnDemand(t *testing.T) {
+	root := writeListingTree(t, "tv", 2)
+	entries, err := listingDir(root, "tv", listingConfig()).ReadDir(0)
+	require.NoError(t, err)
+
+	byName := make(map[string]fs.DirEntry, len(entries))
+	for _, e := range entries {
+		byName[e.Name()] = e
+	}
+	require.Contains(t, byName, "file.ep1.mkv")
+	info, err := byName["file.ep1.mkv"].Info()
+	require.NoError(t, err)
+	assert.Equal(t, int64(2048), info.Size())
+	assert.False(t, info.ModTime().IsZero())
+
+	require.Contains(t, byName, "season 2")
+	info, err = byName["season 2"].Info()
+	require.NoError(t, err)
+	assert.True(t, info.IsDir())
+}
+
+func TestReadDir_SkipsMetadataChecks(t *testing.T) {
+	root := writeListingTree(t, "tv", 1)
+	require.NoError(t, os.Truncate(metadata.NewMetadataService(root).GetMetadataFilePath("tv/file.ep0.mkv"), 2))
+	require.NoError(t, os.WriteFile(metadata.NewMetadataService(root).GetMetadataFilePath("tv/notes.nfo"), nil, 0644))
+	cfg := listingConfig()
+	cfg.Streaming.ExtensionFilter = config.ExtensionFilterConfig{
+		Mode:       config.ExtensionFilterDeny,
+		Extensions: []string{"nfo"},
+	}
+
+	names, err := listingDir(root, "tv", cfg).Readdirnames(0)
+	require.NoError(t, err)
+	assert.NotContains(t, names, "file.ep0.mkv")
+
+	entries, err := listingDir(root, "tv", cfg).ReadDir(0)
+	require.NoError(t, err)
+	var lazy []string
+	for _, e := range entries {
+		lazy = append(lazy, e.Name())
+	}
+	assert.Contains(t, lazy, "file.ep0.mkv", "unparseable metadata is not detected without reading it")
+	assert.NotContains(t, lazy, "notes.nfo", "the extension filter still applies")
+}
+
+func benchmarkListing(b *testing.B, list func(*MetadataVirtualDirectory) (int, error)) {
+	root := writeListingTree(b, "tv", 500)
+	cfg := listingConfig()
+	for b.Loop() {
+		n, err := list(listingDir(root, "tv", cfg))
+		if err != nil || n != 502 {
+			b.Fatalf("listed %d entries: %v", n, err)
+		}
+	}
+}
+
+func BenchmarkListing_Readdir(b *testing.B) {
+	benchmarkListing(b, func(d *MetadataVirtualDirectory) (int, error) {
+		infos, err := d.Readdir(0)
+		return len(infos), err
+	})
+}
+
+func BenchmarkListing_ReadDir(b *testing.B) {
+	benchmarkListing(b, func(d *MetadataVirtualDirectory) (int, error) {
+		entries, err := d.ReadDir(0)
+		return len(entries), err
+	})
+}
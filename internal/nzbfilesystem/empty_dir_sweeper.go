@@ -0,0 +1,98 @@
+package nzbfilesystem
+
+import (
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/javi11/altmount/internal/config"
+	"github.com/javi11/altmount/internal/utils"
+)
+
+// defaultEmptyDirSweepInterval is how often queued directories are checked.
+const defaultEmptyDirSweepInterval = 10 * time.Second
+
+// EmptyDirSweeper defers removing library directories left empty by file
+// deletes (Metadata.EmptyDirGraceSeconds). Directories are queued by
+// Schedule and removed in batches by a background worker once they have gone
+// the grace period without changes, so an import that just created one and
+// is about to write into it keeps it.
+type EmptyDirSweeper struct {
+	configGetter config.ConfigGetter
+	interval     time.Duration
+
+	mu      sync.Mutex
+	pending map[string]string // directory -> root it is cleaned up towards
+
+	stopCh chan struct{}
+	stopWg sync.WaitGroup
+}
+
+// NewEmptyDirSweeper constructs a sweeper and starts its background worker.
+// The worker runs until Close, which MetadataRemoteFile.Close calls during
+// shutdown.
+func NewEmptyDirSweeper(configGetter config.ConfigGetter) *EmptyDirSweeper {
+	s := &EmptyDirSweeper{
+		configGetter: configGetter,
+		interval:     defaultEmptyDirSweepInterval,
+		pending:      make(map[string]string),
+		stopCh:       make(chan struct{}),
+	}
+	s.stopWg.Add(1)
+	go s.run()
+	return s
+}
+
+// Schedule queues dir, and its empty parents up to root, for removal.
+func (s *EmptyDirSweeper) Schedule(root, dir string) {
+	s.mu.Lock()
+	s.pending[dir] = root
+	s.mu.Unlock()
+}
+
+// Sweep removes the queued directories that are still empty and have not
+// changed within the grace period before now. Directories changed more
+// recently stay queued for a later sweep; non-empty ones are dropped.
+func (s *EmptyDirSweeper) Sweep(now time.Time) {
+	s.mu.Lock()
+	batch := s.pending
+	s.pending = make(map[string]string)
+	s.mu.Unlock()
+
+	cutoff := now.Add(-s.configGetter().Metadata.EmptyDirGrace())
+	for dir, root := range batch {
+		if busy := utils.RemoveQuietEmptyDirs(root, dir, cutoff); busy != "" {
+			slog.Debug("Directory changed within grace period, keeping it queued", "path", busy)
+			s.Schedule(root, busy)
+		}
+	}
+}
+
+// Close stops the background worker. Queued directories are left in place.
+func (s *EmptyDirSweeper) Close() {
+	if s == nil {
+		return
+	}
+	select {
+	case <-s.stopCh:
+		return
+	default:
+	}
+	close(s.stopCh)
+	s.stopWg.Wait()
+}
+
+func (s *EmptyDirSweeper) run() {
+	defer s.stopWg.Done()
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.stopCh:
+			return
+		case now := <-ticker.C:
+			s.Sweep(now)
+		}
+	}
+}
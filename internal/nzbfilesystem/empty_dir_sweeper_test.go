@@ -0,0 +1,125 @@
+package nzbfilesystem
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/javi11/altmount/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestSweeper(t *testing.T, grace int) (*EmptyDirSweeper, *config.Config) {
+	t.Helper()
+	cfg := config.DefaultConfig()
+	cfg.Metadata.EmptyDirGraceSeconds = grace
+	s := NewEmptyDirSweeper(func() *config.Config { return cfg })
+	t.Cleanup(s.Close)
+	return s, cfg
+}
+
+func TestEmptyDirSweeper_KeepsDirectoryThatReceivedAFile(t *testing.T) {
+	root := t.TempDir()
+	show := filepath.Join(root, "tv", "Show")
+	require.NoError(t, os.MkdirAll(show, 0755))
+
+	s, _ := newTestSweeper(t, 60)
+	s.Schedule(root, show)
+
+	// An import writes the next episode into the directory inside the window.
+	episode := filepath.Join(show, "Show.S01E02.mkv")
+	require.NoError(t, os.WriteFile(episode, []byte("x"), 0644))
+
+	s.Sweep(time.Now().Add(2 * time.Minute))
+	assert.FileExists(t, episode)
+	assert.Empty(t, s.pending, "a non-empty directory is dropped from the queue")
+}
+
+func TestEmptyDirSweeper_WaitsOutTheGracePeriod(t *testing.T) {
+	root := t.TempDir()
+	show := filepath.Join(root, "tv", "Show")
+	require.NoError(t, os.MkdirAll(show, 0755))
+
+	s, _ := newTestSweeper(t, 60)
+	s.Schedule(root, show)
+
+	// An import created the directory moments ago and has not written yet.
+	s.Sweep(time.Now())
+	assert.DirExists(t, show, "a directory changed within the grace period is kept")
+	assert.Contains(t, s.pending, show, "and stays queued")
+
+	s.Sweep(time.Now().Add(2 * time.Minute))
+	assert.NoDirExists(t, filepath.Join(root, "tv"), "once quiet, it and its empty parents are removed")
+	assert.DirExists(t, root)
+	assert.Empty(t, s.pending)
+}
+
+func TestRemoveFile_DefersEmptyDirCleanupDuringGrace(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		grace     int
+		wantKept  bool
+		wantQueue int
+	}{
+		{"immediate", 0, false, 0},
+		{"grace", 60, true, 1},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			repo, db, ms := setupStreamHealthEnv(t)
+			library := t.TempDir()
+			show := filepath.Join(library, "tv", "Show")
+			require.NoError(t, os.MkdirAll(show, 0755))
+
+			virtualPath := "tv/Show/Show.S01E01.mkv"
+			writeStreamMeta(t, ms, virtualPath)
+			_, err := db.Exec(
+				`INSERT INTO file_health (file_path, library_path, status) VALUES (?, ?, 'healthy')`,
+				virtualPath, filepath.Join(show, "Show.S01E01.mkv"),
+			)
+			require.NoError(t, err)
+
+			s, cfg := newTestSweeper(t, tc.grace)
+			cfg.MountPath = library
+			mrf := &MetadataRemoteFile{
+				metadataService:  ms,
+				healthRepository: repo,
+				configGetter:     func() *config.Config { return cfg },
+				dirSweeper:       s,
+			}
+
+			ok, err := mrf.RemoveFile(context.Background(), "/"+virtualPath)
+			require.NoError(t, err)
+			require.True(t, ok)
+
+			_, statErr := os.Stat(show)
+			assert.Equal(t, tc.wantKept, statErr == nil)
+			assert.Len(t, s.pending, tc.wantQueue)
+		})
+	}
+}
+
+func TestNzbFilesystem_CloseStopsEmptyDirSweeper(t *testing.T) {
+	s, _ := newTestSweeper(t, 60)
+	nfs := NewNzbFilesystem(&MetadataRemoteFile{dirSweeper: s})
+
+	done := make(chan struct{})
+	go func() {
+		nfs.Close()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Close did not return")
+	}
+	assert.NotPanics(t, nfs.Close, "closing twice is safe")
+
+	select {
+	case <-s.stopCh:
+	default:
+		t.Fatal("sweeper worker still running")
+	}
+}
@@ -0,0 +1,68 @@
+package nzbfilesystem
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	metapb "github.com/javi11/altmount/internal/metadata/proto"
+	"github.com/javi11/altmount/internal/testsupport/fakepool"
+	"github.com/javi11/altmount/internal/testsupport/segments"
+	"github.com/javi11/altmount/internal/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetadataVirtualFile_EmptyFileReadsEOF(t *testing.T) {
+	// Zero segments: a zero-byte file imported from an archive.
+	mvf := newTestMVF(t, context.Background(), fakepool.New(), 0, 1024, 4)
+
+	n, err := mvf.Read(make([]byte, 16))
+	assert.Zero(t, n)
+	assert.ErrorIs(t, err, io.EOF)
+
+	n, err = mvf.ReadAt(make([]byte, 16), 0)
+	assert.Zero(t, n)
+	assert.ErrorIs(t, err, io.EOF)
+
+	data, err := io.ReadAll(mvf)
+	require.NoError(t, err)
+	assert.Empty(t, data)
+}
+
+func TestMetadataVirtualFile_ReadPastEndReturnsEOF(t *testing.T) {
+	fp := fakepool.New()
+	configurePoolForFile(fp, 2, 1024, fakepool.SegmentBehavior{})
+	mvf := newTestMVF(t, context.Background(), fp, 2, 1024, 4)
+
+	_, err := mvf.Seek(0, io.SeekEnd)
+	require.NoError(t, err)
+
+	n, err := mvf.Read(make([]byte, 16))
+	assert.Zero(t, n)
+	assert.ErrorIs(t, err, io.EOF)
+}
+
+func TestMetadataVirtualFile_UnknownSizeReadsSegmentsToEOF(t *testing.T) {
+	const n, segSize = 3, 1024
+	fp := fakepool.New()
+	configurePoolForFile(fp, n, segSize, fakepool.SegmentBehavior{})
+	mvf := newTestMVF(t, context.Background(), fp, n, segSize, 4)
+	mvf.meta = newFileHandleMeta(&metapb.FileMetadata{SegmentData: mvf.meta.SegmentData})
+
+	info, err := mvf.Stat()
+	require.NoError(t, err)
+	assert.True(t, utils.SizeUnknown(info))
+	assert.Equal(t, int64(n*segSize), info.Size(), "estimated from the segments")
+
+	data, err := io.ReadAll(mvf)
+	require.NoError(t, err)
+	require.Len(t, data, n*segSize)
+	for i := range n {
+		assert.Equal(t, segments.Payload(i, segSize), data[i*segSize:(i+1)*segSize])
+	}
+
+	// A recorded size is taken as known.
+	known := newFileHandleMeta(&metapb.FileMetadata{FileSize: n * segSize, SegmentData: mvf.meta.SegmentData})
+	assert.False(t, known.SizeUnknown)
+}
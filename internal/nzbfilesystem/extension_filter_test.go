@@ -0,0 +1,91 @@
+package nzbfilesystem
+
+import (
+	"context"
+	"io/fs"
+	"testing"
+
+	"github.com/javi11/altmount/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newExtensionFilterRemoteFile builds a directory holding a video, a sample
+// executable and a subtitle, filtered by the given extension filter.
+func newExtensionFilterRemoteFile(t *testing.T, filter config.ExtensionFilterConfig) *MetadataRemoteFile {
+	t.Helper()
+	repo, _, ms := setupStreamHealthEnv(t)
+	for _, name := range []string{"movie.mkv", "setup.EXE", "movie.srt"} {
+		writeStreamMeta(t, ms, "complete/Movie/"+name)
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.Streaming.ExtensionFilter = filter
+	return &MetadataRemoteFile{
+		metadataService:  ms,
+		healthRepository: repo,
+		configGetter:     func() *config.Config { return cfg },
+	}
+}
+
+func TestExtensionFilter(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		filter  config.ExtensionFilterConfig
+		visible []string
+		hidden  []string
+	}{
+		{
+			name:    "disabled",
+			visible: []string{"movie.mkv", "setup.EXE", "movie.srt"},
+		},
+		{
+			name:    "deny",
+			filter:  config.ExtensionFilterConfig{Mode: config.ExtensionFilterDeny, Extensions: []string{".exe"}},
+			visible: []string{"movie.mkv", "movie.srt"},
+			hidden:  []string{"setup.EXE"},
+		},
+		{
+			name:    "allow",
+			filter:  config.ExtensionFilterConfig{Mode: config.ExtensionFilterAllow, Extensions: []string{"mkv", "SRT"}},
+			visible: []string{"movie.mkv", "movie.srt"},
+			hidden:  []string{"setup.EXE"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			mrf := newExtensionFilterRemoteFile(t, tc.filter)
+			ctx := context.Background()
+
+			assert.ElementsMatch(t, tc.visible, listNames(t, mrf, "/complete/Movie"))
+
+			for _, name := range tc.visible {
+				ok, info, err := mrf.Stat(ctx, "/complete/Movie/"+name)
+				require.NoError(t, err)
+				require.True(t, ok)
+				assert.Equal(t, int64(1024), info.Size())
+
+				ok, f, err := mrf.OpenFile(ctx, "/complete/Movie/"+name)
+				require.NoError(t, err)
+				require.True(t, ok)
+				f.Close()
+			}
+
+			for _, name := range tc.hidden {
+				ok, _, err := mrf.Stat(ctx, "/complete/Movie/"+name)
+				assert.ErrorIs(t, err, fs.ErrNotExist)
+				assert.False(t, ok)
+
+				ok, f, err := mrf.OpenFile(ctx, "/complete/Movie/"+name)
+				assert.NoError(t, err)
+				assert.False(t, ok)
+				assert.Nil(t, f)
+			}
+
+			// Directories are never filtered, even in allow mode.
+			ok, info, err := mrf.Stat(ctx, "/complete/Movie")
+			require.NoError(t, err)
+			require.True(t, ok)
+			assert.True(t, info.IsDir())
+		})
+	}
+}
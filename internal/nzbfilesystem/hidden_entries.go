@@ -0,0 +1,26 @@
+package nzbfilesystem
+
+import (
+	"github.com/javi11/altmount/internal/config"
+)
+
+// managementEntries are the folders altmount keeps at the metadata root for
+// its own bookkeeping rather than as library content.
+var managementEntries = map[string]bool{
+	".ids":               true,
+	".nzbs":              true,
+	corruptedMetadataDir: true,
+}
+
+// hidesEntry reports whether the entry name of this directory is left out of
+// its listings: the root's management folders (.ids, .nzbs and
+// corrupted_metadata), unless Metadata.ShowHidden is set. A listing that
+// asked to show corrupted files keeps corrupted_metadata. Other dot-prefixed
+// entries are library content and are always listed. Hidden entries can
+// still be opened by path.
+func (mvd *MetadataVirtualDirectory) hidesEntry(cfg *config.Config, name string) bool {
+	if cfg.Metadata.ShouldShowHidden() || mvd.normalizedPath != RootPath || !managementEntries[name] {
+		return false
+	}
+	return name != corruptedMetadataDir || !mvd.showCorrupted
+}
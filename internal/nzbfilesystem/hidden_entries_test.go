@@ -0,0 +1,86 @@
+package nzbfilesystem
+
+import (
+	"context"
+	"testing"
+
+	"github.com/javi11/altmount/internal/config"
+	"github.com/javi11/altmount/internal/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newHiddenEntriesRemoteFile builds a tree with a regular file, an .ids/
+// symlink to it, user dot entries and a file in corrupted_metadata.
+func newHiddenEntriesRemoteFile(t *testing.T, showHidden bool) *MetadataRemoteFile {
+	t.Helper()
+	repo, _, ms := setupStreamHealthEnv(t)
+	writeStreamMeta(t, ms, "complete/movie.mkv")
+	writeStreamMeta(t, ms, "complete/.partial.mkv")
+	writeStreamMeta(t, ms, ".extras/featurette.mkv")
+	writeStreamMeta(t, ms, "corrupted_metadata/complete/old.mkv")
+	require.NoError(t, ms.RepairIDSymlink("abcdef123", "complete/movie.mkv"))
+
+	cfg := config.DefaultConfig()
+	cfg.Metadata.ShowHidden = &showHidden
+	return &MetadataRemoteFile{
+		metadataService:  ms,
+		healthRepository: repo,
+		configGetter:     func() *config.Config { return cfg },
+	}
+}
+
+func readDirNames(t *testing.T, mrf *MetadataRemoteFile, dir string) []string {
+	t.Helper()
+	ok, f, err := mrf.OpenFile(context.Background(), dir)
+	require.NoError(t, err)
+	require.True(t, ok)
+	entries, err := f.(*MetadataVirtualDirectory).ReadDir(0)
+	require.NoError(t, err)
+	names := make([]string, len(entries))
+	for i, e := range entries {
+		names[i] = e.Name()
+	}
+	return names
+}
+
+func TestHiddenEntries_HiddenByDefault(t *testing.T) {
+	mrf := newHiddenEntriesRemoteFile(t, false)
+
+	assert.ElementsMatch(t, []string{"complete", ".extras"}, listNames(t, mrf, "/"))
+	assert.ElementsMatch(t, []string{"complete", ".extras"}, readDirNames(t, mrf, "/"))
+
+	// Dot entries that are not management folders are library content.
+	assert.ElementsMatch(t, []string{"movie.mkv", ".partial.mkv"}, listNames(t, mrf, "/complete"))
+	assert.ElementsMatch(t, []string{"movie.mkv", ".partial.mkv"}, readDirNames(t, mrf, "/complete"))
+
+	// Only the root's corrupted_metadata is a management folder.
+	assert.ElementsMatch(t, []string{"complete"}, listNames(t, mrf, "/corrupted_metadata"))
+
+	// Asking for corrupted files brings corrupted_metadata back.
+	ok, f, err := mrf.OpenFile(context.WithValue(context.Background(), utils.ShowCorrupted, true), "/")
+	require.NoError(t, err)
+	require.True(t, ok)
+	names, err := f.Readdirnames(0)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"complete", ".extras", corruptedMetadataDir}, names)
+
+	// Hidden entries are still reachable by path.
+	for _, p := range []string{"/.ids", "/corrupted_metadata/complete/old.mkv"} {
+		ok, f, err := mrf.OpenFile(context.Background(), p)
+		require.NoError(t, err, p)
+		assert.True(t, ok, p)
+		if ok {
+			f.Close()
+		}
+	}
+}
+
+func TestHiddenEntries_ShownWhenEnabled(t *testing.T) {
+	mrf := newHiddenEntriesRemoteFile(t, true)
+
+	assert.ElementsMatch(t, []string{"complete", ".extras", ".ids", corruptedMetadataDir}, listNames(t, mrf, "/"))
+	assert.ElementsMatch(t, []string{"complete", ".extras", ".ids", corruptedMetadataDir}, readDirNames(t, mrf, "/"))
+	assert.ElementsMatch(t, []string{"movie.mkv", ".partial.mkv"}, listNames(t, mrf, "/complete"))
+	assert.ElementsMatch(t, []string{"movie.mkv", ".partial.mkv"}, readDirNames(t, mrf, "/complete"))
+}
@@ -0,0 +1,163 @@
+package nzbfilesystem
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/javi11/altmount/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testNzbdavID = "40e9a6c9-e922-4217-ab6c-9d2207528a78"
+
+// newIDRemoteFile writes a file carrying testNzbdavID in its .id sidecar under
+// currentPath, and an .ids/ symlink pointing at stalePath instead.
+func newIDRemoteFile(t *testing.T, repair bool, currentPath, stalePath string) (*MetadataRemoteFile, string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("stale .ids symlink fixture needs os.Symlink")
+	}
+	_, _, ms := setupStreamHealthEnv(t)
+	root := ms.GetMetadataDirectoryPath("")
+
+	writeStreamMeta(t, ms, currentPath)
+	require.NoError(t, os.WriteFile(ms.GetMetadataFilePath(currentPath)+".id", []byte(testNzbdavID), 0644))
+
+	linkPath := filepath.Join(root, ".ids", "4", "0", "e", "9", "a", testNzbdavID+".meta")
+	if stalePath != "" {
+		require.NoError(t, os.MkdirAll(filepath.Dir(linkPath), 0755))
+		target, err := filepath.Rel(filepath.Dir(linkPath), ms.GetMetadataFilePath(stalePath))
+		require.NoError(t, err)
+		require.NoError(t, os.Symlink(target, linkPath))
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.Metadata.RootPath = root
+	cfg.Metadata.RepairIDSymlinks = &repair
+	return &MetadataRemoteFile{
+		metadataService: ms,
+		configGetter:    func() *config.Config { return cfg },
+		idMisses:        newIDMissCache(),
+	}, linkPath
+}
+
+func TestResolveIDPath_RepairsStaleSymlink(t *testing.T) {
+	current := "complete/Movies/Renamed.Movie.2024/Renamed.Movie.2024.mkv"
+	mrf, linkPath := newIDRemoteFile(t, true, current, "complete/Movies/Old.Name/Old.Name.mkv")
+	ctx := context.Background()
+
+	ok, info, err := mrf.Stat(ctx, ".ids/4/0/e/9/a/"+testNzbdavID)
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, "Renamed.Movie.2024.mkv", info.Name())
+	assert.Equal(t, int64(1024), info.Size())
+
+	// The symlink now points at the renamed file, so the next lookup takes the
+	// fast path.
+	target, err := os.Readlink(linkPath)
+	require.NoError(t, err)
+	assert.Equal(t, mrf.metadataService.GetMetadataFilePath(current), filepath.Join(filepath.Dir(linkPath), target))
+
+	resolved, err := readIDSymlink(mrf.configGetter().Metadata.RootPath, linkPath)
+	require.NoError(t, err)
+	assert.Equal(t, current, resolved)
+}
+
+func TestResolveIDPath_RecreatesMissingSymlink(t *testing.T) {
+	current := "complete/Movies/Some.Movie.2024/Some.Movie.2024.mkv"
+	mrf, linkPath := newIDRemoteFile(t, true, current, "")
+
+	resolved, err := mrf.resolveIDPath(context.Background(), ".ids/4/0/e/9/a/"+testNzbdavID)
+	require.NoError(t, err)
+	assert.Equal(t, current, resolved)
+
+	_, err = os.Lstat(linkPath)
+	assert.NoError(t, err, "missing symlink is recreated")
+}
+
+func TestResolveIDPath_RepairDisabled(t *testing.T) {
+	mrf, linkPath := newIDRemoteFile(t, false,
+		"complete/Movies/Renamed.Movie.2024/Renamed.Movie.2024.mkv", "complete/Movies/Old.Name/Old.Name.mkv")
+	before, err := os.Readlink(linkPath)
+	require.NoError(t, err)
+
+	ok, _, err := mrf.Stat(context.Background(), ".ids/4/0/e/9/a/"+testNzbdavID)
+	assert.Error(t, err)
+	assert.False(t, ok)
+
+	after, err := os.Readlink(linkPath)
+	require.NoError(t, err)
+	assert.Equal(t, before, after, "symlink is left untouched when repair is disabled")
+}
+
+func TestResolveIDPath_UnknownID(t *testing.T) {
+	mrf, _ := newIDRemoteFile(t, true, "complete/Movies/Some.Movie.2024/Some.Movie.2024.mkv", "")
+
+	_, err := mrf.resolveIDPath(context.Background(), ".ids/1/2/3/4/5/12345678-0000-0000-0000-000000000000")
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+}
+
+func TestResolveIDPath_CachesMisses(t *testing.T) {
+	mrf, _ := newIDRemoteFile(t, true, "complete/Movies/Some.Movie.2024/Some.Movie.2024.mkv", "")
+	const otherID = "12345678-0000-0000-0000-000000000000"
+	idPath := ".ids/1/2/3/4/5/" + otherID
+
+	_, err := mrf.resolveIDPath(context.Background(), idPath)
+	require.ErrorIs(t, err, fs.ErrNotExist)
+
+	// A file with the ID appearing now is not scanned for until the miss expires.
+	later := "complete/Movies/Later.2024/Later.2024.mkv"
+	writeStreamMeta(t, mrf.metadataService, later)
+	require.NoError(t, os.WriteFile(mrf.metadataService.GetMetadataFilePath(later)+".id", []byte(otherID), 0644))
+	_, err = mrf.resolveIDPath(context.Background(), idPath)
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+
+	mrf.idMisses.Purge()
+	resolved, err := mrf.resolveIDPath(context.Background(), idPath)
+	require.NoError(t, err)
+	assert.Equal(t, later, resolved)
+}
+
+func TestParseIDPath(t *testing.T) {
+	id, ok := parseIDPath(".ids/4/0/e/9/a/" + testNzbdavID)
+	assert.True(t, ok)
+	assert.Equal(t, testNzbdavID, id)
+
+	for _, p := range []string{
+		".ids/4/0/e/9/a/._" + testNzbdavID, // shards don't match the name
+		".ids/4/0/e/9/" + testNzbdavID,     // too shallow
+		".ids/4/0/e/9/a/" + testNzbdavID + "/extra",
+		".ids/a/b/c/d/e/abcd",
+		".ids/desktop.ini",
+	} {
+		_, ok := parseIDPath(p)
+		assert.False(t, ok, p)
+	}
+}
+
+// TestResolveIDPath_AfterRootMigration checks that ID paths resolve against
+// the active metadata root once it has moved, even before Metadata.RootPath
+// is updated.
+func TestResolveIDPath_AfterRootMigration(t *testing.T) {
+	current := "complete/Movies/Some.Movie.2024/Some.Movie.2024.mkv"
+	mrf, _ := newIDRemoteFile(t, false, current, current)
+	oldRoot := mrf.metadataService.RootPath()
+
+	newRoot := filepath.Join(t.TempDir(), "metadata")
+	require.NoError(t, mrf.metadataService.MigrateRoot(context.Background(), newRoot))
+	require.NoError(t, os.RemoveAll(oldRoot))
+
+	resolved, err := mrf.resolveIDPath(context.Background(), ".ids/4/0/e/9/a/"+testNzbdavID)
+	require.NoError(t, err)
+	assert.Equal(t, current, resolved)
+
+	ok, info, err := mrf.Stat(context.Background(), ".ids/4/0/e/9/a/"+testNzbdavID)
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, int64(1024), info.Size())
+}
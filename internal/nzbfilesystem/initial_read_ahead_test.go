@@ -0,0 +1,156 @@
+package nzbfilesystem
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/javi11/altmount/internal/testsupport/fakepool"
+	"github.com/javi11/altmount/internal/testsupport/segments"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// staggeredPool serves segment i after i*step, so how long the first read
+// takes shows how many segments it waited for.
+func staggeredPool(n, segSize int, step time.Duration) *fakepool.Client {
+	fp := fakepool.New()
+	for i := 0; i < n; i++ {
+		fp.SetBehavior(segments.MessageID(i), fakepool.SegmentBehavior{
+			Latency: time.Duration(i) * step,
+			Bytes:   segments.Payload(i, segSize),
+		})
+	}
+	return fp
+}
+
+func wantFileBytes(n, segSize int) []byte {
+	var out []byte
+	for i := 0; i < n; i++ {
+		out = append(out, segments.Payload(i, segSize)...)
+	}
+	return out
+}
+
+func TestMetadataVirtualFile_FirstReadPrimesInitialReadAhead(t *testing.T) {
+	const (
+		n       = 8
+		segSize = 1024
+		step    = 40 * time.Millisecond
+	)
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	mvf := newTestMVF(t, ctx, staggeredPool(n, segSize, step), n, segSize, n)
+	mvf.initialReadAhead = 4 * segSize
+
+	start := time.Now()
+	buf := make([]byte, 16)
+	got, err := mvf.Read(buf)
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, time.Since(start), 3*step, "first read waits for the fourth segment")
+
+	// Subsequent reads are not primed again and the stream is intact.
+	rest, err := io.ReadAll(mvf)
+	require.NoError(t, err)
+	assert.True(t, bytes.Equal(wantFileBytes(n, segSize), append(buf[:got], rest...)))
+}
+
+func TestMetadataVirtualFile_InitialReadAheadDisabledReadsImmediately(t *testing.T) {
+	const (
+		n       = 8
+		segSize = 1024
+		step    = 200 * time.Millisecond
+	)
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	mvf := newTestMVF(t, ctx, staggeredPool(n, segSize, step), n, segSize, n)
+
+	start := time.Now()
+	_, err := mvf.Read(make([]byte, 16))
+	require.NoError(t, err)
+	assert.Less(t, time.Since(start), step, "first read only needs the first segment")
+}
+
+func TestMetadataVirtualFile_InitialReadAheadBoundedByMaxPrefetch(t *testing.T) {
+	const (
+		n       = 8
+		segSize = 1024
+	)
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	fp := fakepool.New()
+	configurePoolForFile(fp, n, segSize, fakepool.SegmentBehavior{})
+	mvf := newTestMVF(t, ctx, fp, n, segSize, 2)
+	mvf.initialReadAhead = int64(n * segSize * 10)
+
+	data, err := io.ReadAll(mvf)
+	require.NoError(t, err)
+	assert.True(t, bytes.Equal(wantFileBytes(n, segSize), data))
+	fakepool.AssertMaxInFlightLE(t, fp, 2)
+}
+
+func TestMetadataVirtualFile_WarmUpPrimesWithoutConsuming(t *testing.T) {
+	const (
+		n       = 6
+		segSize = 1024
+		step    = 40 * time.Millisecond
+	)
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	mvf := newTestMVF(t, ctx, staggeredPool(n, segSize, step), n, segSize, n)
+	mvf.initialReadAhead = 3 * segSize
+
+	start := time.Now()
+	require.NoError(t, mvf.WarmUp())
+	assert.GreaterOrEqual(t, time.Since(start), 2*step)
+	assert.Zero(t, mvf.position)
+
+	data, err := io.ReadAll(mvf)
+	require.NoError(t, err)
+	assert.True(t, bytes.Equal(wantFileBytes(n, segSize), data))
+}
+
+func TestMetadataVirtualFile_WarmUpOnOpenFillsBeforeFirstRead(t *testing.T) {
+	const (
+		n       = 6
+		segSize = 1024
+	)
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	fp := staggeredPool(n, segSize, 0)
+	mvf := newTestMVF(t, ctx, fp, n, segSize, n)
+	mvf.initialReadAhead = 3 * segSize
+
+	go mvf.warmUpOnOpen()
+	assert.Eventually(t, func() bool {
+		return fp.PerMessageCalls(segments.MessageID(2)) > 0
+	}, 5*time.Second, 10*time.Millisecond, "read-ahead is fetched before any read")
+
+	data, err := io.ReadAll(mvf)
+	require.NoError(t, err)
+	assert.True(t, bytes.Equal(wantFileBytes(n, segSize), data))
+}
+
+func TestMetadataVirtualFile_InitialReadAheadHonorsCancellation(t *testing.T) {
+	const (
+		n       = 4
+		segSize = 1024
+	)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	mvf := newTestMVF(t, ctx, staggeredPool(n, segSize, 10*time.Second), n, segSize, n)
+	mvf.initialReadAhead = int64(n * segSize)
+
+	time.AfterFunc(50*time.Millisecond, cancel)
+	start := time.Now()
+	_, err := mvf.Read(make([]byte, 16))
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
@@ -0,0 +1,106 @@
+package nzbfilesystem
+
+import (
+	"io/fs"
+	"slices"
+	"strings"
+
+	"github.com/javi11/altmount/internal/config"
+)
+
+// sortListing orders infos in place according to mode. Unknown modes and
+// ListingSortNone leave the slice untouched.
+func sortListing(infos []fs.FileInfo, mode config.ListingSort) {
+	var cmp func(a, b fs.FileInfo) int
+	switch mode {
+	case config.ListingSortName:
+		cmp = func(a, b fs.FileInfo) int { return strings.Compare(a.Name(), b.Name()) }
+	case config.ListingSortNatural:
+		cmp = func(a, b fs.FileInfo) int { return naturalCompare(a.Name(), b.Name()) }
+	case config.ListingSortMtime:
+		cmp = func(a, b fs.FileInfo) int {
+			if c := b.ModTime().Compare(a.ModTime()); c != 0 {
+				return c
+			}
+			return strings.Compare(a.Name(), b.Name())
+		}
+	default:
+		return
+	}
+	slices.SortStableFunc(infos, cmp)
+}
+
+// naturalCompare compares strings so runs of digits order by numeric value
+// ("ep2" < "ep10") and other text compares case-insensitively. Exact ties
+// fall back to byte order so the result is deterministic.
+func naturalCompare(a, b string) int {
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		ca, cb := a[i], b[j]
+		if isDigit(ca) && isDigit(cb) {
+			si, sj := i, j
+			for i < len(a) && isDigit(a[i]) {
+				i++
+			}
+			for j < len(b) && isDigit(b[j]) {
+				j++
+			}
+			// Compare digit runs by value: strip leading zeros, then the
+			// longer run is larger; equal lengths compare lexically.
+			na := strings.TrimLeft(a[si:i], "0")
+			nb := strings.TrimLeft(b[sj:j], "0")
+			if len(na) != len(nb) {
+				if len(na) < len(nb) {
+					return -1
+				}
+				return 1
+			}
+			if c := strings.Compare(na, nb); c != 0 {
+				return c
+			}
+			continue
+		}
+
+		la, lb := toLowerASCII(ca), toLowerASCII(cb)
+		if la != lb {
+			if la < lb {
+				return -1
+			}
+			return 1
+		}
+		i++
+		j++
+	}
+
+	switch {
+	case len(a)-i < len(b)-j:
+		return -1
+	case len(a)-i > len(b)-j:
+		return 1
+	}
+	return strings.Compare(a, b)
+}
+
+func isDigit(c byte) bool { return c >= '0' && c <= '9' }
+
+func toLowerASCII(c byte) byte {
+	if c >= 'A' && c <= 'Z' {
+		return c + ('a' - 'A')
+	}
+	return c
+}
+
+// sortEntries orders a names-only listing in place according to mode.
+// ListingSortMtime needs modification times such a listing never reads, so
+// it falls back to name order.
+func sortEntries(entries []fs.DirEntry, mode config.ListingSort) {
+	cmp := strings.Compare
+	switch mode {
+	case config.ListingSortName, config.ListingSortMtime:
+	case config.ListingSortNatural:
+		cmp = naturalCompare
+	default:
+		return
+	}
+	slices.SortStableFunc(entries, func(a, b fs.DirEntry) int { return cmp(a.Name(), b.Name()) })
+}
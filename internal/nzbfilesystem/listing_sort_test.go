@@ -0,0 +1,20 @@
+package nzbfilesystem
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNaturalCompare(t *testing.T) {
+	ordered := []string{
+		"ep1", "ep2", "ep02b", "ep10", "ep010x", "Ep11", "ep100",
+		"movie", "movie 2", "Movie 10",
+	}
+	for i := 0; i+1 < len(ordered); i++ {
+		assert.Negative(t, naturalCompare(ordered[i], ordered[i+1]), "%q < %q", ordered[i], ordered[i+1])
+		assert.Positive(t, naturalCompare(ordered[i+1], ordered[i]), "%q > %q", ordered[i+1], ordered[i])
+	}
+	assert.Zero(t, naturalCompare("ep2", "ep2"))
+	assert.NotZero(t, naturalCompare("ep2", "EP2"), "case-only differences still order deterministically")
+}
@@ -8,14 +8,18 @@ import (
 	"io/fs"
 	"log/slog"
 	"os"
+	"path"
 	"path/filepath"
+	"slices"
 	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
 
 	lru "github.com/hashicorp/golang-lru/v2"
+	"github.com/hashicorp/golang-lru/v2/expirable"
 
+	"github.com/javi11/altmount/internal/bufpool"
 	"github.com/javi11/altmount/internal/config"
 	"github.com/javi11/altmount/internal/database"
 	"github.com/javi11/altmount/internal/encryption"
@@ -47,6 +51,11 @@ type MetadataRemoteFile struct {
 	cacheSource      *segcache.Source         // Segment cache source (nil = no cache configured)
 	repairCoalescer  *RepairCoalescer         // Throttles streaming-failure repair triggers and rclone VFS refreshes
 	padRecorder      *padRecorder             // Process-lived worker persisting degraded-pad events
+	streamLimiter    *StreamLimiter           // Caps concurrent streams per client IP
+	warmupLimiter    *WarmupLimiter           // Caps concurrent WarmUp calls across handles
+	dirSweeper       *EmptyDirSweeper         // Deferred empty library directory cleanup
+	idMisses         *idMissCache             // IDs a metadata scan recently failed to find; nil = no caching
+	sniffedTypes     *sniffedTypeCache        // Content-Types sniffed at open, by path; nil = no caching
 	renameMu         sync.Mutex               // Mutex to protect rename operations from race conditions
 }
 
@@ -91,9 +100,20 @@ func NewMetadataRemoteFile(
 		cacheSource:      cacheSource,
 		repairCoalescer:  repairCoalescer,
 		padRecorder:      newPadRecorder(metadataService, healthRepository, repairCoalescer),
+		streamLimiter:    NewStreamLimiter(configGetter),
+		warmupLimiter:    NewWarmupLimiter(configGetter),
+		dirSweeper:       NewEmptyDirSweeper(configGetter),
+		idMisses:         newIDMissCache(),
+		sniffedTypes:     newSniffedTypeCache(),
 	}
 }
 
+// Close stops the handler's background workers, such as the empty directory
+// sweeper. Open file handles are not affected.
+func (mrf *MetadataRemoteFile) Close() {
+	mrf.dirSweeper.Close()
+}
+
 // Helper methods to get dynamic config values
 func (mrf *MetadataRemoteFile) getMaxPrefetch() int {
 	return mrf.configGetter().Streaming.MaxPrefetch
@@ -124,7 +144,7 @@ func (mrf *MetadataRemoteFile) OpenFile(ctx context.Context, name string) (bool,
 	}
 
 	// Normalize the path to handle trailing slashes consistently
-	normalizedName := normalizePath(name)
+	normalizedName := mrf.resolvePathCase(normalizePath(name))
 
 	// Extract showCorrupted flag from context
 	showCorrupted := false
@@ -138,8 +158,17 @@ func (mrf *MetadataRemoteFile) OpenFile(ctx context.Context, name string) (bool,
 		showCorrupted = true
 	}
 
+	// Paths inside a category's .corrupted view exist only as health records
+	if view, ok := mrf.parseCorruptedView(normalizedName); ok {
+		return mrf.openCorruptedView(ctx, name, normalizedName, view)
+	}
+
 	// Check if this is a directory first
-	if mrf.metadataService.DirectoryExists(normalizedName) {
+	serveDir, err := mrf.servesDirectory(ctx, normalizedName)
+	if err != nil {
+		return false, nil, err
+	}
+	if serveDir {
 		// Create a directory handle
 		virtualDir := &MetadataVirtualDirectory{
 			name:             name,
@@ -148,6 +177,9 @@ func (mrf *MetadataRemoteFile) OpenFile(ctx context.Context, name string) (bool,
 			healthRepository: mrf.healthRepository,
 			configGetter:     mrf.configGetter,
 			showCorrupted:    showCorrupted,
+			viewEntry: func() fs.FileInfo {
+				return mrf.categoryViewEntry(ctx, normalizedName)
+			},
 		}
 		return true, virtualDir, nil
 	}
@@ -158,7 +190,7 @@ func (mrf *MetadataRemoteFile) OpenFile(ctx context.Context, name string) (bool,
 		// Check if it's a sharded ID path (.ids/...)
 		if strings.HasPrefix(normalizedName, ".ids/") {
 			// Resolve the ID path to the actual virtual path
-			resolvedPath, err := mrf.resolveIDPath(normalizedName)
+			resolvedPath, err := mrf.resolveIDPath(ctx, normalizedName)
 			if err == nil && resolvedPath != "" {
 				// Continue with the resolved path
 				normalizedName = resolvedPath
@@ -185,6 +217,10 @@ func (mrf *MetadataRemoteFile) OpenFile(ctx context.Context, name string) (bool,
 		}
 	}
 
+	if mrf.configGetter().Streaming.ExtensionFilter.Hides(normalizedName) {
+		return false, nil, nil
+	}
+
 	// Get file metadata using simplified schema
 	fileMeta, err := mrf.metadataService.ReadFileMetadata(normalizedName)
 	if err != nil {
@@ -196,12 +232,31 @@ func (mrf *MetadataRemoteFile) OpenFile(ctx context.Context, name string) (bool,
 	}
 
 	if fileMeta.Status == metapb.FileStatus_FILE_STATUS_CORRUPTED {
+		switch mrf.arrCorruptView(ctx, normalizedName) {
+		case config.ArrCorruptViewHide:
+			return false, nil, nil
+		case config.ArrCorruptViewZero:
+			return true, &emptyFile{name: name, info: &MetadataFileInfo{
+				name:    filepath.Base(normalizedName),
+				mode:    0444,
+				modTime: time.Unix(fileMeta.ModifiedAt, 0),
+			}}, nil
+		}
 		return false, nil, &CorruptedFileError{
 			TotalExpected: fileMeta.FileSize,
 			UnderlyingErr: ErrMissmatchedSegments,
 		}
 	}
 
+	// Enforce the per-client-IP stream cap before any tracking state is created.
+	clientAddr, _ := ctx.Value(utils.ClientIPKey).(string)
+	releaseStream, err := mrf.streamLimiter.Acquire(clientAddr)
+	if err != nil {
+		slog.WarnContext(ctx, "Rejected stream open: per-IP stream limit reached",
+			"path", normalizedName, "error", err)
+		return false, nil, err
+	}
+
 	// Extract max prefetch from context if available (overrides global config)
 	maxPrefetch := mrf.getMaxPrefetch()
 
@@ -252,20 +307,7 @@ func (mrf *MetadataRemoteFile) OpenFile(ctx context.Context, name string) (bool,
 	// freeing the proto wrapper overhead (~protoimpl.MessageState +
 	// unknownFields + sizeCache + unused fields like NzbdavId). Slices are
 	// carried by reference; they stay alive only while the handle is open.
-	handleMeta := &fileHandleMeta{
-		FileSize:       fileMeta.FileSize,
-		ModifiedAt:     fileMeta.ModifiedAt,
-		SourceNzbPath:  fileMeta.SourceNzbPath,
-		Encryption:     fileMeta.Encryption,
-		Password:       fileMeta.Password,
-		Salt:           fileMeta.Salt,
-		AesKey:         fileMeta.AesKey,
-		AesIv:          fileMeta.AesIv,
-		SegmentData:    fileMeta.SegmentData,
-		NestedSources:  fileMeta.NestedSources,
-		ClipBoundaries: fileMeta.ClipBoundaries,
-		KnownHoles:     fileMeta.KnownHoles,
-	}
+	handleMeta := newFileHandleMeta(fileMeta)
 
 	// Create a metadata-based virtual file handle
 	virtualFile := &MetadataVirtualFile{
@@ -287,12 +329,51 @@ func (mrf *MetadataRemoteFile) OpenFile(ctx context.Context, name string) (bool,
 		globalSalt:       mrf.getGlobalSalt(),
 		streamTracker:    mrf.streamTracker,
 		streamID:         streamID,
+		releaseStream:    releaseStream,
 		segmentStore:     mrf.resolveSegmentStore(),
+		initialReadAhead: mrf.configGetter().Streaming.InitialReadAheadBytes,
+		warmupLimiter:    mrf.warmupLimiter,
+
+		smallFileThreshold: mrf.configGetter().Streaming.SmallFileThreshold,
+	}
+	virtualFile.readPool = readPoolGetter(mrf.poolManager, handleMeta.PreferredProvider)
+	virtualFile.tailWait = time.Duration(mrf.configGetter().Streaming.TailWaitMs) * time.Millisecond
+	virtualFile.startSegment = -1
+	if hint, ok := ctx.Value(utils.StartSegmentKey).(int); ok {
+		virtualFile.applyStartSegment(hint)
+	}
+	virtualFile.adaptivePrefetch = mrf.configGetter().Streaming.PrefetchDirection == config.PrefetchAdaptive
+	virtualFile.trackerUpdateInterval = time.Duration(mrf.configGetter().Streaming.TrackerUpdateIntervalMs) * time.Millisecond
+	if len(handleMeta.NestedSources) > 0 {
+		if k := mrf.configGetter().Streaming.NestedReadConcurrency; k > 1 {
+			virtualFile.nestedReadSlots = make(chan struct{}, k)
+		}
+	}
+	virtualFile.readBufPool = bufpool.ForSize(mrf.configGetter().Streaming.ReadBufferSizeKB * 1024)
+	mrf.sniffContentType(virtualFile, normalizedName)
+	if virtualFile.initialReadAhead > 0 {
+		// Start filling the initial read-ahead now rather than on the
+		// first read; that read waits on the handle lock until it is done.
+		go virtualFile.warmUpOnOpen()
 	}
 
 	return true, virtualFile, nil
 }
 
+// resolvePathCase maps a normalized path to its on-disk casing when
+// case-insensitive lookups are enabled. Exact matches are returned as-is
+// without scanning; names with no match are returned unchanged so the caller
+// reports them as missing.
+func (mrf *MetadataRemoteFile) resolvePathCase(normalizedName string) string {
+	if mrf.configGetter == nil || !mrf.configGetter().Metadata.IsCaseInsensitive() {
+		return normalizedName
+	}
+	if resolved, ok := mrf.metadataService.ResolvePathCase(normalizedName); ok {
+		return resolved
+	}
+	return normalizedName
+}
+
 // RemoveFile removes a virtual file or directory from the metadata
 func (mrf *MetadataRemoteFile) RemoveFile(ctx context.Context, fileName string) (bool, error) {
 	// Normalize the path to handle trailing slashes consistently
@@ -303,6 +384,11 @@ func (mrf *MetadataRemoteFile) RemoveFile(ctx context.Context, fileName string)
 		return false, ErrCannotRemoveRoot
 	}
 
+	// Corrupted views are read-only
+	if _, ok := mrf.parseCorruptedView(normalizedName); ok {
+		return false, os.ErrPermission
+	}
+
 	// Prevent removal of category folders
 	if mrf.isCategoryFolder(normalizedName) {
 		slog.DebugContext(ctx, "Silently ignored removal request for category folder", "path", normalizedName)
@@ -313,6 +399,9 @@ func (mrf *MetadataRemoteFile) RemoveFile(ctx context.Context, fileName string)
 
 	// Check if this is a directory
 	if mrf.metadataService.DirectoryExists(normalizedName) {
+		if mrf.healthRepository != nil && mrf.configGetter().Metadata.ShouldProtectRepairingOnDelete() {
+			return true, mrf.removeDirectoryProtectingRepairs(ctx, normalizedName)
+		}
 		// Use MetadataService's directory delete operation
 		return true, mrf.metadataService.DeleteDirectory(normalizedName)
 	}
@@ -354,13 +443,49 @@ func (mrf *MetadataRemoteFile) RemoveFile(ctx context.Context, fileName string)
 		}
 
 		if rootPath != "" {
-			utils.RemoveEmptyDirs(rootPath, filepath.Dir(physicalPath))
+			if cfg.Metadata.EmptyDirGrace() > 0 && mrf.dirSweeper != nil {
+				mrf.dirSweeper.Schedule(rootPath, filepath.Dir(physicalPath))
+			} else {
+				utils.RemoveEmptyDirs(rootPath, filepath.Dir(physicalPath))
+			}
 		}
 	}
 
 	return true, nil
 }
 
+// removeDirectoryProtectingRepairs deletes a directory except for files whose
+// repair is in progress, which an Arr may still need while it grabs a
+// replacement. Without such files it is a plain DeleteDirectory.
+func (mrf *MetadataRemoteFile) removeDirectoryProtectingRepairs(ctx context.Context, dir string) error {
+	repairing, err := mrf.healthRepository.GetRepairTriggeredPathsByPrefix(ctx, dir)
+	if err != nil {
+		return fmt.Errorf("failed to look up files under repair: %w", err)
+	}
+	if len(repairing) == 0 {
+		return mrf.metadataService.DeleteDirectory(dir)
+	}
+
+	protected := make(map[string]struct{}, len(repairing))
+	for _, p := range repairing {
+		protected[strings.TrimPrefix(p, "/")] = struct{}{}
+	}
+
+	deleteSourceNzb := mrf.configGetter().Metadata.ShouldDeleteSourceNzb()
+	kept, err := mrf.metadataService.DeleteDirectoryPreserving(ctx, dir, deleteSourceNzb, func(virtualPath string) bool {
+		_, ok := protected[virtualPath]
+		return ok
+	})
+	if err != nil {
+		return err
+	}
+
+	slog.InfoContext(ctx, "Partially removed directory, keeping files under repair",
+		"path", dir,
+		"kept", kept)
+	return nil
+}
+
 // RenameFile renames a virtual file or directory in the metadata
 func (mrf *MetadataRemoteFile) RenameFile(ctx context.Context, oldName, newName string) (bool, error) {
 	mrf.renameMu.Lock()
@@ -378,6 +503,13 @@ func (mrf *MetadataRemoteFile) RenameFile(ctx context.Context, oldName, newName
 		return false, os.ErrPermission
 	}
 
+	// Corrupted views are read-only in both directions
+	_, fromView := mrf.parseCorruptedView(normalizedOld)
+	_, toView := mrf.parseCorruptedView(normalizedNew)
+	if fromView || toView {
+		return false, os.ErrPermission
+	}
+
 	// Check if old path is a directory
 	if mrf.metadataService.DirectoryExists(normalizedOld) {
 		// Get the filesystem paths for the directories
@@ -386,16 +518,29 @@ func (mrf *MetadataRemoteFile) RenameFile(ctx context.Context, oldName, newName
 
 		slog.InfoContext(ctx, "Moving metadata directory", "from", oldDirPath, "to", newDirPath)
 
+		// An earlier rename whose updates failed is still journaled; finish it
+		// first so its record is not lost.
+		if _, err := mrf.recoverInterruptedRename(ctx); err != nil {
+			return false, fmt.Errorf("failed to finish pending directory rename: %w", err)
+		}
+
+		// Journal the rename so a crash before the per-file updates below can
+		// be repaired on the next start (see RecoverInterruptedRename).
+		if err := mrf.metadataService.BeginDirRename(normalizedOld, normalizedNew); err != nil {
+			return false, err
+		}
+
 		// Rename the entire directory
 		if err := os.Rename(oldDirPath, newDirPath); err != nil {
+			_ = mrf.metadataService.EndDirRename()
 			return false, fmt.Errorf("failed to rename directory: %w", err)
 		}
 
-		// Update health records for all files under the renamed directory
-		if mrf.healthRepository != nil {
-			if err := mrf.healthRepository.RenameHealthRecord(ctx, normalizedOld, normalizedNew); err != nil {
-				slog.WarnContext(ctx, "Failed to update health records for renamed directory", "old", normalizedOld, "new", normalizedNew, "error", err)
-			}
+		// A failed update leaves the journal in place so the next start retries it.
+		if err := mrf.finishDirRename(ctx, normalizedOld, normalizedNew); err != nil {
+			slog.WarnContext(ctx, "Failed to update records for renamed directory", "old", normalizedOld, "new", normalizedNew, "error", err)
+		} else if err := mrf.metadataService.EndDirRename(); err != nil {
+			slog.WarnContext(ctx, "Failed to clear rename journal", "error", err)
 		}
 
 		return true, nil
@@ -447,58 +592,220 @@ func (mrf *MetadataRemoteFile) RenameFile(ctx context.Context, oldName, newName
 	return true, nil
 }
 
-// isCategoryFolder checks if a path corresponds to a configured category folder
-func (mrf *MetadataRemoteFile) isCategoryFolder(path string) bool {
-	cfg := mrf.configGetter()
-	normalizedPath := strings.Trim(normalizePath(path), "/")
-	completeDir := strings.Trim(normalizePath(cfg.SABnzbd.CompleteDir), "/")
-
-	// Helper to check if a name matches a category
-	matchesCategory := func(name string) bool {
-		name = strings.Trim(normalizePath(name), "/")
-		if name == "" {
-			return false
+// finishDirRename brings the health records and .ids/ symlinks of the files
+// under a directory moved from oldPath to newPath in line with the move. Both
+// steps are idempotent, so it is safe to repeat after a crash.
+func (mrf *MetadataRemoteFile) finishDirRename(ctx context.Context, oldPath, newPath string) error {
+	if mrf.healthRepository != nil {
+		if err := mrf.healthRepository.RenameHealthRecord(ctx, oldPath, newPath); err != nil {
+			return fmt.Errorf("failed to update health records: %w", err)
 		}
+	}
+
+	// The files moved with the directory; repoint their .ids/ symlinks.
+	relinked, err := mrf.metadataService.RelinkIDSymlinksUnder(ctx, newPath, mrf.configGetter().Metadata.RenameWorkers())
+	if err != nil {
+		return fmt.Errorf("failed to update ID symlinks: %w", err)
+	}
+	if relinked > 0 {
+		slog.InfoContext(ctx, "Updated ID symlinks for renamed directory", "new", newPath, "count", relinked)
+	}
+	return nil
+}
+
+// RecoverInterruptedRename finishes a directory rename that a previous run
+// journaled but did not complete. If the directory was moved, its health
+// records and .ids/ symlinks are updated to the new location; if it never
+// moved, the journal is simply dropped. Meant to run once at startup, before
+// the filesystem is served; RenameFile also runs it before journaling a new
+// directory rename. Returns whether a rename was found.
+func (mrf *MetadataRemoteFile) RecoverInterruptedRename(ctx context.Context) (bool, error) {
+	mrf.renameMu.Lock()
+	defer mrf.renameMu.Unlock()
+	return mrf.recoverInterruptedRename(ctx)
+}
 
-		// Check exact match (case-insensitive)
-		if strings.EqualFold(normalizedPath, name) {
-			return true
+// recoverInterruptedRename is RecoverInterruptedRename for callers already
+// holding renameMu.
+func (mrf *MetadataRemoteFile) recoverInterruptedRename(ctx context.Context) (bool, error) {
+	pending, err := mrf.metadataService.PendingDirRename()
+	if err != nil {
+		slog.WarnContext(ctx, "Discarding unreadable rename journal", "error", err)
+		return false, mrf.metadataService.EndDirRename()
+	}
+	if pending == nil {
+		return false, nil
+	}
+
+	oldExists := mrf.metadataService.DirectoryExists(pending.Old)
+	newExists := mrf.metadataService.DirectoryExists(pending.New)
+	switch {
+	case newExists && !oldExists:
+		slog.InfoContext(ctx, "Completing interrupted directory rename", "old", pending.Old, "new", pending.New)
+		if err := mrf.finishDirRename(ctx, pending.Old, pending.New); err != nil {
+			return true, err
+		}
+	case newExists:
+		// Both exist: the old path was recreated after the move, so its health
+		// records may be current. Only the moved files' symlinks are repaired.
+		slog.WarnContext(ctx, "Interrupted rename source was recreated, repairing ID symlinks only", "old", pending.Old, "new", pending.New)
+		if _, err := mrf.metadataService.RelinkIDSymlinksUnder(ctx, pending.New, mrf.configGetter().Metadata.RenameWorkers()); err != nil {
+			return true, err
 		}
+	default:
+		slog.InfoContext(ctx, "Interrupted directory rename never moved the directory", "old", pending.Old, "new", pending.New)
+	}
+
+	return true, mrf.metadataService.EndDirRename()
+}
 
-		// Check match with complete_dir prefix (e.g. complete/tv)
-		if completeDir != "" && strings.EqualFold(normalizedPath, strings.Trim(completeDir+"/"+name, "/")) {
-			return true
+// credentialSampleSize is how much of a file VerifyCredentials decrypts. One
+// rclone crypt block (64 KiB) is enough for its authenticator to reject a
+// wrong key, and it covers the container headers aesSampleHeaders checks.
+const credentialSampleSize = 64 * 1024
+
+// VerifyCredentials reads the start of virtualPath using the credentials in
+// candidate and reports any decryption failure. For AES, which cannot detect
+// a wrong key itself, the decrypted sample must also start with the header
+// its extension promises (see aesSampleHeaders); other file types return
+// ErrAesKeyUnverifiable. It implements metadata.CredentialVerifier. The probe
+// handle has no health or repair collaborators and registers no stream, so a
+// failed probe never marks the file corrupted or shows up as an active stream.
+func (mrf *MetadataRemoteFile) VerifyCredentials(ctx context.Context, virtualPath string, candidate *metapb.FileMetadata) error {
+	if candidate.FileSize <= 0 {
+		return nil
+	}
+	var checkHeader func([]byte) bool
+	if usesAes(candidate) {
+		var ok bool
+		if checkHeader, ok = aesSampleHeaders[strings.ToLower(filepath.Ext(virtualPath))]; !ok {
+			return fmt.Errorf("%w: %s", ErrAesKeyUnverifiable, virtualPath)
 		}
+	}
 
-		return false
+	probe := &MetadataVirtualFile{
+		name:             virtualPath,
+		meta:             newFileHandleMeta(candidate),
+		configGetter:     mrf.configGetter,
+		poolManager:      mrf.poolManager,
+		ctx:              ctx,
+		maxPrefetch:      1,
+		rcloneCipher:     mrf.rcloneCipher,
+		aesCipher:        mrf.aesCipher,
+		globalPassword:   mrf.getGlobalPassword(),
+		globalSalt:       mrf.getGlobalSalt(),
+		segmentStore:     mrf.resolveSegmentStore(),
+		streamTracker:    mrf.streamTracker, // metrics only; no stream ID is registered
+		originalRangeEnd: -1,
+		// Force ReadAtContext onto the ephemeral range-reader path: the shared
+		// path swallows mid-read errors, which would hide a decryption failure.
+		readAtSharedNext: -1,
 	}
+	defer probe.Close()
 
-	// Check complete_dir itself
-	if strings.EqualFold(normalizedPath, completeDir) {
-		return true
+	buf := make([]byte, min(credentialSampleSize, candidate.FileSize))
+	n, err := probe.ReadAtContext(ctx, buf, 0)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("failed to decrypt sample of %s: %w", virtualPath, err)
+	}
+	if checkHeader != nil && !checkHeader(buf[:n]) {
+		return fmt.Errorf("decrypted sample of %s has no valid %s header: wrong AES key", virtualPath, filepath.Ext(virtualPath))
+	}
+	return nil
+}
+
+// MoveToCategory moves a file from its current SABnzbd category folder into
+// newCategory, keeping the path below the category folder unchanged (e.g.
+// tv/Show/ep.mkv -> anime/Show/ep.mkv). The metadata rename and health-record
+// update go through RenameFile; the file's .ids/ symlink, if any, is then
+// repointed at the new location. Category folders themselves cannot be moved,
+// and the destination must not already exist. Returns the new virtual path.
+func (mrf *MetadataRemoteFile) MoveToCategory(ctx context.Context, virtualPath, newCategory string) (string, error) {
+	normalizedOld := strings.Trim(normalizePath(virtualPath), "/")
+	if mrf.isCategoryFolder(normalizedOld) {
+		return "", os.ErrPermission
+	}
+	if !mrf.metadataService.FileExists(normalizedOld) {
+		return "", os.ErrNotExist
+	}
+
+	cfg := mrf.configGetter()
+	newCategoryDir, ok := cfg.GetCategoryDir(newCategory)
+	if !ok {
+		return "", fmt.Errorf("unknown category %q", newCategory)
 	}
 
-	// Check configured categories
-	for _, cat := range cfg.SABnzbd.Categories {
-		// Check both the category name and its specific directory if set
-		if matchesCategory(cat.Name) {
-			return true
+	// Split the path into <complete_dir>/<category dir>/<rest>.
+	completeDir := strings.Trim(normalizePath(cfg.SABnzbd.CompleteDir), "/")
+	rel := normalizedOld
+	if completeDir != "" {
+		if !strings.HasPrefix(strings.ToLower(rel), strings.ToLower(completeDir)+"/") {
+			return "", fmt.Errorf("path %q is not under complete_dir %q", normalizedOld, completeDir)
+		}
+		rel = rel[len(completeDir)+1:]
+	}
+	// A category Dir may span several segments (e.g. media/tv), so take the
+	// longest leading directory that is a category folder.
+	var rest string
+	found := false
+	for i := strings.LastIndex(rel, "/"); i > 0; i = strings.LastIndex(rel[:i], "/") {
+		if mrf.isCategoryFolder(path.Join(completeDir, rel[:i])) {
+			rest, found = rel[i+1:], true
+			break
 		}
-		if cat.Dir != "" && matchesCategory(cat.Dir) {
-			return true
+	}
+	if !found {
+		return "", fmt.Errorf("path %q is not inside a category folder", normalizedOld)
+	}
+
+	normalizedNew := path.Join(completeDir, strings.Trim(normalizePath(newCategoryDir), "/"), rest)
+	if strings.EqualFold(normalizedNew, normalizedOld) {
+		return normalizedOld, nil
+	}
+	if mrf.metadataService.FileExists(normalizedNew) {
+		return "", fmt.Errorf("destination %q: %w", normalizedNew, os.ErrExist)
+	}
+
+	renamed, err := mrf.RenameFile(ctx, normalizedOld, normalizedNew)
+	if err != nil {
+		return "", err
+	}
+	if !renamed {
+		return "", os.ErrNotExist
+	}
+
+	if meta, err := mrf.metadataService.ReadFileMetadata(normalizedNew); err == nil && meta != nil && meta.NzbdavId != "" {
+		if _, err := mrf.metadataService.UpdateIDSymlink(meta.NzbdavId, normalizedNew); err != nil {
+			slog.WarnContext(ctx, "Failed to update ID symlink after category move",
+				"path", normalizedNew, "nzbdav_id", meta.NzbdavId, "error", err)
 		}
 	}
 
-	return false
+	slog.InfoContext(ctx, "Moved file to category",
+		"source", normalizedOld, "destination", normalizedNew, "category", newCategory)
+	return normalizedNew, nil
+}
+
+// isCategoryFolder checks if a path corresponds to a configured category folder
+func (mrf *MetadataRemoteFile) isCategoryFolder(path string) bool {
+	return mrf.configGetter().IsCategoryDir(path)
 }
 
 // Stat returns file information for a path using metadata
 func (mrf *MetadataRemoteFile) Stat(ctx context.Context, name string) (bool, fs.FileInfo, error) {
 	// Normalize the path
-	normalizedName := normalizePath(name)
+	normalizedName := mrf.resolvePathCase(normalizePath(name))
+
+	if view, ok := mrf.parseCorruptedView(normalizedName); ok {
+		return mrf.statCorruptedView(ctx, normalizedName, view)
+	}
 
 	// Check if this is a directory first
-	if mrf.metadataService.DirectoryExists(normalizedName) {
+	serveDir, err := mrf.servesDirectory(ctx, normalizedName)
+	if err != nil {
+		return false, nil, err
+	}
+	if serveDir {
 		info := &MetadataFileInfo{
 			name:    filepath.Base(normalizedName),
 			size:    0,
@@ -515,7 +822,7 @@ func (mrf *MetadataRemoteFile) Stat(ctx context.Context, name string) (bool, fs.
 		// Check if it's a sharded ID path (.ids/...)
 		if strings.HasPrefix(normalizedName, ".ids/") {
 			// Resolve the ID path to the actual virtual path
-			resolvedPath, err := mrf.resolveIDPath(normalizedName)
+			resolvedPath, err := mrf.resolveIDPath(ctx, normalizedName)
 			if err == nil && resolvedPath != "" {
 				// Continue with the resolved path
 				normalizedName = resolvedPath
@@ -528,6 +835,10 @@ func (mrf *MetadataRemoteFile) Stat(ctx context.Context, name string) (bool, fs.
 		}
 	}
 
+	if mrf.configGetter().Streaming.ExtensionFilter.Hides(normalizedName) {
+		return false, nil, fs.ErrNotExist
+	}
+
 	// Use lightweight metadata — Stat only needs size and modtime, not segments.
 	fileMeta, err := mrf.metadataService.ReadFileMetadataLite(normalizedName)
 	if err != nil {
@@ -557,11 +868,22 @@ func (mrf *MetadataRemoteFile) Stat(ctx context.Context, name string) (bool, fs.
 
 	// Convert to fs.FileInfo
 	info := &MetadataFileInfo{
-		name:    filepath.Base(normalizedName),
-		size:    fileMeta.FileSize,
-		mode:    0644, // Default file mode
-		modTime: time.Unix(fileMeta.ModifiedAt, 0),
-		isDir:   false,
+		name:        filepath.Base(normalizedName),
+		size:        fileMeta.FileSize,
+		mode:        0644, // Default file mode
+		modTime:     time.Unix(fileMeta.ModifiedAt, 0),
+		isDir:       false,
+		contentType: mrf.contentType(normalizedName),
+	}
+
+	if fileMeta.Status == metapb.FileStatus_FILE_STATUS_CORRUPTED {
+		switch mrf.arrCorruptView(ctx, normalizedName) {
+		case config.ArrCorruptViewHide:
+			return false, nil, fs.ErrNotExist
+		case config.ArrCorruptViewZero:
+			info.size = 0
+			info.mode = 0444
+		}
 	}
 
 	return true, info, nil
@@ -574,6 +896,13 @@ type MetadataFileInfo struct {
 	mode    os.FileMode
 	modTime time.Time
 	isDir   bool
+	// unreadable marks a listing entry whose .meta file could not be parsed.
+	unreadable bool
+	// sizeUnknown is set for opened files whose size is only estimated from
+	// their segments.
+	sizeUnknown bool
+	// contentType is the type the file is served with; "" when unknown.
+	contentType string
 }
 
 func (mfi *MetadataFileInfo) Name() string       { return mfi.name }
@@ -583,6 +912,13 @@ func (mfi *MetadataFileInfo) ModTime() time.Time { return mfi.modTime }
 func (mfi *MetadataFileInfo) IsDir() bool        { return mfi.isDir }
 func (mfi *MetadataFileInfo) Sys() any           { return nil }
 
+// Unreadable reports whether the entry's metadata could not be parsed. Such
+// entries only appear in listings when corrupted files are shown.
+func (mfi *MetadataFileInfo) Unreadable() bool { return mfi.unreadable }
+
+// SizeUnknown reports whether Size is only an estimate; see utils.SizeUnknown.
+func (mfi *MetadataFileInfo) SizeUnknown() bool { return mfi.sizeUnknown }
+
 // MetadataSegmentLoader adapts metadata segments to the usenet.SegmentLoader interface
 type MetadataSegmentLoader struct {
 	segments []*metapb.SegmentData
@@ -602,13 +938,14 @@ func (msl *MetadataSegmentLoader) GetSegment(index int) (segment usenet.Segment,
 	}
 
 	seg := msl.segments[index]
+	start, end := seg.BodyRange()
 
 	return usenet.Segment{
 		Id:    seg.Id,
-		Start: seg.StartOffset,
-		End:   seg.EndOffset,
+		Start: start,
+		End:   end,
 		Size:  seg.SegmentSize,
-	}, []string{}, true // Empty groups for now - could be stored in metadata if needed
+	}, []string{}, true // No groups: the pool fetches articles by message-id only
 }
 
 // MetadataVirtualDirectory implements afero.File for metadata-backed virtual directories
@@ -619,6 +956,12 @@ type MetadataVirtualDirectory struct {
 	healthRepository *database.HealthRepository
 	configGetter     config.ConfigGetter
 	showCorrupted    bool
+	// listView, when set, replaces the on-disk listing: the directory lives
+	// inside a category's .corrupted view.
+	listView func() ([]fs.FileInfo, error)
+	// viewEntry, when set, returns a category's .corrupted entry to list
+	// alongside its subdirectories, or nil when there is none.
+	viewEntry func() fs.FileInfo
 }
 
 // Read implements afero.File.Read (not supported for directories)
@@ -648,6 +991,17 @@ func (mvd *MetadataVirtualDirectory) Name() string {
 
 // Readdir implements afero.File.Readdir
 func (mvd *MetadataVirtualDirectory) Readdir(count int) ([]fs.FileInfo, error) {
+	if mvd.listView != nil {
+		infos, err := mvd.listView()
+		if err != nil {
+			return nil, err
+		}
+		if count > 0 && len(infos) > count {
+			infos = infos[:count]
+		}
+		return infos, nil
+	}
+
 	// Single os.ReadDir call that returns both subdirectory infos and file names.
 	// Uses ReadFileMetadataLite for files so that full protos (with SegmentData,
 	// Par2Files, etc.) are NOT pulled into the main cache just for a listing.
@@ -656,32 +1010,78 @@ func (mvd *MetadataVirtualDirectory) Readdir(count int) ([]fs.FileInfo, error) {
 		return nil, err
 	}
 
+	cfg := mvd.configGetter()
+	dirInfos = slices.DeleteFunc(dirInfos, func(info fs.FileInfo) bool {
+		return mvd.hidesEntry(cfg, info.Name())
+	})
+	if mvd.viewEntry != nil {
+		if entry := mvd.viewEntry(); entry != nil {
+			dirInfos = append(dirInfos, entry)
+		}
+	}
+
+	// A sorted listing needs every entry before the count cut-off applies.
+	sortMode := cfg.Metadata.ListingSort
+	sorted := sortMode != "" && sortMode != config.ListingSortNone
+	limit := count
+	if sorted {
+		limit = 0
+	}
+
 	var infos []fs.FileInfo
 
 	// Add directories first
 	for _, dirInfo := range dirInfos {
 		infos = append(infos, dirInfo)
-		if count > 0 && len(infos) >= count {
+		if limit > 0 && len(infos) >= limit {
 			return infos, nil
 		}
 	}
+	numDirs := len(infos)
 
 	// Check if failure masking is enabled
-	cfg := mvd.configGetter()
 	maskingEnabled := cfg.Streaming.FailureMasking.Enabled == nil || *cfg.Streaming.FailureMasking.Enabled
 
 	ctx := context.Background()
 
 	for _, fileName := range fileNames {
+		if cfg.Streaming.ExtensionFilter.Hides(fileName) || mvd.hidesEntry(cfg, fileName) {
+			continue
+		}
+
 		virtualFilePath := filepath.Join(mvd.normalizedPath, fileName)
 		fileMeta, err := mvd.metadataService.ReadFileMetadataLite(virtualFilePath)
-		if err != nil || fileMeta == nil {
+		if err != nil {
+			// Unparseable metadata is hidden from normal listings but surfaced
+			// as a flagged, zero-size entry when showing corrupted files so it
+			// can be found and cleaned up instead of silently vanishing.
+			if !mvd.showCorrupted {
+				slog.DebugContext(ctx, "Skipping unreadable metadata in listing", "path", virtualFilePath, "error", err)
+				continue
+			}
+			infos = append(infos, &MetadataFileInfo{
+				name:       fileName,
+				mode:       0444,
+				modTime:    mvd.metadataModTime(virtualFilePath),
+				unreadable: true,
+			})
+			if limit > 0 && len(infos) >= limit {
+				return infos, nil
+			}
+			continue
+		}
+		if fileMeta == nil {
 			continue
 		}
 
-		// Skip corrupted files unless showCorrupted flag is set
+		// Skip corrupted files unless showCorrupted flag is set, or list them
+		// as empty files when the ARR view asks for that.
+		size, mode := fileMeta.FileSize, os.FileMode(0644)
 		if !mvd.showCorrupted && fileMeta.Status == metapb.FileStatus_FILE_STATUS_CORRUPTED {
-			continue
+			if cfg.Health.ArrCorruptView != config.ArrCorruptViewZero {
+				continue
+			}
+			size, mode = 0, 0444
 		}
 
 		// Skip masked files if masking is enabled
@@ -694,20 +1094,38 @@ func (mvd *MetadataVirtualDirectory) Readdir(count int) ([]fs.FileInfo, error) {
 
 		info := &MetadataFileInfo{
 			name:    fileName,
-			size:    fileMeta.FileSize,
-			mode:    0644,
+			size:    size,
+			mode:    mode,
 			modTime: time.Unix(fileMeta.ModifiedAt, 0),
 			isDir:   false,
 		}
 		infos = append(infos, info)
-		if count > 0 && len(infos) >= count {
+		if limit > 0 && len(infos) >= limit {
 			return infos, nil
 		}
 	}
 
+	if sorted {
+		// Directories stay ahead of files; each group is ordered on its own.
+		sortListing(infos[:numDirs], sortMode)
+		sortListing(infos[numDirs:], sortMode)
+		if count > 0 && len(infos) > count {
+			infos = infos[:count]
+		}
+	}
+
 	return infos, nil
 }
 
+// metadataModTime returns the on-disk modification time of a file's .meta,
+// used for entries whose metadata cannot be parsed. Falls back to the zero time.
+func (mvd *MetadataVirtualDirectory) metadataModTime(virtualFilePath string) time.Time {
+	if st, err := os.Stat(mvd.metadataService.GetMetadataFilePath(virtualFilePath)); err == nil {
+		return st.ModTime()
+	}
+	return time.Time{}
+}
+
 // Readdirnames implements afero.File.Readdirnames
 func (mvd *MetadataVirtualDirectory) Readdirnames(n int) ([]string, error) {
 	infos, err := mvd.Readdir(n)
@@ -784,6 +1202,51 @@ type fileHandleMeta struct {
 	// KnownHoles is the persisted hole map: segments confirmed missing on all
 	// providers, zero-filled during streaming without a fetch round-trip.
 	KnownHoles []*metapb.HoleRun
+	// SizeUnknown marks a file imported without a recorded size. FileSize is
+	// then the total of its segments, read until EOF, and the file is served
+	// without a Content-Length.
+	SizeUnknown bool
+	// PreferredProvider names the provider (config ID or name) this file's
+	// reads try first. Empty uses normal provider selection.
+	PreferredProvider string
+	// Growing marks a file whose import is still appending segments; reads
+	// at its end may wait for more (see waitForGrowth).
+	Growing bool
+	// ContentMD5 is the MD5 of the whole file, checked passively as it is
+	// read (see feedChecksum). Empty when no checksum was stored.
+	ContentMD5 []byte
+}
+
+// newFileHandleMeta extracts the handle fields from fileMeta.
+func newFileHandleMeta(fileMeta *metapb.FileMetadata) *fileHandleMeta {
+	hm := &fileHandleMeta{
+		FileSize:          fileMeta.FileSize,
+		ModifiedAt:        fileMeta.ModifiedAt,
+		SourceNzbPath:     fileMeta.SourceNzbPath,
+		Encryption:        fileMeta.Encryption,
+		Password:          fileMeta.Password,
+		Salt:              fileMeta.Salt,
+		AesKey:            fileMeta.AesKey,
+		AesIv:             fileMeta.AesIv,
+		SegmentData:       fileMeta.SegmentData,
+		NestedSources:     fileMeta.NestedSources,
+		ClipBoundaries:    fileMeta.ClipBoundaries,
+		KnownHoles:        fileMeta.KnownHoles,
+		PreferredProvider: fileMeta.PreferredProvider,
+		ContentMD5:        fileMeta.ContentMd5,
+		Growing:           fileMeta.Growing,
+	}
+
+	// Only a plain segment list maps one-to-one onto file bytes, so only
+	// there can the segments stand in for a missing size.
+	if hm.FileSize <= 0 && hm.Encryption == metapb.Encryption_NONE &&
+		len(hm.NestedSources) == 0 && len(hm.SegmentData) > 0 {
+		for _, seg := range hm.SegmentData {
+			hm.FileSize += seg.UsableLen()
+		}
+		hm.SizeUnknown = true
+	}
+	return hm
 }
 
 // MetadataVirtualFile implements afero.File for metadata-backed virtual files
@@ -806,21 +1269,83 @@ type MetadataVirtualFile struct {
 	globalSalt       string
 	streamTracker    StreamTracker
 	streamID         string
+	releaseStream    func()              // returns the per-IP stream slot; nil when not limited
 	segmentStore     usenet.SegmentStore // optional segment cache
 	segmentIndexOnce sync.Once           // guards lazy init of segmentIndex
+	// readPool is the client source for this handle's reads (see
+	// readPoolGetter), fixed at open so reads never consult meta for it.
+	readPool func() (pool.NntpClient, error)
+
+	// nestedReadSlots bounds how many non-sequential ReadAts on a nested file
+	// may download concurrently (Streaming.NestedReadConcurrency). nil keeps
+	// the default of fully serialized reads.
+	nestedReadSlots chan struct{}
+
+	// readBufPool supplies AES decrypt scratch buffers shared across handles
+	// (Streaming.ReadBufferSizeKB). nil allocates a buffer per reader.
+	readBufPool *bufpool.Pool
+
+	// initialReadAhead is how many bytes the first read of the handle waits to
+	// have buffered (Streaming.InitialReadAheadBytes); 0 disables priming.
+	// readAheadPrimed is set once that wait has been attempted.
+	initialReadAhead int64
+	readAheadPrimed  bool
+	// tailWait is Streaming.TailWaitMs: how long a read at the end of a
+	// growing file waits for more segments (see waitForGrowth).
+	tailWait time.Duration
+	// startSegment is the segment index a resuming client said it starts at
+	// (utils.StartSegmentKey), already checked against the file; -1 when none.
+	// It only picks the first reader's segment. See applyStartSegment.
+	startSegment int
+	// warmupLimiter bounds concurrent WarmUp calls across handles
+	// (Streaming.MaxWarmups); nil never limits.
+	warmupLimiter *WarmupLimiter
+
+	// smallFileThreshold is Streaming.SmallFileThreshold: files below it cap
+	// prefetch to the read that opens the reader plus smallFilePrefetchMargin
+	// segments. readerPrefetch is that cap for the current reader (0 = use
+	// maxPrefetch); ensureReader recomputes it for every reader it opens and
+	// it is cleared once that reader is closed or widened.
+	smallFileThreshold int64
+	readerPrefetch     int
+
+	// narrowReader is the current reader while it runs with readerPrefetch
+	// below maxPrefetch, and narrowReads counts the reads it has served; see
+	// widenPrefetch.
+	narrowReader prefetchWidener
+	narrowReads  int
+
+	// adaptivePrefetch is Streaming.PrefetchDirection=adaptive: seeks are
+	// recorded in seeks, and readers opened while the handle keeps seeking
+	// backward stop reading ahead (see prefetchBackward).
+	adaptivePrefetch bool
+	seeks            seekHistory
+
+	// trackerUpdateInterval is Streaming.TrackerUpdateIntervalMs: reads are
+	// batched in progress and published to streamTracker at most this often
+	// (0 = on every read).
+	trackerUpdateInterval time.Duration
+	progress              trackerBatch
 
 	// clipSpans is the lazily-built absolute byte-range + delta table for the
 	// continuous-timeline remux, derived once from meta.ClipBoundaries.
 	clipSpans     []clipSpan
 	clipSpansOnce sync.Once
 
+	// checksum is the in-progress passive verification of meta.ContentMD5;
+	// nil until the first read of a verifiable file. Guarded by mvf.mu.
+	checksum *contentChecksum
+
 	// Reader state and position tracking
 	reader io.ReadCloser
 	// bufOffReader is mvf.reader pre-asserted to the GetBufferedOffset interface
 	// (nil when the active reader doesn't implement it), so the hot Read/ReadAt
 	// loops avoid repeating the type assertion every iteration. Kept in sync by
 	// setReader and the remux-wrap step; guarded by mvf.mu like reader.
-	bufOffReader      interface{ GetBufferedOffset() int64 }
+	bufOffReader interface{ GetBufferedOffset() int64 }
+	// primer is the segment reader beneath mvf.reader when it supports
+	// read-ahead priming; set by setReader so remux wrappers don't hide it.
+	primer            readAheadPrimer
 	readerInitialized bool
 	position          int64 // File position (what client sees after Seek)
 	originalRangeEnd  int64 // Original end requested by client (-1 for unbounded)
@@ -844,6 +1369,14 @@ type MetadataVirtualFile struct {
 	mu      sync.Mutex
 	closeWg sync.WaitGroup // tracks the bounded closer-worker pool
 
+	// sniffedContentType is the type detected from the file's first bytes
+	// (Streaming.SniffContentTypes); set at open and never changed after.
+	sniffedContentType string
+
+	// segmentTrace, when set, instruments the readers the handle creates
+	// (see DiagnoseRead); held under mvf.mu.
+	segmentTrace func(usenet.SegmentTrace)
+
 	// closerCh is the per-file bounded closer queue. Lazy-initialized
 	// on first closeCurrentReader; closed in mvf.Close so the worker
 	// goroutines exit. See enqueueCloser / closerWorkerCount.
@@ -889,11 +1422,18 @@ type readerInterrupter interface {
 // nil and rejects type changes between calls).
 type interruptSlot struct{ i readerInterrupter }
 
+// readAheadPrimer is implemented by readers that can block until a minimum
+// number of bytes ahead of the read position are downloaded (UsenetReader).
+type readAheadPrimer interface {
+	Prime(ctx context.Context, minBytes int64) (int64, error)
+}
+
 // setReader assigns a new reader and refreshes the interrupt handle.
 // Callers must hold mvf.mu. Pass nil to clear.
 func (mvf *MetadataVirtualFile) setReader(r io.ReadCloser) {
 	mvf.reader = r
 	mvf.bufOffReader, _ = r.(interface{ GetBufferedOffset() int64 })
+	mvf.primer, _ = r.(readAheadPrimer)
 	slot := interruptSlot{}
 	if i, ok := r.(readerInterrupter); ok {
 		slot.i = i
@@ -934,7 +1474,7 @@ func buildSegmentIndex(segments []*metapb.SegmentData) *segmentOffsetIndex {
 	var pos int64
 	for i, seg := range segments {
 		idx.offsets[i] = pos
-		usableLen := seg.EndOffset - seg.StartOffset + 1
+		usableLen := seg.UsableLen()
 		idx.sizes[i] = usableLen
 		pos += usableLen
 	}
@@ -1005,24 +1545,31 @@ func (mvf *MetadataVirtualFile) Read(p []byte) (n int, err error) {
 		return 0, ErrFileClosed
 	}
 
+	// Also covers zero-byte files, which have no segments to open a reader on.
+	if mvf.position >= mvf.meta.FileSize {
+		grew, err := mvf.waitForGrowth(mvf.ctx, mvf.position)
+		if err != nil {
+			return 0, err
+		}
+		if !grew {
+			return 0, io.EOF
+		}
+	}
+
 	for n < len(p) {
-		if err := mvf.ensureReader(); err != nil {
+		if err := mvf.ensureReader(int64(len(p) - n)); err != nil {
+			return n, err
+		}
+		if err := mvf.primeReadAhead(); err != nil {
 			return n, err
 		}
 
 		totalRead, readErr := mvf.reader.Read(p[n:])
+		mvf.feedChecksum(p[n:n+totalRead], mvf.position)
 		n += totalRead
 		mvf.position += int64(totalRead)
 
-		if totalRead > 0 && mvf.streamTracker != nil && mvf.streamID != "" {
-			mvf.streamTracker.UpdateProgress(mvf.streamID, int64(totalRead))
-			mvf.streamTracker.UpdateCurrentOffset(mvf.streamID, mvf.position)
-
-			// Update buffered offset if available
-			if mvf.bufOffReader != nil {
-				mvf.streamTracker.UpdateBufferedOffset(mvf.streamID, mvf.bufOffReader.GetBufferedOffset())
-			}
-		}
+		mvf.recordProgress(totalRead, mvf.position)
 
 		if readErr != nil {
 			if errors.Is(readErr, io.EOF) && mvf.hasMoreDataToRead() {
@@ -1044,10 +1591,99 @@ func (mvf *MetadataVirtualFile) Read(p []byte) (n int, err error) {
 			return n, readErr
 		}
 	}
+	mvf.widenPrefetch()
 
 	return n, nil
 }
 
+// WarmUp opens the reader at the current position and waits for the initial
+// read-ahead (Streaming.InitialReadAheadBytes) without consuming any data, so
+// a caller can fill the buffer before the player's first read arrives. It is
+// a no-op once the handle has been primed or when priming is disabled.
+//
+// At most Streaming.MaxWarmups warm-ups run at once across all handles. Past
+// that, WarmUp returns ErrTooManyWarmups without touching the handle, or
+// waits for a slot when Streaming.WarmupOverflow is queue; the handle lock is
+// not held while waiting, so reads proceed meanwhile.
+func (mvf *MetadataVirtualFile) WarmUp() error {
+	mvf.mu.Lock()
+	needed, err := mvf.needsWarmUp()
+	mvf.mu.Unlock()
+	if err != nil || !needed {
+		return err
+	}
+
+	ctx := mvf.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	release, err := mvf.warmupLimiter.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	mvf.mu.Lock()
+	defer mvf.mu.Unlock()
+
+	// A read may have primed the handle while this call waited for a slot.
+	if needed, err := mvf.needsWarmUp(); err != nil || !needed {
+		return err
+	}
+	if err := mvf.ensureReader(mvf.initialReadAhead); err != nil {
+		return err
+	}
+	return mvf.primeReadAhead()
+}
+
+// warmUpOnOpen runs WarmUp for a handle just opened with
+// Streaming.InitialReadAheadBytes set. A skipped or failed warm-up is only
+// logged: the first read primes the handle itself and surfaces any error.
+func (mvf *MetadataVirtualFile) warmUpOnOpen() {
+	err := mvf.WarmUp()
+	if err == nil || errors.Is(err, ErrFileClosed) || errors.Is(err, context.Canceled) {
+		return
+	}
+	slog.DebugContext(mvf.ctx, "Initial read-ahead warm-up skipped",
+		"file", mvf.name,
+		"error", err)
+}
+
+// needsWarmUp reports whether WarmUp has anything to do. Callers must hold
+// mvf.mu.
+func (mvf *MetadataVirtualFile) needsWarmUp() (bool, error) {
+	if mvf.meta == nil {
+		return false, ErrFileClosed
+	}
+	return !mvf.readAheadPrimed && mvf.initialReadAhead > 0 && mvf.position < mvf.meta.FileSize, nil
+}
+
+// primeReadAhead blocks the first read of the handle until the initial
+// read-ahead is buffered. Later calls, readers without priming support, and
+// download errors (surfaced by the following Read) return immediately.
+// Callers must hold mvf.mu.
+func (mvf *MetadataVirtualFile) primeReadAhead() error {
+	if mvf.readAheadPrimed {
+		return nil
+	}
+	mvf.readAheadPrimed = true
+	if mvf.initialReadAhead <= 0 || mvf.primer == nil {
+		return nil
+	}
+
+	start := time.Now()
+	primed, err := mvf.primer.Prime(mvf.ctx, mvf.initialReadAhead)
+	if err != nil {
+		return err
+	}
+	slog.DebugContext(mvf.ctx, "Primed initial read-ahead",
+		"file", mvf.name,
+		"bytes", primed,
+		"wanted", mvf.initialReadAhead,
+		"duration", time.Since(start))
+	return nil
+}
+
 // ReadAt implements afero.File.ReadAt. It delegates to ReadAtContext using the
 // file-level context.
 func (mvf *MetadataVirtualFile) ReadAt(p []byte, off int64) (n int, err error) {
@@ -1062,7 +1698,9 @@ func (mvf *MetadataVirtualFile) ReadAt(p []byte, off int64) (n int, err error) {
 // streaming reader (preserving the prefetch pipeline); non-sequential offsets
 // use a short-lived range reader so the shared pipeline is not disturbed.
 // All calls are serialized via mvf.mu — the caller (FUSE handle) must ensure
-// per-handle ordering.
+// per-handle ordering. The one exception is non-sequential reads on nested
+// files when Streaming.NestedReadConcurrency > 1, which release the lock for
+// the download (see readNestedAtConcurrently).
 func (mvf *MetadataVirtualFile) ReadAtContext(readCtx context.Context, p []byte, off int64) (n int, err error) {
 	if len(p) == 0 {
 		return 0, nil
@@ -1082,7 +1720,13 @@ func (mvf *MetadataVirtualFile) ReadAtContext(readCtx context.Context, p []byte,
 		return 0, ErrFileClosed
 	}
 	if off >= mvf.meta.FileSize {
-		return 0, io.EOF
+		grew, err := mvf.waitForGrowth(readCtx, off)
+		if err != nil {
+			return 0, err
+		}
+		if !grew {
+			return 0, io.EOF
+		}
 	}
 
 	// Determine whether this offset can reuse the shared reader.
@@ -1101,7 +1745,7 @@ func (mvf *MetadataVirtualFile) ReadAtContext(readCtx context.Context, p []byte,
 		(mvf.readAtSharedNext == 0 && !mvf.readerInitialized && off == mvf.position)
 
 	if useShared {
-		if err := mvf.ensureReader(); err != nil {
+		if err := mvf.ensureReader(int64(len(p))); err != nil {
 			return 0, err
 		}
 
@@ -1131,18 +1775,12 @@ func (mvf *MetadataVirtualFile) ReadAtContext(readCtx context.Context, p []byte,
 			rn, readErr := mvf.reader.Read(buf[n:])
 			n += rn
 
-			if n > 0 && mvf.streamTracker != nil && mvf.streamID != "" {
-				mvf.streamTracker.UpdateProgress(mvf.streamID, int64(rn))
-				mvf.streamTracker.UpdateCurrentOffset(mvf.streamID, off+int64(n))
-				if mvf.bufOffReader != nil {
-					mvf.streamTracker.UpdateBufferedOffset(mvf.streamID, mvf.bufOffReader.GetBufferedOffset())
-				}
-			}
+			mvf.recordProgress(rn, off+int64(n))
 
 			if readErr != nil {
 				if errors.Is(readErr, io.EOF) && mvf.hasMoreDataToRead() {
 					mvf.closeCurrentReader()
-					if err := mvf.ensureReader(); err != nil {
+					if err := mvf.ensureReader(want - int64(n)); err != nil {
 						break
 					}
 					continue
@@ -1151,9 +1789,12 @@ func (mvf *MetadataVirtualFile) ReadAtContext(readCtx context.Context, p []byte,
 			}
 		}
 
+		mvf.feedChecksum(buf[:n], off)
+
 		// Advance the shared cursor so the next sequential call hits this path again.
 		mvf.readAtSharedNext = off + int64(n)
 		mvf.ephemeralStreak = 0 // sequential read — reset scrub counter
+		mvf.widenPrefetch()
 		return n, nil
 	}
 
@@ -1200,6 +1841,10 @@ ephemeral:
 		return n, nil
 	}
 
+	if mvf.nestedReadSlots != nil && len(mvf.meta.NestedSources) > 0 && !mvf.remuxActive() {
+		return mvf.readNestedAtConcurrently(readCtx, p, off, end)
+	}
+
 	reader, err := mvf.createReaderAtOffset(off, end)
 	if err != nil {
 		return 0, err
@@ -1211,6 +1856,7 @@ ephemeral:
 	if err == io.ErrUnexpectedEOF {
 		err = nil
 	}
+	mvf.feedChecksum(buf[:n], off)
 
 	// Only update the shared cursor when the shared reader was torn down.
 	// If it is still alive, readAtSharedNext already points to the reader's
@@ -1222,6 +1868,46 @@ ephemeral:
 	return n, err
 }
 
+// readNestedAtConcurrently serves a non-sequential ReadAt on a nested file
+// without holding mvf.mu for the download, so up to cap(nestedReadSlots) reads
+// at different offsets proceed in parallel. The reader (and its snapshot of the
+// nested sources) is built under the lock; the lock is released only for the
+// read itself and re-acquired before returning. Caller must hold mvf.mu.
+func (mvf *MetadataVirtualFile) readNestedAtConcurrently(readCtx context.Context, p []byte, off, end int64) (int, error) {
+	if mvf.poolManager == nil {
+		return 0, ErrNoUsenetPool
+	}
+	reader, err := mvf.createNestedReader(off, end)
+	if err != nil {
+		return 0, err
+	}
+	slots := mvf.nestedReadSlots
+
+	mvf.mu.Unlock()
+	n, err := func() (int, error) {
+		defer reader.Close()
+		select {
+		case slots <- struct{}{}:
+		case <-readCtx.Done():
+			return 0, readCtx.Err()
+		}
+		defer func() { <-slots }()
+
+		n, err := readFullContext(readCtx, reader, p[:end-off+1])
+		if err == io.ErrUnexpectedEOF {
+			err = nil
+		}
+		return n, err
+	}()
+	mvf.mu.Lock()
+
+	// The file may have been closed while the lock was released.
+	if mvf.meta != nil && !mvf.readerInitialized {
+		mvf.readAtSharedNext = off + int64(n)
+	}
+	return n, err
+}
+
 // tryServeFromRandomReadCache attempts to satisfy a single-segment
 // ephemeral ReadAt from the per-file LRU. On miss it downloads the
 // full containing segment, caches it, then serves the requested
@@ -1429,6 +2115,7 @@ func (mvf *MetadataVirtualFile) createEncryptedReaderAtOffset(start, end int64)
 			func(ctx context.Context, s, e int64) (io.ReadCloser, error) {
 				return mvf.createUsenetReader(ctx, s, e)
 			},
+			aes.WithBufferPool(mvf.readBufPool),
 		)
 
 	default:
@@ -1472,7 +2159,12 @@ func (mvf *MetadataVirtualFile) Seek(offset int64, whence int) (int64, error) {
 	// Reset originalRangeEnd when position changes to force fresh range calculation
 	// on next read. This prevents stale range information from being reused after seek.
 	if abs != mvf.position {
+		if mvf.adaptivePrefetch {
+			mvf.seeks.record(mvf.position, abs)
+		}
 		mvf.originalRangeEnd = 0
+		// Publish batched reads first so their offset cannot overwrite this one.
+		mvf.flushProgress()
 		if mvf.streamTracker != nil && mvf.streamID != "" {
 			mvf.streamTracker.UpdateCurrentOffset(mvf.streamID, abs)
 		}
@@ -1498,9 +2190,14 @@ func (mvf *MetadataVirtualFile) Close() error {
 	// use to read streamID. Without this, the race detector flags an
 	// unsynchronized read/write between Close and a concurrent Read.
 	if mvf.streamTracker != nil && mvf.streamID != "" {
+		mvf.flushProgress()
 		mvf.streamTracker.Remove(mvf.streamID)
 		mvf.streamID = ""
 	}
+	if mvf.releaseStream != nil {
+		mvf.releaseStream()
+		mvf.releaseStream = nil
+	}
 	if mvf.reader != nil {
 		mvf.reader.Close()
 		mvf.setReader(nil)
@@ -1555,11 +2252,13 @@ func (mvf *MetadataVirtualFile) Readdirnames(n int) ([]string, error) {
 // Stat implements afero.File.Stat
 func (mvf *MetadataVirtualFile) Stat() (fs.FileInfo, error) {
 	info := &MetadataFileInfo{
-		name:    filepath.Base(mvf.name),
-		size:    mvf.meta.FileSize,
-		mode:    0644,
-		modTime: time.Unix(mvf.meta.ModifiedAt, 0),
-		isDir:   false, // Files are never directories in simplified schema
+		name:        filepath.Base(mvf.name),
+		size:        mvf.meta.FileSize,
+		mode:        0644,
+		modTime:     time.Unix(mvf.meta.ModifiedAt, 0),
+		isDir:       false, // Files are never directories in simplified schema
+		sizeUnknown: mvf.meta.SizeUnknown,
+		contentType: mvf.contentType(),
 	}
 
 	return info, nil
@@ -1619,6 +2318,8 @@ func (mvf *MetadataVirtualFile) closeCurrentReader() {
 	}
 	mvf.readerInitialized = false
 	mvf.ephemeralStreak = 0
+	mvf.readerPrefetch = 0
+	mvf.narrowReader, mvf.narrowReads = nil, 0
 }
 
 // closerWorkerCount bounds the number of background reader-Close
@@ -1632,10 +2333,11 @@ const closerWorkerCount = 4
 // mvf.mu (so the lazy init is safe).
 func (mvf *MetadataVirtualFile) enqueueCloser(r io.Closer) {
 	if mvf.closerCh == nil {
-		mvf.closerCh = make(chan io.Closer, closerWorkerCount)
+		ch := make(chan io.Closer, closerWorkerCount)
+		mvf.closerCh = ch
 		for i := 0; i < closerWorkerCount; i++ {
 			mvf.closeWg.Go(func() {
-				for c := range mvf.closerCh {
+				for c := range ch {
 					_ = c.Close()
 				}
 			})
@@ -1657,7 +2359,10 @@ func (mvf *MetadataVirtualFile) enqueueCloser(r io.Closer) {
 }
 
 // ensureReader ensures we have a reader initialized for the current position with range support
-func (mvf *MetadataVirtualFile) ensureReader() error {
+//
+// readLen is the size of the read that needs the reader; for small files it
+// bounds how far the new reader prefetches (see smallFilePrefetch).
+func (mvf *MetadataVirtualFile) ensureReader(readLen int64) error {
 	if mvf.readerInitialized {
 		return nil
 	}
@@ -1666,6 +2371,8 @@ func (mvf *MetadataVirtualFile) ensureReader() error {
 		return ErrNoUsenetPool
 	}
 
+	mvf.narrowReader, mvf.narrowReads = nil, 0
+
 	// Get request range from args or use default range starting from current position
 	start, end := mvf.getRequestRange()
 
@@ -1682,6 +2389,7 @@ func (mvf *MetadataVirtualFile) ensureReader() error {
 		(end < 0 || mvf.readAtSharedNext <= end) {
 		start = mvf.readAtSharedNext
 	}
+	mvf.readerPrefetch = mvf.smallFilePrefetch(start, end, readLen)
 
 	// For multi-clip BD main features, expand the underlying window outward to
 	// the BDAV packet grid so the remux rewrites whole packets; we trim back to
@@ -1694,6 +2402,8 @@ func (mvf *MetadataVirtualFile) ensureReader() error {
 	if remux {
 		rawStart = alignStartDown(mvf.clipSpans, start)
 		rawEnd = alignEndUp(mvf.clipSpans, end, mvf.meta.FileSize)
+	} else if mvf.prefetchBackward() {
+		rawStart, mvf.readerPrefetch = mvf.backwardWindow(start, end, readLen)
 	}
 
 	// Create reader for the calculated range using metadata segments
@@ -1718,6 +2428,9 @@ func (mvf *MetadataVirtualFile) ensureReader() error {
 			return err
 		}
 		mvf.setReader(ur)
+		if mvf.readerPrefetch > 0 {
+			mvf.narrowReader, _ = ur.(prefetchWidener)
+		}
 	}
 
 	// Apply the continuous-timeline remux for multi-clip BD main features, then
@@ -1727,6 +2440,10 @@ func (mvf *MetadataVirtualFile) ensureReader() error {
 	if remux {
 		mvf.reader = newTSRemuxReader(mvf.reader, mvf.clipSpans, rawStart)
 		mvf.reader = newSkipLimitReader(mvf.reader, start-rawStart, end-start+1)
+	} else if rawStart < start {
+		// A backward-biased reader opened behind start to fill the segment
+		// cache; those bytes are not part of this read.
+		mvf.reader = newSkipLimitReader(mvf.reader, start-rawStart, end-start+1)
 	}
 	mvf.bufOffReader, _ = mvf.reader.(interface{ GetBufferedOffset() int64 })
 
@@ -1734,6 +2451,73 @@ func (mvf *MetadataVirtualFile) ensureReader() error {
 	return nil
 }
 
+// smallFilePrefetchMargin is how many segments past the opening read a small
+// file's reader may prefetch, so a follow-up read straight after a header probe
+// does not stall.
+const smallFilePrefetchMargin = 2
+
+// smallFilePrefetch returns the prefetch ceiling for a reader opened over
+// [start, end] by a read of readLen bytes, or 0 to use maxPrefetch. Only flat
+// files below Streaming.SmallFileThreshold are capped: for them maxPrefetch
+// segments is often the whole file, while a probe only needs the header.
+func (mvf *MetadataVirtualFile) smallFilePrefetch(start, end, readLen int64) int {
+	if mvf.smallFileThreshold <= 0 || mvf.meta.FileSize >= mvf.smallFileThreshold ||
+		readLen <= 0 || len(mvf.meta.SegmentData) == 0 {
+		return 0
+	}
+
+	mvf.segmentIndexOnce.Do(func() {
+		mvf.segmentIndex = buildSegmentIndex(mvf.meta.SegmentData)
+	})
+	first := mvf.segmentIndex.findSegmentForOffset(start)
+	last := mvf.segmentIndex.findSegmentForOffset(min(end, start+readLen-1))
+	if first < 0 || last < first {
+		return 0
+	}
+
+	ceiling := last - first + 1 + smallFilePrefetchMargin
+	if mvf.maxPrefetch > 0 && ceiling >= mvf.maxPrefetch {
+		return 0
+	}
+	return ceiling
+}
+
+// prefetchWidener is a reader whose prefetch depth can be raised after it
+// was opened.
+type prefetchWidener interface {
+	SetMaxPrefetch(n int)
+}
+
+// readsToWidenPrefetch is how many reads in a row a reader opened with a
+// narrowed prefetch window serves before it gets the full maxPrefetch.
+const readsToWidenPrefetch = 2
+
+// widenPrefetch counts a read served by the current reader. A reader opened
+// with a narrowed window (a small file's probe or a backward-biased seek)
+// that keeps being read in order is playing forward, so once it has served
+// readsToWidenPrefetch reads its prefetch goes back to maxPrefetch. Callers
+// must hold mvf.mu.
+func (mvf *MetadataVirtualFile) widenPrefetch() {
+	if mvf.narrowReader == nil {
+		return
+	}
+	mvf.narrowReads++
+	if mvf.narrowReads < readsToWidenPrefetch {
+		return
+	}
+	mvf.narrowReader.SetMaxPrefetch(mvf.maxPrefetch)
+	mvf.narrowReader = nil
+	mvf.readerPrefetch = 0
+}
+
+// prefetch is the segment prefetch depth for the reader being opened.
+func (mvf *MetadataVirtualFile) prefetch() int {
+	if mvf.readerPrefetch > 0 {
+		return mvf.readerPrefetch
+	}
+	return mvf.maxPrefetch
+}
+
 // getRequestRange gets the range for reader creation based on HTTP range or current position
 // Implements intelligent range limiting to prevent excessive memory usage when end=-1 or ranges are too large
 func (mvf *MetadataVirtualFile) getRequestRange() (start, end int64) {
@@ -1766,6 +2550,59 @@ func (mvf *MetadataVirtualFile) getRequestRange() (start, end int64) {
 	return mvf.position, targetEnd
 }
 
+// segmentFetchTimeout returns the configured per-segment fetch deadline, or 0
+// to keep the reader default.
+func (mvf *MetadataVirtualFile) segmentFetchTimeout() time.Duration {
+	if mvf.configGetter == nil {
+		return 0
+	}
+	return time.Duration(mvf.configGetter().Streaming.SegmentFetchTimeoutSeconds) * time.Second
+}
+
+// maxInFlightBytes returns Streaming.MaxInFlightBytes, or 0 (unlimited).
+func (mvf *MetadataVirtualFile) maxInFlightBytes() int64 {
+	if mvf.configGetter == nil {
+		return 0
+	}
+	return mvf.configGetter().Streaming.MaxInFlightBytes
+}
+
+// missingSegmentAbortFraction returns Streaming.MissingSegmentAbortFraction,
+// or 0 (never abort early).
+func (mvf *MetadataVirtualFile) missingSegmentAbortFraction() float64 {
+	if mvf.configGetter == nil {
+		return 0
+	}
+	return mvf.configGetter().Streaming.MissingSegmentAbortFraction
+}
+
+// readPoolGetter returns the client source for a file's reads: its preferred
+// provider backed by the shared pool when it names one and the pool manager
+// supports it, otherwise the shared pool alone, bounded by the acquire
+// timeout when the manager supports one. nil when there is no pool.
+func readPoolGetter(pm pool.Manager, preferredProvider string) func() (pool.NntpClient, error) {
+	if pm == nil {
+		return nil
+	}
+	if pg, ok := pm.(pool.PreferredPoolGetter); ok && preferredProvider != "" {
+		return func() (pool.NntpClient, error) { return pg.GetPreferredPool(preferredProvider) }
+	}
+	if sg, ok := pm.(pool.StreamPoolGetter); ok {
+		return sg.GetStreamPool
+	}
+	return pm.GetPool
+}
+
+// poolGetter returns the client source worked out when the handle was opened.
+// It never reads mvf.meta, so the unlocked nested read paths can call it
+// while Close runs.
+func (mvf *MetadataVirtualFile) poolGetter() func() (pool.NntpClient, error) {
+	if mvf.readPool != nil {
+		return mvf.readPool
+	}
+	return mvf.poolManager.GetPool
+}
+
 // createUsenetReader creates a new usenet reader for the specified range using metadata segments
 func (mvf *MetadataVirtualFile) createUsenetReader(ctx context.Context, start, end int64) (io.ReadCloser, error) {
 	if len(mvf.meta.SegmentData) == 0 {
@@ -1783,7 +2620,7 @@ func (mvf *MetadataVirtualFile) createUsenetReader(ctx context.Context, start, e
 	// Use O(log n) binary search to find segment boundaries, then create a lazy
 	// range with O(1) initialization. Corrupt metadata (index returning -1) results
 	// in an empty range caught by HasSegments() below.
-	startSegIdx := mvf.segmentIndex.findSegmentForOffset(start)
+	startSegIdx := mvf.segmentIndex.startSegmentFor(mvf.startSegment, start)
 	startFilePos := mvf.segmentIndex.getOffsetForSegment(startSegIdx)
 	endSegIdx := mvf.segmentIndex.findSegmentForOffset(end)
 	endFilePos := mvf.segmentIndex.getOffsetForSegment(endSegIdx)
@@ -1817,8 +2654,12 @@ func (mvf *MetadataVirtualFile) createUsenetReader(ctx context.Context, start, e
 	// Hole hooks enable on-the-fly zero-fill of confirmed-missing segments
 	// for eligible video files (nil for everything else — reads fail as
 	// always). See holes.go.
-	ur, err := usenet.NewUsenetReader(ctx, mvf.poolManager.GetPool, rg, mvf.maxPrefetch, mvf.streamTracker, mvf.streamID, mvf.segmentStore,
-		usenet.WithHoleHooks(mvf.holeHooks()))
+	ur, err := usenet.NewUsenetReader(ctx, mvf.poolGetter(), rg, mvf.prefetch(), mvf.streamTracker, mvf.streamID, mvf.segmentStore,
+		usenet.WithHoleHooks(mvf.holeHooks()),
+		usenet.WithSegmentFetchTimeout(mvf.segmentFetchTimeout()),
+		usenet.WithMaxInFlightBytes(mvf.maxInFlightBytes()),
+		usenet.WithMissingSegmentAbort(mvf.missingSegmentAbortFraction()),
+		usenet.WithSegmentTrace(mvf.segmentTrace))
 	if err != nil {
 		return nil, err
 	}
@@ -1886,48 +2727,91 @@ func (mvf *MetadataVirtualFile) createNestedReader(start, end int64) (io.ReadClo
 		return nil, fmt.Errorf("no nested sources cover range [%d, %d]", start, end)
 	}
 
-	return &lazyNestedMultiReader{mvf: mvf, specs: specs}, nil
+	return &lazyNestedMultiReader{mvf: mvf, specs: specs, streamID: mvf.streamID}, nil
 }
 
 // createNestedSourceReader creates a reader for a single NestedSegmentSource,
 // starting at innerStart within the decrypted inner volume and reading readLen bytes.
+// The inner volume is decrypted according to src.Encryption; sources written
+// before that field existed carry only an AES key, which still selects AES.
 func (mvf *MetadataVirtualFile) createNestedSourceReader(
 	src *metapb.NestedSegmentSource,
 	innerStart int64,
 	readLen int64,
+	streamID string,
 ) (io.ReadCloser, error) {
 	absoluteStart := src.InnerOffset + innerStart
+	rh := &utils.RangeHeader{
+		Start: absoluteStart,
+		End:   absoluteStart + readLen - 1,
+	}
+	openSegments := func(ctx context.Context, s, e int64) (io.ReadCloser, error) {
+		return mvf.createUsenetReaderFromSegments(ctx, streamID, src.Segments, s, e)
+	}
+
+	encryption := src.Encryption
+	if encryption == metapb.Encryption_NONE && len(src.AesKey) > 0 {
+		encryption = metapb.Encryption_AES
+	}
+
+	switch encryption {
+	case metapb.Encryption_NONE:
+		// Unencrypted source: read directly from segments at inner offset
+		return openSegments(mvf.ctx, rh.Start, rh.End)
 
-	if len(src.AesKey) > 0 {
+	case metapb.Encryption_AES:
 		// Encrypted source: decrypt with AES-CBC then read at inner offset
 		if mvf.aesCipher == nil {
 			return nil, ErrNoCipherConfig
 		}
-
-		rh := &utils.RangeHeader{
-			Start: absoluteStart,
-			End:   absoluteStart + readLen - 1,
-		}
-
 		return mvf.aesCipher.Open(
 			mvf.ctx,
 			rh,
 			src.InnerVolumeSize,
 			src.AesKey,
 			src.AesIv,
-			func(ctx context.Context, s, e int64) (io.ReadCloser, error) {
-				return mvf.createUsenetReaderFromSegments(ctx, src.Segments, s, e)
-			},
+			openSegments,
+			aes.WithBufferPool(mvf.readBufPool),
 		)
-	}
 
-	// Unencrypted source: read directly from segments at inner offset
-	return mvf.createUsenetReaderFromSegments(mvf.ctx, src.Segments, absoluteStart, absoluteStart+readLen-1)
+	case metapb.Encryption_RCLONE:
+		if mvf.rcloneCipher == nil {
+			return nil, ErrNoCipherConfig
+		}
+
+		// Per-source credentials, with global fallback
+		password := src.Password
+		if password == "" {
+			password = mvf.globalPassword
+		}
+		salt := src.Salt
+		if salt == "" {
+			salt = mvf.globalSalt
+		}
+
+		decryptedReader, err := mvf.rcloneCipher.Open(
+			mvf.ctx,
+			rh,
+			src.InnerVolumeSize,
+			password,
+			salt,
+			openSegments,
+		)
+		if err != nil {
+			return nil, fmt.Errorf(ErrMsgFailedCreateDecryptReader, err)
+		}
+		return decryptedReader, nil
+
+	default:
+		return nil, fmt.Errorf("unsupported nested source encryption type: %v", encryption)
+	}
 }
 
 // createUsenetReaderFromSegments creates a usenet reader from a specific set of segments
 // (used for nested source reading where segments differ from the main file metadata).
-func (mvf *MetadataVirtualFile) createUsenetReaderFromSegments(ctx context.Context, segments []*metapb.SegmentData, start, end int64) (io.ReadCloser, error) {
+// streamID is passed in rather than read from mvf because nested readers may be
+// opened after mvf.mu has been released (see readNestedAtConcurrently).
+func (mvf *MetadataVirtualFile) createUsenetReaderFromSegments(ctx context.Context, streamID string, segments []*metapb.SegmentData, start, end int64) (io.ReadCloser, error) {
 	if len(segments) == 0 {
 		return nil, ErrMissmatchedSegments
 	}
@@ -1946,7 +2830,11 @@ func (mvf *MetadataVirtualFile) createUsenetReaderFromSegments(ctx context.Conte
 		return nil, fmt.Errorf("no segments cover range [%d, %d]", start, end)
 	}
 
-	ur, err := usenet.NewUsenetReader(ctx, mvf.poolManager.GetPool, rg, mvf.maxPrefetch, mvf.streamTracker, mvf.streamID, mvf.segmentStore)
+	ur, err := usenet.NewUsenetReader(ctx, mvf.poolGetter(), rg, mvf.maxPrefetch, mvf.streamTracker, streamID, mvf.segmentStore,
+		usenet.WithSegmentFetchTimeout(mvf.segmentFetchTimeout()),
+		usenet.WithMaxInFlightBytes(mvf.maxInFlightBytes()),
+		usenet.WithMissingSegmentAbort(mvf.missingSegmentAbortFraction()),
+		usenet.WithSegmentTrace(mvf.segmentTrace))
 	if err != nil {
 		return nil, err
 	}
@@ -1965,10 +2853,11 @@ type nestedSourceSpec struct {
 // This prevents all inner volumes from being opened simultaneously, which would cause
 // all their segments to be prefetched concurrently and spike memory usage.
 type lazyNestedMultiReader struct {
-	mvf     *MetadataVirtualFile
-	specs   []nestedSourceSpec
-	idx     int
-	current io.ReadCloser
+	mvf      *MetadataVirtualFile
+	specs    []nestedSourceSpec
+	streamID string // snapshot of mvf.streamID taken when the reader was built
+	idx      int
+	current  io.ReadCloser
 }
 
 func (r *lazyNestedMultiReader) Read(p []byte) (int, error) {
@@ -1978,7 +2867,7 @@ func (r *lazyNestedMultiReader) Read(p []byte) (int, error) {
 				return 0, io.EOF
 			}
 			spec := r.specs[r.idx]
-			rc, err := r.mvf.createNestedSourceReader(spec.src, spec.localStart, spec.readLen)
+			rc, err := r.mvf.createNestedSourceReader(spec.src, spec.localStart, spec.readLen, r.streamID)
 			if err != nil {
 				return 0, err
 			}
@@ -2069,6 +2958,7 @@ func (mvf *MetadataVirtualFile) wrapWithEncryption(start, end int64) (io.ReadClo
 				// Create usenet reader first for encrypted data
 				return mvf.createUsenetReader(ctx, s, e)
 			},
+			aes.WithBufferPool(mvf.readBufPool),
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to create AES decrypt reader: %w", err)
@@ -2112,14 +3002,15 @@ func (mvf *MetadataVirtualFile) updateFileHealthOnError(dataCorruptionErr *usene
 	isDegraded := healthEnabled && classification != nil &&
 		classification.Verdict == holes.VerdictDegraded
 
-	// Increment failure count for tracking/masking if explicitly enabled with a valid
-	// threshold. Masking must be opt-in: Enabled == nil means disabled (not on-by-default),
-	// and Threshold <= 0 would make every file immediately masked (count+1 >= 0 is always
-	// true), suppressing all repairs. Degraded files are also exempt: masking a still-
-	// playable file defeats the point of keeping it available.
+	// Increment failure count for tracking/masking if explicitly enabled. Masking must be
+	// opt-in: Enabled == nil means disabled (not on-by-default). The threshold is resolved
+	// by the repository — a runtime override wins over the configured value, and with no
+	// positive threshold nothing is masked (count+1 >= 0 would mask every file). Degraded
+	// files are also exempt: masking a still-playable file defeats the point of keeping
+	// it available.
 	shouldRepair := true
 	isMasked := false
-	if !isDegraded && cfg.Streaming.FailureMasking.Enabled != nil && *cfg.Streaming.FailureMasking.Enabled && cfg.Streaming.FailureMasking.Threshold > 0 {
+	if !isDegraded && cfg.Streaming.FailureMasking.Enabled != nil && *cfg.Streaming.FailureMasking.Enabled {
 		var err error
 		isMasked, shouldRepair, err = mvf.healthRepository.IncrementStreamingFailureCount(ctx, mvf.name, cfg.Streaming.FailureMasking.Threshold)
 		if err != nil {
@@ -2309,17 +3200,25 @@ func (mrf *MetadataRemoteFile) isValidEmptyDirectory(normalizedPath string) bool
 }
 
 func (mrf *MetadataRemoteFile) Mkdir(ctx context.Context, name string, perm os.FileMode) error {
+	if _, ok := mrf.parseCorruptedView(normalizePath(name)); ok {
+		return os.ErrPermission
+	}
 	return mrf.metadataService.CreateDirectory(name)
 }
 
 func (mrf *MetadataRemoteFile) MkdirAll(ctx context.Context, name string, perm os.FileMode) error {
+	if _, ok := mrf.parseCorruptedView(normalizePath(name)); ok {
+		return os.ErrPermission
+	}
 	return mrf.metadataService.CreateDirectory(name)
 }
 
-// resolveIDPath resolves a sharded ID path (.ids/...) to the actual virtual path
-func (mrf *MetadataRemoteFile) resolveIDPath(idPath string) (string, error) {
+// resolveIDPath resolves a sharded ID path (.ids/...) to the actual virtual path.
+// When the symlink is broken or missing and Metadata.RepairIDSymlinks is on,
+// the file is looked up by its ID sidecar and the symlink is repointed at it.
+func (mrf *MetadataRemoteFile) resolveIDPath(ctx context.Context, idPath string) (string, error) {
 	cfg := mrf.configGetter()
-	metadataRoot := cfg.Metadata.RootPath
+	metadataRoot := mrf.metadataService.RootPath()
 
 	// The idPath is like .ids/4/0/e/9/a/40e9a6c9-e922-4217-ab6c-9d2207528a78
 	// The metadata file is at metadataRoot/.ids/4/0/e/9/a/40e9a6c9-e922-4217-ab6c-9d2207528a78.meta
@@ -2327,6 +3226,82 @@ func (mrf *MetadataRemoteFile) resolveIDPath(idPath string) (string, error) {
 	// Ensure it has .meta extension for the check
 	fullIdPath := filepath.Join(metadataRoot, idPath+".meta")
 
+	virtualPath, err := readIDSymlink(metadataRoot, fullIdPath)
+	if err == nil && mrf.metadataService.FileExists(virtualPath) {
+		return virtualPath, nil
+	}
+	if !cfg.Metadata.ShouldRepairIDSymlinks() {
+		return virtualPath, err
+	}
+
+	// A repair scans the whole metadata tree, so only well-formed IDs get
+	// one, and an ID the scan missed is not scanned for again for a while.
+	nzbdavID, ok := parseIDPath(idPath)
+	if !ok {
+		return "", fs.ErrNotExist
+	}
+	if mrf.idMisses != nil && mrf.idMisses.Contains(nzbdavID) {
+		return "", fs.ErrNotExist
+	}
+	found, findErr := mrf.metadataService.FindFileByNzbdavID(ctx, nzbdavID)
+	if findErr != nil {
+		return "", fmt.Errorf("failed to search metadata for ID %s: %w", nzbdavID, findErr)
+	}
+	if found == "" {
+		if mrf.idMisses != nil {
+			mrf.idMisses.Add(nzbdavID, struct{}{})
+		}
+		if err != nil {
+			return "", err
+		}
+		return "", fs.ErrNotExist
+	}
+
+	if repairErr := mrf.metadataService.RepairIDSymlink(nzbdavID, found); repairErr != nil {
+		slog.WarnContext(ctx, "Failed to repair ID symlink", "id", nzbdavID, "path", found, "error", repairErr)
+	} else {
+		slog.InfoContext(ctx, "Repaired stale ID symlink", "id", nzbdavID, "stale_target", virtualPath, "path", found)
+	}
+	return found, nil
+}
+
+// idMissTTL is how long an ID the metadata scan did not find is answered as
+// missing without scanning again; idMissCacheSize bounds how many are kept.
+const (
+	idMissTTL       = time.Minute
+	idMissCacheSize = 4096
+)
+
+// idMissCache remembers IDs the metadata scan did not find, for idMissTTL.
+type idMissCache = expirable.LRU[string, struct{}]
+
+func newIDMissCache() *idMissCache {
+	return expirable.NewLRU[string, struct{}](idMissCacheSize, nil, idMissTTL)
+}
+
+// parseIDPath returns the ID of a sharded ID path (.ids/4/0/e/9/a/<id>) whose
+// five shard directories are the ID's first five characters, as
+// MetadataService writes them. Anything else cannot name an ID symlink.
+func parseIDPath(idPath string) (string, bool) {
+	parts := strings.Split(strings.TrimPrefix(idPath, ".ids/"), "/")
+	if len(parts) != 6 {
+		return "", false
+	}
+	id := parts[5]
+	if len(id) < 5 {
+		return "", false
+	}
+	for i, shard := range parts[:5] {
+		if shard != id[i:i+1] {
+			return "", false
+		}
+	}
+	return id, true
+}
+
+// readIDSymlink returns the virtual path an .ids/ symlink points at, without
+// checking that the target still exists.
+func readIDSymlink(metadataRoot, fullIdPath string) (string, error) {
 	// Read the symlink
 	target, err := os.Readlink(fullIdPath)
 	if err != nil {
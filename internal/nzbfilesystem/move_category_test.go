@@ -0,0 +1,290 @@
+package nzbfilesystem
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/javi11/altmount/internal/config"
+	"github.com/javi11/altmount/internal/database"
+	"github.com/javi11/altmount/internal/metadata"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newCategoryRemoteFile(t *testing.T) (*MetadataRemoteFile, *database.HealthRepository, string) {
+	t.Helper()
+	repo, _, ms := setupStreamHealthEnv(t)
+
+	cfg := config.DefaultConfig()
+	cfg.SABnzbd.CompleteDir = "complete"
+	cfg.SABnzbd.Categories = []config.SABnzbdCategory{
+		{Name: "tv", Dir: "tv"},
+		{Name: "anime", Dir: "anime-shows"},
+	}
+
+	return &MetadataRemoteFile{
+		metadataService:  ms,
+		healthRepository: repo,
+		configGetter:     func() *config.Config { return cfg },
+	}, repo, ms.GetMetadataDirectoryPath("")
+}
+
+func TestMoveToCategory_UpdatesMetadataHealthAndIDSymlink(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("category move repoints the file's .ids symlink")
+	}
+	mrf, repo, root := newCategoryRemoteFile(t)
+	ctx := context.Background()
+	ms := mrf.metadataService
+
+	oldPath := "complete/tv/Show/Show.S01E01.mkv"
+	writeStreamMeta(t, ms, oldPath)
+
+	// Importer-style .id sidecar plus sharded .ids/ symlink.
+	const nzbdavID = "40e9a6c9-1111-2222-3333-444455556666"
+	require.NoError(t, os.WriteFile(ms.GetMetadataFilePath(oldPath)+".id", []byte(nzbdavID), 0644))
+	linkPath := filepath.Join(root, ".ids", "4", "0", "e", "9", "a", nzbdavID+".meta")
+	require.NoError(t, os.MkdirAll(filepath.Dir(linkPath), 0755))
+	require.NoError(t, os.Symlink(ms.GetMetadataFilePath(oldPath), linkPath))
+
+	require.NoError(t, repo.AddFileToHealthCheck(ctx, oldPath, nil, 3, 3, nil, database.HealthPriorityNormal))
+
+	newPath, err := mrf.MoveToCategory(ctx, oldPath, "anime")
+	require.NoError(t, err)
+	assert.Equal(t, "complete/anime-shows/Show/Show.S01E01.mkv", newPath)
+
+	// Metadata moved.
+	assert.False(t, ms.FileExists(oldPath))
+	meta, err := ms.ReadFileMetadata(newPath)
+	require.NoError(t, err)
+	require.NotNil(t, meta)
+	assert.Equal(t, nzbdavID, meta.NzbdavId)
+
+	// Health record follows the file.
+	fh, err := repo.GetFileHealth(ctx, newPath)
+	require.NoError(t, err)
+	require.NotNil(t, fh)
+	old, err := repo.GetFileHealth(ctx, oldPath)
+	require.NoError(t, err)
+	assert.Nil(t, old)
+
+	// ID symlink resolves to the new .meta file.
+	resolved, err := filepath.EvalSymlinks(linkPath)
+	require.NoError(t, err)
+	expected, err := filepath.EvalSymlinks(ms.GetMetadataFilePath(newPath))
+	require.NoError(t, err)
+	assert.Equal(t, expected, resolved)
+}
+
+func TestMoveToCategory_Rejections(t *testing.T) {
+	mrf, _, _ := newCategoryRemoteFile(t)
+	ctx := context.Background()
+
+	writeStreamMeta(t, mrf.metadataService, "complete/tv/a.mkv")
+	writeStreamMeta(t, mrf.metadataService, "complete/anime-shows/a.mkv")
+	writeStreamMeta(t, mrf.metadataService, "other/b.mkv")
+
+	_, err := mrf.MoveToCategory(ctx, "complete/tv", "anime")
+	assert.ErrorIs(t, err, os.ErrPermission, "category folders cannot be moved")
+
+	_, err = mrf.MoveToCategory(ctx, "complete/tv/a.mkv", "music")
+	assert.Error(t, err, "unknown category")
+
+	_, err = mrf.MoveToCategory(ctx, "complete/tv/a.mkv", "anime")
+	assert.ErrorIs(t, err, os.ErrExist, "destination already exists")
+
+	_, err = mrf.MoveToCategory(ctx, "other/b.mkv", "anime")
+	assert.Error(t, err, "file outside complete_dir")
+
+	_, err = mrf.MoveToCategory(ctx, "complete/tv/missing.mkv", "anime")
+	assert.ErrorIs(t, err, os.ErrNotExist)
+
+	newPath, err := mrf.MoveToCategory(ctx, "complete/tv/a.mkv", "tv")
+	require.NoError(t, err)
+	assert.Equal(t, "complete/tv/a.mkv", newPath, "moving into the current category is a no-op")
+}
+
+func TestMoveToCategory_NestedCategoryDir(t *testing.T) {
+	mrf, _, _ := newCategoryRemoteFile(t)
+	ctx := context.Background()
+	cfg := mrf.configGetter()
+	cfg.SABnzbd.Categories = append(cfg.SABnzbd.Categories, config.SABnzbdCategory{Name: "movies", Dir: "media/movies"})
+
+	assert.True(t, mrf.isCategoryFolder("complete/media/movies"))
+	assert.True(t, mrf.isCategoryFolder("Complete/Media/Movies"), "protection is case-insensitive")
+	assert.False(t, mrf.isCategoryFolder("complete/media"))
+
+	writeStreamMeta(t, mrf.metadataService, "complete/media/movies/Film/Film.mkv")
+
+	_, err := mrf.MoveToCategory(ctx, "complete/media/movies", "tv")
+	assert.ErrorIs(t, err, os.ErrPermission, "mapped category folders cannot be moved")
+
+	newPath, err := mrf.MoveToCategory(ctx, "complete/media/movies/Film/Film.mkv", "tv")
+	require.NoError(t, err)
+	assert.Equal(t, "complete/tv/Film/Film.mkv", newPath)
+
+	newPath, err = mrf.MoveToCategory(ctx, newPath, "movies")
+	require.NoError(t, err)
+	assert.Equal(t, "complete/media/movies/Film/Film.mkv", newPath)
+}
+
+func TestRenameFile_DirectoryUpdatesHealthAndIDSymlinks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("directory rename repoints every .ids symlink below it")
+	}
+	mrf, repo, root := newCategoryRemoteFile(t)
+	ctx := context.Background()
+	ms := mrf.metadataService
+
+	const n = 25
+	links := writeLinkedShow(t, mrf, repo, root, "complete/tv/Old Show", n)
+
+	renamed, err := mrf.RenameFile(ctx, "/complete/tv/Old Show", "/complete/tv/New Show")
+	require.NoError(t, err)
+	require.True(t, renamed)
+
+	for i := range n {
+		p := fmt.Sprintf("complete/tv/New Show/Show.S01E%02d.mkv", i)
+		fh, err := repo.GetFileHealth(ctx, p)
+		require.NoError(t, err)
+		assert.NotNil(t, fh, "health record follows %s", p)
+
+		resolved, err := filepath.EvalSymlinks(links[i])
+		require.NoError(t, err, "ID symlink for %s resolves", p)
+		expected, err := filepath.EvalSymlinks(ms.GetMetadataFilePath(p))
+		require.NoError(t, err)
+		assert.Equal(t, expected, resolved)
+	}
+}
+
+// writeLinkedShow writes n episodes under dir, each with an .id sidecar, an
+// .ids/ symlink and a health record, and returns the symlink paths.
+func writeLinkedShow(t *testing.T, mrf *MetadataRemoteFile, repo *database.HealthRepository, root, dir string, n int) []string {
+	t.Helper()
+	ms := mrf.metadataService
+	links := make([]string, n)
+	for i := range n {
+		p := fmt.Sprintf("%s/Show.S01E%02d.mkv", dir, i)
+		writeStreamMeta(t, ms, p)
+		id := fmt.Sprintf("%05d-linked", i)
+		require.NoError(t, os.WriteFile(ms.GetMetadataFilePath(p)+".id", []byte(id), 0644))
+		shard := append([]string{root, ".ids"}, strings.Split(id[:5], "")...)
+		links[i] = filepath.Join(append(shard, id+".meta")...)
+		require.NoError(t, os.MkdirAll(filepath.Dir(links[i]), 0755))
+		require.NoError(t, os.Symlink(ms.GetMetadataFilePath(p), links[i]))
+		require.NoError(t, repo.AddFileToHealthCheck(context.Background(), p, nil, 3, 3, nil, database.HealthPriorityNormal))
+	}
+	return links
+}
+
+func TestRecoverInterruptedRename(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("rename recovery repoints .ids symlinks after a crash")
+	}
+	const n = 5
+	const oldDir, newDir = "complete/tv/Old Show", "complete/tv/New Show"
+
+	t.Run("crash after the directory moved", func(t *testing.T) {
+		mrf, repo, root := newCategoryRemoteFile(t)
+		ctx := context.Background()
+		ms := mrf.metadataService
+		links := writeLinkedShow(t, mrf, repo, root, oldDir, n)
+
+		// Simulate RenameFile dying right after os.Rename.
+		require.NoError(t, ms.BeginDirRename(oldDir, newDir))
+		require.NoError(t, os.Rename(ms.GetMetadataDirectoryPath(oldDir), ms.GetMetadataDirectoryPath(newDir)))
+		_, err := filepath.EvalSymlinks(links[0])
+		require.Error(t, err, "symlinks are stale before recovery")
+
+		recovered, err := mrf.RecoverInterruptedRename(ctx)
+		require.NoError(t, err)
+		assert.True(t, recovered)
+
+		for i := range n {
+			p := fmt.Sprintf("%s/Show.S01E%02d.mkv", newDir, i)
+			fh, err := repo.GetFileHealth(ctx, p)
+			require.NoError(t, err)
+			assert.NotNil(t, fh, "health record follows %s", p)
+			stale, err := repo.GetFileHealth(ctx, fmt.Sprintf("%s/Show.S01E%02d.mkv", oldDir, i))
+			require.NoError(t, err)
+			assert.Nil(t, stale)
+
+			resolved, err := filepath.EvalSymlinks(links[i])
+			require.NoError(t, err)
+			expected, err := filepath.EvalSymlinks(ms.GetMetadataFilePath(p))
+			require.NoError(t, err)
+			assert.Equal(t, expected, resolved)
+		}
+
+		pending, err := ms.PendingDirRename()
+		require.NoError(t, err)
+		assert.Nil(t, pending, "journal is cleared once repaired")
+
+		recovered, err = mrf.RecoverInterruptedRename(ctx)
+		require.NoError(t, err)
+		assert.False(t, recovered, "nothing left to recover")
+	})
+
+	t.Run("crash before the directory moved", func(t *testing.T) {
+		mrf, repo, root := newCategoryRemoteFile(t)
+		ctx := context.Background()
+		ms := mrf.metadataService
+		writeLinkedShow(t, mrf, repo, root, oldDir, 1)
+		require.NoError(t, ms.BeginDirRename(oldDir, newDir))
+
+		recovered, err := mrf.RecoverInterruptedRename(ctx)
+		require.NoError(t, err)
+		assert.True(t, recovered)
+
+		fh, err := repo.GetFileHealth(ctx, oldDir+"/Show.S01E00.mkv")
+		require.NoError(t, err)
+		assert.NotNil(t, fh, "records of a directory that never moved are untouched")
+		pending, err := ms.PendingDirRename()
+		require.NoError(t, err)
+		assert.Nil(t, pending)
+	})
+
+	t.Run("pending rename is finished before the next one", func(t *testing.T) {
+		mrf, repo, root := newCategoryRemoteFile(t)
+		ctx := context.Background()
+		ms := mrf.metadataService
+		writeLinkedShow(t, mrf, repo, root, oldDir, 1)
+		writeStreamMeta(t, ms, "complete/tv/Other/Show.S01E00.mkv")
+		require.NoError(t, repo.AddFileToHealthCheck(ctx, "complete/tv/Other/Show.S01E00.mkv", nil, 3, 3, nil, database.HealthPriorityNormal))
+
+		// A rename whose updates failed at runtime leaves its journal behind.
+		require.NoError(t, ms.BeginDirRename(oldDir, newDir))
+		require.NoError(t, os.Rename(ms.GetMetadataDirectoryPath(oldDir), ms.GetMetadataDirectoryPath(newDir)))
+		assert.ErrorIs(t, ms.BeginDirRename("complete/tv/Other", "complete/tv/Renamed"), metadata.ErrDirRenamePending)
+
+		renamed, err := mrf.RenameFile(ctx, "/complete/tv/Other", "/complete/tv/Renamed")
+		require.NoError(t, err)
+		assert.True(t, renamed)
+
+		fh, err := repo.GetFileHealth(ctx, newDir+"/Show.S01E00.mkv")
+		require.NoError(t, err)
+		assert.NotNil(t, fh, "the earlier rename's records were brought up to date")
+		fh, err = repo.GetFileHealth(ctx, "complete/tv/Renamed/Show.S01E00.mkv")
+		require.NoError(t, err)
+		assert.NotNil(t, fh)
+		pending, err := ms.PendingDirRename()
+		require.NoError(t, err)
+		assert.Nil(t, pending)
+	})
+
+	t.Run("completed rename leaves no journal", func(t *testing.T) {
+		mrf, repo, root := newCategoryRemoteFile(t)
+		writeLinkedShow(t, mrf, repo, root, oldDir, 1)
+
+		_, err := mrf.RenameFile(context.Background(), "/"+oldDir, "/"+newDir)
+		require.NoError(t, err)
+		pending, err := mrf.metadataService.PendingDirRename()
+		require.NoError(t, err)
+		assert.Nil(t, pending)
+	})
+}
@@ -0,0 +1,112 @@
+package nzbfilesystem
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	metapb "github.com/javi11/altmount/internal/metadata/proto"
+	"github.com/javi11/altmount/internal/testsupport/fakepool"
+	"github.com/javi11/altmount/internal/testsupport/segments"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newNestedTestMVF builds a MetadataVirtualFile whose content is n unencrypted
+// nested sources of one segment each, so source i is served by
+// segments.MessageID(i). slots > 1 enables concurrent nested ReadAts.
+func newNestedTestMVF(t *testing.T, fp *fakepool.Client, n, segSize, slots int) *MetadataVirtualFile {
+	t.Helper()
+	segData := buildSegmentData(t, n, segSize)
+	sources := make([]*metapb.NestedSegmentSource, n)
+	for i := range sources {
+		sources[i] = &metapb.NestedSegmentSource{
+			Segments:        segData[i : i+1],
+			InnerLength:     int64(segSize),
+			InnerVolumeSize: int64(segSize),
+		}
+	}
+	configurePoolForFile(fp, n, segSize, fakepool.SegmentBehavior{})
+
+	mvf := &MetadataVirtualFile{
+		name: "test-nested-file",
+		meta: &fileHandleMeta{
+			FileSize:      int64(n * segSize),
+			NestedSources: sources,
+		},
+		poolManager:      newFakePoolManager(fp),
+		ctx:              context.Background(),
+		maxPrefetch:      1,
+		originalRangeEnd: -1,
+		streamTracker:    noopStreamTracker{},
+		streamID:         "test-stream",
+	}
+	if slots > 1 {
+		mvf.nestedReadSlots = make(chan struct{}, slots)
+	}
+	t.Cleanup(func() { _ = mvf.Close() })
+	return mvf
+}
+
+// readNestedSourcesInParallel issues one non-sequential ReadAt into each of
+// sources 1..n-1 (source 0 is skipped so no read lands on the sequential
+// cursor) and returns a wait function that checks every payload.
+func readNestedSourcesInParallel(t *testing.T, mvf *MetadataVirtualFile, n, segSize int) func() {
+	t.Helper()
+	const skip, length = 16, 256
+	var wg sync.WaitGroup
+	for i := 1; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			buf := make([]byte, length)
+			got, err := mvf.ReadAtContext(context.Background(), buf, int64(i*segSize+skip))
+			assert.NoError(t, err)
+			assert.Equal(t, length, got)
+			assert.Equal(t, segments.Payload(i, segSize)[skip:skip+length], buf)
+		}(i)
+	}
+	return wg.Wait
+}
+
+func TestNestedReadAt_ConcurrentWithinCap(t *testing.T) {
+	const n, segSize, slots = 5, 1024, 2
+
+	fp := fakepool.New()
+	release := make(chan struct{})
+	fp.BlockUntil(release)
+	mvf := newNestedTestMVF(t, fp, n, segSize, slots)
+
+	wait := readNestedSourcesInParallel(t, mvf, n, segSize)
+
+	require.Eventually(t, func() bool { return fp.InFlight() == slots }, 2*time.Second, 5*time.Millisecond,
+		"nested reads at different offsets should download in parallel")
+	// The remaining reads must queue behind the cap rather than join in.
+	time.Sleep(50 * time.Millisecond)
+	assert.EqualValues(t, slots, fp.InFlight())
+
+	close(release)
+	wait()
+	assert.EqualValues(t, slots, fp.MaxInFlight())
+}
+
+func TestNestedReadAt_SerializedByDefault(t *testing.T) {
+	const n, segSize = 4, 1024
+
+	fp := fakepool.New()
+	release := make(chan struct{})
+	fp.BlockUntil(release)
+	mvf := newNestedTestMVF(t, fp, n, segSize, 0)
+	require.Nil(t, mvf.nestedReadSlots)
+
+	wait := readNestedSourcesInParallel(t, mvf, n, segSize)
+
+	require.Eventually(t, func() bool { return fp.InFlight() == 1 }, 2*time.Second, 5*time.Millisecond)
+	time.Sleep(50 * time.Millisecond)
+	assert.EqualValues(t, 1, fp.InFlight())
+
+	close(release)
+	wait()
+	assert.EqualValues(t, 1, fp.MaxInFlight())
+}
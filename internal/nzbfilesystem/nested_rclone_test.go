@@ -0,0 +1,126 @@
+package nzbfilesystem
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+
+	"github.com/javi11/altmount/internal/encryption"
+	"github.com/javi11/altmount/internal/encryption/rclone"
+	metapb "github.com/javi11/altmount/internal/metadata/proto"
+	"github.com/javi11/altmount/internal/testsupport/fakepool"
+	"github.com/javi11/altmount/internal/testsupport/segments"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+// rcloneEncryptedVolume serves volume, rclone-encrypted with password/salt, as
+// fake segment idx and returns the segment data covering the ciphertext.
+func rcloneEncryptedVolume(t *testing.T, fp *fakepool.Client, idx int, password, salt string, volume []byte) []*metapb.SegmentData {
+	t.Helper()
+	c, err := rclone.NewCipher(rclone.NameEncryptionOff, "", "", false, nil)
+	require.NoError(t, err)
+	k, err := rclone.GenerateKey(password, salt)
+	require.NoError(t, err)
+	r, err := c.EncryptData(bytes.NewReader(volume), k)
+	require.NoError(t, err)
+	ciphertext, err := io.ReadAll(r)
+	require.NoError(t, err)
+
+	fp.SetBehavior(segments.MessageID(idx), fakepool.SegmentBehavior{Bytes: ciphertext})
+	return []*metapb.SegmentData{{
+		Id:          segments.MessageID(idx),
+		SegmentSize: int64(len(ciphertext)),
+		EndOffset:   int64(len(ciphertext) - 1),
+	}}
+}
+
+func TestNestedReader_RcloneEncryptedSources(t *testing.T) {
+	fp := fakepool.New()
+
+	// Two rclone-encrypted inner volumes. The file starts 100 bytes into the
+	// first one and ends 50 bytes before the end of the second.
+	vol0 := bytes.Repeat([]byte("volume-zero;"), 800)
+	vol1 := bytes.Repeat([]byte("volume-one;"), 600)
+	const offset0, tail1 = 100, 50
+	want := append(append([]byte{}, vol0[offset0:]...), vol1[:len(vol1)-tail1]...)
+
+	sources := []*metapb.NestedSegmentSource{
+		{
+			// Per-source credentials.
+			Segments:        rcloneEncryptedVolume(t, fp, 0, "inner-pass", "inner-salt", vol0),
+			Encryption:      metapb.Encryption_RCLONE,
+			Password:        "inner-pass",
+			Salt:            "inner-salt",
+			InnerOffset:     offset0,
+			InnerLength:     int64(len(vol0) - offset0),
+			InnerVolumeSize: int64(len(vol0)),
+		},
+		{
+			// No credentials stored: falls back to the global ones.
+			Segments:        rcloneEncryptedVolume(t, fp, 1, "global-pass", "global-salt", vol1),
+			Encryption:      metapb.Encryption_RCLONE,
+			InnerLength:     int64(len(vol1) - tail1),
+			InnerVolumeSize: int64(len(vol1)),
+		},
+	}
+
+	rcloneCipher, err := rclone.NewRcloneCipher(&encryption.Config{})
+	require.NoError(t, err)
+	mvf := &MetadataVirtualFile{
+		name: "test-nested-rclone",
+		meta: &fileHandleMeta{
+			FileSize:      int64(len(want)),
+			NestedSources: sources,
+		},
+		poolManager:      newFakePoolManager(fp),
+		rcloneCipher:     rcloneCipher,
+		globalPassword:   "global-pass",
+		globalSalt:       "global-salt",
+		ctx:              context.Background(),
+		maxPrefetch:      1,
+		originalRangeEnd: -1,
+		streamTracker:    noopStreamTracker{},
+		streamID:         "test-stream",
+	}
+	t.Cleanup(func() { _ = mvf.Close() })
+
+	t.Run("whole file", func(t *testing.T) {
+		rc, err := mvf.createNestedReader(0, int64(len(want))-1)
+		require.NoError(t, err)
+		defer rc.Close()
+		got, err := io.ReadAll(rc)
+		require.NoError(t, err)
+		assert.Equal(t, want, got)
+	})
+
+	t.Run("range spanning both sources", func(t *testing.T) {
+		boundary := int64(len(vol0) - offset0)
+		start, end := boundary-300, boundary+200
+		rc, err := mvf.createNestedReader(start, end)
+		require.NoError(t, err)
+		defer rc.Close()
+		got, err := io.ReadAll(rc)
+		require.NoError(t, err)
+		assert.Equal(t, want[start:end+1], got)
+	})
+
+	t.Run("wrong credentials do not decrypt", func(t *testing.T) {
+		bad := proto.Clone(sources[0]).(*metapb.NestedSegmentSource)
+		bad.Password = "wrong-pass"
+		rc, err := mvf.createNestedSourceReader(bad, 0, 64, "test-stream")
+		if err == nil {
+			defer rc.Close()
+			_, err = io.ReadAll(rc)
+		}
+		assert.Error(t, err, "reading with the wrong password must fail authentication")
+	})
+
+	t.Run("no rclone cipher", func(t *testing.T) {
+		noCipher := &MetadataVirtualFile{ctx: context.Background()}
+		_, err := noCipher.createNestedSourceReader(sources[0], 0, 64, "test-stream")
+		assert.ErrorIs(t, err, ErrNoCipherConfig)
+	})
+}
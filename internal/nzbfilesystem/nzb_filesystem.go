@@ -22,6 +22,12 @@ func NewNzbFilesystem(remoteFile *MetadataRemoteFile) *NzbFilesystem {
 	}
 }
 
+// Close stops the filesystem's background workers. Call it during shutdown,
+// once no more requests are being served.
+func (nfs *NzbFilesystem) Close() {
+	nfs.remoteFile.Close()
+}
+
 // Name returns the filesystem name
 func (nfs *NzbFilesystem) Name() string {
 	return "altmount"
@@ -117,6 +123,12 @@ func (nfs *NzbFilesystem) Rename(ctx context.Context, oldName, newName string) e
 	return nil
 }
 
+// MoveToCategory moves a file into another SABnzbd category folder, updating
+// its metadata, health record and ID symlink. Returns the new virtual path.
+func (nfs *NzbFilesystem) MoveToCategory(ctx context.Context, name, category string) (string, error) {
+	return nfs.remoteFile.MoveToCategory(ctx, name, category)
+}
+
 // Mkdir creates a directory (not supported - read-only filesystem)
 func (nfs *NzbFilesystem) Mkdir(ctx context.Context, name string, perm os.FileMode) error {
 	return nfs.remoteFile.Mkdir(ctx, name, perm)
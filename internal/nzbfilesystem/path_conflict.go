@@ -0,0 +1,35 @@
+package nzbfilesystem
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/javi11/altmount/internal/config"
+)
+
+// servesDirectory reports whether normalizedName should be opened as a
+// directory. A path that is both a directory and a metadata file is resolved
+// by Metadata.OnPathConflict: the directory wins by default, prefer_file
+// falls through to the file, and error serves neither. The file check only
+// runs for paths that are directories, so ordinary lookups cost no extra stat.
+func (mrf *MetadataRemoteFile) servesDirectory(ctx context.Context, normalizedName string) (bool, error) {
+	if !mrf.metadataService.DirectoryExists(normalizedName) {
+		return false, nil
+	}
+	if !mrf.metadataService.FileExists(normalizedName) {
+		return true, nil
+	}
+
+	switch mode := mrf.configGetter().Metadata.OnPathConflict; mode {
+	case config.PathConflictPreferFile:
+		slog.DebugContext(ctx, "Path is both a file and a directory, serving the file", "path", normalizedName)
+		return false, nil
+	case config.PathConflictError:
+		slog.WarnContext(ctx, "Path is both a file and a directory, serving neither", "path", normalizedName)
+		return false, fmt.Errorf("%s: %w", normalizedName, ErrPathConflict)
+	default:
+		slog.DebugContext(ctx, "Path is both a file and a directory, serving the directory", "path", normalizedName)
+		return true, nil
+	}
+}
@@ -0,0 +1,80 @@
+package nzbfilesystem
+
+import (
+	"context"
+	"testing"
+
+	"github.com/javi11/altmount/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newConflictRemoteFile builds a tree where complete/Show is both a directory
+// (holding an episode) and a file.
+func newConflictRemoteFile(t *testing.T, mode config.PathConflict) *MetadataRemoteFile {
+	t.Helper()
+	_, _, ms := setupStreamHealthEnv(t)
+	writeStreamMeta(t, ms, "complete/Show/episode.mkv")
+	writeStreamMeta(t, ms, "complete/Show")
+	require.True(t, ms.HasPathConflict("complete/Show"))
+
+	cfg := config.DefaultConfig()
+	cfg.Metadata.OnPathConflict = mode
+	return &MetadataRemoteFile{
+		metadataService: ms,
+		configGetter:    func() *config.Config { return cfg },
+	}
+}
+
+func TestPathConflict_Resolution(t *testing.T) {
+	for _, tc := range []struct {
+		mode    config.PathConflict
+		wantDir bool
+	}{
+		{"", true},
+		{config.PathConflictPreferDir, true},
+		{config.PathConflictPreferFile, false},
+	} {
+		t.Run(string(tc.mode), func(t *testing.T) {
+			mrf := newConflictRemoteFile(t, tc.mode)
+			ctx := context.Background()
+
+			ok, info, err := mrf.Stat(ctx, "/complete/Show")
+			require.NoError(t, err)
+			require.True(t, ok)
+			assert.Equal(t, tc.wantDir, info.IsDir())
+
+			ok, f, err := mrf.OpenFile(ctx, "/complete/Show")
+			require.NoError(t, err)
+			require.True(t, ok)
+			defer f.Close()
+			_, isDir := f.(*MetadataVirtualDirectory)
+			assert.Equal(t, tc.wantDir, isDir)
+
+			// Paths below the directory are unaffected.
+			ok, info, err = mrf.Stat(ctx, "/complete/Show/episode.mkv")
+			require.NoError(t, err)
+			require.True(t, ok)
+			assert.False(t, info.IsDir())
+		})
+	}
+}
+
+func TestPathConflict_ErrorServesNeither(t *testing.T) {
+	mrf := newConflictRemoteFile(t, config.PathConflictError)
+	ctx := context.Background()
+
+	ok, _, err := mrf.Stat(ctx, "/complete/Show")
+	assert.ErrorIs(t, err, ErrPathConflict)
+	assert.False(t, ok)
+
+	ok, _, err = mrf.OpenFile(ctx, "/complete/Show")
+	assert.ErrorIs(t, err, ErrPathConflict)
+	assert.False(t, ok)
+
+	// A plain directory is still served.
+	ok, info, err := mrf.Stat(ctx, "/complete")
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.True(t, info.IsDir())
+}
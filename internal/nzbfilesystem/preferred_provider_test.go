@@ -0,0 +1,90 @@
+package nzbfilesystem
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+
+	"github.com/javi11/altmount/internal/pool"
+	"github.com/javi11/altmount/internal/testsupport/fakepool"
+	"github.com/javi11/altmount/internal/testsupport/segments"
+	"github.com/javi11/nntppool/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// preferredPoolManager is a fakePoolManager that also serves
+// preferred-provider reads from a set of per-provider fakes.
+type preferredPoolManager struct {
+	*fakePoolManager
+	providers map[string]*fakepool.Client
+}
+
+var _ pool.PreferredPoolGetter = (*preferredPoolManager)(nil)
+
+func (m *preferredPoolManager) GetPreferredPool(provider string) (pool.NntpClient, error) {
+	if p, ok := m.providers[provider]; ok {
+		return pool.WithPreferredProvider(p, m.client), nil
+	}
+	return m.client, nil
+}
+
+func TestPreferredProvider_Reads(t *testing.T) {
+	const n, segSize = 4, 1024
+	var want []byte
+	for i := range n {
+		want = append(want, segments.Payload(i, segSize)...)
+	}
+
+	newFile := func(t *testing.T, preferred string) (mvf *MetadataVirtualFile, shared, providerA *fakepool.Client) {
+		shared, providerA = fakepool.New(), fakepool.New()
+		configurePoolForFile(shared, n, segSize, fakepool.SegmentBehavior{})
+		configurePoolForFile(providerA, n, segSize, fakepool.SegmentBehavior{})
+
+		mvf = newTestMVF(t, context.Background(), shared, n, segSize, 2)
+		mvf.poolManager = &preferredPoolManager{
+			fakePoolManager: newFakePoolManager(shared),
+			providers:       map[string]*fakepool.Client{"provider-a": providerA},
+		}
+		mvf.meta.PreferredProvider = preferred
+		mvf.readPool = readPoolGetter(mvf.poolManager, preferred)
+		return mvf, shared, providerA
+	}
+
+	t.Run("fetched from the preferred provider when present", func(t *testing.T) {
+		mvf, shared, providerA := newFile(t, "provider-a")
+		got, err := io.ReadAll(mvf)
+		require.NoError(t, err)
+		assert.True(t, bytes.Equal(want, got))
+		assert.Equal(t, int64(n), providerA.TotalCalls())
+		assert.Zero(t, shared.TotalCalls(), "shared pool is not used while the preferred provider serves every segment")
+	})
+
+	t.Run("falls back to the shared pool for articles the provider lacks", func(t *testing.T) {
+		mvf, shared, providerA := newFile(t, "provider-a")
+		providerA.SetBehavior(segments.MessageID(2), fakepool.SegmentBehavior{Err: nntppool.ErrArticleNotFound})
+		got, err := io.ReadAll(mvf)
+		require.NoError(t, err)
+		assert.True(t, bytes.Equal(want, got))
+		assert.Equal(t, int64(1), shared.PerMessageCalls(segments.MessageID(2)))
+		assert.Zero(t, shared.PerMessageCalls(segments.MessageID(0)))
+	})
+
+	t.Run("falls back to normal selection when the provider is absent", func(t *testing.T) {
+		mvf, shared, providerA := newFile(t, "provider-b")
+		got, err := io.ReadAll(mvf)
+		require.NoError(t, err)
+		assert.True(t, bytes.Equal(want, got))
+		assert.Zero(t, providerA.TotalCalls())
+		assert.Equal(t, int64(n), shared.TotalCalls())
+	})
+
+	t.Run("untagged file uses the shared pool", func(t *testing.T) {
+		mvf, shared, providerA := newFile(t, "")
+		_, err := io.ReadAll(mvf)
+		require.NoError(t, err)
+		assert.Zero(t, providerA.TotalCalls())
+		assert.Equal(t, int64(n), shared.TotalCalls())
+	})
+}
@@ -0,0 +1,83 @@
+package nzbfilesystem
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/javi11/altmount/internal/config"
+	"github.com/javi11/altmount/internal/encryption/rclone"
+	metapb "github.com/javi11/altmount/internal/metadata/proto"
+	"github.com/javi11/altmount/internal/testsupport/fakepool"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
+)
+
+// nestedReadbackMeta maps a file onto n nested sources of one segment each.
+func nestedReadbackMeta(t *testing.T, n, segSize int) *metapb.FileMetadata {
+	t.Helper()
+	segData := buildSegmentData(t, n, segSize)
+	meta := &metapb.FileMetadata{FileSize: int64(n * segSize)}
+	for i := range n {
+		meta.NestedSources = append(meta.NestedSources, &metapb.NestedSegmentSource{
+			Segments:        segData[i : i+1],
+			InnerLength:     int64(segSize),
+			InnerVolumeSize: int64(segSize),
+		})
+	}
+	return meta
+}
+
+func TestVerifyReadback_NestedFile(t *testing.T) {
+	const n, segSize = 4, 1024
+	fp := fakepool.New()
+	configurePoolForFile(fp, n, segSize, fakepool.SegmentBehavior{})
+	pm := newFakePoolManager(fp)
+	cfg := config.DefaultConfig()
+	getter := func() *config.Config { return cfg }
+	ctx := context.Background()
+
+	assert.NoError(t, VerifyReadback(ctx, pm, getter, "movies/ok.mkv", nestedReadbackMeta(t, n, segSize)))
+
+	// The last source claims its data starts halfway into its volume, so the
+	// mapping runs off the end of the segment it points at.
+	shifted := nestedReadbackMeta(t, n, segSize)
+	shifted.NestedSources[n-1].InnerOffset = segSize / 2
+	assert.Error(t, VerifyReadback(ctx, pm, getter, "movies/shifted.mkv", shifted))
+
+	// The sources cover less than the advertised file size.
+	short := nestedReadbackMeta(t, n, segSize)
+	short.NestedSources = short.NestedSources[:n-1]
+	assert.Error(t, VerifyReadback(ctx, pm, getter, "movies/short.mkv", short))
+}
+
+func TestVerifyReadback_EmptyFile(t *testing.T) {
+	getter := func() *config.Config { return config.DefaultConfig() }
+	assert.NoError(t, VerifyReadback(context.Background(), nil, getter, "empty.bin", &metapb.FileMetadata{}))
+}
+
+func TestVerifyRcloneSize(t *testing.T) {
+	fp := fakepool.New()
+	plaintext := bytes.Repeat([]byte("altmount"), 10000)
+	meta := newRcloneCandidate(t, fp, "pass", "salt", plaintext)
+	meta.Password, meta.Salt = "pass", "salt"
+	pm := newFakePoolManager(fp)
+	cfg := config.DefaultConfig()
+	getter := func() *config.Config { return cfg }
+	ctx := context.Background()
+
+	assert.NoError(t, VerifyRcloneSize(ctx, pm, getter, "movies/ok.mkv", meta))
+
+	// Stored size taken from the encrypted length.
+	encLen := proto.Clone(meta).(*metapb.FileMetadata)
+	encLen.FileSize = meta.SegmentData[0].SegmentSize
+	assert.ErrorIs(t, VerifyRcloneSize(ctx, pm, getter, "movies/enc.mkv", encLen), rclone.ErrDecryptedSizeMismatch)
+
+	// Stored size off by a few bytes, with segments sized to match it, so
+	// only decrypting the tail can tell.
+	short := proto.Clone(meta).(*metapb.FileMetadata)
+	short.FileSize -= 10
+	short.SegmentData[0].EndOffset -= 10
+	short.SegmentData[0].SegmentSize -= 10
+	assert.ErrorIs(t, VerifyRcloneSize(ctx, pm, getter, "movies/short.mkv", short), rclone.ErrDecryptedSizeMismatch)
+}
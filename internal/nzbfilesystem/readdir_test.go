@@ -0,0 +1,118 @@
+package nzbfilesystem
+
+import (
+	"os"
+	"testing"
+
+	"github.com/javi11/altmount/internal/config"
+	"github.com/javi11/altmount/internal/metadata"
+	metapb "github.com/javi11/altmount/internal/metadata/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReaddir_UnreadableMetadataOnlyShownWithCorrupted(t *testing.T) {
+	root := t.TempDir()
+	ms := metadata.NewMetadataService(root)
+
+	good := ms.CreateFileMetadata(
+		1024, "test.nzb", metapb.FileStatus_FILE_STATUS_HEALTHY,
+		nil, metapb.Encryption_NONE, "", "", nil, nil, 0, nil, "",
+	)
+	require.NoError(t, ms.WriteFileMetadata("movies/good.mkv", good))
+	require.NoError(t, ms.WriteFileMetadata("movies/broken.mkv", good))
+
+	// Truncate inside the first varint so the proto can no longer be parsed.
+	brokenPath := ms.GetMetadataFilePath("movies/broken.mkv")
+	require.NoError(t, os.Truncate(brokenPath, 2))
+	// Fresh service so the listing isn't served from the write-populated lite cache.
+	ms = metadata.NewMetadataService(root)
+
+	masking := false
+	cfg := &config.Config{}
+	cfg.Streaming.FailureMasking.Enabled = &masking
+
+	list := func(showCorrupted bool) map[string]*MetadataFileInfo {
+		dir := &MetadataVirtualDirectory{
+			name:            "movies",
+			normalizedPath:  "movies",
+			metadataService: ms,
+			configGetter:    func() *config.Config { return cfg },
+			showCorrupted:   showCorrupted,
+		}
+		infos, err := dir.Readdir(0)
+		require.NoError(t, err)
+		out := make(map[string]*MetadataFileInfo, len(infos))
+		for _, fi := range infos {
+			out[fi.Name()] = fi.(*MetadataFileInfo)
+		}
+		return out
+	}
+
+	normal := list(false)
+	assert.Contains(t, normal, "good.mkv")
+	assert.NotContains(t, normal, "broken.mkv", "unreadable metadata must stay hidden in normal listings")
+
+	withCorrupted := list(true)
+	require.Contains(t, withCorrupted, "broken.mkv")
+	broken := withCorrupted["broken.mkv"]
+	assert.True(t, broken.Unreadable())
+	assert.Zero(t, broken.Size())
+	assert.False(t, broken.IsDir())
+	assert.False(t, broken.ModTime().IsZero(), "mod time comes from the .meta file on disk")
+
+	require.Contains(t, withCorrupted, "good.mkv")
+	assert.False(t, withCorrupted["good.mkv"].Unreadable())
+	assert.Equal(t, int64(1024), withCorrupted["good.mkv"].Size())
+}
+
+func TestReaddir_ListingSort(t *testing.T) {
+	root := t.TempDir()
+	ms := metadata.NewMetadataService(root)
+
+	for i, name := range []string{"show.ep10.mkv", "show.ep2.mkv", "show.ep1.mkv", "Show.ep3.mkv"} {
+		meta := ms.CreateFileMetadata(
+			1024, "test.nzb", metapb.FileStatus_FILE_STATUS_HEALTHY,
+			nil, metapb.Encryption_NONE, "", "", nil, nil, 0, nil, "",
+		)
+		// ep10 newest, ep1 oldest among the first three.
+		meta.ModifiedAt = int64(1_700_000_000 + 100*(4-i))
+		require.NoError(t, ms.WriteFileMetadata("tv/"+name, meta))
+	}
+	require.NoError(t, os.MkdirAll(ms.GetMetadataDirectoryPath("tv/season 10"), 0755))
+	require.NoError(t, os.MkdirAll(ms.GetMetadataDirectoryPath("tv/season 2"), 0755))
+
+	masking := false
+	cfg := &config.Config{}
+	cfg.Streaming.FailureMasking.Enabled = &masking
+
+	list := func(mode config.ListingSort, count int) []string {
+		cfg.Metadata.ListingSort = mode
+		dir := &MetadataVirtualDirectory{
+			name:            "tv",
+			normalizedPath:  "tv",
+			metadataService: metadata.NewMetadataService(root),
+			configGetter:    func() *config.Config { return cfg },
+		}
+		names, err := dir.Readdirnames(count)
+		require.NoError(t, err)
+		return names
+	}
+
+	assert.Equal(t,
+		[]string{"season 2", "season 10", "show.ep1.mkv", "show.ep2.mkv", "Show.ep3.mkv", "show.ep10.mkv"},
+		list(config.ListingSortNatural, 0))
+	assert.Equal(t,
+		[]string{"season 10", "season 2", "Show.ep3.mkv", "show.ep1.mkv", "show.ep10.mkv", "show.ep2.mkv"},
+		list(config.ListingSortName, 0))
+
+	mtime := list(config.ListingSortMtime, 0)
+	assert.Equal(t, []string{"show.ep10.mkv", "show.ep2.mkv", "show.ep1.mkv", "Show.ep3.mkv"}, mtime[2:])
+
+	// count is applied after sorting, not to the first entries found on disk.
+	assert.Equal(t, []string{"season 2", "season 10", "show.ep1.mkv"}, list(config.ListingSortNatural, 3))
+
+	assert.ElementsMatch(t,
+		[]string{"season 2", "season 10", "show.ep1.mkv", "show.ep2.mkv", "Show.ep3.mkv", "show.ep10.mkv"},
+		list(config.ListingSortNone, 0))
+}
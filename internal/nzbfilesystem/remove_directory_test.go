@@ -0,0 +1,78 @@
+package nzbfilesystem
+
+import (
+	"context"
+	"testing"
+
+	"github.com/javi11/altmount/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newRemoveDirEnv lays out a season directory with three episodes (one in a
+// nested extras folder) and marks episode 2 as repair_triggered.
+func newRemoveDirEnv(t *testing.T, protect bool) *MetadataRemoteFile {
+	t.Helper()
+	repo, db, ms := setupStreamHealthEnv(t)
+
+	for _, p := range []string{
+		"complete/Show/Season 01/show.s01e01.mkv",
+		"complete/Show/Season 01/show.s01e02.mkv",
+		"complete/Show/Season 01/extras/show.s01.featurette.mkv",
+	} {
+		writeStreamMeta(t, ms, p)
+	}
+	_, err := db.Exec(
+		`INSERT INTO file_health (file_path, status, scheduled_check_at) VALUES (?, 'repair_triggered', datetime('now'))`,
+		"complete/Show/Season 01/show.s01e02.mkv",
+	)
+	require.NoError(t, err)
+	_, err = db.Exec(
+		`INSERT INTO file_health (file_path, status, scheduled_check_at) VALUES (?, 'healthy', datetime('now'))`,
+		"complete/Show/Season 01/show.s01e01.mkv",
+	)
+	require.NoError(t, err)
+
+	cfg := config.DefaultConfig()
+	cfg.Metadata.ProtectRepairingOnDelete = &protect
+	return &MetadataRemoteFile{
+		metadataService:  ms,
+		healthRepository: repo,
+		configGetter:     func() *config.Config { return cfg },
+	}
+}
+
+func TestRemoveFile_DirectoryKeepsFilesUnderRepair(t *testing.T) {
+	mrf := newRemoveDirEnv(t, true)
+	ms := mrf.metadataService
+
+	ok, err := mrf.RemoveFile(context.Background(), "/complete/Show/Season 01")
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	assert.True(t, ms.DirectoryExists("complete/Show/Season 01"), "directory is preserved while a repair is pending")
+	assert.True(t, ms.FileExists("complete/Show/Season 01/show.s01e02.mkv"), "file under repair is kept")
+	assert.False(t, ms.FileExists("complete/Show/Season 01/show.s01e01.mkv"))
+	assert.False(t, ms.FileExists("complete/Show/Season 01/extras/show.s01.featurette.mkv"))
+	assert.False(t, ms.DirectoryExists("complete/Show/Season 01/extras"), "emptied subdirectories are removed")
+}
+
+func TestRemoveFile_DirectoryWithoutRepairsIsRemoved(t *testing.T) {
+	mrf := newRemoveDirEnv(t, true)
+	ms := mrf.metadataService
+
+	ok, err := mrf.RemoveFile(context.Background(), "/complete/Show/Season 01/extras")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.False(t, ms.DirectoryExists("complete/Show/Season 01/extras"))
+	assert.True(t, ms.FileExists("complete/Show/Season 01/show.s01e02.mkv"))
+}
+
+func TestRemoveFile_DirectoryProtectionDisabled(t *testing.T) {
+	mrf := newRemoveDirEnv(t, false)
+
+	ok, err := mrf.RemoveFile(context.Background(), "/complete/Show/Season 01")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.False(t, mrf.metadataService.DirectoryExists("complete/Show/Season 01"), "default delete removes everything")
+}
@@ -0,0 +1,85 @@
+package nzbfilesystem
+
+import metapb "github.com/javi11/altmount/internal/metadata/proto"
+
+const (
+	// seekHistorySize is how many recent seeks a handle remembers.
+	seekHistorySize = 4
+	// backwardSeeksForBias is how many of those must go backward before the
+	// handle stops reading ahead.
+	backwardSeeksForBias = 3
+	// backwardPrefetchWindow bounds how many segments behind a read a
+	// backward-biased reader fetches into the segment cache.
+	backwardPrefetchWindow = 4
+)
+
+// seekHistory is a ring of a handle's most recent seek directions, used by
+// Streaming.PrefetchDirection=adaptive to tell a reverse scan from playback.
+type seekHistory struct {
+	backward [seekHistorySize]bool
+	n        int
+	next     int
+}
+
+// record adds a seek from one position to another; seeks to the same
+// position carry no direction and are ignored.
+func (h *seekHistory) record(from, to int64) {
+	if from == to {
+		return
+	}
+	h.backward[h.next] = to < from
+	h.next = (h.next + 1) % seekHistorySize
+	if h.n < seekHistorySize {
+		h.n++
+	}
+}
+
+// isBackward reports whether enough recent seeks went backward to bias
+// prefetch that way.
+func (h *seekHistory) isBackward() bool {
+	count := 0
+	for i := range h.n {
+		if h.backward[i] {
+			count++
+		}
+	}
+	return count >= backwardSeeksForBias
+}
+
+// prefetchBackward reports whether the next reader should be biased
+// backward: adaptive prefetch is on and the handle keeps seeking backward.
+// Only flat, unencrypted files qualify, since the reader is opened behind
+// the requested offset.
+func (mvf *MetadataVirtualFile) prefetchBackward() bool {
+	return mvf.adaptivePrefetch && mvf.seeks.isBackward() &&
+		len(mvf.meta.NestedSources) == 0 && mvf.meta.Encryption == metapb.Encryption_NONE &&
+		len(mvf.meta.SegmentData) > 0
+}
+
+// backwardWindow returns where a backward-biased reader for a read of
+// readLen bytes at start opens and how many segments it prefetches. The
+// reader never reads ahead past the read; with a segment cache it also opens
+// up to backwardPrefetchWindow segments earlier, so the seeks that follow
+// find those segments cached. Without a cache they would only be discarded.
+func (mvf *MetadataVirtualFile) backwardWindow(start, end, readLen int64) (rawStart int64, prefetch int) {
+	mvf.segmentIndexOnce.Do(func() {
+		mvf.segmentIndex = buildSegmentIndex(mvf.meta.SegmentData)
+	})
+	first := mvf.segmentIndex.findSegmentForOffset(start)
+	last := mvf.segmentIndex.findSegmentForOffset(min(end, start+max(readLen, 1)-1))
+	if first < 0 || last < first {
+		return start, 0
+	}
+
+	behind := 0
+	if mvf.segmentStore != nil {
+		behind = min(first, backwardPrefetchWindow)
+		if mvf.maxPrefetch > 0 {
+			behind = max(0, min(behind, mvf.maxPrefetch-(last-first+1)))
+		}
+	}
+	if behind == 0 {
+		return start, last - first + 1
+	}
+	return mvf.segmentIndex.getOffsetForSegment(first - behind), last - first + 1 + behind
+}
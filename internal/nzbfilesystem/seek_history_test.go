@@ -0,0 +1,190 @@
+package nzbfilesystem
+
+import (
+	"context"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/javi11/altmount/internal/testsupport/fakepool"
+	"github.com/javi11/altmount/internal/testsupport/segments"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSeekHistory(t *testing.T) {
+	var h seekHistory
+	assert.False(t, h.isBackward())
+
+	h.record(100, 100)
+	assert.Zero(t, h.n, "a seek to the same position has no direction")
+
+	h.record(100, 50)
+	h.record(60, 10)
+	assert.False(t, h.isBackward())
+	h.record(20, 0)
+	assert.True(t, h.isBackward())
+
+	// Forward seeks push the backward ones out of the window.
+	h.record(0, 500)
+	assert.True(t, h.isBackward(), "three of the last four are still backward")
+	h.record(500, 900)
+	assert.False(t, h.isBackward())
+}
+
+// memSegmentStore is an in-memory usenet.SegmentStore.
+type memSegmentStore struct {
+	mu   sync.Mutex
+	data map[string][]byte
+}
+
+func (s *memSegmentStore) Get(id string) ([]byte, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b, ok := s.data[id]
+	return b, ok
+}
+
+func (s *memSegmentStore) Put(id string, b []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data[id] = b
+	return nil
+}
+
+// reverseScan reads a few bytes at the start of each segment from seg down to
+// seg-steps+1, seeking backward between reads, and returns the data read.
+func reverseScan(t *testing.T, mvf *MetadataVirtualFile, seg, steps, segSize int) [][]byte {
+	t.Helper()
+	var out [][]byte
+	for i := range steps {
+		_, err := mvf.Seek(int64((seg-i)*segSize), io.SeekStart)
+		require.NoError(t, err)
+		buf := make([]byte, 100)
+		_, err = io.ReadFull(mvf, buf)
+		require.NoError(t, err)
+		out = append(out, buf)
+	}
+	return out
+}
+
+// fetchesForRead returns how many segments one more backward seek and read
+// at seg downloads once the handle has settled.
+func fetchesForRead(t *testing.T, fp *fakepool.Client, mvf *MetadataVirtualFile, seg, segSize int) int64 {
+	t.Helper()
+	time.Sleep(100 * time.Millisecond)
+	before := fp.BodyPriorityCalls()
+	reverseScan(t, mvf, seg, 1, segSize)
+	time.Sleep(100 * time.Millisecond)
+	return fp.BodyPriorityCalls() - before
+}
+
+func TestMetadataVirtualFile_BackwardSeeksStopReadAhead(t *testing.T) {
+	const (
+		n           = 20
+		segSize     = 1024
+		maxPrefetch = 8
+	)
+
+	t.Run("forward", func(t *testing.T) {
+		fp := fakepool.New()
+		configurePoolForFile(fp, n, segSize, fakepool.SegmentBehavior{})
+		mvf := newTestMVF(t, context.Background(), fp, n, segSize, maxPrefetch)
+
+		reverseScan(t, mvf, 18, 4, segSize)
+		assert.Greater(t, fetchesForRead(t, fp, mvf, 14, segSize), int64(1),
+			"the default keeps reading ahead of every read")
+	})
+
+	t.Run("adaptive", func(t *testing.T) {
+		fp := fakepool.New()
+		configurePoolForFile(fp, n, segSize, fakepool.SegmentBehavior{})
+		mvf := newTestMVF(t, context.Background(), fp, n, segSize, maxPrefetch)
+		mvf.adaptivePrefetch = true
+
+		reverseScan(t, mvf, 18, 4, segSize)
+		require.True(t, mvf.seeks.isBackward())
+		assert.Equal(t, int64(1), fetchesForRead(t, fp, mvf, 14, segSize),
+			"a reverse scan only fetches the segment it reads")
+
+		// Playback resuming forward restores read-ahead.
+		for _, off := range []int64{2 * segSize, 4 * segSize, 6 * segSize} {
+			_, err := mvf.Seek(off, io.SeekStart)
+			require.NoError(t, err)
+		}
+		assert.False(t, mvf.seeks.isBackward())
+	})
+}
+
+func TestMetadataVirtualFile_ForwardReadsWidenBackwardReader(t *testing.T) {
+	const (
+		n           = 20
+		segSize     = 1024
+		maxPrefetch = 8
+	)
+	fp := fakepool.New()
+	configurePoolForFile(fp, n, segSize, fakepool.SegmentBehavior{})
+	mvf := newTestMVF(t, context.Background(), fp, n, segSize, maxPrefetch)
+	mvf.adaptivePrefetch = true
+
+	reverseScan(t, mvf, 18, 4, segSize)
+	require.True(t, mvf.seeks.isBackward())
+	reverseScan(t, mvf, 5, 1, segSize)
+	time.Sleep(100 * time.Millisecond)
+	assert.Zero(t, fp.PerMessageCalls(segments.MessageID(6)), "the backward-biased reader only fetched its read")
+
+	// Playback carries on from there without seeking.
+	rest := make([]byte, 3*segSize)
+	_, err := io.ReadFull(mvf, rest)
+	require.NoError(t, err)
+	assert.Equal(t, segments.Payload(6, segSize)[:100], rest[segSize-100:segSize])
+	assert.Eventually(t, func() bool {
+		return fp.PerMessageCalls(segments.MessageID(11)) > 0
+	}, 5*time.Second, 10*time.Millisecond, "read-ahead is back to maxPrefetch")
+}
+
+func TestMetadataVirtualFile_BackwardSeeksWarmCacheBehindRead(t *testing.T) {
+	const (
+		n           = 20
+		segSize     = 1024
+		maxPrefetch = 8
+	)
+	fp := fakepool.New()
+	configurePoolForFile(fp, n, segSize, fakepool.SegmentBehavior{})
+	mvf := newTestMVF(t, context.Background(), fp, n, segSize, maxPrefetch)
+	mvf.adaptivePrefetch = true
+	store := &memSegmentStore{data: map[string][]byte{}}
+	mvf.segmentStore = store
+
+	got := reverseScan(t, mvf, 18, 5, segSize)
+	for i, buf := range got {
+		assert.Equal(t, segments.Payload(18-i, segSize)[:100], buf, "read at segment %d", 18-i)
+	}
+
+	// The last read, at segment 14, ran backward-biased and fetched the
+	// window behind it.
+	for seg := 14 - backwardPrefetchWindow; seg < 14; seg++ {
+		_, ok := store.Get(segments.MessageID(seg))
+		assert.True(t, ok, "segment %d was cached ahead of the reverse scan", seg)
+	}
+	_, ok := store.Get(segments.MessageID(14 - backwardPrefetchWindow - 1))
+	assert.False(t, ok, "the window is bounded")
+}
+
+func TestBackwardWindow(t *testing.T) {
+	mvf := newTestMVF(t, context.Background(), fakepool.New(), 20, 1024, 8)
+
+	start, prefetch := mvf.backwardWindow(10*1024+5, 20*1024-1, 100)
+	assert.Equal(t, int64(10*1024+5), start, "without a cache the reader opens at the read")
+	assert.Equal(t, 1, prefetch)
+
+	mvf.segmentStore = &memSegmentStore{data: map[string][]byte{}}
+	start, prefetch = mvf.backwardWindow(10*1024+5, 20*1024-1, 100)
+	assert.Equal(t, int64((10-backwardPrefetchWindow)*1024), start)
+	assert.Equal(t, 1+backwardPrefetchWindow, prefetch)
+
+	start, prefetch = mvf.backwardWindow(1024, 20*1024-1, 100)
+	assert.Equal(t, int64(0), start, "the window stops at the start of the file")
+	assert.Equal(t, 2, prefetch)
+}
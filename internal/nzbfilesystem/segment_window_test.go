@@ -0,0 +1,82 @@
+package nzbfilesystem
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	metapb "github.com/javi11/altmount/internal/metadata/proto"
+	"github.com/javi11/altmount/internal/testsupport/fakepool"
+	"github.com/javi11/altmount/internal/testsupport/segments"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newJunkWindowMVF serves a three-segment file whose middle article decodes
+// to junk, then its data, then more junk. It returns the file's bytes.
+func newJunkWindowMVF(t *testing.T) (*MetadataVirtualFile, []byte) {
+	t.Helper()
+	const segSize, lead, trail = 1024, 100, 50
+	data := checksumFixture()[:3*segSize]
+
+	body := append(append(filler(lead, 0xEE), data[segSize:2*segSize]...), filler(trail, 0xEE)...)
+	fp := fakepool.New()
+	fp.SetBehavior(segments.MessageID(0), fakepool.SegmentBehavior{Bytes: data[:segSize]})
+	fp.SetBehavior(segments.MessageID(1), fakepool.SegmentBehavior{Bytes: body})
+	fp.SetBehavior(segments.MessageID(2), fakepool.SegmentBehavior{Bytes: data[2*segSize:]})
+
+	mvf := newTestMVF(t, context.Background(), fp, 3, segSize, 4)
+	mvf.meta.SegmentData[1] = &metapb.SegmentData{
+		Id:          segments.MessageID(1),
+		SegmentSize: int64(len(body)),
+		StartOffset: 0,
+		EndOffset:   segSize - 1,
+		UsableStart: lead,
+		UsableEnd:   lead + segSize - 1,
+	}
+	return mvf, data
+}
+
+func TestSegmentUsableWindow(t *testing.T) {
+	t.Run("sequential read", func(t *testing.T) {
+		mvf, data := newJunkWindowMVF(t)
+		got, err := io.ReadAll(mvf)
+		require.NoError(t, err)
+		assert.Equal(t, data, got)
+	})
+
+	t.Run("ranges across the window", func(t *testing.T) {
+		mvf, data := newJunkWindowMVF(t)
+		for _, r := range []struct{ off, n int64 }{
+			{1000, 100}, // into the window's first bytes
+			{1500, 200}, // inside it
+			{2000, 100}, // out of its last bytes
+		} {
+			buf := make([]byte, r.n)
+			n, err := mvf.ReadAt(buf, r.off)
+			require.NoError(t, err)
+			require.Equal(t, int(r.n), n)
+			assert.Equal(t, data[r.off:r.off+r.n], buf, "range at %d", r.off)
+		}
+	})
+}
+
+func TestSegmentDataBodyRange(t *testing.T) {
+	tests := []struct {
+		name             string
+		seg              *metapb.SegmentData
+		wantStart, wantE int64
+	}{
+		{"no window", &metapb.SegmentData{StartOffset: 10, EndOffset: 99}, 10, 99},
+		{"leading junk", &metapb.SegmentData{StartOffset: 10, EndOffset: 99, UsableStart: 5}, 15, 104},
+		{"clamped to usable end", &metapb.SegmentData{StartOffset: 0, EndOffset: 99, UsableStart: 5, UsableEnd: 80}, 5, 80},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			start, end := tc.seg.BodyRange()
+			assert.Equal(t, tc.wantStart, start)
+			assert.Equal(t, tc.wantE, end)
+			assert.Equal(t, tc.wantE-tc.wantStart+1, tc.seg.UsableLen())
+		})
+	}
+}
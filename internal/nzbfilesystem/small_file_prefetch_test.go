@@ -0,0 +1,119 @@
+package nzbfilesystem
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/javi11/altmount/internal/testsupport/fakepool"
+	"github.com/javi11/altmount/internal/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetadataVirtualFile_SmallFileProbeFetchesOnlyNeededSegments(t *testing.T) {
+	const (
+		n           = 10
+		segSize     = 1024
+		maxPrefetch = 8
+	)
+	// An open-ended range, as players send when probing a header.
+	ctx := context.WithValue(context.Background(), utils.RangeKey, "bytes=0-")
+
+	fp := fakepool.New()
+	configurePoolForFile(fp, n, segSize, fakepool.SegmentBehavior{})
+	mvf := newTestMVF(t, ctx, fp, n, segSize, maxPrefetch)
+	mvf.smallFileThreshold = n*segSize + 1
+
+	buf := make([]byte, 100)
+	got, err := mvf.Read(buf)
+	require.NoError(t, err)
+	assert.Equal(t, 100, got)
+
+	// Give the download manager time to schedule anything it would.
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, int64(1+smallFilePrefetchMargin), fp.BodyPriorityCalls(),
+		"only the header segment plus the margin is fetched")
+}
+
+func TestMetadataVirtualFile_SmallFileCapDoesNotApplyToLargeFiles(t *testing.T) {
+	const (
+		n           = 10
+		segSize     = 1024
+		maxPrefetch = 8
+	)
+	fp := fakepool.New()
+	configurePoolForFile(fp, n, segSize, fakepool.SegmentBehavior{})
+	mvf := newTestMVF(t, context.Background(), fp, n, segSize, maxPrefetch)
+	mvf.smallFileThreshold = n * segSize // file is not below the threshold
+
+	_, err := mvf.Read(make([]byte, 100))
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool { return fp.BodyPriorityCalls() >= maxPrefetch }, 2*time.Second, 5*time.Millisecond,
+		"files at or above the threshold keep the full prefetch window")
+}
+
+func TestMetadataVirtualFile_SmallFileSequentialReadStillCompletes(t *testing.T) {
+	const (
+		n       = 10
+		segSize = 1024
+	)
+	fp := fakepool.New()
+	configurePoolForFile(fp, n, segSize, fakepool.SegmentBehavior{})
+	mvf := newTestMVF(t, context.Background(), fp, n, segSize, 8)
+	mvf.smallFileThreshold = 1 << 20
+
+	first := make([]byte, 16)
+	_, err := mvf.Read(first)
+	require.NoError(t, err)
+
+	// The capped window slides with the reader, so the rest of the file
+	// still streams through the same reader.
+	rest := make([]byte, n*segSize-16)
+	got := 0
+	for got < len(rest) {
+		m, err := mvf.Read(rest[got:])
+		require.NoError(t, err)
+		got += m
+	}
+	assert.Equal(t, wantFileBytes(n, segSize), append(first, rest...))
+	fakepool.AssertMaxInFlightLE(t, fp, int32(1+smallFilePrefetchMargin))
+}
+
+func TestMetadataVirtualFile_SmallFileCapEndsWithItsReader(t *testing.T) {
+	const (
+		n           = 10
+		segSize     = 1024
+		maxPrefetch = 8
+	)
+	fp := fakepool.New()
+	configurePoolForFile(fp, n, segSize, fakepool.SegmentBehavior{})
+	mvf := newTestMVF(t, context.Background(), fp, n, segSize, maxPrefetch)
+	mvf.smallFileThreshold = n*segSize + 1
+
+	_, err := mvf.Read(make([]byte, 100))
+	require.NoError(t, err)
+	assert.Equal(t, 1+smallFilePrefetchMargin, mvf.prefetch())
+
+	// Replacing the reader drops its cap, so independent readers opened
+	// before the next ensureReader do not inherit a stale window.
+	_, err = mvf.Seek(5*segSize, io.SeekStart)
+	require.NoError(t, err)
+	assert.Equal(t, maxPrefetch, mvf.prefetch())
+}
+
+func TestSmallFilePrefetch(t *testing.T) {
+	mvf := newTestMVF(t, context.Background(), fakepool.New(), 10, 1024, 8)
+	mvf.smallFileThreshold = 1 << 20
+
+	assert.Equal(t, 1+smallFilePrefetchMargin, mvf.smallFilePrefetch(0, 10*1024-1, 100))
+	assert.Equal(t, 2+smallFilePrefetchMargin, mvf.smallFilePrefetch(1000, 10*1024-1, 100),
+		"a read straddling a segment boundary needs both segments")
+	assert.Zero(t, mvf.smallFilePrefetch(0, 10*1024-1, 8*1024), "a cap at or above maxPrefetch is no cap")
+	assert.Zero(t, mvf.smallFilePrefetch(0, 10*1024-1, 0))
+
+	mvf.smallFileThreshold = 0
+	assert.Zero(t, mvf.smallFilePrefetch(0, 10*1024-1, 100), "disabled by default")
+}
@@ -0,0 +1,46 @@
+package nzbfilesystem
+
+import (
+	"log/slog"
+
+	metapb "github.com/javi11/altmount/internal/metadata/proto"
+)
+
+// applyStartSegment records segment hint for a freshly opened handle, for
+// clients resuming a stream by segment index (the X-Start-Segment header).
+// The handle's position is left alone: a reader starting at that segment's
+// first byte, as a resuming Range request does, begins there without looking
+// its offset up. Hints that don't fit the file — out of range, pointing at an
+// empty segment, or on a file whose byte positions are not segment positions
+// (encrypted or nested) — are ignored.
+func (mvf *MetadataVirtualFile) applyStartSegment(hint int) {
+	mvf.startSegment = -1
+	if len(mvf.meta.NestedSources) > 0 || mvf.meta.Encryption != metapb.Encryption_NONE ||
+		hint < 0 || hint >= len(mvf.meta.SegmentData) {
+		slog.DebugContext(mvf.ctx, "Ignoring start segment hint",
+			"file", mvf.name, "segment", hint, "segments", len(mvf.meta.SegmentData))
+		return
+	}
+
+	mvf.segmentIndexOnce.Do(func() {
+		mvf.segmentIndex = buildSegmentIndex(mvf.meta.SegmentData)
+	})
+	if mvf.segmentIndex.sizes[hint] <= 0 {
+		slog.DebugContext(mvf.ctx, "Ignoring start segment hint on an empty segment",
+			"file", mvf.name, "segment", hint)
+		return
+	}
+
+	mvf.startSegment = hint
+}
+
+// startSegmentFor returns the segment a reader starting at start begins in.
+// hint is used as is when start is exactly its first byte; otherwise, as
+// after a seek, the segment is found from the offset.
+func (idx *segmentOffsetIndex) startSegmentFor(hint int, start int64) int {
+	if idx != nil && hint >= 0 && hint < len(idx.offsets) &&
+		idx.sizes[hint] > 0 && idx.offsets[hint] == start {
+		return hint
+	}
+	return idx.findSegmentForOffset(start)
+}
@@ -0,0 +1,111 @@
+package nzbfilesystem
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/javi11/altmount/internal/config"
+	metapb "github.com/javi11/altmount/internal/metadata/proto"
+	"github.com/javi11/altmount/internal/testsupport/segments"
+	"github.com/javi11/altmount/internal/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStartSegment_ValidHintStartsAtSegment(t *testing.T) {
+	const n, segSize = 4, 1024
+	fp := staggeredPool(n, segSize, 0)
+	mvf := newTestMVF(t, context.Background(), fp, n, segSize, 1)
+	mvf.startSegment = -1
+
+	mvf.applyStartSegment(2)
+	require.Equal(t, 2, mvf.startSegment)
+
+	// The hint does not move the handle; the resuming read seeks there.
+	pos, err := mvf.Seek(0, io.SeekCurrent)
+	require.NoError(t, err)
+	assert.Zero(t, pos)
+	_, err = mvf.Seek(2*segSize, io.SeekStart)
+	require.NoError(t, err)
+
+	got, err := io.ReadAll(mvf)
+	require.NoError(t, err)
+	assert.Equal(t, wantFileBytes(n, segSize)[2*segSize:], got)
+	assert.Zero(t, fp.PerMessageCalls(segments.MessageID(0)))
+	assert.Zero(t, fp.PerMessageCalls(segments.MessageID(1)))
+}
+
+func TestStartSegment_InvalidHintIgnored(t *testing.T) {
+	const n, segSize = 4, 1024
+	for _, hint := range []int{-1, n, 100} {
+		mvf := newTestMVF(t, context.Background(), staggeredPool(n, segSize, 0), n, segSize, 1)
+		mvf.startSegment = -1
+
+		mvf.applyStartSegment(hint)
+		assert.Equal(t, -1, mvf.startSegment, "hint %d", hint)
+
+		got, err := io.ReadAll(mvf)
+		require.NoError(t, err)
+		assert.Equal(t, wantFileBytes(n, segSize), got, "hint %d", hint)
+	}
+}
+
+func TestStartSegmentFor(t *testing.T) {
+	idx := &segmentOffsetIndex{
+		offsets: []int64{0, 100, 100, 250},
+		sizes:   []int64{100, 0, 150, 50},
+	}
+	for _, tc := range []struct {
+		name  string
+		hint  int
+		start int64
+		want  int
+	}{
+		{"hint at its boundary", 3, 250, 3},
+		{"no hint", -1, 120, 2},
+		{"hint elsewhere after a seek", 3, 120, 2},
+		{"hint out of range", 9, 250, 3},
+		{"empty segment hint", 1, 100, 2},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, idx.startSegmentFor(tc.hint, tc.start))
+		})
+	}
+}
+
+func TestStartSegment_FromOpenContext(t *testing.T) {
+	repo, _, ms := setupStreamHealthEnv(t)
+	meta := ms.CreateFileMetadata(
+		3*1024, "test.nzb", metapb.FileStatus_FILE_STATUS_HEALTHY,
+		buildSegmentData(t, 3, 1024), metapb.Encryption_NONE, "", "", nil, nil, 0, nil, "",
+	)
+	require.NoError(t, ms.WriteFileMetadata("complete/movie.mkv", meta))
+	cfg := config.DefaultConfig()
+	mrf := &MetadataRemoteFile{
+		metadataService:  ms,
+		healthRepository: repo,
+		configGetter:     func() *config.Config { return cfg },
+	}
+
+	for _, tc := range []struct {
+		name    string
+		ctx     context.Context
+		wantSeg int
+	}{
+		{"no hint", context.Background(), -1},
+		{"valid hint", context.WithValue(context.Background(), utils.StartSegmentKey, 1), 1},
+		{"invalid hint", context.WithValue(context.Background(), utils.StartSegmentKey, 3), -1},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ok, f, err := mrf.OpenFile(tc.ctx, "/complete/movie.mkv")
+			require.NoError(t, err)
+			require.True(t, ok)
+			defer f.Close()
+			assert.Equal(t, tc.wantSeg, f.(*MetadataVirtualFile).startSegment)
+			pos, err := f.Seek(0, io.SeekCurrent)
+			require.NoError(t, err)
+			assert.Zero(t, pos)
+		})
+	}
+}
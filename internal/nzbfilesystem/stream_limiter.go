@@ -0,0 +1,95 @@
+package nzbfilesystem
+
+import (
+	"net"
+	"strings"
+	"sync"
+
+	"github.com/javi11/altmount/internal/config"
+)
+
+// StreamLimiter caps the number of concurrently open file streams per client IP
+// (Streaming.MaxStreamsPerIP). Slots are taken in OpenFile and handed back when
+// the file handle is closed. Limits are read from config on every acquire, so
+// changes apply to new opens without a restart.
+type StreamLimiter struct {
+	configGetter config.ConfigGetter
+
+	mu     sync.Mutex
+	active map[string]int
+}
+
+// NewStreamLimiter creates a per-IP stream limiter backed by the given config.
+func NewStreamLimiter(configGetter config.ConfigGetter) *StreamLimiter {
+	return &StreamLimiter{
+		configGetter: configGetter,
+		active:       make(map[string]int),
+	}
+}
+
+// Acquire reserves a stream slot for clientAddr. It returns a release func that
+// must be called exactly once when the stream ends, or ErrTooManyStreams when
+// the IP already holds MaxStreamsPerIP streams. Requests with no client address
+// (FUSE, internal callers) and, unless disabled, loopback clients are never
+// limited; they get a no-op release.
+func (l *StreamLimiter) Acquire(clientAddr string) (func(), error) {
+	noop := func() {}
+	if l == nil {
+		return noop, nil
+	}
+
+	cfg := l.configGetter()
+	limit := cfg.Streaming.MaxStreamsPerIP
+	if limit <= 0 {
+		return noop, nil
+	}
+
+	ip := clientIPFromAddr(clientAddr)
+	if ip == "" {
+		return noop, nil
+	}
+	exemptLoopback := cfg.Streaming.ExemptLoopbackStreams == nil || *cfg.Streaming.ExemptLoopbackStreams
+	if exemptLoopback {
+		if parsed := net.ParseIP(ip); parsed != nil && parsed.IsLoopback() {
+			return noop, nil
+		}
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.active[ip] >= limit {
+		return nil, &TooManyStreamsError{ClientIP: ip, Limit: limit}
+	}
+	l.active[ip]++
+
+	var once sync.Once
+	return func() {
+		once.Do(func() { l.release(ip) })
+	}, nil
+}
+
+func (l *StreamLimiter) release(ip string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.active[ip] <= 1 {
+		delete(l.active, ip)
+		return
+	}
+	l.active[ip]--
+}
+
+// Active returns the number of streams currently held by clientAddr.
+func (l *StreamLimiter) Active(clientAddr string) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.active[clientIPFromAddr(clientAddr)]
+}
+
+// clientIPFromAddr strips the port from a "host:port" remote address. Bare
+// IPs (with or without IPv6 brackets) are returned unchanged.
+func clientIPFromAddr(addr string) string {
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		return host
+	}
+	return strings.Trim(addr, "[]")
+}
@@ -0,0 +1,118 @@
+package nzbfilesystem
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/javi11/altmount/internal/config"
+	"github.com/javi11/altmount/internal/metadata"
+	metapb "github.com/javi11/altmount/internal/metadata/proto"
+	"github.com/javi11/altmount/internal/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newLimitedRemoteFile(t *testing.T, cfg *config.Config) *MetadataRemoteFile {
+	t.Helper()
+	ms := metadata.NewMetadataService(t.TempDir())
+	meta := ms.CreateFileMetadata(
+		1024, "test.nzb", metapb.FileStatus_FILE_STATUS_HEALTHY,
+		nil, metapb.Encryption_NONE, "", "", nil, nil, 0, nil, "",
+	)
+	require.NoError(t, ms.WriteFileMetadata("movies/film.mkv", meta))
+
+	getter := func() *config.Config { return cfg }
+	return &MetadataRemoteFile{
+		metadataService: ms,
+		configGetter:    getter,
+		streamLimiter:   NewStreamLimiter(getter),
+	}
+}
+
+func openAs(t *testing.T, mrf *MetadataRemoteFile, remoteAddr string) (*MetadataVirtualFile, error) {
+	t.Helper()
+	ctx := context.WithValue(context.Background(), utils.ClientIPKey, remoteAddr)
+	ok, f, err := mrf.OpenFile(ctx, "movies/film.mkv")
+	if err != nil {
+		return nil, err
+	}
+	require.True(t, ok)
+	return f.(*MetadataVirtualFile), nil
+}
+
+func TestOpenFile_MaxStreamsPerIP(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Streaming.MaxStreamsPerIP = 2
+	mrf := newLimitedRemoteFile(t, cfg)
+
+	first, err := openAs(t, mrf, "10.0.0.1:50000")
+	require.NoError(t, err)
+	_, err = openAs(t, mrf, "10.0.0.1:50001")
+	require.NoError(t, err)
+
+	// N+1th stream from the same IP (different source port) is rejected.
+	_, err = openAs(t, mrf, "10.0.0.1:50002")
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrTooManyStreams))
+	var tooMany *TooManyStreamsError
+	require.True(t, errors.As(err, &tooMany))
+	assert.Equal(t, "10.0.0.1", tooMany.ClientIP)
+	assert.Equal(t, 2, tooMany.Limit)
+
+	// Another IP is unaffected.
+	_, err = openAs(t, mrf, "10.0.0.2:40000")
+	require.NoError(t, err)
+
+	// Closing a stream frees its slot; closing twice does not free two.
+	require.NoError(t, first.Close())
+	require.NoError(t, first.Close())
+	assert.Equal(t, 1, mrf.streamLimiter.Active("10.0.0.1:1"))
+	_, err = openAs(t, mrf, "10.0.0.1:50003")
+	require.NoError(t, err)
+	_, err = openAs(t, mrf, "10.0.0.1:50004")
+	assert.ErrorIs(t, err, ErrTooManyStreams)
+}
+
+func TestStreamLimiter_LoopbackExemption(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Streaming.MaxStreamsPerIP = 1
+	limiter := NewStreamLimiter(func() *config.Config { return cfg })
+
+	// Loopback is exempt by default.
+	for range 3 {
+		_, err := limiter.Acquire("127.0.0.1:1234")
+		require.NoError(t, err)
+	}
+	_, err := limiter.Acquire("[::1]:1234")
+	require.NoError(t, err)
+
+	// Disabling the exemption applies the limit to loopback too.
+	exempt := false
+	cfg.Streaming.ExemptLoopbackStreams = &exempt
+	release, err := limiter.Acquire("127.0.0.1:1234")
+	require.NoError(t, err)
+	_, err = limiter.Acquire("127.0.0.1:1235")
+	assert.ErrorIs(t, err, ErrTooManyStreams)
+	release()
+	_, err = limiter.Acquire("127.0.0.1:1235")
+	assert.NoError(t, err)
+}
+
+func TestStreamLimiter_NoLimitOrNoClient(t *testing.T) {
+	cfg := &config.Config{}
+	limiter := NewStreamLimiter(func() *config.Config { return cfg })
+
+	// Unlimited when MaxStreamsPerIP is 0.
+	for range 5 {
+		_, err := limiter.Acquire("10.0.0.1:1")
+		require.NoError(t, err)
+	}
+
+	// Requests without a client address (FUSE) are never limited.
+	cfg.Streaming.MaxStreamsPerIP = 1
+	for range 3 {
+		_, err := limiter.Acquire("")
+		require.NoError(t, err)
+	}
+}
@@ -0,0 +1,46 @@
+package nzbfilesystem
+
+import "time"
+
+// trackerBatch holds stream tracker updates that have not been published yet.
+// Reads record into it and it is flushed at most once per
+// Streaming.TrackerUpdateIntervalMs, so handles reading in small chunks do not
+// take the tracker's locks on every read. Guarded by mvf.mu.
+type trackerBatch struct {
+	bytes     int64     // bytes read since the last flush
+	offset    int64     // file offset after the last recorded read
+	lastFlush time.Time // when the batch was last published
+}
+
+// recordProgress notes that n bytes were read, leaving the handle at offset,
+// and publishes the batch if the update interval has elapsed or the read
+// reached the end of the file. Caller must hold mvf.mu.
+func (mvf *MetadataVirtualFile) recordProgress(n int, offset int64) {
+	if n <= 0 || mvf.streamTracker == nil || mvf.streamID == "" {
+		return
+	}
+	mvf.progress.bytes += int64(n)
+	mvf.progress.offset = offset
+	atEOF := mvf.meta != nil && offset >= mvf.meta.FileSize
+	if !atEOF && mvf.trackerUpdateInterval > 0 && time.Since(mvf.progress.lastFlush) < mvf.trackerUpdateInterval {
+		return
+	}
+	mvf.flushProgress()
+}
+
+// flushProgress publishes any batched progress to the stream tracker. Caller
+// must hold mvf.mu.
+func (mvf *MetadataVirtualFile) flushProgress() {
+	if mvf.progress.bytes == 0 || mvf.streamTracker == nil || mvf.streamID == "" {
+		return
+	}
+	mvf.streamTracker.UpdateProgress(mvf.streamID, mvf.progress.bytes)
+	mvf.streamTracker.UpdateCurrentOffset(mvf.streamID, mvf.progress.offset)
+	if mvf.bufOffReader != nil {
+		mvf.streamTracker.UpdateBufferedOffset(mvf.streamID, mvf.bufOffReader.GetBufferedOffset())
+	}
+	mvf.progress.bytes = 0
+	if mvf.trackerUpdateInterval > 0 {
+		mvf.progress.lastFlush = time.Now()
+	}
+}
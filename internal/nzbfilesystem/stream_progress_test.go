@@ -0,0 +1,150 @@
+package nzbfilesystem
+
+import (
+	"context"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/javi11/altmount/internal/testsupport/fakepool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingStreamTracker records the progress a handle publishes and how many
+// tracker calls it took.
+type countingStreamTracker struct {
+	noopStreamTracker
+	mu      sync.Mutex
+	calls   int
+	bytes   int64
+	offset  int64
+	removed bool
+}
+
+func (c *countingStreamTracker) UpdateProgress(_ string, n int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.calls++
+	c.bytes += n
+}
+
+func (c *countingStreamTracker) UpdateCurrentOffset(_ string, off int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.calls++
+	c.offset = off
+}
+
+func (c *countingStreamTracker) UpdateBufferedOffset(_ string, _ int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.calls++
+}
+
+func (c *countingStreamTracker) Remove(_ string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.removed = true
+}
+
+func newTrackedMVF(t testing.TB, n, segSize int, interval time.Duration) (*MetadataVirtualFile, *countingStreamTracker) {
+	t.Helper()
+	fp := fakepool.New()
+	configurePoolForFile(fp, n, segSize, fakepool.SegmentBehavior{})
+	mvf := newTestMVF(t, context.Background(), fp, n, segSize, 4)
+	tracker := &countingStreamTracker{}
+	mvf.streamTracker = tracker
+	mvf.trackerUpdateInterval = interval
+	return mvf, tracker
+}
+
+// readInChunks reads up to limit bytes from mvf in chunk-sized reads.
+func readInChunks(t testing.TB, mvf *MetadataVirtualFile, chunk int, limit int64) int64 {
+	t.Helper()
+	buf := make([]byte, chunk)
+	var total int64
+	for total < limit {
+		n, err := mvf.Read(buf[:min(int64(chunk), limit-total)])
+		total += int64(n)
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+	}
+	return total
+}
+
+func TestTrackerBatch_FinalProgressIsExact(t *testing.T) {
+	const segCount, segSize = 8, 4096
+	mvf, tracker := newTrackedMVF(t, segCount, segSize, time.Hour)
+
+	// Stop mid-file so the end-of-file flush cannot publish the bytes.
+	half := int64(segCount * segSize / 2)
+	require.Equal(t, half, readInChunks(t, mvf, 512, half))
+	tracker.mu.Lock()
+	assert.Less(t, tracker.bytes, half, "reads within the interval are batched")
+	tracker.mu.Unlock()
+
+	require.NoError(t, mvf.Close())
+	tracker.mu.Lock()
+	defer tracker.mu.Unlock()
+	assert.Equal(t, half, tracker.bytes, "close publishes every batched byte")
+	assert.Equal(t, half, tracker.offset)
+	assert.True(t, tracker.removed)
+}
+
+func TestTrackerBatch_SeekPublishesPendingFirst(t *testing.T) {
+	const segCount, segSize = 8, 4096
+	mvf, tracker := newTrackedMVF(t, segCount, segSize, time.Hour)
+
+	readInChunks(t, mvf, 512, 4096)
+	_, err := mvf.Seek(0, io.SeekStart)
+	require.NoError(t, err)
+
+	tracker.mu.Lock()
+	defer tracker.mu.Unlock()
+	assert.Equal(t, int64(4096), tracker.bytes)
+	assert.Zero(t, tracker.offset, "the seek's offset is not overwritten by the batched read's")
+}
+
+func TestTrackerBatch_EOFPublishes(t *testing.T) {
+	const segCount, segSize = 4, 4096
+	mvf, tracker := newTrackedMVF(t, segCount, segSize, time.Hour)
+
+	size := int64(segCount * segSize)
+	require.Equal(t, size, readInChunks(t, mvf, 512, size+1))
+
+	tracker.mu.Lock()
+	defer tracker.mu.Unlock()
+	assert.Equal(t, size, tracker.bytes)
+	assert.Equal(t, size, tracker.offset)
+}
+
+// BenchmarkTrackerUpdates reads a file in small chunks and reports the stream
+// tracker calls per read with and without batching.
+func BenchmarkTrackerUpdates(b *testing.B) {
+	const segCount, segSize, chunk = 16, 64 * 1024, 512
+	for _, bc := range []struct {
+		name     string
+		interval time.Duration
+	}{
+		{"every-read", 0},
+		{"batched-250ms", 250 * time.Millisecond},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			var calls, reads int
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				mvf, tracker := newTrackedMVF(b, segCount, segSize, bc.interval)
+				b.StartTimer()
+				readInChunks(b, mvf, chunk, int64(segCount*segSize))
+				_ = mvf.Close()
+				calls += tracker.calls
+				reads += segCount * segSize / chunk
+			}
+			b.ReportMetric(float64(calls)/float64(reads), "tracker-calls/read")
+		})
+	}
+}
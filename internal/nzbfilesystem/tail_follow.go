@@ -0,0 +1,102 @@
+package nzbfilesystem
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// tailPollInterval is how often a read waiting at the end of a growing file
+// re-reads the file's metadata.
+var tailPollInterval = 100 * time.Millisecond
+
+// tailFollowEligible reports whether reads at this handle's end may wait for
+// the file to grow: only files whose metadata marks them as growing, and only
+// with Streaming.TailWaitMs set. A file that merely lacks a recorded size is
+// complete and returns EOF at once.
+func (mvf *MetadataVirtualFile) tailFollowEligible() bool {
+	return mvf.tailWait > 0 && mvf.meta.Growing && mvf.metadataService != nil
+}
+
+// waitForGrowth is called by a read at off, at or past the end of the file.
+// For an eligible handle it polls the file's metadata for up to
+// Streaming.TailWaitMs and reports whether the file grew past off, in which
+// case the handle now serves the longer segment list. It returns false once
+// the wait runs out or the file stops growing, and ctx's error if ctx ends
+// first.
+//
+// Callers must hold mvf.mu; it is released while waiting so Close and other
+// reads are not held up, and is held again on return. ErrFileClosed is
+// returned when the handle was closed meanwhile.
+func (mvf *MetadataVirtualFile) waitForGrowth(ctx context.Context, off int64) (bool, error) {
+	if !mvf.tailFollowEligible() {
+		return false, nil
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	mvf.mu.Unlock()
+	relocked := false
+	defer func() {
+		if !relocked {
+			mvf.mu.Lock()
+		}
+	}()
+
+	deadline := time.NewTimer(mvf.tailWait)
+	defer deadline.Stop()
+	ticker := time.NewTicker(tailPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return false, ctx.Err()
+		case <-deadline.C:
+			return false, nil
+		case <-ticker.C:
+		}
+
+		fresh, err := mvf.metadataService.ReadFileMetadata(mvf.name)
+		if err != nil || fresh == nil {
+			slog.DebugContext(ctx, "Failed to re-read metadata of growing file",
+				"file", mvf.name, "error", err)
+			continue
+		}
+		grown := newFileHandleMeta(fresh)
+		if grown.FileSize <= off {
+			if !grown.Growing {
+				// The import finished without reaching off.
+				return false, nil
+			}
+			continue
+		}
+
+		mvf.mu.Lock()
+		relocked = true
+		if mvf.meta == nil {
+			return false, ErrFileClosed
+		}
+		// Another read on this handle may have picked the growth up already.
+		if grown.FileSize > mvf.meta.FileSize {
+			mvf.adoptGrownMeta(grown)
+		}
+		return mvf.meta.FileSize > off, nil
+	}
+}
+
+// adoptGrownMeta switches the handle to a longer version of its file. The
+// reader ends at the old size, so it is dropped and the next read opens one
+// over the new segments. Callers must hold mvf.mu.
+func (mvf *MetadataVirtualFile) adoptGrownMeta(grown *fileHandleMeta) {
+	slog.DebugContext(mvf.ctx, "Growing file got more data",
+		"file", mvf.name,
+		"old_size", mvf.meta.FileSize,
+		"new_size", grown.FileSize)
+	mvf.closeCurrentReader()
+	mvf.meta = grown
+	mvf.segmentIndex = nil
+	mvf.segmentIndexOnce = sync.Once{}
+}
@@ -0,0 +1,141 @@
+package nzbfilesystem
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/javi11/altmount/internal/metadata"
+	metapb "github.com/javi11/altmount/internal/metadata/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	tailTestSegs    = 4
+	tailTestSegSize = 1024
+	tailTestName    = "tail/growing.mkv"
+)
+
+// writeGrowingMeta stores tailTestName with the first n segments and no
+// recorded size, marked as growing while its import is still running.
+func writeGrowingMeta(t *testing.T, ms *metadata.MetadataService, n int, growing bool) {
+	t.Helper()
+	meta := ms.CreateFileMetadata(
+		0, "growing.nzb", metapb.FileStatus_FILE_STATUS_HEALTHY,
+		buildSegmentData(t, tailTestSegs, tailTestSegSize)[:n],
+		metapb.Encryption_NONE, "", "", nil, nil, 0, nil, "",
+	)
+	meta.Growing = growing
+	require.NoError(t, ms.WriteFileMetadata(tailTestName, meta))
+}
+
+// newGrowingMVF opens tailTestName, currently holding its first two
+// segments, with reads at the end waiting up to wait for more.
+func newGrowingMVF(t *testing.T, ctx context.Context, wait time.Duration) (*MetadataVirtualFile, *metadata.MetadataService) {
+	t.Helper()
+	ms := metadata.NewMetadataService(t.TempDir())
+	writeGrowingMeta(t, ms, 2, true)
+	stored, err := ms.ReadFileMetadata(tailTestName)
+	require.NoError(t, err)
+
+	mvf := newTestMVF(t, ctx, staggeredPool(tailTestSegs, tailTestSegSize, 0), tailTestSegs, tailTestSegSize, tailTestSegs)
+	mvf.name = tailTestName
+	mvf.meta = newFileHandleMeta(stored)
+	require.True(t, mvf.meta.Growing)
+	mvf.metadataService = ms
+	mvf.tailWait = wait
+	return mvf, ms
+}
+
+func TestTailFollow_WaitsThenEOFWithoutGrowth(t *testing.T) {
+	ctx := context.Background()
+	const wait = 300 * time.Millisecond
+	mvf, _ := newGrowingMVF(t, ctx, wait)
+
+	got := make([]byte, 2*tailTestSegSize)
+	_, err := io.ReadFull(mvf, got)
+	require.NoError(t, err)
+	assert.Equal(t, wantFileBytes(2, tailTestSegSize), got)
+
+	start := time.Now()
+	n, err := mvf.Read(make([]byte, 16))
+	assert.Zero(t, n)
+	assert.ErrorIs(t, err, io.EOF)
+	assert.GreaterOrEqual(t, time.Since(start), wait, "the read at the end waits before giving up")
+
+	start = time.Now()
+	_, err = mvf.ReadAt(make([]byte, 16), 2*tailTestSegSize)
+	assert.ErrorIs(t, err, io.EOF)
+	assert.GreaterOrEqual(t, time.Since(start), wait)
+}
+
+func TestTailFollow_ReadContinuesWhenFileGrows(t *testing.T) {
+	ctx := context.Background()
+	mvf, ms := newGrowingMVF(t, ctx, 5*time.Second)
+
+	head := make([]byte, 2*tailTestSegSize)
+	_, err := io.ReadFull(mvf, head)
+	require.NoError(t, err)
+
+	go func() {
+		time.Sleep(150 * time.Millisecond)
+		writeGrowingMeta(t, ms, tailTestSegs, false)
+	}()
+
+	start := time.Now()
+	rest, err := io.ReadAll(mvf)
+	require.NoError(t, err, "io.ReadAll treats the final EOF as success")
+	assert.Less(t, time.Since(start), 5*time.Second+time.Second)
+	assert.True(t, bytes.Equal(wantFileBytes(tailTestSegs, tailTestSegSize), append(head, rest...)))
+	assert.Equal(t, int64(tailTestSegs*tailTestSegSize), mvf.meta.FileSize)
+}
+
+func TestTailFollow_ReadAtContextCancelled(t *testing.T) {
+	mvf, _ := newGrowingMVF(t, context.Background(), 5*time.Second)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := mvf.ReadAtContext(ctx, make([]byte, 16), 2*tailTestSegSize)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
+
+func TestTailFollow_StopsWhenImportFinishes(t *testing.T) {
+	mvf, ms := newGrowingMVF(t, context.Background(), 5*time.Second)
+
+	go func() {
+		time.Sleep(150 * time.Millisecond)
+		writeGrowingMeta(t, ms, 2, false)
+	}()
+
+	start := time.Now()
+	_, err := mvf.ReadAt(make([]byte, 16), 2*tailTestSegSize)
+	assert.ErrorIs(t, err, io.EOF)
+	assert.Less(t, time.Since(start), time.Second, "no need to wait out tail_wait_ms")
+}
+
+func TestTailFollow_OnlyForGrowingFiles(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("disabled", func(t *testing.T) {
+		mvf, _ := newGrowingMVF(t, ctx, 0)
+		start := time.Now()
+		_, err := mvf.ReadAt(make([]byte, 16), 2*tailTestSegSize)
+		assert.ErrorIs(t, err, io.EOF)
+		assert.Less(t, time.Since(start), tailPollInterval)
+	})
+
+	t.Run("size unknown but not growing", func(t *testing.T) {
+		mvf, _ := newGrowingMVF(t, ctx, 5*time.Second)
+		mvf.meta.Growing = false
+		require.True(t, mvf.meta.SizeUnknown)
+		start := time.Now()
+		_, err := mvf.ReadAt(make([]byte, 16), 2*tailTestSegSize)
+		assert.ErrorIs(t, err, io.EOF)
+		assert.Less(t, time.Since(start), tailPollInterval)
+	})
+}
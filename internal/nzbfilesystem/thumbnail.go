@@ -0,0 +1,200 @@
+package nzbfilesystem
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+const (
+	// DefaultThumbnailMaxBytes bounds the region OpenThumbnailSource exposes
+	// when the caller does not pass a limit.
+	DefaultThumbnailMaxBytes int64 = 8 << 20
+	// thumbnailSniffSize is how much of the file head is read to detect the
+	// container.
+	thumbnailSniffSize = 4096
+	// maxMP4TopLevelBoxes caps the top-level box walk looking for moov, so a
+	// malformed file cannot turn the probe into a long series of fetches.
+	maxMP4TopLevelBoxes = 64
+)
+
+// SniffContentType guesses a media content type from a file's leading bytes.
+// Containers common on Usenet are matched by their magic bytes; anything else
+// falls back to net/http's sniffer.
+func SniffContentType(head []byte) string {
+	switch {
+	case len(head) >= 12 && string(head[4:8]) == "ftyp":
+		if string(head[8:12]) == "qt  " {
+			return "video/quicktime"
+		}
+		return "video/mp4"
+	case bytes.HasPrefix(head, []byte{0x1A, 0x45, 0xDF, 0xA3}):
+		// The EBML header carries the DocType near the start of the file.
+		if bytes.Contains(head[:min(len(head), 64)], []byte("webm")) {
+			return "video/webm"
+		}
+		return "video/x-matroska"
+	case len(head) >= 12 && string(head[0:4]) == "RIFF" && string(head[8:12]) == "AVI ":
+		return "video/x-msvideo"
+	case len(head) > 188 && head[0] == 0x47 && head[188] == 0x47:
+		return "video/mp2t"
+	}
+	return http.DetectContentType(head)
+}
+
+// OpenThumbnailSource returns a bounded reader over the part of the file an
+// external thumbnailer needs to probe it, plus the detected content type.
+// For MP4 that is the ftyp and moov boxes (contiguous when moov is at the
+// front, spliced together when it trails the media data); for every other
+// container it is the first maxBytes of the file. maxBytes <= 0 uses
+// DefaultThumbnailMaxBytes. The caller must close the returned reader.
+func (mvf *MetadataVirtualFile) OpenThumbnailSource(maxBytes int64) (io.ReadCloser, string, error) {
+	if maxBytes <= 0 {
+		maxBytes = DefaultThumbnailMaxBytes
+	}
+
+	mvf.mu.Lock()
+	defer mvf.mu.Unlock()
+
+	if mvf.meta == nil {
+		return nil, "", ErrFileClosed
+	}
+	fileSize := mvf.meta.FileSize
+	if fileSize <= 0 {
+		return nil, "", io.EOF
+	}
+
+	head, err := mvf.readRange(0, min(fileSize, thumbnailSniffSize))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read file head: %w", err)
+	}
+	contentType := SniffContentType(head)
+
+	if contentType == "video/mp4" || contentType == "video/quicktime" {
+		ftyp, moov, err := mvf.locateMP4Boxes(fileSize)
+		if err != nil {
+			return nil, "", err
+		}
+		if moov.size > 0 {
+			if moov.end() <= maxBytes {
+				// Fast-start layout: everything up to the end of moov.
+				r, err := mvf.createReaderAtOffset(0, moov.end()-1)
+				if err != nil {
+					return nil, "", err
+				}
+				return r, contentType, nil
+			}
+			if ftyp.size+moov.size <= maxBytes {
+				return mvf.spliceRanges(contentType, ftyp, moov)
+			}
+			return nil, "", fmt.Errorf("mp4 header region of %d bytes exceeds limit %d", ftyp.size+moov.size, maxBytes)
+		}
+		// No moov among the top-level boxes (e.g. fragmented MP4): fall back to
+		// the leading bytes.
+	}
+
+	r, err := mvf.createReaderAtOffset(0, min(fileSize, maxBytes)-1)
+	if err != nil {
+		return nil, "", err
+	}
+	return r, contentType, nil
+}
+
+// byteRange is a [start, start+size) region of the file.
+type byteRange struct {
+	start, size int64
+}
+
+func (b byteRange) end() int64 { return b.start + b.size }
+
+// locateMP4Boxes walks the top-level MP4 boxes and returns the ftyp and moov
+// regions. A zero-size moov means it was not found.
+func (mvf *MetadataVirtualFile) locateMP4Boxes(fileSize int64) (ftyp, moov byteRange, err error) {
+	var off int64
+	for range maxMP4TopLevelBoxes {
+		if off+8 > fileSize {
+			break
+		}
+		hdr, err := mvf.readRange(off, min(16, fileSize-off))
+		if err != nil {
+			return ftyp, moov, fmt.Errorf("failed to read mp4 box header at %d: %w", off, err)
+		}
+
+		size := int64(binary.BigEndian.Uint32(hdr[0:4]))
+		boxType := string(hdr[4:8])
+		switch size {
+		case 0:
+			size = fileSize - off // box extends to end of file
+		case 1:
+			if len(hdr) < 16 {
+				return ftyp, moov, fmt.Errorf("truncated mp4 box header at %d", off)
+			}
+			size = int64(binary.BigEndian.Uint64(hdr[8:16]))
+		}
+		if size < 8 || off+size > fileSize {
+			return ftyp, moov, fmt.Errorf("invalid mp4 box %q at %d with size %d", boxType, off, size)
+		}
+
+		switch boxType {
+		case "ftyp":
+			ftyp = byteRange{start: off, size: size}
+		case "moov":
+			moov = byteRange{start: off, size: size}
+			return ftyp, moov, nil
+		}
+		off += size
+	}
+	return ftyp, moov, nil
+}
+
+// readRange reads exactly n bytes at off through a short-lived reader.
+func (mvf *MetadataVirtualFile) readRange(off, n int64) ([]byte, error) {
+	r, err := mvf.createReaderAtOffset(off, off+n-1)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	buf := make([]byte, n)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
+
+// spliceRanges returns a single reader that streams each range in turn.
+func (mvf *MetadataVirtualFile) spliceRanges(contentType string, ranges ...byteRange) (io.ReadCloser, string, error) {
+	readers := make([]io.Reader, 0, len(ranges))
+	closers := make(multiCloser, 0, len(ranges))
+	for _, br := range ranges {
+		if br.size == 0 {
+			continue
+		}
+		r, err := mvf.createReaderAtOffset(br.start, br.end()-1)
+		if err != nil {
+			_ = closers.Close()
+			return nil, "", err
+		}
+		readers = append(readers, r)
+		closers = append(closers, r)
+	}
+	return struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(readers...), closers}, contentType, nil
+}
+
+// multiCloser closes every reader, returning the first error.
+type multiCloser []io.Closer
+
+func (m multiCloser) Close() error {
+	var first error
+	for _, c := range m {
+		if err := c.Close(); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
@@ -0,0 +1,125 @@
+package nzbfilesystem
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"testing"
+
+	"github.com/javi11/altmount/internal/testsupport/fakepool"
+	"github.com/javi11/altmount/internal/testsupport/segments"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const thumbTestSegSize = 1024
+
+// mp4Box encodes a top-level MP4 box around payload.
+func mp4Box(boxType string, payload []byte) []byte {
+	out := make([]byte, 8, 8+len(payload))
+	binary.BigEndian.PutUint32(out[0:4], uint32(8+len(payload)))
+	copy(out[4:8], boxType)
+	return append(out, payload...)
+}
+
+func filler(n int, b byte) []byte { return bytes.Repeat([]byte{b}, n) }
+
+// newThumbnailTestMVF serves data (a multiple of thumbTestSegSize) from the
+// fake pool as a plain single-file handle.
+func newThumbnailTestMVF(t *testing.T, data []byte) *MetadataVirtualFile {
+	t.Helper()
+	require.Zero(t, len(data)%thumbTestSegSize, "fixture must fill whole segments")
+	n := len(data) / thumbTestSegSize
+
+	fp := fakepool.New()
+	for i := range n {
+		fp.SetBehavior(segments.MessageID(i), fakepool.SegmentBehavior{
+			Bytes: data[i*thumbTestSegSize : (i+1)*thumbTestSegSize],
+		})
+	}
+	return newTestMVF(t, context.Background(), fp, n, thumbTestSegSize, 4)
+}
+
+// mp4Fixture lays out ftyp, mdat and moov, with moov either first or last;
+// the mdat is sized so the file fills exactly segs segments.
+func mp4Fixture(moovFirst bool, segs int) (file, ftyp, moov []byte) {
+	ftyp = mp4Box("ftyp", []byte("isom\x00\x00\x02\x00isomiso2avc1mp41"))
+	moov = mp4Box("moov", filler(300, 0xAB))
+	mdat := mp4Box("mdat", filler(segs*thumbTestSegSize-len(ftyp)-len(moov)-8, 0xCD))
+
+	file = append([]byte{}, ftyp...)
+	if moovFirst {
+		file = append(append(file, moov...), mdat...)
+	} else {
+		file = append(append(file, mdat...), moov...)
+	}
+	return file, ftyp, moov
+}
+
+func TestOpenThumbnailSource_MP4FastStart(t *testing.T) {
+	file, ftyp, moov := mp4Fixture(true, 6)
+	mvf := newThumbnailTestMVF(t, file)
+
+	r, contentType, err := mvf.OpenThumbnailSource(0)
+	require.NoError(t, err)
+	defer r.Close()
+	got, err := io.ReadAll(r)
+	require.NoError(t, err)
+
+	assert.Equal(t, "video/mp4", contentType)
+	assert.Equal(t, file[:len(ftyp)+len(moov)], got, "fast-start region is the file head through moov")
+}
+
+func TestOpenThumbnailSource_MP4TrailingMoov(t *testing.T) {
+	file, ftyp, moov := mp4Fixture(false, 6)
+	mvf := newThumbnailTestMVF(t, file)
+
+	// The limit is smaller than the whole file, so the trailing moov must be
+	// spliced after ftyp rather than reached by reading through mdat.
+	r, contentType, err := mvf.OpenThumbnailSource(2048)
+	require.NoError(t, err)
+	defer r.Close()
+	got, err := io.ReadAll(r)
+	require.NoError(t, err)
+
+	assert.Equal(t, "video/mp4", contentType)
+	assert.Equal(t, append(append([]byte{}, ftyp...), moov...), got)
+
+	_, _, err = mvf.OpenThumbnailSource(100)
+	assert.Error(t, err, "header region larger than the limit is rejected")
+}
+
+func TestOpenThumbnailSource_MKVReturnsLeadingBytes(t *testing.T) {
+	file := filler(4*thumbTestSegSize, 0x11)
+	copy(file, []byte{0x1A, 0x45, 0xDF, 0xA3, 0x9F, 0x42, 0x82, 0x88})
+	copy(file[8:], "matroska")
+	mvf := newThumbnailTestMVF(t, file)
+
+	r, contentType, err := mvf.OpenThumbnailSource(1500)
+	require.NoError(t, err)
+	defer r.Close()
+	got, err := io.ReadAll(r)
+	require.NoError(t, err)
+
+	assert.Equal(t, "video/x-matroska", contentType)
+	assert.Equal(t, file[:1500], got)
+}
+
+func TestSniffContentType(t *testing.T) {
+	ts := make([]byte, 400)
+	ts[0], ts[188], ts[376] = 0x47, 0x47, 0x47
+
+	cases := map[string][]byte{
+		"video/mp4":        mp4Box("ftyp", []byte("mp42\x00\x00\x00\x00")),
+		"video/quicktime":  mp4Box("ftyp", []byte("qt  \x00\x00\x00\x00")),
+		"video/webm":       append([]byte{0x1A, 0x45, 0xDF, 0xA3, 0x9F, 0x42, 0x82, 0x84}, "webm"...),
+		"video/x-matroska": append([]byte{0x1A, 0x45, 0xDF, 0xA3, 0x9F, 0x42, 0x82, 0x88}, "matroska"...),
+		"video/x-msvideo":  []byte("RIFF\x00\x00\x00\x00AVI LIST"),
+		"video/mp2t":       ts,
+	}
+	for want, head := range cases {
+		assert.Equal(t, want, SniffContentType(head), want)
+	}
+	assert.Equal(t, "text/plain; charset=utf-8", SniffContentType([]byte("hello")))
+}
@@ -0,0 +1,117 @@
+package nzbfilesystem
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"io"
+	"testing"
+
+	"github.com/javi11/altmount/internal/config"
+	"github.com/javi11/altmount/internal/encryption"
+	aescipher "github.com/javi11/altmount/internal/encryption/aes"
+	"github.com/javi11/altmount/internal/encryption/rclone"
+	metapb "github.com/javi11/altmount/internal/metadata/proto"
+	"github.com/javi11/altmount/internal/testsupport/fakepool"
+	"github.com/javi11/altmount/internal/testsupport/segments"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newRcloneCandidate serves plaintext, rclone-encrypted with password/salt, as a
+// single fake segment and returns matching metadata without credentials.
+func newRcloneCandidate(t *testing.T, fp *fakepool.Client, password, salt string, plaintext []byte) *metapb.FileMetadata {
+	t.Helper()
+	c, err := rclone.NewCipher(rclone.NameEncryptionOff, "", "", false, nil)
+	require.NoError(t, err)
+	k, err := rclone.GenerateKey(password, salt)
+	require.NoError(t, err)
+	r, err := c.EncryptData(bytes.NewReader(plaintext), k)
+	require.NoError(t, err)
+	ciphertext, err := io.ReadAll(r)
+	require.NoError(t, err)
+
+	fp.SetBehavior(segments.MessageID(0), fakepool.SegmentBehavior{Bytes: ciphertext})
+	return &metapb.FileMetadata{
+		FileSize:   int64(len(plaintext)),
+		Encryption: metapb.Encryption_RCLONE,
+		SegmentData: []*metapb.SegmentData{{
+			Id:          segments.MessageID(0),
+			SegmentSize: int64(len(ciphertext)),
+			EndOffset:   int64(len(ciphertext) - 1),
+		}},
+	}
+}
+
+func TestVerifyCredentials_RcloneSample(t *testing.T) {
+	fp := fakepool.New()
+	candidate := newRcloneCandidate(t, fp, "right-pass", "salt", bytes.Repeat([]byte("altmount"), 512))
+
+	rcloneCipher, err := rclone.NewRcloneCipher(&encryption.Config{})
+	require.NoError(t, err)
+	cfg := config.DefaultConfig()
+	mrf := &MetadataRemoteFile{
+		poolManager:   newFakePoolManager(fp),
+		rcloneCipher:  rcloneCipher,
+		configGetter:  func() *config.Config { return cfg },
+		streamTracker: noopStreamTracker{},
+	}
+	ctx := context.Background()
+
+	candidate.Password, candidate.Salt = "right-pass", "salt"
+	assert.NoError(t, mrf.VerifyCredentials(ctx, "movies/a.mkv", candidate))
+
+	candidate.Password = "wrong-pass"
+	assert.Error(t, mrf.VerifyCredentials(ctx, "movies/a.mkv", candidate))
+}
+
+// newAesCandidate serves plaintext, AES-CBC encrypted with key/iv, as a single
+// fake segment and returns matching metadata without a key.
+func newAesCandidate(t *testing.T, fp *fakepool.Client, key, iv, plaintext []byte) *metapb.FileMetadata {
+	t.Helper()
+	block, err := aes.NewCipher(key)
+	require.NoError(t, err)
+	ciphertext := make([]byte, len(plaintext))
+	cipher.NewCBCEncrypter(block, iv).CryptBlocks(ciphertext, plaintext)
+
+	fp.SetBehavior(segments.MessageID(0), fakepool.SegmentBehavior{Bytes: ciphertext})
+	return &metapb.FileMetadata{
+		FileSize:   int64(len(plaintext)),
+		Encryption: metapb.Encryption_AES,
+		AesIv:      iv,
+		SegmentData: []*metapb.SegmentData{{
+			Id:          segments.MessageID(0),
+			SegmentSize: int64(len(ciphertext)),
+			EndOffset:   int64(len(ciphertext) - 1),
+		}},
+	}
+}
+
+func TestVerifyCredentials_AesSampleHeader(t *testing.T) {
+	fp := fakepool.New()
+	key := bytes.Repeat([]byte{0x11}, 32)
+	iv := bytes.Repeat([]byte{0x22}, 16)
+	plaintext := append([]byte{0x1A, 0x45, 0xDF, 0xA3}, bytes.Repeat([]byte("altmount"), 510)...)
+	plaintext = append(plaintext, make([]byte, 12)...) // whole AES blocks
+	candidate := newAesCandidate(t, fp, key, iv, plaintext)
+
+	cfg := config.DefaultConfig()
+	mrf := &MetadataRemoteFile{
+		poolManager:   newFakePoolManager(fp),
+		aesCipher:     aescipher.NewAesCipher(),
+		configGetter:  func() *config.Config { return cfg },
+		streamTracker: noopStreamTracker{},
+	}
+	ctx := context.Background()
+
+	candidate.AesKey = key
+	assert.NoError(t, mrf.VerifyCredentials(ctx, "movies/a.mkv", candidate))
+
+	// A wrong key decrypts without error, into noise that has no header.
+	candidate.AesKey = bytes.Repeat([]byte{0x33}, 32)
+	assert.Error(t, mrf.VerifyCredentials(ctx, "movies/a.mkv", candidate))
+
+	candidate.AesKey = key
+	assert.ErrorIs(t, mrf.VerifyCredentials(ctx, "movies/a.bin", candidate), ErrAesKeyUnverifiable)
+}
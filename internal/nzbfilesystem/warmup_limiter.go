@@ -0,0 +1,83 @@
+package nzbfilesystem
+
+import (
+	"context"
+	"sync"
+
+	"github.com/javi11/altmount/internal/config"
+)
+
+// WarmupLimiter caps how many WarmUp calls download their initial read-ahead
+// at once across every handle of the filesystem (Streaming.MaxWarmups). What a
+// call does when the cap is reached follows Streaming.WarmupOverflow. Both are
+// read from config on every acquire, so changes apply without a restart.
+type WarmupLimiter struct {
+	configGetter config.ConfigGetter
+
+	mu     sync.Mutex
+	active int
+	// freed is closed, and replaced, whenever a slot is released, waking
+	// queued callers to retry.
+	freed chan struct{}
+}
+
+// NewWarmupLimiter creates a warm-up limiter backed by the given config.
+func NewWarmupLimiter(configGetter config.ConfigGetter) *WarmupLimiter {
+	return &WarmupLimiter{
+		configGetter: configGetter,
+		freed:        make(chan struct{}),
+	}
+}
+
+// Acquire reserves a warm-up slot. It returns a release func that must be
+// called exactly once when the warm-up ends. When MaxWarmups are running it
+// returns ErrTooManyWarmups in drop mode, or waits for a slot in queue mode
+// until ctx is done. A nil limiter or a zero cap never limits.
+func (l *WarmupLimiter) Acquire(ctx context.Context) (func(), error) {
+	noop := func() {}
+	if l == nil {
+		return noop, nil
+	}
+
+	for {
+		cfg := l.configGetter()
+		limit := cfg.Streaming.MaxWarmups
+		if limit <= 0 {
+			return noop, nil
+		}
+
+		l.mu.Lock()
+		if l.active < limit {
+			l.active++
+			l.mu.Unlock()
+			var once sync.Once
+			return func() { once.Do(l.release) }, nil
+		}
+		freed := l.freed
+		l.mu.Unlock()
+
+		if cfg.Streaming.WarmupOverflow != config.WarmupOverflowQueue {
+			return nil, ErrTooManyWarmups
+		}
+		select {
+		case <-freed:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+func (l *WarmupLimiter) release() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.active--
+	close(l.freed)
+	l.freed = make(chan struct{})
+}
+
+// Active returns the number of warm-ups currently running.
+func (l *WarmupLimiter) Active() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.active
+}
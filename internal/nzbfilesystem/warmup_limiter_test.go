@@ -0,0 +1,151 @@
+package nzbfilesystem
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/javi11/altmount/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestWarmupLimiter(max int, overflow config.WarmupOverflow) *WarmupLimiter {
+	cfg := &config.Config{}
+	cfg.Streaming.MaxWarmups = max
+	cfg.Streaming.WarmupOverflow = overflow
+	return NewWarmupLimiter(func() *config.Config { return cfg })
+}
+
+func TestWarmupLimiter_QueuedNeverExceedCap(t *testing.T) {
+	const limit, callers = 3, 40
+	l := newTestWarmupLimiter(limit, config.WarmupOverflowQueue)
+
+	var running, peak atomic.Int32
+	var wg sync.WaitGroup
+	for range callers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			release, err := l.Acquire(context.Background())
+			if !assert.NoError(t, err) {
+				return
+			}
+			defer release()
+			n := running.Add(1)
+			for {
+				p := peak.Load()
+				if n <= p || peak.CompareAndSwap(p, n) {
+					break
+				}
+			}
+			time.Sleep(2 * time.Millisecond)
+			running.Add(-1)
+		}()
+	}
+	wg.Wait()
+
+	assert.LessOrEqual(t, peak.Load(), int32(limit))
+	assert.Equal(t, int32(limit), peak.Load(), "the cap is used, not just respected")
+	assert.Zero(t, l.Active())
+}
+
+func TestWarmupLimiter_DropsOverflow(t *testing.T) {
+	l := newTestWarmupLimiter(1, "")
+
+	release, err := l.Acquire(context.Background())
+	require.NoError(t, err)
+	_, err = l.Acquire(context.Background())
+	assert.ErrorIs(t, err, ErrTooManyWarmups)
+
+	release()
+	release() // extra calls are harmless
+	again, err := l.Acquire(context.Background())
+	require.NoError(t, err)
+	again()
+	assert.Zero(t, l.Active())
+}
+
+func TestWarmupLimiter_QueueHonorsContext(t *testing.T) {
+	l := newTestWarmupLimiter(1, config.WarmupOverflowQueue)
+	release, err := l.Acquire(context.Background())
+	require.NoError(t, err)
+	defer release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err = l.Acquire(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, 1, l.Active())
+}
+
+func TestWarmupLimiter_ZeroIsUnlimited(t *testing.T) {
+	l := newTestWarmupLimiter(0, "")
+	for range 100 {
+		_, err := l.Acquire(context.Background())
+		require.NoError(t, err)
+	}
+	assert.Zero(t, l.Active())
+
+	var nilLimiter *WarmupLimiter
+	release, err := nilLimiter.Acquire(context.Background())
+	require.NoError(t, err)
+	release()
+}
+
+func TestMetadataVirtualFile_WarmUpLimited(t *testing.T) {
+	const (
+		n       = 4
+		segSize = 1024
+	)
+	newWarmupMVF := func(t *testing.T, l *WarmupLimiter) *MetadataVirtualFile {
+		mvf := newTestMVF(t, context.Background(), staggeredPool(n, segSize, time.Millisecond), n, segSize, n)
+		mvf.initialReadAhead = 2 * segSize
+		mvf.warmupLimiter = l
+		return mvf
+	}
+
+	t.Run("drop", func(t *testing.T) {
+		l := newTestWarmupLimiter(1, config.WarmupOverflowDrop)
+		hold, err := l.Acquire(context.Background())
+		require.NoError(t, err)
+		defer hold()
+
+		mvf := newWarmupMVF(t, l)
+		assert.ErrorIs(t, mvf.WarmUp(), ErrTooManyWarmups)
+		assert.False(t, mvf.readAheadPrimed, "a dropped warm-up leaves priming to the first read")
+
+		data, err := io.ReadAll(mvf)
+		require.NoError(t, err)
+		assert.True(t, bytes.Equal(wantFileBytes(n, segSize), data))
+	})
+
+	t.Run("queue", func(t *testing.T) {
+		l := newTestWarmupLimiter(1, config.WarmupOverflowQueue)
+		hold, err := l.Acquire(context.Background())
+		require.NoError(t, err)
+
+		mvf := newWarmupMVF(t, l)
+		done := make(chan error, 1)
+		go func() { done <- mvf.WarmUp() }()
+
+		select {
+		case err := <-done:
+			t.Fatalf("WarmUp finished while the only slot was held: %v", err)
+		case <-time.After(50 * time.Millisecond):
+		}
+		hold()
+		select {
+		case err := <-done:
+			require.NoError(t, err)
+		case <-time.After(5 * time.Second):
+			t.Fatal("WarmUp did not resume after a slot was freed")
+		}
+		assert.True(t, mvf.readAheadPrimed)
+		assert.Zero(t, l.Active())
+	})
+}
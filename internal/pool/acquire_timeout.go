@@ -0,0 +1,137 @@
+package pool
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/javi11/nntppool/v4"
+)
+
+// ErrPoolExhausted is returned when a body fetch is not picked up by any
+// connection within the configured acquire timeout because every provider's
+// connection slots are in use. It says nothing about the article itself, so
+// callers must not treat it as corruption; the serving layer maps it to 503.
+var ErrPoolExhausted = errors.New("no NNTP connection available: pool exhausted")
+
+// StreamPoolGetter is implemented by managers that bound how long streaming
+// reads wait for a connection (Pool.AcquireTimeoutSeconds). Streaming reads
+// go through GetStreamPool; imports, health checks and other background work
+// use GetPool and keep queueing behind them. Test fakes need not implement it.
+type StreamPoolGetter interface {
+	GetStreamPool() (NntpClient, error)
+}
+
+// acquireTimeoutClient bounds how long a body fetch may wait for a connection.
+// nntppool queues requests internally without exposing acquisition, so a fetch
+// counts as started once its yEnc header arrives (the onMeta callback). If
+// that hasn't happened when the timeout fires and the pool reports no free
+// slots, the fetch is cancelled with ErrPoolExhausted. A fetch that is merely
+// slow on a pool with free slots keeps waiting. Stat calls pass through.
+type acquireTimeoutClient struct {
+	NntpClient
+	timeout time.Duration
+}
+
+// WithAcquireTimeout wraps client so body fetches fail fast with
+// ErrPoolExhausted instead of queueing indefinitely on a saturated pool.
+// A timeout <= 0 returns client unchanged.
+func WithAcquireTimeout(client NntpClient, timeout time.Duration) NntpClient {
+	if timeout <= 0 || client == nil {
+		return client
+	}
+	return &acquireTimeoutClient{NntpClient: client, timeout: timeout}
+}
+
+func (c *acquireTimeoutClient) Body(ctx context.Context, messageID string, onMeta ...func(nntppool.YEncMeta)) (*nntppool.ArticleBody, error) {
+	return c.await(ctx, onMeta, func(ctx context.Context, meta func(nntppool.YEncMeta)) (*nntppool.ArticleBody, error) {
+		return c.NntpClient.Body(ctx, messageID, meta)
+	})
+}
+
+func (c *acquireTimeoutClient) BodyPriority(ctx context.Context, messageID string, onMeta ...func(nntppool.YEncMeta)) (*nntppool.ArticleBody, error) {
+	return c.await(ctx, onMeta, func(ctx context.Context, meta func(nntppool.YEncMeta)) (*nntppool.ArticleBody, error) {
+		return c.NntpClient.BodyPriority(ctx, messageID, meta)
+	})
+}
+
+func (c *acquireTimeoutClient) BodyAsync(ctx context.Context, messageID string, w io.Writer, onMeta ...func(nntppool.YEncMeta)) <-chan nntppool.BodyResult {
+	ch := make(chan nntppool.BodyResult, 1)
+	go func() {
+		defer close(ch)
+		body, err := c.await(ctx, onMeta, func(ctx context.Context, meta func(nntppool.YEncMeta)) (*nntppool.ArticleBody, error) {
+			res := <-c.NntpClient.BodyAsync(ctx, messageID, w, meta)
+			return res.Body, res.Err
+		})
+		ch <- nntppool.BodyResult{Body: body, Err: err}
+	}()
+	return ch
+}
+
+// await runs fetch and enforces the acquire timeout on it. After cancelling
+// it still waits for fetch to return, so the caller's writer and callbacks
+// are never touched once await has returned.
+func (c *acquireTimeoutClient) await(
+	ctx context.Context,
+	onMeta []func(nntppool.YEncMeta),
+	fetch func(ctx context.Context, meta func(nntppool.YEncMeta)) (*nntppool.ArticleBody, error),
+) (*nntppool.ArticleBody, error) {
+	callCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	started := make(chan struct{})
+	var once sync.Once
+	meta := func(m nntppool.YEncMeta) {
+		once.Do(func() { close(started) })
+		for _, fn := range onMeta {
+			fn(m)
+		}
+	}
+
+	type result struct {
+		body *nntppool.ArticleBody
+		err  error
+	}
+	done := make(chan result, 1)
+	go func() {
+		body, err := fetch(callCtx, meta)
+		done <- result{body, err}
+	}()
+
+	timer := time.NewTimer(c.timeout)
+	defer timer.Stop()
+
+	select {
+	case r := <-done:
+		return r.body, r.err
+	case <-started:
+	case <-timer.C:
+		if c.saturated() {
+			cancel()
+			if r := <-done; r.err == nil {
+				return r.body, nil // completed just as the timeout fired
+			}
+			return nil, ErrPoolExhausted
+		}
+	}
+	r := <-done
+	return r.body, r.err
+}
+
+// saturated reports whether every provider that can still serve requests has
+// no free connection slot.
+func (c *acquireTimeoutClient) saturated() bool {
+	usable := 0
+	for _, p := range c.NntpClient.Stats().Providers {
+		if p.QuotaExceeded {
+			continue
+		}
+		if p.AvailableSlots > 0 {
+			return false
+		}
+		usable++
+	}
+	return usable > 0
+}
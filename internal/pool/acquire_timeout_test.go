@@ -0,0 +1,76 @@
+package pool_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/javi11/altmount/internal/pool"
+	"github.com/javi11/altmount/internal/testsupport/fakepool"
+	"github.com/javi11/nntppool/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// saturatedPool returns a fake whose fetches hang for latency and whose single
+// provider reports availableSlots free connection slots.
+func saturatedPool(latency time.Duration, availableSlots int) *fakepool.Client {
+	fp := fakepool.New()
+	fp.SetDefaultBehavior(fakepool.SegmentBehavior{Latency: latency, Bytes: []byte("payload")})
+	fp.SetStats(nntppool.ClientStats{Providers: []nntppool.ProviderStats{
+		{Name: "news.example:563", MaxConnections: 2, AvailableSlots: availableSlots},
+	}})
+	return fp
+}
+
+func TestAcquireTimeout_SaturatedPoolFailsFast(t *testing.T) {
+	fp := saturatedPool(time.Minute, 0)
+	client := pool.WithAcquireTimeout(fp, 50*time.Millisecond)
+
+	start := time.Now()
+	_, err := client.BodyPriority(context.Background(), "<a@x>")
+	assert.ErrorIs(t, err, pool.ErrPoolExhausted)
+	assert.Less(t, time.Since(start), 5*time.Second, "returned promptly instead of hanging")
+	assert.Zero(t, fp.InFlight(), "the queued fetch was cancelled")
+
+	_, err = client.Body(context.Background(), "<b@x>")
+	assert.ErrorIs(t, err, pool.ErrPoolExhausted)
+}
+
+func TestAcquireTimeout_BodyAsync(t *testing.T) {
+	fp := saturatedPool(time.Minute, 0)
+	client := pool.WithAcquireTimeout(fp, 50*time.Millisecond)
+
+	select {
+	case res := <-client.BodyAsync(context.Background(), "<a@x>", &bytes.Buffer{}):
+		assert.ErrorIs(t, res.Err, pool.ErrPoolExhausted)
+	case <-time.After(5 * time.Second):
+		t.Fatal("BodyAsync did not fail fast on a saturated pool")
+	}
+}
+
+func TestAcquireTimeout_FreeSlotsKeepWaiting(t *testing.T) {
+	// The fetch outlasts the timeout, but the pool has free slots, so the
+	// slowness is the provider's and not exhaustion.
+	fp := saturatedPool(200*time.Millisecond, 1)
+	client := pool.WithAcquireTimeout(fp, 20*time.Millisecond)
+
+	body, err := client.BodyPriority(context.Background(), "<a@x>")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("payload"), body.Bytes)
+}
+
+func TestAcquireTimeout_FastFetchUnaffected(t *testing.T) {
+	fp := saturatedPool(0, 0)
+	client := pool.WithAcquireTimeout(fp, time.Second)
+
+	body, err := client.Body(context.Background(), "<a@x>")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("payload"), body.Bytes)
+}
+
+func TestAcquireTimeout_DisabledReturnsClient(t *testing.T) {
+	fp := fakepool.New()
+	assert.Same(t, fp, pool.WithAcquireTimeout(fp, 0))
+}
@@ -0,0 +1,322 @@
+package pool
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/javi11/altmount/internal/config"
+	"github.com/javi11/nntppool/v4"
+)
+
+// breakerCheckInterval is how often the circuit breaker samples provider stats.
+const breakerCheckInterval = time.Second
+
+type breakerPhase int
+
+const (
+	breakerClosed   breakerPhase = iota // in the pool, counting failures
+	breakerOpen                         // out of the pool until the cooldown ends
+	breakerHalfOpen                     // back in the pool on probation
+)
+
+func (p breakerPhase) String() string {
+	switch p {
+	case breakerOpen:
+		return "open"
+	case breakerHalfOpen:
+		return "half-open"
+	default:
+		return "closed"
+	}
+}
+
+// breakerTarget is the part of *nntppool.Client the circuit breaker drives.
+type breakerTarget interface {
+	Stats() nntppool.ClientStats
+	RemoveProvider(name string) error
+	AddProvider(p nntppool.Provider) error
+}
+
+// providerBreaker tracks one provider for circuitBreaker.
+type providerBreaker struct {
+	phase breakerPhase
+
+	// Counter values at the previous check; baseline is false until the
+	// provider's first check in the pool.
+	baseline   bool
+	lastErrors int64
+	lastBytes  int64
+
+	failures     int64
+	firstFailure time.Time
+
+	// Set while open: when the breaker opened and the provider to re-add.
+	openedAt time.Time
+	provider nntppool.Provider
+}
+
+// circuitBreaker takes providers that keep failing out of the pool for a
+// cooldown so reads stop paying their failure latency (Pool.CircuitBreaker).
+// nntppool does not report individual fetches, so the breaker works from the
+// per-provider counters in the pool's stats: errors with no bytes received
+// since the previous check count as consecutive failures, and any bytes
+// received reset the count. Article-not-found answers are not failures.
+type circuitBreaker struct {
+	mu        sync.Mutex
+	settings  config.CircuitBreakerConfig
+	providers map[string]*providerBreaker // pool name -> state
+	logger    *slog.Logger
+}
+
+func newCircuitBreaker(settings config.CircuitBreakerConfig, logger *slog.Logger) *circuitBreaker {
+	return &circuitBreaker{
+		settings:  settings,
+		providers: make(map[string]*providerBreaker),
+		logger:    logger,
+	}
+}
+
+// setSettings replaces the thresholds; tracked state is kept.
+func (b *circuitBreaker) setSettings(settings config.CircuitBreakerConfig) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.settings = settings
+}
+
+// isOpen reports whether name is currently held out of the pool.
+func (b *circuitBreaker) isOpen(name string) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	st, ok := b.providers[name]
+	return ok && st.phase == breakerOpen
+}
+
+// anyOpen reports whether any provider is currently held out of the pool.
+func (b *circuitBreaker) anyOpen() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, st := range b.providers {
+		if st.phase == breakerOpen {
+			return true
+		}
+	}
+	return false
+}
+
+// check samples target's stats once at now: it counts failures, removes
+// providers whose breaker opens and re-adds those whose cooldown is over.
+// lookup returns the configuration of a provider in the pool.
+func (b *circuitBreaker) check(ctx context.Context, now time.Time, target breakerTarget, lookup func(name string) (nntppool.Provider, bool)) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	stats := target.Stats().Providers
+	inPool := len(stats)
+	for _, ps := range stats {
+		st := b.providers[ps.Name]
+		if st == nil {
+			st = &providerBreaker{}
+			b.providers[ps.Name] = st
+		}
+		if st.phase == breakerOpen {
+			// Re-added from outside, e.g. by a config change.
+			*st = providerBreaker{}
+		}
+
+		errs, received := ps.Errors-st.lastErrors, ps.BytesConsumed-st.lastBytes
+		st.lastErrors, st.lastBytes = ps.Errors, ps.BytesConsumed
+		if !st.baseline || errs < 0 || received < 0 {
+			// First sample, or the counters restarted: nothing to compare.
+			st.baseline = true
+			continue
+		}
+
+		switch {
+		case received > 0:
+			if st.phase == breakerHalfOpen {
+				b.logger.InfoContext(ctx, "Provider circuit breaker closed", "provider", ps.Name)
+			}
+			st.phase = breakerClosed
+			st.failures = 0
+		case errs > 0:
+			if st.phase == breakerHalfOpen {
+				if b.open(ctx, now, target, lookup, ps, inPool) {
+					inPool--
+				}
+				continue
+			}
+			if st.failures == 0 || now.Sub(st.firstFailure) > b.settings.Window() {
+				st.failures = 0
+				st.firstFailure = now
+			}
+			st.failures += errs
+			if st.failures >= int64(b.settings.FailureThreshold) && b.open(ctx, now, target, lookup, ps, inPool) {
+				inPool--
+			}
+		}
+	}
+
+	for name, st := range b.providers {
+		if st.phase != breakerOpen || now.Sub(st.openedAt) < b.settings.Cooldown() {
+			continue
+		}
+		if err := target.AddProvider(st.provider); err != nil {
+			b.logger.WarnContext(ctx, "Failed to re-add provider after circuit breaker cooldown",
+				"provider", name, "error", err)
+			continue
+		}
+		b.logger.InfoContext(ctx, "Provider circuit breaker half-open, probing provider", "provider", name)
+		*st = providerBreaker{phase: breakerHalfOpen}
+	}
+}
+
+// open removes the provider described by ps from target, unless it is the
+// last of the inPool providers left, and reports whether it did. Must be
+// called with b.mu held.
+func (b *circuitBreaker) open(ctx context.Context, now time.Time, target breakerTarget, lookup func(string) (nntppool.Provider, bool), ps nntppool.ProviderStats, inPool int) bool {
+	st := b.providers[ps.Name]
+	if inPool <= 1 {
+		// Nowhere else to route reads; keep counting instead.
+		return false
+	}
+	p, ok := lookup(ps.Name)
+	if !ok {
+		return false
+	}
+	// Carry the quota over so re-adding the provider does not reset it.
+	p.QuotaUsed = ps.QuotaUsed
+	p.QuotaResetAt = ps.QuotaResetAt
+
+	if err := target.RemoveProvider(ps.Name); err != nil {
+		b.logger.WarnContext(ctx, "Failed to remove provider for circuit breaker",
+			"provider", ps.Name, "error", err)
+		return false
+	}
+	b.logger.WarnContext(ctx, "Provider circuit breaker opened, provider removed from selection",
+		"provider", ps.Name,
+		"from", st.phase,
+		"failures", st.failures,
+		"cooldown", b.settings.Cooldown())
+	*st = providerBreaker{phase: breakerOpen, openedAt: now, provider: p}
+	return true
+}
+
+// forget drops the state for name, reporting whether it was held out of the
+// pool.
+func (b *circuitBreaker) forget(name string) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	st, ok := b.providers[name]
+	delete(b.providers, name)
+	return ok && st.phase == breakerOpen
+}
+
+// releaseAll re-adds every provider held out of the pool to target, ending
+// their cooldown early, and forgets all state.
+func (b *circuitBreaker) releaseAll(ctx context.Context, target breakerTarget) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for name, st := range b.providers {
+		if st.phase == breakerOpen && target != nil {
+			if err := target.AddProvider(st.provider); err != nil {
+				b.logger.WarnContext(ctx, "Failed to re-add provider held by circuit breaker",
+					"provider", name, "error", err)
+			}
+		}
+	}
+	b.providers = make(map[string]*providerBreaker)
+}
+
+// reset forgets all state without touching any pool.
+func (b *circuitBreaker) reset() {
+	b.releaseAll(context.Background(), nil)
+}
+
+// CircuitBreakerStatus is implemented by managers that support
+// Pool.CircuitBreaker; test fakes need not. A held-out provider is gone from
+// the shared pool, so health checks run meanwhile could report articles
+// missing that only it has; the health worker waits instead.
+type CircuitBreakerStatus interface {
+	// ProvidersHeldOut reports whether the circuit breaker currently holds
+	// any provider out of the pool.
+	ProvidersHeldOut() bool
+}
+
+// ProvidersHeldOut implements CircuitBreakerStatus.
+func (m *manager) ProvidersHeldOut() bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.breaker != nil && m.breaker.anyOpen()
+}
+
+// circuitBreakerSetter is implemented by managers that support
+// Pool.CircuitBreaker; test fakes need not.
+type circuitBreakerSetter interface {
+	SetCircuitBreaker(settings config.CircuitBreakerConfig)
+}
+
+// SetCircuitBreaker applies Pool.CircuitBreaker. Enabling it starts a
+// background check of provider stats; disabling it stops the check and puts
+// back any provider it held out of the pool.
+func (m *manager) SetCircuitBreaker(settings config.CircuitBreakerConfig) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !settings.Enabled() {
+		if m.breaker != nil {
+			m.breakerCancel()
+			m.breaker.releaseAll(m.ctx, m.breakerTargetLocked())
+			m.breaker, m.breakerCancel = nil, nil
+		}
+		return
+	}
+	if m.breaker != nil {
+		m.breaker.setSettings(settings)
+		return
+	}
+
+	m.breaker = newCircuitBreaker(settings, m.logger)
+	ctx, cancel := context.WithCancel(m.ctx)
+	m.breakerCancel = cancel
+	go m.breakerLoop(ctx, m.breaker)
+}
+
+// breakerTargetLocked returns the pool as a breakerTarget, or nil when there
+// is none. Must be called with m.mu held.
+func (m *manager) breakerTargetLocked() breakerTarget {
+	if m.pool == nil {
+		return nil
+	}
+	return m.pool
+}
+
+// breakerLoop runs b's checks against the current pool until ctx is done.
+func (m *manager) breakerLoop(ctx context.Context, b *circuitBreaker) {
+	ticker := time.NewTicker(breakerCheckInterval)
+	defer ticker.Stop()
+
+	lookup := func(name string) (nntppool.Provider, bool) {
+		p, ok := m.providers[name]
+		return p, ok
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			m.mu.Lock()
+			if m.pool != nil {
+				b.check(ctx, now, m.pool, lookup)
+			}
+			m.mu.Unlock()
+		}
+	}
+}
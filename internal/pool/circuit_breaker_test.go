@@ -0,0 +1,211 @@
+package pool
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/javi11/altmount/internal/config"
+	"github.com/javi11/nntppool/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeBreakerPool stands in for *nntppool.Client: a provider list whose
+// counters the test moves by hand.
+type fakeBreakerPool struct {
+	stats []nntppool.ProviderStats
+	added []nntppool.Provider
+}
+
+func newFakeBreakerPool(names ...string) *fakeBreakerPool {
+	f := &fakeBreakerPool{}
+	for _, n := range names {
+		f.stats = append(f.stats, nntppool.ProviderStats{Name: n})
+	}
+	return f
+}
+
+func (f *fakeBreakerPool) Stats() nntppool.ClientStats {
+	return nntppool.ClientStats{Providers: slices.Clone(f.stats)}
+}
+
+func (f *fakeBreakerPool) RemoveProvider(name string) error {
+	f.stats = slices.DeleteFunc(f.stats, func(ps nntppool.ProviderStats) bool { return ps.Name == name })
+	return nil
+}
+
+func (f *fakeBreakerPool) AddProvider(p nntppool.Provider) error {
+	f.added = append(f.added, p)
+	f.stats = append(f.stats, nntppool.ProviderStats{Name: providerPoolName(p)})
+	return nil
+}
+
+func (f *fakeBreakerPool) names() []string {
+	var out []string
+	for _, ps := range f.stats {
+		out = append(out, ps.Name)
+	}
+	return out
+}
+
+// fail and succeed record activity on name since the previous check.
+func (f *fakeBreakerPool) fail(name string, n int64) {
+	for i := range f.stats {
+		if f.stats[i].Name == name {
+			f.stats[i].Errors += n
+		}
+	}
+}
+
+func (f *fakeBreakerPool) succeed(name string) {
+	for i := range f.stats {
+		if f.stats[i].Name == name {
+			f.stats[i].BytesConsumed += 1000
+		}
+	}
+}
+
+func breakerLookup(name string) (nntppool.Provider, bool) {
+	return nntppool.Provider{Host: name, Connections: 10}, true
+}
+
+func newTestBreaker(threshold int) *circuitBreaker {
+	return newCircuitBreaker(config.CircuitBreakerConfig{
+		FailureThreshold: threshold,
+		WindowSeconds:    10,
+		CooldownSeconds:  30,
+	}, slog.Default())
+}
+
+func TestCircuitBreaker_FlappingProviderRemovedAndRestored(t *testing.T) {
+	ctx := context.Background()
+	b := newTestBreaker(3)
+	p := newFakeBreakerPool("flaky", "steady")
+	now := time.Unix(1_700_000_000, 0)
+	tick := func(d time.Duration) {
+		now = now.Add(d)
+		b.check(ctx, now, p, breakerLookup)
+	}
+
+	tick(0) // baseline
+	p.fail("flaky", 2)
+	tick(time.Second)
+	assert.Equal(t, []string{"flaky", "steady"}, p.names(), "below the threshold")
+
+	p.fail("flaky", 1)
+	p.succeed("steady")
+	tick(time.Second)
+	assert.Equal(t, []string{"steady"}, p.names(), "the flapping provider is out of selection")
+	assert.True(t, b.isOpen("flaky"))
+	assert.True(t, b.anyOpen())
+
+	tick(29 * time.Second)
+	assert.Equal(t, []string{"steady"}, p.names(), "still cooling down")
+
+	tick(time.Second)
+	assert.Equal(t, []string{"steady", "flaky"}, p.names(), "re-added for a probe after the cooldown")
+	assert.False(t, b.isOpen("flaky"))
+	assert.False(t, b.anyOpen())
+
+	// The probe succeeds: the breaker closes and failures count from zero.
+	tick(time.Second) // baseline for the re-added provider
+	p.succeed("flaky")
+	tick(time.Second)
+	p.fail("flaky", 2)
+	tick(time.Second)
+	assert.Contains(t, p.names(), "flaky")
+}
+
+func TestCircuitBreaker_FailedProbeReopens(t *testing.T) {
+	ctx := context.Background()
+	b := newTestBreaker(1)
+	p := newFakeBreakerPool("flaky", "steady")
+	now := time.Unix(1_700_000_000, 0)
+
+	b.check(ctx, now, p, breakerLookup)
+	p.fail("flaky", 1)
+	now = now.Add(time.Second)
+	b.check(ctx, now, p, breakerLookup)
+	require.True(t, b.isOpen("flaky"))
+
+	now = now.Add(30 * time.Second)
+	b.check(ctx, now, p, breakerLookup) // half-open
+	now = now.Add(time.Second)
+	b.check(ctx, now, p, breakerLookup) // baseline
+	p.fail("flaky", 1)
+	now = now.Add(time.Second)
+	b.check(ctx, now, p, breakerLookup)
+	assert.True(t, b.isOpen("flaky"), "a failed probe reopens the breaker")
+	assert.Equal(t, []string{"steady"}, p.names())
+}
+
+func TestCircuitBreaker_FailuresOutsideWindowDoNotTrip(t *testing.T) {
+	ctx := context.Background()
+	b := newTestBreaker(3)
+	p := newFakeBreakerPool("slow", "steady")
+	now := time.Unix(1_700_000_000, 0)
+
+	b.check(ctx, now, p, breakerLookup)
+	for range 5 {
+		p.fail("slow", 1)
+		now = now.Add(6 * time.Second)
+		b.check(ctx, now, p, breakerLookup)
+		p.fail("slow", 1)
+		now = now.Add(6 * time.Second)
+		b.check(ctx, now, p, breakerLookup)
+	}
+	assert.Contains(t, p.names(), "slow", "two failures per 10s window never reach 3")
+
+	// A success in between also breaks the run.
+	p.succeed("slow")
+	now = now.Add(time.Second)
+	b.check(ctx, now, p, breakerLookup)
+	p.fail("slow", 2)
+	now = now.Add(time.Second)
+	b.check(ctx, now, p, breakerLookup)
+	p.succeed("slow")
+	now = now.Add(time.Second)
+	b.check(ctx, now, p, breakerLookup)
+	p.fail("slow", 2)
+	now = now.Add(time.Second)
+	b.check(ctx, now, p, breakerLookup)
+	assert.Contains(t, p.names(), "slow")
+}
+
+func TestCircuitBreaker_KeepsLastProvider(t *testing.T) {
+	ctx := context.Background()
+	b := newTestBreaker(1)
+	p := newFakeBreakerPool("a", "b")
+	now := time.Unix(1_700_000_000, 0)
+
+	b.check(ctx, now, p, breakerLookup)
+	p.fail("a", 1)
+	p.fail("b", 1)
+	now = now.Add(time.Second)
+	b.check(ctx, now, p, breakerLookup)
+	assert.Len(t, p.names(), 1, "one provider always stays in the pool")
+}
+
+func TestCircuitBreaker_CarriesQuotaAndReleases(t *testing.T) {
+	ctx := context.Background()
+	b := newTestBreaker(1)
+	p := newFakeBreakerPool("capped", "steady")
+	resetAt := time.Unix(1_800_000_000, 0)
+	p.stats[0].QuotaUsed = 5000
+	p.stats[0].QuotaResetAt = resetAt
+	now := time.Unix(1_700_000_000, 0)
+
+	b.check(ctx, now, p, breakerLookup)
+	p.fail("capped", 1)
+	b.check(ctx, now.Add(time.Second), p, breakerLookup)
+	require.True(t, b.isOpen("capped"))
+
+	b.releaseAll(ctx, p)
+	require.Len(t, p.added, 1)
+	assert.Equal(t, int64(5000), p.added[0].QuotaUsed)
+	assert.Equal(t, resetAt, p.added[0].QuotaResetAt)
+	assert.False(t, b.isOpen("capped"))
+}
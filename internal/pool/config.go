@@ -3,6 +3,8 @@ package pool
 import (
 	"context"
 	"log/slog"
+	"strings"
+	"time"
 
 	"github.com/javi11/altmount/internal/config"
 )
@@ -11,13 +13,21 @@ import (
 func RegisterConfigHandlers(ctx context.Context, configManager *config.Manager, poolManager Manager) {
 	// Initial ID mapping
 	updateProviderIDMap(configManager.GetConfig(), poolManager)
+	updateProviderTags(configManager.GetConfig(), poolManager)
 	// Initial import connection budget: the pool's total connection capacity.
 	poolManager.SetImportConnCapacity(configManager.GetConfig().TotalProviderConnections())
+	if s, ok := poolManager.(acquireTimeoutSetter); ok {
+		s.SetAcquireTimeout(configManager.GetConfig().Pool.AcquireTimeout())
+	}
+	if s, ok := poolManager.(circuitBreakerSetter); ok {
+		s.SetCircuitBreaker(configManager.GetConfig().Pool.CircuitBreaker)
+	}
 
 	configManager.OnConfigChange(func(oldConfig, newConfig *config.Config) {
 		slog.InfoContext(ctx, "Configuration updated")
 
 		updateProviderIDMap(newConfig, poolManager)
+		updateProviderTags(newConfig, poolManager)
 		handleProviderChanges(ctx, oldConfig, newConfig, poolManager)
 
 		// Keep the import connection budget in sync with provider capacity.
@@ -26,6 +36,17 @@ func RegisterConfigHandlers(ctx context.Context, configManager *config.Manager,
 			poolManager.SetImportConnCapacity(capacity)
 		}
 
+		if s, ok := poolManager.(acquireTimeoutSetter); ok && newConfig.Pool.AcquireTimeout() != oldConfig.Pool.AcquireTimeout() {
+			slog.InfoContext(ctx, "Connection acquire timeout updated", "timeout", newConfig.Pool.AcquireTimeout())
+			s.SetAcquireTimeout(newConfig.Pool.AcquireTimeout())
+		}
+
+		if s, ok := poolManager.(circuitBreakerSetter); ok && newConfig.Pool.CircuitBreaker != oldConfig.Pool.CircuitBreaker {
+			slog.InfoContext(ctx, "Provider circuit breaker updated",
+				"failure_threshold", newConfig.Pool.CircuitBreaker.FailureThreshold)
+			s.SetCircuitBreaker(newConfig.Pool.CircuitBreaker)
+		}
+
 		// Log changes that still require restart
 		if oldConfig.Metadata.RootPath != newConfig.Metadata.RootPath {
 			slog.InfoContext(ctx, "Metadata root path changed (restart required)",
@@ -35,6 +56,12 @@ func RegisterConfigHandlers(ctx context.Context, configManager *config.Manager,
 	})
 }
 
+// acquireTimeoutSetter is implemented by managers that support
+// Pool.AcquireTimeoutSeconds; test fakes need not.
+type acquireTimeoutSetter interface {
+	SetAcquireTimeout(timeout time.Duration)
+}
+
 // updateProviderIDMap provides a mapping of pool names to config IDs to the pool manager
 func updateProviderIDMap(cfg *config.Config, poolManager Manager) {
 	idMap := make(map[string]string)
@@ -44,6 +71,24 @@ func updateProviderIDMap(cfg *config.Config, poolManager Manager) {
 	poolManager.SetProviderIDs(idMap)
 }
 
+// updateProviderTags gives managers that support preferred-provider reads the
+// config IDs and names a file's metadata may use to refer to a provider.
+func updateProviderTags(cfg *config.Config, poolManager Manager) {
+	s, ok := poolManager.(providerTagSetter)
+	if !ok {
+		return
+	}
+	tags := make(map[string]string)
+	for _, p := range cfg.Providers {
+		name := p.NNTPPoolName()
+		if p.Name != "" {
+			tags[strings.ToLower(p.Name)] = name
+		}
+		tags[strings.ToLower(p.ID)] = name
+	}
+	s.SetProviderTags(tags)
+}
+
 // handleProviderChanges applies incremental provider changes to the pool.
 // It uses AddProvider/RemoveProvider for individual changes and falls back
 // to full SetProviders only when provider order changes (nntppool v4 has no reorder API).
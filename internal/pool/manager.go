@@ -6,6 +6,7 @@ import (
 	"log/slog"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/javi11/nntppool/v4"
@@ -115,12 +116,18 @@ type manager struct {
 	pool             *nntppool.Client
 	metricsTracker   *MetricsTracker
 	providerIDMap    map[string]string
+	providers        map[string]nntppool.Provider // pool name -> provider, as currently in pool
+	providerTags     map[string]string            // lower-cased config ID/name -> pool name
+	preferred        map[string]*nntppool.Client  // pool name -> dedicated client for preferred-provider reads
 	repo             StatsRepository
 	ctx              context.Context
 	logger           *slog.Logger
 	quotaWatchCancel context.CancelFunc
 	admission        *ImportAdmission
 	budget           *ImportBudget
+	acquireTimeout   atomic.Int64    // time.Duration; 0 = wait for a connection indefinitely
+	breaker          *circuitBreaker // nil while Pool.CircuitBreaker is disabled
+	breakerCancel    context.CancelFunc
 }
 
 // NewManager creates a new pool manager
@@ -200,7 +207,7 @@ func (m *manager) injectQuotaState(providers []nntppool.Provider) {
 }
 
 // GetPool returns the current connection pool or error if not available.
-// The concrete return type is *nntppool.Client which satisfies NntpClient.
+// The concrete client is *nntppool.Client.
 func (m *manager) GetPool() (NntpClient, error) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
@@ -212,12 +219,34 @@ func (m *manager) GetPool() (NntpClient, error) {
 	return m.pool, nil
 }
 
+// GetStreamPool returns GetPool's client wrapped by WithAcquireTimeout when
+// an acquire timeout is set.
+func (m *manager) GetStreamPool() (NntpClient, error) {
+	client, err := m.GetPool()
+	if err != nil {
+		return nil, err
+	}
+	return WithAcquireTimeout(client, time.Duration(m.acquireTimeout.Load())), nil
+}
+
+// SetAcquireTimeout bounds how long body fetches on clients returned by
+// GetStreamPool and GetPreferredPool wait for a connection before failing
+// with ErrPoolExhausted. 0 disables the bound.
+func (m *manager) SetAcquireTimeout(timeout time.Duration) {
+	m.acquireTimeout.Store(int64(max(timeout, 0)))
+}
+
 // SetProviders creates/recreates the pool with new providers
 func (m *manager) SetProviders(providers []nntppool.Provider) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
 	// Shut down existing pool and metrics tracker if present
+	m.closePreferredLocked("")
+	m.providers = nil
+	if m.breaker != nil {
+		m.breaker.reset()
+	}
 	if m.pool != nil {
 		m.logger.InfoContext(m.ctx, "Shutting down existing NNTP connection pool")
 		if m.metricsTracker != nil {
@@ -245,6 +274,10 @@ func (m *manager) SetProviders(providers []nntppool.Provider) error {
 	}
 
 	m.pool = pool
+	m.providers = make(map[string]nntppool.Provider, len(providers))
+	for _, p := range providers {
+		m.providers[providerPoolName(p)] = p
+	}
 
 	// Start metrics tracker
 	m.metricsTracker = NewMetricsTracker(pool, m.repo)
@@ -264,6 +297,11 @@ func (m *manager) ClearPool() error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
+	m.closePreferredLocked("")
+	m.providers = nil
+	if m.breaker != nil {
+		m.breaker.reset()
+	}
 	if m.pool != nil {
 		m.logger.InfoContext(m.ctx, "Clearing NNTP connection pool")
 		m.stopQuotaWatcher()
@@ -415,6 +453,13 @@ func (m *manager) AddProvider(provider nntppool.Provider) error {
 		}
 	}
 
+	name := providerPoolName(provider)
+	m.closePreferredLocked(name)
+	if m.providers == nil {
+		m.providers = make(map[string]nntppool.Provider)
+	}
+	m.providers[name] = provider
+
 	m.startQuotaWatcher()
 	return nil
 }
@@ -430,9 +475,23 @@ func (m *manager) RemoveProvider(name string) error {
 	}
 
 	m.logger.InfoContext(m.ctx, "Removing provider from NNTP connection pool", "provider", name)
+	if m.breaker != nil && m.breaker.forget(name) {
+		// Held out by the circuit breaker, so already gone from the pool.
+		m.closePreferredLocked(name)
+		delete(m.providers, name)
+		return nil
+	}
 	if err := m.pool.RemoveProvider(name); err != nil {
 		return err
 	}
+	m.closePreferredLocked(name)
+	delete(m.providers, name)
+
+	// Providers held out by the circuit breaker are all that is left: put
+	// them back rather than closing the pool under them.
+	if m.pool.NumProviders() == 0 && m.breaker != nil {
+		m.breaker.releaseAll(m.ctx, m.pool)
+	}
 
 	// If no providers remain, tear down the pool entirely
 	if m.pool.NumProviders() == 0 {
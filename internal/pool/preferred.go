@@ -0,0 +1,186 @@
+package pool
+
+import (
+	"context"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/javi11/nntppool/v4"
+)
+
+// PreferredPoolGetter is implemented by managers that can bias a file's reads
+// toward one provider. Files whose metadata names a preferred provider stream
+// through GetPreferredPool instead of GetStreamPool; test fakes need not
+// implement it.
+type PreferredPoolGetter interface {
+	// GetPreferredPool returns a client that tries provider (a config ID or
+	// name) first and falls back to the shared pool for anything it cannot
+	// serve. When provider is empty, unknown or not currently in the pool it
+	// returns the same client as GetStreamPool.
+	GetPreferredPool(provider string) (NntpClient, error)
+}
+
+// providerTagSetter is implemented by managers that support
+// PreferredPoolGetter; test fakes need not.
+type providerTagSetter interface {
+	SetProviderTags(tags map[string]string)
+}
+
+// preferredClient serves article fetches from preferred and retries them on
+// fallback when preferred fails. Failures are not classified: a provider that
+// lacks the article, is over quota or is unreachable all fall through to the
+// shared pool, which applies its normal provider selection. Batch existence
+// checks and stats go straight to fallback since it covers every provider.
+type preferredClient struct {
+	preferred NntpClient
+	fallback  NntpClient
+}
+
+// WithPreferredProvider returns a client that tries preferred before
+// fallback. A nil preferred returns fallback unchanged.
+func WithPreferredProvider(preferred, fallback NntpClient) NntpClient {
+	if preferred == nil {
+		return fallback
+	}
+	return &preferredClient{preferred: preferred, fallback: fallback}
+}
+
+// retryOnFallback reports whether a failed fetch on the preferred provider
+// should be retried on the shared pool.
+func retryOnFallback(ctx context.Context, err error) bool {
+	return err != nil && ctx.Err() == nil
+}
+
+func (c *preferredClient) Body(ctx context.Context, messageID string, onMeta ...func(nntppool.YEncMeta)) (*nntppool.ArticleBody, error) {
+	body, err := c.preferred.Body(ctx, messageID, onMeta...)
+	if retryOnFallback(ctx, err) {
+		return c.fallback.Body(ctx, messageID, onMeta...)
+	}
+	return body, err
+}
+
+func (c *preferredClient) BodyPriority(ctx context.Context, messageID string, onMeta ...func(nntppool.YEncMeta)) (*nntppool.ArticleBody, error) {
+	body, err := c.preferred.BodyPriority(ctx, messageID, onMeta...)
+	if retryOnFallback(ctx, err) {
+		return c.fallback.BodyPriority(ctx, messageID, onMeta...)
+	}
+	return body, err
+}
+
+// BodyAsync only falls back when the preferred attempt wrote nothing to w, so
+// a transfer that failed part-way is never followed by a second copy.
+func (c *preferredClient) BodyAsync(ctx context.Context, messageID string, w io.Writer, onMeta ...func(nntppool.YEncMeta)) <-chan nntppool.BodyResult {
+	ch := make(chan nntppool.BodyResult, 1)
+	go func() {
+		defer close(ch)
+		cw := &countingWriter{w: w}
+		res := <-c.preferred.BodyAsync(ctx, messageID, cw, onMeta...)
+		if retryOnFallback(ctx, res.Err) && cw.n == 0 {
+			res = <-c.fallback.BodyAsync(ctx, messageID, w, onMeta...)
+		}
+		ch <- res
+	}()
+	return ch
+}
+
+func (c *preferredClient) Stat(ctx context.Context, messageID string) (*nntppool.StatResult, error) {
+	res, err := c.preferred.Stat(ctx, messageID)
+	if retryOnFallback(ctx, err) {
+		return c.fallback.Stat(ctx, messageID)
+	}
+	return res, err
+}
+
+func (c *preferredClient) StatMany(ctx context.Context, messageIDs []string, opts nntppool.StatManyOptions) <-chan nntppool.StatManyResult {
+	return c.fallback.StatMany(ctx, messageIDs, opts)
+}
+
+func (c *preferredClient) Stats() nntppool.ClientStats {
+	return c.fallback.Stats()
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	cw.n += int64(n)
+	return n, err
+}
+
+// preferredConnectionsDivisor sizes a preferred provider's dedicated client
+// relative to the provider's own connection limit. The dedicated client's
+// connections come on top of the shared pool's, so it is kept small.
+const preferredConnectionsDivisor = 4
+
+// GetPreferredPool returns a client biased toward provider. The provider's
+// dedicated single-provider client is created on first use and torn down
+// whenever the provider leaves or the pool is rebuilt.
+func (m *manager) GetPreferredPool(provider string) (NntpClient, error) {
+	fallback, err := m.GetStreamPool()
+	if err != nil || provider == "" {
+		return fallback, err
+	}
+
+	preferred := m.preferredProviderClient(provider)
+	if preferred == nil {
+		return fallback, nil
+	}
+	return WithPreferredProvider(WithAcquireTimeout(preferred, time.Duration(m.acquireTimeout.Load())), fallback), nil
+}
+
+// preferredProviderClient resolves provider to a pool name and returns its dedicated
+// client, or nil when the provider is unknown, not in the pool, held out by
+// the circuit breaker, or the client could not be created.
+func (m *manager) preferredProviderClient(provider string) *nntppool.Client {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	name, ok := m.providerTags[strings.ToLower(provider)]
+	if !ok {
+		name = provider
+	}
+	p, ok := m.providers[name]
+	if !ok || m.pool == nil || (m.breaker != nil && m.breaker.isOpen(name)) {
+		return nil
+	}
+	if client, ok := m.preferred[name]; ok {
+		return client
+	}
+
+	p.Connections = max(p.Connections/preferredConnectionsDivisor, 1)
+	client, err := nntppool.NewClient(m.ctx, []nntppool.Provider{p})
+	if err != nil {
+		m.logger.WarnContext(m.ctx, "Failed to create preferred provider client, using shared pool",
+			"provider", name, "error", err)
+		return nil
+	}
+	if m.preferred == nil {
+		m.preferred = make(map[string]*nntppool.Client)
+	}
+	m.preferred[name] = client
+	return client
+}
+
+// SetProviderTags sets the lookup from lower-cased provider IDs and names to
+// pool names used to resolve a file's preferred provider.
+func (m *manager) SetProviderTags(tags map[string]string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.providerTags = tags
+}
+
+// closePreferredLocked closes the dedicated client for name, or every
+// dedicated client when name is empty. Must be called with m.mu held.
+func (m *manager) closePreferredLocked(name string) {
+	for n, client := range m.preferred {
+		if name == "" || n == name {
+			client.Close()
+			delete(m.preferred, n)
+		}
+	}
+}
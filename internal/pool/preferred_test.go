@@ -0,0 +1,56 @@
+package pool_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/javi11/altmount/internal/pool"
+	"github.com/javi11/altmount/internal/testsupport/fakepool"
+	"github.com/javi11/nntppool/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPreferredProvider_PrefersThenFallsBack(t *testing.T) {
+	preferred, shared := fakepool.New(), fakepool.New()
+	preferred.SetBehavior("<a@x>", fakepool.SegmentBehavior{Bytes: []byte("from-preferred")})
+	preferred.SetBehavior("<b@x>", fakepool.SegmentBehavior{Err: nntppool.ErrArticleNotFound})
+	shared.SetDefaultBehavior(fakepool.SegmentBehavior{Bytes: []byte("from-shared")})
+	client := pool.WithPreferredProvider(preferred, shared)
+
+	body, err := client.BodyPriority(context.Background(), "<a@x>")
+	require.NoError(t, err)
+	assert.Equal(t, "from-preferred", string(body.Bytes))
+	assert.Zero(t, shared.TotalCalls())
+
+	body, err = client.Body(context.Background(), "<b@x>")
+	require.NoError(t, err)
+	assert.Equal(t, "from-shared", string(body.Bytes))
+
+	var buf bytes.Buffer
+	res := <-client.BodyAsync(context.Background(), "<b@x>", &buf)
+	require.NoError(t, res.Err)
+	assert.Equal(t, "from-shared", buf.String())
+
+	_, err = client.Stat(context.Background(), "<b@x>")
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), shared.TotalCalls())
+}
+
+func TestPreferredProvider_NoFallbackAfterCancel(t *testing.T) {
+	preferred, shared := fakepool.New(), fakepool.New()
+	preferred.SetDefaultBehavior(fakepool.SegmentBehavior{Err: nntppool.ErrArticleNotFound})
+	client := pool.WithPreferredProvider(preferred, shared)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := client.Body(ctx, "<a@x>")
+	assert.Error(t, err)
+	assert.Zero(t, shared.TotalCalls(), "a cancelled fetch is not retried on the shared pool")
+}
+
+func TestPreferredProvider_NilPreferred(t *testing.T) {
+	shared := fakepool.New()
+	assert.Same(t, shared, pool.WithPreferredProvider(nil, shared))
+}
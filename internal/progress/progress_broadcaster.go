@@ -14,7 +14,7 @@ type ProgressUpdate struct {
 	QueueID     int       `json:"queue_id"`
 	Percentage  int       `json:"percentage"`
 	Stage       string    `json:"stage,omitempty"`        // e.g. "Parsing NZB", "Validating segments"
-	Status      string    `json:"status,omitempty"`       // "completed", "failed", or "streamable" on terminal/early events
+	Status      string    `json:"status,omitempty"`       // "completed", "failed", "streamable", or a queue status ("processing", "pending") on status events
 	StoragePath string    `json:"storage_path,omitempty"` // set when Status="streamable"
 	Timestamp   time.Time `json:"timestamp"`
 }
@@ -234,6 +234,19 @@ func (pb *ProgressBroadcaster) NotifyStreamable(queueID int, storagePath string)
 	pb.broadcast(update, "subscriber channel full, skipping streamable event")
 }
 
+// NotifyStatus broadcasts a non-terminal status change for a queue item (e.g.
+// "processing" when a worker claims it, "pending" when it is requeued for a
+// retry) so SSE clients can update the row without refetching the queue.
+// Terminal transitions go through NotifyComplete.
+func (pb *ProgressBroadcaster) NotifyStatus(queueID int, status string) {
+	update := ProgressUpdate{
+		QueueID:   queueID,
+		Status:    status,
+		Timestamp: time.Now(),
+	}
+	pb.broadcast(update, "subscriber channel full, skipping status event")
+}
+
 // BroadcastQueueChanged sends a queue-change notification to all SSE subscribers.
 // Uses QueueID=0 and Status="queue_changed" as a sentinel for non-progress queue events.
 func (pb *ProgressBroadcaster) BroadcastQueueChanged() {
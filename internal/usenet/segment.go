@@ -194,6 +194,11 @@ type segment struct {
 	End         int64
 	SegmentSize int64
 	groups      []string
+	// yencName and yencPart come from the article's =ybegin/=ypart header once
+	// downloaded (zero for cache hits and non-yEnc bodies). Written and read
+	// only by the segment's download task; see checkSegmentOrder.
+	yencName string
+	yencPart int64
 	// loaderIdx is the segment's index in the loader's (file's) segment
 	// space, independent of the range-local position. Hole bookkeeping is
 	// keyed on it so persisted hole maps line up across reads.
@@ -0,0 +1,93 @@
+package usenet
+
+import (
+	"errors"
+	"fmt"
+)
+
+// maxSegmentLengthSlack is how many bytes a decoded segment may be off its
+// expected length before the mismatch is treated as corruption. yEnc encoders
+// occasionally pad or trim a byte or two at the end of a part; mismatches that
+// small are absorbed instead of failing the stream.
+const maxSegmentLengthSlack = 16
+
+// maxEncodingOverheadDivisor bounds yEnc's encoding overhead (line breaks and
+// escapes, typically 2-4%) as a fraction of the encoded size: 1/20 = 5%.
+// Metadata whose sizes were never normalized to decoded sizes (no pool at
+// import, or no yEnc headers) declares encoded sizes, so every full segment
+// decodes short by about that much.
+const maxEncodingOverheadDivisor = 20
+
+// ErrSegmentLengthMismatch reports that a segment decoded to a length too far
+// from the one the metadata expects for its offsets to be trusted.
+var ErrSegmentLengthMismatch = errors.New("decoded segment length does not match metadata")
+
+// checkSegmentLength validates a downloaded segment's decoded length against
+// what the reader will consume from it. A segment shorter than its usable end
+// would shift every later byte of the stream, so a small shortfall is padded
+// with zeros; a segment longer than its declared size is only read up to its
+// usable end, so a small surplus needs no adjustment. A shortfall in line
+// with yEnc overhead on a segment read to its declared end means the sizes
+// were never normalized and is passed through as is. Larger mismatches fail
+// the segment. Returns the data to hand to the segment.
+func (b *UsenetReader) checkSegmentLength(s *segment, data []byte) ([]byte, error) {
+	need := s.End + 1
+	got := int64(len(data))
+
+	var expected int64
+	switch {
+	case got < need && encodedSizeShortfall(s, got):
+		// The offsets come from the same encoded sizes, so the stream was never
+		// byte-exact; padding would only add zeros mid-file.
+		b.log.DebugContext(b.ctx, "segment size looks yEnc-encoded, skipping length check",
+			"segment_id", s.Id,
+			"decoded_bytes", got,
+			"declared_bytes", s.SegmentSize)
+		return data, nil
+	case got < need:
+		expected = need
+	case s.SegmentSize > 0 && got > s.SegmentSize:
+		expected = s.SegmentSize
+	default:
+		return data, nil
+	}
+	diff := got - expected
+
+	if -maxSegmentLengthSlack <= diff && diff <= maxSegmentLengthSlack {
+		b.log.WarnContext(b.ctx, "segment decoded to unexpected length, adjusting",
+			"segment_id", s.Id,
+			"decoded_bytes", got,
+			"expected_bytes", expected,
+			"difference", diff)
+		if got < need {
+			padded := make([]byte, need)
+			copy(padded, data)
+			return padded, nil
+		}
+		return data, nil
+	}
+
+	b.log.ErrorContext(b.ctx, "segment decoded to unexpected length",
+		"segment_id", s.Id,
+		"decoded_bytes", got,
+		"expected_bytes", expected,
+		"difference", diff)
+	return nil, &DataCorruptionError{
+		UnderlyingErr: fmt.Errorf("%w: %s decoded to %d bytes (off by %d)",
+			ErrSegmentLengthMismatch, s.Id, got, diff),
+		NoRetry:    true,
+		FileOffset: -1,
+		SegmentID:  s.Id,
+	}
+}
+
+// encodedSizeShortfall reports whether a segment that decoded to got bytes
+// looks like one whose metadata declares its encoded size: it is used through
+// its declared end and fell short by no more than the encoding overhead.
+func encodedSizeShortfall(s *segment, got int64) bool {
+	if s.SegmentSize <= 0 || s.End+1 != s.SegmentSize {
+		return false
+	}
+	short := s.SegmentSize - got
+	return short > maxSegmentLengthSlack && short <= s.SegmentSize/maxEncodingOverheadDivisor
+}
@@ -0,0 +1,93 @@
+package usenet
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/javi11/altmount/internal/testsupport/fakepool"
+	"github.com/javi11/altmount/internal/testsupport/segments"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// resizedPool serves n segments of segSize bytes, except segment 1 which
+// decodes to segSize+delta bytes.
+func resizedPool(n, segSize, delta int) *fakepool.Client {
+	fp := fakepool.New()
+	for i := range n {
+		payload := segments.Payload(i, segSize)
+		if i == 1 {
+			if delta < 0 {
+				payload = payload[:segSize+delta]
+			} else {
+				payload = append(payload, make([]byte, delta)...)
+			}
+		}
+		fp.SetBehavior(segments.MessageID(i), fakepool.SegmentBehavior{Bytes: payload})
+	}
+	return fp
+}
+
+func readResized(t *testing.T, segSize, delta int) ([]byte, error) {
+	t.Helper()
+	const n = 4
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	rg := buildEagerRange(ctx, t, n, segSize)
+	ur := newReaderForTest(t, ctx, resizedPool(n, segSize, delta), rg, n)
+	ur.Start()
+	return io.ReadAll(ur)
+}
+
+func TestSegmentLength_SmallMismatchKeepsOffsets(t *testing.T) {
+	t.Parallel()
+	const segSize = 64
+
+	for _, delta := range []int{-1, 1} {
+		data, err := readResized(t, segSize, delta)
+		require.NoError(t, err, "delta %d", delta)
+		require.Len(t, data, 4*segSize, "delta %d", delta)
+
+		// The segments after the resized one stay at their offsets.
+		assert.Equal(t, segments.Payload(2, segSize), data[2*segSize:3*segSize], "delta %d", delta)
+		assert.Equal(t, segments.Payload(3, segSize), data[3*segSize:], "delta %d", delta)
+
+		want := segments.Payload(1, segSize)
+		if delta < 0 {
+			want[segSize-1] = 0 // the missing byte is padded
+		}
+		assert.Equal(t, want, data[segSize:2*segSize], "delta %d", delta)
+	}
+}
+
+func TestSegmentLength_LargeMismatchFails(t *testing.T) {
+	t.Parallel()
+	const segSize = 64
+
+	for _, delta := range []int{-(maxSegmentLengthSlack + 1), maxSegmentLengthSlack + 1} {
+		_, err := readResized(t, segSize, delta)
+		require.Error(t, err, "delta %d", delta)
+		assert.ErrorIs(t, err, ErrSegmentLengthMismatch)
+		var corruption *DataCorruptionError
+		require.True(t, errors.As(err, &corruption))
+		assert.True(t, corruption.NoRetry)
+		assert.Equal(t, segments.MessageID(1), corruption.SegmentID)
+	}
+}
+
+func TestSegmentLength_EncodedSizesPassThrough(t *testing.T) {
+	t.Parallel()
+	const segSize = 2000
+
+	// Un-normalized metadata declares the encoded size; the article decodes
+	// about 2% short. The check steps aside instead of failing the stream.
+	const delta = -40
+	data, err := readResized(t, segSize, delta)
+	require.NoError(t, err)
+	require.Len(t, data, 4*segSize+delta)
+	assert.Equal(t, segments.Payload(1, segSize)[:segSize+delta], data[segSize:2*segSize+delta])
+}
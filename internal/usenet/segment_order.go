@@ -0,0 +1,80 @@
+package usenet
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrSegmentsOutOfOrder reports that a file's segments are not stored in file
+// order, so byte offsets computed from them map to the wrong data.
+var ErrSegmentsOutOfOrder = errors.New("segments are not in file order")
+
+// segmentPart is the yEnc identity of a downloaded segment.
+type segmentPart struct {
+	id   string
+	name string
+	part int64
+}
+
+// checkSegmentOrder compares a freshly downloaded segment's yEnc part number
+// with its already downloaded neighbours. Consecutive articles of the same
+// posted file must have ascending part numbers; anything else means the
+// metadata laid the segments out in the wrong order. Downloads complete out of
+// order, so whichever of two neighbours lands second performs the check. The
+// range releases segments as they are read, so the headers are remembered in
+// b.parts by index instead. Segments repeating the same article (patched
+// gaps) and articles without yEnc part info are skipped.
+func (b *UsenetReader) checkSegmentOrder(idx int, s *segment) error {
+	if s.yencPart <= 0 {
+		return nil
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.rg == nil {
+		return nil
+	}
+
+	cur := segmentPart{id: s.Id, name: s.yencName, part: s.yencPart}
+	if b.parts == nil {
+		b.parts = make(map[int]segmentPart)
+	}
+	b.parts[idx] = cur
+	// A segment the reader has not reached yet pins the read position at or
+	// before it, so entries more than one behind the position have no
+	// neighbour left to check against.
+	for ; b.partsFloor < b.rg.GetCurrentIndex()-1; b.partsFloor++ {
+		delete(b.parts, b.partsFloor)
+	}
+
+	var before, after segmentPart
+	if prev, ok := b.parts[idx-1]; ok && segmentsOutOfOrder(prev, cur) {
+		before, after = prev, cur
+	} else if next, ok := b.parts[idx+1]; ok && segmentsOutOfOrder(cur, next) {
+		before, after = cur, next
+	} else {
+		return nil
+	}
+
+	b.log.ErrorContext(b.ctx, "segments are not in file order",
+		"segment_id", after.id,
+		"yenc_name", after.name,
+		"part", after.part,
+		"previous_part", before.part)
+	return &DataCorruptionError{
+		UnderlyingErr: fmt.Errorf("%w: %s (part %d of %q) follows part %d",
+			ErrSegmentsOutOfOrder, after.id, after.part, after.name, before.part),
+		NoRetry:    true,
+		FileOffset: -1,
+		SegmentID:  after.id,
+	}
+}
+
+// segmentsOutOfOrder reports whether b, stored right after a, carries a part
+// number that does not come after a's within the same posted file.
+func segmentsOutOfOrder(a, b segmentPart) bool {
+	if a.id == b.id || a.name != b.name {
+		return false
+	}
+	return b.part <= a.part
+}
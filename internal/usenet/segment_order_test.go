@@ -0,0 +1,77 @@
+package usenet
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/javi11/altmount/internal/testsupport/fakepool"
+	"github.com/javi11/altmount/internal/testsupport/segments"
+	"github.com/javi11/nntppool/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// orderedPool serves segments.MessageID(i) as yEnc part parts[i] of one
+// posted file.
+func orderedPool(parts []int64, segSize int) *fakepool.Client {
+	fp := fakepool.New()
+	for i, part := range parts {
+		fp.SetBehavior(segments.MessageID(i), fakepool.SegmentBehavior{
+			Bytes: segments.Payload(i, segSize),
+			YEnc:  nntppool.YEncMeta{FileName: "movie.mkv", Part: part},
+		})
+	}
+	return fp
+}
+
+func TestSegmentOrder_InterleavedLayoutIsCorrupt(t *testing.T) {
+	t.Parallel()
+	const segSize = 16
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Parts 2 and 3 were stored swapped.
+	fp := orderedPool([]int64{1, 3, 2, 4}, segSize)
+	rg := buildEagerRange(ctx, t, 4, segSize)
+	ur := newReaderForTest(t, ctx, fp, rg, 4)
+	ur.Start()
+
+	_, err := io.ReadAll(ur)
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrSegmentsOutOfOrder)
+	var corruption *DataCorruptionError
+	require.True(t, errors.As(err, &corruption))
+	assert.True(t, corruption.NoRetry)
+	assert.Contains(t, err.Error(), "follows part")
+}
+
+func TestSegmentOrder_AscendingLayoutReads(t *testing.T) {
+	t.Parallel()
+	const segSize = 16
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	fp := orderedPool([]int64{1, 2, 3, 4}, segSize)
+	rg := buildEagerRange(ctx, t, 4, segSize)
+	ur := newReaderForTest(t, ctx, fp, rg, 4)
+	ur.Start()
+
+	data, err := io.ReadAll(ur)
+	require.NoError(t, err)
+	assert.Len(t, data, 4*segSize)
+}
+
+func TestSegmentsOutOfOrder(t *testing.T) {
+	part := func(id, name string, n int64) segmentPart {
+		return segmentPart{id: id, name: name, part: n}
+	}
+	assert.False(t, segmentsOutOfOrder(part("a", "f", 1), part("b", "f", 2)))
+	assert.True(t, segmentsOutOfOrder(part("a", "f", 2), part("b", "f", 1)))
+	assert.True(t, segmentsOutOfOrder(part("a", "f", 2), part("b", "f", 2)))
+	// Same article repeated, or parts of different posted files.
+	assert.False(t, segmentsOutOfOrder(part("a", "f", 2), part("a", "f", 2)))
+	assert.False(t, segmentsOutOfOrder(part("a", "f", 2), part("b", "g", 1)))
+}
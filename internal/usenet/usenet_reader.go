@@ -20,6 +20,8 @@ import (
 
 const (
 	defaultMaxPrefetch = 60 // Default to 60 segments prefetched ahead
+	// defaultSegmentFetchTimeout bounds a single fetch attempt of one segment.
+	defaultSegmentFetchTimeout = 15 * time.Second
 )
 
 var (
@@ -84,6 +86,86 @@ func WithImportProfile(budget ConnBudget) ReaderOption {
 	}
 }
 
+// WithMaxInFlightBytes caps the bytes of the segments scheduled ahead of the
+// read position (downloading, or downloaded and not yet consumed) on top of
+// the maxPrefetch segment count, so deep prefetch on files with large segments
+// cannot buffer unbounded memory. The segment under the read position is
+// always scheduled, even if it alone exceeds the cap. Non-positive values
+// disable the cap.
+func WithMaxInFlightBytes(n int64) ReaderOption {
+	return func(r *UsenetReader) {
+		r.maxBytes = max(n, 0)
+	}
+}
+
+// WithSegmentFetchTimeout sets the deadline for a single segment fetch
+// attempt. A segment that misses it is retried on a fresh connection (the pool
+// round-robins across providers) while the other in-flight segments keep
+// going, so one slow article cannot stall the whole range until the overall
+// read timeout. Non-positive values keep the default.
+func WithSegmentFetchTimeout(d time.Duration) ReaderOption {
+	return func(r *UsenetReader) {
+		if d > 0 {
+			r.segmentFetchTimeout = d
+		}
+	}
+}
+
+// SegmentTrace describes how a reader obtained one segment; see
+// WithSegmentTrace.
+type SegmentTrace struct {
+	// Index is the segment's position in the file's segment list.
+	Index     int
+	MessageID string
+	// Bytes is the decoded size of the segment.
+	Bytes int
+	// CacheHit is set when the segment came from the segment cache.
+	CacheHit bool
+	// Padded is set when a missing segment was zero-filled by the hole hooks.
+	Padded bool
+	// Attempts counts wire fetches, retries included.
+	Attempts int
+	// Duration covers the whole fetch, retries and checks included.
+	Duration time.Duration
+	// Provider is the provider whose received bytes grew most during the
+	// fetch, or "" when none did. It is inferred from the pool's counters, so
+	// it is only exact when nothing else reads through the pool meanwhile.
+	Provider string
+	// Err is the error the segment failed with, if any.
+	Err error
+}
+
+// WithSegmentTrace calls fn once for every segment the reader schedules,
+// after the segment is settled. Tracing costs a pool stats snapshot per
+// fetch, so it is meant for diagnostics rather than normal streaming. fn runs
+// on download goroutines and must be concurrency-safe. A nil fn disables it.
+func WithSegmentTrace(fn func(SegmentTrace)) ReaderOption {
+	return func(r *UsenetReader) {
+		r.trace = fn
+	}
+}
+
+// WithMissingSegmentAbort fails the whole read once more than fraction of
+// the segments fetched so far have been confirmed missing on every provider,
+// instead of probing (or zero-filling) each remaining one before the
+// inevitable failure. The ratio is judged only after missingAbortMinSample
+// fetches (or the whole range, if shorter), so one early miss cannot abort a
+// read. In-flight fetches are cancelled and Read returns a DataCorruptionError
+// wrapping nntppool.ErrArticleNotFound. Segments the hole hooks already know
+// to be missing are not counted, as they cost no fetch. Values outside (0, 1]
+// disable it.
+func WithMissingSegmentAbort(fraction float64) ReaderOption {
+	return func(r *UsenetReader) {
+		if fraction > 0 && fraction <= 1 {
+			r.missingAbortFraction = fraction
+		}
+	}
+}
+
+// missingAbortMinSample is how many segment fetches must settle before
+// WithMissingSegmentAbort judges the missing ratio.
+const missingAbortMinSample = 5
+
 type DataCorruptionError struct {
 	UnderlyingErr error
 	BytesRead     int64
@@ -109,7 +191,8 @@ type UsenetReader struct {
 	ctx            context.Context // Reader's context for cancellation
 	cancel         context.CancelFunc
 	rg             *segmentRange
-	maxPrefetch    int // Maximum segments prefetched ahead of current read position
+	maxPrefetch    int   // Maximum segments prefetched ahead of current read position
+	maxBytes       int64 // Maximum bytes buffered or in flight ahead of the reader (0 = unlimited)
 	init           chan any
 	initDownload   sync.Once
 	closeOnce      sync.Once
@@ -123,9 +206,29 @@ type UsenetReader struct {
 	budget         ConnBudget   // optional; gates import fetches on the global connection budget
 	cond           *sync.Cond   // Signals downloadManager when reader advances
 
+	// segmentFetchTimeout is the per-attempt deadline of one segment fetch.
+	segmentFetchTimeout time.Duration
+
+	// trace receives a SegmentTrace per settled segment (WithSegmentTrace).
+	trace func(SegmentTrace)
+
+	// missingAbortFraction is WithMissingSegmentAbort's fraction (0 = off).
+	// fetchedSegments counts the segment fetches settled so far and
+	// missingSegments those confirmed missing; abortErr is set once the
+	// latter exceed the fraction of the former.
+	missingAbortFraction float64
+	fetchedSegments      atomic.Int32
+	missingSegments      atomic.Int32
+	abortErr             atomic.Pointer[error]
+
 	// Prefetch-based download tracking
 	nextToDownload int // Index of next segment to schedule
 
+	// yEnc part headers of downloaded segments by range index, kept until the
+	// reader is past both neighbours; see checkSegmentOrder.
+	parts      map[int]segmentPart
+	partsFloor int
+
 	// Tracing counters (atomic, no lock needed)
 	inFlight atomic.Int32 // goroutines actively downloading right now
 
@@ -161,6 +264,8 @@ func NewUsenetReader(
 		streamID:       streamID,
 		segmentStore:   segmentStore,
 		priority:       true, // streaming profile by default; WithImportProfile demotes
+
+		segmentFetchTimeout: defaultSegmentFetchTimeout,
 	}
 	for _, opt := range opts {
 		opt(ur)
@@ -186,6 +291,63 @@ func (b *UsenetReader) Start() {
 	})
 }
 
+// Prime starts the download and blocks until at least minBytes from the
+// current read position are buffered, returning the bytes actually buffered.
+// It never waits past the maxPrefetch window, since segments beyond it are not
+// scheduled until the reader advances. A failed segment ends priming early
+// without an error; the following Read reports it.
+func (b *UsenetReader) Prime(ctx context.Context, minBytes int64) (int64, error) {
+	b.Start()
+
+	b.mu.Lock()
+	rg := b.rg
+	b.mu.Unlock()
+	if rg == nil || minBytes <= 0 {
+		return 0, nil
+	}
+
+	b.mu.Lock()
+	window := b.maxPrefetch
+	b.mu.Unlock()
+
+	var primed int64
+	first := rg.GetCurrentIndex()
+	for idx := first; idx < first+window && primed < minBytes; idx++ {
+		seg, err := rg.GetSegment(idx)
+		if err != nil || seg == nil {
+			break
+		}
+
+		select {
+		case <-seg.dataReady:
+		case <-ctx.Done():
+			return primed, ctx.Err()
+		case <-b.ctx.Done():
+			return primed, b.ctx.Err()
+		}
+
+		if seg.GetDownloadError() != nil {
+			break
+		}
+		primed += seg.End - seg.Start + 1
+	}
+
+	return primed, nil
+}
+
+// SetMaxPrefetch changes how many segments the reader downloads ahead of the
+// read position. Raising it lets a reader opened with a narrow window ramp up
+// once its caller turns out to be reading straight through.
+func (b *UsenetReader) SetMaxPrefetch(n int) {
+	if n <= 0 {
+		n = defaultMaxPrefetch
+	}
+	b.mu.Lock()
+	b.maxPrefetch = n
+	b.mu.Unlock()
+	b.cond.Broadcast()
+}
+
 // Interrupt cancels the reader's context and signals any blocked Read
 // to return. Non-blocking and idempotent; safe to call concurrently
 // with Read or Close. The caller is still responsible for invoking
@@ -265,6 +427,22 @@ func (b *UsenetReader) Read(p []byte) (int, error) {
 	if len(p) == 0 {
 		return 0, nil
 	}
+	if err := b.abortError(); err != nil {
+		return 0, err
+	}
+
+	n, err := b.read(p)
+	if err != nil {
+		// Aborting cancels the reader, so whatever the read tripped over
+		// (a cancelled wait, a failed segment) is reported as the abort.
+		if abortErr := b.abortError(); abortErr != nil {
+			return n, abortErr
+		}
+	}
+	return n, err
+}
+
+func (b *UsenetReader) read(p []byte) (int, error) {
 
 	b.initDownload.Do(func() {
 		select {
@@ -302,6 +480,9 @@ func (b *UsenetReader) Read(p []byte) (int, error) {
 				}
 			}
 		}
+		if errors.Is(err, pool.ErrPoolExhausted) {
+			return 0, err
+		}
 		return 0, io.EOF
 	}
 
@@ -346,6 +527,9 @@ func (b *UsenetReader) Read(p []byte) (int, error) {
 							}
 						}
 					}
+					if errors.Is(err, pool.ErrPoolExhausted) {
+						return n, err
+					}
 					return n, io.EOF
 				}
 			} else {
@@ -364,6 +548,67 @@ func (b *UsenetReader) Read(p []byte) (int, error) {
 	return n, nil
 }
 
+// noteFetchedSegment counts one more settled segment fetch, missing or not,
+// and, once WithMissingSegmentAbort's fraction of those fetched is exceeded,
+// aborts the read: the reader is cancelled so no further segments are
+// fetched. It returns the abort's error once aborted, nil otherwise.
+func (b *UsenetReader) noteFetchedSegment(ctx context.Context, missing bool) error {
+	if b.missingAbortFraction <= 0 {
+		return nil
+	}
+	fetched := int(b.fetchedSegments.Add(1))
+	if !missing {
+		// Hits only lower the ratio.
+		return b.abortError()
+	}
+	missed := int(b.missingSegments.Add(1))
+
+	b.mu.Lock()
+	rg := b.rg
+	b.mu.Unlock()
+	if rg == nil {
+		return nil
+	}
+	if fetched < min(missingAbortMinSample, rg.Len()) ||
+		float64(missed) <= b.missingAbortFraction*float64(fetched) {
+		return b.abortError()
+	}
+
+	err := fmt.Errorf("%d of %d segments fetched missing, over the %.0f%% abort threshold: %w",
+		missed, fetched, b.missingAbortFraction*100, nntppool.ErrArticleNotFound)
+	if b.abortErr.CompareAndSwap(nil, &err) {
+		b.log.WarnContext(ctx, "Aborting read: too many missing segments",
+			"missing", missed,
+			"fetched", fetched,
+			"fraction", b.missingAbortFraction)
+		b.cancel()
+		b.cond.Broadcast()
+	}
+	return b.abortError()
+}
+
+// abortError returns the DataCorruptionError a read aborted by
+// noteFetchedSegment fails with, or nil when it was not aborted.
+func (b *UsenetReader) abortError() error {
+	errp := b.abortErr.Load()
+	if errp == nil {
+		return nil
+	}
+	b.mu.Lock()
+	totalRead := b.totalBytesRead
+	var start int64 = -1
+	if b.rg != nil {
+		start = b.rg.start + totalRead
+	}
+	b.mu.Unlock()
+	return &DataCorruptionError{
+		UnderlyingErr: *errp,
+		BytesRead:     totalRead,
+		NoRetry:       true,
+		FileOffset:    start,
+	}
+}
+
 // isArticleNotFoundError checks if the error indicates articles were not found in providers
 func (b *UsenetReader) isArticleNotFoundError(err error) bool {
 	return errors.Is(err, nntppool.ErrArticleNotFound)
@@ -396,8 +641,10 @@ func (b *UsenetReader) GetBufferedOffset() int64 {
 	return s.Start + int64(s.SegmentSize)
 }
 
-// downloadSegmentWithRetry attempts to download a segment with retry logic for pool unavailability
-func (b *UsenetReader) downloadSegmentWithRetry(ctx context.Context, seg *segment) ([]byte, error) {
+// downloadSegmentWithRetry attempts to download a segment with retry logic
+// for pool unavailability. When tr is not nil it records the cache outcome,
+// the attempts made and the provider that served the segment.
+func (b *UsenetReader) downloadSegmentWithRetry(ctx context.Context, seg *segment, tr *SegmentTrace) ([]byte, error) {
 	// Cache HIT: skip NNTP entirely
 	if b.segmentStore != nil {
 		if data, ok := b.segmentStore.Get(seg.Id); ok {
@@ -405,6 +652,9 @@ func (b *UsenetReader) downloadSegmentWithRetry(ctx context.Context, seg *segmen
 				"segment_id", seg.Id,
 				"size_bytes", len(data),
 			)
+			if tr != nil {
+				tr.CacheHit = true
+			}
 			return data, nil
 		}
 	}
@@ -441,12 +691,21 @@ func (b *UsenetReader) downloadSegmentWithRetry(ctx context.Context, seg *segmen
 		defer release()
 	}
 
+	var before map[string]int64
+	if tr != nil {
+		before = providerBytes(cp.Stats())
+	}
+
 	segStart := time.Now()
 	var resultBytes []byte
 	err := retry.Do(
 		func() error {
-			// Fix C: reduce per-attempt timeout 30s → 15s to free stuck connections faster
-			attemptCtx, cancel := context.WithTimeout(ctx, 15*time.Second)
+			if tr != nil {
+				tr.Attempts++
+			}
+			// Per-attempt deadline (WithSegmentFetchTimeout, 15s by default) frees
+			// stuck connections and lets a slow article fail over on retry.
+			attemptCtx, cancel := context.WithTimeout(ctx, b.segmentFetchTimeout)
 			defer cancel()
 
 			fetchStart := time.Now()
@@ -462,8 +721,9 @@ func (b *UsenetReader) downloadSegmentWithRetry(ctx context.Context, seg *segmen
 			fetchDur := time.Since(fetchStart)
 			if err != nil {
 				if errors.Is(err, context.DeadlineExceeded) {
-					b.log.DebugContext(ctx, "segment download timed out after 15s",
+					b.log.DebugContext(ctx, "segment download timed out",
 						"segment_id", seg.Id,
+						"timeout", b.segmentFetchTimeout,
 						"fetch_dur", fetchDur,
 					)
 				}
@@ -486,6 +746,7 @@ func (b *UsenetReader) downloadSegmentWithRetry(ctx context.Context, seg *segmen
 			}
 
 			resultBytes = result.Bytes
+			seg.yencName, seg.yencPart = result.YEnc.FileName, result.YEnc.Part
 			b.metricsTracker.IncArticlesDownloaded()
 			b.metricsTracker.UpdateDownloadProgress(b.streamID, int64(len(resultBytes)))
 
@@ -512,6 +773,9 @@ func (b *UsenetReader) downloadSegmentWithRetry(ctx context.Context, seg *segmen
 			if errors.Is(err, nntppool.ErrArticleNotFound) {
 				return false // permanent failure — do not retry
 			}
+			if errors.Is(err, pool.ErrPoolExhausted) {
+				return false // already waited the acquire timeout — fail fast
+			}
 			return true
 		}),
 		retry.OnRetry(func(n uint, err error) {
@@ -532,6 +796,10 @@ func (b *UsenetReader) downloadSegmentWithRetry(ctx context.Context, seg *segmen
 		_ = b.segmentStore.Put(seg.Id, resultBytes)
 	}
 
+	if tr != nil {
+		tr.Provider = busiestProvider(before, providerBytes(cp.Stats()))
+	}
+
 	if errors.Is(err, nntppool.ErrArticleNotFound) {
 		b.log.DebugContext(ctx, "missing segment",
 			"segment_id", seg.Id,
@@ -541,6 +809,50 @@ func (b *UsenetReader) downloadSegmentWithRetry(ctx context.Context, seg *segmen
 	return resultBytes, err
 }
 
+// providerBytes returns the bytes each provider in stats has received.
+func providerBytes(stats nntppool.ClientStats) map[string]int64 {
+	out := make(map[string]int64, len(stats.Providers))
+	for _, ps := range stats.Providers {
+		out[ps.Name] = ps.BytesConsumed
+	}
+	return out
+}
+
+// busiestProvider returns the provider whose received bytes grew most from
+// before to after, or "" when none grew.
+func busiestProvider(before, after map[string]int64) string {
+	var name string
+	var most int64
+	for n, v := range after {
+		if d := v - before[n]; d > most {
+			name, most = n, d
+		}
+	}
+	return name
+}
+
+// overByteBudgetLocked reports whether scheduling the next segment would push
+// the bytes held between the read position and nextToDownload over maxBytes.
+// It never blocks the segment at the read position, so a reader always makes
+// progress. Caller must hold b.mu.
+func (b *UsenetReader) overByteBudgetLocked(currentRead int) bool {
+	if b.maxBytes <= 0 || b.nextToDownload <= currentRead {
+		return false
+	}
+
+	next, err := b.rg.GetSegment(b.nextToDownload)
+	if err != nil || next == nil {
+		return false
+	}
+	total := next.SegmentSize
+	for idx := currentRead; idx < b.nextToDownload; idx++ {
+		if s, err := b.rg.GetSegment(idx); err == nil && s != nil {
+			total += s.SegmentSize
+		}
+	}
+	return total > b.maxBytes
+}
+
 func (b *UsenetReader) downloadManager(ctx context.Context) {
 	select {
 	case _, ok := <-b.init:
@@ -573,7 +885,7 @@ func (b *UsenetReader) downloadManager(ctx context.Context) {
 		// Limit how far ahead we prefetch beyond the current read position
 		currentRead := b.rg.GetCurrentIndex()
 		ahead := b.nextToDownload - currentRead
-		if ahead >= b.maxPrefetch {
+		if ahead >= b.maxPrefetch || b.overByteBudgetLocked(currentRead) {
 			b.cond.Wait()
 			b.mu.Unlock()
 			if ctx.Err() != nil {
@@ -605,31 +917,60 @@ func (b *UsenetReader) downloadManager(ctx context.Context) {
 
 			taskCtx := slogutil.With(ctx, "segment_id", s.Id, "segment_idx", segIdx)
 
+			var tr *SegmentTrace
+			if b.trace != nil {
+				tr = &SegmentTrace{Index: s.loaderIdx, MessageID: s.Id}
+				defer func(start time.Time) {
+					tr.Duration = time.Since(start)
+					b.trace(*tr)
+				}(time.Now())
+			}
+
 			// Replay pre-pad: a segment already known missing (persisted hole
 			// map) zero-fills immediately, with no fetch round-trip.
 			if b.holeHooks != nil && b.holeHooks.KnownHoles != nil && b.holeHooks.KnownHoles(s.loaderIdx) {
 				b.log.DebugContext(taskCtx, "zero-filling known-missing segment without fetch")
 				s.SetData(make([]byte, s.End+1))
+				if tr != nil {
+					tr.Padded, tr.Bytes = true, int(s.End+1)
+				}
 				return
 			}
 
-			data, err := b.downloadSegmentWithRetry(taskCtx, s)
+			data, err := b.downloadSegmentWithRetry(taskCtx, s, tr)
+			if err == nil {
+				err = b.checkSegmentOrder(segIdx, s)
+			}
+			if err == nil {
+				data, err = b.checkSegmentLength(s, data)
+			}
+
+			missing := errors.Is(err, nntppool.ErrArticleNotFound)
+			if abortErr := b.noteFetchedSegment(taskCtx, missing); abortErr != nil && err != nil {
+				err = abortErr
+			}
 
 			if err != nil {
 				// A confirmed-missing article may be zero-filled instead of
 				// failing the stream, when the owner's hole hook approves.
-				if b.holeHooks != nil && b.holeHooks.OnHole != nil &&
+				if b.abortError() == nil && b.holeHooks != nil && b.holeHooks.OnHole != nil &&
 					errors.Is(err, nntppool.ErrArticleNotFound) &&
 					b.holeHooks.OnHole(s.loaderIdx, s.Id) == holes.DecisionPad {
 					b.log.InfoContext(taskCtx, "zero-filling missing segment",
 						"file_segment_index", s.loaderIdx)
 					s.SetData(make([]byte, s.End+1))
+					if tr != nil {
+						tr.Padded, tr.Bytes, tr.Err = true, int(s.End+1), err
+					}
 					return
 				}
 				s.SetError(err)
 			} else {
 				s.SetData(data)
 			}
+			if tr != nil {
+				tr.Bytes, tr.Err = len(data), err
+			}
 		}(idx, seg)
 	}
 
@@ -49,6 +49,7 @@ func TestUsenetReader_StreamingUsesPriorityLane(t *testing.T) {
 
 	const nSegs, segSize = 4, 64
 	fp := fakepool.New()
+	fp.SetDefaultBehavior(fakepool.SegmentBehavior{Bytes: make([]byte, segSize)})
 	rg := buildEagerRange(ctx, t, nSegs, segSize)
 	ur := newReaderForTest(t, ctx, fp, rg, nSegs)
 
@@ -68,6 +69,7 @@ func TestUsenetReader_ImportProfileUsesNormalLaneAndBudget(t *testing.T) {
 
 	const nSegs, segSize = 4, 64
 	fp := fakepool.New()
+	fp.SetDefaultBehavior(fakepool.SegmentBehavior{Bytes: make([]byte, segSize)})
 	rg := buildEagerRange(ctx, t, nSegs, segSize)
 
 	budget := &recordingBudget{}
@@ -101,6 +103,7 @@ func TestUsenetReader_ImportBudgetBoundsInFlightFetches(t *testing.T) {
 
 	const nSegs, segSize = 12, 64
 	fp := fakepool.New()
+	fp.SetDefaultBehavior(fakepool.SegmentBehavior{Bytes: make([]byte, segSize)})
 	rg := buildEagerRange(ctx, t, nSegs, segSize)
 
 	// Real budget with capacity 2: the reader's prefetch fan-out must never
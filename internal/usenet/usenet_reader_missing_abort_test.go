@@ -0,0 +1,137 @@
+package usenet
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/javi11/altmount/internal/holes"
+	"github.com/javi11/altmount/internal/pool"
+	"github.com/javi11/altmount/internal/testsupport/fakepool"
+	"github.com/javi11/altmount/internal/testsupport/segments"
+	"github.com/javi11/nntppool/v4"
+)
+
+// padAll approves zero-filling every missing segment.
+var padAll = &HoleHooks{
+	OnHole: func(int, string) holes.Decision { return holes.DecisionPad },
+}
+
+func newAbortingReader(t *testing.T, ctx context.Context, fp *fakepool.Client, rg *segmentRange, maxPrefetch int, hooks *HoleHooks, fraction float64) *UsenetReader {
+	t.Helper()
+	getter := func() (pool.NntpClient, error) { return fp, nil }
+	ur, err := NewUsenetReader(ctx, getter, rg, maxPrefetch, noopMetrics{}, "test-stream", nil,
+		WithHoleHooks(hooks), WithMissingSegmentAbort(fraction))
+	if err != nil {
+		t.Fatalf("NewUsenetReader: %v", err)
+	}
+	t.Cleanup(func() { _ = ur.Close() })
+	return ur
+}
+
+func assertAbortedCorruption(t *testing.T, err error) {
+	t.Helper()
+	var dce *DataCorruptionError
+	if !errors.As(err, &dce) {
+		t.Fatalf("err = %v, want *DataCorruptionError", err)
+	}
+	if !dce.NoRetry {
+		t.Error("abort should not be retried")
+	}
+	if !errors.Is(err, nntppool.ErrArticleNotFound) {
+		t.Errorf("err = %v, want it to wrap ErrArticleNotFound", err)
+	}
+}
+
+func TestMissingSegmentAbort_StopsProbingPaddedHoles(t *testing.T) {
+	ctx := context.Background()
+	const n, segSize = 10, 4
+	fp := fillFakePool(n, segSize)
+	for i := range 7 {
+		fp.SetBehavior(segments.MessageID(i), fakepool.SegmentBehavior{Err: nntppool.ErrArticleNotFound})
+	}
+
+	ur := newAbortingReader(t, ctx, fp, buildEagerRange(ctx, t, n, segSize), 1, padAll, 0.5)
+	_, err := io.ReadAll(ur)
+	assertAbortedCorruption(t, err)
+
+	// Five fetched, five missing: the sample is full and over half, so
+	// nothing past the fifth segment is tried.
+	for i := missingAbortMinSample; i < n; i++ {
+		if calls := fp.PerMessageCalls(segments.MessageID(i)); calls != 0 {
+			t.Errorf("segment %d fetched %d times after the abort", i, calls)
+		}
+	}
+}
+
+func TestMissingSegmentAbort_FailsBeforeSlowHead(t *testing.T) {
+	ctx := context.Background()
+	const n, segSize = 10, 4
+	fp := fillFakePool(n, segSize)
+	fp.SetBehavior(segments.MessageID(0), fakepool.SegmentBehavior{Latency: 10 * time.Second, Bytes: make([]byte, segSize)})
+	for i := 1; i <= 6; i++ {
+		fp.SetBehavior(segments.MessageID(i), fakepool.SegmentBehavior{Err: nntppool.ErrArticleNotFound})
+	}
+
+	ur := newAbortingReader(t, ctx, fp, buildEagerRange(ctx, t, n, segSize), n, nil, 0.5)
+	start := time.Now()
+	_, err := ur.Read(make([]byte, segSize))
+	assertAbortedCorruption(t, err)
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("read waited %v for the slow first segment instead of aborting", elapsed)
+	}
+}
+
+func TestMissingSegmentAbort_UnderThresholdPads(t *testing.T) {
+	ctx := context.Background()
+	const n, segSize = 10, 4
+	fp := fillFakePool(n, segSize)
+	for _, i := range []int{2, 5} {
+		fp.SetBehavior(segments.MessageID(i), fakepool.SegmentBehavior{Err: nntppool.ErrArticleNotFound})
+	}
+
+	ur := newAbortingReader(t, ctx, fp, buildEagerRange(ctx, t, n, segSize), 4, padAll, 0.5)
+	got, err := io.ReadAll(ur)
+	if err != nil {
+		t.Fatalf("ReadAll: %v", err)
+	}
+	if len(got) != n*segSize {
+		t.Fatalf("read %d bytes, want %d", len(got), n*segSize)
+	}
+}
+
+func TestMissingSegmentAbort_JudgesFetchedNotRange(t *testing.T) {
+	ctx := context.Background()
+	const n, segSize = 100, 4
+	fp := fillFakePool(n, segSize)
+	for i := range 10 {
+		fp.SetBehavior(segments.MessageID(i), fakepool.SegmentBehavior{Err: nntppool.ErrArticleNotFound})
+	}
+
+	// A dead head on a long range aborts long before half the range is tried.
+	ur := newAbortingReader(t, ctx, fp, buildEagerRange(ctx, t, n, segSize), 1, padAll, 0.5)
+	_, err := io.ReadAll(ur)
+	assertAbortedCorruption(t, err)
+	if calls := fp.PerMessageCalls(segments.MessageID(10)); calls != 0 {
+		t.Errorf("segment 10 fetched %d times after the abort", calls)
+	}
+}
+
+func TestMissingSegmentAbort_WaitsForMinSample(t *testing.T) {
+	ctx := context.Background()
+	const n, segSize = 10, 4
+	fp := fillFakePool(n, segSize)
+	fp.SetBehavior(segments.MessageID(0), fakepool.SegmentBehavior{Err: nntppool.ErrArticleNotFound})
+
+	// One miss out of one fetch is over any fraction but too small a sample.
+	ur := newAbortingReader(t, ctx, fp, buildEagerRange(ctx, t, n, segSize), 1, padAll, 0.5)
+	got, err := io.ReadAll(ur)
+	if err != nil {
+		t.Fatalf("ReadAll: %v", err)
+	}
+	if len(got) != n*segSize {
+		t.Fatalf("read %d bytes, want %d", len(got), n*segSize)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/javi11/altmount/internal/pool"
 	"github.com/javi11/altmount/internal/testsupport/fakepool"
 	"github.com/javi11/altmount/internal/testsupport/segments"
 )
@@ -105,3 +106,106 @@ func TestPrefetch_DoesNotExceedMaxPrefetchOnSlowPool(t *testing.T) {
 	}
 	fakepool.AssertMaxInFlightLE(t, fp, int32(maxPrefetch))
 }
+
+// TestPrefetch_MaxInFlightBytesCapsLargeSegments pins the byte ceiling: with
+// large segments and a deep maxPrefetch, WithMaxInFlightBytes bounds the
+// segments scheduled ahead of the read position (in flight or buffered) to
+// what fits in the cap, no matter how many maxPrefetch would allow.
+//
+// Method: 24 segments of 256 KiB, maxPrefetch=20 and a 1 MiB cap (4
+// segments). The reader consumes one segment at a time; after each step the
+// fetches issued must not run more than 4 segments past the consumed ones.
+func TestPrefetch_MaxInFlightBytesCapsLargeSegments(t *testing.T) {
+	t.Parallel()
+	const (
+		segCount    = 24
+		segSize     = 256 * 1024
+		maxPrefetch = 20
+		maxBytes    = 4 * segSize
+		segLatency  = 5 * time.Millisecond
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	fp := fakepool.New()
+	for i := 0; i < segCount; i++ {
+		fp.SetBehavior(segments.MessageID(i), fakepool.SegmentBehavior{
+			Latency: segLatency,
+			Bytes:   segments.Payload(i, segSize),
+		})
+	}
+
+	rg := buildEagerRange(ctx, t, segCount, segSize)
+	getter := func() (pool.NntpClient, error) { return fp, nil }
+	ur, err := NewUsenetReader(ctx, getter, rg, maxPrefetch, noopMetrics{}, "test-stream", nil,
+		WithMaxInFlightBytes(maxBytes))
+	if err != nil {
+		t.Fatalf("NewUsenetReader: %v", err)
+	}
+	t.Cleanup(func() { _ = ur.Close() })
+	ur.Start()
+
+	// Idle reader: the window fills up to the byte cap and stops there.
+	time.Sleep(100 * time.Millisecond)
+	if got := fp.BodyPriorityCalls(); got != maxBytes/segSize {
+		t.Fatalf("BodyPriorityCalls with idle reader = %d, want %d", got, maxBytes/segSize)
+	}
+
+	buf := make([]byte, segSize)
+	for consumed := 1; consumed <= segCount; consumed++ {
+		if _, err := io.ReadFull(ur, buf); err != nil {
+			t.Fatalf("ReadFull segment %d: %v", consumed-1, err)
+		}
+		if want := segments.Payload(consumed-1, segSize); string(buf) != string(want) {
+			t.Fatalf("segment %d payload mismatch", consumed-1)
+		}
+		time.Sleep(2 * segLatency)
+		if got, limit := fp.BodyPriorityCalls(), int64(consumed+maxBytes/segSize); got > limit {
+			t.Fatalf("after consuming %d segments BodyPriorityCalls = %d, want <= %d", consumed, got, limit)
+		}
+	}
+
+	fakepool.AssertMaxInFlightLE(t, fp, maxBytes/segSize)
+}
+
+// TestPrefetch_MaxInFlightBytesBelowSegmentSize pins the progress guarantee:
+// a cap smaller than one segment degrades to fetching one segment at a time
+// instead of stalling the reader.
+func TestPrefetch_MaxInFlightBytesBelowSegmentSize(t *testing.T) {
+	t.Parallel()
+	const (
+		segCount = 8
+		segSize  = 64 * 1024
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	fp := fakepool.New()
+	for i := 0; i < segCount; i++ {
+		fp.SetBehavior(segments.MessageID(i), fakepool.SegmentBehavior{
+			Latency: 5 * time.Millisecond,
+			Bytes:   segments.Payload(i, segSize),
+		})
+	}
+
+	rg := buildEagerRange(ctx, t, segCount, segSize)
+	getter := func() (pool.NntpClient, error) { return fp, nil }
+	ur, err := NewUsenetReader(ctx, getter, rg, 10, noopMetrics{}, "test-stream", nil,
+		WithMaxInFlightBytes(segSize/2))
+	if err != nil {
+		t.Fatalf("NewUsenetReader: %v", err)
+	}
+	t.Cleanup(func() { _ = ur.Close() })
+	ur.Start()
+
+	data, err := io.ReadAll(ur)
+	if err != nil {
+		t.Fatalf("ReadAll: %v", err)
+	}
+	if len(data) != segCount*segSize {
+		t.Fatalf("read %d bytes, want %d", len(data), segCount*segSize)
+	}
+	fakepool.AssertMaxInFlightLE(t, fp, 1)
+}
@@ -0,0 +1,93 @@
+package usenet
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/javi11/altmount/internal/testsupport/fakepool"
+	"github.com/javi11/altmount/internal/testsupport/segments"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func slowPool(segCount, segSize int, latency time.Duration) *fakepool.Client {
+	fp := fakepool.New()
+	for i := 0; i < segCount; i++ {
+		fp.SetBehavior(segments.MessageID(i), fakepool.SegmentBehavior{
+			Latency: latency,
+			Bytes:   segments.Payload(i, segSize),
+		})
+	}
+	return fp
+}
+
+func TestPrime_WaitsForMinimumBytes(t *testing.T) {
+	t.Parallel()
+	const (
+		segCount = 10
+		segSize  = 64
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	rg := buildEagerRange(ctx, t, segCount, segSize)
+	ur := newReaderForTest(t, ctx, slowPool(segCount, segSize, 20*time.Millisecond), rg, 8)
+
+	primed, err := ur.Prime(ctx, 3*segSize-10)
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, primed, int64(3*segSize-10))
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, segSize, rg.segments[i].DataLen(), "segment %d buffered", i)
+	}
+
+	// Reads after priming return the full stream unchanged.
+	data, err := io.ReadAll(ur)
+	require.NoError(t, err)
+	var want []byte
+	for i := 0; i < segCount; i++ {
+		want = append(want, segments.Payload(i, segSize)...)
+	}
+	assert.True(t, bytes.Equal(want, data))
+}
+
+func TestPrime_StopsAtMaxPrefetch(t *testing.T) {
+	t.Parallel()
+	const (
+		segCount    = 10
+		segSize     = 64
+		maxPrefetch = 3
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	fp := slowPool(segCount, segSize, 5*time.Millisecond)
+	rg := buildEagerRange(ctx, t, segCount, segSize)
+	ur := newReaderForTest(t, ctx, fp, rg, maxPrefetch)
+
+	primed, err := ur.Prime(ctx, segCount*segSize)
+	require.NoError(t, err)
+	assert.Equal(t, int64(maxPrefetch*segSize), primed)
+	fakepool.AssertMaxInFlightLE(t, fp, int32(maxPrefetch))
+}
+
+func TestPrime_HonorsContextCancellation(t *testing.T) {
+	t.Parallel()
+	const segSize = 64
+
+	rg := buildEagerRange(context.Background(), t, 4, segSize)
+	ur := newReaderForTest(t, context.Background(), slowPool(4, segSize, 10*time.Second), rg, 4)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	primed, err := ur.Prime(ctx, segSize)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Zero(t, primed)
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
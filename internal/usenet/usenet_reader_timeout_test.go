@@ -0,0 +1,99 @@
+package usenet
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/javi11/altmount/internal/pool"
+	"github.com/javi11/altmount/internal/testsupport/fakepool"
+	"github.com/javi11/altmount/internal/testsupport/segments"
+	"github.com/javi11/nntppool/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stallFirstAttemptClient hangs the first fetch of one message ID until the
+// caller's deadline fires — a provider sitting on a slow article. Later
+// attempts (the failover) are served by the wrapped fake pool.
+type stallFirstAttemptClient struct {
+	*fakepool.Client
+	stallID string
+	stalled atomic.Bool
+}
+
+func (c *stallFirstAttemptClient) BodyPriority(ctx context.Context, messageID string, onMeta ...func(nntppool.YEncMeta)) (*nntppool.ArticleBody, error) {
+	if messageID == c.stallID && c.stalled.CompareAndSwap(false, true) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	return c.Client.BodyPriority(ctx, messageID, onMeta...)
+}
+
+// TestSegmentFetchTimeout_SlowSegmentFailsOver pins the per-segment deadline:
+// a segment whose first fetch exceeds WithSegmentFetchTimeout is retried on
+// its own while the rest of the range is fetched normally, and the read
+// completes well before the overall read timeout.
+func TestSegmentFetchTimeout_SlowSegmentFailsOver(t *testing.T) {
+	t.Parallel()
+	const (
+		segCount    = 6
+		segSize     = 16
+		maxPrefetch = 6
+		slowIdx     = 2
+	)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	fp := fakepool.New()
+	var want bytes.Buffer
+	for i := range segCount {
+		payload := segments.Payload(i, segSize)
+		want.Write(payload)
+		fp.SetBehavior(segments.MessageID(i), fakepool.SegmentBehavior{Bytes: payload})
+	}
+	cp := &stallFirstAttemptClient{Client: fp, stallID: segments.MessageID(slowIdx)}
+
+	rg := buildEagerRange(ctx, t, segCount, segSize)
+	getter := func() (pool.NntpClient, error) { return cp, nil }
+	ur, err := NewUsenetReader(ctx, getter, rg, maxPrefetch, noopMetrics{}, "test-stream", nil,
+		WithSegmentFetchTimeout(100*time.Millisecond))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = ur.Close() })
+	ur.Start()
+
+	start := time.Now()
+	got, err := io.ReadAll(ur)
+	require.NoError(t, err)
+	assert.Equal(t, want.Bytes(), got)
+	assert.Less(t, time.Since(start), 2*time.Second, "slow segment must not hold the range for the default timeout")
+
+	assert.True(t, cp.stalled.Load())
+	assert.Equal(t, int64(1), fp.PerMessageCalls(segments.MessageID(slowIdx)), "the retry is the only call reaching the fake pool")
+	for i := range segCount {
+		if i != slowIdx {
+			assert.Equal(t, int64(1), fp.PerMessageCalls(segments.MessageID(i)), "segment %d fetched once", i)
+		}
+	}
+}
+
+func TestWithSegmentFetchTimeout_NonPositiveKeepsDefault(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	fp := fakepool.New()
+	rg := buildEagerRange(ctx, t, 1, 16)
+	getter := func() (pool.NntpClient, error) { return fp, nil }
+
+	ur, err := NewUsenetReader(ctx, getter, rg, 1, noopMetrics{}, "test-stream", nil, WithSegmentFetchTimeout(0))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = ur.Close() })
+	assert.Equal(t, defaultSegmentFetchTimeout, ur.segmentFetchTimeout)
+
+	ur2, err := NewUsenetReader(ctx, getter, rg, 1, noopMetrics{}, "test-stream", nil, WithSegmentFetchTimeout(3*time.Second))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = ur2.Close() })
+	assert.Equal(t, 3*time.Second, ur2.segmentFetchTimeout)
+}
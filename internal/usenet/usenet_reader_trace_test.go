@@ -0,0 +1,93 @@
+package usenet
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/javi11/altmount/internal/pool"
+	"github.com/javi11/altmount/internal/testsupport/fakepool"
+	"github.com/javi11/altmount/internal/testsupport/segments"
+	"github.com/javi11/nntppool/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// traceRecorder collects the traces of a reader built with WithSegmentTrace.
+type traceRecorder struct {
+	mu     sync.Mutex
+	traces []SegmentTrace
+}
+
+func (r *traceRecorder) record(tr SegmentTrace) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.traces = append(r.traces, tr)
+}
+
+func (r *traceRecorder) all() []SegmentTrace {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]SegmentTrace(nil), r.traces...)
+}
+
+// mapStore is an in-memory SegmentStore.
+type mapStore map[string][]byte
+
+func (m mapStore) Get(id string) ([]byte, bool) { d, ok := m[id]; return d, ok }
+func (m mapStore) Put(string, []byte) error     { return nil }
+
+func TestSegmentTrace_TimingsCacheAndFailure(t *testing.T) {
+	const segSize = 64
+	ctx := context.Background()
+	fp := fakepool.New()
+	fp.SetBehavior(segments.MessageID(1), fakepool.SegmentBehavior{
+		Bytes:   bytes.Repeat([]byte{1}, segSize),
+		Latency: 5 * time.Millisecond,
+	})
+	fp.SetBehavior(segments.MessageID(2), fakepool.SegmentBehavior{Err: nntppool.ErrArticleNotFound})
+	store := mapStore{segments.MessageID(0): bytes.Repeat([]byte{0}, segSize)}
+
+	rec := &traceRecorder{}
+	getter := func() (pool.NntpClient, error) { return fp, nil }
+	ur, err := NewUsenetReader(ctx, getter, buildEagerRange(ctx, t, 3, segSize), 1, noopMetrics{}, "test-stream", store,
+		WithSegmentTrace(rec.record))
+	require.NoError(t, err)
+
+	n, err := io.Copy(io.Discard, ur)
+	require.ErrorIs(t, err, nntppool.ErrArticleNotFound)
+	assert.Equal(t, int64(2*segSize), n)
+	require.NoError(t, ur.Close())
+
+	traces := rec.all()
+	require.Len(t, traces, 3)
+
+	assert.Equal(t, 0, traces[0].Index)
+	assert.True(t, traces[0].CacheHit)
+	assert.Zero(t, traces[0].Attempts, "a cache hit makes no fetch")
+	assert.Equal(t, segSize, traces[0].Bytes)
+
+	assert.Equal(t, 1, traces[1].Index)
+	assert.False(t, traces[1].CacheHit)
+	assert.Equal(t, 1, traces[1].Attempts)
+	assert.Equal(t, segSize, traces[1].Bytes)
+	assert.GreaterOrEqual(t, traces[1].Duration, 5*time.Millisecond)
+	assert.NoError(t, traces[1].Err)
+
+	assert.Equal(t, 2, traces[2].Index)
+	assert.Equal(t, segments.MessageID(2), traces[2].MessageID)
+	assert.True(t, errors.Is(traces[2].Err, nntppool.ErrArticleNotFound))
+	assert.Equal(t, 1, traces[2].Attempts, "missing articles are not retried")
+}
+
+func TestBusiestProvider(t *testing.T) {
+	before := map[string]int64{"a": 100, "b": 500}
+	assert.Equal(t, "b", busiestProvider(before, map[string]int64{"a": 150, "b": 900}))
+	assert.Equal(t, "c", busiestProvider(before, map[string]int64{"a": 100, "b": 500, "c": 10}),
+		"a provider added during the fetch counts from zero")
+	assert.Empty(t, busiestProvider(before, before))
+}
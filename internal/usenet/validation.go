@@ -89,7 +89,7 @@ func ValidateSegmentAvailabilityDetailed(
 	for i, seg := range segments {
 		indexByID[seg.Id] = i
 		fileOffsets[i] = pos
-		pos += seg.EndOffset - seg.StartOffset + 1
+		pos += seg.UsableLen()
 	}
 
 	statCtx, cancel := context.WithTimeout(ctx, pool.StatManyTimeout(len(ids), maxConnections, timeout))
@@ -111,7 +111,7 @@ func ValidateSegmentAvailabilityDetailed(
 						Index: idx,
 						ID:    r.MessageID,
 						Start: fileOffsets[idx],
-						End:   fileOffsets[idx] + (seg.EndOffset - seg.StartOffset),
+						End:   fileOffsets[idx] + seg.UsableLen() - 1,
 					})
 				}
 			}
@@ -0,0 +1,29 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+)
+
+// RunWithContext runs fn and returns its error, or ctx's error as soon as ctx
+// is done, whichever comes first. Blocking filesystem calls can't be
+// interrupted, so an abandoned fn keeps running in the background and its
+// result is discarded; callers must tolerate it completing late.
+func RunWithContext(ctx context.Context, fn func() error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if ctx.Done() == nil {
+		return fn()
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- fn() }()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return fmt.Errorf("abandoned blocked filesystem operation: %w", ctx.Err())
+	}
+}
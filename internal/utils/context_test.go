@@ -0,0 +1,36 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRunWithContext(t *testing.T) {
+	want := errors.New("boom")
+	if err := RunWithContext(context.Background(), func() error { return want }); err != want {
+		t.Fatalf("RunWithContext() = %v, want fn's error", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	release := make(chan struct{})
+	defer close(release)
+
+	start := time.Now()
+	err := RunWithContext(ctx, func() error { <-release; return nil })
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("RunWithContext() = %v, want context.DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Fatalf("RunWithContext blocked for %v after the deadline", elapsed)
+	}
+
+	called := false
+	cancelled, cancelNow := context.WithCancel(context.Background())
+	cancelNow()
+	if err := RunWithContext(cancelled, func() error { called = true; return nil }); !errors.Is(err, context.Canceled) || called {
+		t.Fatalf("RunWithContext on a done context = %v (called=%v), want context.Canceled without calling fn", err, called)
+	}
+}
@@ -19,6 +19,21 @@ func MoveFile(src, dst string) error {
 	}
 
 	// Fallback: Copy-and-delete for cross-device moves
+	if err := CopyFile(src, dst); err != nil {
+		return err
+	}
+
+	// Remove source file
+	if err := os.Remove(src); err != nil {
+		return fmt.Errorf("failed to remove source file after successful copy: %w", err)
+	}
+
+	return nil
+}
+
+// CopyFile copies src to dst with src's permissions, replacing dst. The copy
+// is synced before returning; a partial dst is removed on failure.
+func CopyFile(src, dst string) error {
 	srcInfo, err := os.Stat(src)
 	if err != nil {
 		return fmt.Errorf("failed to stat source file: %w", err)
@@ -51,17 +66,12 @@ func MoveFile(src, dst string) error {
 		return fmt.Errorf("failed to sync destination file: %w", err)
 	}
 
-	// Close both files before attempting removal
+	// Close both files before returning so the caller may remove src
 	srcFile.Close()
 	if err := dstFile.Close(); err != nil {
 		return fmt.Errorf("failed to close destination file: %w", err)
 	}
 
-	// Remove source file
-	if err := os.Remove(src); err != nil {
-		return fmt.Errorf("failed to remove source file after successful copy: %w", err)
-	}
-
 	return nil
 }
 
@@ -5,6 +5,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 )
 
 // CheckDirectoryWritable checks if a directory exists and is writable.
@@ -88,6 +89,33 @@ func RemoveEmptyDirs(root, path string) {
 	RemoveEmptyDirs(root, parent)
 }
 
+// RemoveQuietEmptyDirs is RemoveEmptyDirs for directories that may be in use:
+// a directory modified after cutoff is left in place and returned, so the
+// caller can retry it later. A parent's age is judged from before its child
+// was removed, so the removal itself doesn't hold the parent back. Returns ""
+// once the walk stops at a non-empty directory, a missing one or root.
+func RemoveQuietEmptyDirs(root, path string, cutoff time.Time) string {
+	if root == "" || path == "" {
+		return ""
+	}
+	root = filepath.Clean(root)
+	path = filepath.Clean(path)
+
+	info, err := os.Stat(path)
+	for err == nil && path != root && strings.HasPrefix(path, root) {
+		if info.ModTime().After(cutoff) {
+			return path
+		}
+		parent := filepath.Dir(path)
+		parentInfo, parentErr := os.Stat(parent)
+		if os.Remove(path) != nil {
+			return "" // not empty, or we lack permissions
+		}
+		path, info, err = parent, parentInfo, parentErr
+	}
+	return ""
+}
+
 // JoinAbsPath safely joins a base path with another path (which could be absolute or relative).
 // If the second path is absolute and starts with the base path, it returns the second path as is.
 // Otherwise, it joins them normally.
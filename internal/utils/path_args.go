@@ -22,4 +22,5 @@ const (
 	UserAgentKey              = contextKey("userAgent")
 	MaxPrefetchKey            = contextKey("maxPrefetch")
 	SuppressStreamTrackingKey = contextKey("suppressStreamTracking")
+	StartSegmentKey           = contextKey("startSegment")
 )
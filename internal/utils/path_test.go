@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 )
 
 func TestRemoveEmptyDirs(t *testing.T) {
@@ -75,3 +76,38 @@ func TestRemoveEmptyDirs(t *testing.T) {
 		t.Error("Expected keep.txt to still exist")
 	}
 }
+
+func TestRemoveQuietEmptyDirs(t *testing.T) {
+	root := t.TempDir()
+	nested := filepath.Join(root, "a", "b")
+	if err := os.MkdirAll(nested, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	// Freshly created: inside the grace window.
+	now := time.Now()
+	if busy := RemoveQuietEmptyDirs(root, nested, now.Add(-time.Minute)); busy != nested {
+		t.Errorf("Expected %s to be reported busy, got %q", nested, busy)
+	}
+	if _, err := os.Stat(nested); err != nil {
+		t.Error("Expected a recently modified directory to be kept")
+	}
+
+	// Once quiet, the directory and its empty parents go, even though
+	// removing the child updates the parent's modification time.
+	old := now.Add(-time.Hour)
+	for _, dir := range []string{nested, filepath.Dir(nested)} {
+		if err := os.Chtimes(dir, old, old); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if busy := RemoveQuietEmptyDirs(root, nested, now.Add(-time.Minute)); busy != "" {
+		t.Errorf("Expected no busy directory, got %q", busy)
+	}
+	if _, err := os.Stat(filepath.Join(root, "a")); err == nil {
+		t.Error("Expected the empty parents to be removed")
+	}
+	if _, err := os.Stat(root); err != nil {
+		t.Error("Expected root directory to exist")
+	}
+}
@@ -0,0 +1,41 @@
+package utils
+
+import (
+	"io"
+	"io/fs"
+	"net/http"
+)
+
+// ServeUnknownLength writes content to w until EOF without a Content-Length,
+// for files whose size isn't known up front. The header is flushed before the
+// body so HTTP/1.1 clients always get chunked transfer encoding, even for a
+// body small enough that net/http would otherwise buffer it and add a length.
+// Ranges can't be resolved against an unknown end, so Range requests get the
+// full body and Accept-Ranges says so. HEAD requests get headers only.
+func ServeUnknownLength(w http.ResponseWriter, r *http.Request, content io.Reader) error {
+	h := w.Header()
+	h.Del("Content-Length")
+	h.Set("Accept-Ranges", "none")
+	if h.Get("Content-Type") == "" {
+		h.Set("Content-Type", "application/octet-stream")
+	}
+	w.WriteHeader(http.StatusOK)
+
+	if r.Method == http.MethodHead {
+		return nil
+	}
+	if f, ok := w.(http.Flusher); ok {
+		f.Flush()
+	}
+
+	_, err := io.Copy(w, content)
+	return err
+}
+
+// SizeUnknown reports whether a file's info marks its size as uncertain, in
+// which case Size is only an estimate and the file should be served with
+// ServeUnknownLength.
+func SizeUnknown(info fs.FileInfo) bool {
+	u, ok := info.(interface{ SizeUnknown() bool })
+	return ok && u.SizeUnknown()
+}
@@ -9,6 +9,7 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 
 	"github.com/go-pkgz/auth/v2/token"
@@ -72,7 +73,19 @@ func (h *webdavMethods) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	case http.MethodHead, http.MethodGet:
 		h.handleGet(w, r)
 	case "PROPFIND":
-		tracker := &headerTracker{ResponseWriter: w}
+		// Listings can be large multistatus XML documents; compress them when the
+		// client allows it. GET/HEAD media streams are never compressed.
+		out := w
+		if propfind.AcceptsGzip(r) {
+			gz := propfind.NewGzipResponseWriter(w)
+			defer func() {
+				if err := gz.Close(); err != nil {
+					slog.DebugContext(r.Context(), "Failed to finish gzip PROPFIND response", "err", err)
+				}
+			}()
+			out = gz
+		}
+		tracker := &headerTracker{ResponseWriter: out}
 		status, err := propfind.HandlePropfind(propfindFS{h.fs}, tracker, r, h.prefix)
 		if status != 0 {
 			if tracker.written {
@@ -148,9 +161,53 @@ func (h *webdavMethods) handleGet(w http.ResponseWriter, r *http.Request) {
 	}
 	defer f.Close()
 
+	// With an ETag set, ServeContent honors If-Range: a resumed download
+	// whose validator no longer matches gets the full file instead of a
+	// range spliced from different content.
+	if etag := fileETag(f); etag != "" {
+		w.Header().Set("ETag", etag)
+	}
+	// Replace the extension-based guess made before routing with the type the
+	// file system resolved (overrides, sniffing).
+	if ctype := fileContentType(f); ctype != "" {
+		w.Header().Set("Content-Type", ctype)
+	}
+
+	// A file whose size is only an estimate is streamed until EOF rather
+	// than promised at a Content-Length it might not meet.
+	if opened, err := f.Stat(); err == nil && utils.SizeUnknown(opened) {
+		if err := utils.ServeUnknownLength(w, r, f); err != nil {
+			slog.DebugContext(ctx, "WebDAV GET unknown-length stream ended", "path", reqPath, "error", err)
+		}
+		return
+	}
+
 	http.ServeContent(w, r, fi.Name(), fi.ModTime(), f)
 }
 
+// fileETag returns the entity tag of an opened file, or "" when it cannot be
+// stat'ed. It matches the getetag PROPFIND reports for the same file.
+func fileETag(f File) string {
+	fi, err := f.Stat()
+	if err != nil {
+		return ""
+	}
+	return propfind.ETag(fi)
+}
+
+// fileContentType returns the Content-Type of an opened file, or "" when the
+// file system does not provide one.
+func fileContentType(f File) string {
+	fi, err := f.Stat()
+	if err != nil {
+		return ""
+	}
+	if ct, ok := fi.(interface{ ContentType() string }); ok {
+		return ct.ContentType()
+	}
+	return ""
+}
+
 func (h *webdavMethods) handleDelete(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	reqPath, status, err := propfind.StripPrefix(r.URL.Path, h.prefix)
@@ -355,6 +412,9 @@ func NewHandler(
 		ctx = context.WithValue(ctx, utils.IsCopy, r.Method == "COPY")
 		ctx = context.WithValue(ctx, utils.Origin, r.RequestURI)
 		ctx = context.WithValue(ctx, utils.ShowCorrupted, r.Header.Get("X-Show-Corrupted") == "true")
+		if seg, err := strconv.Atoi(r.Header.Get("X-Start-Segment")); err == nil {
+			ctx = context.WithValue(ctx, utils.StartSegmentKey, seg)
+		}
 		ctx = context.WithValue(ctx, utils.ClientIPKey, r.RemoteAddr)
 		ctx = context.WithValue(ctx, utils.UserAgentKey, r.UserAgent())
 		r = r.WithContext(ctx)
@@ -0,0 +1,241 @@
+package webdav
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/javi11/altmount/internal/webdav/propfind"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memFileInfo struct {
+	name        string
+	size        int64
+	dir         bool
+	sizeUnknown bool
+}
+
+func (fi memFileInfo) Name() string       { return fi.name }
+func (fi memFileInfo) Size() int64        { return fi.size }
+func (fi memFileInfo) ModTime() time.Time { return time.Unix(1700000000, 0) }
+func (fi memFileInfo) IsDir() bool        { return fi.dir }
+func (fi memFileInfo) Sys() any           { return nil }
+func (fi memFileInfo) SizeUnknown() bool  { return fi.sizeUnknown }
+func (fi memFileInfo) Mode() os.FileMode {
+	if fi.dir {
+		return os.ModeDir | 0555
+	}
+	return 0444
+}
+
+type memFile struct {
+	*bytes.Reader
+	info    memFileInfo
+	entries []os.FileInfo
+}
+
+func (f *memFile) Close() error                       { return nil }
+func (f *memFile) Write([]byte) (int, error)          { return 0, os.ErrPermission }
+func (f *memFile) Readdir(int) ([]os.FileInfo, error) { return f.entries, nil }
+func (f *memFile) Stat() (os.FileInfo, error)         { return f.info, nil }
+
+// memFS is a read-only FileSystem with one directory holding media files.
+type memFS struct {
+	files map[string][]byte
+	// unknownSize names files whose opened handles report an estimated size.
+	unknownSize map[string]bool
+}
+
+func (m *memFS) Mkdir(context.Context, string, os.FileMode) error { return os.ErrPermission }
+func (m *memFS) RemoveAll(context.Context, string) error          { return os.ErrPermission }
+func (m *memFS) Rename(context.Context, string, string) error     { return os.ErrPermission }
+
+func (m *memFS) Stat(_ context.Context, name string) (os.FileInfo, error) {
+	name = strings.Trim(name, "/")
+	if name == "" {
+		return memFileInfo{name: "/", dir: true}, nil
+	}
+	data, ok := m.files[name]
+	if !ok {
+		return nil, os.ErrNotExist
+	}
+	return memFileInfo{name: path.Base(name), size: int64(len(data))}, nil
+}
+
+func (m *memFS) OpenFile(ctx context.Context, name string, _ int, _ os.FileMode) (File, error) {
+	info, err := m.Stat(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	f := &memFile{info: info.(memFileInfo)}
+	f.info.sizeUnknown = m.unknownSize[strings.Trim(name, "/")]
+	if info.IsDir() {
+		f.Reader = bytes.NewReader(nil)
+		for n, data := range m.files {
+			f.entries = append(f.entries, memFileInfo{name: n, size: int64(len(data))})
+		}
+		return f, nil
+	}
+	f.Reader = bytes.NewReader(m.files[strings.Trim(name, "/")])
+	return f, nil
+}
+
+func newTestMethods() *webdavMethods {
+	files := map[string][]byte{}
+	for i := range 50 {
+		files["movie-"+strings.Repeat("x", i)+".mkv"] = bytes.Repeat([]byte{byte(i)}, 4096)
+	}
+	return &webdavMethods{fs: &memFS{files: files}}
+}
+
+func TestPropfind_GzipWhenRequested(t *testing.T) {
+	h := newTestMethods()
+
+	req := httptest.NewRequest("PROPFIND", "/", nil)
+	req.Header.Set("Depth", "1")
+	req.Header.Set("Accept-Encoding", "gzip")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusMultiStatus, rec.Code)
+	assert.Equal(t, "gzip", rec.Header().Get("Content-Encoding"))
+
+	zr, err := gzip.NewReader(rec.Body)
+	require.NoError(t, err)
+	body, err := io.ReadAll(zr)
+	require.NoError(t, err)
+	assert.Contains(t, string(body), "multistatus")
+	assert.Contains(t, string(body), "movie-.mkv")
+
+	// Without Accept-Encoding the listing is sent as plain XML.
+	req = httptest.NewRequest("PROPFIND", "/", nil)
+	req.Header.Set("Depth", "1")
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusMultiStatus, rec.Code)
+	assert.Empty(t, rec.Header().Get("Content-Encoding"))
+	assert.Contains(t, rec.Body.String(), "multistatus")
+}
+
+func TestPropfind_GzipErrorIsPlain(t *testing.T) {
+	h := newTestMethods()
+
+	req := httptest.NewRequest("PROPFIND", "/missing.mkv", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Empty(t, rec.Header().Get("Content-Encoding"))
+	assert.Equal(t, http.StatusText(http.StatusNotFound), rec.Body.String())
+}
+
+func TestGet_MediaNeverCompressed(t *testing.T) {
+	h := newTestMethods()
+
+	req := httptest.NewRequest(http.MethodGet, "/movie-.mkv", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	req.Header.Set("Range", "bytes=0-99")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusPartialContent, rec.Code)
+	assert.Empty(t, rec.Header().Get("Content-Encoding"))
+	assert.Equal(t, 100, rec.Body.Len())
+}
+
+func TestGet_IfRange(t *testing.T) {
+	h := newTestMethods()
+	fi, err := h.fs.Stat(context.Background(), "movie-.mkv")
+	require.NoError(t, err)
+	// The same tag PROPFIND reports as getetag for the file.
+	etag := propfind.ETag(fi)
+
+	get := func(ifRange string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/movie-.mkv", nil)
+		req.Header.Set("Range", "bytes=100-199")
+		if ifRange != "" {
+			req.Header.Set("If-Range", ifRange)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("matching validator serves the range", func(t *testing.T) {
+		rec := get(etag)
+		assert.Equal(t, http.StatusPartialContent, rec.Code)
+		assert.Equal(t, etag, rec.Header().Get("ETag"))
+		assert.Equal(t, 100, rec.Body.Len())
+	})
+
+	t.Run("stale validator serves the full file", func(t *testing.T) {
+		rec := get(`"5f5e100-1000"`)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, etag, rec.Header().Get("ETag"))
+		assert.Equal(t, 4096, rec.Body.Len())
+	})
+
+	t.Run("weak validator never matches", func(t *testing.T) {
+		rec := get("W/" + etag)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, 4096, rec.Body.Len())
+	})
+
+	t.Run("no validator serves the range", func(t *testing.T) {
+		rec := get("")
+		assert.Equal(t, http.StatusPartialContent, rec.Code)
+		assert.Equal(t, 100, rec.Body.Len())
+	})
+}
+
+func TestGet_UnknownLengthIsChunked(t *testing.T) {
+	h := newTestMethods()
+	h.fs.(*memFS).unknownSize = map[string]bool{"movie-.mkv": true}
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+
+	get := func(rangeHeader string) (*http.Response, []byte) {
+		req, err := http.NewRequest(http.MethodGet, srv.URL+"/movie-.mkv", nil)
+		require.NoError(t, err)
+		if rangeHeader != "" {
+			req.Header.Set("Range", rangeHeader)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp, body
+	}
+
+	resp, body := get("")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, int64(-1), resp.ContentLength)
+	assert.Empty(t, resp.Header.Get("Content-Length"))
+	assert.Equal(t, []string{"chunked"}, resp.TransferEncoding)
+	assert.Equal(t, bytes.Repeat([]byte{0}, 4096), body, "streamed to EOF")
+
+	// A range can't be cut from an unknown end: the whole file comes back.
+	resp, body = get("bytes=100-199")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "none", resp.Header.Get("Accept-Ranges"))
+	assert.Len(t, body, 4096)
+
+	// Files with a known size keep their Content-Length.
+	h.fs.(*memFS).unknownSize = nil
+	resp, body = get("")
+	assert.Equal(t, int64(4096), resp.ContentLength)
+	assert.Len(t, body, 4096)
+}
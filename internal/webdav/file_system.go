@@ -10,6 +10,7 @@ import (
 	"os"
 
 	"github.com/javi11/altmount/internal/nzbfilesystem"
+	"github.com/javi11/altmount/internal/pool"
 	"github.com/javi11/altmount/internal/slogutil"
 )
 
@@ -103,6 +104,18 @@ func (c *customErrorHandler) mapError(err error) error {
 		}
 	}
 
+	if errors.Is(err, pool.ErrPoolExhausted) {
+		return poolExhaustedError(err)
+	}
+
+	if errors.Is(err, nzbfilesystem.ErrTooManyStreams) {
+		return &HTTPError{
+			StatusCode: http.StatusTooManyRequests,
+			Message:    "Too many concurrent streams from this client",
+			Err:        err,
+		}
+	}
+
 	return err
 }
 
@@ -129,6 +142,11 @@ func (f *errorHandlingFile) Read(p []byte) (int, error) {
 			}
 		}
 
+		if errors.Is(err, pool.ErrPoolExhausted) {
+			slog.WarnContext(f.ctx, "No NNTP connection available for read", "error", err)
+			return n, poolExhaustedError(err)
+		}
+
 		if errors.As(err, &corruptedErr) {
 			slog.ErrorContext(f.ctx, "File corrupted",
 				"total_expected", corruptedErr.TotalExpected,
@@ -143,3 +161,13 @@ func (f *errorHandlingFile) Read(p []byte) (int, error) {
 
 	return n, err
 }
+
+// poolExhaustedError reports a read that gave up waiting for an NNTP
+// connection. The file itself is fine, so clients should simply retry.
+func poolExhaustedError(err error) error {
+	return &HTTPError{
+		StatusCode: http.StatusServiceUnavailable,
+		Message:    "No usenet connection available, try again later",
+		Err:        err,
+	}
+}
@@ -0,0 +1,59 @@
+package propfind
+
+import (
+	"fmt"
+	"hash/fnv"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// listingETag returns a weak entity tag for a Depth: 1 PROPFIND of dir whose
+// entries are children. It hashes the name, size, modification time and kind
+// of the directory and every child, plus the properties the request asked
+// for, so it changes whenever the multistatus body would. Clients send it
+// back in If-None-Match to skip re-reading an unchanged folder.
+//
+// Deeper listings get no tag: a change further down the tree would not show
+// in the immediate children.
+func listingETag(dir os.FileInfo, children []os.FileInfo, pf propfind) string {
+	h := fnv.New64a()
+	writeEntry := func(fi os.FileInfo) {
+		fmt.Fprintf(h, "%s\x00%d\x00%d\x00%t\x00", fi.Name(), fi.Size(), fi.ModTime().UnixNano(), fi.IsDir())
+	}
+
+	writeEntry(dir)
+	for _, fi := range children {
+		writeEntry(fi)
+	}
+	writeRequest(h, pf)
+
+	return fmt.Sprintf(`W/"%x-%x"`, len(children), h.Sum64())
+}
+
+// writeRequest adds which properties pf asks for to the listing hash.
+func writeRequest(w io.Writer, pf propfind) {
+	fmt.Fprintf(w, "allprop=%t;propname=%t;", pf.Allprop != nil, pf.Propname != nil)
+	for _, n := range pf.Prop {
+		fmt.Fprintf(w, "prop=%s %s;", n.Space, n.Local)
+	}
+	for _, n := range pf.Include {
+		fmt.Fprintf(w, "include=%s %s;", n.Space, n.Local)
+	}
+}
+
+// etagMatches reports whether the If-None-Match header of r matches etag,
+// using the weak comparison RFC 9110 prescribes for If-None-Match.
+func etagMatches(r *http.Request, etag string) bool {
+	want := strings.TrimPrefix(etag, "W/")
+	for _, header := range r.Header.Values("If-None-Match") {
+		for candidate := range strings.SplitSeq(header, ",") {
+			candidate = strings.TrimSpace(candidate)
+			if candidate == "*" || strings.TrimPrefix(candidate, "W/") == want {
+				return true
+			}
+		}
+	}
+	return false
+}
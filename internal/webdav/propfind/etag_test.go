@@ -0,0 +1,125 @@
+package propfind
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memInfo struct {
+	name  string
+	size  int64
+	mtime time.Time
+	dir   bool
+}
+
+func (m memInfo) Name() string       { return m.name }
+func (m memInfo) Size() int64        { return m.size }
+func (m memInfo) ModTime() time.Time { return m.mtime }
+func (m memInfo) IsDir() bool        { return m.dir }
+func (m memInfo) Sys() any           { return nil }
+func (m memInfo) Mode() os.FileMode {
+	if m.dir {
+		return os.ModeDir | 0755
+	}
+	return 0644
+}
+
+// memFS is a one-level tree: directories map to their entries.
+type memFS map[string][]os.FileInfo
+
+type memDir []os.FileInfo
+
+func (memDir) Close() error                         { return nil }
+func (d memDir) Readdir(int) ([]os.FileInfo, error) { return d, nil }
+
+func (fs memFS) Stat(_ context.Context, name string) (os.FileInfo, error) {
+	if _, ok := fs[name]; ok {
+		return memInfo{name: path.Base(name), dir: true, mtime: time.Unix(1_700_000_000, 0)}, nil
+	}
+	for _, fi := range fs[path.Dir(name)] {
+		if fi.Name() == path.Base(name) {
+			return fi, nil
+		}
+	}
+	return nil, os.ErrNotExist
+}
+
+func (fs memFS) OpenFile(_ context.Context, name string, _ int, _ os.FileMode) (FSFile, error) {
+	entries, ok := fs[name]
+	if !ok {
+		return nil, os.ErrNotExist
+	}
+	return memDir(entries), nil
+}
+
+func newMovieFS() memFS {
+	mtime := time.Unix(1_700_000_000, 0)
+	return memFS{
+		"/movies": {
+			memInfo{name: "A.mkv", size: 100, mtime: mtime},
+			memInfo{name: "B.mkv", size: 200, mtime: mtime},
+		},
+	}
+}
+
+func propfindListing(t *testing.T, fs FS, depth, ifNoneMatch string) *httptest.ResponseRecorder {
+	t.Helper()
+	r := httptest.NewRequest("PROPFIND", "/movies", strings.NewReader(""))
+	r.Header.Set("Depth", depth)
+	if ifNoneMatch != "" {
+		r.Header.Set("If-None-Match", ifNoneMatch)
+	}
+	rec := httptest.NewRecorder()
+	status, err := HandlePropfind(fs, rec, r, "")
+	require.NoError(t, err)
+	require.Zero(t, status)
+	return rec
+}
+
+func TestPropfindListingETag_Unchanged(t *testing.T) {
+	fs := newMovieFS()
+
+	first := propfindListing(t, fs, "1", "")
+	etag := first.Header().Get("ETag")
+	require.NotEmpty(t, etag)
+	assert.Equal(t, http.StatusMultiStatus, first.Code)
+	assert.Equal(t, etag, propfindListing(t, fs, "1", "").Header().Get("ETag"))
+
+	cached := propfindListing(t, fs, "1", etag)
+	assert.Equal(t, http.StatusNotModified, cached.Code)
+	assert.Empty(t, cached.Body.String())
+
+	// Weak comparison: the tag matches with or without the W/ prefix.
+	assert.Equal(t, http.StatusNotModified, propfindListing(t, fs, "1", strings.TrimPrefix(etag, "W/")).Code)
+}
+
+func TestPropfindListingETag_Changed(t *testing.T) {
+	fs := newMovieFS()
+	etag := propfindListing(t, fs, "1", "").Header().Get("ETag")
+
+	fs["/movies"][1] = memInfo{name: "B.mkv", size: 201, mtime: time.Unix(1_700_000_000, 0)}
+	resized := propfindListing(t, fs, "1", etag)
+	assert.Equal(t, http.StatusMultiStatus, resized.Code)
+	assert.NotEqual(t, etag, resized.Header().Get("ETag"))
+
+	fs["/movies"] = append(fs["/movies"], memInfo{name: "C.mkv", size: 1, mtime: time.Unix(1_700_000_000, 0)})
+	added := propfindListing(t, fs, "1", resized.Header().Get("ETag"))
+	assert.Equal(t, http.StatusMultiStatus, added.Code)
+	assert.NotEqual(t, resized.Header().Get("ETag"), added.Header().Get("ETag"))
+	assert.Contains(t, added.Body.String(), "C.mkv")
+}
+
+func TestPropfindListingETag_OnlyDepthOne(t *testing.T) {
+	fs := newMovieFS()
+	assert.Empty(t, propfindListing(t, fs, "0", "").Header().Get("ETag"))
+	assert.Empty(t, propfindListing(t, fs, "infinity", "").Header().Get("ETag"))
+}
@@ -0,0 +1,91 @@
+package propfind
+
+import (
+	"compress/gzip"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// AcceptsGzip reports whether the request's Accept-Encoding header allows a
+// gzip-encoded response. Entries with q=0 are treated as refusals.
+func AcceptsGzip(r *http.Request) bool {
+	for _, header := range r.Header.Values("Accept-Encoding") {
+		for part := range strings.SplitSeq(header, ",") {
+			coding, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+			coding = strings.ToLower(strings.TrimSpace(coding))
+			if coding != "gzip" && coding != "x-gzip" {
+				continue
+			}
+			if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+				if v, err := strconv.ParseFloat(q, 64); err == nil && v == 0 {
+					return false
+				}
+			}
+			return true
+		}
+	}
+	return false
+}
+
+// GzipResponseWriter compresses a response body with gzip. It is meant for
+// PROPFIND listings only: multistatus XML compresses very well, while media
+// byte streams do not and must keep working with Range requests.
+//
+// The gzip stream is started lazily on the first body write, so a handler that
+// ends up writing an error straight to the underlying writer leaves no gzip
+// framing behind. Close must be called to flush the trailer.
+type GzipResponseWriter struct {
+	http.ResponseWriter
+	gz          *gzip.Writer
+	wroteHeader bool
+	compress    bool
+}
+
+// NewGzipResponseWriter wraps w so that the response body is gzip-encoded.
+func NewGzipResponseWriter(w http.ResponseWriter) *GzipResponseWriter {
+	return &GzipResponseWriter{ResponseWriter: w}
+}
+
+func (g *GzipResponseWriter) WriteHeader(status int) {
+	if g.wroteHeader {
+		return
+	}
+	g.wroteHeader = true
+
+	h := g.ResponseWriter.Header()
+	h.Add("Vary", "Accept-Encoding")
+	// No body for these statuses, and never double-encode.
+	if status != http.StatusNoContent && status != http.StatusNotModified &&
+		status >= http.StatusOK && h.Get("Content-Encoding") == "" {
+		g.compress = true
+		h.Set("Content-Encoding", "gzip")
+		h.Del("Content-Length")
+	}
+	g.ResponseWriter.WriteHeader(status)
+}
+
+func (g *GzipResponseWriter) Write(b []byte) (int, error) {
+	if !g.wroteHeader {
+		g.WriteHeader(http.StatusOK)
+	}
+	if !g.compress {
+		return g.ResponseWriter.Write(b)
+	}
+	if g.gz == nil {
+		g.gz = gzip.NewWriter(g.ResponseWriter)
+	}
+	return g.gz.Write(b)
+}
+
+// Close flushes any buffered compressed data and writes the gzip trailer. A
+// compressed response with an empty body still gets a valid (empty) gzip stream.
+func (g *GzipResponseWriter) Close() error {
+	if !g.compress {
+		return nil
+	}
+	if g.gz == nil {
+		g.gz = gzip.NewWriter(g.ResponseWriter)
+	}
+	return g.gz.Close()
+}
@@ -0,0 +1,63 @@
+package propfind
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAcceptsGzip(t *testing.T) {
+	cases := map[string]bool{
+		"":                    false,
+		"identity":            false,
+		"gzip":                true,
+		"deflate, gzip;q=0.8": true,
+		"GZIP":                true,
+		"x-gzip":              true,
+		"gzip;q=0":            false,
+		"br, gzip; q=0.0":     false,
+	}
+	for header, want := range cases {
+		r := httptest.NewRequest("PROPFIND", "/", nil)
+		if header != "" {
+			r.Header.Set("Accept-Encoding", header)
+		}
+		assert.Equal(t, want, AcceptsGzip(r), "Accept-Encoding %q", header)
+	}
+}
+
+func TestGzipResponseWriter(t *testing.T) {
+	rec := httptest.NewRecorder()
+	gz := NewGzipResponseWriter(rec)
+	gz.Header().Set("Content-Length", "11")
+	gz.WriteHeader(207)
+	_, err := gz.Write([]byte("hello world"))
+	require.NoError(t, err)
+	require.NoError(t, gz.Close())
+
+	assert.Equal(t, 207, rec.Code)
+	assert.Equal(t, "gzip", rec.Header().Get("Content-Encoding"))
+	assert.Equal(t, "Accept-Encoding", rec.Header().Get("Vary"))
+	assert.Empty(t, rec.Header().Get("Content-Length"))
+
+	zr, err := gzip.NewReader(rec.Body)
+	require.NoError(t, err)
+	body, err := io.ReadAll(zr)
+	require.NoError(t, err)
+	assert.Equal(t, "hello world", string(body))
+}
+
+func TestGzipResponseWriter_NoBodyStatusNotCompressed(t *testing.T) {
+	rec := httptest.NewRecorder()
+	gz := NewGzipResponseWriter(rec)
+	gz.WriteHeader(http.StatusNoContent)
+	require.NoError(t, gz.Close())
+
+	assert.Empty(t, rec.Header().Get("Content-Encoding"))
+	assert.Zero(t, rec.Body.Len())
+}
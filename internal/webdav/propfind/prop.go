@@ -227,6 +227,14 @@ func findLastModified(ctx context.Context, name string, fi os.FileInfo) (string,
 }
 
 func findContentType(ctx context.Context, name string, fi os.FileInfo) (string, error) {
+	// A file system that knows better, e.g. from a configured override,
+	// reports the type through its FileInfo.
+	if ct, ok := fi.(interface{ ContentType() string }); ok {
+		if ctype := ct.ContentType(); ctype != "" {
+			return ctype, nil
+		}
+	}
+
 	// This implementation is based on serveContent's code in the standard net/http package.
 	ctype := mime.TypeByExtension(filepath.Ext(name))
 	if ctype != "" {
@@ -237,10 +245,17 @@ func findContentType(ctx context.Context, name string, fi os.FileInfo) (string,
 }
 
 func findETag(ctx context.Context, name string, fi os.FileInfo) (string, error) {
+	return ETag(fi), nil
+}
+
+// ETag returns the strong entity tag of a file. PROPFIND's getetag and the
+// ETag header on GET both use it, so a client validating a resumed download
+// with If-Range compares against the tag it saw in the listing.
+func ETag(fi os.FileInfo) string {
 	// The Apache http 2.4 web server by default concatenates the
 	// modification time and size of a file. We replicate the heuristic
 	// with nanosecond granularity.
-	return fmt.Sprintf(`"%x%x"`, fi.ModTime().UnixNano(), fi.Size()), nil
+	return fmt.Sprintf(`"%x%x"`, fi.ModTime().UnixNano(), fi.Size())
 }
 
 func findSupportedLock(ctx context.Context, name string, fi os.FileInfo) (string, error) {
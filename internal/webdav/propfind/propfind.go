@@ -54,6 +54,21 @@ func HandlePropfind(fs FS, w http.ResponseWriter, r *http.Request, prefix string
 		return status, err
 	}
 
+	// A Depth: 1 listing carries an ETag over its entries; a client that
+	// already holds it gets 304 instead of the whole multistatus again.
+	var children []os.FileInfo
+	if fi.IsDir() && depth == 1 {
+		children, err = readDir(ctx, fs, reqPath)
+		if err == nil {
+			etag := listingETag(fi, children, pf)
+			w.Header().Set("ETag", etag)
+			if etagMatches(r, etag) {
+				w.WriteHeader(http.StatusNotModified)
+				return 0, nil
+			}
+		}
+	}
+
 	mw := multistatusWriter{w: w}
 
 	walkFn := func(reqPath string, info os.FileInfo, err error) error {
@@ -88,7 +103,12 @@ func HandlePropfind(fs FS, w http.ResponseWriter, r *http.Request, prefix string
 		return mw.write(makePropstatResponse(href, pstats))
 	}
 
-	walkErr := walkFS(ctx, fs, depth, reqPath, fi, walkFn)
+	var walkErr error
+	if children != nil {
+		walkErr = walkListing(ctx, fs, reqPath, fi, children, walkFn)
+	} else {
+		walkErr = walkFS(ctx, fs, depth, reqPath, fi, walkFn)
+	}
 	closeErr := mw.close()
 	if walkErr != nil {
 		return http.StatusInternalServerError, walkErr
@@ -178,20 +198,31 @@ func walkFS(ctx context.Context, fs FS, depth int, name string, info os.FileInfo
 	}
 
 	// Read directory names.
-	f, err := fs.OpenFile(ctx, name, os.O_RDONLY, 0)
+	fileInfos, err := readDir(ctx, fs, name)
 	if err != nil {
 		return walkFn(name, info, err)
 	}
-	fileInfos, err := f.Readdir(0)
-	f.Close()
-	if err != nil {
-		return walkFn(name, info, err)
+	return walkChildren(ctx, fs, depth, name, fileInfos, walkFn)
+}
+
+// walkListing is walkFS at depth 1 for a directory whose entries were already
+// read.
+func walkListing(ctx context.Context, fs FS, name string, info os.FileInfo, children []os.FileInfo, walkFn filepath.WalkFunc) error {
+	if err := walkFn(name, info, nil); err != nil {
+		if err == filepath.SkipDir {
+			return nil
+		}
+		return err
 	}
+	return walkChildren(ctx, fs, 0, name, children, walkFn)
+}
 
+// walkChildren walks each entry of the directory name up to depth levels.
+func walkChildren(ctx context.Context, fs FS, depth int, name string, fileInfos []os.FileInfo, walkFn filepath.WalkFunc) error {
 	for _, fileInfo := range fileInfos {
 		filename := path.Join(name, fileInfo.Name())
 
-		err = walkFS(ctx, fs, depth, filename, fileInfo, walkFn)
+		err := walkFS(ctx, fs, depth, filename, fileInfo, walkFn)
 		if err != nil {
 			if !fileInfo.IsDir() || err != filepath.SkipDir {
 				return err
@@ -201,6 +232,16 @@ func walkFS(ctx context.Context, fs FS, depth int, name string, info os.FileInfo
 	return nil
 }
 
+// readDir returns the entries of the directory name.
+func readDir(ctx context.Context, fs FS, name string) ([]os.FileInfo, error) {
+	f, err := fs.OpenFile(ctx, name, os.O_RDONLY, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return f.Readdir(0)
+}
+
 // StripPrefix strips the prefix from path p.
 func StripPrefix(p, prefix string) (string, int, error) {
 	if prefix == "" {